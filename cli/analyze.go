@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// runAnalyze runs `layout analyze`, which prints the analyzed layout of every type
+// in the file: a table per type, or with -json an array of analyzer.Report for
+// external tools. It fails when a layout has errors, after printing them.
+// Skipped tags and annotations are warned about on stderr, so -json output is
+// JSON alone.
+func runAnalyze(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the layouts as JSON")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	return analyze(os.Stdout, flags.Arg(0), *asJSON)
+}
+
+// analyze prints the analyzed layouts of the types in file to w
+func analyze(w io.Writer, file string, asJSON bool) error {
+	in, err := loadInput(parser.NewLoader(), file)
+	if err != nil {
+		return err
+	}
+
+	reports := []analyzer.Report{}
+	invalid := 0
+	for _, layout := range in.layouts {
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		if !analyzed.IsValid() {
+			invalid++
+		}
+		reports = append(reports, analyzed.Report())
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			return err
+		}
+	} else {
+		printReports(w, reports)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d layouts invalid", invalid, len(reports))
+	}
+	return nil
+}
+
+// printReports prints each report to out as a table of its regions
+func printReports(out io.Writer, reports []analyzer.Report) {
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s (%d bytes)\n", report.Type, report.Size)
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  FIELD\tTYPE\tDIRECTION\tSTART\tBOUNDARY\tELEMENT")
+		for _, r := range append(report.Regions, report.Trailer...) {
+			element := ""
+			if r.ElementSize > 0 {
+				element = fmt.Sprintf("%d", r.ElementSize)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%d\t%s\n", r.Field, r.GoType, r.Direction, r.Start, r.Boundary, element)
+		}
+		w.Flush()
+
+		for _, e := range report.Errors {
+			fmt.Fprintf(out, "  error: %s\n", e)
+		}
+		for _, w := range report.Warnings {
+			fmt.Fprintf(out, "  warning: %s\n", w)
+		}
+	}
+}
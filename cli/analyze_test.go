@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+func TestAnalyzeJSONSkippedTag(t *testing.T) {
+	// The malformed tag of B is warned about on stderr, leaving stdout JSON alone
+	file := filepath.Join(t.TempDir(), "a.go")
+	src := "package a\n\n" +
+		"// @layout size=8\n" +
+		"type P struct {\n" +
+		"\tA uint32 `layout:\"@0\"`\n" +
+		"\tB uint32 `layout:\"@bogus\"`\n" +
+		"}\n"
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := analyze(&out, file, true); err != nil {
+		t.Fatalf("analyze() error: %v", err)
+	}
+	var reports []analyzer.Report
+	if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+		t.Fatalf("output isn't JSON: %v\n%s", err, out.String())
+	}
+	if len(reports) != 1 || reports[0].Type != "P" || len(reports[0].Regions) != 1 {
+		t.Errorf("reports = %+v, want P with the region of A", reports)
+	}
+}
@@ -0,0 +1,798 @@
+// Package cli implements the layout command. The layout binary is Main; binaries
+// bundling custom field kinds call it after importing the packages registering
+// them with package kind.
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/codegen"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+const usage = `Usage:
+  layout generate [-tags expr] [-fastarch arch,...] [-golden] [-fuzz] [-builders] [-blocksize n] [-target tinygo] [-banner file] [-output-package dir] <file.go>...
+  layout test -type T -corpus dir [-v] <file.go>
+  layout corrupt -type T -sample file <file.go>
+  layout analyze [-json] <file.go>
+  layout compat <old.go> <new.go>
+  layout vet <file.go|dir|./...>...
+`
+
+// Main runs the layout command on os.Args and exits on failure
+func Main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	var err error
+	switch cmd := os.Args[1]; cmd {
+	case "generate":
+		err = runGenerate(os.Args[2:])
+	case "test":
+		err = runTest(os.Args[2:])
+	case "corrupt":
+		err = runCorrupt(os.Args[2:])
+	case "analyze":
+		err = runAnalyze(os.Args[2:])
+	case "compat":
+		err = runCompat(os.Args[2:])
+	case "vet":
+		err = runVet(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
+		fmt.Fprintf(os.Stderr, "Available commands: generate, test, corrupt, analyze, compat, vet\n")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runGenerate runs `layout generate`
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	tags := flags.String("tags", "", "build constraint for generated files, e.g. \"linux && !purego\"")
+	fastArch := flags.String("fastarch", "", "comma-separated GOARCHes tolerating unaligned loads (e.g. amd64,arm64); "+
+		"emits a typed-load variant for them and a bytewise-load variant for all others")
+	golden := flags.Bool("golden", false, "also generate tests comparing encoded values against golden files in testdata/")
+	fuzz := flags.Bool("fuzz", false, "also generate fuzz tests checking that decoded buffers re-encode to byte-stable output")
+	builders := flags.Bool("builders", false, "also generate New<Type>Builder test data builders producing encoded buffers with counts kept in step")
+	banner := flags.String("banner", os.Getenv(codegen.BannerEnv), "file holding a copyright or license banner for every generated file, "+
+		"with {{version}}, {{source}} and {{sha256}} placeholders (default $"+codegen.BannerEnv+")")
+	blockSize := flags.Int("blocksize", 0, "filesystem block size in bytes that align= layouts must meet for direct I/O (O_DIRECT), e.g. 4096")
+	target := flags.String("target", "", "compiler the generated code must build with: tinygo refuses layouts needing unsafe or reflection")
+	outputPackage := flags.String("output-package", "", "directory of a sibling package to generate into, e.g. internal/wire, "+
+		"keeping the layout methods off the annotated types")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	if *outputPackage != "" && flags.NArg() > 1 {
+		return fmt.Errorf("-output-package takes one input file, got %d", flags.NArg())
+	}
+	if *blockSize < 0 || *blockSize&(*blockSize-1) != 0 {
+		return fmt.Errorf("-blocksize %d is not a power of two", *blockSize)
+	}
+	if *target != "" && *target != codegen.TargetTinyGo {
+		return fmt.Errorf("unknown -target %s (supported: %s)", *target, codegen.TargetTinyGo)
+	}
+	opts := options{tags: *tags, golden: *golden, fuzz: *fuzz, builders: *builders, blockSize: *blockSize, target: *target,
+		outputPackage: *outputPackage}
+	if *fastArch != "" {
+		opts.fastArch = strings.Split(*fastArch, ",")
+	}
+
+	// Files of several packages share the layouts of the packages they import
+	loader := parser.NewLoader()
+	for _, inputFile := range flags.Args() {
+		if *banner != "" {
+			var err error
+			if opts.banner, err = loadBanner(*banner, inputFile); err != nil {
+				return err
+			}
+		}
+		if err := generate(loader, inputFile, opts); err != nil {
+			return fmt.Errorf("%s: %w", inputFile, err)
+		}
+	}
+	return nil
+}
+
+// loadBanner renders the banner template in file for the generated files of
+// inputFile
+func loadBanner(file, inputFile string) (string, error) {
+	template, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("read banner: %w", err)
+	}
+	source, err := os.ReadFile(inputFile)
+	if err != nil {
+		return "", fmt.Errorf("read input: %w", err)
+	}
+	sum := sha256.Sum256(source)
+
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+
+	return codegen.GenerateBanner(string(template), codegen.BannerVars{
+		Version: version,
+		Source:  filepath.Base(inputFile),
+		SHA256:  hex.EncodeToString(sum[:]),
+	})
+}
+
+// options controls build constraints on generated files and optional outputs
+type options struct {
+	tags      string   // //go:build expression for every generated file
+	fastArch  []string // GOARCHes getting a separate variant without alignment fallbacks
+	golden    bool     // Also generate golden-file tests
+	fuzz      bool     // Also generate round-trip fuzz tests
+	builders  bool     // Also generate test data builders
+	banner    string   // Comment block preceding the "Code generated" line of every generated file
+	blockSize int      // Filesystem block size checked against align= layouts, 0 for none
+	target    string   // Compiler the generated code must build with, "" for gc
+
+	outputPackage string // Directory of the package generated into, if not the input file's
+}
+
+// input is a parsed input file, shared by every generated variant
+type input struct {
+	file       string
+	parsed     *parser.ParsedFile
+	layouts    []*parser.TypeLayout // Layouts declared in the file, in dependency order
+	allLayouts []*parser.TypeLayout // Declared and imported layouts
+	registry   *analyzer.TypeRegistry
+	pkg        string // Package of the generated files
+	foreign    string // Qualifier of the input file's package, when generating into another
+	blockSize  int    // Filesystem block size aligned layouts are checked against (-blocksize)
+	target     string // Compiler the generated code must build with (-target)
+}
+
+func generate(loader *parser.Loader, inputFile string, opts options) error {
+	in, err := loadInput(loader, inputFile)
+	if err != nil {
+		return err
+	}
+	in.blockSize = opts.blockSize
+	in.target = opts.target
+	printWarnings(in)
+
+	// Build output filename: page.go -> page_layout.go
+	outputFile := generateOutputFilename(inputFile)
+	if opts.outputPackage != "" {
+		if in, err = foreignInput(in, opts.outputPackage); err != nil {
+			return err
+		}
+		outputFile = filepath.Join(opts.outputPackage, filepath.Base(outputFile))
+	}
+
+	variants, err := buildVariants(in, outputFile, opts)
+	if err != nil {
+		return err
+	}
+
+	var generatedTypes []string
+	for _, v := range variants {
+		code, types, err := render(in, v)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(v.file, []byte(opts.banner+code), 0644); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+		if len(variants) == 1 {
+			fmt.Printf("Generated: %s\n", v.file)
+		} else {
+			fmt.Printf("Generated: %s (%s)\n", v.file, v.constraint)
+		}
+		generatedTypes = types
+	}
+
+	// Sentinel errors shared by every generated file of the package
+	errorsFile := filepath.Join(filepath.Dir(outputFile), codegen.ErrorsFile)
+	if err := os.WriteFile(errorsFile, []byte(opts.banner+codegen.GenerateErrors(in.pkg)), 0644); err != nil {
+		return fmt.Errorf("write errors: %w", err)
+	}
+	fmt.Printf("Generated: %s\n", errorsFile)
+
+	// Byte orders encoding/binary lacks, when a layout is encoded in one
+	for _, layout := range in.layouts {
+		if !codegen.UsesByteOrderFile(layout.Anno.Endian) {
+			continue
+		}
+		byteOrderFile := filepath.Join(filepath.Dir(outputFile), codegen.ByteOrderFile)
+		if err := os.WriteFile(byteOrderFile, []byte(opts.banner+codegen.GenerateByteOrder(in.pkg)), 0644); err != nil {
+			return fmt.Errorf("write byte order: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", byteOrderFile)
+		break
+	}
+
+	// LayoutMetrics interface, when a layout reports its calls
+	for _, layout := range in.layouts {
+		if !layout.Anno.Metrics {
+			continue
+		}
+		metricsFile := filepath.Join(filepath.Dir(outputFile), codegen.MetricsFile)
+		if err := os.WriteFile(metricsFile, []byte(opts.banner+codegen.GenerateMetrics(in.pkg)), 0644); err != nil {
+			return fmt.Errorf("write metrics: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", metricsFile)
+		break
+	}
+
+	// cgo offset tests, when a layout mirrors a C struct
+	if members, test := renderCStruct(in); members != "" {
+		base := strings.TrimSuffix(outputFile, ".go") + "_cstruct"
+		if err := os.WriteFile(base+".go", []byte(opts.banner+members), 0644); err != nil {
+			return fmt.Errorf("write cstruct members: %w", err)
+		}
+		if err := os.WriteFile(base+"_test.go", []byte(opts.banner+test), 0644); err != nil {
+			return fmt.Errorf("write cstruct test: %w", err)
+		}
+		fmt.Printf("Generated: %s.go, %s_test.go (go test -tags %s)\n", base, base, codegen.CStructTag)
+	}
+
+	// Allocation tests, when a layout is unmarshaled into reused values
+	if test := renderReuse(in); test != "" {
+		reuseFile := strings.TrimSuffix(outputFile, ".go") + "_reuse_test.go"
+		if err := os.WriteFile(reuseFile, []byte(opts.banner+test), 0644); err != nil {
+			return fmt.Errorf("write reuse test: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", reuseFile)
+	}
+
+	// Allocation budget tests, when a layout sets one
+	if test := renderAllocs(in); test != "" {
+		allocsFile := strings.TrimSuffix(outputFile, ".go") + "_allocs_test.go"
+		if err := os.WriteFile(allocsFile, []byte(opts.banner+test), 0644); err != nil {
+			return fmt.Errorf("write allocs test: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", allocsFile)
+	}
+
+	if opts.golden {
+		goldenFile := strings.TrimSuffix(outputFile, ".go") + "_golden_test.go"
+		if err := os.WriteFile(goldenFile, []byte(opts.banner+renderGolden(in)), 0644); err != nil {
+			return fmt.Errorf("write golden test: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", goldenFile)
+	}
+
+	if opts.fuzz {
+		fuzzFile := strings.TrimSuffix(outputFile, ".go") + "_fuzz_test.go"
+		if err := os.WriteFile(fuzzFile, []byte(opts.banner+renderFuzz(in)), 0644); err != nil {
+			return fmt.Errorf("write fuzz test: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", fuzzFile)
+	}
+
+	if opts.builders {
+		builderFile := strings.TrimSuffix(outputFile, ".go") + "_builder_test.go"
+		if err := os.WriteFile(builderFile, []byte(opts.banner+renderBuilders(in)), 0644); err != nil {
+			return fmt.Errorf("write builders: %w", err)
+		}
+		fmt.Printf("Generated: %s\n", builderFile)
+	}
+
+	// Success message
+	for _, typeName := range generatedTypes {
+		fmt.Printf("  - %s.MarshalLayout() ([]byte, error)\n", typeName)
+		fmt.Printf("  - %s.UnmarshalLayout([]byte) error\n", typeName)
+	}
+
+	return nil
+}
+
+// loadInput parses inputFile like parseInput, printing the tags and annotations
+// the parse skipped to stderr as warnings
+func loadInput(loader *parser.Loader, inputFile string) (input, error) {
+	in, diagnostics, err := parseInput(loader, inputFile)
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", d.Position, d.Message)
+	}
+	return in, err
+}
+
+// parseInput parses inputFile and the layouts of packages it references, loading
+// those loader hasn't yet, and registers every layout's size and fields. It returns
+// the tags and annotations the parse skipped, even when it then finds no layouts.
+func parseInput(loader *parser.Loader, inputFile string) (input, []parser.Diagnostic, error) {
+	// Parse input file and the layouts of packages it references
+	parsed, err := loader.ParseFile(inputFile)
+	if err != nil {
+		return input{}, nil, fmt.Errorf("parse failed: %w", err)
+	}
+	layouts := parsed.Layouts
+
+	if len(layouts) == 0 {
+		return input{}, parsed.Diagnostics, fmt.Errorf("no types with @layout annotations found in %s", inputFile)
+	}
+
+	// Process nested types before the types embedding them
+	layouts, err = analyzer.SortByDependency(layouts)
+	if err != nil {
+		return input{}, parsed.Diagnostics, err
+	}
+
+	registry := analyzer.NewTypeRegistry()
+
+	// Register type aliases
+	for alias, underlying := range parsed.Aliases {
+		registry.RegisterAlias(alias, underlying)
+	}
+
+	// Register all types in the registry. Imported types are only registered;
+	// their package generates their code.
+	allLayouts := append(append([]*parser.TypeLayout{}, layouts...), parsed.Imported...)
+	for _, layout := range allLayouts {
+		registry.Register(layout.Name, layout.Anno.Size)
+		registry.RegisterFields(layout.Name, layout.Fields)
+		if layout.Anno.BinaryRead {
+			registry.RegisterBinaryRead(layout.Name, layout.Anno.Endian)
+		}
+		if layout.Anno.Reuse {
+			registry.RegisterReuse(layout.Name)
+		}
+		if layout.Anno.Zeroize {
+			registry.RegisterZeroize(layout.Name)
+		}
+	}
+
+	// Slices of stream types hold length-prefixed elements, at least a frame header
+	// and trailer each
+	for _, layout := range allLayouts {
+		if layout.Anno.Mode != "stream" {
+			continue
+		}
+		if analyzed, err := analyzer.Analyze(layout, registry); err == nil {
+			registry.RegisterFrame(layout.Name, analyzed.MinFrameSize())
+		}
+	}
+
+	return input{
+		file:       inputFile,
+		parsed:     parsed,
+		layouts:    layouts,
+		allLayouts: allLayouts,
+		registry:   registry,
+		pkg:        extractPackageName(inputFile),
+	}, parsed.Diagnostics, nil
+}
+
+// printWarnings prints the analyzer's warnings for each layout of in, once per
+// run rather than once per generated variant
+func printWarnings(in input) {
+	for _, layout := range in.layouts {
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		if analyzed == nil {
+			continue
+		}
+		for _, w := range analyzed.Warnings {
+			fmt.Printf("Warning: %s: %s\n", layout.Name, w)
+		}
+	}
+}
+
+// variant is one of the layout files generated for in, each built under its own
+// constraint
+type variant struct {
+	file        string
+	constraint  string // //go:build expression, "" for none
+	unalignedOK bool   // Typed loads for misaligned zerocopy fields (-fastarch)
+	blit        bool   // Memory copies for blit=true layouts, the host being of their byte order
+}
+
+// buildVariants returns the layout files generated for in as outputFile, each
+// under the -tags constraint: a fast variant for the -fastarch arches tolerating
+// unaligned loads and a portable one for the rest, unless no zerocopy field is
+// misaligned and both would be the same, and each split again when blit=true
+// layouts copy their memory on hosts of their byte order
+func buildVariants(in input, outputFile string, opts options) ([]variant, error) {
+	variants := []variant{{file: outputFile, constraint: opts.tags}}
+	if len(opts.fastArch) > 0 && misaligned(in) {
+		fast := strings.Join(opts.fastArch, " || ")
+		portable := "!" + strings.Join(opts.fastArch, " && !")
+		if opts.tags != "" {
+			fast = fmt.Sprintf("(%s) && (%s)", opts.tags, fast)
+			portable = fmt.Sprintf("(%s) && %s", opts.tags, portable)
+		}
+		variants = []variant{
+			{file: strings.TrimSuffix(outputFile, ".go") + "_fast.go", constraint: fast, unalignedOK: true},
+			{file: outputFile, constraint: portable},
+		}
+	}
+
+	endian, err := blitEndian(in)
+	if err != nil {
+		return nil, err
+	}
+	if endian != "" {
+		blit := codegen.BlitConstraint(endian)
+		var split []variant
+		for _, v := range variants {
+			blitted, portable := v, v
+			blitted.file = strings.TrimSuffix(v.file, ".go") + "_blit.go"
+			blitted.constraint, blitted.blit = blit, true
+			portable.constraint = fmt.Sprintf("!(%s)", blit)
+			if v.constraint != "" {
+				blitted.constraint = fmt.Sprintf("(%s) && %s", v.constraint, blit)
+				portable.constraint = fmt.Sprintf("(%s) && !(%s)", v.constraint, blit)
+			}
+			split = append(split, blitted, portable)
+		}
+		variants = split
+	}
+	return variants, nil
+}
+
+// misaligned reports whether a zerocopy layout of in has a misaligned field,
+// loaded bytewise but for the -fastarch variant
+func misaligned(in input) bool {
+	for _, layout := range in.layouts {
+		if layout.Anno.Mode != "zerocopy" {
+			continue
+		}
+		analyzed, err := analyzer.Analyze(layout, in.registry)
+		if err != nil {
+			continue // render reports it
+		}
+		for _, region := range analyzed.Regions {
+			if region.Misaligned {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// blitEndian returns the byte order of the blit=true layouts of in, or "" for
+// none. Hosts store a single order, so the layouts of a file must share it.
+func blitEndian(in input) (string, error) {
+	var endian, first string
+	for _, layout := range in.layouts {
+		if !layout.Anno.Blit {
+			continue
+		}
+		order := layout.Anno.Endian
+		if order == "" {
+			order = "little"
+		}
+		if endian != "" && order != endian {
+			return "", fmt.Errorf("blit=true layouts of a file must share endian=: %s is %s endian, %s is %s endian",
+				first, endian, layout.Name, order)
+		}
+		endian, first = order, layout.Name
+	}
+	return endian, nil
+}
+
+// render generates variant v of the layout file for in. With v.unalignedOK,
+// fields the analyzer marked misaligned still get typed loads, for architectures
+// that handle unaligned access in hardware; with v.blit, blit=true layouts copy
+// their memory.
+func render(in input, v variant) (string, []string, error) {
+	layouts, registry := in.layouts, in.registry
+	constraint := v.constraint
+
+	analyze := func(layout *parser.TypeLayout) (*analyzer.AnalyzedLayout, error) {
+		analyzed, err := analyzer.Analyze(layout, registry)
+		if err == nil && v.unalignedOK {
+			for i := range analyzed.Regions {
+				analyzed.Regions[i].Misaligned = false
+			}
+		}
+		return analyzed, err
+	}
+
+	var generated strings.Builder
+
+	// File header
+	generated.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	if constraint != "" {
+		generated.WriteString(fmt.Sprintf("//go:build %s\n\n", constraint))
+	}
+
+	// Determine package from first layout (all should be same package)
+	generated.WriteString(fmt.Sprintf("package %s\n\n", in.pkg))
+
+	// Collect imports required by each type's generated code
+	imports := make(map[string]bool)
+
+	for _, layout := range layouts {
+		analyzed, err := analyze(layout)
+		if err != nil {
+			return "", nil, fmt.Errorf("analyze %s: %w", layout.Name, err)
+		}
+
+		gen := newGenerator(in, layout, analyzed)
+		gen.SetBlit(v.blit)
+
+		for _, path := range gen.Imports() {
+			imports[path] = true
+		}
+	}
+
+	// Second pass: generate code for each type
+	var body strings.Builder
+	if in.foreign != "" {
+		body.WriteString(codegen.GenerateForeignTypes(in.foreign, layouts))
+	}
+	generatedTypes := []string{}
+	var gens []*codegen.Generator
+	for _, layout := range layouts {
+		analyzed, err := analyze(layout)
+		if err != nil {
+			// Print detailed errors for debugging
+			if analyzed != nil && len(analyzed.Errors) > 0 {
+				for _, e := range analyzed.Errors {
+					fmt.Fprintf(os.Stderr, "  Error: %s\n", e)
+				}
+			}
+			return "", nil, fmt.Errorf("analyze %s: %w", layout.Name, err)
+		}
+
+		if !analyzed.IsValid() {
+			return "", nil, fmt.Errorf("layout %s invalid: %v", layout.Name, analyzed.Errors)
+		}
+
+		gen := newGenerator(in, layout, analyzed)
+		gen.SetBlit(v.blit)
+
+		// Generate code (marshal/unmarshal for copy mode, accessors for zerocopy mode)
+		code, err := gen.Generate()
+		if err != nil {
+			return "", nil, fmt.Errorf("generate %s: %w", layout.Name, err)
+		}
+		body.WriteString(code)
+		body.WriteString("\n")
+
+		generatedTypes = append(generatedTypes, layout.Name)
+		gens = append(gens, gen)
+	}
+
+	// DecodePage, routing encoded pages to their kind= layouts
+	decode, decodeImports, err := codegen.GenerateDecodePage(gens)
+	if err != nil {
+		return "", nil, err
+	}
+	if decode != "" {
+		body.WriteString(decode)
+		body.WriteString("\n")
+		for _, path := range decodeImports {
+			imports[path] = true
+		}
+	}
+
+	// Imported packages whose types the generated code names (e.g., common.PageHeader)
+	referenced, err := qualifiers(in.pkg, body.String())
+	if err != nil {
+		return "", nil, err
+	}
+	named := make(map[string]string)
+	for qualifier, path := range in.parsed.Imports {
+		if referenced[qualifier] {
+			imports[path] = true
+			named[path] = qualifier
+		}
+	}
+
+	// Imports
+	var importPaths []string
+	for path := range imports {
+		importPaths = append(importPaths, path)
+	}
+	sort.Strings(importPaths)
+
+	generated.WriteString("import (\n")
+	for _, path := range importPaths {
+		if qualifier, ok := named[path]; ok && qualifier != filepath.Base(path) {
+			generated.WriteString(fmt.Sprintf("\t%s %q\n", qualifier, path))
+		} else {
+			generated.WriteString(fmt.Sprintf("\t%q\n", path))
+		}
+	}
+	generated.WriteString(")\n\n")
+	generated.WriteString(body.String())
+
+	return generated.String(), generatedTypes, nil
+}
+
+// qualifiers returns the package names the generated code selects from, such as
+// common in common.PageHeader. Identifiers declared in the code, a local named
+// like an import or an occurrence in a comment or string, don't count.
+func qualifiers(pkg, body string) (map[string]bool, error) {
+	file, err := goparser.ParseFile(token.NewFileSet(), "", "package "+pkg+"\n"+body, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parse generated code: %w", err)
+	}
+	unresolved := make(map[*ast.Ident]bool)
+	for _, ident := range file.Unresolved {
+		unresolved[ident] = true
+	}
+	found := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && unresolved[x] {
+				found[x.Name] = true
+			}
+		}
+		return true
+	})
+	return found, nil
+}
+
+// renderGolden generates the golden-file tests of the layouts of in. Generating
+// the layout file already validated them.
+func renderGolden(in input) string {
+	var code strings.Builder
+	code.WriteString(codegen.GenerateGoldenHeader(in.pkg))
+	for i, layout := range in.layouts {
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		if i > 0 {
+			code.WriteString("\n")
+		}
+		code.WriteString(newGenerator(in, layout, analyzed).GenerateGoldenTest())
+	}
+	return code.String()
+}
+
+// renderFuzz generates the round-trip fuzz tests of the layouts of in
+func renderFuzz(in input) string {
+	var code strings.Builder
+	code.WriteString(codegen.GenerateFuzzHeader(in.pkg))
+	for i, layout := range in.layouts {
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		if i > 0 {
+			code.WriteString("\n")
+		}
+		code.WriteString(newGenerator(in, layout, analyzed).GenerateFuzzTest())
+	}
+	return code.String()
+}
+
+// renderCStruct generates the file reading the C members of the cstruct= layouts
+// of in and the tests comparing them, or nothing without such layouts. The file
+// includes the cinclude= headers of every layout, once each.
+func renderCStruct(in input) (string, string) {
+	var includes, members, tests []string
+	for _, layout := range in.layouts {
+		if layout.Anno.CStruct == "" {
+			continue
+		}
+		for _, header := range layout.Anno.CInclude {
+			if !slices.Contains(includes, header) {
+				includes = append(includes, header)
+			}
+		}
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		gen := newGenerator(in, layout, analyzed)
+		members = append(members, gen.GenerateCStructMembers())
+		tests = append(tests, gen.GenerateCStructTest())
+	}
+	if len(members) == 0 {
+		return "", ""
+	}
+	return codegen.GenerateCStructHeader(in.pkg, includes) + strings.Join(members, "\n"),
+		codegen.GenerateCStructTestHeader(in.pkg) + strings.Join(tests, "\n")
+}
+
+// renderReuse generates the allocation tests of the reuse=true layouts of in,
+// or nothing without such layouts
+func renderReuse(in input) string {
+	var tests []string
+	for _, layout := range in.layouts {
+		if !layout.Anno.Reuse {
+			continue
+		}
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		tests = append(tests, newGenerator(in, layout, analyzed).GenerateReuseTest())
+	}
+	if len(tests) == 0 {
+		return ""
+	}
+	return codegen.GenerateReuseTestHeader(in.pkg) + strings.Join(tests, "\n")
+}
+
+// renderAllocs generates the allocation budget tests of the layouts of in
+// setting marshalallocs= or unmarshalallocs=, or nothing without such layouts
+func renderAllocs(in input) string {
+	var tests []string
+	for _, layout := range in.layouts {
+		if layout.Anno.MarshalAllocs == nil && layout.Anno.UnmarshalAllocs == nil {
+			continue
+		}
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		tests = append(tests, newGenerator(in, layout, analyzed).GenerateAllocsTest())
+	}
+	if len(tests) == 0 {
+		return ""
+	}
+	return codegen.GenerateAllocsTestHeader(in.pkg) + strings.Join(tests, "\n")
+}
+
+// renderBuilders generates the test data builders of the layouts of in
+func renderBuilders(in input) string {
+	var code strings.Builder
+	code.WriteString(codegen.GenerateBuilderHeader(in.pkg))
+	var builders []string
+	for _, layout := range in.layouts {
+		analyzed, _ := analyzer.Analyze(layout, in.registry)
+		if builder := newGenerator(in, layout, analyzed).GenerateBuilder(); builder != "" {
+			builders = append(builders, builder) // Zerocopy types have none
+		}
+	}
+	code.WriteString(strings.Join(builders, "\n"))
+	return code.String()
+}
+
+// newGenerator creates the generator for an analyzed layout of in, applying the
+// annotation's endian and mode defaults
+func newGenerator(in input, layout *parser.TypeLayout, analyzed *analyzer.AnalyzedLayout) *codegen.Generator {
+	endian := "little"
+	if layout.Anno.Endian != "" {
+		endian = layout.Anno.Endian
+	}
+
+	mode := "copy"
+	if layout.Anno.Mode != "" {
+		mode = layout.Anno.Mode
+	}
+
+	gen := codegen.NewGenerator(analyzed, layout, in.allLayouts, in.registry, endian, mode,
+		layout.Anno.Align, layout.Anno.Allocator)
+	gen.SetBlockSize(in.blockSize)
+	gen.SetTarget(in.target)
+	return gen
+}
+
+func generateOutputFilename(inputFile string) string {
+	dir := filepath.Dir(inputFile)
+	base := filepath.Base(inputFile)
+	ext := filepath.Ext(base)
+	nameWithoutExt := strings.TrimSuffix(base, ext)
+
+	outputBase := nameWithoutExt + "_layout.go"
+	return filepath.Join(dir, outputBase)
+}
+
+func extractPackageName(inputFile string) string {
+	// Quick and dirty: read first line that starts with "package"
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return "main"
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "package ") {
+			parts := strings.Fields(line)
+			if len(parts) >= 2 {
+				return parts[1]
+			}
+		}
+	}
+
+	return "main"
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"go/build/constraint"
+	"reflect"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/codegen"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestBuildVariants(t *testing.T) {
+	// @layout size=8 mode=zerocopy
+	// type Header struct {
+	//     Kind  uint8  `layout:"@0"`
+	//     Count uint32 `layout:"@1"` // Misaligned
+	// }
+	header := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 8, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "Kind", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Count", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+		},
+	}
+	// @layout size=8 blit=true
+	// type Rec struct {
+	//     ID uint64 `layout:"@0"`
+	// }
+	rec := &parser.TypeLayout{
+		Name: "Rec",
+		Anno: &parser.TypeAnnotation{Size: 8, Blit: true},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	// The same fields in copy mode, which has no unaligned loads to speed up
+	copied := *header
+	copied.Anno = &parser.TypeAnnotation{Size: 8}
+
+	blit := codegen.BlitConstraint("little")
+	fast := []string{"amd64", "arm64"}
+	tests := []struct {
+		name    string
+		layouts []*parser.TypeLayout
+		opts    options
+		want    []variant
+	}{
+		{"tags", []*parser.TypeLayout{header}, options{tags: "linux"}, []variant{
+			{file: "h_layout.go", constraint: "linux"},
+		}},
+		{"fastarch", []*parser.TypeLayout{header}, options{fastArch: fast}, []variant{
+			{file: "h_layout_fast.go", constraint: "amd64 || arm64", unalignedOK: true},
+			{file: "h_layout.go", constraint: "!amd64 && !arm64"},
+		}},
+		{"tags and fastarch", []*parser.TypeLayout{header}, options{tags: "linux && !purego", fastArch: fast}, []variant{
+			{file: "h_layout_fast.go", constraint: "(linux && !purego) && (amd64 || arm64)", unalignedOK: true},
+			{file: "h_layout.go", constraint: "(linux && !purego) && !amd64 && !arm64"},
+		}},
+		// Both variants would be the same file
+		{"fastarch without zerocopy", []*parser.TypeLayout{&copied}, options{tags: "linux", fastArch: fast}, []variant{
+			{file: "h_layout.go", constraint: "linux"},
+		}},
+		{"tags, fastarch and blit", []*parser.TypeLayout{header, rec}, options{tags: "linux", fastArch: fast}, []variant{
+			{file: "h_layout_fast_blit.go", constraint: "((linux) && (amd64 || arm64)) && " + blit, unalignedOK: true, blit: true},
+			{file: "h_layout_fast.go", constraint: "((linux) && (amd64 || arm64)) && !(" + blit + ")", unalignedOK: true},
+			{file: "h_layout_blit.go", constraint: "((linux) && !amd64 && !arm64) && " + blit, blit: true},
+			{file: "h_layout.go", constraint: "((linux) && !amd64 && !arm64) && !(" + blit + ")"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := input{layouts: tt.layouts, allLayouts: tt.layouts, registry: analyzer.NewTypeRegistry()}
+			got, err := buildVariants(in, "h_layout.go", tt.opts)
+			if err != nil {
+				t.Fatalf("buildVariants() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildVariants() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			for _, v := range got {
+				if _, err := constraint.Parse("//go:build " + v.constraint); err != nil {
+					t.Errorf("%s: constraint %q doesn't parse: %v", v.file, v.constraint, err)
+				}
+			}
+		})
+	}
+}
+
+func TestQualifiers(t *testing.T) {
+	// common is selected from; hdr is a local named like an import, and the
+	// comment and string mentioning other.X select nothing
+	body := `
+// MarshalLayout encodes other.X
+func (p *Page) MarshalLayout() ([]byte, error) {
+	hdr := p.Header
+	buf, err := common.MarshalPageHeader(&hdr)
+	if err != nil {
+		return nil, fmt.Errorf("other.X: %w", err)
+	}
+	_ = hdr.Kind
+	return buf, nil
+}
+`
+	got, err := qualifiers("example", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"common": true, "fmt": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("qualifiers = %v, want %v", got, want)
+	}
+
+	if _, err := qualifiers("example", "func {"); err == nil {
+		t.Error("qualifiers accepted code that doesn't parse")
+	}
+}
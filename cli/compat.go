@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// runCompat runs `layout compat`, which compares the layouts of two versions of a
+// source file and lists what changed, type by type. It fails when a change breaks
+// compatibility with buffers encoded by the old version.
+func runCompat(args []string) error {
+	flags := flag.NewFlagSet("compat", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	old, err := compatReports(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	new, err := compatReports(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	changes := append(analyzer.Compare(old.reports, new.reports), encodingChanges(old.layouts, new.layouts)...)
+	breaking := 0
+	for _, c := range changes {
+		kind := "compatible"
+		if c.Breaking {
+			kind = "breaking"
+			breaking++
+		}
+		fmt.Printf("%s: %s\n", kind, c)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No layout changes")
+	}
+
+	if breaking > 0 {
+		return fmt.Errorf("%d breaking changes", breaking)
+	}
+	return nil
+}
+
+// compatInput is one version of a file: its layouts and their reports
+type compatInput struct {
+	layouts []*parser.TypeLayout
+	reports []analyzer.Report
+}
+
+// compatReports analyzes every layout of a file version. Versions with invalid
+// layouts can't be compared.
+func compatReports(file string) (compatInput, error) {
+	in, err := loadInput(parser.NewLoader(), file)
+	if err != nil {
+		return compatInput{}, err
+	}
+	version := compatInput{layouts: in.layouts}
+	for _, layout := range in.layouts {
+		analyzed, err := analyzer.Analyze(layout, in.registry)
+		if err != nil {
+			return compatInput{}, fmt.Errorf("%s: analyze %s: %w", file, layout.Name, err)
+		}
+		version.reports = append(version.reports, analyzed.Report())
+	}
+	return version, nil
+}
+
+// encodingChanges reports types of both versions whose byte or bit order
+// changed, which re-encodes every multi-byte or bit field in place
+func encodingChanges(old, new []*parser.TypeLayout) []analyzer.Change {
+	newLayouts := make(map[string]*parser.TypeLayout)
+	for _, layout := range new {
+		newLayouts[layout.Name] = layout
+	}
+
+	var changes []analyzer.Change
+	for _, o := range old {
+		n, ok := newLayouts[o.Name]
+		if !ok {
+			continue
+		}
+		if from, to := orDefault(o.Anno.Endian, "little"), orDefault(n.Anno.Endian, "little"); from != to {
+			changes = append(changes, analyzer.Change{Type: o.Name, Breaking: true,
+				Message: fmt.Sprintf("endian changed from %s to %s", from, to)})
+		}
+		if from, to := orDefault(o.Anno.BitOrder, "lsb"), orDefault(n.Anno.BitOrder, "lsb"); from != to {
+			changes = append(changes, analyzer.Change{Type: o.Name, Breaking: true,
+				Message: fmt.Sprintf("bitorder changed from %s to %s", from, to)})
+		}
+	}
+	return changes
+}
+
+// orDefault returns s, or def when s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
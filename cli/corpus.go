@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/codegen"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// runTest runs `layout test`, which replays a corpus of encoded buffers, such as
+// corrupted pages collected from production, through a type's generated
+// UnmarshalLayout and MarshalLayout. It writes a temporary test next to the
+// generated code under a name of its own, runs it with `go test` and removes it,
+// so the code must have been generated first.
+func runTest(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	typeName := flags.String("type", "", "layout type to decode the corpus files as")
+	corpus := flags.String("corpus", "", "directory of encoded buffers, one per file")
+	verbose := flags.Bool("v", false, "list every corpus file, including refused ones")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *typeName == "" || *corpus == "" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+	inputFile := flags.Arg(0)
+
+	corpusDir, err := filepath.Abs(*corpus)
+	if err != nil {
+		return fmt.Errorf("corpus: %w", err)
+	}
+	if info, err := os.Stat(corpusDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("corpus %s is not a directory", *corpus)
+	}
+
+	in, err := loadInput(parser.NewLoader(), inputFile)
+	if err != nil {
+		return err
+	}
+	var gen *codegen.Generator
+	for _, layout := range in.layouts {
+		if layout.Name != *typeName {
+			continue
+		}
+		analyzed, err := analyzer.Analyze(layout, in.registry)
+		if err != nil {
+			return fmt.Errorf("analyze %s: %w", layout.Name, err)
+		}
+		gen = newGenerator(in, layout, analyzed)
+	}
+	if gen == nil {
+		return fmt.Errorf("no @layout type %s in %s", *typeName, inputFile)
+	}
+
+	// A file of its own, created exclusively, so none of the package's is replaced.
+	// One left by an interrupted run would declare the test twice.
+	dir := filepath.Dir(inputFile)
+	if stale, _ := filepath.Glob(filepath.Join(dir, codegen.CorpusTestPattern)); len(stale) > 0 {
+		return fmt.Errorf("%s exists, left by an interrupted layout test; remove it", stale[0])
+	}
+	testFile, err := os.CreateTemp(dir, codegen.CorpusTestPattern)
+	if err != nil {
+		return fmt.Errorf("create corpus test: %w", err)
+	}
+	defer os.Remove(testFile.Name())
+	code := gen.GenerateCorpusTest(extractPackageName(inputFile), corpusDir)
+	if _, err := testFile.WriteString(code); err != nil {
+		testFile.Close()
+		return fmt.Errorf("write corpus test: %w", err)
+	}
+	if err := testFile.Close(); err != nil {
+		return fmt.Errorf("write corpus test: %w", err)
+	}
+
+	goArgs := []string{"test", "-count=1", "-run", fmt.Sprintf("^TestLayoutCorpus%s$", *typeName)}
+	if *verbose {
+		goArgs = append(goArgs, "-v")
+	}
+	cmd := exec.Command("go", append(goArgs, ".")...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("corpus replay of %s failed: %w", *typeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/codegen"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// runCorrupt runs `layout corrupt`, which derives corrupted variants of a valid
+// encoded sample of a type, writes them to testdata/corrupt/<Type>/ next to the
+// input file and generates layout_corrupt_<type>_test.go checking that decoding
+// them fails gracefully. The sample is kept alongside as the baseline.
+func runCorrupt(args []string) error {
+	flags := flag.NewFlagSet("corrupt", flag.ExitOnError)
+	typeName := flags.String("type", "", "layout type the sample encodes")
+	sampleFile := flags.String("sample", "", "file holding a valid encoded sample of the type")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *typeName == "" || *sampleFile == "" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+	inputFile := flags.Arg(0)
+
+	sample, err := os.ReadFile(*sampleFile)
+	if err != nil {
+		return fmt.Errorf("read sample: %w", err)
+	}
+
+	in, err := loadInput(parser.NewLoader(), inputFile)
+	if err != nil {
+		return err
+	}
+	var gen *codegen.Generator
+	for _, layout := range in.layouts {
+		if layout.Name != *typeName {
+			continue
+		}
+		analyzed, err := analyzer.Analyze(layout, in.registry)
+		if err != nil {
+			return fmt.Errorf("analyze %s: %w", layout.Name, err)
+		}
+		gen = newGenerator(in, layout, analyzed)
+	}
+	if gen == nil {
+		return fmt.Errorf("no @layout type %s in %s", *typeName, inputFile)
+	}
+	variants, err := gen.CorruptVariants(sample)
+	if err != nil {
+		return err
+	}
+
+	// Replace the variants of an earlier run, which may have other names
+	dir := filepath.Dir(inputFile)
+	variantDir := filepath.Join("testdata", "corrupt", *typeName)
+	if err := os.RemoveAll(filepath.Join(dir, variantDir)); err != nil {
+		return fmt.Errorf("remove old variants: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, variantDir), 0755); err != nil {
+		return fmt.Errorf("create variant directory: %w", err)
+	}
+	files := append([]codegen.CorruptVariant{{Name: "sample", Data: sample}}, variants...)
+	for _, v := range files {
+		if err := os.WriteFile(filepath.Join(dir, variantDir, v.Name), v.Data, 0644); err != nil {
+			return fmt.Errorf("write variant: %w", err)
+		}
+	}
+	fmt.Printf("Generated: %s (%d variants)\n", filepath.Join(dir, variantDir), len(variants))
+
+	testFile := filepath.Join(dir, "layout_corrupt_"+strings.ToLower(*typeName)+"_test.go")
+	code := gen.GenerateCorruptTest(extractPackageName(inputFile), filepath.ToSlash(variantDir), variants)
+	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
+		return fmt.Errorf("write corrupt test: %w", err)
+	}
+	fmt.Printf("Generated: %s\n", testFile)
+	return nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/codegen"
+	"golang.org/x/tools/go/packages"
+)
+
+// foreignInput retargets in at the package in dir (-output-package). The input
+// file's package is imported by its import path in the enclosing module, which
+// go/packages resolves like the parser resolves imported layouts.
+func foreignInput(in input, dir string) (input, error) {
+	srcDir, err := filepath.Abs(filepath.Dir(in.file))
+	if err != nil {
+		return input{}, err
+	}
+	outDir, err := filepath.Abs(dir)
+	if err != nil {
+		return input{}, err
+	}
+	if srcDir == outDir {
+		return input{}, fmt.Errorf("-output-package %s is the input file's own package", dir)
+	}
+
+	found, err := packages.Load(&packages.Config{Mode: packages.NeedName, Dir: srcDir}, ".")
+	if err == nil && len(found) != 1 {
+		err = fmt.Errorf("%d packages match", len(found))
+	}
+	if err == nil && len(found[0].Errors) > 0 {
+		err = found[0].Errors[0]
+	}
+	if err != nil {
+		return input{}, fmt.Errorf("locate package of %s: %w", in.file, err)
+	}
+	importPath := found[0].PkgPath
+
+	qualifier := in.pkg
+	pkg := outputPackageName(outDir)
+	if pkg == qualifier {
+		return input{}, fmt.Errorf("-output-package %s: package %s would shadow the input package of the same name", dir, pkg)
+	}
+
+	aliases, err := codegen.PrepareForeign(in.layouts, in.parsed.Aliases, qualifier)
+	if err != nil {
+		return input{}, err
+	}
+	for alias, underlying := range aliases {
+		in.registry.RegisterAlias(alias, underlying)
+	}
+	in.parsed.Imports[qualifier] = importPath
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return input{}, fmt.Errorf("create output package: %w", err)
+	}
+	in.pkg = pkg
+	in.foreign = qualifier
+	return in, nil
+}
+
+// outputPackageName returns the package of the Go files already in dir, or the
+// directory's name for a new package
+func outputPackageName(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if !strings.HasSuffix(file, "_test.go") {
+			return extractPackageName(file)
+		}
+	}
+	return strings.ReplaceAll(filepath.Base(dir), "-", "_")
+}
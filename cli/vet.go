@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// runVet runs `layout vet`, which parses and analyzes every type in the given
+// files, directories and ./... patterns without generating code. It prints their
+// errors and warnings, such as collisions, counts too narrow for their regions,
+// regions too small for an element and exported fields without layout tags, as
+// file: Type: message, and notes the free space shared by opposing regions. Tags
+// and annotations the parser skips are errors, at their file:line. It fails when
+// there are errors or warnings, so CI can keep them out.
+func runVet(args []string) error {
+	flags := flag.NewFlagSet("vet", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	files, err := vetFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	return vet(os.Stdout, files)
+}
+
+// vet prints the problems of the layouts in files to w
+func vet(w io.Writer, files []string) error {
+	loader := parser.NewLoader()
+	errors, warnings := 0, 0
+	for _, file := range files {
+		in, diagnostics, err := parseInput(loader, file)
+		for _, d := range diagnostics {
+			fmt.Fprintf(w, "%s: %s: error: %s\n", d.Position, d.Type, d.Message)
+		}
+		errors += len(diagnostics)
+		if err != nil {
+			fmt.Fprintf(w, "%s: error: %v\n", file, err)
+			errors++
+			continue
+		}
+		for _, layout := range in.layouts {
+			analyzed, _ := analyzer.Analyze(layout, in.registry)
+			for _, e := range analyzed.Errors {
+				fmt.Fprintf(w, "%s: %s: error: %s\n", file, layout.Name, e)
+			}
+			for _, warning := range analyzed.Warnings {
+				fmt.Fprintf(w, "%s: %s: %s\n", file, layout.Name, warning)
+			}
+			for _, gap := range analyzed.Gaps {
+				fmt.Fprintf(w, "%s: %s: note: %s and %s share free space, each bounded only by the other\n",
+					file, layout.Name, gap.Forward.Field.Name, gap.Backward.Field.Name)
+			}
+			errors += len(analyzed.Errors)
+			warnings += len(analyzed.Warnings)
+		}
+	}
+
+	if errors+warnings > 0 {
+		return fmt.Errorf("%d errors, %d warnings in %d files", errors, warnings, len(files))
+	}
+	return nil
+}
+
+// annotated matches an @layout annotation in the doc comment of a struct type,
+// standalone or in a type ( ... ) group
+var annotated = regexp.MustCompile(`(?m)^\s*//\s*@layout\b.*\n(\s*//.*\n)*\s*(type\s+)?\w+\s+struct\b`)
+
+// vetFiles expands the arguments of `layout vet` into the source files declaring
+// @layout types. A directory stands for its files, and dir/... for the files of
+// its whole tree, skipping testdata, vendor and hidden directories as go does.
+// Files named explicitly are always vetted.
+func vetFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		root, recursive := strings.CutSuffix(arg, "...")
+		if recursive {
+			root = filepath.Clean(strings.TrimSuffix(root, "/"))
+			if root == "" {
+				root = "."
+			}
+		} else if info, err := os.Stat(arg); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != root && (!recursive || name == "testdata" || name == "vendor" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if annotated.Match(src) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files with @layout types in %s", strings.Join(args, " "))
+	}
+	return files, nil
+}
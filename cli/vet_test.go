@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVetSkipped(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			// A malformed tag drops the field
+			name: "tag",
+			src: "package a\n\n" +
+				"// @layout size=8\n" +
+				"type P struct {\n" +
+				"\tA uint32 `layout:\"@0\"`\n" +
+				"\tB uint32 `layout:\"@bogus\"`\n" +
+				"}\n",
+			want: "a.go:6:11: P: error: P.B: invalid layout tag",
+		},
+		{
+			// An unknown parameter drops the whole type
+			name: "annotation",
+			src: "package a\n\n" +
+				"// @layout size=8 bogus=1\n" +
+				"type Q struct {\n" +
+				"\tA uint32 `layout:\"@0\"`\n" +
+				"}\n",
+			want: "a.go:3:1: Q: error: Q: invalid @layout annotation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "a.go")
+			if err := os.WriteFile(file, []byte(tt.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			if err := vet(&out, []string{file}); err == nil {
+				t.Errorf("vet() = nil, want the skipped %s to fail it", tt.name)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("vet() printed:\n%s\nwant %q", out.String(), tt.want)
+			}
+		})
+	}
+}
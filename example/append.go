@@ -0,0 +1,33 @@
+package example
+
+// @layout size=4
+type AppendElement struct {
+	Value uint32 `layout:"@0"`
+}
+
+// AppendPage grows Elements up toward Tail, which grows down from the end of the
+// page into the same space
+//
+// @layout size=128 mode=zerocopy
+type AppendPage struct {
+	buf         [128]byte
+	NumElements uint8           `layout:"@0"`
+	TailLen     uint8           `layout:"@1"`
+	Elements    []AppendElement `layout:"@8,start-end,count=NumElements"`
+	Tail        []byte          `layout:"end-start,count=TailLen"`
+}
+
+// @layout size=4
+type Slot struct {
+	CellOffset uint16 `layout:"@0"`
+	CellSize   uint16 `layout:"@2"`
+}
+
+// @layout size=256 mode=zerocopy slotted=true
+type SlottedPage struct {
+	buf      [256]byte
+	NumSlots uint16   `layout:"@0"`
+	Slots    []Slot   `layout:"@2,start-end,count=NumSlots"`
+	Data     []byte   `layout:"end-start"`
+	Cells    [][]byte `layout:"from=Slots,offset=CellOffset,size=CellSize,region=Data,offsetmode=page"`
+}
@@ -0,0 +1,1350 @@
+// Code generated by layout. DO NOT EDIT.
+
+package example
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"unsafe"
+)
+
+// Byte offsets and sizes of the fixed fields of AppendElement
+const (
+	AppendElementValueOffset = 0
+	AppendElementValueSize   = 4
+)
+
+func (p *AppendElement) MarshalLayout() ([]byte, error) {
+	buf := make([]byte, 4)
+
+	// Value: uint32 at [0, 4)
+	binary.LittleEndian.PutUint32(buf[0:4], p.Value)
+
+	return buf, nil
+}
+
+func (p *AppendElement) UnmarshalLayout(buf []byte) error {
+	if len(buf) < 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+
+	// Value: uint32 at [0, 4)
+	p.Value = binary.LittleEndian.Uint32(buf[0:4])
+
+	return nil
+}
+
+// PatchAppendElementValue writes v as the Value of the AppendElement encoded in buf, leaving its
+// other bytes as they are
+func PatchAppendElementValue(buf []byte, v uint32) error {
+	if len(buf) < 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := AppendElement{Value: v}
+
+	// Value: uint32 at [0, 4)
+	binary.LittleEndian.PutUint32(buf[0:4], p.Value)
+
+	return nil
+}
+
+// ScanAppendElement reads consecutive AppendElement records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanAppendElement(r io.Reader, fn func(*AppendElement) error) error {
+	p := &AppendElement{}
+	buf := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 4 bytes at offset pageID*4, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *AppendElement) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 2305843009213693951 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4
+	buf := make([]byte, 4)
+	n, err := f.ReadAt(buf, off)
+	if n < 4 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 4 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*4
+func (p *AppendElement) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 2305843009213693951 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded AppendElement from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 4 bytes.
+func (p *AppendElement) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[3] // Bounds check hint to compiler
+	copy(dst[:4], src[:4])
+	dst[0], dst[1], dst[2], dst[3] = dst[3], dst[2], dst[1], dst[0] // Value
+}
+
+// EqualBuffers reports whether a and b encode the same AppendElement, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 4 bytes.
+func (p *AppendElement) EqualBuffers(a, b []byte) bool {
+	_, _ = a[3], b[3] // Bounds check hint to compiler
+	if string(a[0:4]) != string(b[0:4]) { // Value
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the AppendElement encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *AppendElement) Dump(buf []byte) string {
+	if len(buf) < 4 {
+		return fmt.Sprintf("AppendElement: %d bytes, want 4", len(buf))
+	}
+	out := []byte("AppendElement (4 bytes)\n")
+	out = fmt.Appendf(out, "0000  Value: % x\n", buf[0:4])
+	return string(out)
+}
+
+// Byte offsets and sizes of the fixed fields of AppendPage
+const (
+	AppendPageNumElementsOffset = 0
+	AppendPageNumElementsSize   = 1
+	AppendPageTailLenOffset     = 1
+	AppendPageTailLenSize       = 1
+)
+
+// Capacities of the dynamic regions of AppendPage: their bytes, and the most
+// elements of a struct slice that fit when the region holds nothing else
+const (
+	AppendPageElementsCapacity = 120 // bytes
+	AppendPageMaxElements      = 30  // elements
+	AppendPageTailCapacity     = 120 // bytes
+)
+
+// AppendPageFreeSpace returns how many of the 120 bytes shared by Elements and Tail
+// are free, given the length of each (elements for Elements, bytes for Tail).
+// Negative when they don't fit.
+func AppendPageFreeSpace(elements, tail int) int {
+	return 120 - elements*4 - tail
+}
+
+// Clone creates a copy of the AppendPage
+func (p *AppendPage) Clone() *AppendPage {
+	clone := *p
+	return &clone
+}
+
+// GetNumElements returns uint8 at offset 0
+func (p *AppendPage) GetNumElements() uint8 {
+	return p.buf[0]
+}
+
+// SetNumElements sets NumElements, refusing counts beyond the region capacity of 30
+func (p *AppendPage) SetNumElements(v uint8) error {
+	if int(v) > 30 {
+		return fmt.Errorf("SetNumElements: %d exceeds capacity 30: %w", v, ErrRegionOverflow)
+	}
+	p.buf[0] = v
+	return nil
+}
+
+// GetTailLen returns uint8 at offset 1
+func (p *AppendPage) GetTailLen() uint8 {
+	return p.buf[1]
+}
+
+// SetTailLen sets TailLen, refusing counts beyond the region capacity of 120
+func (p *AppendPage) SetTailLen(v uint8) error {
+	if int(v) > 120 {
+		return fmt.Errorf("SetTailLen: %d exceeds capacity 120: %w", v, ErrRegionOverflow)
+	}
+	p.buf[1] = v
+	return nil
+}
+
+// GetElementsCount returns the number of Elements elements
+func (p *AppendPage) GetElementsCount() int {
+	return int(p.GetNumElements())
+}
+
+// GetElementsAt returns the AppendElement element at index idx
+func (p *AppendPage) GetElementsAt(idx int) AppendElement {
+	if idx >= p.GetElementsCount() {
+		panic("index out of bounds")
+	}
+	offset := 8 + idx*4
+	var elem AppendElement
+	elem.UnmarshalLayout(p.buf[offset:offset+4])
+	return elem
+}
+
+// SetElementsAt sets the AppendElement element at index idx
+func (p *AppendPage) SetElementsAt(idx int, elem AppendElement) {
+	if idx >= p.GetElementsCount() {
+		panic("index out of bounds")
+	}
+	offset := 8 + idx*4
+	buf, _ := elem.MarshalLayout()
+	copy(p.buf[offset:offset+4], buf)
+}
+
+// IterateElements calls fn for each AppendElement element decoded from p.buf, stopping early if fn returns false.
+// The element pointer is reused between calls.
+func (p *AppendPage) IterateElements(fn func(i int, e *AppendElement) bool) {
+	var elem AppendElement
+	for i := 0; i < p.GetElementsCount(); i++ {
+		offset := 8 + i*4
+		elem.UnmarshalLayout(p.buf[offset:offset+4])
+		if !fn(i, &elem) {
+			return
+		}
+	}
+}
+
+// AllElements returns an iterator over the AppendElement elements decoded from p.buf
+func (p *AppendPage) AllElements() iter.Seq2[int, AppendElement] {
+	return func(yield func(int, AppendElement) bool) {
+		p.IterateElements(func(i int, e *AppendElement) bool {
+			return yield(i, *e)
+		})
+	}
+}
+
+// AppendElement appends e to Elements in place, bumping NumElements
+func (p *AppendPage) AppendElement(e AppendElement) error {
+	n := p.GetElementsCount()
+	if n+1 > 30 {
+		return fmt.Errorf("AppendElement: Elements full at %d elements: %w", n, ErrRegionOverflow)
+	}
+	// Tail grows down from 128 into the same space
+	if end, low := 8+n*4+4, 128-int(p.GetTailLen()); end > low {
+		return fmt.Errorf("AppendElement: Elements would end at %d, past Tail at %d: %w", end, low, ErrRegionOverflow)
+	}
+	elemBuf, err := e.MarshalLayout()
+	if err != nil {
+		return fmt.Errorf("AppendElement: %w", err)
+	}
+	offset := 8 + n*4
+	copy(p.buf[offset:offset+4], elemBuf)
+	// Reload from p.buf, which the count may have moved past the slice
+	if cap(p.Elements) >= n+1 {
+		p.Elements = p.Elements[:n+1]
+	} else {
+		p.Elements = make([]AppendElement, n+1)
+	}
+	for i := range p.Elements {
+		at := 8 + i*4
+		if err := p.Elements[i].UnmarshalLayout(p.buf[at:at+4]); err != nil {
+			return fmt.Errorf("AppendElement: unmarshal Elements[%d]: %w", i, err)
+		}
+	}
+	_ = p.SetNumElements(uint8(n+1))
+	p.NumElements = uint8(n+1)
+	return nil
+}
+
+func (p *AppendPage) MarshalLayout() ([]byte, error) {
+	// NumElements: uint8 at [0, 1)
+	p.buf[0] = p.NumElements
+
+	// TailLen: uint8 at [1, 2)
+	p.buf[1] = p.TailLen
+
+	// Elements: []AppendElement at [8, 128) with count=NumElements (element size: 4)
+	if len(p.Elements) != int(p.NumElements) {
+		return nil, fmt.Errorf("Elements length mismatch: have %d, want %d: %w", len(p.Elements), p.NumElements, ErrCountMismatch)
+	}
+	for i := range p.Elements {
+		at := 8 + i*4
+		if at + 4 > 128 {
+			return nil, fmt.Errorf("Elements collision at offset %d: %w", at, ErrRegionOverflow)
+		}
+		{ // AppendElement inlined
+			buf := p.buf[at:at+4]
+			p := &p.Elements[i]
+			clear(buf) // Gaps are zero, as in a fresh encoding
+			// Value: uint32 at [0, 4)
+			binary.LittleEndian.PutUint32(buf[0:4], p.Value)
+		}
+	}
+
+	// Tail: []byte at [128, 8) with count=TailLen
+	// Tail is already sliced from p.buf, no copy needed
+
+	return p.buf[:], nil
+}
+
+func (p *AppendPage) UnmarshalLayout(buf []byte) error {
+	// Zero-copy mode: copy buf into p.buf unless it already is p.buf
+	if len(buf) > 0 && len(p.buf) > 0 && &buf[0] != &p.buf[0] {
+		src := uintptr(unsafe.Pointer(&buf[0]))
+		dst := uintptr(unsafe.Pointer(&p.buf[0]))
+		if src < dst+uintptr(len(p.buf)) && dst < src+uintptr(len(buf)) {
+			return fmt.Errorf("UnmarshalLayout: buf overlaps p.buf at a different offset: %w", ErrBadBuffer)
+		}
+		copy(p.buf[:], buf)
+	}
+
+	// NumElements: uint8 at [0, 1)
+	p.NumElements = p.buf[0]
+
+	// TailLen: uint8 at [1, 2)
+	p.TailLen = p.buf[1]
+
+	// Elements: []AppendElement at [8, 128) with count=NumElements (element size: 4)
+	if p.NumElements > 30 {
+		return fmt.Errorf("Elements: count %d outside capacity 30: %w", p.NumElements, ErrRegionOverflow)
+	}
+	// Reuse slice if capacity allows
+	if cap(p.Elements) >= int(p.NumElements) {
+		p.Elements = p.Elements[:p.NumElements]
+	} else {
+		p.Elements = make([]AppendElement, p.NumElements)
+	}
+	for i := range p.Elements {
+		at := 8 + i*4
+		if err := p.Elements[i].UnmarshalLayout(p.buf[at:at+4]); err != nil {
+			return fmt.Errorf("unmarshal Elements[%d]: %w", i, err)
+		}
+	}
+
+	// Tail: []byte at [128, 8) with count=TailLen
+	if p.TailLen > 120 {
+		return fmt.Errorf("Tail: count %d outside capacity 120: %w", p.TailLen, ErrRegionOverflow)
+	}
+	p.Tail = p.buf[128-int(p.TailLen):128]
+
+	return nil
+}
+
+// loadFrom reads one 128-byte AppendPage from r into the buffer and decodes it. It
+// returns io.EOF if r ends before the page, and ErrTruncatedPage if partway
+// through it.
+func (p *AppendPage) loadFrom(r io.Reader) (int, error) {
+	n, err := io.ReadFull(r, p.buf[:])
+	if err == io.ErrUnexpectedEOF {
+		return n, fmt.Errorf("ReadFrom: read %d of 128 bytes: %w", n, ErrTruncatedPage)
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, p.UnmarshalLayout(p.buf[:])
+}
+
+// ReadFrom reads one 128-byte AppendPage from r into the buffer and decodes it.
+// It implements io.ReaderFrom, but stops after the page rather than at EOF. As
+// io.Copy expects, r ending before the page returns 0, nil and leaves p as it
+// was; r ending partway through it fails with ErrTruncatedPage.
+func (p *AppendPage) ReadFrom(r io.Reader) (int64, error) {
+	n, err := p.loadFrom(r)
+	if err == io.EOF {
+		return 0, nil
+	}
+	return int64(n), err
+}
+
+// LoadFromAt reads the 128-byte AppendPage at offset off of r into the buffer and
+// decodes it. It returns io.EOF if off is at or past the end of r, and
+// ErrTruncatedPage if r ends partway through the page.
+func (p *AppendPage) LoadFromAt(r io.ReaderAt, off int64) error {
+	n, err := r.ReadAt(p.buf[:], off)
+	if n < 128 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("LoadFromAt: read %d of 128 bytes at offset %d: %w", n, off, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(p.buf[:])
+}
+
+// WriteTo encodes p into its buffer and writes the buffer to w. It implements
+// io.WriterTo.
+func (p *AppendPage) WriteTo(w io.Writer) (int64, error) {
+	if _, err := p.MarshalLayout(); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(p.buf[:])
+	return int64(n), err
+}
+
+// LoadFrom is ReadFrom without the byte count, returning io.EOF if r ends
+// before the page, so a loop over a stream of pages knows where it stops
+func (p *AppendPage) LoadFrom(r io.Reader) error {
+	_, err := p.loadFrom(r)
+	return err
+}
+
+// SaveTo is WriteTo without the byte count
+func (p *AppendPage) SaveTo(w io.Writer) error {
+	_, err := p.WriteTo(w)
+	return err
+}
+
+// ScanAppendPage reads consecutive AppendPage records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanAppendPage(r io.Reader, fn func(*AppendPage) error) error {
+	p := &AppendPage{}
+	for {
+		if err := p.LoadFrom(r); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 128 bytes at offset pageID*128, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *AppendPage) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 72057594037927935 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 128
+	return p.LoadFromAt(f, off)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*128
+func (p *AppendPage) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 72057594037927935 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 128
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded AppendPage from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 128 bytes.
+func (p *AppendPage) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[127] // Bounds check hint to compiler
+	copy(dst[:128], src[:128])
+}
+
+// EqualBuffers reports whether a and b encode the same AppendPage, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 128 bytes.
+func (p *AppendPage) EqualBuffers(a, b []byte) bool {
+	_, _ = a[127], b[127] // Bounds check hint to compiler
+	if string(a[0:2]) != string(b[0:2]) { // NumElements, TailLen
+		return false
+	}
+	countNumElements := int(a[0])
+	// Elements: countNumElements*4 bytes in use, at most 120
+	if n := max(min(countNumElements*4, 120), 0); n > 0 {
+		for at := 8; at < 8+n; at += 4 {
+			if !(*AppendElement)(nil).EqualBuffers(a[at:at+4], b[at:at+4]) {
+				return false
+			}
+		}
+	}
+	countTailLen := int(a[1])
+	// Tail: countTailLen bytes in use, at most 120
+	if n := max(min(countTailLen, 120), 0); string(a[128-n:128]) != string(b[128-n:128]) {
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the AppendPage encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *AppendPage) Dump(buf []byte) string {
+	if len(buf) < 128 {
+		return fmt.Sprintf("AppendPage: %d bytes, want 128", len(buf))
+	}
+	out := []byte("AppendPage (128 bytes)\n")
+	out = fmt.Appendf(out, "0000  NumElements: % x\n", buf[0:1])
+	out = fmt.Appendf(out, "0001  TailLen: % x\n", buf[1:2])
+	countNumElements := int(buf[0])
+	usedElements := max(min(countNumElements*4, 120), 0)
+	out = fmt.Appendf(out, "%04x  Elements: %d bytes\n", 8, usedElements)
+	for at := 8; at < 8+usedElements; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 8+usedElements)])
+	}
+	countTailLen := int(buf[1])
+	usedTail := max(min(countTailLen, 120), 0)
+	out = fmt.Appendf(out, "%04x  Tail: %d bytes\n", 128-usedTail, usedTail)
+	for at := 128-usedTail; at < 128; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 128)])
+	}
+	return string(out)
+}
+
+// appendPageJSON is the JSON form of AppendPage, filled from its buffer
+type appendPageJSON struct {
+	NumElements uint8
+	TailLen     uint8
+	Elements    []AppendElement
+	Tail        []byte
+}
+
+// MarshalJSON encodes the AppendPage held in the buffer, read through its accessors
+func (p *AppendPage) MarshalJSON() ([]byte, error) {
+	v := appendPageJSON{
+		NumElements: p.GetNumElements(),
+		TailLen: p.GetTailLen(),
+	}
+	v.Elements = make([]AppendElement, max(min(p.GetElementsCount(), 30), 0))
+	for i := range v.Elements {
+		v.Elements[i] = p.GetElementsAt(i)
+	}
+	countTailLen := int(p.buf[1])
+	v.Tail = p.buf[128-max(min(countTailLen, 120), 0) : 128]
+	return json.Marshal(&v)
+}
+
+// UnmarshalJSON writes the AppendPage encoded in data into the buffer through its
+// accessors, then decodes the buffer as UnmarshalLayout does
+func (p *AppendPage) UnmarshalJSON(data []byte) error {
+	var v appendPageJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := p.SetNumElements(v.NumElements); err != nil {
+		return fmt.Errorf("UnmarshalJSON: %w", err)
+	}
+	if err := p.SetTailLen(v.TailLen); err != nil {
+		return fmt.Errorf("UnmarshalJSON: %w", err)
+	}
+	if len(v.Elements) > 30 {
+		return fmt.Errorf("UnmarshalJSON: Elements: %d elements exceeds capacity 30: %w", len(v.Elements), ErrRegionOverflow)
+	}
+	if len(v.Elements) != p.GetElementsCount() {
+		return fmt.Errorf("UnmarshalJSON: Elements has %d elements, count says %d: %w", len(v.Elements), p.GetElementsCount(), ErrCountMismatch)
+	}
+	for i, elem := range v.Elements {
+		p.SetElementsAt(i, elem)
+	}
+	if len(v.Tail) > 120 {
+		return fmt.Errorf("UnmarshalJSON: Tail: %d bytes exceeds capacity 120: %w", len(v.Tail), ErrRegionOverflow)
+	}
+	countTailLen := int(p.buf[1])
+	if len(v.Tail) != countTailLen {
+		return fmt.Errorf("UnmarshalJSON: Tail has %d bytes, count says %d: %w", len(v.Tail), countTailLen, ErrCountMismatch)
+	}
+	copy(p.buf[128-len(v.Tail):128], v.Tail)
+	return p.UnmarshalLayout(p.buf[:])
+}
+
+// Byte offsets and sizes of the fixed fields of Slot
+const (
+	SlotCellOffsetOffset = 0
+	SlotCellOffsetSize   = 2
+	SlotCellSizeOffset   = 2
+	SlotCellSizeSize     = 2
+)
+
+func (p *Slot) MarshalLayout() ([]byte, error) {
+	buf := make([]byte, 4)
+
+	// CellOffset: uint16 at [0, 2)
+	binary.LittleEndian.PutUint16(buf[0:2], p.CellOffset)
+
+	// CellSize: uint16 at [2, 4)
+	binary.LittleEndian.PutUint16(buf[2:4], p.CellSize)
+
+	return buf, nil
+}
+
+func (p *Slot) UnmarshalLayout(buf []byte) error {
+	if len(buf) < 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+
+	// CellOffset: uint16 at [0, 2)
+	p.CellOffset = binary.LittleEndian.Uint16(buf[0:2])
+
+	// CellSize: uint16 at [2, 4)
+	p.CellSize = binary.LittleEndian.Uint16(buf[2:4])
+
+	return nil
+}
+
+// PatchSlotCellOffset writes v as the CellOffset of the Slot encoded in buf, leaving its
+// other bytes as they are
+func PatchSlotCellOffset(buf []byte, v uint16) error {
+	if len(buf) < 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := Slot{CellOffset: v}
+
+	// CellOffset: uint16 at [0, 2)
+	binary.LittleEndian.PutUint16(buf[0:2], p.CellOffset)
+
+	return nil
+}
+
+// PatchSlotCellSize writes v as the CellSize of the Slot encoded in buf, leaving its
+// other bytes as they are
+func PatchSlotCellSize(buf []byte, v uint16) error {
+	if len(buf) < 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4 {
+		return fmt.Errorf("expected 4 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := Slot{CellSize: v}
+
+	// CellSize: uint16 at [2, 4)
+	binary.LittleEndian.PutUint16(buf[2:4], p.CellSize)
+
+	return nil
+}
+
+// ScanSlot reads consecutive Slot records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanSlot(r io.Reader, fn func(*Slot) error) error {
+	p := &Slot{}
+	buf := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 4 bytes at offset pageID*4, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *Slot) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 2305843009213693951 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4
+	buf := make([]byte, 4)
+	n, err := f.ReadAt(buf, off)
+	if n < 4 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 4 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*4
+func (p *Slot) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 2305843009213693951 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded Slot from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 4 bytes.
+func (p *Slot) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[3] // Bounds check hint to compiler
+	copy(dst[:4], src[:4])
+	dst[0], dst[1] = dst[1], dst[0] // CellOffset
+	dst[2], dst[3] = dst[3], dst[2] // CellSize
+}
+
+// EqualBuffers reports whether a and b encode the same Slot, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 4 bytes.
+func (p *Slot) EqualBuffers(a, b []byte) bool {
+	_, _ = a[3], b[3] // Bounds check hint to compiler
+	if string(a[0:4]) != string(b[0:4]) { // CellOffset, CellSize
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the Slot encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *Slot) Dump(buf []byte) string {
+	if len(buf) < 4 {
+		return fmt.Sprintf("Slot: %d bytes, want 4", len(buf))
+	}
+	out := []byte("Slot (4 bytes)\n")
+	out = fmt.Appendf(out, "0000  CellOffset: % x\n", buf[0:2])
+	out = fmt.Appendf(out, "0002  CellSize: % x\n", buf[2:4])
+	return string(out)
+}
+
+// Byte offsets and sizes of the fixed fields of SlottedPage
+const (
+	SlottedPageNumSlotsOffset = 0
+	SlottedPageNumSlotsSize   = 2
+)
+
+// Capacities of the dynamic regions of SlottedPage: their bytes, and the most
+// elements of a struct slice that fit when the region holds nothing else
+const (
+	SlottedPageSlotsCapacity = 254 // bytes
+	SlottedPageMaxSlots      = 63  // elements
+	SlottedPageDataCapacity  = 254 // bytes
+)
+
+// SlottedPageFreeSpace returns how many of the 254 bytes shared by Slots and Data
+// are free, given the length of each (elements for Slots, bytes for Data).
+// Negative when they don't fit.
+func SlottedPageFreeSpace(slots, data int) int {
+	return 254 - slots*4 - data
+}
+
+// SlottedPageMaxCellsSize returns the largest Cells entry a SlottedPage holds, beside
+// one element.
+// Negative when even an empty one doesn't fit.
+func SlottedPageMaxCellsSize() int {
+	return 250
+}
+
+// Clone creates a copy of the SlottedPage
+func (p *SlottedPage) Clone() *SlottedPage {
+	clone := *p
+	return &clone
+}
+
+// GetNumSlots returns uint16 at offset 0
+func (p *SlottedPage) GetNumSlots() uint16 {
+	var v uint16
+	copy((*[2]byte)(unsafe.Pointer(&v))[:], p.buf[0:2])
+	return v
+}
+
+// SetNumSlots sets NumSlots, refusing counts beyond the region capacity of 63
+func (p *SlottedPage) SetNumSlots(v uint16) error {
+	if int(v) > 63 {
+		return fmt.Errorf("SetNumSlots: %d exceeds capacity 63: %w", v, ErrRegionOverflow)
+	}
+	copy(p.buf[0:2], (*[2]byte)(unsafe.Pointer(&v))[:])
+	return nil
+}
+
+// GetSlotsCount returns the number of Slots elements
+func (p *SlottedPage) GetSlotsCount() int {
+	return int(p.GetNumSlots())
+}
+
+// GetSlotsAt returns the Slot element at index idx
+func (p *SlottedPage) GetSlotsAt(idx int) Slot {
+	if idx >= p.GetSlotsCount() {
+		panic("index out of bounds")
+	}
+	offset := 2 + idx*4
+	var elem Slot
+	elem.UnmarshalLayout(p.buf[offset:offset+4])
+	return elem
+}
+
+// SetSlotsAt sets the Slot element at index idx
+func (p *SlottedPage) SetSlotsAt(idx int, elem Slot) {
+	if idx >= p.GetSlotsCount() {
+		panic("index out of bounds")
+	}
+	offset := 2 + idx*4
+	buf, _ := elem.MarshalLayout()
+	copy(p.buf[offset:offset+4], buf)
+}
+
+// IterateSlots calls fn for each Slot element decoded from p.buf, stopping early if fn returns false.
+// The element pointer is reused between calls.
+func (p *SlottedPage) IterateSlots(fn func(i int, e *Slot) bool) {
+	var elem Slot
+	for i := 0; i < p.GetSlotsCount(); i++ {
+		offset := 2 + i*4
+		elem.UnmarshalLayout(p.buf[offset:offset+4])
+		if !fn(i, &elem) {
+			return
+		}
+	}
+}
+
+// AllSlots returns an iterator over the Slot elements decoded from p.buf
+func (p *SlottedPage) AllSlots() iter.Seq2[int, Slot] {
+	return func(yield func(int, Slot) bool) {
+		p.IterateSlots(func(i int, e *Slot) bool {
+			return yield(i, *e)
+		})
+	}
+}
+
+// AppendSlot appends e to Slots in place, bumping NumSlots
+func (p *SlottedPage) AppendSlot(e Slot) error {
+	n := p.GetSlotsCount()
+	if n+1 > 63 {
+		return fmt.Errorf("AppendSlot: Slots full at %d elements: %w", n, ErrRegionOverflow)
+	}
+	// Cells grows down toward the directory
+	if end, low := 2+n*4+4, p.slottedCellsLow(); end > low {
+		return fmt.Errorf("AppendSlot: Slots would end at %d, past Cells at %d: %w", end, low, ErrRegionOverflow)
+	}
+	elemBuf, err := e.MarshalLayout()
+	if err != nil {
+		return fmt.Errorf("AppendSlot: %w", err)
+	}
+	offset := 2 + n*4
+	copy(p.buf[offset:offset+4], elemBuf)
+	// Reload from p.buf, which the count may have moved past the slice
+	if cap(p.Slots) >= n+1 {
+		p.Slots = p.Slots[:n+1]
+	} else {
+		p.Slots = make([]Slot, n+1)
+	}
+	for i := range p.Slots {
+		at := 2 + i*4
+		if err := p.Slots[i].UnmarshalLayout(p.buf[at:at+4]); err != nil {
+			return fmt.Errorf("AppendSlot: unmarshal Slots[%d]: %w", i, err)
+		}
+	}
+	_ = p.SetNumSlots(uint16(n+1))
+	p.NumSlots = uint16(n+1)
+	return nil
+}
+
+// GetCells returns the Cells at index idx
+func (p *SlottedPage) GetCells(idx int) []byte {
+	if idx >= p.GetSlotsCount() {
+		panic("index out of bounds")
+	}
+	elem := p.GetSlotsAt(idx)
+	start := int(elem.CellOffset)
+	size := int(elem.CellSize)
+	return p.buf[start:start+size]
+}
+
+// SetCellInPlace updates Cells at index idx (size must match)
+func (p *SlottedPage) SetCellInPlace(idx int, data []byte) {
+	if idx >= p.GetSlotsCount() {
+		panic("index out of bounds")
+	}
+	elem := p.GetSlotsAt(idx)
+	if uint16(len(data)) != elem.CellSize {
+		panic("size mismatch: use Update instead of SetInPlace")
+	}
+	start := int(elem.CellOffset)
+	copy(p.buf[start:], data)
+}
+
+// slottedCellsLow returns the lowest offset occupied by a live Cells cell
+func (p *SlottedPage) slottedCellsLow() int {
+	low := 256
+	for i := 0; i < p.GetSlotsCount(); i++ {
+		elem := p.GetSlotsAt(i)
+		if elem.CellOffset != 0 && int(elem.CellOffset) < low {
+			low = int(elem.CellOffset)
+		}
+	}
+	return low
+}
+
+// FreeSpace returns the contiguous free bytes between the Slots directory and the lowest cell
+func (p *SlottedPage) FreeSpace() int {
+	return p.slottedCellsLow() - (2 + p.GetSlotsCount()*4)
+}
+
+// FragmentedBytes returns the bytes inside the cell area not referenced by any live slot
+func (p *SlottedPage) FragmentedBytes() int {
+	used := 0
+	for i := 0; i < p.GetSlotsCount(); i++ {
+		elem := p.GetSlotsAt(i)
+		if elem.CellOffset != 0 {
+			used += int(elem.CellSize)
+		}
+	}
+	return 256 - p.slottedCellsLow() - used
+}
+
+// Defragment compacts live cells against the end of the buffer in slot order,
+// making all free space contiguous, and reloads the page
+func (p *SlottedPage) Defragment() error {
+	var scratch [256]byte
+	offset := 256
+	for i := 0; i < p.GetSlotsCount(); i++ {
+		elem := p.GetSlotsAt(i)
+		if elem.CellOffset == 0 {
+			continue
+		}
+		start := int(elem.CellOffset)
+		size := int(elem.CellSize)
+		offset -= size
+		copy(scratch[offset:offset+size], p.buf[start:start+size])
+		elem.CellOffset = uint16(offset)
+		p.SetSlotsAt(i, elem)
+	}
+	copy(p.buf[offset:256], scratch[offset:])
+	return p.UnmarshalLayout(p.buf[:])
+}
+
+// AllocSlot reserves size bytes of cell space and returns the Slots slot referencing it.
+// Free slots are reused before the directory grows, and the page is defragmented
+// when the request only fits after compaction.
+func (p *SlottedPage) AllocSlot(size int) (int, error) {
+	if size < 0 {
+		return 0, fmt.Errorf("AllocSlot: negative size %d: %w", size, ErrOutOfRange)
+	}
+	count := p.GetSlotsCount()
+	slot := -1
+	for i := 0; i < count; i++ {
+		if p.GetSlotsAt(i).CellOffset == 0 {
+			slot = i
+			break
+		}
+	}
+	dirEnd := 2 + count*4
+	if slot < 0 {
+		dirEnd += 4
+	}
+	free := p.slottedCellsLow() - dirEnd
+	if free < size {
+		if free+p.FragmentedBytes() < size {
+			return 0, fmt.Errorf("AllocSlot: need %d bytes, have %d: %w", size, free+p.FragmentedBytes(), ErrRegionOverflow)
+		}
+		if err := p.Defragment(); err != nil {
+			return 0, err
+		}
+	}
+	offset := p.slottedCellsLow() - size
+	if slot < 0 {
+		slot = count
+		_ = p.SetNumSlots(uint16(count+1))
+	}
+	var elem Slot
+	elem.CellOffset = uint16(offset)
+	elem.CellSize = uint16(size)
+	p.SetSlotsAt(slot, elem)
+	return slot, p.UnmarshalLayout(p.buf[:])
+}
+
+// FreeSlot releases the cell referenced by Slots slot idx. The slot is marked free
+// for reuse, trailing free slots are trimmed from the directory, and the page is
+// reloaded.
+func (p *SlottedPage) FreeSlot(idx int) error {
+	if idx < 0 || idx >= p.GetSlotsCount() {
+		panic("index out of bounds")
+	}
+	var elem Slot
+	p.SetSlotsAt(idx, elem)
+	count := p.GetSlotsCount()
+	for count > 0 && p.GetSlotsAt(count-1).CellOffset == 0 {
+		count--
+	}
+	_ = p.SetNumSlots(uint16(count))
+	return p.UnmarshalLayout(p.buf[:])
+}
+
+func (p *SlottedPage) MarshalLayout() ([]byte, error) {
+	// NumSlots: uint16 at [0, 2), unaligned
+	copy(p.buf[0:2], (*[2]byte)(unsafe.Pointer(&p.NumSlots))[:])
+
+	// Slots: []Slot at [2, 256) with count=NumSlots (element size: 4)
+	if len(p.Slots) != int(p.NumSlots) {
+		return nil, fmt.Errorf("Slots length mismatch: have %d, want %d: %w", len(p.Slots), p.NumSlots, ErrCountMismatch)
+	}
+	for i := range p.Slots {
+		at := 2 + i*4
+		if at + 4 > 256 {
+			return nil, fmt.Errorf("Slots collision at offset %d: %w", at, ErrRegionOverflow)
+		}
+		{ // Slot inlined
+			buf := p.buf[at:at+4]
+			p := &p.Slots[i]
+			clear(buf) // Gaps are zero, as in a fresh encoding
+			// CellOffset: uint16 at [0, 2)
+			binary.LittleEndian.PutUint16(buf[0:2], p.CellOffset)
+
+			// CellSize: uint16 at [2, 4)
+			binary.LittleEndian.PutUint16(buf[2:4], p.CellSize)
+		}
+	}
+
+	// Data: []byte at [256, 2)
+	// Data is already sliced from p.buf, no copy needed
+
+	return p.buf[:], nil
+}
+
+func (p *SlottedPage) UnmarshalLayout(buf []byte) error {
+	// Zero-copy mode: copy buf into p.buf unless it already is p.buf
+	if len(buf) > 0 && len(p.buf) > 0 && &buf[0] != &p.buf[0] {
+		src := uintptr(unsafe.Pointer(&buf[0]))
+		dst := uintptr(unsafe.Pointer(&p.buf[0]))
+		if src < dst+uintptr(len(p.buf)) && dst < src+uintptr(len(buf)) {
+			return fmt.Errorf("UnmarshalLayout: buf overlaps p.buf at a different offset: %w", ErrBadBuffer)
+		}
+		copy(p.buf[:], buf)
+	}
+
+	// NumSlots: uint16 at [0, 2), unaligned
+	copy((*[2]byte)(unsafe.Pointer(&p.NumSlots))[:], p.buf[0:2])
+
+	// Slots: []Slot at [2, 256) with count=NumSlots (element size: 4)
+	if p.NumSlots > 63 {
+		return fmt.Errorf("Slots: count %d outside capacity 63: %w", p.NumSlots, ErrRegionOverflow)
+	}
+	// Reuse slice if capacity allows
+	if cap(p.Slots) >= int(p.NumSlots) {
+		p.Slots = p.Slots[:p.NumSlots]
+	} else {
+		p.Slots = make([]Slot, p.NumSlots)
+	}
+	for i := range p.Slots {
+		at := 2 + i*4
+		if err := p.Slots[i].UnmarshalLayout(p.buf[at:at+4]); err != nil {
+			return fmt.Errorf("unmarshal Slots[%d]: %w", i, err)
+		}
+	}
+
+	// Data: []byte at [256, 2)
+	// Data: end-start data region, set by indirect slice reconstruction
+
+	// Cells: [][]byte from=Slots offset=CellOffset size=CellSize region=Data
+	// Initialize Data data region after metadata
+	elementsEnd := 2 + int(p.NumSlots)*4
+	p.Data = p.buf[elementsEnd:256]
+
+	// Reuse slice if capacity allows
+	if cap(p.Cells) >= len(p.Slots) {
+		p.Cells = p.Cells[:len(p.Slots)]
+	} else {
+		p.Cells = make([][]byte, len(p.Slots))
+	}
+	for i := range p.Slots {
+		offset := int(p.Slots[i].CellOffset)
+		size := int(p.Slots[i].CellSize)
+		if offset == 0 {
+			p.Cells[i] = nil
+			continue
+		}
+		// Offset counts from the page start, rebase onto Data
+		regionOffset := offset - elementsEnd
+		if regionOffset < 0 || size < 0 || regionOffset+size > len(p.Data) {
+			return fmt.Errorf("Cells[%d]: [%d, %d) outside Data: %w", i, regionOffset, regionOffset+size, ErrRegionOverflow)
+		}
+		p.Cells[i] = p.Data[regionOffset:regionOffset+size]
+	}
+
+	return nil
+}
+
+// loadFrom reads one 256-byte SlottedPage from r into the buffer and decodes it. It
+// returns io.EOF if r ends before the page, and ErrTruncatedPage if partway
+// through it.
+func (p *SlottedPage) loadFrom(r io.Reader) (int, error) {
+	n, err := io.ReadFull(r, p.buf[:])
+	if err == io.ErrUnexpectedEOF {
+		return n, fmt.Errorf("ReadFrom: read %d of 256 bytes: %w", n, ErrTruncatedPage)
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, p.UnmarshalLayout(p.buf[:])
+}
+
+// ReadFrom reads one 256-byte SlottedPage from r into the buffer and decodes it.
+// It implements io.ReaderFrom, but stops after the page rather than at EOF. As
+// io.Copy expects, r ending before the page returns 0, nil and leaves p as it
+// was; r ending partway through it fails with ErrTruncatedPage.
+func (p *SlottedPage) ReadFrom(r io.Reader) (int64, error) {
+	n, err := p.loadFrom(r)
+	if err == io.EOF {
+		return 0, nil
+	}
+	return int64(n), err
+}
+
+// LoadFromAt reads the 256-byte SlottedPage at offset off of r into the buffer and
+// decodes it. It returns io.EOF if off is at or past the end of r, and
+// ErrTruncatedPage if r ends partway through the page.
+func (p *SlottedPage) LoadFromAt(r io.ReaderAt, off int64) error {
+	n, err := r.ReadAt(p.buf[:], off)
+	if n < 256 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("LoadFromAt: read %d of 256 bytes at offset %d: %w", n, off, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(p.buf[:])
+}
+
+// WriteTo encodes p into its buffer and writes the buffer to w. It implements
+// io.WriterTo.
+func (p *SlottedPage) WriteTo(w io.Writer) (int64, error) {
+	if _, err := p.MarshalLayout(); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(p.buf[:])
+	return int64(n), err
+}
+
+// LoadFrom is ReadFrom without the byte count, returning io.EOF if r ends
+// before the page, so a loop over a stream of pages knows where it stops
+func (p *SlottedPage) LoadFrom(r io.Reader) error {
+	_, err := p.loadFrom(r)
+	return err
+}
+
+// SaveTo is WriteTo without the byte count
+func (p *SlottedPage) SaveTo(w io.Writer) error {
+	_, err := p.WriteTo(w)
+	return err
+}
+
+// RebuildIndirectSlices rebuilds the physical layout from logical slices
+// Call this after modifying Keys/Values before calling MarshalLayout
+func (p *SlottedPage) RebuildIndirectSlices() {
+	// Calculate where Slots ends
+	elementsEnd := 2 + int(p.NumSlots)*4
+	
+	// Initialize Data buffer after Slots
+	p.Data = p.buf[elementsEnd:elementsEnd:256]
+	
+	// Rebuild Slots array
+	if cap(p.Slots) >= int(p.NumSlots) {
+		p.Slots = p.Slots[:p.NumSlots]
+	} else {
+		p.Slots = make([]Slot, p.NumSlots)
+	}
+	
+	// Pack indirect slices into Data region backward from end
+	offset := 256
+	
+	// Pack all indirect slices backward from end (elements in forward order)
+	for i := 0; i < len(p.Cells); i++ {
+		// Pack Cells[i]
+		size0 := len(p.Cells[i])
+		offset -= size0
+		copy(p.buf[offset:offset+size0], p.Cells[i])
+		p.Slots[i].CellOffset = uint16(offset)
+		p.Slots[i].CellSize = uint16(size0)
+	}
+	
+	// Update Data to span full packed region
+	p.Data = p.buf[elementsEnd:256]
+}
+
+// ScanSlottedPage reads consecutive SlottedPage records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanSlottedPage(r io.Reader, fn func(*SlottedPage) error) error {
+	p := &SlottedPage{}
+	for {
+		if err := p.LoadFrom(r); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 256 bytes at offset pageID*256, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *SlottedPage) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 36028797018963967 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 256
+	return p.LoadFromAt(f, off)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*256
+func (p *SlottedPage) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 36028797018963967 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 256
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded SlottedPage from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 256 bytes.
+func (p *SlottedPage) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[255] // Bounds check hint to compiler
+	copy(dst[:256], src[:256])
+	dst[0], dst[1] = dst[1], dst[0] // NumSlots
+}
+
+// EqualBuffers reports whether a and b encode the same SlottedPage, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 256 bytes.
+func (p *SlottedPage) EqualBuffers(a, b []byte) bool {
+	_, _ = a[255], b[255] // Bounds check hint to compiler
+	if string(a[0:2]) != string(b[0:2]) { // NumSlots
+		return false
+	}
+	var countNumSlotsValue uint16
+	copy((*[2]byte)(unsafe.Pointer(&countNumSlotsValue))[:], a[0:2])
+	countNumSlots := int(countNumSlotsValue)
+	// Slots: countNumSlots*4 bytes in use, at most 252
+	if n := max(min(countNumSlots*4, 252), 0); n > 0 {
+		for at := 2; at < 2+n; at += 4 {
+			if !(*Slot)(nil).EqualBuffers(a[at:at+4], b[at:at+4]) {
+				return false
+			}
+		}
+	}
+	if string(a[2:256]) != string(b[2:256]) { // Data, compared whole
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the SlottedPage encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *SlottedPage) Dump(buf []byte) string {
+	if len(buf) < 256 {
+		return fmt.Sprintf("SlottedPage: %d bytes, want 256", len(buf))
+	}
+	out := []byte("SlottedPage (256 bytes)\n")
+	out = fmt.Appendf(out, "0000  NumSlots: % x\n", buf[0:2])
+	var countNumSlotsValue uint16
+	copy((*[2]byte)(unsafe.Pointer(&countNumSlotsValue))[:], buf[0:2])
+	countNumSlots := int(countNumSlotsValue)
+	usedSlots := max(min(countNumSlots*4, 252), 0)
+	out = fmt.Appendf(out, "%04x  Slots: %d bytes\n", 2, usedSlots)
+	for at := 2; at < 2+usedSlots; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 2+usedSlots)])
+	}
+	usedData := 254
+	out = fmt.Appendf(out, "%04x  Data: %d bytes\n", 256-usedData, usedData)
+	for at := 256-usedData; at < 256; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 256)])
+	}
+	return string(out)
+}
+
+// slottedPageJSON is the JSON form of SlottedPage, filled from its buffer
+type slottedPageJSON struct {
+	NumSlots uint16
+	Slots    []Slot
+	Data     []byte
+}
+
+// MarshalJSON encodes the SlottedPage held in the buffer, read through its accessors
+func (p *SlottedPage) MarshalJSON() ([]byte, error) {
+	v := slottedPageJSON{
+		NumSlots: p.GetNumSlots(),
+	}
+	v.Slots = make([]Slot, max(min(p.GetSlotsCount(), 63), 0))
+	for i := range v.Slots {
+		v.Slots[i] = p.GetSlotsAt(i)
+	}
+	v.Data = p.buf[2:256]
+	return json.Marshal(&v)
+}
+
+// UnmarshalJSON writes the SlottedPage encoded in data into the buffer through its
+// accessors, then decodes the buffer as UnmarshalLayout does
+func (p *SlottedPage) UnmarshalJSON(data []byte) error {
+	var v slottedPageJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := p.SetNumSlots(v.NumSlots); err != nil {
+		return fmt.Errorf("UnmarshalJSON: %w", err)
+	}
+	if len(v.Slots) > 63 {
+		return fmt.Errorf("UnmarshalJSON: Slots: %d elements exceeds capacity 63: %w", len(v.Slots), ErrRegionOverflow)
+	}
+	if len(v.Slots) != p.GetSlotsCount() {
+		return fmt.Errorf("UnmarshalJSON: Slots has %d elements, count says %d: %w", len(v.Slots), p.GetSlotsCount(), ErrCountMismatch)
+	}
+	for i, elem := range v.Slots {
+		p.SetSlotsAt(i, elem)
+	}
+	if len(v.Data) > 254 {
+		return fmt.Errorf("UnmarshalJSON: Data: %d bytes exceeds capacity 254: %w", len(v.Data), ErrRegionOverflow)
+	}
+	clear(p.buf[2+copy(p.buf[2:256], v.Data) : 256])
+	return p.UnmarshalLayout(p.buf[:])
+}
+
@@ -0,0 +1,79 @@
+package example
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAppendStopsAtSharedRegion(t *testing.T) {
+	// Tail holds its last 100 bytes, leaving [8, 28) to Elements
+	var buf [128]byte
+	buf[1] = 100
+	tail := bytes.Repeat([]byte{0xAA}, 100)
+	copy(buf[28:], tail)
+	page := &AppendPage{}
+	if err := page.UnmarshalLayout(buf[:]); err != nil {
+		t.Fatalf("UnmarshalLayout() error: %v", err)
+	}
+
+	appended := 0
+	var err error
+	for i := range 15 {
+		if err = page.AppendElement(AppendElement{Value: 0xFFFFFFFF}); err != nil {
+			break
+		}
+		appended = i + 1
+	}
+	if appended != 5 || !errors.Is(err, ErrRegionOverflow) {
+		t.Errorf("AppendElement() appended %d then returned %v, want 5 then ErrRegionOverflow", appended, err)
+	}
+	if !bytes.Equal(page.buf[28:], tail) {
+		t.Errorf("AppendElement() overwrote Tail: % x", page.buf[28:])
+	}
+	if len(page.Elements) != 5 || page.GetElementsCount() != 5 {
+		t.Errorf("Elements has %d elements, count %d, want 5", len(page.Elements), page.GetElementsCount())
+	}
+}
+
+func TestAppendSlotStopsAtCells(t *testing.T) {
+	page := &SlottedPage{}
+	slot, err := page.AllocSlot(200)
+	if err != nil {
+		t.Fatalf("AllocSlot() error: %v", err)
+	}
+	cell := page.GetCells(slot)
+	for i := range cell {
+		cell[i] = 0xAA
+	}
+
+	// The directory [2, 6) may grow to the cells at 56
+	appended := 0
+	for err == nil {
+		if err = page.AppendSlot(Slot{}); err == nil {
+			appended++
+		}
+	}
+	if appended != 12 || !errors.Is(err, ErrRegionOverflow) {
+		t.Errorf("AppendSlot() appended %d then returned %v, want 12 then ErrRegionOverflow", appended, err)
+	}
+	if !bytes.Equal(page.GetCells(slot), bytes.Repeat([]byte{0xAA}, 200)) {
+		t.Errorf("AppendSlot() overwrote the cell: % x", page.GetCells(slot))
+	}
+	if free := page.FreeSpace(); free != 2 {
+		t.Errorf("FreeSpace() = %d, want 2", free)
+	}
+}
+
+func TestAppendAfterSetCount(t *testing.T) {
+	page := &SlottedPage{}
+	if err := page.SetNumSlots(2); err != nil {
+		t.Fatalf("SetNumSlots() error: %v", err)
+	}
+	if err := page.AppendSlot(Slot{CellOffset: 250, CellSize: 6}); err != nil {
+		t.Fatalf("AppendSlot() error: %v", err)
+	}
+	if len(page.Slots) != 3 || page.Slots[2] != (Slot{CellOffset: 250, CellSize: 6}) {
+		t.Errorf("Slots = %v, want two free slots then {250 6}", page.Slots)
+	}
+}
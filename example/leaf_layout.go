@@ -5,6 +5,15 @@ package example
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
+)
+
+// Byte offsets and sizes of the fixed fields of LeafElement
+const (
+	LeafElementKeyOffset    = 0
+	LeafElementKeySize      = 4
+	LeafElementOffsetOffset = 4
+	LeafElementOffsetSize   = 4
 )
 
 func (p *LeafElement) MarshalLayout() ([]byte, error) {
@@ -20,8 +29,11 @@ func (p *LeafElement) MarshalLayout() ([]byte, error) {
 }
 
 func (p *LeafElement) UnmarshalLayout(buf []byte) error {
-	if len(buf) != 8 {
-		return fmt.Errorf("expected 8 bytes, got %d", len(buf))
+	if len(buf) < 8 {
+		return fmt.Errorf("expected 8 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 8 {
+		return fmt.Errorf("expected 8 bytes, got %d: %w", len(buf), ErrLongBuffer)
 	}
 
 	// Key: uint32 at [0, 4)
@@ -33,6 +45,146 @@ func (p *LeafElement) UnmarshalLayout(buf []byte) error {
 	return nil
 }
 
+// PatchLeafElementKey writes v as the Key of the LeafElement encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafElementKey(buf []byte, v uint32) error {
+	if len(buf) < 8 {
+		return fmt.Errorf("expected 8 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 8 {
+		return fmt.Errorf("expected 8 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafElement{Key: v}
+
+	// Key: uint32 at [0, 4)
+	binary.LittleEndian.PutUint32(buf[0:4], p.Key)
+
+	return nil
+}
+
+// PatchLeafElementOffset writes v as the Offset of the LeafElement encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafElementOffset(buf []byte, v uint32) error {
+	if len(buf) < 8 {
+		return fmt.Errorf("expected 8 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 8 {
+		return fmt.Errorf("expected 8 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafElement{Offset: v}
+
+	// Offset: uint32 at [4, 8)
+	binary.LittleEndian.PutUint32(buf[4:8], p.Offset)
+
+	return nil
+}
+
+// ScanLeafElement reads consecutive LeafElement records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanLeafElement(r io.Reader, fn func(*LeafElement) error) error {
+	p := &LeafElement{}
+	buf := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 8 bytes at offset pageID*8, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *LeafElement) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 1152921504606846975 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 8
+	buf := make([]byte, 8)
+	n, err := f.ReadAt(buf, off)
+	if n < 8 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 8 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*8
+func (p *LeafElement) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 1152921504606846975 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 8
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded LeafElement from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 8 bytes.
+func (p *LeafElement) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[7] // Bounds check hint to compiler
+	copy(dst[:8], src[:8])
+	dst[0], dst[1], dst[2], dst[3] = dst[3], dst[2], dst[1], dst[0] // Key
+	dst[4], dst[5], dst[6], dst[7] = dst[7], dst[6], dst[5], dst[4] // Offset
+}
+
+// EqualBuffers reports whether a and b encode the same LeafElement, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 8 bytes.
+func (p *LeafElement) EqualBuffers(a, b []byte) bool {
+	_, _ = a[7], b[7] // Bounds check hint to compiler
+	if string(a[0:8]) != string(b[0:8]) { // Key, Offset
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the LeafElement encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *LeafElement) Dump(buf []byte) string {
+	if len(buf) < 8 {
+		return fmt.Sprintf("LeafElement: %d bytes, want 8", len(buf))
+	}
+	out := []byte("LeafElement (8 bytes)\n")
+	out = fmt.Appendf(out, "0000  Key: % x\n", buf[0:4])
+	out = fmt.Appendf(out, "0004  Offset: % x\n", buf[4:8])
+	return string(out)
+}
+
+// Byte offsets and sizes of the fixed fields of LeafHeader
+const (
+	LeafHeaderNumKeysOffset  = 0
+	LeafHeaderNumKeysSize    = 2
+	LeafHeaderFlagsOffset    = 2
+	LeafHeaderFlagsSize      = 2
+	LeafHeaderNextPageOffset = 4
+	LeafHeaderNextPageSize   = 4
+	LeafHeaderPrevPageOffset = 8
+	LeafHeaderPrevPageSize   = 4
+	LeafHeaderReservedOffset = 12
+	LeafHeaderReservedSize   = 4
+)
+
 func (p *LeafHeader) MarshalLayout() ([]byte, error) {
 	buf := make([]byte, 16)
 
@@ -55,8 +207,11 @@ func (p *LeafHeader) MarshalLayout() ([]byte, error) {
 }
 
 func (p *LeafHeader) UnmarshalLayout(buf []byte) error {
-	if len(buf) != 16 {
-		return fmt.Errorf("expected 16 bytes, got %d", len(buf))
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
 	}
 
 	// NumKeys: uint16 at [0, 2)
@@ -77,31 +232,246 @@ func (p *LeafHeader) UnmarshalLayout(buf []byte) error {
 	return nil
 }
 
+// PatchLeafHeaderNumKeys writes v as the NumKeys of the LeafHeader encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafHeaderNumKeys(buf []byte, v uint16) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafHeader{NumKeys: v}
+
+	// NumKeys: uint16 at [0, 2)
+	binary.LittleEndian.PutUint16(buf[0:2], p.NumKeys)
+
+	return nil
+}
+
+// PatchLeafHeaderFlags writes v as the Flags of the LeafHeader encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafHeaderFlags(buf []byte, v uint16) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafHeader{Flags: v}
+
+	// Flags: uint16 at [2, 4)
+	binary.LittleEndian.PutUint16(buf[2:4], p.Flags)
+
+	return nil
+}
+
+// PatchLeafHeaderNextPage writes v as the NextPage of the LeafHeader encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafHeaderNextPage(buf []byte, v uint32) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafHeader{NextPage: v}
+
+	// NextPage: uint32 at [4, 8)
+	binary.LittleEndian.PutUint32(buf[4:8], p.NextPage)
+
+	return nil
+}
+
+// PatchLeafHeaderPrevPage writes v as the PrevPage of the LeafHeader encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafHeaderPrevPage(buf []byte, v uint32) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafHeader{PrevPage: v}
+
+	// PrevPage: uint32 at [8, 12)
+	binary.LittleEndian.PutUint32(buf[8:12], p.PrevPage)
+
+	return nil
+}
+
+// PatchLeafHeaderReserved writes v as the Reserved of the LeafHeader encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafHeaderReserved(buf []byte, v uint32) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafHeader{Reserved: v}
+
+	// Reserved: uint32 at [12, 16)
+	binary.LittleEndian.PutUint32(buf[12:16], p.Reserved)
+
+	return nil
+}
+
+// ScanLeafHeader reads consecutive LeafHeader records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanLeafHeader(r io.Reader, fn func(*LeafHeader) error) error {
+	p := &LeafHeader{}
+	buf := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 16 bytes at offset pageID*16, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *LeafHeader) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 576460752303423487 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 16
+	buf := make([]byte, 16)
+	n, err := f.ReadAt(buf, off)
+	if n < 16 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 16 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*16
+func (p *LeafHeader) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 576460752303423487 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 16
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded LeafHeader from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 16 bytes.
+func (p *LeafHeader) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[15] // Bounds check hint to compiler
+	copy(dst[:16], src[:16])
+	dst[0], dst[1] = dst[1], dst[0] // NumKeys
+	dst[2], dst[3] = dst[3], dst[2] // Flags
+	dst[4], dst[5], dst[6], dst[7] = dst[7], dst[6], dst[5], dst[4] // NextPage
+	dst[8], dst[9], dst[10], dst[11] = dst[11], dst[10], dst[9], dst[8] // PrevPage
+	dst[12], dst[13], dst[14], dst[15] = dst[15], dst[14], dst[13], dst[12] // Reserved
+}
+
+// EqualBuffers reports whether a and b encode the same LeafHeader, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 16 bytes.
+func (p *LeafHeader) EqualBuffers(a, b []byte) bool {
+	_, _ = a[15], b[15] // Bounds check hint to compiler
+	if string(a[0:16]) != string(b[0:16]) { // NumKeys, Flags, NextPage, PrevPage, Reserved
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the LeafHeader encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *LeafHeader) Dump(buf []byte) string {
+	if len(buf) < 16 {
+		return fmt.Sprintf("LeafHeader: %d bytes, want 16", len(buf))
+	}
+	out := []byte("LeafHeader (16 bytes)\n")
+	out = fmt.Appendf(out, "0000  NumKeys: % x\n", buf[0:2])
+	out = fmt.Appendf(out, "0002  Flags: % x\n", buf[2:4])
+	out = fmt.Appendf(out, "0004  NextPage: % x\n", buf[4:8])
+	out = fmt.Appendf(out, "0008  PrevPage: % x\n", buf[8:12])
+	out = fmt.Appendf(out, "000c  Reserved: % x\n", buf[12:16])
+	return string(out)
+}
+
+// Byte offsets and sizes of the fixed fields of LeafNode
+const (
+	LeafNodeHeaderOffset = 0
+	LeafNodeHeaderSize   = 16
+	LeafNodeFooterOffset = 4088
+	LeafNodeFooterSize   = 8
+)
+
+// Capacities of the dynamic regions of LeafNode: their bytes, and the most
+// elements of a struct slice that fit when the region holds nothing else
+const (
+	LeafNodeElementsCapacity = 4072 // bytes
+	LeafNodeMaxElements      = 509  // elements
+)
+
 func (p *LeafNode) MarshalLayout() ([]byte, error) {
 	buf := make([]byte, 4096)
 	var offset int
 
 	// Header: LeafHeader at [0, 16)
-	elemBuf, err := p.Header.MarshalLayout()
-	if err != nil {
-		return nil, fmt.Errorf("marshal Header: %w", err)
+	{ // LeafHeader inlined
+		buf := buf[0:16]
+		p := &p.Header
+		// NumKeys: uint16 at [0, 2)
+		binary.LittleEndian.PutUint16(buf[0:2], p.NumKeys)
+
+		// Flags: uint16 at [2, 4)
+		binary.LittleEndian.PutUint16(buf[2:4], p.Flags)
+
+		// NextPage: uint32 at [4, 8)
+		binary.LittleEndian.PutUint32(buf[4:8], p.NextPage)
+
+		// PrevPage: uint32 at [8, 12)
+		binary.LittleEndian.PutUint32(buf[8:12], p.PrevPage)
+
+		// Reserved: uint32 at [12, 16)
+		binary.LittleEndian.PutUint32(buf[12:16], p.Reserved)
 	}
-	copy(buf[0:16], elemBuf)
 
 	// Elements: []LeafElement at [16, 4088) with count=Header.NumKeys (element size: 8)
 	offset = 16
 	if len(p.Elements) != int(p.Header.NumKeys) {
-		return nil, fmt.Errorf("Elements length mismatch: have %d, want %d", len(p.Elements), p.Header.NumKeys)
+		return nil, fmt.Errorf("Elements length mismatch: have %d, want %d: %w", len(p.Elements), p.Header.NumKeys, ErrCountMismatch)
 	}
 	for i := range p.Elements {
 		if offset + 8 > 4088 {
-			return nil, fmt.Errorf("Elements collision at offset %d", offset)
+			return nil, fmt.Errorf("Elements collision at offset %d: %w", offset, ErrRegionOverflow)
 		}
-		elemBuf, err := p.Elements[i].MarshalLayout()
-		if err != nil {
-			return nil, fmt.Errorf("marshal Elements[%d]: %w", i, err)
+		{ // LeafElement inlined
+			buf := buf[offset:offset+8]
+			p := &p.Elements[i]
+			// Key: uint32 at [0, 4)
+			binary.LittleEndian.PutUint32(buf[0:4], p.Key)
+
+			// Offset: uint32 at [4, 8)
+			binary.LittleEndian.PutUint32(buf[4:8], p.Offset)
 		}
-		copy(buf[offset:offset+8], elemBuf)
 		offset += 8
 	}
 
@@ -112,8 +482,11 @@ func (p *LeafNode) MarshalLayout() ([]byte, error) {
 }
 
 func (p *LeafNode) UnmarshalLayout(buf []byte) error {
-	if len(buf) != 4096 {
-		return fmt.Errorf("expected 4096 bytes, got %d", len(buf))
+	if len(buf) < 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
 	}
 
 	// Header: LeafHeader at [0, 16)
@@ -122,18 +495,20 @@ func (p *LeafNode) UnmarshalLayout(buf []byte) error {
 	}
 
 	// Elements: []LeafElement at [16, 4088) with count=Header.NumKeys (element size: 8)
+	if p.Header.NumKeys > 509 {
+		return fmt.Errorf("Elements: count %d outside capacity 509: %w", p.Header.NumKeys, ErrRegionOverflow)
+	}
 	// Reuse slice if capacity allows
 	if cap(p.Elements) >= int(p.Header.NumKeys) {
 		p.Elements = p.Elements[:p.Header.NumKeys]
 	} else {
 		p.Elements = make([]LeafElement, p.Header.NumKeys)
 	}
-	offset := 16
 	for i := range p.Elements {
-		if err := p.Elements[i].UnmarshalLayout(buf[offset:offset+8]); err != nil {
+		at := 16 + i*8
+		if err := p.Elements[i].UnmarshalLayout(buf[at:at+8]); err != nil {
 			return fmt.Errorf("unmarshal Elements[%d]: %w", i, err)
 		}
-		offset += 8
 	}
 
 	// Footer: uint64 at [4088, 4096)
@@ -142,3 +517,130 @@ func (p *LeafNode) UnmarshalLayout(buf []byte) error {
 	return nil
 }
 
+// PatchLeafNodeFooter writes v as the Footer of the LeafNode encoded in buf, leaving its
+// other bytes as they are
+func PatchLeafNodeFooter(buf []byte, v uint64) error {
+	if len(buf) < 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := LeafNode{Footer: v}
+
+	// Footer: uint64 at [4088, 4096)
+	binary.LittleEndian.PutUint64(buf[4088:4096], p.Footer)
+
+	return nil
+}
+
+// ScanLeafNode reads consecutive LeafNode records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanLeafNode(r io.Reader, fn func(*LeafNode) error) error {
+	p := &LeafNode{}
+	buf := make([]byte, 4096)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 4096 bytes at offset pageID*4096, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *LeafNode) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf := make([]byte, 4096)
+	n, err := f.ReadAt(buf, off)
+	if n < 4096 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 4096 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*4096
+func (p *LeafNode) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded LeafNode from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 4096 bytes.
+func (p *LeafNode) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[4095] // Bounds check hint to compiler
+	copy(dst[:4096], src[:4096])
+	p.Header.ConvertEndian(dst[0:16], dst[0:16])
+	dst[4088], dst[4089], dst[4090], dst[4091], dst[4092], dst[4093], dst[4094], dst[4095] = dst[4095], dst[4094], dst[4093], dst[4092], dst[4091], dst[4090], dst[4089], dst[4088] // Footer
+}
+
+// EqualBuffers reports whether a and b encode the same LeafNode, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 4096 bytes.
+func (p *LeafNode) EqualBuffers(a, b []byte) bool {
+	_, _ = a[4095], b[4095] // Bounds check hint to compiler
+	if !(*LeafHeader)(nil).EqualBuffers(a[0:16], b[0:16]) { // Header
+		return false
+	}
+	if string(a[4088:4096]) != string(b[4088:4096]) { // Footer
+		return false
+	}
+	countHeaderNumKeys := int(binary.LittleEndian.Uint16(a[0:2]))
+	// Elements: countHeaderNumKeys*8 bytes in use, at most 4072
+	if n := max(min(countHeaderNumKeys*8, 4072), 0); n > 0 {
+		for at := 16; at < 16+n; at += 8 {
+			if !(*LeafElement)(nil).EqualBuffers(a[at:at+8], b[at:at+8]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Dump returns a hex dump of the LeafNode encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *LeafNode) Dump(buf []byte) string {
+	if len(buf) < 4096 {
+		return fmt.Sprintf("LeafNode: %d bytes, want 4096", len(buf))
+	}
+	out := []byte("LeafNode (4096 bytes)\n")
+	out = fmt.Appendf(out, "0000  Header: % x\n", buf[0:16])
+	out = fmt.Appendf(out, "0ff8  Footer: % x\n", buf[4088:4096])
+	countHeaderNumKeys := int(binary.LittleEndian.Uint16(buf[0:2]))
+	usedElements := max(min(countHeaderNumKeys*8, 4072), 0)
+	out = fmt.Appendf(out, "%04x  Elements: %d bytes\n", 16, usedElements)
+	for at := 16; at < 16+usedElements; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 16+usedElements)])
+	}
+	return string(out)
+}
+
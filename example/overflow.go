@@ -0,0 +1,16 @@
+package example
+
+// @layout
+type OverflowElement struct {
+	OvfPage   uint64 `layout:"@0"`
+	OvfOffset uint32 `layout:"@8"`
+	OvfSize   uint32 `layout:"@12"`
+}
+
+// @layout size=4096
+type OverflowPage struct {
+	NumElements uint16            `layout:"@0"`
+	Elements    []OverflowElement `layout:"@8,start-end,count=NumElements,max=255"`
+	Data        []byte            `layout:"end-start"`
+	Overflow    [][]byte          `layout:"extents=Elements,page=OvfPage,offset=OvfOffset,size=OvfSize"`
+}
@@ -0,0 +1,486 @@
+// Code generated by layout. DO NOT EDIT.
+
+package example
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Byte offsets and sizes of the fixed fields of OverflowElement
+const (
+	OverflowElementOvfPageOffset   = 0
+	OverflowElementOvfPageSize     = 8
+	OverflowElementOvfOffsetOffset = 8
+	OverflowElementOvfOffsetSize   = 4
+	OverflowElementOvfSizeOffset   = 12
+	OverflowElementOvfSizeSize     = 4
+)
+
+func (p *OverflowElement) MarshalLayout() ([]byte, error) {
+	buf := make([]byte, 16)
+
+	// OvfPage: uint64 at [0, 8)
+	binary.LittleEndian.PutUint64(buf[0:8], p.OvfPage)
+
+	// OvfOffset: uint32 at [8, 12)
+	binary.LittleEndian.PutUint32(buf[8:12], p.OvfOffset)
+
+	// OvfSize: uint32 at [12, 16)
+	binary.LittleEndian.PutUint32(buf[12:16], p.OvfSize)
+
+	return buf, nil
+}
+
+func (p *OverflowElement) UnmarshalLayout(buf []byte) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+
+	// OvfPage: uint64 at [0, 8)
+	p.OvfPage = binary.LittleEndian.Uint64(buf[0:8])
+
+	// OvfOffset: uint32 at [8, 12)
+	p.OvfOffset = binary.LittleEndian.Uint32(buf[8:12])
+
+	// OvfSize: uint32 at [12, 16)
+	p.OvfSize = binary.LittleEndian.Uint32(buf[12:16])
+
+	return nil
+}
+
+// PatchOverflowElementOvfPage writes v as the OvfPage of the OverflowElement encoded in buf, leaving its
+// other bytes as they are
+func PatchOverflowElementOvfPage(buf []byte, v uint64) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := OverflowElement{OvfPage: v}
+
+	// OvfPage: uint64 at [0, 8)
+	binary.LittleEndian.PutUint64(buf[0:8], p.OvfPage)
+
+	return nil
+}
+
+// PatchOverflowElementOvfOffset writes v as the OvfOffset of the OverflowElement encoded in buf, leaving its
+// other bytes as they are
+func PatchOverflowElementOvfOffset(buf []byte, v uint32) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := OverflowElement{OvfOffset: v}
+
+	// OvfOffset: uint32 at [8, 12)
+	binary.LittleEndian.PutUint32(buf[8:12], p.OvfOffset)
+
+	return nil
+}
+
+// PatchOverflowElementOvfSize writes v as the OvfSize of the OverflowElement encoded in buf, leaving its
+// other bytes as they are
+func PatchOverflowElementOvfSize(buf []byte, v uint32) error {
+	if len(buf) < 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 16 {
+		return fmt.Errorf("expected 16 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := OverflowElement{OvfSize: v}
+
+	// OvfSize: uint32 at [12, 16)
+	binary.LittleEndian.PutUint32(buf[12:16], p.OvfSize)
+
+	return nil
+}
+
+// ScanOverflowElement reads consecutive OverflowElement records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanOverflowElement(r io.Reader, fn func(*OverflowElement) error) error {
+	p := &OverflowElement{}
+	buf := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 16 bytes at offset pageID*16, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *OverflowElement) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 576460752303423487 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 16
+	buf := make([]byte, 16)
+	n, err := f.ReadAt(buf, off)
+	if n < 16 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 16 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*16
+func (p *OverflowElement) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 576460752303423487 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 16
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded OverflowElement from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 16 bytes.
+func (p *OverflowElement) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[15] // Bounds check hint to compiler
+	copy(dst[:16], src[:16])
+	dst[0], dst[1], dst[2], dst[3], dst[4], dst[5], dst[6], dst[7] = dst[7], dst[6], dst[5], dst[4], dst[3], dst[2], dst[1], dst[0] // OvfPage
+	dst[8], dst[9], dst[10], dst[11] = dst[11], dst[10], dst[9], dst[8] // OvfOffset
+	dst[12], dst[13], dst[14], dst[15] = dst[15], dst[14], dst[13], dst[12] // OvfSize
+}
+
+// EqualBuffers reports whether a and b encode the same OverflowElement, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 16 bytes.
+func (p *OverflowElement) EqualBuffers(a, b []byte) bool {
+	_, _ = a[15], b[15] // Bounds check hint to compiler
+	if string(a[0:16]) != string(b[0:16]) { // OvfPage, OvfOffset, OvfSize
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the OverflowElement encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *OverflowElement) Dump(buf []byte) string {
+	if len(buf) < 16 {
+		return fmt.Sprintf("OverflowElement: %d bytes, want 16", len(buf))
+	}
+	out := []byte("OverflowElement (16 bytes)\n")
+	out = fmt.Appendf(out, "0000  OvfPage: % x\n", buf[0:8])
+	out = fmt.Appendf(out, "0008  OvfOffset: % x\n", buf[8:12])
+	out = fmt.Appendf(out, "000c  OvfSize: % x\n", buf[12:16])
+	return string(out)
+}
+
+// Byte offsets and sizes of the fixed fields of OverflowPage
+const (
+	OverflowPageNumElementsOffset = 0
+	OverflowPageNumElementsSize   = 2
+)
+
+// Capacities of the dynamic regions of OverflowPage: their bytes, and the most
+// elements of a struct slice that fit when the region holds nothing else
+const (
+	OverflowPageElementsCapacity = 4088 // bytes
+	OverflowPageMaxElements      = 255  // elements
+	OverflowPageDataCapacity     = 4088 // bytes
+)
+
+// OverflowPageFreeSpace returns how many of the 4088 bytes shared by Elements and Data
+// are free, given the length of each (elements for Elements, bytes for Data).
+// Negative when they don't fit.
+func OverflowPageFreeSpace(elements, data int) int {
+	return 4088 - elements*16 - data
+}
+
+func (p *OverflowPage) MarshalLayout() ([]byte, error) {
+	buf := make([]byte, 4096)
+	var offset int
+
+	// Elements and Data share free space
+	if free := p.FreeBytes(); free < 0 {
+		return nil, fmt.Errorf("Elements and Data overlap by %d bytes: %w", -free, ErrRegionOverflow)
+	}
+
+	// NumElements: uint16 at [0, 2)
+	binary.LittleEndian.PutUint16(buf[0:2], p.NumElements)
+
+	// Elements: []OverflowElement at [8, 4096) with count=NumElements (element size: 16)
+	offset = 8
+	if len(p.Elements) != int(p.NumElements) {
+		return nil, fmt.Errorf("Elements length mismatch: have %d, want %d: %w", len(p.Elements), p.NumElements, ErrCountMismatch)
+	}
+	if len(p.Elements) > 255 {
+		return nil, fmt.Errorf("Elements: %d elements exceed max 255: %w", len(p.Elements), ErrRegionOverflow)
+	}
+	for i := range p.Elements {
+		if offset + 16 > 4096 {
+			return nil, fmt.Errorf("Elements collision at offset %d: %w", offset, ErrRegionOverflow)
+		}
+		{ // OverflowElement inlined
+			buf := buf[offset:offset+16]
+			p := &p.Elements[i]
+			// OvfPage: uint64 at [0, 8)
+			binary.LittleEndian.PutUint64(buf[0:8], p.OvfPage)
+
+			// OvfOffset: uint32 at [8, 12)
+			binary.LittleEndian.PutUint32(buf[8:12], p.OvfOffset)
+
+			// OvfSize: uint32 at [12, 16)
+			binary.LittleEndian.PutUint32(buf[12:16], p.OvfSize)
+		}
+		offset += 16
+	}
+
+	// Data: []byte at [4096, 8)
+	offset = 4096
+	if offset-len(p.Data) < 8 {
+		return nil, fmt.Errorf("Data collision at offset %d: %w", offset-len(p.Data), ErrRegionOverflow)
+	}
+	offset -= len(p.Data)
+	copy(buf[offset:], p.Data)
+
+	return buf, nil
+}
+
+func (p *OverflowPage) UnmarshalLayout(buf []byte) error {
+	if len(buf) < 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+
+	// NumElements: uint16 at [0, 2)
+	p.NumElements = binary.LittleEndian.Uint16(buf[0:2])
+
+	// Elements: []OverflowElement at [8, 4096) with count=NumElements (element size: 16)
+	if p.NumElements > 255 {
+		return fmt.Errorf("Elements: count %d outside capacity 255: %w", p.NumElements, ErrRegionOverflow)
+	}
+	// Reuse slice if capacity allows
+	if cap(p.Elements) >= int(p.NumElements) {
+		p.Elements = p.Elements[:p.NumElements]
+	} else {
+		p.Elements = make([]OverflowElement, p.NumElements)
+	}
+	for i := range p.Elements {
+		at := 8 + i*16
+		if err := p.Elements[i].UnmarshalLayout(buf[at:at+16]); err != nil {
+			return fmt.Errorf("unmarshal Elements[%d]: %w", i, err)
+		}
+	}
+
+	// Data: []byte at [4096, 8)
+	dataLen := 4096 - 8
+	// Reuse buffer if capacity allows
+	if cap(p.Data) >= dataLen {
+		p.Data = p.Data[:dataLen]
+	} else {
+		p.Data = make([]byte, dataLen)
+	}
+	copy(p.Data, buf[8:4096])
+
+	return nil
+}
+
+// FreeBytes returns the bytes left between Elements, growing up from 8, and Data, growing
+// down from 4096. Negative when they overlap, which MarshalLayout refuses.
+func (p *OverflowPage) FreeBytes() int {
+	return (4096 - len(p.Data)) - (8 + len(p.Elements)*16)
+}
+
+// SetNumElements sets NumElements, refusing counts beyond the region capacity of 255
+func (p *OverflowPage) SetNumElements(v uint16) error {
+	if int(v) > 255 {
+		return fmt.Errorf("SetNumElements: %d exceeds capacity 255: %w", v, ErrRegionOverflow)
+	}
+	p.NumElements = v
+	return nil
+}
+
+// ScanOverflowPage reads consecutive OverflowPage records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanOverflowPage(r io.Reader, fn func(*OverflowPage) error) error {
+	p := &OverflowPage{}
+	buf := make([]byte, 4096)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 4096 bytes at offset pageID*4096, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *OverflowPage) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf := make([]byte, 4096)
+	n, err := f.ReadAt(buf, off)
+	if n < 4096 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 4096 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*4096
+func (p *OverflowPage) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded OverflowPage from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 4096 bytes.
+func (p *OverflowPage) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[4095] // Bounds check hint to compiler
+	copy(dst[:4096], src[:4096])
+	dst[0], dst[1] = dst[1], dst[0] // NumElements
+}
+
+// EqualBuffers reports whether a and b encode the same OverflowPage, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 4096 bytes.
+func (p *OverflowPage) EqualBuffers(a, b []byte) bool {
+	_, _ = a[4095], b[4095] // Bounds check hint to compiler
+	if string(a[0:2]) != string(b[0:2]) { // NumElements
+		return false
+	}
+	countNumElements := int(binary.LittleEndian.Uint16(a[0:2]))
+	// Elements: countNumElements*16 bytes in use, at most 4080
+	if n := max(min(countNumElements*16, 4080), 0); n > 0 {
+		for at := 8; at < 8+n; at += 16 {
+			if !(*OverflowElement)(nil).EqualBuffers(a[at:at+16], b[at:at+16]) {
+				return false
+			}
+		}
+	}
+	if string(a[8:4096]) != string(b[8:4096]) { // Data, compared whole
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the OverflowPage encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *OverflowPage) Dump(buf []byte) string {
+	if len(buf) < 4096 {
+		return fmt.Sprintf("OverflowPage: %d bytes, want 4096", len(buf))
+	}
+	out := []byte("OverflowPage (4096 bytes)\n")
+	out = fmt.Appendf(out, "0000  NumElements: % x\n", buf[0:2])
+	countNumElements := int(binary.LittleEndian.Uint16(buf[0:2]))
+	usedElements := max(min(countNumElements*16, 4080), 0)
+	out = fmt.Appendf(out, "%04x  Elements: %d bytes\n", 8, usedElements)
+	for at := 8; at < 8+usedElements; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 8+usedElements)])
+	}
+	usedData := 4088
+	out = fmt.Appendf(out, "%04x  Data: %d bytes\n", 4096-usedData, usedData)
+	for at := 4096-usedData; at < 4096; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 4096)])
+	}
+	return string(out)
+}
+
+// OverflowAt returns element i of Overflow: Elements[i].OvfSize bytes at Elements[i].OvfOffset of page Elements[i].OvfPage,
+// continuing into the following pages when it runs past the end of one. fetch
+// returns the bytes of a page, or nil if there is none.
+func (p *OverflowPage) OverflowAt(i int, fetch func(pageID uint64) []byte) ([]byte, error) {
+	elem := &p.Elements[i]
+	page := uint64(elem.OvfPage)
+	offset, size := int(elem.OvfOffset), int(elem.OvfSize)
+	data := fetch(page)
+	if offset < 0 || size < 0 || offset > len(data) {
+		return nil, fmt.Errorf("Overflow[%d]: offset %d outside page %d of %d bytes: %w", i, offset, page, len(data), ErrRegionOverflow)
+	}
+	if offset+size <= len(data) {
+		return data[offset : offset+size], nil
+	}
+
+	// Spans pages: gather the pieces as they arrive, so a corrupt size allocates
+	// no more than the pages fetched hold
+	out := append([]byte(nil), data[offset:]...)
+	for len(out) < size {
+		page++
+		data = fetch(page)
+		if len(data) == 0 {
+			return nil, fmt.Errorf("Overflow[%d]: page %d empty with %d of %d bytes read: %w", i, page, len(out), size, ErrShortBuffer)
+		}
+		out = append(out, data[:min(len(data), size-len(out))]...)
+	}
+	return out, nil
+}
+
+// LoadOverflow resolves every element of Overflow through fetch, see OverflowAt
+func (p *OverflowPage) LoadOverflow(fetch func(pageID uint64) []byte) error {
+	if cap(p.Overflow) >= len(p.Elements) {
+		p.Overflow = p.Overflow[:len(p.Elements)]
+	} else {
+		p.Overflow = make([][]byte, len(p.Elements))
+	}
+	for i := range p.Elements {
+		data, err := p.OverflowAt(i, fetch)
+		if err != nil {
+			return err
+		}
+		p.Overflow[i] = data
+	}
+	return nil
+}
+
@@ -0,0 +1,55 @@
+package example
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// overflowPages returns a fetch over pages of 16 bytes, numbered from 1, each
+// holding its ID in every byte
+func overflowPages(n int) func(pageID uint64) []byte {
+	return func(pageID uint64) []byte {
+		if pageID < 1 || pageID > uint64(n) {
+			return nil
+		}
+		return bytes.Repeat([]byte{byte(pageID)}, 16)
+	}
+}
+
+func TestOverflowAt(t *testing.T) {
+	page := &OverflowPage{Elements: []OverflowElement{
+		{OvfPage: 1, OvfOffset: 4, OvfSize: 8},  // Within page 1
+		{OvfPage: 2, OvfOffset: 12, OvfSize: 8}, // Runs on into page 3
+	}}
+	fetch := overflowPages(3)
+
+	if err := page.LoadOverflow(fetch); err != nil {
+		t.Fatalf("LoadOverflow() error: %v", err)
+	}
+	if want := bytes.Repeat([]byte{1}, 8); !bytes.Equal(page.Overflow[0], want) {
+		t.Errorf("Overflow[0] = %v, want %v", page.Overflow[0], want)
+	}
+	if want := []byte{2, 2, 2, 2, 3, 3, 3, 3}; !bytes.Equal(page.Overflow[1], want) {
+		t.Errorf("Overflow[1] = %v, want %v", page.Overflow[1], want)
+	}
+}
+
+func TestOverflowAtCorruptSize(t *testing.T) {
+	// A size of 4 GiB read from a corrupt page, with two pages behind it, fails
+	// once they run out, having allocated no more than they hold
+	page := &OverflowPage{Elements: []OverflowElement{{OvfPage: 1, OvfSize: 1<<32 - 1}}}
+	fetch := overflowPages(2)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := page.OverflowAt(0, fetch)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrShortBuffer) {
+		t.Fatalf("OverflowAt() error = %v, want ErrShortBuffer", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("OverflowAt() allocated %d bytes for two 16-byte pages", allocated)
+	}
+}
@@ -3,11 +3,25 @@
 package example
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"unsafe"
 )
 
+// Byte offsets and sizes of the fixed fields of PageCustomAllocator
+const (
+	PageCustomAllocatorHeaderOffset = 0
+	PageCustomAllocatorHeaderSize   = 2
+	PageCustomAllocatorFooterOffset = 4088
+	PageCustomAllocatorFooterSize   = 8
+)
+
+// Capacities in bytes of the dynamic regions of PageCustomAllocator
+const (
+	PageCustomAllocatorBodyCapacity = 4086 // bytes
+)
+
 func NewPageCustomAllocator() *PageCustomAllocator {
 	p := &PageCustomAllocator{}
 	// IMPORTANT: AllocateAlignedPage() must return a buffer of at least 4607 bytes
@@ -72,11 +86,14 @@ func (p *PageCustomAllocator) MarshalLayout() ([]byte, error) {
 }
 
 func (p *PageCustomAllocator) UnmarshalLayout(buf []byte) error {
-	// Zero-copy mode: copy buf into p.buf if different
-	if len(buf) > 0 && len(p.buf) > 0 {
-		if &buf[0] != &p.buf[0] {
-			copy(p.buf, buf)
+	// Zero-copy mode: copy buf into p.buf unless it already is p.buf
+	if len(buf) > 0 && len(p.buf) > 0 && &buf[0] != &p.buf[0] {
+		src := uintptr(unsafe.Pointer(&buf[0]))
+		dst := uintptr(unsafe.Pointer(&p.buf[0]))
+		if src < dst+uintptr(len(p.buf)) && dst < src+uintptr(len(buf)) {
+			return fmt.Errorf("UnmarshalLayout: buf overlaps p.buf at a different offset: %w", ErrBadBuffer)
 		}
+		copy(p.buf[:], buf)
 	}
 
 	// Header: uint16 at [0, 2)
@@ -91,18 +108,227 @@ func (p *PageCustomAllocator) UnmarshalLayout(buf []byte) error {
 	return nil
 }
 
-func (p *PageCustomAllocator) LoadFrom(r io.Reader) error {
-	if _, err := io.ReadFull(r, p.buf[:]); err != nil {
-		return err
+// SetBuffer makes buf the backing buffer of PageCustomAllocator without copying. Accessors and
+// MarshalLayout write through to buf, so the caller must not reuse it while p is live.
+func (p *PageCustomAllocator) SetBuffer(buf []byte) error {
+	if len(buf) < 4096 {
+		return fmt.Errorf("SetBuffer: expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("SetBuffer: expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
 	}
+	if uintptr(unsafe.Pointer(&buf[0]))%512 != 0 {
+		return fmt.Errorf("SetBuffer: buf is not 512-byte aligned: %w", ErrBadBuffer)
+	}
+	p.buf = buf
 	return p.UnmarshalLayout(p.buf)
 }
 
-func (p *PageCustomAllocator) WriteTo(w io.Writer) error {
+// DirectBuffer returns the buffer of PageCustomAllocator for direct I/O (O_DIRECT), such as
+// syscall.Pread into it. Its address and length are multiples of 512 bytes.
+func (p *PageCustomAllocator) DirectBuffer() ([]byte, error) {
+	if len(p.buf) == 0 {
+		return nil, fmt.Errorf("DirectBuffer: no buffer, create PageCustomAllocator with NewPageCustomAllocator: %w", ErrBadBuffer)
+	}
+	if uintptr(unsafe.Pointer(&p.buf[0]))%512 != 0 {
+		return nil, fmt.Errorf("DirectBuffer: buffer is not 512-byte aligned: %w", ErrBadBuffer)
+	}
+	if len(p.buf)%512 != 0 {
+		return nil, fmt.Errorf("DirectBuffer: %d-byte buffer is not a multiple of 512 bytes: %w", len(p.buf), ErrBadBuffer)
+	}
+	return p.buf, nil
+}
+
+// loadFrom reads one 4096-byte PageCustomAllocator from r into the buffer and decodes it. It
+// returns io.EOF if r ends before the page, and ErrTruncatedPage if partway
+// through it.
+func (p *PageCustomAllocator) loadFrom(r io.Reader) (int, error) {
+	n, err := io.ReadFull(r, p.buf[:])
+	if err == io.ErrUnexpectedEOF {
+		return n, fmt.Errorf("ReadFrom: read %d of 4096 bytes: %w", n, ErrTruncatedPage)
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, p.UnmarshalLayout(p.buf[:])
+}
+
+// ReadFrom reads one 4096-byte PageCustomAllocator from r into the buffer and decodes it.
+// It implements io.ReaderFrom, but stops after the page rather than at EOF. As
+// io.Copy expects, r ending before the page returns 0, nil and leaves p as it
+// was; r ending partway through it fails with ErrTruncatedPage.
+func (p *PageCustomAllocator) ReadFrom(r io.Reader) (int64, error) {
+	n, err := p.loadFrom(r)
+	if err == io.EOF {
+		return 0, nil
+	}
+	return int64(n), err
+}
+
+// LoadFromAt reads the 4096-byte PageCustomAllocator at offset off of r into the buffer and
+// decodes it. It returns io.EOF if off is at or past the end of r, and
+// ErrTruncatedPage if r ends partway through the page.
+func (p *PageCustomAllocator) LoadFromAt(r io.ReaderAt, off int64) error {
+	n, err := r.ReadAt(p.buf[:], off)
+	if n < 4096 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("LoadFromAt: read %d of 4096 bytes at offset %d: %w", n, off, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(p.buf[:])
+}
+
+// WriteTo encodes p into its buffer and writes the buffer to w. It implements
+// io.WriterTo.
+func (p *PageCustomAllocator) WriteTo(w io.Writer) (int64, error) {
 	if _, err := p.MarshalLayout(); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(p.buf[:])
+	return int64(n), err
+}
+
+// LoadFrom is ReadFrom without the byte count, returning io.EOF if r ends
+// before the page, so a loop over a stream of pages knows where it stops
+func (p *PageCustomAllocator) LoadFrom(r io.Reader) error {
+	_, err := p.loadFrom(r)
+	return err
+}
+
+// SaveTo is WriteTo without the byte count
+func (p *PageCustomAllocator) SaveTo(w io.Writer) error {
+	_, err := p.WriteTo(w)
+	return err
+}
+
+// ScanPageCustomAllocator reads consecutive PageCustomAllocator records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanPageCustomAllocator(r io.Reader, fn func(*PageCustomAllocator) error) error {
+	p := NewPageCustomAllocator()
+	for {
+		if err := p.LoadFrom(r); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 4096 bytes at offset pageID*4096, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *PageCustomAllocator) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	return p.LoadFromAt(f, off)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*4096
+func (p *PageCustomAllocator) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf, err := p.MarshalLayout()
+	if err != nil {
 		return err
 	}
-	_, err := w.Write(p.buf[:])
+	_, err = f.WriteAt(buf, off)
 	return err
 }
 
+// ConvertEndian copies an encoded PageCustomAllocator from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 4096 bytes.
+func (p *PageCustomAllocator) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[4095] // Bounds check hint to compiler
+	copy(dst[:4096], src[:4096])
+	dst[0], dst[1] = dst[1], dst[0] // Header
+	dst[4088], dst[4089], dst[4090], dst[4091], dst[4092], dst[4093], dst[4094], dst[4095] = dst[4095], dst[4094], dst[4093], dst[4092], dst[4091], dst[4090], dst[4089], dst[4088] // Footer
+}
+
+// EqualBuffers reports whether a and b encode the same PageCustomAllocator, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 4096 bytes.
+func (p *PageCustomAllocator) EqualBuffers(a, b []byte) bool {
+	_, _ = a[4095], b[4095] // Bounds check hint to compiler
+	if string(a[0:2]) != string(b[0:2]) { // Header
+		return false
+	}
+	if string(a[4088:4096]) != string(b[4088:4096]) { // Footer
+		return false
+	}
+	if string(a[2:4088]) != string(b[2:4088]) { // Body, compared whole
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the PageCustomAllocator encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *PageCustomAllocator) Dump(buf []byte) string {
+	if len(buf) < 4096 {
+		return fmt.Sprintf("PageCustomAllocator: %d bytes, want 4096", len(buf))
+	}
+	out := []byte("PageCustomAllocator (4096 bytes)\n")
+	out = fmt.Appendf(out, "0000  Header: % x\n", buf[0:2])
+	out = fmt.Appendf(out, "0ff8  Footer: % x\n", buf[4088:4096])
+	usedBody := 4086
+	out = fmt.Appendf(out, "%04x  Body: %d bytes\n", 2, usedBody)
+	for at := 2; at < 2+usedBody; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 2+usedBody)])
+	}
+	return string(out)
+}
+
+// pageCustomAllocatorJSON is the JSON form of PageCustomAllocator, filled from its buffer
+type pageCustomAllocatorJSON struct {
+	Header uint16
+	Body   []byte
+	Footer uint64
+}
+
+// MarshalJSON encodes the PageCustomAllocator held in the buffer, read through its accessors
+func (p *PageCustomAllocator) MarshalJSON() ([]byte, error) {
+	if len(p.buf) < 4096 {
+		return nil, fmt.Errorf("MarshalJSON: buffer of %d bytes, want 4096: %w", len(p.buf), ErrShortBuffer)
+	}
+	v := pageCustomAllocatorJSON{
+		Header: p.GetHeader(),
+		Footer: p.GetFooter(),
+	}
+	v.Body = p.buf[2:4088]
+	return json.Marshal(&v)
+}
+
+// UnmarshalJSON writes the PageCustomAllocator encoded in data into the buffer through its
+// accessors, then decodes the buffer as UnmarshalLayout does
+func (p *PageCustomAllocator) UnmarshalJSON(data []byte) error {
+	if len(p.buf) < 4096 {
+		return fmt.Errorf("UnmarshalJSON: buffer of %d bytes, want 4096: %w", len(p.buf), ErrShortBuffer)
+	}
+	var v pageCustomAllocatorJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.SetHeader(v.Header)
+	p.SetFooter(v.Footer)
+	if len(v.Body) > 4086 {
+		return fmt.Errorf("UnmarshalJSON: Body: %d bytes exceeds capacity 4086: %w", len(v.Body), ErrRegionOverflow)
+	}
+	clear(p.buf[2+copy(p.buf[2:4088], v.Body) : 4088])
+	return p.UnmarshalLayout(p.buf[:])
+}
+
@@ -5,23 +5,35 @@ package example
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
+)
+
+// Byte offsets and sizes of the fixed fields of Page
+const (
+	PageHeaderOffset = 0
+	PageHeaderSize   = 2
+	PageFooterOffset = 4088
+	PageFooterSize   = 8
+)
+
+// Capacities in bytes of the dynamic regions of Page
+const (
+	PageBodyCapacity = 4086 // bytes
 )
 
 func (p *Page) MarshalLayout() ([]byte, error) {
 	buf := make([]byte, 4096)
+	var offset int
 
 	// Header: uint16 at [0, 2)
 	binary.LittleEndian.PutUint16(buf[0:2], p.Header)
 
 	// Body: []byte at [2, 4088)
-	offset := 2
-	for i := range p.Body {
-		if offset >= 4088 {
-			return nil, fmt.Errorf("Body collision at offset %d", offset)
-		}
-		buf[offset] = p.Body[i]
-		offset++
+	offset = 2
+	if offset+len(p.Body) > 4088 {
+		return nil, fmt.Errorf("Body collision at offset %d: %w", offset, ErrRegionOverflow)
 	}
+	offset += copy(buf[offset:], p.Body)
 
 	// Footer: uint64 at [4088, 4096)
 	binary.LittleEndian.PutUint64(buf[4088:4096], p.Footer)
@@ -30,20 +42,23 @@ func (p *Page) MarshalLayout() ([]byte, error) {
 }
 
 func (p *Page) UnmarshalLayout(buf []byte) error {
-	if len(buf) != 4096 {
-		return fmt.Errorf("expected 4096 bytes, got %d", len(buf))
+	if len(buf) < 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
 	}
 
 	// Header: uint16 at [0, 2)
 	p.Header = binary.LittleEndian.Uint16(buf[0:2])
 
 	// Body: []byte at [2, 4088)
-	bLen := 4088 - 2
+	bodyLen := 4088 - 2
 	// Reuse buffer if capacity allows
-	if cap(p.Body) >= bLen {
-		p.Body = p.Body[:bLen]
+	if cap(p.Body) >= bodyLen {
+		p.Body = p.Body[:bodyLen]
 	} else {
-		p.Body = make([]byte, bLen)
+		p.Body = make([]byte, bodyLen)
 	}
 	copy(p.Body, buf[2:4088])
 
@@ -53,3 +68,140 @@ func (p *Page) UnmarshalLayout(buf []byte) error {
 	return nil
 }
 
+// PatchPageHeader writes v as the Header of the Page encoded in buf, leaving its
+// other bytes as they are
+func PatchPageHeader(buf []byte, v uint16) error {
+	if len(buf) < 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := Page{Header: v}
+
+	// Header: uint16 at [0, 2)
+	binary.LittleEndian.PutUint16(buf[0:2], p.Header)
+
+	return nil
+}
+
+// PatchPageFooter writes v as the Footer of the Page encoded in buf, leaving its
+// other bytes as they are
+func PatchPageFooter(buf []byte, v uint64) error {
+	if len(buf) < 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrShortBuffer)
+	}
+	if len(buf) > 4096 {
+		return fmt.Errorf("expected 4096 bytes, got %d: %w", len(buf), ErrLongBuffer)
+	}
+	p := Page{Footer: v}
+
+	// Footer: uint64 at [4088, 4096)
+	binary.LittleEndian.PutUint64(buf[4088:4096], p.Footer)
+
+	return nil
+}
+
+// ScanPage reads consecutive Page records from r until it is exhausted, calling fn
+// with each. The value passed to fn is reused for the next record, so fn must copy
+// anything it keeps. Scanning stops at the first error from r, decoding or fn.
+func ScanPage(r io.Reader, fn func(*Page) error) error {
+	p := &Page{}
+	buf := make([]byte, 4096)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				return nil // Clean end between records
+			}
+			return err
+		}
+		if err := p.UnmarshalLayout(buf); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAt reads page pageID of f, the 4096 bytes at offset pageID*4096, and decodes
+// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a
+// page f ends partway through.
+func (p *Page) ReadAt(f io.ReaderAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("ReadAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf := make([]byte, 4096)
+	n, err := f.ReadAt(buf, off)
+	if n < 4096 {
+		switch {
+		case n == 0 && err == io.EOF:
+			return io.EOF
+		case err == nil || err == io.EOF:
+			return fmt.Errorf("ReadAt: read %d of 4096 bytes of page %d: %w", n, pageID, ErrTruncatedPage)
+		}
+		return err
+	}
+	return p.UnmarshalLayout(buf)
+}
+
+// WriteAt encodes p and writes it as page pageID of f, at offset pageID*4096
+func (p *Page) WriteAt(f io.WriterAt, pageID uint64) error {
+	if pageID > 2251799813685247 {
+		return fmt.Errorf("WriteAt: page %d has no int64 offset: %w", pageID, ErrOutOfRange)
+	}
+	off := int64(pageID) * 4096
+	buf, err := p.MarshalLayout()
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, off)
+	return err
+}
+
+// ConvertEndian copies an encoded Page from src to dst, byte-swapping multi-byte
+// fixed fields between little and big endian. dst and src may overlap exactly.
+// Panics if either buffer is shorter than 4096 bytes.
+func (p *Page) ConvertEndian(dst []byte, src []byte) {
+	_ = dst[4095] // Bounds check hint to compiler
+	copy(dst[:4096], src[:4096])
+	dst[0], dst[1] = dst[1], dst[0] // Header
+	dst[4088], dst[4089], dst[4090], dst[4091], dst[4092], dst[4093], dst[4094], dst[4095] = dst[4095], dst[4094], dst[4093], dst[4092], dst[4091], dst[4090], dst[4089], dst[4088] // Footer
+}
+
+// EqualBuffers reports whether a and b encode the same Page, comparing its fields
+// and the used part of its regions but not gaps, reserved ranges or free space.
+// p is not read and may be nil. Panics if either buffer is shorter than 4096 bytes.
+func (p *Page) EqualBuffers(a, b []byte) bool {
+	_, _ = a[4095], b[4095] // Bounds check hint to compiler
+	if string(a[0:2]) != string(b[0:2]) { // Header
+		return false
+	}
+	if string(a[4088:4096]) != string(b[4088:4096]) { // Footer
+		return false
+	}
+	if string(a[2:4088]) != string(b[2:4088]) { // Body, compared whole
+		return false
+	}
+	return true
+}
+
+// Dump returns a hex dump of the Page encoded in buf, for logs and debugging: its
+// fields and the used part of its regions, at their offsets. Sensitive fields and
+// regions are redacted, and free space isn't shown. p is not read and may be nil.
+func (p *Page) Dump(buf []byte) string {
+	if len(buf) < 4096 {
+		return fmt.Sprintf("Page: %d bytes, want 4096", len(buf))
+	}
+	out := []byte("Page (4096 bytes)\n")
+	out = fmt.Appendf(out, "0000  Header: % x\n", buf[0:2])
+	out = fmt.Appendf(out, "0ff8  Footer: % x\n", buf[4088:4096])
+	usedBody := 4086
+	out = fmt.Appendf(out, "%04x  Body: %d bytes\n", 2, usedBody)
+	for at := 2; at < 2+usedBody; at += 16 {
+		out = fmt.Appendf(out, "%04x    % x\n", at, buf[at:min(at+16, 2+usedBody)])
+	}
+	return string(out)
+}
+
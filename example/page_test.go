@@ -0,0 +1,203 @@
+package example
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+)
+
+// Zerocopy pages satisfy the standard I/O interfaces
+var (
+	_ io.ReaderFrom = (*PageZeroCopy)(nil)
+	_ io.WriterTo   = (*PageZeroCopy)(nil)
+)
+
+// newBenchPage returns a page whose Body fills its 4086 bytes, between Header and
+// Footer of a 4K page
+func newBenchPage() *Page {
+	body := make([]byte, 4086)
+	for i := range body {
+		body[i] = byte(i)
+	}
+	return &Page{Header: 0x1234, Body: body, Footer: 0xDEADBEEFCAFEBABE}
+}
+
+func TestPageMarshalUnmarshal(t *testing.T) {
+	page := newBenchPage()
+	buf, err := page.MarshalLayout()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !bytes.Equal(buf[2:4088], page.Body) {
+		t.Fatal("Body not encoded at [2, 4088)")
+	}
+
+	page2 := &Page{}
+	if err := page2.UnmarshalLayout(buf); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if page2.Header != page.Header || page2.Footer != page.Footer || !bytes.Equal(page2.Body, page.Body) {
+		t.Errorf("Round trip mismatch: got Header=%#x Footer=%#x", page2.Header, page2.Footer)
+	}
+
+	page.Body = append(page.Body, 0)
+	if _, err := page.MarshalLayout(); err == nil {
+		t.Error("Expected collision error for a Body past Footer")
+	}
+}
+
+func TestPagePatchFooter(t *testing.T) {
+	page := newBenchPage()
+	buf, err := page.MarshalLayout()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if err := PatchPageFooter(buf, 42); err != nil {
+		t.Fatalf("PatchPageFooter() error: %v", err)
+	}
+	if buf[PageFooterOffset] != 42 {
+		t.Errorf("buf[PageFooterOffset] = %d, want 42", buf[PageFooterOffset])
+	}
+
+	page2 := &Page{}
+	if err := page2.UnmarshalLayout(buf); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if page2.Footer != 42 || page2.Header != page.Header || !bytes.Equal(page2.Body, page.Body) {
+		t.Errorf("Patched page: Header=%#x Footer=%d, want %#x and 42", page2.Header, page2.Footer, page.Header)
+	}
+	if err := PatchPageFooter(buf[:PageFooterOffset], 1); !errors.Is(err, ErrShortBuffer) {
+		t.Errorf("PatchPageFooter() of a short buffer = %v, want ErrShortBuffer", err)
+	}
+}
+
+func TestPageZeroCopyReadWrite(t *testing.T) {
+	page := &PageZeroCopy{Header: 0x1234, Footer: 0xDEADBEEFCAFEBABE}
+
+	var disk bytes.Buffer
+	n, err := page.WriteTo(&disk)
+	if err != nil || n != 4096 {
+		t.Fatalf("WriteTo() = %d, %v, want 4096 bytes", n, err)
+	}
+
+	page2 := &PageZeroCopy{}
+	if n, err := page2.ReadFrom(&disk); err != nil || n != 4096 {
+		t.Fatalf("ReadFrom() = %d, %v, want 4096 bytes", n, err)
+	}
+	if page2.Header != 0x1234 || page2.Footer != 0xDEADBEEFCAFEBABE {
+		t.Errorf("Round trip mismatch: got Header=%#x Footer=%#x", page2.Header, page2.Footer)
+	}
+	if err := page2.LoadFrom(&disk); err != io.EOF {
+		t.Errorf("LoadFrom() past the last page = %v, want io.EOF", err)
+	}
+
+	// io.ReaderFrom reports reaching EOF as success, leaving the page as it was
+	if n, err := page2.ReadFrom(&disk); err != nil || n != 0 {
+		t.Errorf("ReadFrom() past the last page = %d, %v, want 0, nil", n, err)
+	}
+	if page2.Header != 0x1234 {
+		t.Errorf("ReadFrom() past the last page changed Header to %#x", page2.Header)
+	}
+}
+
+func TestPageZeroCopyLoadFromAt(t *testing.T) {
+	page := &PageZeroCopy{Header: 0x1234}
+	var disk bytes.Buffer
+	for range 2 {
+		if err := page.SaveTo(&disk); err != nil {
+			t.Fatalf("SaveTo() error: %v", err)
+		}
+		page.Header++
+	}
+	file := bytes.NewReader(disk.Bytes()[:2*4096-100]) // Second page cut short
+
+	page2 := &PageZeroCopy{}
+	if err := page2.LoadFromAt(file, 0); err != nil || page2.Header != 0x1234 {
+		t.Fatalf("LoadFromAt(0) = %v, Header=%#x, want 0x1234", err, page2.Header)
+	}
+	if err := page2.LoadFromAt(file, 4096); !errors.Is(err, ErrTruncatedPage) {
+		t.Errorf("LoadFromAt(4096) = %v, want ErrTruncatedPage", err)
+	}
+	if err := page2.LoadFromAt(file, 2*4096); err != io.EOF {
+		t.Errorf("LoadFromAt(8192) = %v, want io.EOF", err)
+	}
+	if _, err := page2.ReadFrom(bytes.NewReader(disk.Bytes()[:100])); !errors.Is(err, ErrTruncatedPage) {
+		t.Errorf("ReadFrom() of 100 bytes = %v, want ErrTruncatedPage", err)
+	}
+}
+
+func TestPageReadWriteAt(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "pages"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for id := range uint64(3) {
+		page := &Page{Header: uint16(id), Footer: id * 100}
+		if err := page.WriteAt(f, id); err != nil {
+			t.Fatalf("WriteAt(%d) error: %v", id, err)
+		}
+	}
+
+	page := &Page{}
+	if err := page.ReadAt(f, 1); err != nil || page.Header != 1 || page.Footer != 100 {
+		t.Errorf("ReadAt(1) = %v, Header=%d Footer=%d, want 1 and 100", err, page.Header, page.Footer)
+	}
+	if err := page.ReadAt(f, 3); err != io.EOF {
+		t.Errorf("ReadAt(3) = %v, want io.EOF", err)
+	}
+
+	zpage := &PageZeroCopy{Header: 7}
+	if err := zpage.WriteAt(f, 4); err != nil {
+		t.Fatalf("WriteAt(4) error: %v", err)
+	}
+	if err := zpage.ReadAt(f, 3); err != nil || zpage.Header != 0 {
+		t.Errorf("ReadAt(3) of the hole before page 4 = %v, Header=%d, want a zero page", err, zpage.Header)
+	}
+}
+
+func TestPageAlignedDirectBuffer(t *testing.T) {
+	page := NewPageAligned()
+	buf, err := page.DirectBuffer()
+	if err != nil {
+		t.Fatalf("DirectBuffer() error: %v", err)
+	}
+	if len(buf) != 4096 || uintptr(unsafe.Pointer(&buf[0]))%512 != 0 {
+		t.Errorf("DirectBuffer() = %d bytes at %p, want 4096 bytes 512-byte aligned", len(buf), &buf[0])
+	}
+
+	if _, err := (&PageAligned{}).DirectBuffer(); !errors.Is(err, ErrBadBuffer) {
+		t.Errorf("DirectBuffer() without New = %v, want ErrBadBuffer", err)
+	}
+}
+
+func BenchmarkPageMarshal(b *testing.B) {
+	page := newBenchPage()
+	b.SetBytes(4096)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := page.MarshalLayout(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPageUnmarshal(b *testing.B) {
+	buf, err := newBenchPage().MarshalLayout()
+	if err != nil {
+		b.Fatal(err)
+	}
+	page := &Page{}
+	b.SetBytes(4096)
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := page.UnmarshalLayout(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateAccess checks the register fields of a layout, marked access=: they
+// exist to drop zerocopy accessors, and each accessor must read or write the
+// register whole, with a single naturally aligned load or store. Generated code
+// that reads or writes the field through its accessors rules out count fields
+// and write-only magics.
+func validateAccess(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	counts := make(map[string]bool)
+	for _, field := range layout.Fields {
+		if field.Layout != nil && field.Layout.CountField != "" {
+			counts[field.Layout.CountField] = true
+		}
+	}
+
+	for _, region := range a.Regions {
+		field := region.Field
+		if region.Kind != FixedRegion || field.Layout.Access == "" {
+			continue
+		}
+		access := field.Layout.Access
+		if layout.Anno.Mode != "zerocopy" {
+			return fmt.Errorf("field '%s': access=%s requires mode=zerocopy, other modes have no accessors to drop", field.Name, access)
+		}
+		resolved := registry.ResolveType(field.GoType)
+		if resolved == "byte" {
+			resolved = "uint8"
+		}
+		if !isCountType(resolved) {
+			return fmt.Errorf("field '%s': access=%s requires an integer field, got: %s", field.Name, access, field.GoType)
+		}
+		if field.Layout.Overlap {
+			return fmt.Errorf("field '%s': access=%s cannot be combined with overlap=allow", field.Name, access)
+		}
+		if counts[field.Name] {
+			return fmt.Errorf("field '%s': access=%s cannot mark a count field, which the region accessors read and write", field.Name, access)
+		}
+		if access == parser.AccessWriteOnly && field.Layout.Magic {
+			return fmt.Errorf("field '%s': access=%s cannot have magic=, which is checked by reading it back", field.Name, access)
+		}
+		if region.Misaligned {
+			size, _ := SizeOf(resolved)
+			return fmt.Errorf("field '%s': access=%s registers are loaded and stored whole, which requires @%d and align= to be multiples of %d",
+				field.Name, access, region.Start, size)
+		}
+	}
+	return nil
+}
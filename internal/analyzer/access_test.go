@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Access(t *testing.T) {
+	// @layout size=32 mode=<mode> align=<align>
+	// type UART struct {
+	//     N       uint8   `layout:"@0"`
+	//     <field> <type>  `layout:"@<offset>,access=<access>"`
+	//     Body    []byte  `layout:"@16,start-end,count=N"`
+	// }
+	uart := func(mode string, align int, field parser.Field) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "UART",
+			Anno: &parser.TypeAnnotation{Size: 32, Mode: mode, Align: align},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				field,
+				{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+			},
+		}
+	}
+	register := func(name, goType string, offset int, access string) parser.Field {
+		return parser.Field{Name: name, GoType: goType, Layout: &parser.FieldLayout{Offset: offset, Direction: parser.Fixed, Access: access}}
+	}
+	magic := register("ID", "uint32", 4, parser.AccessWriteOnly)
+	magic.Layout.Default, magic.Layout.Magic = "0x55", true
+	alias := register("Alias", "uint8", 0, parser.AccessReadOnly)
+	alias.Layout.Overlap = true
+	counted := uart("zerocopy", 0, register("Len", "uint8", 1, parser.AccessReadOnly))
+	counted.Fields[2].Layout.CountField = "Len"
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"read-only", uart("zerocopy", 8, register("Status", "uint32", 4, parser.AccessReadOnly)), ""},
+		{"write-only byte", uart("zerocopy", 0, register("Cmd", "uint8", 1, parser.AccessWriteOnly)), ""},
+		{"alias type", uart("zerocopy", 8, register("Ctrl", "Reg", 8, parser.AccessReadWrite)), ""},
+		{"copy mode", uart("copy", 0, register("Status", "uint32", 4, parser.AccessReadOnly)),
+			"field 'Status': access=ro requires mode=zerocopy, other modes have no accessors to drop"},
+		{"float", uart("zerocopy", 8, register("Temp", "float32", 4, parser.AccessReadOnly)),
+			"field 'Temp': access=ro requires an integer field, got: float32"},
+		{"unaligned buffer", uart("zerocopy", 0, register("Status", "uint32", 4, parser.AccessReadOnly)),
+			"field 'Status': access=ro registers are loaded and stored whole, which requires @4 and align= to be multiples of 4"},
+		{"unaligned offset", uart("zerocopy", 8, register("Status", "uint32", 6, parser.AccessReadOnly)),
+			"requires @6 and align= to be multiples of 4"},
+		{"count field", counted, "field 'Len': access=ro cannot mark a count field"},
+		{"write-only magic", uart("zerocopy", 8, magic), "field 'ID': access=wo cannot have magic="},
+		{"alias", uart("zerocopy", 0, alias), "field 'Alias': access=ro cannot be combined with overlap=allow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewTypeRegistry()
+			reg.RegisterAlias("Reg", "uint64")
+			analyzed, err := Analyze(tt.layout, reg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+			} else if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package analyzer
 
 import (
 	"fmt"
+	"go/token"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/alexhholmes/layout/internal/parser"
@@ -10,13 +12,47 @@ import (
 
 // Region represents a memory region in the layout
 type Region struct {
-	Kind        RegionKind
-	Start       int // Byte offset where region begins
-	Boundary    int // Byte offset where region must stop (-1 if end of buffer)
-	Direction   parser.PackDirection
-	Field       parser.Field // The field occupying this region
-	ElementSize int          // Size of each element (for []StructType), 1 for []byte, 0 for fixed fields
-	ElementType string       // Type name of slice elements (e.g., "LeafElement" for []LeafElement)
+	Kind          RegionKind
+	Start         int // Byte offset where region begins
+	Boundary      int // Byte offset where region must stop (-1 if end of buffer)
+	Direction     parser.PackDirection
+	Field         parser.Field // The field occupying this region
+	ElementSize   int          // Size of each element (for []StructType), 1 for []byte and encode=delta, 0 for fixed fields
+	ElementStride int          // Distance between element starts: ElementSize, rounded up to elemalign=, or stride=
+	ElementType   string       // Type name of slice elements (e.g., "LeafElement" for []LeafElement)
+	Misaligned    bool         // Multi-byte primitive not naturally aligned given the buffer's alignment guarantee
+	BitOffset     int          // Bit fields: first bit within the byte at Start
+	Bits          int          // Bit fields: width in bits (0 for byte-granular regions)
+	Chain         int          // Position in the region= chain; regions after the first start where the previous ends
+	Framed        bool         // Elements are mode=stream frames, each as long as its length field; ElementSize is the smallest
+	Members       []Member     // Slices sharing the region (arrange=), its own field first; ElementSize is a row of one element each
+}
+
+// startBit returns the bit position where the region begins
+func (r Region) startBit() int {
+	return r.Start*8 + r.BitOffset
+}
+
+// isAlias reports whether the region is a fixed field aliasing the bytes of
+// other fields (overlap=allow)
+func (r Region) isAlias() bool {
+	return r.Field.Layout != nil && r.Field.Layout.Overlap
+}
+
+// endBit returns the bit position where the region ends
+func (r Region) endBit() int {
+	if r.Bits > 0 {
+		return r.startBit() + r.Bits
+	}
+	return r.Boundary * 8
+}
+
+// before orders regions by start, then by position in the region= chain
+func (r Region) before(other Region) bool {
+	if r.startBit() != other.startBit() {
+		return r.startBit() < other.startBit()
+	}
+	return r.Chain < other.Chain
 }
 
 type RegionKind int
@@ -31,7 +67,10 @@ type AnalyzedLayout struct {
 	TypeName   string
 	BufferSize int
 	Regions    []Region
-	Errors     []string // Validation errors
+	Trailer    []Region    // Stream frame trailer fields, Start/Boundary relative to the trailer
+	Gaps       []SharedGap // Free space shared by a start-end and an end-start region
+	Errors     []string    // Validation errors
+	Warnings   []string    // Format design problems that don't block generation
 }
 
 // Analyze performs layout analysis on a parsed type
@@ -47,8 +86,22 @@ func Analyze(layout *parser.TypeLayout, registry *TypeRegistry) (*AnalyzedLayout
 
 	// Phase 1: Build regions from fields
 	for _, field := range layout.Fields {
+		// Reserved ranges are zero-filled, whatever their field's type
+		if field.Layout.Reserve == 0 && !supportedType(field.GoType) {
+			a.Errors = append(a.Errors, fmt.Sprintf("%s: unsupported field type for layout: %s (want a named type, or arrays or slices of one)",
+				field.Name, field.GoType))
+			continue
+		}
 		// Skip indirect slice fields - they don't occupy regions
-		if field.Layout.From != "" {
+		if field.Layout.From != "" || field.Layout.Extents != "" {
+			continue
+		}
+		// Trailer fields are placed after the payload by validateStream
+		if field.Layout.Trailer {
+			continue
+		}
+		// Parallel slices are members of the arrange= region they name
+		if field.Layout.Parallel != "" {
 			continue
 		}
 
@@ -57,6 +110,12 @@ func Analyze(layout *parser.TypeLayout, registry *TypeRegistry) (*AnalyzedLayout
 			a.Errors = append(a.Errors, fmt.Sprintf("%s: %v", field.Name, err))
 			continue
 		}
+		if field.Layout.Arrange != "" {
+			if err := arrangeRegion(&region, layout, registry); err != nil {
+				a.Errors = append(a.Errors, fmt.Sprintf("%s: %v", field.Name, err))
+				continue
+			}
+		}
 		a.Regions = append(a.Regions, region)
 	}
 
@@ -64,27 +123,156 @@ func Analyze(layout *parser.TypeLayout, registry *TypeRegistry) (*AnalyzedLayout
 		return a, fmt.Errorf("layout has %d errors", len(a.Errors))
 	}
 
-	// Phase 2: Calculate dynamic region start points and boundaries
+	// Phase 2: Order named start-end regions into a chain
+	if err := orderRegions(a, layout); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 3: Calculate dynamic region start points and boundaries
 	if err := calculateBoundaries(a); err != nil {
 		a.Errors = append(a.Errors, err.Error())
 		return a, err
 	}
 
-	// Phase 3: Validate count fields
-	if err := validateCountFields(a, layout); err != nil {
+	// Phase 4: Validate count fields
+	if err := validateCountFields(a, layout, registry); err != nil {
 		a.Errors = append(a.Errors, err.Error())
 		return a, err
 	}
 
-	// Phase 4: Validate indirect slices
+	// Phase 5: Validate indirect slices
 	if err := validateIndirectSlices(a, layout, registry); err != nil {
 		a.Errors = append(a.Errors, err.Error())
 		return a, err
 	}
 
-	// Phase 5: Detect collisions
+	// Phase 6: Validate slotted page requirements
+	if err := validateSlotted(a, layout); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 7: Validate stream frame header, payload and trailer
+	if err := validateStream(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 8: Mark fields unsafe to load through a typed pointer
+	markMisaligned(a, layout, registry)
+
+	// Phase 9: Detect collisions
 	detectCollisions(a)
 
+	// Phase 10: Find free space shared by forward and backward regions
+	findSharedGaps(a, layout)
+
+	// Phase 11: Validate slices of length-prefixed elements
+	if err := validateFrames(a, layout); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 12: Validate pages chained by next=
+	if err := validateChain(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 13: Validate optional slots packed by present=
+	if err := validatePresent(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 14: Validate delta-encoded integer slices
+	if err := validateDelta(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 15: Validate aligned struct slice elements
+	if err := validateElemAlign(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 16: Validate slices arranged in a shared region
+	if err := validateArrange(a, layout); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 17: Validate counts shared by several regions
+	if err := validateSharedCounts(a); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 18: Validate that binaryread=true layouts encode as binary.Read reads
+	if err := validateBinaryRead(layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 19: Validate the C struct a cgo test compares offsets against
+	if err := validateCStruct(layout); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 20: Validate that reuse=true layouts unmarshal without allocating
+	if err := validateReuse(layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 21: Validate that zeroize=true layouts reach every byte they hold
+	if err := validateZeroize(layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 22: Validate that field names don't collide with generated identifiers
+	if err := validateFieldNames(layout); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 23: Validate the kind= DecodePage routes to the layout
+	if err := validateKind(layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 24: Validate register fields marked access=
+	if err := validateAccess(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 25: Validate inline slices marked cap=
+	if err := validateCapSlices(a, layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 26: Validate that blit=true layouts encode the struct's memory
+	if err := validateBlit(layout, registry); err != nil {
+		a.Errors = append(a.Errors, err.Error())
+		return a, err
+	}
+
+	// Phase 27: Warn when maximal counts overflow the buffer
+	checkWorstCase(a, layout, registry)
+
+	// Phase 28: Warn about dynamic regions too small for an element
+	checkUnreachable(a, layout)
+
+	// Phase 29: Warn about exported fields the encoding leaves out
+	checkUntagged(a, layout)
+
 	return a, nil
 }
 
@@ -94,9 +282,30 @@ func buildRegion(field parser.Field, bufferSize int, registry *TypeRegistry) (Re
 		Boundary: -1, // Unknown until calculateBoundaries
 	}
 
+	// Reserved range: the pseudo-field's type is irrelevant
+	if field.Layout.Reserve > 0 {
+		r.Kind = FixedRegion
+		r.Start = field.Layout.Offset
+		r.Boundary = field.Layout.Offset + field.Layout.Reserve
+		r.Direction = parser.Fixed
+		if r.Boundary > bufferSize {
+			return r, fmt.Errorf("reserved range [%d, %d) exceeds buffer size %d",
+				r.Start, r.Boundary, bufferSize)
+		}
+		return r, nil
+	}
+
+	// A blank field has no storage to encode from, so only padding can be blank
+	if field.Name == "_" {
+		return r, fmt.Errorf("blank field of type %s must be [N]byte padding or reserve=L", field.GoType)
+	}
+
 	if field.Layout.Direction == parser.Fixed {
 		// Fixed field: calculate size and end offset
 		size, err := registry.SizeOf(field.GoType)
+		if field.Layout.Kind != "" {
+			size, err = parser.KindSize(field) // The kind encodes the type, whatever it is
+		}
 		if err != nil {
 			return r, fmt.Errorf("cannot determine size: %w", err)
 		}
@@ -109,6 +318,25 @@ func buildRegion(field parser.Field, bufferSize int, registry *TypeRegistry) (Re
 		r.Boundary = field.Layout.Offset + size
 		r.Direction = parser.Fixed
 
+		if field.Layout.Bits > 0 {
+			if err := validateBitField(field, registry); err != nil {
+				return r, err
+			}
+			r.BitOffset = field.Layout.BitOffset
+			r.Bits = field.Layout.Bits
+			r.Boundary = r.Start + (r.BitOffset+r.Bits+7)/8 // Covering bytes
+		}
+
+		if field.Layout.Default != "" {
+			if err := validateDefault(field, registry); err != nil {
+				return r, err
+			}
+		}
+
+		if err := validateHooks(field); err != nil {
+			return r, err
+		}
+
 		if r.Boundary > bufferSize {
 			return r, fmt.Errorf("field [%d, %d) exceeds buffer size %d",
 				r.Start, r.Boundary, bufferSize)
@@ -141,10 +369,32 @@ func buildRegion(field parser.Field, bufferSize int, registry *TypeRegistry) (Re
 		return r, fmt.Errorf("element type cannot be dynamic: %s", elementType)
 	}
 
+	// max= bounds the count field; implicit lengths are bounded by the region
+	if field.Layout.MaxCount > 0 && field.Layout.CountField == "" {
+		return r, fmt.Errorf("max=%d requires count=", field.Layout.MaxCount)
+	}
+	if field.Layout.Sentinel && field.Layout.CountField != "" {
+		return r, fmt.Errorf("sentinel cannot be combined with count=")
+	}
+
 	r.Kind = DynamicRegion
 	r.Direction = field.Layout.Direction
 	r.ElementSize = elementSize
 	r.ElementType = elementType
+	if minSize, ok := registry.Frame(elementType); ok {
+		r.ElementSize = minSize
+		r.Framed = true
+	}
+	if field.Layout.Encode == parser.EncodeDelta {
+		r.ElementSize = 1 // A varint delta takes at least a byte
+	}
+	r.ElementStride = r.ElementSize
+	if align := field.Layout.ElemAlign; align > 0 {
+		r.ElementStride = (r.ElementSize + align - 1) / align * align
+	}
+	if stride := field.Layout.Stride; stride > 0 {
+		r.ElementStride = stride
+	}
 
 	// Set start point
 	if field.Layout.StartAt >= 0 {
@@ -162,6 +412,81 @@ func buildRegion(field parser.Field, bufferSize int, registry *TypeRegistry) (Re
 	return r, nil
 }
 
+// validateBitField checks that a bit field is a bool or an unsigned integer wide
+// enough for its bits, and that its covering bytes fit in a uint64
+func validateBitField(field parser.Field, registry *TypeRegistry) error {
+	bits := field.Layout.Bits
+	resolved := registry.ResolveType(field.GoType)
+
+	switch resolved {
+	case "bool":
+		if bits != 1 {
+			return fmt.Errorf("bool bit field must be 1 bit, got %d", bits)
+		}
+	case "uint8", "byte", "uint16", "uint32", "uint64":
+		size, _ := SizeOf(resolved)
+		if bits > size*8 {
+			return fmt.Errorf("%d bits do not fit in %s", bits, field.GoType)
+		}
+	default:
+		return fmt.Errorf("bit field must be bool or an unsigned integer, got: %s", field.GoType)
+	}
+
+	if field.Layout.BitOffset+bits > 64 {
+		return fmt.Errorf("bit field spans more than 64 bits from byte %d", field.Layout.Offset)
+	}
+	return nil
+}
+
+// validateDefault checks that a default= or magic= value fits the field: its
+// integer type, or its width for bit fields
+func validateDefault(field parser.Field, registry *TypeRegistry) error {
+	value := field.Layout.Default
+	param := "default"
+	if field.Layout.Magic {
+		param = "magic"
+	}
+	resolved := registry.ResolveType(field.GoType)
+	if resolved == "byte" {
+		resolved = "uint8"
+	}
+	if !isCountType(resolved) {
+		return fmt.Errorf("%s=%s requires an integer field, got: %s", param, value, field.GoType)
+	}
+
+	size, _ := SizeOf(resolved)
+	bits := size * 8
+	if field.Layout.Bits > 0 {
+		bits = field.Layout.Bits
+	}
+
+	var err error
+	if strings.HasPrefix(resolved, "int") {
+		_, err = strconv.ParseInt(value, 0, bits)
+	} else {
+		_, err = strconv.ParseUint(value, 0, bits)
+	}
+	if err != nil {
+		return fmt.Errorf("%s=%s does not fit in %d-bit %s", param, value, bits, field.GoType)
+	}
+	return nil
+}
+
+// validateHooks checks that get=/set= hooks sit on whole fields. A get hook
+// computes the value itself, so it cannot be combined with a default.
+func validateHooks(field parser.Field) error {
+	if field.Layout.Get == "" && field.Layout.Set == "" {
+		return nil
+	}
+	if field.Layout.Bits > 0 {
+		return fmt.Errorf("get=/set= hooks are not supported on bit fields")
+	}
+	if field.Layout.Get != "" && field.Layout.Default != "" {
+		return fmt.Errorf("get=%s cannot be combined with default= or magic=", field.Layout.Get)
+	}
+	return nil
+}
+
 // extractElementType extracts element type from slice type
 // "[]byte" -> "byte", "[]LeafElement" -> "LeafElement"
 func extractElementType(sliceType string) string {
@@ -175,7 +500,7 @@ func extractElementType(sliceType string) string {
 func calculateBoundaries(a *AnalyzedLayout) error {
 	// Sort regions by start offset for boundary calculation
 	sort.Slice(a.Regions, func(i, j int) bool {
-		return a.Regions[i].Start < a.Regions[j].Start
+		return a.Regions[i].before(a.Regions[j])
 	})
 
 	// Calculate implicit start points for start-end regions
@@ -183,10 +508,15 @@ func calculateBoundaries(a *AnalyzedLayout) error {
 		r := &a.Regions[i]
 		if r.Kind == DynamicRegion && r.Direction == parser.StartEnd && r.Field.Layout.StartAt < 0 {
 			// Find end of previous fixed region or start of buffer
-			r.Start = findPreviousEnd(a.Regions, i)
+			r.Start = skipAdjacentFixed(a.Regions, findPreviousEnd(a.Regions, i))
 		}
 	}
 
+	// Implicit starts may have moved past fixed fields; restore offset order
+	sort.SliceStable(a.Regions, func(i, j int) bool {
+		return a.Regions[i].before(a.Regions[j])
+	})
+
 	// Calculate boundaries
 	for i := range a.Regions {
 		r := &a.Regions[i]
@@ -221,6 +551,24 @@ func findPreviousEnd(regions []Region, idx int) int {
 	return 0 // Start of buffer
 }
 
+// skipAdjacentFixed advances start past fixed regions packed directly after it, so
+// an implicit start-end region follows a multi-field header (@0, @1, @3, ...) rather
+// than its first field
+func skipAdjacentFixed(regions []Region, start int) int {
+	for {
+		advanced := false
+		for _, r := range regions {
+			if r.Kind == FixedRegion && r.Start == start && r.Boundary > start {
+				start = r.Boundary
+				advanced = true
+			}
+		}
+		if !advanced {
+			return start
+		}
+	}
+}
+
 func findNextStart(regions []Region, idx int, bufferSize int) int {
 	// Find the start offset of the next region after idx
 	for i := idx + 1; i < len(regions); i++ {
@@ -234,7 +582,7 @@ func findNextStart(regions []Region, idx int, bufferSize int) int {
 	return bufferSize // End of buffer
 }
 
-func validateCountFields(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+func validateCountFields(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
 	// Check if dynamic fields require count fields
 	for _, region := range a.Regions {
 		if region.Kind != DynamicRegion {
@@ -243,8 +591,9 @@ func validateCountFields(a *AnalyzedLayout, layout *parser.TypeLayout) error {
 
 		countField := region.Field.Layout.CountField
 
-		// Struct slices ALWAYS require count (can't infer element count from boundary)
-		if region.ElementType != "byte" {
+		// Struct slices require count (can't infer element count from boundary),
+		// unless a zero entry ends them
+		if region.ElementType != "byte" && !region.Field.Layout.Sentinel {
 			if countField == "" {
 				return fmt.Errorf("field '%s' (type %s) requires count= (struct slices must specify element count)",
 					region.Field.Name, region.Field.GoType)
@@ -270,16 +619,14 @@ func validateCountFields(a *AnalyzedLayout, layout *parser.TypeLayout) error {
 
 		// If count specified, validate it exists and has sufficient capacity
 		if countField != "" {
-			countFieldType, err := getCountFieldType(countField, layout)
+			countFieldType, err := getCountFieldType(countField, layout, registry)
 			if err != nil {
 				return err
 			}
 
-			// Validate count field capacity (only for non-nested fields where we know the type)
-			if !strings.Contains(countField, ".") {
-				if err := validateCountCapacity(region, countFieldType, a.BufferSize); err != nil {
-					return err
-				}
+			// Validate count field capacity (skipped when a nested type is unknown)
+			if err := validateCountCapacity(region, countFieldType, a.BufferSize); err != nil {
+				return err
 			}
 		}
 	}
@@ -305,47 +652,51 @@ func hasNonFixedAfter(regions []Region, target Region) bool {
 	return false
 }
 
-// getCountFieldType returns the Go type of a count field
-func getCountFieldType(countField string, layout *parser.TypeLayout) (string, error) {
-	// Check for nested field reference (e.g., "Header.NumKeys")
-	if strings.Contains(countField, ".") {
-		parts := strings.Split(countField, ".")
-		if len(parts) != 2 {
-			return "", fmt.Errorf("count field '%s' has invalid nested reference (only one level supported)", countField)
-		}
-
-		parentField := parts[0]
-		// Validate parent field exists
-		found := false
-		for _, f := range layout.Fields {
-			if f.Name == parentField {
-				found = true
-				// We can't easily validate the nested field type without parsing the parent struct
-				// For nested fields, we can't validate type - assume user knows what they're doing
-				break
-			}
+// getCountFieldType returns the Go type of a count field. Nested references
+// (e.g., "Header.Meta.NumKeys") are resolved through the field types recorded in the
+// registry. When an intermediate type's fields aren't registered the type can't be
+// determined and "" is returned.
+func getCountFieldType(countField string, layout *parser.TypeLayout, registry *TypeRegistry) (string, error) {
+	parts := strings.Split(countField, ".")
+
+	var goType string
+	for _, f := range layout.Fields {
+		if f.Name == parts[0] {
+			goType = f.GoType
+			break
 		}
-		if !found {
-			return "", fmt.Errorf("count field parent '%s' not found in '%s'", parentField, countField)
+	}
+	if goType == "" {
+		if len(parts) > 1 {
+			return "", fmt.Errorf("count field parent '%s' not found in '%s'", parts[0], countField)
 		}
-		return "", nil // Return empty string for nested fields (can't determine type)
+		return "", fmt.Errorf("count field '%s' not found", countField)
 	}
 
-	// Simple field reference - validate type and return it
-	for _, f := range layout.Fields {
-		if f.Name == countField {
-			// Validate type is an integer type
-			if !isCountType(f.GoType) {
-				return "", fmt.Errorf("count field '%s' must be an integer type (int8/16/32/64 or uint8/16/32/64), got: %s",
-					countField, f.GoType)
-			}
-			return f.GoType, nil
+	// Walk nested references through registered struct types
+	for i, name := range parts[1:] {
+		fields, ok := registry.Fields(goType)
+		if !ok {
+			return "", nil // Fields of goType unknown, can't determine type
 		}
+		next, ok := fields[name]
+		if !ok {
+			return "", fmt.Errorf("count field '%s': %s (type %s) has no field '%s'",
+				countField, strings.Join(parts[:i+1], "."), goType, name)
+		}
+		goType = next
+	}
+
+	// Validate type is an integer type
+	if !isCountType(goType) {
+		return "", fmt.Errorf("count field '%s' must be an integer type (int8/16/32/64 or uint8/16/32/64), got: %s",
+			countField, goType)
 	}
-	return "", fmt.Errorf("count field '%s' not found", countField)
+	return goType, nil
 }
 
-// validateCountCapacity checks if count field type can hold max possible element count
+// validateCountCapacity checks if count field type can hold max possible element
+// count, which max= may lower
 func validateCountCapacity(region Region, countFieldType string, bufferSize int) error {
 	if countFieldType == "" {
 		return nil // No count field, skip validation
@@ -363,13 +714,16 @@ func validateCountCapacity(region Region, countFieldType string, bufferSize int)
 		maxSpace = 0
 	}
 
-	maxElements := maxSpace / region.ElementSize
-	if maxSpace%region.ElementSize != 0 {
+	maxElements := maxSpace / region.ElementStride
+	if maxSpace%region.ElementStride != 0 {
 		maxElements++ // Round up
 	}
+	if max := region.Field.Layout.MaxCount; max > 0 && max < maxElements {
+		maxElements = max // max= caps the count below the region capacity
+	}
 
 	// Get max value for count field type
-	maxCountValue := getMaxCountValue(countFieldType)
+	maxCountValue := MaxCountValue(countFieldType)
 	if maxCountValue < 0 {
 		return nil // Unknown type, skip
 	}
@@ -382,13 +736,18 @@ func validateCountCapacity(region Region, countFieldType string, bufferSize int)
 	return nil
 }
 
-// getMaxCountValue returns the maximum value a count type can hold
-func getMaxCountValue(countType string) int {
+// MaxCountValue returns the maximum value a count type can hold, or -1 for
+// non-integer types
+func MaxCountValue(countType string) int {
 	switch countType {
-	case "uint8", "int8":
+	case "uint8":
 		return 255
-	case "uint16", "int16":
+	case "int8":
+		return 127
+	case "uint16":
 		return 65535
+	case "int16":
+		return 32767
 	case "uint32", "int32":
 		return 2147483647 // Max int32
 	case "uint64", "int64":
@@ -407,6 +766,24 @@ func isCountType(goType string) bool {
 	return false
 }
 
+// supportedType reports whether goType is one layouts can hold: a named type,
+// possibly imported, or arrays and slices of one. Whether the named type has a
+// known size is checked with the region it makes.
+func supportedType(goType string) bool {
+	for strings.HasPrefix(goType, "[") {
+		_, elem, ok := strings.Cut(goType, "]")
+		if !ok {
+			return false
+		}
+		goType = elem
+	}
+	qualifier, name, qualified := strings.Cut(goType, ".")
+	if !qualified {
+		return token.IsIdentifier(goType)
+	}
+	return token.IsIdentifier(qualifier) && token.IsIdentifier(name)
+}
+
 // validateIndirectSlices validates [][]byte fields with metadata indirection
 func validateIndirectSlices(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
 	for _, field := range layout.Fields {
@@ -469,20 +846,134 @@ func validateIndirectSlices(a *AnalyzedLayout, layout *parser.TypeLayout, regist
 			return fmt.Errorf("field '%s': region field '%s' must be []byte, got: %s",
 				field.Name, field.Layout.Region, regionField.GoType)
 		}
+
+		// Validate what the stored offsets count from
+		switch field.Layout.OffsetMode {
+		case "", parser.OffsetAfterMetadata, parser.OffsetPage, parser.OffsetRegion:
+		default:
+			return fmt.Errorf("field '%s': offsetmode must be 'page', 'region' or 'after-metadata', got: %s",
+				field.Name, field.Layout.OffsetMode)
+		}
 	}
 
-	return nil
+	if err := validateExtents(a, layout); err != nil {
+		return err
+	}
+	if err := validatePrefix(layout); err != nil {
+		return err
+	}
+	return validateSentinels(a, layout)
+}
+
+// validateSlotted checks that a slotted=true layout has a single indirect slice
+// with absolute offsets into a forward-growing, counted slot directory
+func validateSlotted(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+	if layout.Anno == nil || !layout.Anno.Slotted {
+		return nil
+	}
+
+	if layout.Anno.Mode != "zerocopy" {
+		return fmt.Errorf("slotted=true requires mode=zerocopy")
+	}
+
+	var cells []parser.Field
+	for _, field := range layout.Fields {
+		if field.Layout.From != "" {
+			cells = append(cells, field)
+		}
+	}
+	if len(cells) != 1 {
+		return fmt.Errorf("slotted=true requires exactly one indirect slice field, got %d", len(cells))
+	}
+
+	cell := cells[0]
+	if cell.Layout.OffsetMode != parser.OffsetPage {
+		return fmt.Errorf("field '%s': slotted pages require offsetmode=page (offsets after the metadata shift when slots are added)",
+			cell.Name)
+	}
+
+	for _, region := range a.Regions {
+		if region.Field.Name != cell.Layout.From {
+			continue
+		}
+		if region.Direction != parser.StartEnd {
+			return fmt.Errorf("field '%s': slot directory '%s' must be start-end", cell.Name, cell.Layout.From)
+		}
+		if region.Field.Layout.CountField == "" {
+			return fmt.Errorf("field '%s': slot directory '%s' requires count=", cell.Name, cell.Layout.From)
+		}
+		// Cells are packed down from the end of the buffer over whatever lies past the directory
+		for _, fixed := range a.Regions {
+			if fixed.Kind == FixedRegion && fixed.Start >= region.Start {
+				return fmt.Errorf("field '%s' [%d, %d) lies in the cells of slotted page, which run from directory '%s' to the end of the buffer; move it before the directory",
+					fixed.Field.Name, fixed.Start, fixed.Boundary, cell.Layout.From)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("field '%s': slot directory '%s' not found", cell.Name, cell.Layout.From)
+}
+
+// markMisaligned flags fixed primitive fields whose address in the buffer may not be
+// a multiple of their size. The buffer is only guaranteed to be aligned to the
+// annotation's align= value; without it a [N]byte array may start at any address.
+// Zerocopy accessors load misaligned fields bytewise, since strict-alignment
+// targets (ARM, MIPS) fault on unaligned typed loads.
+func markMisaligned(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) {
+	bufAlign := 1
+	if layout.Anno.Align > 0 {
+		bufAlign = layout.Anno.Align
+	}
+
+	for i := range a.Regions {
+		r := &a.Regions[i]
+		if r.Kind != FixedRegion {
+			continue
+		}
+
+		resolved := registry.ResolveType(r.Field.GoType)
+		size, err := SizeOf(resolved)
+		if err != nil || size < 2 || strings.HasPrefix(resolved, "[") || r.Bits > 0 || r.Field.Layout.Reserve > 0 {
+			continue // Structs, byte arrays, single bytes, bit fields and reserved ranges have no alignment requirement
+		}
+
+		r.Misaligned = bufAlign%size != 0 || r.Start%size != 0
+	}
 }
 
 func detectCollisions(a *AnalyzedLayout) {
+	// Aliases (overlap=allow) may overlap anything, but must overlay some field
+	// encoding their bytes
+	var owners []Region
+	for _, r := range a.Regions {
+		if !r.isAlias() {
+			owners = append(owners, r)
+		}
+	}
+	for _, alias := range a.Regions {
+		if !alias.isAlias() {
+			continue
+		}
+		overlaid := false
+		for _, r := range owners {
+			overlaid = overlaid || (r.Kind == FixedRegion && r.startBit() < alias.endBit() && alias.startBit() < r.endBit())
+		}
+		if !overlaid {
+			a.Errors = append(a.Errors, fmt.Sprintf("%s [%d, %d): overlap=allow but overlays no other field",
+				alias.Field.Name, alias.Start, alias.Boundary))
+		}
+	}
+
 	// Check for overlapping regions
-	for i := 0; i < len(a.Regions)-1; i++ {
-		r1 := a.Regions[i]
-		r2 := a.Regions[i+1]
+	for i := 0; i < len(owners)-1; i++ {
+		r1 := owners[i]
+		r2 := owners[i+1]
 
 		// Check if regions overlap
 		if r1.Kind == FixedRegion && r2.Kind == FixedRegion {
-			if r1.Boundary > r2.Start {
+			// Bit fields may share bytes, so compare bit ranges
+			if r1.endBit() > r2.startBit() {
 				a.Errors = append(a.Errors,
 					fmt.Sprintf("collision: %s [%d, %d) overlaps %s [%d, %d)",
 						r1.Field.Name, r1.Start, r1.Boundary,
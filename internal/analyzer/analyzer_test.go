@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/alexhholmes/layout/internal/parser"
@@ -97,6 +98,40 @@ func TestAnalyze_DynamicWithBoundary(t *testing.T) {
 	}
 }
 
+func TestAnalyze_DynamicAfterMultiFieldHeader(t *testing.T) {
+	// type Frame struct {
+	//     Type uint8  `layout:"@0"`
+	//     Len  uint16 `layout:"@1"`
+	//     Seq  uint32 `layout:"@3"`
+	//     Body []byte `layout:"start-end"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Frame",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Seq", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 3, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: -1,
+			}},
+		},
+	}
+
+	reg := NewTypeRegistry()
+	analyzed, err := Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	// Body should follow the whole header: [7, 64)
+	bodyRegion := analyzed.Regions[3]
+	if bodyRegion.Field.Name != "Body" || bodyRegion.Start != 7 || bodyRegion.Boundary != 64 {
+		t.Errorf("Body region: got %s [%d, %d), want Body [7, 64)",
+			bodyRegion.Field.Name, bodyRegion.Start, bodyRegion.Boundary)
+	}
+}
+
 func TestAnalyze_MissingCountField(t *testing.T) {
 	// type Page struct {
 	//     Header  uint16 `layout:"@0"`
@@ -133,6 +168,45 @@ func TestAnalyze_MissingCountField(t *testing.T) {
 	}
 }
 
+func TestAnalyze_UnsupportedFieldType(t *testing.T) {
+	tests := []struct {
+		goType  string
+		wantErr string
+	}{
+		{"*Node", "Next: unsupported field type for layout: *Node (want a named type, or arrays or slices of one)"},
+		{"map[string]uint32", "Next: unsupported field type for layout: map[string]uint32"},
+		{"[]*Node", "Next: unsupported field type for layout: []*Node"},
+		{"[4]common.PageID", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goType, func(t *testing.T) {
+			// type Node struct {
+			//     Next <goType> `layout:"@0"`
+			// }
+			layout := &parser.TypeLayout{
+				Name: "Node",
+				Anno: &parser.TypeAnnotation{Size: 64},
+				Fields: []parser.Field{
+					{Name: "Next", GoType: tt.goType, Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				},
+			}
+			reg := NewTypeRegistry()
+			reg.RegisterAlias("common.PageID", "uint64")
+
+			analyzed, err := Analyze(layout, reg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestAnalyze_FixedOverlap(t *testing.T) {
 	// type Page struct {
 	//     Field1 uint64 `layout:"@0"`   // [0, 8)
@@ -290,30 +364,430 @@ func TestAnalyze_NestedCountField_ParentNotFound(t *testing.T) {
 	}
 }
 
-func TestAnalyze_NestedCountField_TooManyLevels(t *testing.T) {
+func TestAnalyze_NestedCountField_Deep(t *testing.T) {
+	// type Meta struct {
+	//     NumKeys uint8 `layout:"@0"`
+	// }
+	// type Header struct {
+	//     Meta Meta `layout:"@0"`
+	// }
 	// type Page struct {
-	//     Body []byte `layout:"start-end,count=A.B.C"`
+	//     Header Header `layout:"@0"`
+	//     Body   []byte `layout:"start-end,count=Header.Meta.NumKeys"`
+	// }
+	newLayout := func(countField string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Page",
+			Anno: &parser.TypeAnnotation{Size: 4096},
+			Fields: []parser.Field{
+				{Name: "Header", GoType: "Header", Layout: &parser.FieldLayout{
+					Offset: 0, Direction: parser.Fixed,
+				}},
+				{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: -1,
+					CountField: countField,
+				}},
+			},
+		}
+	}
+
+	reg := NewTypeRegistry()
+	reg.Register("Meta", 1)
+	reg.RegisterFields("Meta", []parser.Field{{Name: "NumKeys", GoType: "uint8"}})
+	reg.Register("Header", 16)
+	reg.RegisterFields("Header", []parser.Field{{Name: "Meta", GoType: "Meta"}})
+
+	// Resolved to uint8, which can't count the 4080 bytes available to Body
+	_, err := Analyze(newLayout("Header.Meta.NumKeys"), reg)
+	if err == nil || !strings.Contains(err.Error(), "type uint8") {
+		t.Errorf("Expected capacity error for uint8 count, got: %v", err)
+	}
+
+	_, err = Analyze(newLayout("Header.Meta.Missing"), reg)
+	if err == nil || !strings.Contains(err.Error(), "has no field 'Missing'") {
+		t.Errorf("Expected missing field error, got: %v", err)
+	}
+
+	_, err = Analyze(newLayout("Header.Meta"), reg)
+	if err == nil || !strings.Contains(err.Error(), "must be an integer type") {
+		t.Errorf("Expected integer type error, got: %v", err)
+	}
+
+	reg.RegisterFields("Meta", []parser.Field{{Name: "NumKeys", GoType: "uint16"}})
+	if _, err := Analyze(newLayout("Header.Meta.NumKeys"), reg); err != nil {
+		t.Errorf("Analyze() error: %v", err)
+	}
+}
+
+func TestAnalyze_Slotted(t *testing.T) {
+	// @layout size=4096 mode=zerocopy slotted=true
+	// type Page struct {
+	//     NumSlots uint16   `layout:"@0"`
+	//     Slots    []Slot   `layout:"@2,start-end,count=NumSlots"`
+	//     Data     []byte   `layout:"end-start"`
+	//     Cells    [][]byte `layout:"from=Slots,offset=Off,size=Len,region=Data,offsetmode=..."`
+	// }
+	newLayout := func(offsetMode string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Page",
+			Anno: &parser.TypeAnnotation{Size: 4096, Mode: "zerocopy", Slotted: true},
+			Fields: []parser.Field{
+				{Name: "NumSlots", GoType: "uint16", Layout: &parser.FieldLayout{
+					Offset: 0, Direction: parser.Fixed,
+				}},
+				{Name: "Slots", GoType: "[]Slot", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: 2, CountField: "NumSlots",
+				}},
+				{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.EndStart, StartAt: -1,
+				}},
+				{Name: "Cells", GoType: "[][]byte", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, From: "Slots", OffsetField: "Off", SizeField: "Len",
+					Region: "Data", OffsetMode: offsetMode,
+				}},
+			},
+		}
+	}
+
+	reg := NewTypeRegistry()
+	reg.Register("Slot", 4)
+
+	if _, err := Analyze(newLayout(parser.OffsetPage), reg); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	_, err := Analyze(newLayout(parser.OffsetAfterMetadata), reg)
+	if err == nil || !strings.Contains(err.Error(), "offsetmode=page") {
+		t.Errorf("Expected offsetmode=page error, got: %v", err)
+	}
+
+	copyMode := newLayout(parser.OffsetPage)
+	copyMode.Anno.Mode = "copy"
+	if _, err := Analyze(copyMode, reg); err == nil {
+		t.Error("Expected error for slotted=true without mode=zerocopy")
+	}
+
+	// Cells are packed down from the end of the buffer, where a footer would be overwritten
+	footer := newLayout(parser.OffsetPage)
+	footer.Fields = append(footer.Fields, parser.Field{Name: "Footer", GoType: "uint64", Layout: &parser.FieldLayout{
+		Offset: 4088, Direction: parser.Fixed,
+	}})
+	if _, err := Analyze(footer, reg); err == nil || !strings.Contains(err.Error(), "field 'Footer' [4088, 4096) lies in the cells of slotted page") {
+		t.Errorf("Expected error for a fixed field past the slot directory, got: %v", err)
+	}
+}
+
+func TestAnalyze_Misaligned(t *testing.T) {
+	// @layout size=16 mode=zerocopy align=...
+	// type Header struct {
+	//     Flags uint8   `layout:"@0"`
+	//     Kind  uint16  `layout:"@2"`
+	//     Count uint32  `layout:"@4"`
+	//     Next  PageID  `layout:"@8"`
+	// }
+	newLayout := func(align int) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Header",
+			Anno: &parser.TypeAnnotation{Size: 16, Mode: "zerocopy", Align: align},
+			Fields: []parser.Field{
+				{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Kind", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+				{Name: "Count", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+				{Name: "Next", GoType: "PageID", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			},
+		}
+	}
+
+	reg := NewTypeRegistry()
+	reg.RegisterAlias("PageID", "uint64")
+
+	tests := []struct {
+		align      int
+		misaligned map[string]bool
+	}{
+		// A plain [N]byte buffer has no alignment guarantee
+		{align: 0, misaligned: map[string]bool{"Flags": false, "Kind": true, "Count": true, "Next": true}},
+		{align: 4, misaligned: map[string]bool{"Flags": false, "Kind": false, "Count": false, "Next": true}},
+		{align: 8, misaligned: map[string]bool{"Flags": false, "Kind": false, "Count": false, "Next": false}},
+	}
+
+	for _, tt := range tests {
+		result, err := Analyze(newLayout(tt.align), reg)
+		if err != nil {
+			t.Fatalf("align=%d: Analyze() error: %v", tt.align, err)
+		}
+		for _, r := range result.Regions {
+			if r.Misaligned != tt.misaligned[r.Field.Name] {
+				t.Errorf("align=%d: %s Misaligned = %v, want %v",
+					tt.align, r.Field.Name, r.Misaligned, tt.misaligned[r.Field.Name])
+			}
+		}
+	}
+}
+
+func TestAnalyze_BitFields(t *testing.T) {
+	bitField := func(name, goType string, offset, bit, bits int) parser.Field {
+		return parser.Field{Name: name, GoType: goType, Layout: &parser.FieldLayout{
+			Offset: offset, Direction: parser.Fixed, BitOffset: bit, Bits: bits,
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		fields  []parser.Field
+		wantErr string
+	}{
+		{"shared byte", []parser.Field{
+			bitField("Version", "uint8", 0, 0, 4),
+			bitField("IHL", "uint8", 0, 4, 4),
+			bitField("FragOff", "uint16", 1, 3, 13),
+		}, ""},
+		{"overlapping bits", []parser.Field{
+			bitField("A", "uint8", 0, 0, 5),
+			bitField("B", "uint8", 0, 4, 4),
+		}, "collision: A"},
+		{"bit field over byte field", []parser.Field{
+			{Name: "Kind", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			bitField("Flag", "bool", 1, 7, 1),
+		}, "collision: Kind"},
+		{"too wide for type", []parser.Field{bitField("A", "uint8", 0, 0, 9)}, "9 bits do not fit in uint8"},
+		{"signed", []parser.Field{bitField("A", "int8", 0, 0, 4)}, "bool or an unsigned integer"},
+		{"wide bool", []parser.Field{bitField("A", "bool", 0, 0, 2)}, "bool bit field must be 1 bit"},
+		{"beyond uint64", []parser.Field{bitField("A", "uint64", 0, 1, 64)}, "more than 64 bits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{Name: "Head", Anno: &parser.TypeAnnotation{Size: 16}, Fields: tt.fields}
+			analyzed, err := Analyze(layout, NewTypeRegistry())
+
+			if tt.wantErr == "" {
+				if err != nil || !analyzed.IsValid() {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				// FragOff covers bits 3..15 of bytes [1, 3)
+				frag := analyzed.Regions[2]
+				if frag.Start != 1 || frag.Boundary != 3 || frag.BitOffset != 3 || frag.Bits != 13 {
+					t.Errorf("FragOff: got [%d, %d) bit %d bits %d, want [1, 3) bit 3 bits 13",
+						frag.Start, frag.Boundary, frag.BitOffset, frag.Bits)
+				}
+				return
+			}
+
+			errs := strings.Join(analyzed.Errors, "; ")
+			if err != nil {
+				errs += "; " + err.Error()
+			}
+			if !strings.Contains(errs, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
+func TestAnalyze_Defaults(t *testing.T) {
+	tests := []struct {
+		goType  string
+		value   string
+		bits    int
+		wantErr string
+	}{
+		{"uint16", "0xCAFE", 0, ""},
+		{"int8", "-128", 0, ""},
+		{"uint8", "4", 3, ""},
+		{"uint16", "0x10000", 0, "does not fit in 16-bit uint16"},
+		{"int8", "200", 0, "does not fit in 8-bit int8"},
+		{"uint8", "8", 3, "does not fit in 3-bit uint8"},
+		{"[4]byte", "1", 0, "requires an integer field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goType+"="+tt.value, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Header",
+				Anno: &parser.TypeAnnotation{Size: 8},
+				Fields: []parser.Field{
+					{Name: "Magic", GoType: tt.goType, Layout: &parser.FieldLayout{
+						Offset: 0, Direction: parser.Fixed, Default: tt.value, Bits: tt.bits,
+					}},
+				},
+			}
+
+			analyzed, err := Analyze(layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, analyzed.Errors)
+			}
+		})
+	}
+}
+
+func TestAnalyze_Reserved(t *testing.T) {
+	// type Header struct {
+	//     Kind uint16   `layout:"@0"`
+	//     _    struct{} `layout:"@2,reserve=8"`
+	//     ID   uint32   `layout:"@8"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "Kind", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "_", GoType: "unknown", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed, Reserve: 8}},
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+
+	analyzed, _ := Analyze(layout, NewTypeRegistry())
+	errs := strings.Join(analyzed.Errors, "; ")
+	if !strings.Contains(errs, "collision: _ [2, 10) overlaps ID [8, 12)") {
+		t.Errorf("Expected reserved range collision, got: %s", errs)
+	}
+
+	layout.Fields[1].Layout.Reserve = 20
+	if _, err := Analyze(layout, NewTypeRegistry()); err == nil {
+		t.Errorf("Expected error for reserved range beyond buffer")
+	}
+}
+
+func TestAnalyze_Overlap(t *testing.T) {
+	// type Packet struct {
+	//     Payload [8]byte   `layout:"@0"`
+	//     Words   [2]uint32 `layout:"@0,overlap=allow"`
+	//     Kind    uint8     `layout:"@7,overlap=allow"`
+	//     Seq     uint16    `layout:"@8"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Packet",
+		Anno: &parser.TypeAnnotation{Size: 12},
+		Fields: []parser.Field{
+			{Name: "Payload", GoType: "[8]byte", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Words", GoType: "[2]uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Overlap: true}},
+			{Name: "Kind", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 7, Direction: parser.Fixed, Overlap: true}},
+			{Name: "Seq", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+
+	analyzed, err := Analyze(layout, NewTypeRegistry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	// Fields other than aliases still collide
+	layout.Fields[3].Layout.Offset = 6
+	analyzed, _ = Analyze(layout, NewTypeRegistry())
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "collision: Payload [0, 8) overlaps Seq [6, 8)") {
+		t.Errorf("Expected collision, got: %s", errs)
+	}
+
+	// An alias needs bytes to alias
+	layout.Fields[3].Layout.Offset = 8
+	layout.Fields[2].Layout.Offset = 10
+	analyzed, _ = Analyze(layout, NewTypeRegistry())
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "Kind [10, 11): overlap=allow but overlays no other field") {
+		t.Errorf("Expected alias error, got: %s", errs)
+	}
+}
+
+func TestAnalyze_BlankPadding(t *testing.T) {
+	// type Header struct {
+	//     Kind uint16  `layout:"@0"`
+	//     _    [6]byte `layout:"@2"`
+	//     ID   uint32  `layout:"@4"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "Kind", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "_", GoType: "[6]byte", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed, Reserve: 6}},
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+		},
+	}
+
+	analyzed, _ := Analyze(layout, NewTypeRegistry())
+	errs := strings.Join(analyzed.Errors, "; ")
+	if !strings.Contains(errs, "collision: _ [2, 8) overlaps ID [4, 8)") {
+		t.Errorf("Expected padding collision, got: %s", errs)
+	}
+
+	// Blank fields other than padding have nothing to encode
+	layout.Fields[1] = parser.Field{Name: "_", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}}
+	layout.Fields = layout.Fields[:2]
+	analyzed, _ = Analyze(layout, NewTypeRegistry())
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "blank field of type uint32 must be [N]byte padding") {
+		t.Errorf("Expected blank field error, got: %s", errs)
+	}
+}
+
+func TestAnalyze_MaxCount(t *testing.T) {
+	// @layout size=1024
+	// type Page struct {
+	//     N    uint8  `layout:"@0"`
+	//     Body []byte `layout:"start-end,count=N,max=200"`
 	// }
 	layout := &parser.TypeLayout{
 		Name: "Page",
-		Anno: &parser.TypeAnnotation{Size: 4096},
+		Anno: &parser.TypeAnnotation{Size: 1024},
 		Fields: []parser.Field{
-			{Name: "A", GoType: "SomeType", Layout: &parser.FieldLayout{
-				Offset: 0, Direction: parser.Fixed,
-			}},
-			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
-				Offset: -1, Direction: parser.StartEnd, StartAt: -1,
-				CountField: "A.B.C",
-			}},
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N", MaxCount: 200}},
 		},
 	}
 
-	reg := NewTypeRegistry()
-	reg.Register("SomeType", 16)
+	// max= lowers the capacity the count type must hold
+	if _, err := Analyze(layout, NewTypeRegistry()); err != nil {
+		t.Errorf("Analyze() unexpected error: %v", err)
+	}
 
-	_, err := Analyze(layout, reg)
+	layout.Fields[1].Layout.MaxCount = 300
+	analyzed, _ := Analyze(layout, NewTypeRegistry())
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "cannot hold") {
+		t.Errorf("Expected count capacity error for max=300, got: %s", errs)
+	}
 
-	if err == nil {
-		t.Error("Expected error about too many nesting levels")
+	layout.Fields[1].Layout.CountField = ""
+	analyzed, _ = Analyze(layout, NewTypeRegistry())
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "max=300 requires count=") {
+		t.Errorf("Expected max= without count= error, got: %s", errs)
+	}
+}
+
+func TestAnalyze_HookValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout *parser.FieldLayout
+		want   string
+	}{
+		{"bit field", &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Bits: 4, Get: "f"}, "not supported on bit fields"},
+		{"get with default", &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "1", Get: "f"}, "cannot be combined with default="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name:   "Record",
+				Anno:   &parser.TypeAnnotation{Size: 8},
+				Fields: []parser.Field{{Name: "Flags", GoType: "uint8", Layout: tt.layout}},
+			}
+			analyzed, err := Analyze(layout, NewTypeRegistry())
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.want) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxCountValue(t *testing.T) {
+	tests := map[string]int{"uint8": 255, "int8": 127, "uint16": 65535, "int16": 32767, "string": -1}
+	for countType, want := range tests {
+		if got := MaxCountValue(countType); got != want {
+			t.Errorf("MaxCountValue(%s) = %d, want %d", countType, got, want)
+		}
 	}
 }
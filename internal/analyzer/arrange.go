@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// Member is one of the slices sharing an arrange= region, element for element
+type Member struct {
+	Field       parser.Field
+	ElementType string
+	ElementSize int
+	Offset      int // Bytes of the members before it in a row; a columnar region's column starts Offset bytes per element in
+}
+
+// arrangeRegion gathers the parallel= slices of an arrange= region into its
+// Members, the region's own field first. Its elements become rows holding one
+// element of each, so capacities and counts are checked per row. Elements are
+// integers or layout types, which codegen places one at a time.
+func arrangeRegion(r *Region, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	fields := []parser.Field{r.Field}
+	for _, f := range layout.Fields {
+		if f.Layout.Parallel == r.Field.Name {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 1 {
+		return fmt.Errorf("arrange=%s requires a parallel=%s slice", r.Field.Layout.Arrange, r.Field.Name)
+	}
+
+	row := 0
+	for _, f := range fields {
+		elementType := extractElementType(f.GoType)
+		if elementType == "" {
+			return fmt.Errorf("parallel slice '%s' must be a slice type, got: %s", f.Name, f.GoType)
+		}
+		if _, ok := registry.Frame(elementType); ok {
+			return fmt.Errorf("parallel slice '%s': length-prefixed %s elements cannot be arranged", f.Name, elementType)
+		}
+		size, err := registry.SizeOf(elementType)
+		if err != nil || size <= 0 {
+			return fmt.Errorf("parallel slice '%s': cannot determine element size for %s", f.Name, elementType)
+		}
+		resolved := registry.ResolveType(elementType)
+		if _, ok := registry.Lookup(resolved); !ok && !isCountType(resolved) && resolved != "byte" {
+			return fmt.Errorf("parallel slice '%s': elements must be integers or layout types, got %s", f.Name, elementType)
+		}
+		r.Members = append(r.Members, Member{Field: f, ElementType: elementType, ElementSize: size, Offset: row})
+		row += size
+	}
+	r.ElementSize, r.ElementStride = row, row
+	return nil
+}
+
+// validateArrange checks arrange= regions and their parallel= slices: counted,
+// forward-growing regions of a copy-mode layout, laid out on their own. Every
+// parallel= slice must name an arrange= slice.
+func validateArrange(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+	arranged := make(map[string]bool)
+	for _, region := range a.Regions {
+		field := region.Field
+		if region.Kind != DynamicRegion || field.Layout.Arrange == "" {
+			continue
+		}
+		arranged[field.Name] = true
+
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': arrange= requires mode=copy, got mode=%s", field.Name, layout.Anno.Mode)
+		}
+		if region.Direction != parser.StartEnd {
+			return fmt.Errorf("field '%s': arrange= requires start-end", field.Name)
+		}
+		if field.Layout.CountField == "" {
+			return fmt.Errorf("field '%s': arrange= requires count=", field.Name)
+		}
+		if field.Layout.Group != "" || field.Layout.ElemAlign > 0 || field.Layout.Stride > 0 || field.Layout.Encode != "" {
+			return fmt.Errorf("field '%s': arrange= cannot be combined with region=, elemalign=, stride= or encode=", field.Name)
+		}
+		for _, f := range layout.Fields {
+			if f.Layout.From == field.Name || f.Layout.Extents == field.Name {
+				return fmt.Errorf("field '%s': indirect slice '%s' cannot use arranged elements as metadata",
+					field.Name, f.Name)
+			}
+		}
+	}
+
+	for _, f := range layout.Fields {
+		if f.Layout.Parallel != "" && !arranged[f.Layout.Parallel] {
+			return fmt.Errorf("field '%s': parallel=%s names no arrange= slice", f.Name, f.Layout.Parallel)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Arrange(t *testing.T) {
+	// @layout size=64
+	// type Index struct {
+	//     N      uint8    `layout:"@0"`
+	//     Keys   []uint32 `layout:"start-end,count=N,arrange=interleave"`
+	//     Values []uint16 `layout:"parallel=Keys"`
+	// }
+	index := func(mode, arrange, parallel, valuesType string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Index",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: mode},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Keys", GoType: "[]uint32", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N", Arrange: arrange}},
+				{Name: "Values", GoType: valuesType, Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, Direction: parser.StartEnd, Parallel: parallel}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"interleave", index("copy", parser.ArrangeInterleave, "Keys", "[]uint16"), ""},
+		{"columnar", index("copy", parser.ArrangeColumnar, "Keys", "[]uint16"), ""},
+		{"zerocopy", index("zerocopy", parser.ArrangeInterleave, "Keys", "[]uint16"), "field 'Keys': arrange= requires mode=copy, got mode=zerocopy"},
+		{"no parallel slice", index("copy", parser.ArrangeInterleave, "Other", "[]uint16"), "arrange=interleave requires a parallel=Keys slice"},
+		{"parallel without arrange", index("copy", "", "Keys", "[]uint16"), "field 'Values': parallel=Keys names no arrange= slice"},
+		{"float elements", index("copy", parser.ArrangeInterleave, "Keys", "[]float64"), "parallel slice 'Values': elements must be integers or layout types, got float64"},
+		{"not a slice", index("copy", parser.ArrangeInterleave, "Keys", "uint16"), "parallel slice 'Values' must be a slice type, got: uint16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				// Values shares the Keys region; rows hold a key and a value
+				if len(analyzed.Regions) != 2 {
+					t.Fatalf("got %d regions, want N and Keys", len(analyzed.Regions))
+				}
+				keys := analyzed.Regions[1]
+				if keys.ElementSize != 6 || keys.ElementStride != 6 || keys.Start != 1 || keys.Boundary != 64 {
+					t.Errorf("Keys region = %+v, want [1, 64) with 6-byte rows", keys)
+				}
+				if len(keys.Members) != 2 || keys.Members[1].Field.Name != "Values" || keys.Members[1].Offset != 4 {
+					t.Errorf("Keys members = %+v, want Keys then Values at 4", keys.Members)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
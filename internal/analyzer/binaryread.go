@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// RegisterBinaryRead records a layout type annotated binaryread=true, whose
+// encoding its own analysis checks against binary.Read, with its endian= byte
+// order. Layouts nesting it in the same byte order may then be checked too.
+func (r *TypeRegistry) RegisterBinaryRead(name, endian string) {
+	if endian == "" {
+		endian = "little"
+	}
+	r.binaryRead[name] = endian
+}
+
+// validateBinaryRead checks that a binaryread=true layout encodes the struct as
+// binary.Read and binary.Write do, in the endian= byte order: every field in
+// declaration order, back to back from offset 0, in as many bytes as the buffer
+// holds. Every deviation is reported, not just the first.
+func validateBinaryRead(layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || !layout.Anno.BinaryRead {
+		return nil
+	}
+	if layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+		return fmt.Errorf("binaryread=true requires mode=copy, got mode=%s", layout.Anno.Mode)
+	}
+
+	endian := layout.Anno.Endian
+	if endian == "" {
+		endian = "little"
+	}
+
+	var deviations []string
+	for _, name := range layout.Omitted {
+		deviations = append(deviations, fmt.Sprintf("binary.Read also reads %s, which has no layout", name))
+	}
+
+	next := 0
+	for _, field := range layout.Fields {
+		fl := field.Layout
+		switch {
+		case fl.Direction != parser.Fixed:
+			deviations = append(deviations, fmt.Sprintf("%s is dynamic, binary.Read only reads fixed-size fields", field.Name))
+			continue
+		case fl.Bits > 0:
+			deviations = append(deviations, fmt.Sprintf("%s is a bit field, binary.Read reads whole bytes", field.Name))
+			continue
+		case fl.Overlap:
+			deviations = append(deviations, fmt.Sprintf("%s overlaps other fields, binary.Read reads them once each", field.Name))
+			continue
+		case fl.Kind != "":
+			deviations = append(deviations, fmt.Sprintf("%s is encoded by kind %s, binary.Read reads its Go value", field.Name, fl.Kind))
+		case fl.Get != "" || fl.Set != "":
+			deviations = append(deviations, fmt.Sprintf("%s is computed by get=/set= hooks, binary.Read reads the field", field.Name))
+		case fl.Default != "" && !fl.Magic:
+			deviations = append(deviations, fmt.Sprintf("%s is encoded as %s when zero, binary.Write writes 0", field.Name, fl.Default))
+		case fl.Present != "":
+			deviations = append(deviations, fmt.Sprintf("%s packs only present slots, binary.Read reads every element", field.Name))
+		case fl.Cap > 0:
+			deviations = append(deviations, fmt.Sprintf("%s zeroes the elements past %s, binary.Write writes every element", field.Name, fl.Used))
+		}
+
+		if fl.Offset != next {
+			deviations = append(deviations, fmt.Sprintf("%s is at offset %d, binary.Read reads it at %d", field.Name, fl.Offset, next))
+		}
+		size, err := binarySize(field.GoType, endian, registry)
+		if err != nil {
+			deviations = append(deviations, fmt.Sprintf("%s: %v", field.Name, err))
+			// Compare the fields after it from where the layout ends it
+			next = fl.Offset + fl.Reserve
+			if size, err := registry.SizeOf(registry.ResolveType(field.GoType)); err == nil && size > 0 {
+				next = fl.Offset + size
+			}
+			continue
+		}
+		if fl.Reserve > 0 && fl.Reserve != size {
+			deviations = append(deviations, fmt.Sprintf("%s reserves %d bytes, binary.Read skips %d for %s", field.Name, fl.Reserve, size, field.GoType))
+		}
+		if resolved := registry.ResolveType(field.GoType); (resolved == "float32" || resolved == "float64") && layout.Anno.FloatPolicy == "strict" {
+			deviations = append(deviations, fmt.Sprintf("%s canonicalizes NaNs (floatpolicy=strict), binary.Write writes the bits as they are", field.Name))
+		}
+		next = fl.Offset + size
+	}
+	if layout.Anno.Size != next {
+		deviations = append(deviations, fmt.Sprintf("binary.Read reads %d bytes, the layout is size=%d", next, layout.Anno.Size))
+	}
+
+	if len(deviations) > 0 {
+		return fmt.Errorf("binaryread=true: the layout differs from binary.Read of %s: %s",
+			layout.Name, strings.Join(deviations, "; "))
+	}
+	return nil
+}
+
+// binarySize returns the bytes binary.Read reads for a field of goType in the
+// given byte order: fixed-size integers, floats, bools and arrays of them, and
+// layout types that are binaryread=true themselves, in the same byte order
+func binarySize(goType, endian string, registry *TypeRegistry) (int, error) {
+	resolved := registry.ResolveType(goType)
+	switch resolved {
+	case "int", "uint", "uintptr":
+		return 0, fmt.Errorf("binary.Read can't read %s, whose size depends on the platform", goType)
+	case "struct{}":
+		return 0, nil
+	}
+	if size, err := SizeOf(resolved); err == nil && size >= 0 {
+		return size, nil
+	}
+	if matches := arrayRe.FindStringSubmatch(resolved); matches != nil {
+		n, _ := strconv.Atoi(matches[1])
+		size, err := binarySize(matches[2], endian, registry)
+		return n * size, err
+	}
+	if nested, ok := registry.binaryRead[resolved]; ok {
+		if nested != endian {
+			return 0, fmt.Errorf("%s is %s endian, binary.Read reads it %s endian", goType, nested, endian)
+		}
+		size, _ := registry.Lookup(resolved)
+		return size, nil
+	}
+	if _, ok := registry.Lookup(resolved); ok {
+		return 0, fmt.Errorf("binary.Read reads %s field by field, annotate it binaryread=true to check it too", goType)
+	}
+	return 0, fmt.Errorf("binary.Read can't read %s", goType)
+}
@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_BinaryRead(t *testing.T) {
+	fixed := func(name, goType string, offset int) parser.Field {
+		return parser.Field{Name: name, GoType: goType, Layout: &parser.FieldLayout{Offset: offset, Direction: parser.Fixed}}
+	}
+	reg := NewTypeRegistry()
+	reg.Register("Inner", 8)
+	reg.RegisterBinaryRead("Inner", "")
+	reg.Register("Other", 8)
+
+	tests := []struct {
+		name    string
+		anno    parser.TypeAnnotation
+		fields  []parser.Field
+		omitted []string
+		wantErr []string
+	}{
+		{
+			// type Msg struct {
+			//     Magic uint32  `layout:"@0,magic=0xCAFE"`
+			//     In    Inner   `layout:"@4"`
+			//     _     [4]byte `layout:"@12"`
+			//     Score float64 `layout:"@16"`
+			// }
+			name: "matches",
+			anno: parser.TypeAnnotation{Size: 24},
+			fields: []parser.Field{
+				{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "0xCAFE", Magic: true}},
+				fixed("In", "Inner", 4),
+				{Name: "_", GoType: "[4]byte", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed, Reserve: 4}},
+				fixed("Score", "float64", 16),
+			},
+		},
+		{
+			// type Msg struct {
+			//     Flags uint8  `layout:"@2"`
+			//     Count int    `layout:"@4"`
+			//     V     uint8  `layout:"@12.0,bits=3"`
+			//     cache []byte
+			// }
+			name: "deviations",
+			anno: parser.TypeAnnotation{Size: 16},
+			fields: []parser.Field{
+				fixed("Flags", "uint8", 2),
+				fixed("Count", "int", 4),
+				{Name: "V", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed, Bits: 3}},
+			},
+			omitted: []string{"cache"},
+			wantErr: []string{
+				"binary.Read also reads cache, which has no layout",
+				"Flags is at offset 2, binary.Read reads it at 0",
+				"Count is at offset 4, binary.Read reads it at 3; Count: binary.Read can't read int",
+				"V is a bit field",
+				"the layout is size=16",
+			},
+		},
+		{
+			name: "nested",
+			anno: parser.TypeAnnotation{Size: 16, Endian: "big"},
+			fields: []parser.Field{
+				fixed("In", "Inner", 0),
+				fixed("Out", "Other", 8),
+			},
+			wantErr: []string{
+				"In: Inner is little endian, binary.Read reads it big endian",
+				"Out: binary.Read reads Other field by field, annotate it binaryread=true",
+			},
+		},
+		{
+			name:    "zerocopy",
+			anno:    parser.TypeAnnotation{Size: 8, Mode: "zerocopy"},
+			fields:  []parser.Field{fixed("ID", "uint64", 0)},
+			wantErr: []string{"binaryread=true requires mode=copy, got mode=zerocopy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anno := tt.anno
+			anno.BinaryRead = true
+			layout := &parser.TypeLayout{Name: "Msg", Anno: &anno, Fields: tt.fields, Omitted: tt.omitted}
+			err := validateBinaryRead(layout, reg)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("validateBinaryRead() error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateBinaryRead() = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validateBinaryRead() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateBlit checks that a blit=true layout encodes the struct as its memory
+// holds it on hosts of the endian= byte order, so that marshaling can copy the
+// struct whole: every field in declaration order, back to back from offset 0,
+// each at an offset its size divides so no compiler pads before it, in as many
+// bytes as the buffer holds. Fields must be integers, floats or byte arrays,
+// encoded as they are. Every deviation is reported, not just the first.
+func validateBlit(layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || !layout.Anno.Blit {
+		return nil
+	}
+	if layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+		return fmt.Errorf("blit=true requires mode=copy, got mode=%s", layout.Anno.Mode)
+	}
+	if layout.Anno.Endian != "" && layout.Anno.Endian != "little" && layout.Anno.Endian != "big" {
+		return fmt.Errorf("blit=true requires endian=little or endian=big, no host stores endian=%s", layout.Anno.Endian)
+	}
+
+	var deviations []string
+	for _, name := range layout.Omitted {
+		deviations = append(deviations, fmt.Sprintf("the struct also holds %s, which has no layout", name))
+	}
+
+	next, widest := 0, 1
+	for _, field := range layout.Fields {
+		fl := field.Layout
+		switch {
+		case fl.Direction != parser.Fixed:
+			deviations = append(deviations, fmt.Sprintf("%s is dynamic, the struct holds a slice header", field.Name))
+			continue
+		case fl.Bits > 0:
+			deviations = append(deviations, fmt.Sprintf("%s is a bit field, the struct holds it in bytes of its own", field.Name))
+			continue
+		case fl.Overlap:
+			deviations = append(deviations, fmt.Sprintf("%s overlaps other fields, the struct holds it apart", field.Name))
+			continue
+		case fl.Reserve > 0:
+			deviations = append(deviations, fmt.Sprintf("%s is reserved, encoded as zero whatever the struct holds", field.Name))
+			next = fl.Offset + fl.Reserve
+			continue
+		case fl.Kind != "":
+			deviations = append(deviations, fmt.Sprintf("%s is encoded by kind %s, the struct holds its Go value", field.Name, fl.Kind))
+		case fl.Get != "" || fl.Set != "":
+			deviations = append(deviations, fmt.Sprintf("%s is computed by get=/set= hooks, the struct holds the field", field.Name))
+		case fl.Present != "":
+			deviations = append(deviations, fmt.Sprintf("%s packs only present slots, the struct holds every element", field.Name))
+		case fl.Cap > 0:
+			deviations = append(deviations, fmt.Sprintf("%s zeroes the elements past %s, the struct holds every element", field.Name, fl.Used))
+		}
+
+		if fl.Offset != next {
+			deviations = append(deviations, fmt.Sprintf("%s is at offset %d, the struct holds it at %d", field.Name, fl.Offset, next))
+		}
+		size, align, err := blitSize(field.GoType, registry)
+		if err != nil {
+			deviations = append(deviations, fmt.Sprintf("%s: %v", field.Name, err))
+			// Compare the fields after it from where the layout ends it
+			next = fl.Offset
+			if size, err := registry.SizeOf(registry.ResolveType(field.GoType)); err == nil && size > 0 {
+				next = fl.Offset + size
+			}
+			continue
+		}
+		if fl.Offset%align != 0 {
+			deviations = append(deviations, fmt.Sprintf("%s is at offset %d, not a multiple of its %d-byte alignment", field.Name, fl.Offset, align))
+		}
+		if resolved := registry.ResolveType(field.GoType); (resolved == "float32" || resolved == "float64") && layout.Anno.FloatPolicy == "strict" {
+			deviations = append(deviations, fmt.Sprintf("%s canonicalizes NaNs (floatpolicy=strict), a copy keeps the bits as they are", field.Name))
+		}
+		next = fl.Offset + size
+		widest = max(widest, align)
+	}
+	if layout.Anno.Size != next {
+		deviations = append(deviations, fmt.Sprintf("the struct holds %d bytes, the layout is size=%d", next, layout.Anno.Size))
+	} else if next%widest != 0 {
+		deviations = append(deviations, fmt.Sprintf("the struct is padded to a multiple of its %d-byte alignment, the layout is size=%d", widest, next))
+	}
+
+	if len(deviations) > 0 {
+		return fmt.Errorf("blit=true: the layout differs from the memory of %s: %s",
+			layout.Name, strings.Join(deviations, "; "))
+	}
+	return nil
+}
+
+// blitSize returns the bytes a field of goType takes in memory and the alignment
+// the layout requires of it: sized integers and floats, whose memory is their
+// encoding in the host's byte order, aligned to their size, and byte arrays.
+// 64-bit fields are aligned to 8 bytes, which 32-bit hosts, aligning them to 4,
+// agree with.
+func blitSize(goType string, registry *TypeRegistry) (int, int, error) {
+	resolved := registry.ResolveType(goType)
+	switch resolved {
+	case "int", "uint", "uintptr":
+		return 0, 0, fmt.Errorf("%s has a size depending on the platform", goType)
+	case "bool":
+		return 0, 0, fmt.Errorf("%s is a bool, which a copy could set to bytes other than 0 and 1", goType)
+	case "uint8", "int8", "byte", "uint16", "int16", "uint32", "int32", "uint64", "int64", "float32", "float64":
+		size, err := SizeOf(resolved)
+		return size, size, err
+	}
+	if matches := arrayRe.FindStringSubmatch(resolved); matches != nil && (matches[2] == "byte" || matches[2] == "uint8") {
+		n, _ := strconv.Atoi(matches[1])
+		return n, 1, nil
+	}
+	if _, ok := registry.Lookup(resolved); ok {
+		return 0, 0, fmt.Errorf("%s is a layout type, encoded field by field", goType)
+	}
+	return 0, 0, fmt.Errorf("a copy can't encode %s", goType)
+}
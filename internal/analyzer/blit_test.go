@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Blit(t *testing.T) {
+	fixed := func(name, goType string, offset int) parser.Field {
+		return parser.Field{Name: name, GoType: goType, Layout: &parser.FieldLayout{Offset: offset, Direction: parser.Fixed}}
+	}
+	reg := NewTypeRegistry()
+	reg.Register("Inner", 8)
+	reg.RegisterAlias("PageID", "uint64")
+
+	tests := []struct {
+		name    string
+		anno    parser.TypeAnnotation
+		fields  []parser.Field
+		omitted []string
+		wantErr []string
+	}{
+		{
+			// type Rec struct {
+			//     Magic uint32   `layout:"@0,magic=0xCAFE"`
+			//     Flags uint16   `layout:"@4"`
+			//     Kind  [2]uint8 `layout:"@6"`
+			//     Page  PageID   `layout:"@8"`
+			//     Score float64  `layout:"@16"`
+			//     Key   [16]byte `layout:"@24"`
+			// }
+			name: "matches",
+			anno: parser.TypeAnnotation{Size: 40},
+			fields: []parser.Field{
+				{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "0xCAFE", Magic: true}},
+				fixed("Flags", "uint16", 4),
+				fixed("Kind", "[2]uint8", 6),
+				fixed("Page", "PageID", 8),
+				fixed("Score", "float64", 16),
+				fixed("Key", "[16]byte", 24),
+			},
+		},
+		{
+			// type Rec struct {
+			//     Flags uint8   `layout:"@2"`
+			//     Count int     `layout:"@4"`
+			//     V     uint8   `layout:"@12.0,bits=3"`
+			//     _     [2]byte `layout:"@13"`
+			//     OK    bool    `layout:"@15"`
+			//     cache []byte
+			// }
+			name: "deviations",
+			anno: parser.TypeAnnotation{Size: 16},
+			fields: []parser.Field{
+				fixed("Flags", "uint8", 2),
+				fixed("Count", "int", 4),
+				{Name: "V", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed, Bits: 3}},
+				{Name: "_", GoType: "[2]byte", Layout: &parser.FieldLayout{Offset: 13, Direction: parser.Fixed, Reserve: 2}},
+				fixed("OK", "bool", 15),
+			},
+			omitted: []string{"cache"},
+			wantErr: []string{
+				"the struct also holds cache, which has no layout",
+				"Flags is at offset 2, the struct holds it at 0",
+				"Count is at offset 4, the struct holds it at 3; Count: int has a size depending on the platform",
+				"V is a bit field",
+				"_ is reserved, encoded as zero whatever the struct holds",
+				"OK: bool is a bool",
+			},
+		},
+		{
+			// type Rec struct {
+			//     Flags uint16    `layout:"@0"`
+			//     ID    uint32    `layout:"@2"`
+			//     In    Inner     `layout:"@6"`
+			//     Keys  [2]uint16 `layout:"@14"`
+			//     Tail  uint8     `layout:"@18"`
+			// }
+			name: "alignment",
+			anno: parser.TypeAnnotation{Size: 19},
+			fields: []parser.Field{
+				fixed("Flags", "uint16", 0),
+				fixed("ID", "uint32", 2),
+				fixed("In", "Inner", 6),
+				fixed("Keys", "[2]uint16", 14),
+				fixed("Tail", "uint8", 18),
+			},
+			wantErr: []string{
+				"ID is at offset 2, not a multiple of its 4-byte alignment",
+				"In: Inner is a layout type",
+				"Keys: a copy can't encode [2]uint16",
+				"the struct is padded to a multiple of its 4-byte alignment, the layout is size=19",
+			},
+		},
+		{
+			name:    "strict floats",
+			anno:    parser.TypeAnnotation{Size: 8, FloatPolicy: "strict"},
+			fields:  []parser.Field{fixed("Score", "float64", 0)},
+			wantErr: []string{"Score canonicalizes NaNs (floatpolicy=strict)"},
+		},
+		{
+			name:    "zerocopy",
+			anno:    parser.TypeAnnotation{Size: 8, Mode: "zerocopy"},
+			fields:  []parser.Field{fixed("ID", "uint64", 0)},
+			wantErr: []string{"blit=true requires mode=copy, got mode=zerocopy"},
+		},
+		{
+			name:    "pdp",
+			anno:    parser.TypeAnnotation{Size: 8, Endian: "pdp"},
+			fields:  []parser.Field{fixed("ID", "uint64", 0)},
+			wantErr: []string{"blit=true requires endian=little or endian=big"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anno := tt.anno
+			anno.Blit = true
+			layout := &parser.TypeLayout{Name: "Rec", Anno: &anno, Fields: tt.fields, Omitted: tt.omitted}
+			err := validateBlit(layout, reg)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("validateBlit() error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("validateBlit() = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("validateBlit() error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
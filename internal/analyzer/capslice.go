@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateCapSlices checks inline slices, marked cap=N,count=F: an [N]T array of
+// integers in a copy-mode layout, whose unsigned count field F can reach N. The
+// count must sit at a lower offset, so unmarshal, which decodes fields in offset
+// order, has it before the elements, and must count nothing else.
+func validateCapSlices(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	for i, r := range a.Regions {
+		capacity, used := r.Field.Layout.Cap, r.Field.Layout.Used
+		if capacity == 0 {
+			continue
+		}
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': cap= requires mode=copy", r.Field.Name)
+		}
+		n, elemType, ok := PresentSlots(r.Field.GoType)
+		resolved := registry.ResolveType(elemType)
+		if !ok || !isCountType(resolved) && resolved != "byte" || r.isAlias() {
+			return fmt.Errorf("field '%s': cap= requires an [N]T array of integers, got %s", r.Field.Name, r.Field.GoType)
+		}
+		if n != capacity {
+			return fmt.Errorf("field '%s': cap=%d requires a [%d]T array, got %s", r.Field.Name, capacity, capacity, r.Field.GoType)
+		}
+
+		j := regionIndex(a.Regions, used)
+		if j < 0 {
+			return fmt.Errorf("field '%s': count field '%s' not found", r.Field.Name, used)
+		}
+		count := a.Regions[j]
+		bits := 0
+		switch registry.ResolveType(count.Field.GoType) {
+		case "uint8", "byte":
+			bits = 8
+		case "uint16":
+			bits = 16
+		case "uint32":
+			bits = 32
+		case "uint64":
+			bits = 64
+		default:
+			return fmt.Errorf("field '%s': count field '%s' must be an unsigned integer, got %s", r.Field.Name, used, count.Field.GoType)
+		}
+		if count.Bits > 0 {
+			bits = count.Bits
+		}
+		if bits < 64 && uint64(1)<<bits-1 < uint64(capacity) {
+			return fmt.Errorf("field '%s': count field '%s' holds at most %d, below cap=%d", r.Field.Name, used, uint64(1)<<bits-1, capacity)
+		}
+		if j > i {
+			return fmt.Errorf("field '%s': count field '%s' must be at a lower offset", r.Field.Name, used)
+		}
+		for _, field := range layout.Fields {
+			if field.Name == r.Field.Name || field.Layout == nil {
+				continue
+			}
+			if field.Layout.CountField == used || field.Layout.Used == used {
+				return fmt.Errorf("field '%s': count field '%s' also counts '%s'", r.Field.Name, used, field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// regionIndex returns the index of the fixed region of the named field, or -1
+func regionIndex(regions []Region, name string) int {
+	for i, r := range regions {
+		if r.Kind == FixedRegion && r.Field.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_CapSlices(t *testing.T) {
+	// @layout size=64
+	// type Batch struct {
+	//     NumItems uint8     `layout:"@0"`
+	//     BodyLen  uint8     `layout:"@1"`
+	//     Items    [8]uint32 `layout:"@4,cap=8,count=NumItems"`
+	//     Body     []byte    `layout:"@36,start-end,count=BodyLen"`
+	// }
+	batch := func(mode string, count, items parser.Field) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Batch",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: mode},
+			Fields: []parser.Field{count, items,
+				{Name: "BodyLen", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+				{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: 36, Direction: parser.StartEnd, CountField: "BodyLen"}},
+			},
+		}
+	}
+	count := func(goType string, offset, bits int) parser.Field {
+		return parser.Field{Name: "NumItems", GoType: goType, Layout: &parser.FieldLayout{Offset: offset, Direction: parser.Fixed, Bits: bits}}
+	}
+	items := func(goType string, capacity int, used string) parser.Field {
+		return parser.Field{Name: "Items", GoType: goType, Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Cap: capacity, Used: used}}
+	}
+	shared := batch("copy", count("uint8", 0, 0), items("[8]uint32", 8, "BodyLen"))
+	alias := batch("copy", count("uint8", 0, 0), items("[8]uint32", 8, "NumItems"))
+	alias.Fields[1].Layout.Overlap = true
+	collide := batch("copy", count("uint8", 0, 0), items("[8]uint32", 8, "NumItems"))
+	collide.Fields[2].Name, collide.Fields[3].Layout.CountField = "ItemsSlice", "ItemsSlice"
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"valid", batch("copy", count("uint8", 0, 0), items("[8]uint32", 8, "NumItems")), ""},
+		{"byte elements", batch("copy", count("uint16", 0, 0), items("[8]byte", 8, "NumItems")), ""},
+		{"bit field count", batch("copy", count("uint8", 0, 4), items("[8]int16", 8, "NumItems")), ""},
+		{"zerocopy", batch("zerocopy", count("uint8", 0, 0), items("[8]uint32", 8, "NumItems")), "field 'Items': cap= requires mode=copy"},
+		{"float elements", batch("copy", count("uint8", 0, 0), items("[8]float32", 8, "NumItems")),
+			"cap= requires an [N]T array of integers, got [8]float32"},
+		{"alias", alias, "cap= requires an [N]T array of integers"},
+		{"cap mismatch", batch("copy", count("uint8", 0, 0), items("[8]uint32", 6, "NumItems")), "field 'Items': cap=6 requires a [6]T array, got [8]uint32"},
+		{"signed count", batch("copy", count("int8", 0, 0), items("[8]uint32", 8, "NumItems")),
+			"count field 'NumItems' must be an unsigned integer, got int8"},
+		{"narrow count", batch("copy", count("uint8", 0, 2), items("[8]uint32", 8, "NumItems")), "count field 'NumItems' holds at most 3, below cap=8"},
+		{"count after items", batch("copy", count("uint8", 60, 0), items("[8]uint32", 8, "NumItems")),
+			"count field 'NumItems' must be at a lower offset"},
+		{"count missing", batch("copy", count("uint8", 0, 0), items("[8]uint32", 8, "Len")), "count field 'Len' not found"},
+		{"shared count", shared, "count field 'BodyLen' also counts 'Body'"},
+		{"helper name", collide, "field 'ItemsSlice' collides with the ItemsSlice method generated for cap="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateChain checks a next= layout: copy mode, an unsigned fixed field for the
+// continuation page ID, and a single counted []byte field to split across pages
+func validateChain(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || layout.Anno.Next == "" {
+		return nil
+	}
+	next := layout.Anno.Next
+
+	if layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+		return fmt.Errorf("next=%s requires mode=copy", next)
+	}
+	for _, field := range layout.Fields {
+		if field.Layout.From != "" || field.Layout.Extents != "" {
+			return fmt.Errorf("next=%s: field '%s': chained layouts can't hold indirect slices", next, field.Name)
+		}
+	}
+
+	found := false
+	var chained []Region
+	for _, r := range a.Regions {
+		if r.Kind == DynamicRegion {
+			chained = append(chained, r)
+			continue
+		}
+		if r.Field.Name != next {
+			continue
+		}
+		found = true
+		switch registry.ResolveType(r.Field.GoType) {
+		case "uint16", "uint32", "uint64":
+		default:
+			return fmt.Errorf("next field '%s' must be uint16, uint32 or uint64, got %s", next, r.Field.GoType)
+		}
+		if r.Bits > 0 || r.isAlias() {
+			return fmt.Errorf("next field '%s' must be a plain fixed field", next)
+		}
+	}
+	if !found {
+		return fmt.Errorf("next field '%s' not found", next)
+	}
+
+	if len(chained) != 1 {
+		return fmt.Errorf("next=%s requires exactly one dynamic field to chain, got %d", next, len(chained))
+	}
+	if r := chained[0]; r.Field.GoType != "[]byte" || r.Field.Layout.CountField == "" {
+		return fmt.Errorf("field '%s': next=%s chains a []byte field with count=, got %s", r.Field.Name, next, r.Field.GoType)
+	}
+	return nil
+}
+
+// chained reports whether r is the dynamic field a next= layout splits across pages
+func chained(r Region, layout *parser.TypeLayout) bool {
+	return r.Kind == DynamicRegion && layout.Anno != nil && layout.Anno.Next != ""
+}
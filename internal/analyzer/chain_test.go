@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Chain(t *testing.T) {
+	// @layout size=64 next=Next
+	// type Blob struct {
+	//     Len  uint16 `layout:"@0"`
+	//     Next uint32 `layout:"@4"`
+	//     Data []byte `layout:"@8,start-end,count=Len"`
+	// }
+	blob := func(mode, nextType string, data parser.Field) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Blob",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: mode, Next: "Next"},
+			Fields: []parser.Field{
+				{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Next", GoType: nextType, Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+				data,
+			},
+		}
+	}
+	data := parser.Field{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "Len"}}
+	uncounted := parser.Field{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: 8}}
+	noDynamic := parser.Field{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"valid", blob("copy", "uint32", data), ""},
+		{"zerocopy", blob("zerocopy", "uint32", data), "next=Next requires mode=copy"},
+		{"signed next field", blob("copy", "int32", data), "next field 'Next' must be uint16, uint32 or uint64, got int32"},
+		{"no count", blob("copy", "uint32", uncounted), "field 'Data': next=Next chains a []byte field with count="},
+		{"nothing to chain", blob("copy", "uint32", noDynamic), "next=Next requires exactly one dynamic field to chain, got 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				if len(analyzed.Warnings) != 0 {
+					t.Errorf("Chained field warned about its worst case: %v", analyzed.Warnings)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+
+	layout := blob("copy", "uint32", data)
+	layout.Anno.Next = "Missing"
+	if _, err := Analyze(layout, NewTypeRegistry()); err == nil || !strings.Contains(err.Error(), "next field 'Missing' not found") {
+		t.Errorf("Analyze() error = %v, want next field not found", err)
+	}
+}
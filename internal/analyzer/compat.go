@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Change is one difference between two versions of a layout. Breaking changes
+// make buffers encoded by one version decode wrongly, or not at all, with the
+// other; the rest are compatible additions and relaxations.
+type Change struct {
+	Type     string
+	Field    string // Empty for changes to the type itself
+	Breaking bool
+	Message  string
+}
+
+// String returns the change as "Type.Field: message"
+func (c Change) String() string {
+	if c.Field == "" {
+		return fmt.Sprintf("%s: %s", c.Type, c.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s", c.Type, c.Field, c.Message)
+}
+
+// Compare reports the changes between the old and new versions of a file's
+// layouts, matching types and fields by name. Types and fields are compared as
+// encoded: moved offsets, resized or removed fields, changed element sizes and
+// count fields break compatibility, while fields added in bytes no old field
+// used and raised max= bounds don't.
+func Compare(old, new []Report) []Change {
+	newTypes := make(map[string]Report)
+	for _, r := range new {
+		newTypes[r.Type] = r
+	}
+	oldTypes := make(map[string]bool)
+
+	var changes []Change
+	for _, o := range old {
+		oldTypes[o.Type] = true
+		n, ok := newTypes[o.Type]
+		if !ok {
+			changes = append(changes, Change{Type: o.Type, Breaking: true, Message: "type removed"})
+			continue
+		}
+		changes = append(changes, compareType(o, n)...)
+	}
+	for _, n := range new {
+		if !oldTypes[n.Type] {
+			changes = append(changes, Change{Type: n.Type, Message: "type added"})
+		}
+	}
+	return changes
+}
+
+// compareType reports the changes between two versions of one type
+func compareType(old, new Report) []Change {
+	var changes []Change
+	if old.Size != new.Size {
+		changes = append(changes, Change{Type: old.Type, Breaking: true,
+			Message: fmt.Sprintf("size changed from %d to %d bytes", old.Size, new.Size)})
+	}
+	changes = append(changes, compareRegions(old.Type, old.Regions, new.Regions)...)
+	return append(changes, compareRegions(old.Type, old.Trailer, new.Trailer)...)
+}
+
+// compareRegions reports the changes between two versions of a type's regions,
+// or of its stream frame trailer
+func compareRegions(typeName string, old, new []RegionReport) []Change {
+	var changes []Change
+	breaking := func(field, format string, args ...any) {
+		changes = append(changes, Change{Type: typeName, Field: field, Breaking: true, Message: fmt.Sprintf(format, args...)})
+	}
+	compatible := func(field, format string, args ...any) {
+		changes = append(changes, Change{Type: typeName, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	newFields := make(map[string]RegionReport)
+	for _, r := range new {
+		newFields[r.Field] = r
+	}
+	oldFields := make(map[string]bool)
+
+	for _, o := range old {
+		oldFields[o.Field] = true
+		n, ok := newFields[o.Field]
+		if !ok {
+			breaking(o.Field, "removed from %s", extent(o))
+			continue
+		}
+		switch {
+		case o.Kind != n.Kind || o.Direction != n.Direction:
+			breaking(o.Field, "changed from %s %s to %s %s", o.Direction, o.Kind, n.Direction, n.Kind)
+		case o.Kind == "fixed" && (o.Start != n.Start || o.Boundary != n.Boundary || o.BitOffset != n.BitOffset || o.Bits != n.Bits):
+			breaking(o.Field, "moved from %s to %s", extent(o), extent(n))
+		case o.Kind == "dynamic" && o.Start != n.Start:
+			breaking(o.Field, "moved to start at %d instead of %d", n.Start, o.Start)
+		case o.Kind == "dynamic" && capacity(n) < capacity(o):
+			breaking(o.Field, "shrank from %s to %s", extent(o), extent(n))
+		}
+		if o.Encode != n.Encode {
+			breaking(o.Field, "encoding changed from %s to %s", orNone(o.Encode), orNone(n.Encode))
+		} else if o.ElementSize != n.ElementSize || o.Framed != n.Framed {
+			breaking(o.Field, "element size changed from %d to %d bytes", o.ElementSize, n.ElementSize)
+		} else if stride(o) != stride(n) {
+			breaking(o.Field, "element stride changed from %d to %d bytes", stride(o), stride(n))
+		} else if o.GoType != n.GoType && extent(o) == extent(n) {
+			breaking(o.Field, "type changed from %s to %s", o.GoType, n.GoType)
+		}
+		if o.Arrange != n.Arrange || !slices.Equal(o.Parallel, n.Parallel) {
+			breaking(o.Field, "arrangement changed from %s to %s", arrangement(o), arrangement(n))
+		}
+		if o.CountField != n.CountField {
+			breaking(o.Field, "count field changed from %q to %q", o.CountField, n.CountField)
+		}
+		switch {
+		case o.MaxCount == n.MaxCount:
+		case n.MaxCount != 0 && (o.MaxCount == 0 || n.MaxCount < o.MaxCount):
+			breaking(o.Field, "max= lowered to %d, old buffers may hold more", n.MaxCount)
+		default:
+			compatible(o.Field, "max= raised, old buffers stay in bounds")
+		}
+	}
+
+	for _, n := range new {
+		if oldFields[n.Field] {
+			continue
+		}
+		if o, ok := overlapping(n, old); ok {
+			breaking(n.Field, "added at %s over the bytes of %s", extent(n), o.Field)
+		} else {
+			compatible(n.Field, "added at %s, unused by the old layout", extent(n))
+		}
+	}
+	return changes
+}
+
+// capacity returns the bytes a dynamic region can grow into
+func capacity(r RegionReport) int {
+	if r.Direction == "end-start" {
+		return r.Start - r.Boundary
+	}
+	return r.Boundary - r.Start
+}
+
+// stride returns the distance between a dynamic region's element starts
+func stride(r RegionReport) int {
+	if r.ElementStride > 0 {
+		return r.ElementStride
+	}
+	return r.ElementSize
+}
+
+// bitRange returns the bits a region covers, [lo, hi)
+func bitRange(r RegionReport) (int, int) {
+	if r.Direction == "end-start" {
+		return r.Boundary * 8, r.Start * 8
+	}
+	lo := r.Start*8 + r.BitOffset
+	if r.Bits > 0 {
+		return lo, lo + r.Bits
+	}
+	return lo, r.Boundary * 8
+}
+
+// overlapping returns the first of regions whose bits r overlaps
+func overlapping(r RegionReport, regions []RegionReport) (RegionReport, bool) {
+	lo, hi := bitRange(r)
+	for _, o := range regions {
+		if olo, ohi := bitRange(o); max(lo, olo) < min(hi, ohi) {
+			return o, true
+		}
+	}
+	return RegionReport{}, false
+}
+
+// extent formats the bytes, or bits, a region covers
+func extent(r RegionReport) string {
+	if r.Bits > 0 {
+		return fmt.Sprintf("byte %d bits [%d, %d)", r.Start, r.BitOffset, r.BitOffset+r.Bits)
+	}
+	lo, hi := bitRange(r)
+	return fmt.Sprintf("[%d, %d)", lo/8, hi/8)
+}
+
+// arrangement describes the slices sharing a region and how they are arranged
+func arrangement(r RegionReport) string {
+	if r.Arrange == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s with %s", r.Arrange, strings.Join(r.Parallel, ", "))
+}
+
+// orNone names an unset encoding
+func orNone(encoding string) string {
+	if encoding == "" {
+		return "none"
+	}
+	return encoding
+}
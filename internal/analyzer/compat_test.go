@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestCompare(t *testing.T) {
+	// @layout size=64
+	// type Node struct {
+	//     Magic uint32 `layout:"@0"`
+	//     N     uint16 `layout:"@4"`
+	//     Keys  []byte `layout:"start-end,count=N,max=20"`
+	//     Tail  uint32 `layout:"@56"`
+	// }
+	node := func(size int, fields ...parser.Field) Report {
+		layout := &parser.TypeLayout{
+			Name: "Node",
+			Anno: &parser.TypeAnnotation{Size: size},
+			Fields: append([]parser.Field{
+				{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			}, fields...),
+		}
+		analyzed, err := Analyze(layout, NewTypeRegistry())
+		if err != nil {
+			t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+		}
+		return analyzed.Report()
+	}
+	keys := func(start, maxCount int) parser.Field {
+		return parser.Field{Name: "Keys", GoType: "[]byte", Layout: &parser.FieldLayout{
+			Offset: -1, Direction: parser.StartEnd, StartAt: start, CountField: "N", MaxCount: maxCount}}
+	}
+	fixed := func(name, goType string, offset int) parser.Field {
+		return parser.Field{Name: name, GoType: goType, Layout: &parser.FieldLayout{Offset: offset, Direction: parser.Fixed}}
+	}
+	old := node(64, keys(-1, 20), fixed("Tail", "uint32", 56))
+
+	tests := []struct {
+		name    string
+		new     Report
+		changes []string // "breaking: ..." or "compatible: ..."
+	}{
+		{"unchanged", node(64, keys(-1, 20), fixed("Tail", "uint32", 56)), nil},
+		{"field added in unused bytes", node(64, keys(-1, 20), fixed("Tail", "uint32", 56), fixed("Extra", "uint16", 60)), []string{
+			"compatible: Node.Extra: added at [60, 62), unused by the old layout",
+		}},
+		{"field added over a region", node(64, keys(-1, 20), fixed("Tail", "uint32", 56), fixed("Flags", "uint8", 40)), []string{
+			"breaking: Node.Keys: shrank from [6, 56) to [6, 40)",
+			"breaking: Node.Flags: added at [40, 41) over the bytes of Keys",
+		}},
+		{"field added before a region", node(64, fixed("Flags", "uint8", 6), keys(-1, 20), fixed("Tail", "uint32", 56)), []string{
+			"breaking: Node.Keys: moved to start at 7 instead of 6",
+			"breaking: Node.Flags: added at [6, 7) over the bytes of Keys",
+		}},
+		{"field resized", node(64, keys(-1, 20), fixed("Tail", "uint64", 56)), []string{
+			"breaking: Node.Tail: moved from [56, 60) to [56, 64)",
+		}},
+		{"field retyped", node(64, keys(-1, 20), fixed("Tail", "int32", 56)), []string{
+			"breaking: Node.Tail: type changed from uint32 to int32",
+		}},
+		{"max= raised", node(64, keys(-1, 40), fixed("Tail", "uint32", 56)), []string{
+			"compatible: Node.Keys: max= raised, old buffers stay in bounds",
+		}},
+		{"max= lowered", node(64, keys(-1, 10), fixed("Tail", "uint32", 56)), []string{
+			"breaking: Node.Keys: max= lowered to 10, old buffers may hold more",
+		}},
+		{"field removed", node(64, keys(-1, 20)), []string{
+			"breaking: Node.Tail: removed from [56, 60)",
+		}},
+		{"size changed", node(128, keys(-1, 20), fixed("Tail", "uint32", 56)), []string{
+			"breaking: Node: size changed from 64 to 128 bytes",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range Compare([]Report{old}, []Report{tt.new}) {
+				kind := "compatible"
+				if c.Breaking {
+					kind = "breaking"
+				}
+				got = append(got, kind+": "+c.String())
+			}
+			if len(got) != len(tt.changes) {
+				t.Fatalf("Compare() = %q, want %q", got, tt.changes)
+			}
+			for i := range got {
+				if got[i] != tt.changes[i] {
+					t.Errorf("change %d = %q, want %q", i, got[i], tt.changes[i])
+				}
+			}
+		})
+	}
+
+	changes := Compare([]Report{old}, nil)
+	if len(changes) != 1 || !changes[0].Breaking || changes[0].String() != "Node: type removed" {
+		t.Errorf("Compare() of a removed type = %v", changes)
+	}
+	changes = Compare(nil, []Report{old})
+	if len(changes) != 1 || changes[0].Breaking || changes[0].String() != "Node: type added" {
+		t.Errorf("Compare() of an added type = %v", changes)
+	}
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateCStruct checks the annotations of a layout mirroring a C struct: the
+// generated cgo test includes the cinclude= headers to reach the cstruct= type,
+// whose members cfield= names, and compares them against fixed offsets, which
+// stream frames don't have
+func validateCStruct(layout *parser.TypeLayout) error {
+	anno := layout.Anno
+	if anno == nil {
+		return nil
+	}
+	if anno.CStruct == "" {
+		if len(anno.CInclude) > 0 {
+			return fmt.Errorf("cinclude= requires cstruct= naming the C type to compare against")
+		}
+		for _, field := range layout.Fields {
+			if field.Layout.CField != "" {
+				return fmt.Errorf("field '%s': cfield=%s requires cstruct= on the type", field.Name, field.Layout.CField)
+			}
+		}
+		return nil
+	}
+
+	if anno.Mode == "stream" {
+		return fmt.Errorf("cstruct=%s requires mode=copy or zerocopy, frames have no fixed layout", anno.CStruct)
+	}
+	if len(anno.CInclude) == 0 {
+		return fmt.Errorf("cstruct=%s requires cinclude= naming the header declaring it", anno.CStruct)
+	}
+	return nil
+}
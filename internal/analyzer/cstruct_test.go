@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_CStruct(t *testing.T) {
+	tests := []struct {
+		name    string
+		anno    parser.TypeAnnotation
+		cfield  string
+		wantErr string
+	}{
+		{"mirrored", parser.TypeAnnotation{Size: 8, CStruct: "struct_dev", CInclude: []string{"dev.h"}}, "id", ""},
+		{"no header", parser.TypeAnnotation{Size: 8, CStruct: "struct_dev"}, "",
+			"cstruct=struct_dev requires cinclude= naming the header declaring it"},
+		{"header only", parser.TypeAnnotation{Size: 8, CInclude: []string{"dev.h"}}, "",
+			"cinclude= requires cstruct= naming the C type to compare against"},
+		{"member only", parser.TypeAnnotation{Size: 8}, "id",
+			"field 'ID': cfield=id requires cstruct= on the type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// @layout size=8 cstruct=struct_dev cinclude=dev.h
+			// type Dev struct {
+			//     ID uint64 `layout:"@0,cfield=id"`
+			// }
+			anno := tt.anno
+			layout := &parser.TypeLayout{
+				Name: "Dev",
+				Anno: &anno,
+				Fields: []parser.Field{
+					{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, CField: tt.cfield}},
+				},
+			}
+			analyzed, err := Analyze(layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+			} else if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
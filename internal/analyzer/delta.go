@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateDelta checks encode=delta regions: counted, forward-growing slices of
+// unsigned integers in a copy-mode layout. Each element is a varint as long as
+// its difference from the one before it, so elements are found by walking the
+// region from its start, and nothing may size the region up front (region=
+// chains, free space shared with an end-start region).
+func validateDelta(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	for _, region := range a.Regions {
+		field := region.Field
+		if region.Kind != DynamicRegion || field.Layout.Encode != parser.EncodeDelta {
+			continue
+		}
+
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': encode=delta requires mode=copy, got mode=%s", field.Name, layout.Anno.Mode)
+		}
+		switch registry.ResolveType(region.ElementType) {
+		case "uint16", "uint32", "uint64":
+		default:
+			return fmt.Errorf("field '%s': encode=delta requires a slice of uint16, uint32 or uint64, got %s",
+				field.Name, field.GoType)
+		}
+		if region.Direction != parser.StartEnd {
+			return fmt.Errorf("field '%s': encode=delta requires start-end", field.Name)
+		}
+		if field.Layout.Group != "" {
+			return fmt.Errorf("field '%s': encode=delta cannot be combined with region=", field.Name)
+		}
+		for _, gap := range a.Gaps {
+			if gap.Forward.Field.Name == field.Name {
+				return fmt.Errorf("field '%s': encode=delta cannot share free space with '%s'",
+					field.Name, gap.Backward.Field.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Delta(t *testing.T) {
+	// @layout size=64
+	// type Postings struct {
+	//     N   uint8    `layout:"@0"`
+	//     IDs []uint32 `layout:"start-end,count=N,encode=delta"`
+	// }
+	postings := func(mode, goType string, direction parser.PackDirection, countField string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Postings",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: mode},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "IDs", GoType: goType, Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, Direction: direction, CountField: countField, Encode: parser.EncodeDelta}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"valid", postings("copy", "[]uint32", parser.StartEnd, "N"), ""},
+		{"zerocopy", postings("zerocopy", "[]uint32", parser.StartEnd, "N"), "field 'IDs': encode=delta requires mode=copy, got mode=zerocopy"},
+		{"signed elements", postings("copy", "[]int32", parser.StartEnd, "N"), "encode=delta requires a slice of uint16, uint32 or uint64, got []int32"},
+		{"end-start", postings("copy", "[]uint32", parser.EndStart, "N"), "field 'IDs': encode=delta requires start-end"},
+		{"no count", postings("copy", "[]uint32", parser.StartEnd, ""), "field 'IDs' (type []uint32) requires count="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				// Deltas take a byte or more, so the count is bounded by the bytes
+				if ids := analyzed.Regions[1]; ids.ElementSize != 1 || ids.Start != 1 || ids.Boundary != 64 {
+					t.Errorf("IDs region = %+v, want [1, 64) with element size 1", ids)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
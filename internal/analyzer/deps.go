@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// SortByDependency orders layouts so every type comes after the layout types its
+// fields embed (fixed struct fields, struct arrays and struct slices). Independent
+// types keep their declaration order. Returns an error naming the cycle if types
+// depend on each other.
+func SortByDependency(layouts []*parser.TypeLayout) ([]*parser.TypeLayout, error) {
+	byName := make(map[string]*parser.TypeLayout, len(layouts))
+	for _, layout := range layouts {
+		byName[layout.Name] = layout
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(layouts))
+	sorted := make([]*parser.TypeLayout, 0, len(layouts))
+	var path []string
+
+	var visit func(layout *parser.TypeLayout) error
+	visit = func(layout *parser.TypeLayout) error {
+		switch state[layout.Name] {
+		case done:
+			return nil
+		case visiting:
+			// Report the cycle starting from the first occurrence of this type
+			for i, name := range path {
+				if name == layout.Name {
+					cycle := append(path[i:len(path):len(path)], layout.Name)
+					return fmt.Errorf("type dependency cycle: %s", strings.Join(cycle, " -> "))
+				}
+			}
+		}
+
+		state[layout.Name] = visiting
+		path = append(path, layout.Name)
+		for _, field := range layout.Fields {
+			dep, ok := byName[baseTypeName(field.GoType)]
+			if !ok {
+				continue // Primitive, alias or type outside this set
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[layout.Name] = done
+
+		sorted = append(sorted, layout)
+		return nil
+	}
+
+	for _, layout := range layouts {
+		if err := visit(layout); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// baseTypeName strips slice and array prefixes: [][]byte → byte, [4]Elem → Elem
+func baseTypeName(goType string) string {
+	for strings.HasPrefix(goType, "[") {
+		end := strings.Index(goType, "]")
+		if end < 0 {
+			break
+		}
+		goType = goType[end+1:]
+	}
+	return goType
+}
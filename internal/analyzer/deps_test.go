@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestSortByDependency(t *testing.T) {
+	newLayout := func(name string, fieldTypes ...string) *parser.TypeLayout {
+		layout := &parser.TypeLayout{Name: name, Anno: &parser.TypeAnnotation{Size: 16}}
+		for i, goType := range fieldTypes {
+			layout.Fields = append(layout.Fields, parser.Field{
+				Name: string(rune('A' + i)), GoType: goType, Layout: &parser.FieldLayout{},
+			})
+		}
+		return layout
+	}
+
+	// LeafNode is declared before the types it embeds
+	layouts := []*parser.TypeLayout{
+		newLayout("LeafNode", "Header", "[]LeafElement", "[][]byte"),
+		newLayout("Standalone", "uint64"),
+		newLayout("LeafElement", "uint16", "[2]Key"),
+		newLayout("Key", "[8]byte"),
+		newLayout("Header", "uint32"),
+	}
+
+	sorted, err := SortByDependency(layouts)
+	if err != nil {
+		t.Fatalf("SortByDependency() error: %v", err)
+	}
+
+	var names []string
+	for _, layout := range sorted {
+		names = append(names, layout.Name)
+	}
+	got := strings.Join(names, ",")
+	want := "Header,Key,LeafElement,LeafNode,Standalone"
+	if got != want {
+		t.Errorf("SortByDependency() = %s, want %s", got, want)
+	}
+}
+
+func TestSortByDependency_Cycle(t *testing.T) {
+	layouts := []*parser.TypeLayout{
+		{Name: "Root", Fields: []parser.Field{{Name: "A", GoType: "A", Layout: &parser.FieldLayout{}}}},
+		{Name: "A", Fields: []parser.Field{{Name: "B", GoType: "[]B", Layout: &parser.FieldLayout{}}}},
+		{Name: "B", Fields: []parser.Field{{Name: "A", GoType: "A", Layout: &parser.FieldLayout{}}}},
+	}
+
+	_, err := SortByDependency(layouts)
+	if err == nil || !strings.Contains(err.Error(), "A -> B -> A") {
+		t.Errorf("Expected cycle error A -> B -> A, got: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateElemAlign checks elemalign= and stride= regions: slices of fixed-size
+// structs whose elements start ElementStride bytes apart. The region start must
+// be aligned too, so every element is. A stride must hold the element and can't
+// be combined with elemalign=. Regions laid out from elsewhere (region= chains,
+// metadata of indirect slices) keep packed elements.
+func validateElemAlign(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	for _, region := range a.Regions {
+		field := region.Field
+		align, stride := field.Layout.ElemAlign, field.Layout.Stride
+		if region.Kind != DynamicRegion || align == 0 && stride == 0 {
+			continue
+		}
+
+		param, value := "elemalign=", align
+		if stride > 0 {
+			param, value = "stride=", stride
+		}
+		if _, err := SizeOf(registry.ResolveType(region.ElementType)); err == nil || region.Framed {
+			return fmt.Errorf("field '%s': %s%d requires a slice of fixed-size structs, got %s",
+				field.Name, param, value, field.GoType)
+		}
+		if align > 0 && stride > 0 {
+			return fmt.Errorf("field '%s': stride= cannot be combined with elemalign=", field.Name)
+		}
+		if field.Layout.Group != "" {
+			return fmt.Errorf("field '%s': %s cannot be combined with region=", field.Name, param)
+		}
+		for _, f := range layout.Fields {
+			if f.Layout.From == field.Name || f.Layout.Extents == field.Name {
+				return fmt.Errorf("field '%s': indirect slice '%s' cannot use %s elements as metadata",
+					field.Name, f.Name, param)
+			}
+		}
+		if stride > 0 && stride < region.ElementSize {
+			return fmt.Errorf("field '%s': stride=%d is smaller than the %d-byte %s",
+				field.Name, stride, region.ElementSize, region.ElementType)
+		}
+		if align > 0 && region.Start%align != 0 {
+			return fmt.Errorf("field '%s': starts at %d, not a multiple of elemalign=%d; place it with @N",
+				field.Name, region.Start, align)
+		}
+	}
+
+	return nil
+}
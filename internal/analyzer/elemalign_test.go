@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_ElemAlign(t *testing.T) {
+	// @layout size=64 mode=copy
+	// type Table struct {
+	//     N       uint8   `layout:"@0"`
+	//     Entries []Entry `layout:"@8,start-end,count=N,elemalign=8"` // Entry is 6 bytes
+	// }
+	table := func(goType string, start int, field func(*parser.FieldLayout)) *parser.TypeLayout {
+		entries := &parser.FieldLayout{Offset: -1, StartAt: start, Direction: parser.StartEnd, CountField: "N", ElemAlign: 8}
+		if field != nil {
+			field(entries)
+		}
+		return &parser.TypeLayout{
+			Name: "Table",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: "copy"},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Entries", GoType: goType, Layout: entries},
+			},
+		}
+	}
+	registry := func() *TypeRegistry {
+		reg := NewTypeRegistry()
+		reg.Register("Entry", 6)
+		return reg
+	}
+
+	analyzed, err := Analyze(table("[]Entry", 8, nil), registry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	entries := analyzed.Regions[1]
+	if entries.ElementSize != 6 || entries.ElementStride != 8 {
+		t.Errorf("Entries element size %d stride %d, want 6 and 8", entries.ElementSize, entries.ElementStride)
+	}
+	if got := analyzed.Report().Regions[1].ElementStride; got != 8 {
+		t.Errorf("Report() elementStride = %d, want 8", got)
+	}
+
+	// stride= spaces elements without aligning them, the start included
+	stride := func(n int) func(*parser.FieldLayout) {
+		return func(l *parser.FieldLayout) { l.ElemAlign, l.Stride = 0, n }
+	}
+	analyzed, err = Analyze(table("[]Entry", -1, stride(10)), registry())
+	if err != nil {
+		t.Fatalf("Analyze() stride=10 error: %v, errors: %v", err, analyzed.Errors)
+	}
+	if entries := analyzed.Regions[1]; entries.Start != 1 || entries.ElementStride != 10 {
+		t.Errorf("stride=10 Entries start %d stride %d, want 1 and 10", entries.Start, entries.ElementStride)
+	}
+	if got := analyzed.Report().Regions[1].ElementStride; got != 10 {
+		t.Errorf("Report() stride=10 elementStride = %d, want 10", got)
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"bytes", table("[]byte", 8, nil), "field 'Entries': elemalign=8 requires a slice of fixed-size structs, got []byte"},
+		{"unaligned start", table("[]Entry", -1, nil), "field 'Entries': starts at 1, not a multiple of elemalign=8"},
+		{"region=", table("[]Entry", -1, func(l *parser.FieldLayout) { l.Group = "body" }), "field 'Entries': elemalign= cannot be combined with region="},
+		{"stride and elemalign", table("[]Entry", 8, func(l *parser.FieldLayout) { l.Stride = 8 }), "field 'Entries': stride= cannot be combined with elemalign="},
+		{"stride bytes", table("[]byte", 8, stride(8)), "field 'Entries': stride=8 requires a slice of fixed-size structs, got []byte"},
+		{"stride too small", table("[]Entry", 8, stride(4)), "field 'Entries': stride=4 is smaller than the 6-byte Entry"},
+		{"stride region=", table("[]Entry", -1, func(l *parser.FieldLayout) { l.ElemAlign, l.Stride, l.Group = 0, 8, "body" }), "field 'Entries': stride= cannot be combined with region="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, registry())
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateExtents checks the extents= fields, [][]byte slices whose bytes live
+// outside the buffer: element i is the SizeField bytes at OffsetField of page
+// PageField, all read from element i of the source slice. They occupy no region,
+// so the source has to be a struct slice decoded from the buffer.
+func validateExtents(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+	for _, field := range layout.Fields {
+		if field.Layout.Extents == "" {
+			continue
+		}
+
+		if field.GoType != "[][]byte" {
+			return fmt.Errorf("field '%s': extents require type [][]byte, got: %s", field.Name, field.GoType)
+		}
+
+		var source *Region
+		for i := range a.Regions {
+			if a.Regions[i].Field.Name == field.Layout.Extents {
+				source = &a.Regions[i]
+				break
+			}
+		}
+		if source == nil {
+			return fmt.Errorf("field '%s': source field '%s' not found", field.Name, field.Layout.Extents)
+		}
+		if source.Kind != DynamicRegion || source.ElementType == "byte" {
+			return fmt.Errorf("field '%s': source field '%s' must be a struct slice, got: %s",
+				field.Name, field.Layout.Extents, source.Field.GoType)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// extentLayout builds:
+//
+//	// @layout size=64
+//	type Leaf struct {
+//	    N        uint16   `layout:"@0"`
+//	    Elems    []Elem   `layout:"@2,start-end,count=N"`
+//	    Overflow [][]byte `layout:"extents=Elems,page=OvfPage,offset=OvfOff,size=OvfLen"`
+//	    Data     []byte   `layout:"end-start"`
+//	}
+func extentLayout() *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, StartAt: -1}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2, CountField: "N",
+			}},
+			{Name: "Overflow", GoType: "[][]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Extents: "Elems", PageField: "OvfPage", OffsetField: "OvfOff", SizeField: "OvfLen",
+			}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+		},
+	}
+}
+
+func TestAnalyze_Extents(t *testing.T) {
+	reg := NewTypeRegistry()
+	reg.Register("Elem", 8)
+
+	analyzed, err := Analyze(extentLayout(), reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	for _, region := range analyzed.Regions {
+		if region.Field.Name == "Overflow" {
+			t.Errorf("Overflow occupies [%d, %d), want no region", region.Start, region.Boundary)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(layout *parser.TypeLayout)
+		wantErr string
+	}{
+		{"not byte slices", func(l *parser.TypeLayout) { l.Fields[2].GoType = "[]byte" }, "extents require type [][]byte"},
+		{"missing source", func(l *parser.TypeLayout) { l.Fields[2].Layout.Extents = "Slots" }, "source field 'Slots' not found"},
+		{"byte source", func(l *parser.TypeLayout) { l.Fields[2].Layout.Extents = "Data" }, "must be a struct slice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := extentLayout()
+			tt.mutate(layout)
+
+			analyzed, _ := Analyze(layout, reg)
+			if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, errs)
+			}
+		})
+	}
+}
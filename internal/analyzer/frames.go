@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// RegisterFrame records a mode=stream type, whose values vary in length, with the
+// smallest frame it encodes to. Slices of it hold length-prefixed elements.
+func (r *TypeRegistry) RegisterFrame(name string, minSize int) {
+	r.frames[name] = minSize
+}
+
+// Frame returns the smallest frame of a registered mode=stream type
+func (r *TypeRegistry) Frame(name string) (int, bool) {
+	minSize, ok := r.frames[r.ResolveType(name)]
+	return minSize, ok
+}
+
+// MinFrameSize returns the smallest frame of a mode=stream layout: its header and
+// trailer around an empty payload
+func (a *AnalyzedLayout) MinFrameSize() int {
+	size := 0
+	for _, r := range a.Regions {
+		if r.Kind == DynamicRegion {
+			size = r.Start
+		}
+	}
+	for _, r := range a.Trailer {
+		size += r.Boundary - r.Start
+	}
+	return size
+}
+
+// validateFrames checks the slices of mode=stream types. Each element is as long
+// as its own length field says, so elements are found by walking the region from
+// its start, count= elements: nothing may locate elements by index (zerocopy
+// accessors, end-start packing, indirect slices) or size the region up front
+// (region= chains, free space shared with an end-start region).
+func validateFrames(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+	for _, region := range a.Regions {
+		if !region.Framed {
+			continue
+		}
+		field := region.Field
+
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': length-prefixed %s elements require mode=copy, got mode=%s",
+				field.Name, region.ElementType, layout.Anno.Mode)
+		}
+		if region.Direction != parser.StartEnd {
+			return fmt.Errorf("field '%s': length-prefixed %s elements require start-end", field.Name, region.ElementType)
+		}
+		if field.Layout.Group != "" {
+			return fmt.Errorf("field '%s': length-prefixed elements cannot be combined with region=", field.Name)
+		}
+		for _, f := range layout.Fields {
+			if f.Layout.From == field.Name {
+				return fmt.Errorf("field '%s': indirect slice '%s' cannot use length-prefixed elements as metadata",
+					field.Name, f.Name)
+			}
+		}
+		for _, gap := range a.Gaps {
+			if gap.Forward.Field.Name == field.Name {
+				return fmt.Errorf("field '%s': length-prefixed elements cannot share free space with '%s'",
+					field.Name, gap.Backward.Field.Name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// recordLayout builds:
+//
+//	// @layout size=64 mode=stream length=Len
+//	type Record struct {
+//	    Type uint8  `layout:"@0"`
+//	    Len  uint16 `layout:"@1"`
+//	    Data []byte `layout:"start-end"`
+//	    CRC  uint32 `layout:"trailer"`
+//	}
+func recordLayout() *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Record",
+		Anno: &parser.TypeAnnotation{Size: 64, Mode: "stream", Length: "Len"},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+			{Name: "CRC", GoType: "uint32", Layout: &parser.FieldLayout{Offset: -1, Trailer: true}},
+		},
+	}
+}
+
+// logLayout builds:
+//
+//	// @layout size=256
+//	type Log struct {
+//	    N       uint8    `layout:"@0"`
+//	    Records []Record `layout:"start-end,count=N"`
+//	}
+func logLayout() *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Log",
+		Anno: &parser.TypeAnnotation{Size: 256},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Records", GoType: "[]Record", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N",
+			}},
+		},
+	}
+}
+
+func TestAnalyze_Frames(t *testing.T) {
+	reg := NewTypeRegistry()
+	reg.Register("Record", 64)
+
+	record, err := Analyze(recordLayout(), reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, record.Errors)
+	}
+	if got := record.MinFrameSize(); got != 7 {
+		t.Errorf("MinFrameSize() = %d, want 7 (3 header and 4 trailer bytes)", got)
+	}
+	reg.RegisterFrame("Record", record.MinFrameSize())
+
+	analyzed, err := Analyze(logLayout(), reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	records := analyzed.Regions[1]
+	if !records.Framed || records.ElementSize != 7 {
+		t.Errorf("Records: Framed = %v, ElementSize = %d, want length-prefixed elements of at least 7 bytes",
+			records.Framed, records.ElementSize)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(layout *parser.TypeLayout)
+		wantErr string
+	}{
+		{"zerocopy", func(l *parser.TypeLayout) { l.Anno.Mode = "zerocopy" }, "require mode=copy, got mode=zerocopy"},
+		{"end-start", func(l *parser.TypeLayout) { l.Fields[1].Layout.Direction = parser.EndStart }, "require start-end"},
+		{"without count", func(l *parser.TypeLayout) { l.Fields[1].Layout.CountField = "" }, "requires count="},
+		{"shared gap", func(l *parser.TypeLayout) {
+			l.Fields = append(l.Fields, parser.Field{Name: "Heap", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Direction: parser.EndStart,
+			}})
+		}, "cannot share free space with 'Heap'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := logLayout()
+			tt.mutate(layout)
+
+			analyzed, _ := Analyze(layout, reg)
+			if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import "github.com/alexhholmes/layout/internal/parser"
+
+// SharedGap is free space claimed by both a start-end region growing up and an
+// end-start region growing down. Each is bounded only by the other at runtime.
+type SharedGap struct {
+	Forward  Region
+	Backward Region
+}
+
+// findSharedGaps pairs the copy-mode start-end and end-start regions whose ranges
+// overlap. Regions holding indirect slice data and region= chains are sized
+// differently and are left out.
+func findSharedGaps(a *AnalyzedLayout, layout *parser.TypeLayout) {
+	if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+		return
+	}
+
+	indirect := make(map[string]bool)
+	for _, field := range layout.Fields {
+		if field.Layout.From != "" {
+			indirect[field.Layout.Region] = true
+		}
+	}
+	eligible := func(r Region, dir parser.PackDirection) bool {
+		return r.Kind == DynamicRegion && r.Direction == dir &&
+			r.Field.Layout.Group == "" && !indirect[r.Field.Name]
+	}
+
+	for _, fwd := range a.Regions {
+		if !eligible(fwd, parser.StartEnd) {
+			continue
+		}
+		for _, bwd := range a.Regions {
+			if !eligible(bwd, parser.EndStart) {
+				continue
+			}
+			// Forward covers [Start, Boundary), backward covers [Boundary, Start)
+			if max(fwd.Start, bwd.Boundary) < min(fwd.Boundary, bwd.Start) {
+				a.Gaps = append(a.Gaps, SharedGap{Forward: fwd, Backward: bwd})
+			}
+		}
+	}
+}
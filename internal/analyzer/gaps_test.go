@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_SharedGaps(t *testing.T) {
+	// @layout size=64
+	// type Slot struct {
+	//     N    uint16 `layout:"@0"`
+	//     Keys []byte `layout:"start-end,count=N"`
+	//     Data []byte `layout:"@60,end-start"`
+	//     Tail uint32 `layout:"@60"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Slot",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Keys", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: 60}},
+			{Name: "Tail", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 60, Direction: parser.Fixed}},
+		},
+	}
+
+	analyzed, err := Analyze(layout, NewTypeRegistry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	if len(analyzed.Gaps) != 1 {
+		t.Fatalf("Expected 1 shared gap, got %d", len(analyzed.Gaps))
+	}
+	if gap := analyzed.Gaps[0]; gap.Forward.Field.Name != "Keys" || gap.Backward.Field.Name != "Data" {
+		t.Errorf("Gap: got %s/%s, want Keys/Data", gap.Forward.Field.Name, gap.Backward.Field.Name)
+	}
+
+	// Zerocopy layouts manage shared space themselves (slotted pages)
+	layout.Anno.Mode = "zerocopy"
+	analyzed, err = Analyze(layout, NewTypeRegistry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	if len(analyzed.Gaps) != 0 {
+		t.Errorf("Expected no shared gaps in zerocopy mode, got %d", len(analyzed.Gaps))
+	}
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+	"github.com/alexhholmes/layout/kind"
+)
+
+func init() {
+	kind.Register(kind.Kind{
+		Name: "analyzertestuuid",
+		Size: func(f kind.Field) (int, error) {
+			if f.GoType != "uuid.UUID" {
+				return 0, fmt.Errorf("requires uuid.UUID, got %s", f.GoType)
+			}
+			return 16, nil
+		},
+		Marshal:   func(f kind.Field, dst, value string) string { return "" },
+		Unmarshal: func(f kind.Field, src, value string) string { return "" },
+	})
+}
+
+func TestAnalyze_Kind(t *testing.T) {
+	// @layout size=32
+	// type Record struct {
+	//     Flags uint32    `layout:"@0"`
+	//     ID    uuid.UUID `layout:"@4,analyzertestuuid"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Record",
+		Anno: &parser.TypeAnnotation{Size: 32},
+		Fields: []parser.Field{
+			{Name: "Flags", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "ID", GoType: "uuid.UUID", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Kind: "analyzertestuuid"}},
+		},
+	}
+
+	reg := NewTypeRegistry()
+	analyzed, err := Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	id := analyzed.Regions[1]
+	if id.Start != 4 || id.Boundary != 20 {
+		t.Errorf("ID at [%d, %d), want [4, 20)", id.Start, id.Boundary)
+	}
+
+	// The kind's own validation is reported against the field
+	layout.Fields[1].GoType = "[16]byte"
+	analyzed, _ = Analyze(layout, reg)
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "ID: cannot determine size: analyzertestuuid: requires uuid.UUID") {
+		t.Errorf("Expected kind error, got: %s", errs)
+	}
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// bufferFields are the struct fields zerocopy types hold their buffer in, which
+// the generated code reaches as p.buf and p.backing whatever the mode
+var bufferFields = []string{"buf", "backing"}
+
+// generatedMethods returns the methods generated on the layout's type, each with
+// the annotation that asks for it, or the mode it comes with
+func generatedMethods(layout *parser.TypeLayout) map[string]string {
+	anno := layout.Anno
+	methods := make(map[string]string)
+	add := func(reason string, names ...string) {
+		for _, name := range names {
+			methods[name] = reason
+		}
+	}
+
+	mode := anno.Mode
+	if mode == "" {
+		mode = "copy"
+	}
+	add("every layout", "MarshalLayout", "UnmarshalLayout")
+	switch mode {
+	case "stream":
+		add("mode=stream", "ReadFrame", "WriteFrame")
+	case "zerocopy":
+		add("mode=zerocopy", "ReadFrom", "WriteTo", "LoadFrom", "LoadFromAt", "SaveTo", "Clone", "MarshalJSON", "UnmarshalJSON")
+		if anno.Align > 0 || anno.Allocator != "" {
+			add("align= and allocator=", "SetBuffer")
+		}
+		if anno.Align > 0 {
+			add("align=", "DirectBuffer")
+		}
+		fallthrough
+	default:
+		add("every layout", "ReadAt", "WriteAt", "ConvertEndian", "EqualBuffers")
+		if anno.Errors != "minimal" {
+			add("every layout", "Dump")
+		}
+	}
+
+	if anno.Into {
+		add("into=true", "MarshalLayoutInto")
+	}
+	if anno.Batch {
+		add("batch=true", "MarshalLayoutInto")
+	}
+	if anno.Next != "" {
+		add("next=", "ChainLen", "MarshalChain", "UnmarshalChain")
+	}
+	if anno.BinaryRead {
+		add("binaryread=true", "ReadBinary", "WriteBinary")
+	}
+	if anno.Reuse {
+		add("reuse=true", "Reset")
+	}
+	if anno.Zeroize {
+		add("zeroize=true", "Zeroize")
+	}
+	if anno.ReadOnly != "" {
+		add("readonly=", "View")
+	}
+
+	// Accessors named after fields, exported even for unexported fields
+	for _, field := range layout.Fields {
+		name := upperFirst(field.Name)
+		if field.Layout.CountField != "" && !strings.Contains(field.Layout.CountField, ".") {
+			add("count="+field.Layout.CountField, "Set"+upperFirst(field.Layout.CountField))
+		}
+		if field.Layout.Cap > 0 {
+			add("cap=", "Append"+strings.TrimSuffix(name, "s"), name+"Slice", "All"+name)
+		}
+		if mode == "zerocopy" && field.Layout.Direction == parser.Fixed {
+			add("mode=zerocopy", "Get"+name, "Set"+name)
+		}
+		if anno.Retain && field.Layout.Direction != parser.Fixed && field.Layout.From == "" {
+			add("retain=true", name+"Bytes")
+		}
+	}
+
+	return methods
+}
+
+// upperFirst returns name with its first letter uppercased, as the generated code
+// names the constants and methods of a field
+func upperFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// validateFieldNames checks that no tagged field takes a name the generated code
+// uses for something else: the buffer fields, or a method of the type, which
+// Go refuses to declare alongside a field of the same name. Fields differing only
+// in the case of their first letter (count and Count) are refused too, as their
+// generated identifiers would be the same.
+func validateFieldNames(layout *parser.TypeLayout) error {
+	methods := generatedMethods(layout)
+	exported := make(map[string]string)
+	for _, field := range layout.Fields {
+		name := upperFirst(field.Name)
+		if other, ok := exported[name]; ok && name != "_" {
+			return fmt.Errorf("fields '%s' and '%s' would share generated names such as %s%sOffset; rename one of them",
+				other, field.Name, layout.Name, name)
+		}
+		exported[name] = field.Name
+		for _, reserved := range bufferFields {
+			if field.Name == reserved {
+				return fmt.Errorf("field '%s': the name is reserved for the buffer of zerocopy types; rename the field", field.Name)
+			}
+		}
+		if reason, ok := methods[field.Name]; ok {
+			return fmt.Errorf("field '%s' collides with the %s method generated for %s; rename the field", field.Name, field.Name, reason)
+		}
+	}
+	return nil
+}
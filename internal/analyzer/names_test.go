@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_FieldNames(t *testing.T) {
+	// @layout size=64 <anno>
+	// type Page struct {
+	//     N      uint8  `layout:"@0"`
+	//     <name> uint32 `layout:"@4"`
+	//     Body   []byte `layout:"@8,start-end,count=N"`
+	// }
+	page := func(anno parser.TypeAnnotation, name string) *parser.TypeLayout {
+		anno.Size = 64
+		return &parser.TypeLayout{
+			Name: "Page",
+			Anno: &anno,
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: name, GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+				{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"plain", page(parser.TypeAnnotation{}, "Flags"), ""},
+		{"unexported", page(parser.TypeAnnotation{Mode: "zerocopy"}, "flags"), ""},
+		{"first letter case", page(parser.TypeAnnotation{}, "n"),
+			"fields 'N' and 'n' would share generated names such as PageNOffset"},
+		{"buf", page(parser.TypeAnnotation{}, "buf"), "field 'buf': the name is reserved for the buffer of zerocopy types"},
+		{"backing", page(parser.TypeAnnotation{}, "backing"), "field 'backing': the name is reserved"},
+		{"marshal", page(parser.TypeAnnotation{}, "MarshalLayout"),
+			"field 'MarshalLayout' collides with the MarshalLayout method generated for every layout"},
+		{"dump", page(parser.TypeAnnotation{}, "Dump"), "field 'Dump' collides with the Dump method"},
+		{"dump minimal", page(parser.TypeAnnotation{Errors: "minimal"}, "Dump"), ""},
+		{"reset", page(parser.TypeAnnotation{}, "Reset"), ""},
+		{"reset reuse", page(parser.TypeAnnotation{Reuse: true}, "Reset"),
+			"field 'Reset' collides with the Reset method generated for reuse=true"},
+		{"copy WriteTo", page(parser.TypeAnnotation{}, "WriteTo"), ""},
+		{"zerocopy WriteTo", page(parser.TypeAnnotation{Mode: "zerocopy"}, "WriteTo"),
+			"field 'WriteTo' collides with the WriteTo method generated for mode=zerocopy"},
+		{"zerocopy accessor", page(parser.TypeAnnotation{Mode: "zerocopy"}, "GetN"), "field 'GetN' collides with the GetN method"},
+		{"readonly view", page(parser.TypeAnnotation{Mode: "zerocopy", ReadOnly: "PageView"}, "View"),
+			"field 'View' collides with the View method generated for readonly="},
+		{"retained bytes", page(parser.TypeAnnotation{Retain: true}, "BodyBytes"),
+			"field 'BodyBytes' collides with the BodyBytes method generated for retain=true"},
+		{"count setter", page(parser.TypeAnnotation{}, "SetN"), "field 'SetN' collides with the SetN method generated for count=N"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+			} else if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
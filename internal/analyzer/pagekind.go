@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateKind checks the kind= a layout is routed by in DecodePage: kindfield=
+// must name a fixed integer field, outside any bit field, wide enough to hold the
+// kind. The layouts of a file must agree on where the field sits, which is up to
+// the generator, seeing them all.
+func validateKind(layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || (layout.Anno.Kind == "" && layout.Anno.KindField == "") {
+		return nil
+	}
+	kind, name := layout.Anno.Kind, layout.Anno.KindField
+	if kind == "" {
+		return fmt.Errorf("kindfield=%s requires kind=, the value routing to %s", name, layout.Name)
+	}
+	if name == "" {
+		return fmt.Errorf("kind=%s requires kindfield=, the field DecodePage reads the kind from", kind)
+	}
+
+	var field *parser.Field
+	for i := range layout.Fields {
+		if layout.Fields[i].Name == name {
+			field = &layout.Fields[i]
+		}
+	}
+	if field == nil || field.Layout == nil || field.Layout.Direction != parser.Fixed || field.Layout.Offset < 0 {
+		return fmt.Errorf("kindfield=%s must name a field at a fixed offset", name)
+	}
+	if field.Layout.Bits > 0 {
+		return fmt.Errorf("kindfield=%s cannot be a bit field", name)
+	}
+
+	resolved := registry.ResolveType(field.GoType)
+	if resolved == "byte" {
+		resolved = "uint8"
+	}
+	if !isCountType(resolved) {
+		return fmt.Errorf("kindfield=%s requires an integer field, got: %s", name, field.GoType)
+	}
+
+	size, _ := SizeOf(resolved)
+	var err error
+	if strings.HasPrefix(resolved, "int") {
+		_, err = strconv.ParseInt(kind, 0, size*8)
+	} else {
+		_, err = strconv.ParseUint(kind, 0, size*8)
+	}
+	if err != nil {
+		return fmt.Errorf("kind=%s does not fit in %d-bit %s", kind, size*8, field.GoType)
+	}
+	return nil
+}
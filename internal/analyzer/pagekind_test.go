@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_PageKind(t *testing.T) {
+	// @layout size=64 kind=3 kindfield=Type
+	// type Leaf struct {
+	//     Type  uint8  `layout:"@0"`
+	//     Flags uint8  `layout:"@1,bits=4"`
+	//     Magic uint16 `layout:"@2"`
+	//     ID    ID     `layout:"@4"` // ID is a uint32 alias
+	//     Name  string `layout:"@8"`
+	//     Body  []byte `layout:"@16,start-end,count=Type"`
+	// }
+	tests := []struct {
+		name      string
+		kind      string
+		kindField string
+		wantErr   string
+	}{
+		{"byte field", "3", "Type", ""},
+		{"hex kind", "0xff", "Type", ""},
+		{"alias field", "70000", "ID", ""},
+		{"no kindfield", "3", "", "kind=3 requires kindfield="},
+		{"no kind", "", "Type", "kindfield=Type requires kind="},
+		{"unknown field", "3", "Kind", "kindfield=Kind must name a field at a fixed offset"},
+		{"dynamic field", "3", "Body", "kindfield=Body must name a field at a fixed offset"},
+		{"bit field", "3", "Flags", "kindfield=Flags cannot be a bit field"},
+		{"string field", "3", "Name", "kindfield=Name requires an integer field, got: string"},
+		{"too wide", "256", "Type", "kind=256 does not fit in 8-bit uint8"},
+		{"negative unsigned", "-1", "Magic", "kind=-1 does not fit in 16-bit uint16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Leaf",
+				Anno: &parser.TypeAnnotation{Size: 64, Kind: tt.kind, KindField: tt.kindField},
+				Fields: []parser.Field{
+					{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed, Bits: 4}},
+					{Name: "Magic", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+					{Name: "ID", GoType: "ID", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+					{Name: "Name", GoType: "string", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+					{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+						Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "Type"}},
+				},
+			}
+			reg := NewTypeRegistry()
+			reg.RegisterAlias("ID", "uint32")
+			err := validateKind(layout, reg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateKind() error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateKind() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validatePrefix checks the indirect slices marked encode=prefix, each entry
+// stored as the length of the prefix it shares with the entry before it and the
+// bytes after. Entries are rebuilt by unmarshal into slices of their own, so
+// only copy-mode layouts are supported.
+func validatePrefix(layout *parser.TypeLayout) error {
+	for _, field := range layout.Fields {
+		if field.Layout.From == "" || field.Layout.Encode != parser.EncodePrefix {
+			continue
+		}
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': encode=prefix requires mode=copy, got mode=%s", field.Name, layout.Anno.Mode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Prefix(t *testing.T) {
+	// @layout size=64
+	// type Leaf struct {
+	//     N     uint8    `layout:"@0"`
+	//     Elems []Elem   `layout:"@2,start-end,count=N"`
+	//     Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data,encode=prefix"`
+	//     Data  []byte   `layout:"end-start"`
+	// }
+	leaf := func(mode string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Leaf",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: mode},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: 2, CountField: "N"}},
+				{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize", Region: "Data",
+					Encode: parser.EncodePrefix}},
+				{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+			},
+		}
+	}
+	reg := NewTypeRegistry()
+	reg.Register("Elem", 4)
+
+	if analyzed, err := Analyze(leaf("copy"), reg); err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	analyzed, err := Analyze(leaf("zerocopy"), reg)
+	want := "field 'Keys': encode=prefix requires mode=copy, got mode=zerocopy"
+	if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), want) {
+		t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, want)
+	}
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// PresentSlots returns the slot count and slot type of a present= field's [N]T type
+func PresentSlots(goType string) (int, string, bool) {
+	matches := arrayRe.FindStringSubmatch(goType)
+	if matches == nil {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, "", false
+	}
+	return n, matches[2], true
+}
+
+// validatePresent checks present= fields: an [N]T array of integer slots in a
+// copy-mode layout, governed by an unsigned bitmap field with a bit for every
+// slot. The bitmap must sit at a lower offset, so unmarshal, which decodes
+// fields in offset order, has it before unpacking the slots.
+func validatePresent(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	for i, r := range a.Regions {
+		present := r.Field.Layout.Present
+		if present == "" {
+			continue
+		}
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': present= requires mode=copy", r.Field.Name)
+		}
+		n, slotType, ok := PresentSlots(r.Field.GoType)
+		resolved := registry.ResolveType(slotType)
+		if !ok || !isCountType(resolved) && resolved != "byte" || r.isAlias() {
+			return fmt.Errorf("field '%s': present= requires an [N]T array of integers, got %s", r.Field.Name, r.Field.GoType)
+		}
+
+		found := false
+		for j, b := range a.Regions {
+			if b.Field.Name != present {
+				continue
+			}
+			found = true
+			bits := 0
+			switch registry.ResolveType(b.Field.GoType) {
+			case "uint8":
+				bits = 8
+			case "uint16":
+				bits = 16
+			case "uint32":
+				bits = 32
+			case "uint64":
+				bits = 64
+			default:
+				return fmt.Errorf("field '%s': present field '%s' must be an unsigned integer, got %s", r.Field.Name, present, b.Field.GoType)
+			}
+			if b.Bits > 0 {
+				bits = b.Bits
+			}
+			if bits < n {
+				return fmt.Errorf("field '%s': present field '%s' has %d bits for %d slots", r.Field.Name, present, bits, n)
+			}
+			if j > i {
+				return fmt.Errorf("field '%s': present field '%s' must be at a lower offset", r.Field.Name, present)
+			}
+		}
+		if !found {
+			return fmt.Errorf("field '%s': present field '%s' not found", r.Field.Name, present)
+		}
+	}
+	return nil
+}
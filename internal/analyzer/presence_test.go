@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Present(t *testing.T) {
+	// @layout size=64
+	// type Sensor struct {
+	//     Mask   uint8     `layout:"@0"`
+	//     Values [8]uint32 `layout:"@4,present=Mask"`
+	// }
+	sensor := func(mode string, mask, values parser.Field) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name:   "Sensor",
+			Anno:   &parser.TypeAnnotation{Size: 64, Mode: mode},
+			Fields: []parser.Field{mask, values},
+		}
+	}
+	mask := func(goType string, offset, bits int) parser.Field {
+		return parser.Field{Name: "Mask", GoType: goType, Layout: &parser.FieldLayout{Offset: offset, Direction: parser.Fixed, Bits: bits}}
+	}
+	values := func(goType, present string) parser.Field {
+		return parser.Field{Name: "Values", GoType: goType, Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Present: present}}
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		wantErr string
+	}{
+		{"valid", sensor("copy", mask("uint8", 0, 0), values("[8]uint32", "Mask")), ""},
+		{"byte slots", sensor("copy", mask("uint8", 0, 0), values("[8]byte", "Mask")), ""},
+		{"zerocopy", sensor("zerocopy", mask("uint8", 0, 0), values("[8]uint32", "Mask")), "field 'Values': present= requires mode=copy"},
+		{"float slots", sensor("copy", mask("uint8", 0, 0), values("[8]float32", "Mask")), "present= requires an [N]T array of integers, got [8]float32"},
+		{"signed bitmap", sensor("copy", mask("int8", 0, 0), values("[8]uint32", "Mask")), "present field 'Mask' must be an unsigned integer, got int8"},
+		{"narrow bitmap", sensor("copy", mask("uint8", 0, 0), values("[9]uint32", "Mask")), "present field 'Mask' has 8 bits for 9 slots"},
+		{"narrow bit field", sensor("copy", mask("uint8", 0, 4), values("[8]uint32", "Mask")), "present field 'Mask' has 4 bits for 8 slots"},
+		{"bitmap after slots", sensor("copy", mask("uint8", 60, 0), values("[8]uint32", "Mask")), "present field 'Mask' must be at a lower offset"},
+		{"bitmap missing", sensor("copy", mask("uint8", 0, 0), values("[8]uint32", "Flags")), "present field 'Flags' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, NewTypeRegistry())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// orderRegions chains the named start-end regions (region=) into one sequence:
+// regions in the order of the regions= annotation, fields of a region in
+// declaration order. Each field starts where the previous one ends at runtime, so
+// every field but the last needs count= to locate the next. Once any field is
+// named, all start-end fields without an explicit start must be, leaving nothing
+// to infer from offsets.
+func orderRegions(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+	var order []string
+	if layout.Anno != nil {
+		order = layout.Anno.Regions
+	}
+
+	// Region names in order of first use
+	var used []string
+	for _, r := range a.Regions {
+		if group := r.Field.Layout.Group; group != "" && !slices.Contains(used, group) {
+			used = append(used, group)
+		}
+	}
+	if len(used) == 0 {
+		if len(order) > 0 {
+			return fmt.Errorf("regions=%s requires fields declaring region=", strings.Join(order, ","))
+		}
+		return nil
+	}
+
+	if layout.Anno != nil && layout.Anno.Mode != "copy" {
+		return fmt.Errorf("region= requires mode=copy, got mode=%s", layout.Anno.Mode)
+	}
+	for _, r := range a.Regions {
+		if r.Kind != DynamicRegion {
+			continue
+		}
+		named := r.Field.Layout.Group != ""
+		switch {
+		case named && r.Direction != parser.StartEnd:
+			return fmt.Errorf("field '%s': region= requires start-end", r.Field.Name)
+		case named && r.Field.Layout.StartAt >= 0:
+			return fmt.Errorf("field '%s': region= fields cannot have an explicit start", r.Field.Name)
+		case !named && r.Direction == parser.StartEnd && r.Field.Layout.StartAt < 0:
+			return fmt.Errorf("field '%s' requires region= (other start-end fields declare regions)", r.Field.Name)
+		}
+	}
+
+	if len(order) == 0 {
+		if len(used) > 1 {
+			return fmt.Errorf("regions %s need an order: add regions=%s to the annotation",
+				strings.Join(used, ", "), strings.Join(used, ","))
+		}
+		order = used
+	}
+	for i, name := range order {
+		if slices.Contains(order[:i], name) {
+			return fmt.Errorf("regions= lists '%s' twice", name)
+		}
+		if !slices.Contains(used, name) {
+			return fmt.Errorf("regions= lists '%s' but no field declares region=%s", name, name)
+		}
+	}
+	for _, name := range used {
+		if !slices.Contains(order, name) {
+			return fmt.Errorf("region '%s' is missing from regions=", name)
+		}
+	}
+
+	// Number the chain; a.Regions is still in declaration order
+	var chain []*Region
+	for _, name := range order {
+		for i := range a.Regions {
+			if a.Regions[i].Field.Layout.Group == name {
+				a.Regions[i].Chain = len(chain)
+				chain = append(chain, &a.Regions[i])
+			}
+		}
+	}
+	for _, r := range chain[:len(chain)-1] {
+		if r.Field.Layout.CountField == "" {
+			return fmt.Errorf("field '%s' in region '%s' requires count= to locate the fields after it",
+				r.Field.Name, r.Field.Layout.Group)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// regionsLayout builds:
+//
+//	// @layout size=128 mode=copy regions=<order>
+//	type Page struct {
+//	    BodyLen uint16 `layout:"@0"`
+//	    NumIdx  uint8  `layout:"@2"`
+//	    Blob    []byte `layout:"start-end,region=blob"`
+//	    Index   []byte `layout:"start-end,count=NumIdx,region=index"`
+//	    Body    []byte `layout:"start-end,count=BodyLen,region=body"`
+//	    Footer  uint32 `layout:"@124"`
+//	}
+func regionsLayout(order ...string) *parser.TypeLayout {
+	dynamic := func(count, group string) *parser.FieldLayout {
+		return &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: count, Group: group}
+	}
+	return &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Mode: "copy", Regions: order},
+		Fields: []parser.Field{
+			{Name: "BodyLen", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "NumIdx", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Blob", GoType: "[]byte", Layout: dynamic("", "blob")},
+			{Name: "Index", GoType: "[]byte", Layout: dynamic("NumIdx", "index")},
+			{Name: "Body", GoType: "[]byte", Layout: dynamic("BodyLen", "body")},
+			{Name: "Footer", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 124, Direction: parser.Fixed}},
+		},
+	}
+}
+
+func TestAnalyze_Regions(t *testing.T) {
+	analyzed, err := Analyze(regionsLayout("body", "index", "blob"), NewTypeRegistry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	// Regions follow the annotation order, not declaration order
+	var names []string
+	for _, r := range analyzed.Regions {
+		if r.Kind != DynamicRegion {
+			continue
+		}
+		names = append(names, r.Field.Name)
+		if r.Start != 3 || r.Boundary != 124 {
+			t.Errorf("%s: got [%d, %d), want the chain extent [3, 124)", r.Field.Name, r.Start, r.Boundary)
+		}
+	}
+	if got := strings.Join(names, ","); got != "Body,Index,Blob" {
+		t.Errorf("Region order: got %s, want Body,Index,Blob", got)
+	}
+}
+
+func TestAnalyze_RegionsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout func() *parser.TypeLayout
+		want   string
+	}{
+		{
+			name:   "order required",
+			layout: func() *parser.TypeLayout { return regionsLayout() },
+			want:   "need an order: add regions=blob,index,body",
+		},
+		{
+			name:   "unlisted region",
+			layout: func() *parser.TypeLayout { return regionsLayout("body", "index") },
+			want:   "region 'blob' is missing from regions=",
+		},
+		{
+			name:   "unknown region",
+			layout: func() *parser.TypeLayout { return regionsLayout("body", "index", "blob", "tail") },
+			want:   "regions= lists 'tail' but no field declares region=tail",
+		},
+		{
+			name:   "count required before the last",
+			layout: func() *parser.TypeLayout { return regionsLayout("blob", "index", "body") },
+			want:   "field 'Blob' in region 'blob' requires count=",
+		},
+		{
+			name: "unnamed start-end field",
+			layout: func() *parser.TypeLayout {
+				l := regionsLayout("body", "index", "blob")
+				l.Fields[2].Layout.Group = ""
+				return l
+			},
+			want: "field 'Blob' requires region=",
+		},
+		{
+			name: "zerocopy",
+			layout: func() *parser.TypeLayout {
+				l := regionsLayout("body", "index", "blob")
+				l.Anno.Mode = "zerocopy"
+				return l
+			},
+			want: "region= requires mode=copy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout(), NewTypeRegistry())
+			if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.want) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.want)
+			}
+		})
+	}
+}
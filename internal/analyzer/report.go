@@ -0,0 +1,109 @@
+package analyzer
+
+// Report is the machine-readable form of an AnalyzedLayout, for linters, doc
+// generators and CI checks that consume layout metadata without parsing Go source.
+// Its JSON field names are stable; Region and parser.Field are not.
+type Report struct {
+	Type     string         `json:"type"`
+	Size     int            `json:"size"`
+	Valid    bool           `json:"valid"`
+	Regions  []RegionReport `json:"regions"`
+	Trailer  []RegionReport `json:"trailer,omitempty"`
+	Gaps     []GapReport    `json:"gaps,omitempty"`
+	Errors   []string       `json:"errors,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// RegionReport describes one region. Start and Boundary are byte offsets; a
+// backward (end-start) region grows down from Start towards Boundary.
+type RegionReport struct {
+	Field         string   `json:"field"`
+	GoType        string   `json:"goType"`
+	Kind          string   `json:"kind"`      // "fixed" or "dynamic"
+	Direction     string   `json:"direction"` // "fixed", "start-end" or "end-start"
+	Start         int      `json:"start"`
+	Boundary      int      `json:"boundary"`
+	ElementSize   int      `json:"elementSize,omitempty"`
+	ElementStride int      `json:"elementStride,omitempty"` // Set when elemalign= or stride= spaces elements wider than elementSize
+	ElementType   string   `json:"elementType,omitempty"`
+	Framed        bool     `json:"framed,omitempty"`   // Elements are length-prefixed; elementSize is the smallest
+	Encode        string   `json:"encode,omitempty"`   // Element encoding (encode=); elementSize is the smallest
+	Arrange       string   `json:"arrange,omitempty"`  // Arrangement of the region's slices; elementSize is a row
+	Parallel      []string `json:"parallel,omitempty"` // Slices sharing the region with this one, in row order
+	CountField    string   `json:"countField,omitempty"`
+	MaxCount      int      `json:"maxCount,omitempty"`
+	Group         string   `json:"region,omitempty"`
+	BitOffset     int      `json:"bitOffset,omitempty"`
+	Bits          int      `json:"bits,omitempty"`
+	Misaligned    bool     `json:"misaligned,omitempty"`
+}
+
+// GapReport names the start-end and end-start regions sharing free space
+type GapReport struct {
+	Forward  string `json:"forward"`
+	Backward string `json:"backward"`
+}
+
+// String returns "fixed" or "dynamic"
+func (k RegionKind) String() string {
+	if k == DynamicRegion {
+		return "dynamic"
+	}
+	return "fixed"
+}
+
+// Report returns the machine-readable form of the layout
+func (a *AnalyzedLayout) Report() Report {
+	report := Report{
+		Type:     a.TypeName,
+		Size:     a.BufferSize,
+		Valid:    a.IsValid(),
+		Regions:  reportRegions(a.Regions),
+		Trailer:  reportRegions(a.Trailer),
+		Errors:   a.Errors,
+		Warnings: a.Warnings,
+	}
+	if report.Regions == nil {
+		report.Regions = []RegionReport{}
+	}
+	for _, gap := range a.Gaps {
+		report.Gaps = append(report.Gaps, GapReport{Forward: gap.Forward.Field.Name, Backward: gap.Backward.Field.Name})
+	}
+	return report
+}
+
+// reportRegions converts regions to their reports
+func reportRegions(regions []Region) []RegionReport {
+	var reports []RegionReport
+	for _, r := range regions {
+		report := RegionReport{
+			Field:       r.Field.Name,
+			GoType:      r.Field.GoType,
+			Kind:        r.Kind.String(),
+			Direction:   r.Direction.String(),
+			Start:       r.Start,
+			Boundary:    r.Boundary,
+			ElementSize: r.ElementSize,
+			ElementType: r.ElementType,
+			Framed:      r.Framed,
+			BitOffset:   r.BitOffset,
+			Bits:        r.Bits,
+			Misaligned:  r.Misaligned,
+		}
+		if l := r.Field.Layout; l != nil {
+			report.CountField = l.CountField
+			report.Encode = l.Encode
+			report.Arrange = l.Arrange
+			if l.ElemAlign > 0 || l.Stride > 0 {
+				report.ElementStride = r.ElementStride
+			}
+			report.MaxCount = l.MaxCount
+			report.Group = l.Group
+		}
+		for _, m := range r.Members[min(1, len(r.Members)):] {
+			report.Parallel = append(report.Parallel, m.Field.Name)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyzedLayout_Report(t *testing.T) {
+	// @layout size=64
+	// type Slot struct {
+	//     N    uint16 `layout:"@0"`
+	//     Keys []byte `layout:"start-end,count=N,max=16"`
+	//     Data []byte `layout:"@60,end-start"`
+	//     Tail uint32 `layout:"@60"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Slot",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Keys", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N", MaxCount: 16}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: 60}},
+			{Name: "Tail", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 60, Direction: parser.Fixed}},
+		},
+	}
+
+	analyzed, err := Analyze(layout, NewTypeRegistry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	report := analyzed.Report()
+
+	if report.Type != "Slot" || report.Size != 64 || !report.Valid {
+		t.Errorf("Report = %s/%d/valid=%v, want Slot/64/valid=true", report.Type, report.Size, report.Valid)
+	}
+	if len(report.Regions) != 4 {
+		t.Fatalf("Expected 4 regions, got %d", len(report.Regions))
+	}
+	keys := report.Regions[1]
+	if keys.Field != "Keys" || keys.Kind != "dynamic" || keys.Direction != "start-end" ||
+		keys.Start != 2 || keys.ElementSize != 1 || keys.CountField != "N" || keys.MaxCount != 16 {
+		t.Errorf("Keys region = %+v", keys)
+	}
+	if len(report.Gaps) != 1 || report.Gaps[0] != (GapReport{Forward: "Keys", Backward: "Data"}) {
+		t.Errorf("Gaps = %+v, want Keys/Data", report.Gaps)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	expectedParts := []string{
+		`"type":"Slot"`,
+		`{"field":"N","goType":"uint16","kind":"fixed","direction":"fixed","start":0,"boundary":2`,
+		`"countField":"N","maxCount":16`,
+		`"gaps":[{"forward":"Keys","backward":"Data"}]`,
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("JSON missing: %s\n\nJSON:\n%s", expected, data)
+		}
+	}
+	if strings.Contains(string(data), `"errors"`) {
+		t.Errorf("JSON of a valid layout should omit errors:\n%s", data)
+	}
+
+	// Invalid layouts report their errors
+	layout.Fields[3].Layout.Offset = 62
+	analyzed, _ = Analyze(layout, NewTypeRegistry())
+	report = analyzed.Report()
+	if report.Valid || len(report.Errors) == 0 {
+		t.Errorf("Report of overflowing layout: valid=%v, errors=%v", report.Valid, report.Errors)
+	}
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// RegisterReuse records a layout type annotated reuse=true, whose own analysis
+// checks that unmarshaling into a reused value doesn't allocate. Layouts nesting
+// it may then be reuse=true too.
+func (r *TypeRegistry) RegisterReuse(name string) {
+	r.reuse[name] = true
+}
+
+// NestedLayout returns the layout type a field of goType nests: the type itself,
+// or the elements of an array or slice of it
+func (r *TypeRegistry) NestedLayout(goType string) (string, bool) {
+	elemType := strings.TrimPrefix(goType, "[]")
+	if matches := arrayRe.FindStringSubmatch(elemType); matches != nil {
+		elemType = matches[2]
+	}
+	elemType = r.ResolveType(elemType)
+	if _, ok := r.Lookup(elemType); !ok {
+		return "", false
+	}
+	return elemType, true
+}
+
+// validateReuse checks that a reuse=true layout unmarshals into a value it was
+// unmarshaled into before without allocating: its slices take the counts they
+// find within the capacity left from earlier calls. Re-encoding under
+// canonical=true and rebuilding encode=prefix entries allocate every time, and
+// nested layouts must be reuse=true themselves, which checks them too and gives
+// them the Reset that Reset calls.
+func validateReuse(layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || !layout.Anno.Reuse {
+		return nil
+	}
+	if layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+		return fmt.Errorf("reuse=true requires mode=copy, got mode=%s", layout.Anno.Mode)
+	}
+	if layout.Anno.Canonical {
+		return fmt.Errorf("reuse=true cannot be combined with canonical=true, whose check re-encodes every buffer unmarshaled")
+	}
+
+	for _, field := range layout.Fields {
+		if field.Layout.Encode == parser.EncodePrefix {
+			return fmt.Errorf("field '%s': reuse=true cannot be combined with encode=prefix, which allocates every entry it rebuilds",
+				field.Name)
+		}
+		if nested, ok := registry.NestedLayout(field.GoType); ok && !registry.reuse[nested] {
+			return fmt.Errorf("field '%s': %s must be reuse=true too, for unmarshaling it to be checked and for Reset to reach it",
+				field.Name, nested)
+		}
+	}
+
+	return nil
+}
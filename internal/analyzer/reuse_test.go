@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Reuse(t *testing.T) {
+	// @layout size=64 reuse=true
+	// type Page struct {
+	//     N       uint8    `layout:"@0"`
+	//     Head    Record   `layout:"@8"`
+	//     Records []Record `layout:"@24,start-end,count=N"` // Record is 16 bytes
+	// }
+	page := func(anno parser.TypeAnnotation, records func(*parser.FieldLayout)) *parser.TypeLayout {
+		l := &parser.FieldLayout{Offset: -1, StartAt: 24, Direction: parser.StartEnd, CountField: "N"}
+		if records != nil {
+			records(l)
+		}
+		return &parser.TypeLayout{
+			Name: "Page",
+			Anno: &anno,
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Head", GoType: "Record", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+				{Name: "Records", GoType: "[]Record", Layout: l},
+			},
+		}
+	}
+	registry := func(reuse bool) *TypeRegistry {
+		reg := NewTypeRegistry()
+		reg.Register("Record", 16)
+		if reuse {
+			reg.RegisterReuse("Record")
+		}
+		return reg
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		reuse   bool // Record is reuse=true
+		wantErr string
+	}{
+		{"reused", page(parser.TypeAnnotation{Size: 64, Reuse: true}, nil), true, ""},
+		{"not reused", page(parser.TypeAnnotation{Size: 64}, nil), false, ""},
+		{"nested not reused", page(parser.TypeAnnotation{Size: 64, Reuse: true}, nil), false,
+			"field 'Head': Record must be reuse=true too"},
+		{"zerocopy", page(parser.TypeAnnotation{Size: 64, Mode: "zerocopy", Reuse: true}, nil), true,
+			"reuse=true requires mode=copy, got mode=zerocopy"},
+		{"canonical", page(parser.TypeAnnotation{Size: 64, Canonical: true, Reuse: true}, nil), true,
+			"reuse=true cannot be combined with canonical=true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzed, err := Analyze(tt.layout, registry(tt.reuse))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+			} else if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNestedLayout(t *testing.T) {
+	reg := NewTypeRegistry()
+	reg.Register("Record", 16)
+	reg.RegisterAlias("Entry", "Record")
+
+	for goType, want := range map[string]string{
+		"Record": "Record", "[]Record": "Record", "[4]Record": "Record", "[]Entry": "Record",
+		"uint32": "", "[]byte": "", "[8]byte": "",
+	} {
+		if got, _ := reg.NestedLayout(goType); got != want {
+			t.Errorf("NestedLayout(%q) = %q, want %q", goType, got, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateSentinels checks the metadata slices marked sentinel, which store no
+// element count: they end at the first entry whose indirect offset and size fields
+// are all zero. The offset and size fields come from the from= slices reading the
+// metadata, so at least one must reference it. Reading stops at a terminator found
+// by scanning, so only copy-mode forward regions of their own are supported.
+func validateSentinels(a *AnalyzedLayout, layout *parser.TypeLayout) error {
+	for _, region := range a.Regions {
+		field := region.Field
+		if !field.Layout.Sentinel {
+			continue
+		}
+
+		if layout.Anno != nil && layout.Anno.Mode != "" && layout.Anno.Mode != "copy" {
+			return fmt.Errorf("field '%s': sentinel requires mode=copy, got mode=%s", field.Name, layout.Anno.Mode)
+		}
+		if region.Kind != DynamicRegion || region.ElementType == "byte" {
+			return fmt.Errorf("field '%s': sentinel requires a struct slice, got %s", field.Name, field.GoType)
+		}
+		if region.Direction != parser.StartEnd {
+			return fmt.Errorf("field '%s': sentinel requires start-end", field.Name)
+		}
+		if field.Layout.Group != "" {
+			return fmt.Errorf("field '%s': sentinel cannot be combined with region=", field.Name)
+		}
+
+		referenced := false
+		for _, f := range layout.Fields {
+			referenced = referenced || f.Layout.From == field.Name
+		}
+		if !referenced {
+			return fmt.Errorf("field '%s': sentinel requires an indirect slice with from=%s to define the zero entry",
+				field.Name, field.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// sentinelLayout builds:
+//
+//	// @layout size=64
+//	type Leaf struct {
+//	    Elems []Elem   `layout:"@2,start-end,sentinel"`
+//	    Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data"`
+//	    Data  []byte   `layout:"end-start"`
+//	}
+func sentinelLayout() *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2, Sentinel: true,
+			}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize", Region: "Data",
+			}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+		},
+	}
+}
+
+func TestAnalyze_Sentinel(t *testing.T) {
+	reg := NewTypeRegistry()
+	reg.Register("Elem", 8)
+
+	analyzed, err := Analyze(sentinelLayout(), reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(layout *parser.TypeLayout)
+		wantErr string
+	}{
+		{"zerocopy", func(l *parser.TypeLayout) { l.Anno.Mode = "zerocopy" }, "sentinel requires mode=copy"},
+		{"with count", func(l *parser.TypeLayout) { l.Fields[0].Layout.CountField = "N" }, "cannot be combined with count="},
+		{"unreferenced", func(l *parser.TypeLayout) { l.Fields = append(l.Fields[:1], l.Fields[2]) }, "requires an indirect slice with from=Elems"},
+		{"byte slice", func(l *parser.TypeLayout) { l.Fields[2].Layout.Sentinel = true }, "sentinel requires a struct slice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := sentinelLayout()
+			tt.mutate(layout)
+
+			analyzed, _ := Analyze(layout, reg)
+			if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %s", tt.wantErr, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import "fmt"
+
+// SharedCount returns the dynamic regions sized by countField, in offset order.
+// Several regions may share one count (e.g., Keys, Values and Flags all sized by
+// NumEntries): every one of them holds exactly that many elements.
+func (a *AnalyzedLayout) SharedCount(countField string) []Region {
+	var regions []Region
+	for _, r := range a.Regions {
+		if r.Kind == DynamicRegion && r.Field.Layout.CountField == countField {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// validateSharedCounts checks the regions sharing a count field agree on how
+// many elements it may hold: a max= on one of them bounds them all, so two
+// different max= values would promise the larger one elements it never gets.
+func validateSharedCounts(a *AnalyzedLayout) error {
+	seen := make(map[string]bool)
+	for _, region := range a.Regions {
+		countField := region.Field.Layout.CountField
+		if region.Kind != DynamicRegion || countField == "" || seen[countField] {
+			continue
+		}
+		seen[countField] = true
+
+		var bounded *Region
+		for _, r := range a.SharedCount(countField) {
+			if r.Field.Layout.MaxCount == 0 {
+				continue
+			}
+			if bounded != nil && bounded.Field.Layout.MaxCount != r.Field.Layout.MaxCount {
+				return fmt.Errorf("fields '%s' and '%s' share count=%s but have max=%d and max=%d; give them the same max=",
+					bounded.Field.Name, r.Field.Name, countField, bounded.Field.Layout.MaxCount, r.Field.Layout.MaxCount)
+			}
+			bounded = &r
+		}
+	}
+	return nil
+}
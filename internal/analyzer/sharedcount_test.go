@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_SharedCount(t *testing.T) {
+	// @layout size=256
+	// type Page struct {
+	//     N      uint8  `layout:"@0"`
+	//     Keys   []Elem `layout:"@8,start-end,count=N,max=<keys>"`
+	//     Values []Elem `layout:"@128,start-end,count=N,max=<values>"`
+	//     Flags  []byte `layout:"@256,end-start,count=N"`
+	// }
+	page := func(keysMax, valuesMax int) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Page",
+			Anno: &parser.TypeAnnotation{Size: 256},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Keys", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N", MaxCount: keysMax}},
+				{Name: "Values", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 128, Direction: parser.StartEnd, CountField: "N", MaxCount: valuesMax}},
+				{Name: "Flags", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 256, Direction: parser.EndStart, CountField: "N"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		keysMax   int
+		valuesMax int
+		wantErr   string // Empty for no error
+	}{
+		{"no max", 0, 0, ""},
+		{"one max", 8, 0, ""},
+		{"same max", 8, 8, ""},
+		{"different max", 8, 12, "fields 'Keys' and 'Values' share count=N but have max=8 and max=12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewTypeRegistry()
+			reg.Register("Elem", 8)
+
+			analyzed, err := Analyze(page(tt.keysMax, tt.valuesMax), reg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Analyze() error: %v", err)
+				}
+				if got := analyzed.SharedCount("N"); len(got) != 3 || got[0].Field.Name != "Keys" {
+					t.Errorf("SharedCount(N) = %d regions, want Keys, Values and Flags", len(got))
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Analyze() succeeded, want error")
+			}
+			if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, tt.wantErr) {
+				t.Errorf("Errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
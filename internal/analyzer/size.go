@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
 )
 
 // SizeOf returns the size in bytes of a Go type
@@ -71,14 +73,24 @@ func arraySize(goType string) (int, error) {
 
 // TypeRegistry tracks struct sizes and type aliases for layout analysis
 type TypeRegistry struct {
-	types   map[string]int    // type name → size in bytes
-	aliases map[string]string // alias → underlying type
+	types      map[string]int               // type name → size in bytes
+	aliases    map[string]string            // alias → underlying type
+	fields     map[string]map[string]string // type name → field name → Go type
+	frames     map[string]int               // mode=stream type name → smallest frame
+	binaryRead map[string]string            // binaryread=true type name → its byte order
+	reuse      map[string]bool              // reuse=true type names
+	zeroize    map[string]bool              // zeroize=true type names
 }
 
 func NewTypeRegistry() *TypeRegistry {
 	return &TypeRegistry{
-		types:   make(map[string]int),
-		aliases: make(map[string]string),
+		types:      make(map[string]int),
+		aliases:    make(map[string]string),
+		fields:     make(map[string]map[string]string),
+		frames:     make(map[string]int),
+		binaryRead: make(map[string]string),
+		reuse:      make(map[string]bool),
+		zeroize:    make(map[string]bool),
 	}
 }
 
@@ -92,6 +104,22 @@ func (r *TypeRegistry) RegisterAlias(alias, underlying string) {
 	r.aliases[alias] = underlying
 }
 
+// RegisterFields records the field types of a struct type, for resolving nested
+// field references (e.g., count=Header.Meta.NumKeys)
+func (r *TypeRegistry) RegisterFields(name string, fields []parser.Field) {
+	types := make(map[string]string, len(fields))
+	for _, f := range fields {
+		types[f.Name] = f.GoType
+	}
+	r.fields[name] = types
+}
+
+// Fields returns the field name → Go type map of a registered struct type
+func (r *TypeRegistry) Fields(name string) (map[string]string, bool) {
+	fields, ok := r.fields[r.ResolveType(name)]
+	return fields, ok
+}
+
 // Lookup returns the size of a registered type
 func (r *TypeRegistry) Lookup(name string) (int, bool) {
 	size, ok := r.types[name]
@@ -151,4 +179,4 @@ func (r *TypeRegistry) SizeOf(goType string) (int, error) {
 	}
 
 	return 0, fmt.Errorf("unknown type: %s (not registered)", goType)
-}
\ No newline at end of file
+}
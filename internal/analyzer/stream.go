@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// validateStream checks a mode=stream layout: fixed header fields, one []byte
+// start-end payload and optional trailer fields. The header field named by
+// length= holds the total frame length, so it must fit the maximum frame size.
+// Trailer regions are collected into a.Trailer in declaration order.
+func validateStream(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || layout.Anno.Mode != "stream" {
+		if layout.Anno != nil && layout.Anno.Length != "" {
+			return fmt.Errorf("length=%s requires mode=stream", layout.Anno.Length)
+		}
+		for _, field := range layout.Fields {
+			if field.Layout.Trailer {
+				return fmt.Errorf("%s: trailer fields require mode=stream", field.Name)
+			}
+		}
+		return nil
+	}
+
+	if layout.Anno.Length == "" {
+		return fmt.Errorf("mode=stream requires length=Field naming the frame length header field")
+	}
+	if layout.Anno.Mirror != "" {
+		return fmt.Errorf("mirror=%s requires mode=copy or zerocopy, frames have no fixed layout", layout.Anno.Mirror)
+	}
+	for _, field := range layout.Fields {
+		if field.Layout.From != "" {
+			return fmt.Errorf("%s: mode=stream does not support indirect slices", field.Name)
+		}
+	}
+
+	var payload *Region
+	for i := range a.Regions {
+		r := &a.Regions[i]
+		if r.Kind != DynamicRegion {
+			continue
+		}
+		if payload != nil || r.Direction != parser.StartEnd || r.ElementType != "byte" {
+			return fmt.Errorf("mode=stream requires exactly one []byte start-end payload")
+		}
+		payload = r
+	}
+	if payload == nil {
+		return fmt.Errorf("mode=stream requires exactly one []byte start-end payload")
+	}
+	if payload.Field.Layout.CountField != "" {
+		return fmt.Errorf("%s: stream payload length comes from length=, not count=", payload.Field.Name)
+	}
+
+	var length *Region
+	for i := range a.Regions {
+		r := &a.Regions[i]
+		if r.Kind != FixedRegion {
+			continue
+		}
+		if r.Boundary > payload.Start {
+			return fmt.Errorf("%s: header field [%d, %d) overlaps the payload at %d",
+				r.Field.Name, r.Start, r.Boundary, payload.Start)
+		}
+		if r.Field.Name == layout.Anno.Length {
+			length = r
+		}
+	}
+	if length == nil {
+		return fmt.Errorf("length field '%s' not found in header", layout.Anno.Length)
+	}
+
+	if length.Bits > 0 {
+		return fmt.Errorf("length field '%s' must not be a bit field", length.Field.Name)
+	}
+	if length.Field.Layout.Get != "" {
+		return fmt.Errorf("length field '%s' is set by MarshalLayout and cannot have get=", length.Field.Name)
+	}
+	lengthType := registry.ResolveType(length.Field.GoType)
+	if !isCountType(lengthType) {
+		return fmt.Errorf("length field '%s' must be an integer type, got: %s", length.Field.Name, length.Field.GoType)
+	}
+	if max := MaxCountValue(lengthType); max < a.BufferSize {
+		return fmt.Errorf("length field '%s' (%s) cannot hold the maximum frame size %d",
+			length.Field.Name, length.Field.GoType, a.BufferSize)
+	}
+
+	offset := 0
+	for _, field := range layout.Fields {
+		if !field.Layout.Trailer {
+			continue
+		}
+		size, err := registry.SizeOf(field.GoType)
+		if err != nil {
+			return fmt.Errorf("%s: cannot determine size: %w", field.Name, err)
+		}
+		if size < 0 {
+			return fmt.Errorf("%s: trailer field cannot have dynamic type: %s", field.Name, field.GoType)
+		}
+		a.Trailer = append(a.Trailer, Region{
+			Kind:      FixedRegion,
+			Start:     offset,
+			Boundary:  offset + size,
+			Direction: parser.Fixed,
+			Field:     field,
+		})
+		offset += size
+	}
+
+	if payload.Start+offset > a.BufferSize {
+		return fmt.Errorf("size %d is smaller than the %d header and %d trailer bytes",
+			a.BufferSize, payload.Start, offset)
+	}
+
+	return nil
+}
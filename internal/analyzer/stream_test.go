@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// streamLayout builds:
+//
+//	// @layout size=1500 mode=stream length=Len
+//	type Msg struct {
+//	    Type    uint8   `layout:"@0"`
+//	    Len     <lenType> `layout:"@1"`
+//	    Payload []byte  `layout:"start-end"`
+//	    Tag     [2]byte `layout:"trailer"`
+//	    CRC     uint32  `layout:"trailer"`
+//	}
+func streamLayout(lenType string) *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Msg",
+		Anno: &parser.TypeAnnotation{Size: 1500, Mode: "stream", Length: "Len"},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Len", GoType: lenType, Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Payload", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: -1,
+			}},
+			{Name: "Tag", GoType: "[2]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.Fixed, Trailer: true}},
+			{Name: "CRC", GoType: "uint32", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.Fixed, Trailer: true}},
+		},
+	}
+}
+
+func TestAnalyze_Stream(t *testing.T) {
+	reg := NewTypeRegistry()
+	analyzed, err := Analyze(streamLayout("uint16"), reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	if len(analyzed.Regions) != 3 {
+		t.Fatalf("Expected 3 regions (trailer excluded), got %d", len(analyzed.Regions))
+	}
+	if payload := analyzed.Regions[2]; payload.Start != 3 {
+		t.Errorf("Payload start: got %d, want 3", payload.Start)
+	}
+
+	// Trailer offsets are relative to the trailer, in declaration order
+	if len(analyzed.Trailer) != 2 {
+		t.Fatalf("Expected 2 trailer regions, got %d", len(analyzed.Trailer))
+	}
+	if tag := analyzed.Trailer[0]; tag.Start != 0 || tag.Boundary != 2 {
+		t.Errorf("Tag: got [%d, %d), want [0, 2)", tag.Start, tag.Boundary)
+	}
+	if crc := analyzed.Trailer[1]; crc.Start != 2 || crc.Boundary != 6 {
+		t.Errorf("CRC: got [%d, %d), want [2, 6)", crc.Start, crc.Boundary)
+	}
+}
+
+func TestAnalyze_StreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(layout *parser.TypeLayout)
+		wantErr string
+	}{
+		{"missing length", func(l *parser.TypeLayout) { l.Anno.Length = "" }, "requires length=Field"},
+		{"unknown length field", func(l *parser.TypeLayout) { l.Anno.Length = "Size" }, "length field 'Size' not found"},
+		{"length too narrow", func(l *parser.TypeLayout) { l.Fields[1].GoType = "uint8" }, "cannot hold the maximum frame size 1500"},
+		{"counted payload", func(l *parser.TypeLayout) { l.Fields[2].Layout.CountField = "Len" }, "not count="},
+		{"trailer outside stream", func(l *parser.TypeLayout) { l.Anno.Mode = "copy"; l.Anno.Length = "" }, "trailer fields require mode=stream"},
+		{"length outside stream", func(l *parser.TypeLayout) { l.Anno.Mode = "copy" }, "length=Len requires mode=stream"},
+		{"mirrored frame", func(l *parser.TypeLayout) { l.Anno.Mirror = "RawMsg" }, "mirror=RawMsg requires mode=copy or zerocopy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := streamLayout("uint16")
+			tt.mutate(layout)
+
+			_, err := Analyze(layout, NewTypeRegistry())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// checkUnreachable warns about dynamic regions squeezed between their neighbours
+// into less than one element: their count can only be zero, so nothing written
+// to them is ever encoded. region= chains share their space and are left out.
+func checkUnreachable(a *AnalyzedLayout, layout *parser.TypeLayout) {
+	if layout.Anno != nil && layout.Anno.Mode == "stream" {
+		return // Frames are as long as their payload
+	}
+	for _, r := range a.Regions {
+		if r.Kind != DynamicRegion || r.Field.Layout.Group != "" {
+			continue
+		}
+		low, high := r.Start, r.Boundary
+		if r.Direction == parser.EndStart {
+			low, high = r.Boundary, r.Start
+		}
+		if stride := max(r.ElementStride, 1); high-low < stride {
+			a.Warnings = append(a.Warnings, fmt.Sprintf(
+				"%s has %d bytes at [%d, %d), less than one %d-byte element; it can only be empty",
+				r.Field.Name, max(high-low, 0), low, high, stride))
+		}
+	}
+}
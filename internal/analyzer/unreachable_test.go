@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Unreachable(t *testing.T) {
+	// @layout size=16
+	// type Node struct {
+	//     N     uint8  `layout:"@0"`
+	//     Elems []Elem `layout:"start-end,count=N"` // Elem is 8 bytes
+	//     Tail  uint64 `layout:"@<tail>"`
+	// }
+	node := func(tail int) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Node",
+			Anno: &parser.TypeAnnotation{Size: 16},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N"}},
+				{Name: "Tail", GoType: "uint64", Layout: &parser.FieldLayout{Offset: tail, Direction: parser.Fixed}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tail    int
+		warning string // Empty for no warning
+	}{
+		{"room for one", 8 + 1, ""},
+		{"squeezed", 8, "Elems has 7 bytes at [1, 8), less than one 8-byte element; it can only be empty"},
+		{"no room", 1, "Elems has 0 bytes at [9, 9), less than one 8-byte element"}, // Starts after Tail, at the buffer end,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewTypeRegistry()
+			reg.Register("Elem", 8)
+			layout := node(tt.tail)
+			layout.Anno.Size = tt.tail + 8
+
+			analyzed, err := Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			warnings := strings.Join(analyzed.Warnings, "; ")
+			if tt.warning == "" {
+				if strings.Contains(warnings, "can only be empty") {
+					t.Errorf("Warnings = %v, want Elems reachable", analyzed.Warnings)
+				}
+				return
+			}
+			if !strings.Contains(warnings, tt.warning) {
+				t.Errorf("Warnings = %v, want %q", analyzed.Warnings, tt.warning)
+			}
+		})
+	}
+}
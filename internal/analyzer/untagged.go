@@ -0,0 +1,19 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// checkUntagged warns about the exported fields of the type without a layout tag:
+// MarshalLayout leaves them out, so they come back from UnmarshalLayout as zero
+func checkUntagged(a *AnalyzedLayout, layout *parser.TypeLayout) {
+	if len(layout.Untagged) == 0 {
+		return
+	}
+	a.Warnings = append(a.Warnings, fmt.Sprintf(
+		"exported fields without layout tags don't round-trip: %s; tag them, or mark them layout:%q",
+		strings.Join(layout.Untagged, ", "), parser.IgnoreTag))
+}
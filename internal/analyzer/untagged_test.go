@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Untagged(t *testing.T) {
+	// @layout size=8
+	// type Node struct {
+	//     ID    uint64 `layout:"@0"`
+	//     Cache []byte
+	//     Dirty bool
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Node",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+		Untagged: []string{"Cache", "Dirty"},
+	}
+
+	analyzed, err := Analyze(layout, NewTypeRegistry())
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	want := `exported fields without layout tags don't round-trip: Cache, Dirty; tag them, or mark them layout:"ignore"`
+	if len(analyzed.Warnings) != 1 || analyzed.Warnings[0] != want {
+		t.Errorf("Warnings = %v, want [%q]", analyzed.Warnings, want)
+	}
+	if !analyzed.IsValid() {
+		t.Error("Untagged fields are a warning, not an error")
+	}
+
+	layout.Untagged = nil
+	if analyzed, _ := Analyze(layout, NewTypeRegistry()); len(analyzed.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", analyzed.Warnings)
+	}
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// dynamicUsage is the most space a counted dynamic region can take: its count
+// field at the largest value the field's type, bit width, max= and the region's
+// capacity allow
+type dynamicUsage struct {
+	Field       string
+	MaxCount    int
+	ElementSize int // Element stride; the smallest element for framed and encode=delta regions, making Bytes a lower bound
+	Bytes       int
+}
+
+// worstCase returns the worst-case usage of each dynamic region with a count
+// field, and the bytes the fixed fields leave to the dynamic regions. Regions
+// whose count field type can't be determined are left out.
+func worstCase(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) ([]dynamicUsage, int) {
+	covered := make([]bool, a.BufferSize)
+	for _, r := range a.Regions {
+		if r.Kind != FixedRegion {
+			continue
+		}
+		for i := max(r.Start, 0); i < min(r.Boundary, a.BufferSize); i++ {
+			covered[i] = true
+		}
+	}
+	space := 0
+	for _, c := range covered {
+		if !c {
+			space++
+		}
+	}
+
+	var usage []dynamicUsage
+	for _, r := range a.Regions {
+		if r.Kind != DynamicRegion || r.Field.Layout.CountField == "" || r.ElementSize == 0 {
+			continue
+		}
+		// next= splits the field across pages, each filled to capacity at most
+		if chained(r, layout) {
+			continue
+		}
+		countType, err := getCountFieldType(r.Field.Layout.CountField, layout, registry)
+		if err != nil {
+			continue
+		}
+		maxCount := MaxCountValue(countType)
+		if maxCount < 0 {
+			continue
+		}
+		// A bit-field count holds no more than its width allows
+		for _, c := range a.Regions {
+			if c.Field.Name == r.Field.Layout.CountField && c.Bits > 0 {
+				maxCount = min(maxCount, 1<<c.Bits-1)
+			}
+		}
+		if max := r.Field.Layout.MaxCount; max > 0 {
+			maxCount = min(maxCount, max)
+		}
+		// Generated code refuses counts past the region's capacity
+		maxCount = min(maxCount, regionCapacity(r, a.BufferSize))
+		usage = append(usage, dynamicUsage{
+			Field:       r.Field.Name,
+			MaxCount:    maxCount,
+			ElementSize: r.ElementStride,
+			Bytes:       maxCount * r.ElementStride,
+		})
+	}
+	return usage, space
+}
+
+// regionCapacity returns the elements of its stride the span of a dynamic region
+// holds, the bound generated code checks its count against
+func regionCapacity(r Region, bufferSize int) int {
+	boundary := r.Boundary
+	if boundary < 0 {
+		boundary = bufferSize
+	}
+	span := boundary - r.Start
+	if span < 0 {
+		span = -span // Backward regions grow from Start down to Boundary
+	}
+	return span / max(r.ElementStride, 1)
+}
+
+// checkWorstCase warns when the counted dynamic regions, each filled to the
+// largest count its count field can hold and its capacity allows, need more bytes
+// than the fixed fields leave free. A region alone is held to its capacity by the
+// generated code, so this takes regions sharing space, which each fit but not
+// together. Marshal fails on such input at runtime; the warning points at the
+// format instead, where a narrower count type or max= fixes it.
+func checkWorstCase(a *AnalyzedLayout, layout *parser.TypeLayout, registry *TypeRegistry) {
+	if layout.Anno != nil && layout.Anno.Mode == "stream" {
+		return // Frames are as long as their payload
+	}
+
+	usage, space := worstCase(a, layout, registry)
+	total := 0
+	var parts []string
+	for _, u := range usage {
+		total += u.Bytes
+		parts = append(parts, fmt.Sprintf("%s: %d × %d = %d",
+			u.Field, u.MaxCount, u.ElementSize, u.Bytes))
+	}
+	if total <= space {
+		return
+	}
+	a.Warnings = append(a.Warnings, fmt.Sprintf(
+		"dynamic regions can need %d bytes at their largest counts (%s), more than the %d bytes left by fixed fields; narrow the count type or set max=",
+		total, strings.Join(parts, ", "), space))
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_WorstCase(t *testing.T) {
+	// @layout size=64
+	// type Node struct {
+	//     N    uint8  `layout:"@0"`            // [bits=B]
+	//     M    uint8  `layout:"@1"`
+	//     Keys []byte `layout:"start-end,count=N"` // [max=K]
+	//     Vals []Elem `layout:"@64,end-start,count=M"` // [max=V], Elem is 8 bytes
+	// }
+	node := func(bits, maxKeys, maxVals int) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Node",
+			Anno: &parser.TypeAnnotation{Size: 64},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Bits: bits}},
+				{Name: "M", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+				{Name: "Keys", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N", MaxCount: maxKeys}},
+				{Name: "Vals", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: 64, CountField: "M", MaxCount: maxVals}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		layout  *parser.TypeLayout
+		warning string // Empty for no warning
+	}{
+		// Each region is held to the 62 bytes of the gap, not the 255 of its count
+		{"shared space overflows", node(0, 0, 0),
+			"dynamic regions can need 118 bytes at their largest counts (Keys: 62 × 1 = 62, Vals: 7 × 8 = 56), more than the 62 bytes left by fixed fields"},
+		{"max= bounds fit", node(0, 30, 4), ""},
+		{"max= bounds overflow together", node(0, 32, 4),
+			"dynamic regions can need 64 bytes at their largest counts (Keys: 32 × 1 = 32, Vals: 4 × 8 = 32), more than the 62 bytes left by fixed fields"},
+		{"bit-field count", node(5, 0, 3), ""},
+		// @layout size=4096
+		// type Leaf struct {
+		//     N      uint16 `layout:"@0"`
+		//     Elems  []Elem `layout:"start-end,count=N"`
+		//     Footer uint64 `layout:"@4088"`
+		// }
+		// A uint16 count of 8-byte elements is held to the region by generated code
+		{"region capacity", &parser.TypeLayout{
+			Name: "Leaf",
+			Anno: &parser.TypeAnnotation{Size: 4096},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N"}},
+				{Name: "Footer", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 4088, Direction: parser.Fixed}},
+			},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewTypeRegistry()
+			reg.Register("Elem", 8)
+			analyzed, err := Analyze(tt.layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			if tt.warning == "" {
+				if len(analyzed.Warnings) != 0 {
+					t.Errorf("Expected no warnings, got %v", analyzed.Warnings)
+				}
+				return
+			}
+			if len(analyzed.Warnings) != 1 || !strings.Contains(analyzed.Warnings[0], tt.warning) {
+				t.Errorf("Warnings = %v, want %q", analyzed.Warnings, tt.warning)
+			}
+			if !analyzed.IsValid() {
+				t.Errorf("Warnings made the layout invalid: %v", analyzed.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// RegisterZeroize records a layout type annotated zeroize=true, which gets a
+// Zeroize method. Layouts nesting it may then be zeroize=true too.
+func (r *TypeRegistry) RegisterZeroize(name string) {
+	r.zeroize[name] = true
+}
+
+// validateZeroize checks that the Zeroize of a zeroize=true layout can reach
+// every byte the value holds: the slices of the layouts nested in it are their
+// own, wiped only by their own Zeroize, so those layouts must be zeroize=true too.
+func validateZeroize(layout *parser.TypeLayout, registry *TypeRegistry) error {
+	if layout.Anno == nil || !layout.Anno.Zeroize {
+		return nil
+	}
+
+	for _, field := range layout.Fields {
+		if nested, ok := registry.NestedLayout(field.GoType); ok && !registry.zeroize[nested] {
+			return fmt.Errorf("field '%s': %s must be zeroize=true too, for Zeroize to wipe the slices it holds",
+				field.Name, nested)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestAnalyze_Zeroize(t *testing.T) {
+	// @layout size=64 zeroize=true
+	// type Session struct {
+	//     N       uint8    `layout:"@0"`
+	//     Records []Record `layout:"@8,start-end,count=N"` // Record is 16 bytes
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Session",
+		Anno: &parser.TypeAnnotation{Size: 64, Zeroize: true},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Records", GoType: "[]Record", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		zeroize bool // Record is zeroize=true
+		wantErr string
+	}{
+		{"nested zeroized", true, ""},
+		{"nested not zeroized", false, "field 'Records': Record must be zeroize=true too"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewTypeRegistry()
+			reg.Register("Record", 16)
+			if tt.zeroize {
+				reg.RegisterZeroize("Record")
+			}
+			analyzed, err := Analyze(layout, reg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+				}
+			} else if err == nil || !strings.Contains(strings.Join(analyzed.Errors, "; "), tt.wantErr) {
+				t.Errorf("Analyze() errors = %v, want %q", analyzed.Errors, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// bytesOf returns an expression viewing the size bytes at addr as a []byte, for
+// copying values in and out of p.buf without a typed (alignment-sensitive) access
+func bytesOf(addr string, size int) string {
+	return fmt.Sprintf("(*[%d]byte)(unsafe.Pointer(%s))[:]", size, addr)
+}
+
+// generateUnalignedOp generates zerocopy marshal/unmarshal code for a field the
+// analyzer marked misaligned. The bytes are copied in native order, matching the
+// typed loads used for aligned fields, so both paths agree on the encoding.
+func (g *Generator) generateUnalignedOp(region analyzer.Region, op string) string {
+	field := region.Field
+	start := region.Start
+	end := region.Boundary
+	value := bytesOf("&p."+field.Name, end-start)
+
+	code := fmt.Sprintf("\t// %s: %s at [%d, %d), unaligned\n", field.Name, field.GoType, start, end)
+	if op == "marshal" {
+		code += fmt.Sprintf("\tcopy(p.buf[%d:%d], %s)\n\n", start, end, value)
+	} else {
+		code += fmt.Sprintf("\tcopy(%s, p.buf[%d:%d])\n\n", value, start, end)
+	}
+	return code
+}
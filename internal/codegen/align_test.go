@@ -0,0 +1,57 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateUnalignedAccessors(t *testing.T) {
+	// @layout size=16 mode=zerocopy align=...
+	// type Header struct {
+	//     buf   [16]byte
+	//     Kind  uint16 `layout:"@2"`
+	//     Count uint32 `layout:"@5"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 16, Mode: "zerocopy", Align: 8},
+		Fields: []parser.Field{
+			{Name: "Kind", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Count", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 5, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 8, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Aligned fields keep the typed load
+		"return *(*uint16)(unsafe.Pointer(&p.buf[2]))",
+		// Misaligned fields are copied bytewise
+		"copy((*[4]byte)(unsafe.Pointer(&v))[:], p.buf[5:9])",
+		"copy(p.buf[5:9], (*[4]byte)(unsafe.Pointer(&v))[:])",
+		"copy(p.buf[5:9], (*[4]byte)(unsafe.Pointer(&p.Count))[:])",
+		"copy((*[4]byte)(unsafe.Pointer(&p.Count))[:], p.buf[5:9])",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+
+	if strings.Contains(code, "*(*uint32)(unsafe.Pointer(&p.buf[5]))") {
+		t.Error("Generated typed load for misaligned field")
+	}
+}
@@ -0,0 +1,94 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateAllocsTestHeader generates the package clause and imports of the
+// tests holding layouts to their marshalallocs= and unmarshalallocs= budgets
+func GenerateAllocsTestHeader(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import \"testing\"\n\n")
+
+	return code.String()
+}
+
+// caseValues returns the literal of the functions building the golden cases of
+// the layout, the empty value and each fixtures= function, indented by indent
+func (g *Generator) caseValues(indent string) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("[]func() *%s{\n", typeName))
+	code.WriteString(fmt.Sprintf("%s\tfunc() *%s { return %s },\n", indent, typeName, g.emptyValue()))
+	for _, fixture := range g.layout.Anno.Fixtures {
+		code.WriteString(fmt.Sprintf("%s\t%s,\n", indent, fixture))
+	}
+	code.WriteString(indent + "}")
+	return code.String()
+}
+
+// GenerateAllocsTest generates Test<Type>MarshalAllocs and
+// Test<Type>UnmarshalAllocs for the budgets the layout sets, each measuring the
+// call with testing.AllocsPerRun on every golden case and failing above the
+// budget, so a change to the layout or the generator that allocates more is
+// caught. Unmarshal decodes into one value per case, as callers reusing it do.
+func (g *Generator) GenerateAllocsTest() string {
+	if g.layout.Anno.Raw != "" {
+		return g.rawGenerator().GenerateAllocsTest() // The raw= type has the methods
+	}
+
+	var tests []string
+	typeName := g.analyzed.TypeName
+
+	if budget := g.layout.Anno.MarshalAllocs; budget != nil {
+		var code strings.Builder
+		code.WriteString(fmt.Sprintf("// Test%sMarshalAllocs checks that MarshalLayout allocates at most %d times\n", typeName, *budget))
+		code.WriteString("// per call (marshalallocs=)\n")
+		code.WriteString(fmt.Sprintf("func Test%sMarshalAllocs(t *testing.T) {\n", typeName))
+		code.WriteString(fmt.Sprintf("\tfor i, value := range %s {\n", g.caseValues("\t")))
+		code.WriteString("\t\tp := value()\n")
+		code.WriteString("\t\tallocs := testing.AllocsPerRun(100, func() {\n")
+		code.WriteString("\t\t\tif _, err := p.MarshalLayout(); err != nil {\n")
+		code.WriteString("\t\t\t\tt.Fatalf(\"MarshalLayout(case %d) error: %v\", i, err)\n")
+		code.WriteString("\t\t\t}\n")
+		code.WriteString("\t\t})\n")
+		code.WriteString(fmt.Sprintf("\t\tif allocs > %d {\n", *budget))
+		code.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"MarshalLayout(case %%d) allocates %%v times, budget is %d\", i, allocs)\n", *budget))
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t}\n")
+		code.WriteString("}\n")
+		tests = append(tests, code.String())
+	}
+
+	if budget := g.layout.Anno.UnmarshalAllocs; budget != nil {
+		var code strings.Builder
+		code.WriteString(fmt.Sprintf("// Test%sUnmarshalAllocs checks that UnmarshalLayout allocates at most %d\n", typeName, *budget))
+		code.WriteString("// times per call decoding into a value it decoded into before (unmarshalallocs=)\n")
+		code.WriteString(fmt.Sprintf("func Test%sUnmarshalAllocs(t *testing.T) {\n", typeName))
+		code.WriteString(fmt.Sprintf("\tfor i, value := range %s {\n", g.caseValues("\t")))
+		code.WriteString("\t\tbuf, err := value().MarshalLayout()\n")
+		code.WriteString("\t\tif err != nil {\n")
+		code.WriteString("\t\t\tt.Fatalf(\"MarshalLayout(case %d) error: %v\", i, err)\n")
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t\tbuf = append([]byte(nil), buf...) // Zerocopy values return their own buffer\n")
+		code.WriteString(fmt.Sprintf("\t\tp := %s\n", g.emptyValue()))
+		code.WriteString("\t\tallocs := testing.AllocsPerRun(100, func() {\n")
+		code.WriteString("\t\t\tif err := p.UnmarshalLayout(buf); err != nil {\n")
+		code.WriteString("\t\t\t\tt.Fatalf(\"UnmarshalLayout(case %d) error: %v\", i, err)\n")
+		code.WriteString("\t\t\t}\n")
+		code.WriteString("\t\t})\n")
+		code.WriteString(fmt.Sprintf("\t\tif allocs > %d {\n", *budget))
+		code.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"UnmarshalLayout(case %%d) allocates %%v times, budget is %d\", i, allocs)\n", *budget))
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t}\n")
+		code.WriteString("}\n")
+		tests = append(tests, code.String())
+	}
+
+	return strings.Join(tests, "\n")
+}
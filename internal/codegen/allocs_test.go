@@ -0,0 +1,56 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateAllocsTest(t *testing.T) {
+	// @layout size=64 fixtures=fullPage marshalallocs=1 unmarshalallocs=0
+	// type Page struct {
+	//     N    uint8  `layout:"@0"`
+	//     Body []byte `layout:"@8,start-end,count=N"`
+	// }
+	marshal, unmarshal := 1, 0
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Fixtures: []string{"fullPage"}, MarshalAllocs: &marshal, UnmarshalAllocs: &unmarshal},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code := gen.GenerateAllocsTest()
+
+	expectedParts := []string{
+		"func TestPageMarshalAllocs(t *testing.T) {\n\tfor i, value := range []func() *Page{\n" +
+			"\t\tfunc() *Page { return &Page{} },\n\t\tfullPage,\n\t} {\n",
+		"\t\tif allocs > 1 {\n\t\t\tt.Errorf(\"MarshalLayout(case %d) allocates %v times, budget is 1\", i, allocs)\n",
+		"func TestPageUnmarshalAllocs(t *testing.T) {\n",
+		"\t\tp := &Page{}\n\t\tallocs := testing.AllocsPerRun(100, func() {\n\t\t\tif err := p.UnmarshalLayout(buf); err != nil {\n",
+		"\t\tif allocs > 0 {\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated test missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Only the budgets set are tested
+	layout.Anno.MarshalAllocs = nil
+	code = gen.GenerateAllocsTest()
+	if strings.Contains(code, "TestPageMarshalAllocs") || !strings.Contains(code, "TestPageUnmarshalAllocs") {
+		t.Errorf("GenerateAllocsTest() with only unmarshalallocs= =\n%s", code)
+	}
+}
@@ -0,0 +1,154 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// generateAppend generates an Append helper for a counted forward-growing region in
+// zerocopy mode. The helper bounds-checks against the region boundary and the live
+// bytes of regions growing down toward it, writes in place into p.buf, bumps the
+// count field and reloads the struct field from p.buf. Regions sharing their count
+// get none: appending to one alone would leave the others a count their elements
+// don't match.
+//
+// Body []byte         → AppendBody(b []byte) error
+// Elements []LeafElem → AppendElement(e LeafElem) error
+func (g *Generator) generateAppend(region analyzer.Region) string {
+	field := region.Field
+	countField := field.Layout.CountField
+	if region.Direction != parser.StartEnd || countField == "" {
+		return "" // Length of uncounted or backward regions isn't stored in p.buf
+	}
+	if len(g.analyzed.SharedCount(countField)) > 1 {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	start := region.Start
+	boundary := region.Boundary
+	capacity := boundary - start
+	limit := g.countLimit(countField) // The count may overflow before the region fills
+	if limit >= 0 && region.ElementType == "byte" && limit < capacity {
+		capacity = limit
+	}
+	if max := field.Layout.MaxCount; max > 0 && region.ElementType == "byte" && max < capacity {
+		capacity = max
+	}
+
+	if region.ElementType == "byte" {
+		code.WriteString(fmt.Sprintf("// Append%s appends b to %s in place, bumping %s\n", upperFirst(field.Name), field.Name, countField))
+		code.WriteString(fmt.Sprintf("func (p *%s) Append%s(b []byte) error {\n", typeName, upperFirst(field.Name)))
+		code.WriteString(fmt.Sprintf("\tn := %s\n", g.countFieldGetter(countField)))
+		code.WriteString(fmt.Sprintf("\tif n+len(b) > %d {\n", capacity))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %%d bytes exceeds capacity %d: %%w\", n+len(b), ErrRegionOverflow)\n",
+			upperFirst(field.Name), capacity))
+		code.WriteString("\t}\n")
+		code.WriteString(g.appendBoundChecks(region, fmt.Sprintf("%d+n+len(b)", start), upperFirst(field.Name)))
+		code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d+n:], b)\n", start))
+		code.WriteString(g.countFieldSetter(countField, "n+len(b)", "\t"))
+		code.WriteString(g.countFieldMirror(countField, "n+len(b)", "\t"))
+		code.WriteString(fmt.Sprintf("\tp.%s = p.buf[%d : %d+n+len(b)]\n", field.Name, start, start))
+		code.WriteString("\treturn nil\n")
+		code.WriteString("}\n\n")
+		return code.String()
+	}
+
+	elementType := region.ElementType
+	elementSize := region.ElementSize
+	maxElements := capacity / region.ElementStride
+	if limit >= 0 && limit < maxElements {
+		maxElements = limit
+	}
+	if max := field.Layout.MaxCount; max > 0 && max < maxElements {
+		maxElements = max
+	}
+	singularName := upperFirst(strings.TrimSuffix(field.Name, "s")) // Elements -> Element
+
+	code.WriteString(fmt.Sprintf("// Append%s appends e to %s in place, bumping %s\n", singularName, field.Name, countField))
+	code.WriteString(fmt.Sprintf("func (p *%s) Append%s(e %s) error {\n", typeName, singularName, elementType))
+	code.WriteString(fmt.Sprintf("\tn := p.Get%sCount()\n", upperFirst(field.Name)))
+	code.WriteString(fmt.Sprintf("\tif n+1 > %d {\n", maxElements))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %s full at %%d elements: %%w\", n, ErrRegionOverflow)\n", singularName, field.Name))
+	code.WriteString("\t}\n")
+	code.WriteString(g.appendBoundChecks(region, fmt.Sprintf("%d+n*%d+%d", start, region.ElementStride, elementSize), singularName))
+	if g.marshalsInto(elementType) {
+		code.WriteString(fmt.Sprintf("\toffset := %d + n*%d\n", start, region.ElementStride))
+		code.WriteString(fmt.Sprintf("\tif _, err := e.MarshalLayoutInto(p.buf[offset : offset+%d]); err != nil {\n", elementSize))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %%w\", err)\n", singularName))
+		code.WriteString("\t}\n")
+	} else {
+		code.WriteString("\telemBuf, err := e.MarshalLayout()\n")
+		code.WriteString("\tif err != nil {\n")
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %%w\", err)\n", singularName))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\toffset := %d + n*%d\n", start, region.ElementStride))
+		code.WriteString(fmt.Sprintf("\tcopy(p.buf[offset:offset+%d], elemBuf)\n", elementSize))
+	}
+	code.WriteString(clearSlack(region, "offset", "\t"))
+
+	// The slice may be out of step with the count (e.g., after Set<Count>), so it's
+	// reloaded from p.buf for the new count, as UnmarshalLayout does
+	code.WriteString("\t// Reload from p.buf, which the count may have moved past the slice\n")
+	code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= n+1 {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:n+1]\n", field.Name, field.Name))
+	code.WriteString("\t} else {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = make([]%s, n+1)\n", field.Name, elementType))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\tat := %d + i*%d\n", start, region.ElementStride))
+	code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(p.buf[at:at+%d]); err != nil {\n", field.Name, elementSize))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"Append%s: unmarshal %s[%%d]: %%w\", i, err)\n", singularName, field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString(g.countFieldSetter(countField, "n+1", "\t"))
+	code.WriteString(g.countFieldMirror(countField, "n+1", "\t"))
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
+
+// appendBoundChecks returns the checks refusing an append to region whose new end,
+// an int expression, would reach the live bytes of a region growing down into the
+// same space: the cells of a slotted page, or a counted end-start region sharing
+// the gap. The region's boundary is the end of that space, so the static capacity
+// check alone lets an append overwrite them. Each low end is read from p.buf.
+func (g *Generator) appendBoundChecks(region analyzer.Region, end, name string) string {
+	var code strings.Builder
+	field := region.Field
+
+	if cell, dir, ok := g.slottedParts(); ok && dir.Field.Name == field.Name {
+		code.WriteString(fmt.Sprintf("\t// %s grows down toward the directory\n", cell.Name))
+		code.WriteString(fmt.Sprintf("\tif end, low := %s, p.slottedCellsLow(); end > low {\n", end))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %s would end at %%d, past %s at %%d: %%w\", end, low, ErrRegionOverflow)\n",
+			name, field.Name, cell.Name))
+		code.WriteString("\t}\n")
+	}
+
+	for _, bwd := range g.analyzed.Regions {
+		if bwd.Kind != analyzer.DynamicRegion || bwd.Direction != parser.EndStart ||
+			bwd.Field.Layout.CountField == "" || bwd.Field.Layout.Group != "" {
+			continue
+		}
+		// Forward covers [Start, Boundary), backward covers [Boundary, Start)
+		if max(region.Start, bwd.Boundary) >= min(region.Boundary, bwd.Start) {
+			continue
+		}
+		used := g.countFieldGetter(bwd.Field.Layout.CountField)
+		if bwd.ElementStride > 1 {
+			used = fmt.Sprintf("%s*%d", used, bwd.ElementStride)
+		}
+		code.WriteString(fmt.Sprintf("\t// %s grows down from %d into the same space\n", bwd.Field.Name, bwd.Start))
+		code.WriteString(fmt.Sprintf("\tif end, low := %s, %d-%s; end > low {\n", end, bwd.Start, used))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %s would end at %%d, past %s at %%d: %%w\", end, low, ErrRegionOverflow)\n",
+			name, field.Name, bwd.Field.Name))
+		code.WriteString("\t}\n")
+	}
+
+	return code.String()
+}
@@ -0,0 +1,119 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateAppend(t *testing.T) {
+	// @layout size=4096 mode=zerocopy
+	// type Page struct {
+	//     buf      [4096]byte
+	//     BodyLen  uint16 `layout:"@0"`
+	//     NumElems uint16 `layout:"@2"`
+	//     Body     []byte `layout:"@4,start-end,count=BodyLen"`
+	//     Elements []Elem `layout:"@2048,start-end,count=NumElems"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 4096, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "BodyLen", GoType: "uint16", Layout: &parser.FieldLayout{
+				Offset: 0, Direction: parser.Fixed,
+			}},
+			{Name: "NumElems", GoType: "uint16", Layout: &parser.FieldLayout{
+				Offset: 2, Direction: parser.Fixed,
+			}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 4, CountField: "BodyLen",
+			}},
+			{Name: "Elements", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2048, CountField: "NumElems",
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// []byte region: capacity is [4, 2048)
+		"func (p *Page) AppendBody(b []byte) error {",
+		"n := int(p.GetBodyLen())",
+		"if n+len(b) > 2044 {",
+		"p.SetBodyLen(uint16(n+len(b)))",
+		"p.BodyLen = uint16(n+len(b))",
+		"p.Body = p.buf[4 : 4+n+len(b)]",
+		// Struct slice: (4096 - 2048) / 8 elements
+		"func (p *Page) AppendElement(e Elem) error {",
+		"if n+1 > 256 {",
+		"offset := 2048 + n*8",
+		"p.SetNumElems(uint16(n+1))",
+		// Reloaded from p.buf rather than appended to, in case the count moved
+		"if cap(p.Elements) >= n+1 {\n\t\tp.Elements = p.Elements[:n+1]\n\t} else {\n\t\tp.Elements = make([]Elem, n+1)\n\t}\n",
+		"\t\tat := 2048 + i*8\n\t\tif err := p.Elements[i].UnmarshalLayout(p.buf[at:at+8]); err != nil {\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+}
+
+func TestGenerateAppendSharedGap(t *testing.T) {
+	// @layout size=128 mode=zerocopy
+	// type Page struct {
+	//     buf  [128]byte
+	//     N    uint8  `layout:"@0"`
+	//     M    uint8  `layout:"@1"`
+	//     Fwd  []Elem `layout:"@8,start-end,count=N"`
+	//     Back []byte `layout:"end-start,count=M"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Fwd", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "N",
+			}},
+			{Name: "Back", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.EndStart, StartAt: -1, CountField: "M",
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 4)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// The static capacity runs to the end of the page; Back's count bounds it at runtime
+	expected := "\t// Back grows down from 128 into the same space\n" +
+		"\tif end, low := 8+n*4+4, 128-int(p.GetM()); end > low {\n" +
+		"\t\treturn fmt.Errorf(\"AppendFwd: Fwd would end at %d, past Back at %d: %w\", end, low, ErrRegionOverflow)\n"
+	if !strings.Contains(code, expected) {
+		t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+	}
+}
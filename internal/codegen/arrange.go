@@ -0,0 +1,165 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// usesArrangedIntegers reports whether an arrange= region holds multi-byte
+// integer members, which are encoded with encoding/binary
+func (g *Generator) usesArrangedIntegers() bool {
+	for _, region := range g.analyzed.Regions {
+		for _, m := range region.Members {
+			if m.ElementSize > 1 && g.arrangedInteger(m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// arrangedInteger reports whether a member of an arrange= region holds integers
+// rather than layout types
+func (g *Generator) arrangedInteger(m analyzer.Member) bool {
+	_, ok := g.registry.Lookup(g.registry.ResolveType(m.ElementType))
+	return !ok
+}
+
+// arrangeNote describes how an arrange= region's members share it, for generated comments
+func arrangeNote(region analyzer.Region) string {
+	var names []string
+	for _, m := range region.Members[1:] {
+		names = append(names, m.Field.Name)
+	}
+	how := "interleaved"
+	if region.Field.Layout.Arrange == parser.ArrangeColumnar {
+		how = "in columns"
+	}
+	return fmt.Sprintf("%s with %s (row size: %d)", how, strings.Join(names, ", "), region.ElementSize)
+}
+
+// memberAt returns the expression for where element i of member m starts, given
+// the region start and the element count n: within row i when interleaved, or
+// within m's column, after the columns of the members before it, when columnar
+func memberAt(region analyzer.Region, m analyzer.Member, start, n string) string {
+	if region.Field.Layout.Arrange == parser.ArrangeColumnar {
+		if m.Offset == 0 {
+			return fmt.Sprintf("%s + i*%d", start, m.ElementSize)
+		}
+		return fmt.Sprintf("%s + %s*%d + i*%d", start, n, m.Offset, m.ElementSize)
+	}
+	if m.Offset == 0 {
+		return fmt.Sprintf("%s + i*%d", start, region.ElementSize)
+	}
+	return fmt.Sprintf("%s + i*%d + %d", start, region.ElementSize, m.Offset)
+}
+
+// generateArrangedMarshal generates marshal code for an arrange= region: the
+// region's slice and its parallel= slices, all count= long, written one row of
+// elements at a time, either side by side or each in its own column
+func (g *Generator) generateArrangedMarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s, %s\n",
+		field.Name, field.GoType, region.Start, region.Boundary, countField, arrangeNote(region)))
+	code.WriteString(fmt.Sprintf("\toffset = %d\n", region.Start))
+	for _, m := range region.Members {
+		code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", m.Field.Name, countField))
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
+			m.Field.Name, m.Field.Name, countField))
+		code.WriteString("\t}\n")
+	}
+	code.WriteString(generateMaxCheck(field))
+	code.WriteString(fmt.Sprintf("\tif offset+len(p.%s)*%d > %d {\n", field.Name, region.ElementSize, region.Boundary))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset, ErrRegionOverflow)\n", field.Name))
+	code.WriteString("\t}\n")
+
+	n := fmt.Sprintf("len(p.%s)", field.Name)
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+	for j, m := range region.Members {
+		decl := "="
+		if j == 0 {
+			decl = ":="
+		}
+		code.WriteString(fmt.Sprintf("\t\tat %s %s\n", decl, memberAt(region, m, "offset", n)))
+		elem := fmt.Sprintf("p.%s[i]", m.Field.Name)
+		if !g.arrangedInteger(m) {
+			code.WriteString(g.generateElementMarshal(elem, m.ElementType,
+				fmt.Sprintf("buf[at:at+%d]", m.ElementSize), "\t\t",
+				fmt.Sprintf("return nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)", m.Field.Name)))
+			continue
+		}
+		if m.ElementSize == 1 {
+			if m.ElementType != "byte" && m.ElementType != "uint8" {
+				elem = fmt.Sprintf("byte(%s)", elem)
+			}
+			code.WriteString(fmt.Sprintf("\t\tbuf[at] = %s\n", elem))
+			continue
+		}
+		unsigned := fmt.Sprintf("uint%d", m.ElementSize*8)
+		if m.ElementType != unsigned {
+			elem = fmt.Sprintf("%s(%s)", unsigned, elem)
+		}
+		code.WriteString(fmt.Sprintf("\t\t%s.%s(buf[at:at+%d], %s)\n",
+			g.endianPrefix(), g.binaryPutFunc(m.ElementType), m.ElementSize, elem))
+	}
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\toffset += %s * %d\n\n", n, region.ElementSize))
+
+	return code.String()
+}
+
+// generateArrangedUnmarshal generates unmarshal code for an arrange= region,
+// sizing every member slice to the count field and reading their elements back
+// from the rows or columns marshal wrote
+func (g *Generator) generateArrangedUnmarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s, %s\n",
+		field.Name, field.GoType, region.Start, region.Boundary, countField, arrangeNote(region)))
+	code.WriteString(g.generateCountCheck(region))
+	code.WriteString("\t// Reuse slices if capacity allows\n")
+	for _, m := range region.Members {
+		code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= int(p.%s) {\n", m.Field.Name, countField))
+		code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:p.%s]\n", m.Field.Name, m.Field.Name, countField))
+		code.WriteString("\t} else {\n")
+		code.WriteString(fmt.Sprintf("\t\tp.%s = make([]%s, p.%s)\n", m.Field.Name, m.ElementType, countField))
+		code.WriteString("\t}\n")
+	}
+
+	n := fmt.Sprintf("len(p.%s)", field.Name)
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+	for j, m := range region.Members {
+		decl := "="
+		if j == 0 {
+			decl = ":="
+		}
+		code.WriteString(fmt.Sprintf("\t\tat %s %s\n", decl, memberAt(region, m, fmt.Sprint(region.Start), n)))
+		if !g.arrangedInteger(m) {
+			code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(buf[at:at+%d]); err != nil {\n",
+				m.Field.Name, m.ElementSize))
+			code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", m.Field.Name))
+			code.WriteString("\t\t}\n")
+			continue
+		}
+		unsigned := fmt.Sprintf("uint%d", m.ElementSize*8)
+		load := "buf[at]"
+		if m.ElementSize > 1 {
+			load = fmt.Sprintf("%s.%s(buf[at:at+%d])", g.endianPrefix(), g.binaryGetFunc(m.ElementType), m.ElementSize)
+		}
+		if m.ElementType != unsigned && (m.ElementSize > 1 || m.ElementType != "byte") {
+			load = fmt.Sprintf("%s(%s)", m.ElementType, load)
+		}
+		code.WriteString(fmt.Sprintf("\t\tp.%s[i] = %s\n", m.Field.Name, load))
+	}
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
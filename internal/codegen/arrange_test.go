@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateArrange(t *testing.T) {
+	// @layout size=64
+	// type Index struct {
+	//     N      uint8    `layout:"@0"`
+	//     Keys   []uint32 `layout:"start-end,count=N,arrange=<arrange>"`
+	//     Flags  []int8   `layout:"parallel=Keys"`
+	//     Values []uint16 `layout:"parallel=Keys"`
+	// }
+	index := func(arrange string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Index",
+			Anno: &parser.TypeAnnotation{Size: 64},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Keys", GoType: "[]uint32", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N", Arrange: arrange}},
+				{Name: "Flags", GoType: "[]int8", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, Direction: parser.StartEnd, Parallel: "Keys"}},
+				{Name: "Values", GoType: "[]uint16", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, Direction: parser.StartEnd, Parallel: "Keys"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		arrange       string
+		expectedParts []string
+	}{
+		{
+			name:    "interleave",
+			arrange: parser.ArrangeInterleave,
+			expectedParts: []string{
+				"\t// Keys: []uint32 at [1, 64) with count=N, interleaved with Flags, Values (row size: 7)\n\toffset = 1\n",
+				"\tif len(p.Values) != int(p.N) {\n",
+				"\tif offset+len(p.Keys)*7 > 64 {\n",
+				// Each row holds a key, a flag and a value
+				"\t\tat := offset + i*7\n\t\tbinary.LittleEndian.PutUint32(buf[at:at+4], p.Keys[i])\n",
+				"\t\tat = offset + i*7 + 4\n\t\tbuf[at] = byte(p.Flags[i])\n",
+				"\t\tat = offset + i*7 + 5\n\t\tbinary.LittleEndian.PutUint16(buf[at:at+2], p.Values[i])\n",
+				"\toffset += len(p.Keys) * 7\n",
+				// Unmarshal bounds the count by whole rows and sizes every slice
+				"\t\treturn fmt.Errorf(\"Keys: count %d outside capacity 9: %w\", p.N, ErrRegionOverflow)\n",
+				"\t\tp.Flags = make([]int8, p.N)\n",
+				"\t\tat = 1 + i*7 + 4\n\t\tp.Flags[i] = int8(buf[at])\n",
+				"\t\tat = 1 + i*7 + 5\n\t\tp.Values[i] = binary.LittleEndian.Uint16(buf[at:at+2])\n",
+			},
+		},
+		{
+			name:    "columnar",
+			arrange: parser.ArrangeColumnar,
+			expectedParts: []string{
+				"\t// Keys: []uint32 at [1, 64) with count=N, in columns with Flags, Values (row size: 7)\n",
+				// Each slice takes a column after the ones before it
+				"\t\tat := offset + i*4\n",
+				"\t\tat = offset + len(p.Keys)*4 + i*1\n",
+				"\t\tat = offset + len(p.Keys)*5 + i*2\n",
+				"\t\tat = 1 + len(p.Keys)*5 + i*2\n\t\tp.Values[i] = binary.LittleEndian.Uint16(buf[at:at+2])\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := index(tt.arrange)
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if !gen.usesBinary() {
+				t.Error("usesBinary() = false, want true for multi-byte members")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// BannerEnv is the environment variable naming the banner file when -banner is
+// not given, so a repository can configure it once for every go:generate line
+const BannerEnv = "LAYOUT_BANNER"
+
+// BannerVars are the values a banner template can reference
+type BannerVars struct {
+	Version string // {{version}}: the layout version generating the file
+	Source  string // {{source}}: the input file name
+	SHA256  string // {{sha256}}: the hex SHA-256 of the input file
+}
+
+// bannerPlaceholder matches a {{name}} reference of a banner template
+var bannerPlaceholder = regexp.MustCompile(`\{\{\s*(\w*)\s*\}\}`)
+
+// GenerateBanner renders a banner template, such as a copyright or license
+// notice, as the comment block preceding the "Code generated" line of every
+// generated file. Lines not already comments are prefixed with "// ". The block
+// ends with a blank line so it never becomes the package doc.
+func GenerateBanner(template string, vars BannerVars) (string, error) {
+	values := map[string]string{
+		"version": vars.Version,
+		"source":  vars.Source,
+		"sha256":  vars.SHA256,
+	}
+	var unknown error
+	text := bannerPlaceholder.ReplaceAllStringFunc(template, func(ref string) string {
+		name := bannerPlaceholder.FindStringSubmatch(ref)[1]
+		value, ok := values[name]
+		if !ok && unknown == nil {
+			unknown = fmt.Errorf("banner: unknown placeholder %s, want {{version}}, {{source}} or {{sha256}}", ref)
+		}
+		return value
+	})
+	if unknown != nil {
+		return "", unknown
+	}
+
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return "", nil
+	}
+
+	var code strings.Builder
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		switch {
+		case strings.HasPrefix(line, "//"):
+			code.WriteString(line + "\n")
+		case line == "":
+			code.WriteString("//\n")
+		default:
+			code.WriteString("// " + line + "\n")
+		}
+	}
+	code.WriteString("\n")
+	return code.String(), nil
+}
@@ -0,0 +1,41 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateBanner(t *testing.T) {
+	vars := BannerVars{Version: "v1.2.0", Source: "page.go", SHA256: "ab12"}
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"plain text", "Copyright 2026 Example Corp.\n\nSPDX-License-Identifier: Apache-2.0\n",
+			"// Copyright 2026 Example Corp.\n//\n// SPDX-License-Identifier: Apache-2.0\n\n"},
+		{"already comments", "// Copyright 2026 Example Corp.\r\n//   Indented\r\n",
+			"// Copyright 2026 Example Corp.\n//   Indented\n\n"},
+		{"placeholders", "Generated by layout {{version}} from {{ source }} (sha256 {{sha256}})",
+			"// Generated by layout v1.2.0 from page.go (sha256 ab12)\n\n"},
+		{"empty", "\n\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateBanner(tt.template, vars)
+			if err != nil {
+				t.Fatalf("GenerateBanner() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateBanner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateBannerUnknownPlaceholder(t *testing.T) {
+	_, err := GenerateBanner("Copyright {{year}}", BannerVars{})
+	if err == nil || !strings.Contains(err.Error(), "unknown placeholder {{year}}") {
+		t.Errorf("GenerateBanner() error = %v, want unknown placeholder", err)
+	}
+}
@@ -0,0 +1,69 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkBatch reports batch=true on a mode whose encodings aren't values of a
+// fixed size encoded into the caller's buffer
+func (g *Generator) checkBatch() error {
+	if g.layout.Anno.Batch && g.mode != "copy" {
+		return fmt.Errorf("batch=true requires mode=copy, mode=%s has no MarshalLayoutInto to encode each value in place", g.mode)
+	}
+	return nil
+}
+
+// marshalInto reports whether the layout gets MarshalLayoutInto: asked for with
+// into=true, or encoding each value of a batch=true batch in place
+func (g *Generator) marshalInto() bool {
+	return g.layout.Anno.Into || g.layout.Anno.Batch
+}
+
+// generateBatch generates Marshal<Type>Batch and Unmarshal<Type>Batch (batch=true),
+// which encode values back to back, each in the layout's size, for bulk loads and
+// scans. Marshaling allocates the buffer of the whole batch once and encodes each
+// value into it through MarshalLayoutInto. Unmarshaling checks the buffer holds
+// whole records once and decodes them into a single slice.
+func (g *Generator) generateBatch() string {
+	if !g.layout.Anno.Batch {
+		return ""
+	}
+
+	typeName := g.analyzed.TypeName
+	size := g.analyzed.BufferSize
+	marshal, unmarshal := "Marshal"+typeName+"Batch", "Unmarshal"+typeName+"Batch"
+
+	var code strings.Builder
+	code.WriteString("\n")
+	code.WriteString(fmt.Sprintf("// %s encodes pages back to back, %d bytes each, into a single\n", marshal, size))
+	code.WriteString(fmt.Sprintf("// allocation: the bytes of page i are those its MarshalLayout returns, at i*%d.\n", size))
+	code.WriteString(fmt.Sprintf("func %s(pages []*%s) ([]byte, error) {\n", marshal, typeName))
+	code.WriteString(fmt.Sprintf("\tbuf := make([]byte, len(pages)*%d)\n", size))
+	code.WriteString("\tfor i, p := range pages {\n")
+	code.WriteString(fmt.Sprintf("\t\tif _, err := p.MarshalLayoutInto(buf[i*%d : (i+1)*%d]); err != nil {\n", size, size))
+	code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s: page %%d: %%w\", i, err)\n", marshal))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn buf, nil\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// %s decodes the pages %s encoded in buf, %d bytes each,\n", unmarshal, marshal, size))
+	code.WriteString("// into a single allocation. buf must hold whole pages, and fails as the first page\n")
+	code.WriteString("// failing to decode does.\n")
+	code.WriteString(fmt.Sprintf("func %s(buf []byte) ([]%s, error) {\n", unmarshal, typeName))
+	code.WriteString(fmt.Sprintf("\tif len(buf)%%%d != 0 {\n", size))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s: %%d bytes end partway through page %%d: %%w\", len(buf), len(buf)/%d, ErrTruncatedPage)\n",
+		unmarshal, size))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tpages := make([]%s, len(buf)/%d)\n", typeName, size))
+	code.WriteString("\tfor i := range pages {\n")
+	code.WriteString(fmt.Sprintf("\t\tif err := pages[i].UnmarshalLayout(buf[i*%d : (i+1)*%d]); err != nil {\n", size, size))
+	code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s: page %%d: %%w\", i, err)\n", unmarshal))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn pages, nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
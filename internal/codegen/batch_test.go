@@ -0,0 +1,59 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateBatch(t *testing.T) {
+	// @layout size=16 batch=true
+	// type Rec struct {
+	//     ID    uint64 `layout:"@0"`
+	//     Flags uint32 `layout:"@8,default=1"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Rec",
+		Anno: &parser.TypeAnnotation{Size: 16, Batch: true},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Flags", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, Default: "1"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	for _, expected := range []string{
+		// Each value is encoded in place through MarshalLayoutInto
+		"func (p Rec) MarshalLayoutInto(buf []byte) (int, error) {",
+		"func MarshalRecBatch(pages []*Rec) ([]byte, error) {\n\tbuf := make([]byte, len(pages)*16)\n",
+		"\t\tif _, err := p.MarshalLayoutInto(buf[i*16 : (i+1)*16]); err != nil {\n" +
+			"\t\t\treturn nil, fmt.Errorf(\"MarshalRecBatch: page %d: %w\", i, err)\n",
+		// One length check for the batch, one allocation for its values
+		"func UnmarshalRecBatch(buf []byte) ([]Rec, error) {\n\tif len(buf)%16 != 0 {\n",
+		"ErrTruncatedPage)\n",
+		"\tpages := make([]Rec, len(buf)/16)\n",
+		"\t\tif err := pages[i].UnmarshalLayout(buf[i*16 : (i+1)*16]); err != nil {\n",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Zerocopy values are views of their buffer, with nothing to marshal
+	zerocopy := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	if _, err := zerocopy.Generate(); err == nil || !strings.Contains(err.Error(), "batch=true requires mode=copy") {
+		t.Errorf("Generate() with mode=zerocopy error = %v, want batch=true refused", err)
+	}
+}
@@ -0,0 +1,44 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateBinaryIO generates ReadBinary and WriteBinary for binaryread=true
+// layouts, drop-in replacements for binary.Read and binary.Write of the struct:
+// the analyzer checked that both encode it to the same bytes
+func (g *Generator) generateBinaryIO() string {
+	if !g.layout.Anno.BinaryRead {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName, size := g.analyzed.TypeName, g.analyzed.BufferSize
+	order := g.endianPrefix()
+
+	code.WriteString(fmt.Sprintf("// ReadBinary reads the %d bytes of p from r and decodes them, replacing\n", size))
+	code.WriteString(fmt.Sprintf("// binary.Read(r, %s, p): the bytes are the same. Like it, ReadBinary\n", order))
+	code.WriteString("// returns io.EOF if no byte was read and io.ErrUnexpectedEOF if r ended partway\n")
+	code.WriteString("// through; unlike it, it also refuses what UnmarshalLayout refuses, such as bad\n")
+	code.WriteString("// magics.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) ReadBinary(r io.Reader) error {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tvar buf [%d]byte\n", size))
+	code.WriteString("\tif _, err := io.ReadFull(r, buf[:]); err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn p.UnmarshalLayout(buf[:])\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// WriteBinary encodes p and writes it to w, replacing binary.Write(w, %s, p)\n", order))
+	code.WriteString(fmt.Sprintf("func (p *%s) WriteBinary(w io.Writer) error {\n", typeName))
+	code.WriteString("\tbuf, err := p.MarshalLayout()\n")
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\t_, err = w.Write(buf)\n")
+	code.WriteString("\treturn err\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
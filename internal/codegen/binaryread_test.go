@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateBinaryIO(t *testing.T) {
+	// @layout size=12 endian=big binaryread=true
+	// type Msg struct {
+	//     ID    uint64 `layout:"@0"`
+	//     Flags uint32 `layout:"@8"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Msg",
+		Anno: &parser.TypeAnnotation{Size: 12, Endian: "big", BinaryRead: true},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Flags", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "big", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"// binary.Read(r, binary.BigEndian, p): the bytes are the same.",
+		"func (p *Msg) ReadBinary(r io.Reader) error {\n\tvar buf [12]byte\n" +
+			"\tif _, err := io.ReadFull(r, buf[:]); err != nil {\n\t\treturn err\n\t}\n\treturn p.UnmarshalLayout(buf[:])\n}\n",
+		"// WriteBinary encodes p and writes it to w, replacing binary.Write(w, binary.BigEndian, p)\n",
+		"func (p *Msg) WriteBinary(w io.Writer) error {\n\tbuf, err := p.MarshalLayout()\n",
+		"\t_, err = w.Write(buf)\n\treturn err\n}\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	layout.Anno.BinaryRead = false
+	if got := gen.generateBinaryIO(); got != "" {
+		t.Errorf("generateBinaryIO() without binaryread=true = %q, want none", got)
+	}
+}
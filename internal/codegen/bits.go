@@ -0,0 +1,189 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// Bit fields are read and written through their covering bytes taken as one
+// integer. With bitorder=lsb the first byte is least significant and bit 0 is the
+// low bit of a byte (little-endian bit streams, e.g. CAN Intel signals). With
+// bitorder=msb the first byte is most significant and bit 0 is the high bit of a
+// byte (network bit order, e.g. the IPv4 version nibble is @0.0,bits=4).
+
+// bitShift returns the shift of the field's low bit within its covering bytes
+func (g *Generator) bitShift(region analyzer.Region) int {
+	if g.bitOrder() == "msb" {
+		return (region.Boundary-region.Start)*8 - region.BitOffset - region.Bits
+	}
+	return region.BitOffset
+}
+
+// byteShift returns the shift of covering byte i within the covering integer
+func (g *Generator) byteShift(region analyzer.Region, i int) int {
+	if g.bitOrder() == "msb" {
+		return 8 * (region.Boundary - region.Start - 1 - i)
+	}
+	return 8 * i
+}
+
+// bitOrder returns the layout's bit order, defaulting to lsb
+func (g *Generator) bitOrder() string {
+	if g.layout != nil && g.layout.Anno != nil && g.layout.Anno.BitOrder == "msb" {
+		return "msb"
+	}
+	return "lsb"
+}
+
+// bitMask returns the mask of a field's bits before shifting
+func bitMask(bits int) uint64 {
+	if bits == 64 {
+		return ^uint64(0)
+	}
+	return 1<<bits - 1
+}
+
+// bitComment returns the field comment for a bit field
+func (g *Generator) bitComment(region analyzer.Region) string {
+	field := region.Field
+	return fmt.Sprintf("\t// %s: %s bits [%d, %d) of bytes [%d, %d) (%s)\n", field.Name, field.GoType,
+		region.BitOffset, region.BitOffset+region.Bits, region.Start, region.Boundary, g.bitOrder())
+}
+
+// bitLoad returns an expression for the field's bits, read from bufExpr. Fields
+// within one byte evaluate to a byte, wider fields to a uint64.
+func (g *Generator) bitLoad(region analyzer.Region, bufExpr string) string {
+	shift := g.bitShift(region)
+	mask := bitMask(region.Bits)
+
+	if region.Boundary-region.Start == 1 {
+		if shift == 0 {
+			return fmt.Sprintf("%s[%d]&%#x", bufExpr, region.Start, mask)
+		}
+		return fmt.Sprintf("(%s[%d]>>%d)&%#x", bufExpr, region.Start, shift, mask)
+	}
+
+	terms := make([]string, 0, region.Boundary-region.Start)
+	for i := 0; i < region.Boundary-region.Start; i++ {
+		term := fmt.Sprintf("uint64(%s[%d])", bufExpr, region.Start+i)
+		if s := g.byteShift(region, i); s > 0 {
+			term += fmt.Sprintf("<<%d", s)
+		}
+		terms = append(terms, term)
+	}
+	if shift == 0 {
+		return fmt.Sprintf("(%s)&%#x", strings.Join(terms, " | "), mask)
+	}
+	return fmt.Sprintf("((%s)>>%d)&%#x", strings.Join(terms, " | "), shift, mask)
+}
+
+// bitStore returns statements writing value into the field's bits of bufExpr,
+// leaving neighboring bits untouched. Bits of value beyond the field width are
+// dropped.
+func (g *Generator) bitStore(region analyzer.Region, bufExpr, value, indent string) string {
+	var code strings.Builder
+	shift := g.bitShift(region)
+	fieldMask := bitMask(region.Bits) << shift
+
+	if g.registry.ResolveType(region.Field.GoType) == "bool" {
+		// Single bit: covering byte is the field's only byte
+		bit := fieldMask & 0xff
+		code.WriteString(fmt.Sprintf("%sif %s {\n", indent, value))
+		code.WriteString(fmt.Sprintf("%s\t%s[%d] |= %#x\n", indent, bufExpr, region.Start, bit))
+		code.WriteString(fmt.Sprintf("%s} else {\n", indent))
+		code.WriteString(fmt.Sprintf("%s\t%s[%d] &^= %#x\n", indent, bufExpr, region.Start, bit))
+		code.WriteString(fmt.Sprintf("%s}\n", indent))
+		return code.String()
+	}
+
+	for i := 0; i < region.Boundary-region.Start; i++ {
+		s := g.byteShift(region, i)
+		byteMask := (fieldMask >> s) & 0xff
+		if byteMask == 0 {
+			continue
+		}
+
+		shifted := fmt.Sprintf("uint64(%s)", value)
+		if shift > 0 {
+			shifted += fmt.Sprintf("<<%d", shift)
+		}
+		if s > 0 {
+			shifted += fmt.Sprintf(">>%d", s)
+		}
+
+		b := fmt.Sprintf("%s[%d]", bufExpr, region.Start+i)
+		if byteMask == 0xff {
+			code.WriteString(fmt.Sprintf("%s%s = byte(%s)\n", indent, b, shifted))
+		} else {
+			code.WriteString(fmt.Sprintf("%s%s = %s&^%#x | byte(%s)&%#x\n", indent, b, b, byteMask, shifted, byteMask))
+		}
+	}
+	return code.String()
+}
+
+// generateBitOp generates marshal/unmarshal code for a bit field. Marshal refuses
+// values wider than the field rather than silently truncating them.
+func (g *Generator) generateBitOp(region analyzer.Region, op string) string {
+	var code strings.Builder
+	field := region.Field
+	bufExpr := "buf"
+	if g.mode == "zerocopy" {
+		bufExpr = "p.buf"
+	}
+	isBool := g.registry.ResolveType(field.GoType) == "bool"
+
+	code.WriteString(g.bitComment(region))
+	if op == "marshal" {
+		typeBits := 0
+		if size, err := g.registry.SizeOf(field.GoType); err == nil {
+			typeBits = size * 8
+		}
+		if !isBool && region.Bits < typeBits {
+			code.WriteString(fmt.Sprintf("\tif p.%s > %#x {\n", field.Name, bitMask(region.Bits)))
+			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s: %%d exceeds %d bits: %%w\", p.%s, ErrOutOfRange)\n",
+				field.Name, region.Bits, field.Name))
+			code.WriteString("\t}\n")
+		}
+		code.WriteString(g.bitStore(region, bufExpr, "p."+field.Name, "\t"))
+	} else if isBool {
+		code.WriteString(fmt.Sprintf("\tp.%s = %s != 0\n", field.Name, g.bitLoad(region, bufExpr)))
+	} else {
+		code.WriteString(fmt.Sprintf("\tp.%s = %s(%s)\n", field.Name, field.GoType, g.bitLoad(region, bufExpr)))
+	}
+	code.WriteString("\n")
+
+	return code.String()
+}
+
+// generateBitAccessors generates zerocopy Get/Set accessors for a bit field. The
+// setter read-modify-writes the covering bytes, dropping bits beyond the width.
+func (g *Generator) generateBitAccessors(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// Get%s returns the %d-bit %s at byte %d, bit %d\n",
+		upperFirst(field.Name), region.Bits, field.Name, region.Start, region.BitOffset))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%s() %s {\n", typeName, upperFirst(field.Name), field.GoType))
+	if g.registry.ResolveType(field.GoType) == "bool" {
+		code.WriteString(fmt.Sprintf("\treturn %s != 0\n", g.bitLoad(region, "p.buf")))
+	} else {
+		code.WriteString(fmt.Sprintf("\treturn %s(%s)\n", field.GoType, g.bitLoad(region, "p.buf")))
+	}
+	code.WriteString("}\n\n")
+
+	if _, ok := g.countCapacity(field.Name); ok {
+		code.WriteString(g.generateCountSetter(region))
+		return code.String()
+	}
+
+	code.WriteString(fmt.Sprintf("// Set%s sets the %d-bit %s at byte %d, bit %d\n",
+		upperFirst(field.Name), region.Bits, field.Name, region.Start, region.BitOffset))
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) {\n", typeName, upperFirst(field.Name), field.GoType))
+	code.WriteString(g.bitStore(region, "p.buf", "v", "\t"))
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
@@ -0,0 +1,116 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateBitFields(t *testing.T) {
+	// @layout size=4 bitorder=msb
+	// type IPHead struct {
+	//     Version uint8  `layout:"@0.0,bits=4"`
+	//     IHL     uint8  `layout:"@0.4,bits=4"`
+	//     DF      bool   `layout:"@2.1"`
+	//     FragOff uint16 `layout:"@2.3,bits=13"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "IPHead",
+		Anno: &parser.TypeAnnotation{Size: 4, BitOrder: "msb"},
+		Fields: []parser.Field{
+			{Name: "Version", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Bits: 4}},
+			{Name: "IHL", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, BitOffset: 4, Bits: 4}},
+			{Name: "DF", GoType: "bool", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed, BitOffset: 1, Bits: 1}},
+			{Name: "FragOff", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed, BitOffset: 3, Bits: 13}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal range-checks, then merges bits into shared bytes
+		`if p.Version > 0xf {`,
+		`return nil, fmt.Errorf("Version: %d exceeds 4 bits: %w", p.Version, ErrOutOfRange)`,
+		"buf[0] = buf[0]&^0xf0 | byte(uint64(p.Version)<<4)&0xf0",
+		"buf[0] = buf[0]&^0xf | byte(uint64(p.IHL))&0xf",
+		"buf[2] |= 0x40",
+		"buf[2] = buf[2]&^0x1f | byte(uint64(p.FragOff)>>8)&0x1f",
+		"buf[3] = byte(uint64(p.FragOff))",
+		// Unmarshal shifts and masks
+		"p.Version = uint8((buf[0]>>4)&0xf)",
+		"p.IHL = uint8(buf[0]&0xf)",
+		"p.DF = (buf[2]>>6)&0x1 != 0",
+		"p.FragOff = uint16((uint64(buf[2])<<8 | uint64(buf[3]))&0x1fff)",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Bit fields are byte-order free and don't need encoding/binary
+	if strings.Contains(code, "dst[2], dst[3] = dst[3], dst[2]") {
+		t.Errorf("ConvertEndian should not swap bit fields\n\nGenerated:\n%s", code)
+	}
+	for _, path := range gen.Imports() {
+		if path == "encoding/binary" {
+			t.Errorf("Imports() = %v, want no encoding/binary", gen.Imports())
+		}
+	}
+}
+
+func TestGenerateBitFieldsZeroCopy(t *testing.T) {
+	// @layout size=8 mode=zerocopy
+	// type Signal struct {
+	//     Enabled bool   `layout:"@0.0"`
+	//     Speed   uint16 `layout:"@0.1,bits=12"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Signal",
+		Anno: &parser.TypeAnnotation{Size: 8, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "Enabled", GoType: "bool", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Bits: 1}},
+			{Name: "Speed", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, BitOffset: 1, Bits: 12}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// lsb: first byte least significant, bit 0 is a byte's low bit
+	expectedParts := []string{
+		"func (p *Signal) GetSpeed() uint16 {",
+		"return uint16(((uint64(p.buf[0]) | uint64(p.buf[1])<<8)>>1)&0xfff)",
+		"func (p *Signal) SetSpeed(v uint16) {",
+		"p.buf[0] = p.buf[0]&^0xfe | byte(uint64(v)<<1)&0xfe",
+		"p.buf[1] = p.buf[1]&^0x1f | byte(uint64(v)<<1>>8)&0x1f",
+		"func (p *Signal) GetEnabled() bool {",
+		"return p.buf[0]&0x1 != 0",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// blitArches lists the GOARCHes storing integers in each byte order, whose
+// memory blit=true layouts of that endian= are copied from and to
+var blitArches = map[string][]string{
+	"little": {"386", "amd64", "arm", "arm64", "loong64", "mips64le", "mipsle", "ppc64le", "riscv64", "wasm"},
+	"big":    {"mips", "mips64", "ppc64", "s390x"},
+}
+
+// BlitConstraint returns the build constraint of the file copying the memory of
+// the blit=true layouts of the endian= byte order: the GOARCHes storing integers
+// in that order, unless built with the purego tag
+func BlitConstraint(endian string) string {
+	if endian == "" {
+		endian = "little"
+	}
+	return fmt.Sprintf("(%s) && !purego", strings.Join(blitArches[endian], " || "))
+}
+
+// SetBlit sets whether a blit=true layout marshals by copying its memory, for
+// the file built on hosts of its byte order (BlitConstraint). Other layouts and
+// the file built elsewhere encode field by field.
+func (g *Generator) SetBlit(blit bool) {
+	g.blit = blit && g.layout.Anno.Blit
+}
+
+// generateBlitMarshal generates the copy-mode MarshalLayout of a blitted layout,
+// whose encoding the analyzer proved is the struct's memory
+func (g *Generator) generateBlitMarshal() string {
+	size := g.analyzed.BufferSize
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", g.analyzed.TypeName))
+	code.WriteString(g.generateMarshalDefaults())
+	code.WriteString(fmt.Sprintf("\tbuf := make([]byte, %d)\n", size))
+	code.WriteString(fmt.Sprintf("\tcopy(buf, (*[%d]byte)(unsafe.Pointer(p))[:]) // blit=true: the memory is the encoding\n", size))
+	code.WriteString("\treturn buf, nil\n")
+	code.WriteString("}\n")
+	return code.String()
+}
+
+// generateBlitUnmarshal generates the copy-mode UnmarshalLayout of a blitted
+// layout, which copies buf over the struct and then checks magic= fields
+func (g *Generator) generateBlitUnmarshal() string {
+	size := g.analyzed.BufferSize
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", g.analyzed.TypeName))
+	code.WriteString(generateLengthCheck("len(buf)", size, size, "", ""))
+	code.WriteString("\n")
+	code.WriteString(fmt.Sprintf("\tcopy((*[%d]byte)(unsafe.Pointer(p))[:], buf) // blit=true: the encoding is the memory\n", size))
+	for _, region := range g.analyzed.Regions {
+		if region.Field.Layout.Magic {
+			code.WriteString(magicCheck(region.Field))
+		}
+	}
+	code.WriteString("\n")
+	code.WriteString(g.generateCanonicalCheck())
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n")
+	return code.String()
+}
+
+// generateBlitAssertions generates compile-time checks that the struct of a
+// blitted layout declares every field at its tagged offset and size and holds
+// nothing else, as mirror= does for another struct. The analyzer proved it from
+// the tags; these hold the compiler to it.
+func (g *Generator) generateBlitAssertions() string {
+	if !g.blit {
+		return ""
+	}
+
+	typeName := g.analyzed.TypeName
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// %s is copied as its memory; these fail to compile when the memory drifts from the layout\n", typeName))
+	code.WriteString("var (\n")
+	code.WriteString(g.offsetAssertions(typeName))
+	code.WriteString("\t// The struct holds the buffer and nothing else\n")
+	code.WriteString(fmt.Sprintf("\t_ [0]struct{} = [unsafe.Sizeof(%s{}) - %d]struct{}{}\n", typeName, g.analyzed.BufferSize))
+	code.WriteString(")\n")
+
+	return code.String()
+}
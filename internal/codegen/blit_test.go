@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateBlit(t *testing.T) {
+	// @layout size=24 blit=true into=true
+	// type Rec struct {
+	//     Magic   uint32  `layout:"@0,magic=0xCAFE"`
+	//     Version uint16  `layout:"@4,default=1"`
+	//     Flags   uint16  `layout:"@6"`
+	//     ID      uint64  `layout:"@8"`
+	//     Key     [8]byte `layout:"@16"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Rec",
+		Anno: &parser.TypeAnnotation{Size: 24, Blit: true, Into: true},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "0xCAFE", Magic: true}},
+			{Name: "Version", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Default: "1"}},
+			{Name: "Flags", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			{Name: "Key", GoType: "[8]byte", Layout: &parser.FieldLayout{Offset: 16, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	gen.SetBlit(true)
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	for _, expected := range []string{
+		// Defaults apply before the copy, magics are checked after it
+		"\tif p.Version == 0 {\n\t\tp.Version = 1\n\t}\n\n\tbuf := make([]byte, 24)\n" +
+			"\tcopy(buf, (*[24]byte)(unsafe.Pointer(p))[:]) // blit=true: the memory is the encoding\n\treturn buf, nil\n",
+		"\tbuf = buf[:24]\n\tcopy(buf, (*[24]byte)(unsafe.Pointer(&p))[:])",
+		"\tcopy((*[24]byte)(unsafe.Pointer(p))[:], buf) // blit=true: the encoding is the memory\n" +
+			"\tif p.Magic != 0xCAFE {\n",
+		// The compiler holds the struct to the layout
+		"\t_ [0]struct{} = [unsafe.Offsetof(Rec{}.ID) - 8]struct{}{}\n",
+		"\t_ [0]struct{} = [unsafe.Sizeof(Rec{}.Key) - 8]struct{}{}\n",
+		"\t_ [0]struct{} = [unsafe.Sizeof(Rec{}) - 24]struct{}{}\n",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	_, marshal, _ := strings.Cut(code, "func (p *Rec) MarshalLayout() ([]byte, error) {")
+	if marshal, _, _ = strings.Cut(marshal, "\n}\n"); strings.Contains(marshal, "binary.") {
+		t.Errorf("Blitted MarshalLayout encodes fields one by one:\n%s", marshal)
+	}
+	if !slices.Contains(gen.Imports(), "unsafe") {
+		t.Errorf("Imports() = %v, want unsafe", gen.Imports())
+	}
+
+	// Hosts of the other byte order encode field by field, without unsafe
+	portable := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err = portable.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if strings.Contains(code, "unsafe.") || slices.Contains(portable.Imports(), "unsafe") {
+		t.Errorf("Portable code uses unsafe\n\nGenerated:\n%s", code)
+	}
+
+	if got, want := BlitConstraint("big"), "(mips || mips64 || ppc64 || s390x) && !purego"; got != want {
+		t.Errorf("BlitConstraint(big) = %q, want %q", got, want)
+	}
+
+	// unsafe=false refuses the copies
+	layout.Anno.NoUnsafe = true
+	if _, err := portable.Generate(); err == nil || !strings.Contains(err.Error(), "unsafe=false cannot be combined with blit=true") {
+		t.Errorf("Generate() with unsafe=false error = %v, want blit=true refused", err)
+	}
+}
@@ -0,0 +1,58 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateBufferIntake generates the start of zerocopy UnmarshalLayout, which copies
+// buf into p.buf unless buf already is p.buf. A buf overlapping p.buf at a different
+// offset (e.g., p.buf[8:]) is refused: the copy would shift bytes under the caller's
+// view of the buffer.
+func (g *Generator) generateBufferIntake() string {
+	var code strings.Builder
+
+	code.WriteString("\t// Zero-copy mode: copy buf into p.buf unless it already is p.buf\n")
+	code.WriteString("\tif len(buf) > 0 && len(p.buf) > 0 && &buf[0] != &p.buf[0] {\n")
+	code.WriteString("\t\tsrc := uintptr(unsafe.Pointer(&buf[0]))\n")
+	code.WriteString("\t\tdst := uintptr(unsafe.Pointer(&p.buf[0]))\n")
+	code.WriteString("\t\tif src < dst+uintptr(len(p.buf)) && dst < src+uintptr(len(buf)) {\n")
+	code.WriteString("\t\t\treturn fmt.Errorf(\"UnmarshalLayout: buf overlaps p.buf at a different offset: %w\", ErrBadBuffer)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tcopy(p.buf[:], buf)\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// generateSetBuffer generates SetBuffer for slice-backed zerocopy types (align= or
+// allocator=). SetBuffer takes ownership of the caller's buffer instead of copying
+// it, so p aliases buf from then on. Array-backed types can't alias a slice and
+// don't get SetBuffer.
+func (g *Generator) generateSetBuffer() string {
+	if g.align == 0 && g.allocator == "" {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	bufferSize := g.analyzed.BufferSize
+
+	code.WriteString(fmt.Sprintf("// SetBuffer makes buf the backing buffer of %s without copying. Accessors and\n", typeName))
+	code.WriteString("// MarshalLayout write through to buf, so the caller must not reuse it while p is live.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) SetBuffer(buf []byte) error {\n", typeName))
+	code.WriteString(generateLengthCheck("len(buf)", bufferSize, bufferSize, "SetBuffer", ""))
+	if g.align > 0 {
+		code.WriteString(fmt.Sprintf("\tif uintptr(unsafe.Pointer(&buf[0]))%%%d != 0 {\n", g.align))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"SetBuffer: buf is not %d-byte aligned: %%w\", ErrBadBuffer)\n", g.align))
+		code.WriteString("\t}\n")
+		if g.allocator == "" {
+			code.WriteString("\tp.backing = nil // Release the buffer allocated by New\n")
+		}
+	}
+	code.WriteString("\tp.buf = buf\n")
+	code.WriteString("\treturn p.UnmarshalLayout(p.buf)\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
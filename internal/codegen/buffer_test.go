@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateBufferAliasing(t *testing.T) {
+	// @layout size=64 mode=zerocopy align=...
+	// type Page struct {
+	//     Header uint16 `layout:"@0"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "Header", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	// Array-backed: overlap detection, no SetBuffer
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	expectedParts := []string{
+		"if src < dst+uintptr(len(p.buf)) && dst < src+uintptr(len(buf)) {",
+		`return fmt.Errorf("UnmarshalLayout: buf overlaps p.buf at a different offset: %w", ErrBadBuffer)`,
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+	if strings.Contains(code, "SetBuffer") {
+		t.Error("SetBuffer generated for array-backed buf")
+	}
+
+	// Slice-backed with alignment: SetBuffer aliases the caller's buffer
+	layout.Anno.Align = 512
+	gen = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 512, "")
+	code, err = gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	expectedParts = []string{
+		"func (p *Page) SetBuffer(buf []byte) error {",
+		"if len(buf) < 64 {",
+		"if uintptr(unsafe.Pointer(&buf[0]))%512 != 0 {",
+		"p.backing = nil",
+		"p.buf = buf\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateBuilderHeader generates the package clause of a test data builder
+// file, followed by one GenerateBuilder per type
+func GenerateBuilderHeader(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+
+	return code.String()
+}
+
+// GenerateBuilder generates a fluent builder of encoded buffers for tests:
+// New<Type>Builder, a With<Field> per tagged field and Build, which sets the
+// count fields from the lengths of their slices and marshals. Fixtures built
+// this way follow the layout as it changes instead of spelling out its bytes.
+// Zerocopy types have no builder, their setters already write the buffer.
+func (g *Generator) GenerateBuilder() string {
+	if g.mode == "zerocopy" || g.layout.Anno.Raw != "" {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	builder := typeName + "Builder"
+
+	code.WriteString(fmt.Sprintf("// %s builds encoded %s buffers for tests\n", builder, typeName))
+	code.WriteString(fmt.Sprintf("type %s struct {\n", builder))
+	code.WriteString(fmt.Sprintf("\tvalue %s\n", typeName))
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// New%s returns a builder of a zero %s\n", builder, typeName))
+	code.WriteString(fmt.Sprintf("func New%s() *%s {\n", builder, builder))
+	code.WriteString(fmt.Sprintf("\treturn &%s{}\n", builder))
+	code.WriteString("}\n")
+
+	for _, field := range g.layout.Fields {
+		fl := field.Layout
+		if field.Name == "_" || fl.Reserve > 0 || fl.Overlap || fl.Get != "" {
+			continue // Reserved, aliased or computed on marshal
+		}
+		param := "v " + field.GoType
+		if elem, ok := strings.CutPrefix(field.GoType, "[]"); ok && elem != "byte" {
+			param = "v ..." + elem
+		}
+		code.WriteString(fmt.Sprintf("\n// With%s sets %s\n", upperFirst(field.Name), field.Name))
+		code.WriteString(fmt.Sprintf("func (b *%s) With%s(%s) *%s {\n", builder, upperFirst(field.Name), param, builder))
+		code.WriteString(fmt.Sprintf("\tb.value.%s = v\n", field.Name))
+		code.WriteString("\treturn b\n")
+		code.WriteString("}\n")
+	}
+
+	counts := g.builderCounts()
+	if len(counts) > 0 {
+		code.WriteString(fmt.Sprintf("\n// Build sets the count fields of %s from the lengths of their slices and\n", typeName))
+		code.WriteString("// returns its encoding. Panics if it doesn't marshal.\n")
+	} else {
+		code.WriteString(fmt.Sprintf("\n// Build returns the encoding of %s. Panics if it doesn't marshal.\n", typeName))
+	}
+	code.WriteString(fmt.Sprintf("func (b *%s) Build() []byte {\n", builder))
+	for _, count := range counts {
+		code.WriteString(fmt.Sprintf("\tb.value.%s = %s(len(b.value.%s))\n", count.field, g.countFieldType(count.field), count.slice))
+	}
+	code.WriteString("\tbuf, err := b.value.MarshalLayout()\n")
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString(fmt.Sprintf("\t\tpanic(\"%s.Build: \" + err.Error())\n", builder))
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn buf\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// builderCount is a count field Build keeps in step with a slice
+type builderCount struct {
+	field string // Count field, e.g. "Header.NumKeys"
+	slice string // Slice it counts
+}
+
+// builderCounts returns the count fields Build sets, each from the first slice
+// counted by it: slices sharing a count must have its length already, which
+// MarshalLayout checks. Counts of a custom kind are left to the caller.
+func (g *Generator) builderCounts() []builderCount {
+	var counts []builderCount
+	seen := make(map[string]bool)
+	for _, field := range g.layout.Fields {
+		countField := field.Layout.CountField
+		if countField == "" || field.Layout.From != "" || seen[countField] {
+			continue
+		}
+		seen[countField] = true
+		if g.countKind(countField) {
+			continue
+		}
+		counts = append(counts, builderCount{countField, field.Name})
+	}
+	return counts
+}
+
+// countKind reports whether a count field, possibly nested, is encoded by a
+// custom kind, whose Go type needn't convert from int
+func (g *Generator) countKind(countField string) bool {
+	parts := strings.Split(countField, ".")
+	fields := g.layout.Fields
+	for i, name := range parts {
+		goType := ""
+		for _, f := range fields {
+			if f.Name != name {
+				continue
+			}
+			if i == len(parts)-1 {
+				return f.Layout != nil && f.Layout.Kind != ""
+			}
+			goType = f.GoType
+		}
+		fields = nil
+		for _, layout := range g.allLayouts {
+			if layout.Name == goType {
+				fields = layout.Fields
+			}
+		}
+	}
+	return false
+}
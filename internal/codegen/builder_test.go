@@ -0,0 +1,86 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateBuilder(t *testing.T) {
+	// @layout size=8
+	// type Header struct {
+	//     NumKeys uint16 `layout:"@0"`
+	//     Flags   uint16 `layout:"@2"`
+	// }
+	//
+	// @layout size=128
+	// type Page struct {
+	//     Header Header   `layout:"@0"`
+	//     Pad    [4]byte  `layout:"@8,reserve=4"`
+	//     Items  []uint32 `layout:"@16,start-end,count=Header.NumKeys"`
+	//     Tail   []byte   `layout:"@128,end-start"`
+	// }
+	header := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "NumKeys", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Flags", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128},
+		Fields: []parser.Field{
+			{Name: "Header", GoType: "Header", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Pad", GoType: "[4]byte", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, Reserve: 4}},
+			{Name: "Items", GoType: "[]uint32", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "Header.NumKeys"}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 128, Direction: parser.EndStart}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Header", 8)
+	reg.RegisterFields("Header", header.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	allLayouts := []*parser.TypeLayout{header, layout}
+	gen := NewGenerator(analyzed, layout, allLayouts, reg, "little", "copy", 0, "")
+	code := GenerateBuilderHeader("pages") + gen.GenerateBuilder()
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n\nGenerated:\n%s", err, code)
+	}
+
+	expectedParts := []string{
+		"package pages\n",
+		"type PageBuilder struct {\n\tvalue Page\n}\n",
+		"func NewPageBuilder() *PageBuilder {\n\treturn &PageBuilder{}\n}\n",
+		"func (b *PageBuilder) WithHeader(v Header) *PageBuilder {\n\tb.value.Header = v\n\treturn b\n}\n",
+		// Struct slices take their elements, byte regions their bytes
+		"func (b *PageBuilder) WithItems(v ...uint32) *PageBuilder {\n",
+		"func (b *PageBuilder) WithTail(v []byte) *PageBuilder {\n",
+		// The nested count follows the slice whenever it's set
+		"\tb.value.Header.NumKeys = uint16(len(b.value.Items))\n\tbuf, err := b.value.MarshalLayout()\n",
+		"\t\tpanic(\"PageBuilder.Build: \" + err.Error())\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "WithPad") {
+		t.Errorf("reserved range got a setter\n\nGenerated:\n%s", code)
+	}
+
+	// Zerocopy types write their buffer through their setters instead
+	layout.Anno.Mode = "zerocopy"
+	if got := NewGenerator(analyzed, layout, allLayouts, reg, "little", "zerocopy", 0, "").GenerateBuilder(); got != "" {
+		t.Errorf("zerocopy GenerateBuilder() = %q, want none", got)
+	}
+}
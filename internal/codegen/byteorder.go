@@ -0,0 +1,162 @@
+package codegen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// ByteOrderFile is the file holding the byte orders encoding/binary lacks, written
+// once per package directory when any of its layouts is annotated with one
+const ByteOrderFile = "layout_byteorder.go"
+
+// byteOrderFamily is an endian= byte order: how generated code encodes integers
+// in it, and how ConvertEndian converts them to and from another order. Orders
+// outside encoding/binary are generated into ByteOrderFile as binary.ByteOrder
+// implementations, so every call site stays the same.
+type byteOrderFamily struct {
+	expr    string                       // Expression implementing binary.ByteOrder, e.g. binary.BigEndian
+	order   binary.ByteOrder             // The same order at generation time, for corrupt variants
+	swap    func(start, size int) string // Statement converting dst[start:start+size]; "" if unchanged
+	convert string                       // The orders ConvertEndian converts between
+	custom  bool                         // Generated into ByteOrderFile
+}
+
+// byteOrders are the endian= byte orders by name
+var byteOrders = map[string]byteOrderFamily{
+	"little": {expr: "binary.LittleEndian", order: binary.LittleEndian, swap: swapBytes, convert: "little and big endian"},
+	"big":    {expr: "binary.BigEndian", order: binary.BigEndian, swap: swapBytes, convert: "little and big endian"},
+	"native": {expr: "binary.NativeEndian", order: binary.NativeEndian, swap: swapBytes, convert: "little and big endian"},
+	// PDP-11 order: little-endian 16-bit words, the most significant word first
+	"pdp": {expr: "layoutPDPEndian{}", order: pdpEndian{}, swap: swapWords, convert: "PDP-11 and little endian", custom: true},
+}
+
+// byteOrderFamily returns the family of endian=, little endian by default
+func (g *Generator) byteOrderFamily() byteOrderFamily {
+	return byteOrderOf(g.endian)
+}
+
+// byteOrderOf returns the family named endian, little endian for "" and unknown names
+func byteOrderOf(endian string) byteOrderFamily {
+	if family, ok := byteOrders[endian]; ok {
+		return family
+	}
+	return byteOrders["little"]
+}
+
+// swapWords returns a tuple assignment reversing the order of the 16-bit words
+// of dst[start:start+size], keeping the bytes of each word in place; "" for a
+// single word
+func swapWords(start, size int) string {
+	words := size / 2
+	if words < 2 {
+		return ""
+	}
+	lhs := make([]string, 0, size)
+	rhs := make([]string, 0, size)
+	for i := 0; i < words; i++ {
+		from := start + (words-1-i)*2
+		lhs = append(lhs, fmt.Sprintf("dst[%d]", start+i*2), fmt.Sprintf("dst[%d]", start+i*2+1))
+		rhs = append(rhs, fmt.Sprintf("dst[%d]", from), fmt.Sprintf("dst[%d]", from+1))
+	}
+	return strings.Join(lhs, ", ") + " = " + strings.Join(rhs, ", ")
+}
+
+// generateByteOrderAssertion asserts that a generated byte order implements
+// binary.ByteOrder, which also keeps the encoding/binary import in use when the
+// order replaces every binary call of the type
+func (g *Generator) generateByteOrderAssertion(code string) string {
+	family := g.byteOrderFamily()
+	if !family.custom || !strings.Contains(code, family.expr) {
+		return ""
+	}
+	return fmt.Sprintf("\nvar _ binary.ByteOrder = %s\n", family.expr)
+}
+
+// pdpEndian is the PDP-11 byte order at generation time, matching the
+// layoutPDPEndian type GenerateByteOrder generates
+type pdpEndian struct{}
+
+func (pdpEndian) Uint16(b []byte) uint16 {
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (pdpEndian) PutUint16(b []byte, v uint16) {
+	binary.LittleEndian.PutUint16(b, v)
+}
+
+func (pdpEndian) Uint32(b []byte) uint32 {
+	return uint32(binary.LittleEndian.Uint16(b))<<16 | uint32(binary.LittleEndian.Uint16(b[2:]))
+}
+
+func (pdpEndian) PutUint32(b []byte, v uint32) {
+	binary.LittleEndian.PutUint16(b, uint16(v>>16))
+	binary.LittleEndian.PutUint16(b[2:], uint16(v))
+}
+
+func (e pdpEndian) Uint64(b []byte) uint64 {
+	return uint64(e.Uint32(b))<<32 | uint64(e.Uint32(b[4:]))
+}
+
+func (e pdpEndian) PutUint64(b []byte, v uint64) {
+	e.PutUint32(b, uint32(v>>32))
+	e.PutUint32(b[4:], uint32(v))
+}
+
+func (pdpEndian) String() string {
+	return "PDPEndian"
+}
+
+// UsesByteOrderFile reports whether any endian= of endians needs ByteOrderFile
+func UsesByteOrderFile(endians ...string) bool {
+	for _, endian := range endians {
+		if byteOrderOf(endian).custom {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateByteOrder generates the byte order file of package pkg: the
+// binary.ByteOrder implementations of the endian= orders encoding/binary lacks
+func GenerateByteOrder(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import \"encoding/binary\"\n\n")
+	code.WriteString("// layoutPDPEndian is the PDP-11 byte order of endian=pdp layouts: 16-bit words\n")
+	code.WriteString("// are little endian, and wider integers store their most significant word\n")
+	code.WriteString("// first, so 0x0A0B0C0D is encoded 0B 0A 0D 0C.\n")
+	code.WriteString("type layoutPDPEndian struct{}\n\n")
+
+	code.WriteString("func (layoutPDPEndian) Uint16(b []byte) uint16 {\n")
+	code.WriteString("\treturn binary.LittleEndian.Uint16(b)\n")
+	code.WriteString("}\n\n")
+	code.WriteString("func (layoutPDPEndian) PutUint16(b []byte, v uint16) {\n")
+	code.WriteString("\tbinary.LittleEndian.PutUint16(b, v)\n")
+	code.WriteString("}\n\n")
+	code.WriteString("func (layoutPDPEndian) Uint32(b []byte) uint32 {\n")
+	code.WriteString("\t_ = b[3] // Bounds check hint to compiler\n")
+	code.WriteString("\treturn uint32(binary.LittleEndian.Uint16(b))<<16 | uint32(binary.LittleEndian.Uint16(b[2:]))\n")
+	code.WriteString("}\n\n")
+	code.WriteString("func (layoutPDPEndian) PutUint32(b []byte, v uint32) {\n")
+	code.WriteString("\t_ = b[3] // Bounds check hint to compiler\n")
+	code.WriteString("\tbinary.LittleEndian.PutUint16(b, uint16(v>>16))\n")
+	code.WriteString("\tbinary.LittleEndian.PutUint16(b[2:], uint16(v))\n")
+	code.WriteString("}\n\n")
+	code.WriteString("func (e layoutPDPEndian) Uint64(b []byte) uint64 {\n")
+	code.WriteString("\t_ = b[7] // Bounds check hint to compiler\n")
+	code.WriteString("\treturn uint64(e.Uint32(b))<<32 | uint64(e.Uint32(b[4:]))\n")
+	code.WriteString("}\n\n")
+	code.WriteString("func (e layoutPDPEndian) PutUint64(b []byte, v uint64) {\n")
+	code.WriteString("\t_ = b[7] // Bounds check hint to compiler\n")
+	code.WriteString("\te.PutUint32(b, uint32(v>>32))\n")
+	code.WriteString("\te.PutUint32(b[4:], uint32(v))\n")
+	code.WriteString("}\n\n")
+	code.WriteString("func (layoutPDPEndian) String() string {\n")
+	code.WriteString("\treturn \"PDPEndian\"\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
@@ -0,0 +1,121 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerate_PDPEndian(t *testing.T) {
+	// @layout size=16 endian=pdp
+	// type Record struct {
+	//     Flags uint8  `layout:"@0"`
+	//     Mode  uint16 `layout:"@2"`
+	//     Size  uint32 `layout:"@4"`
+	//     Time  int64  `layout:"@8"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Record",
+		Anno: &parser.TypeAnnotation{Size: 16, Endian: "pdp"},
+		Fields: []parser.Field{
+			{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Mode", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Size", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "Time", GoType: "int64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "pdp", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"layoutPDPEndian{}.PutUint32(buf[4:8], p.Size)",
+		"p.Mode = layoutPDPEndian{}.Uint16(buf[2:4])",
+		"var _ binary.ByteOrder = layoutPDPEndian{}",
+		// ConvertEndian reverses the words of wider fields, leaving single words alone
+		"fixed fields between PDP-11 and little endian",
+		"dst[4], dst[5], dst[6], dst[7] = dst[6], dst[7], dst[4], dst[5] // Size",
+		"dst[8], dst[9], dst[10], dst[11], dst[12], dst[13], dst[14], dst[15] = dst[14], dst[15], dst[12], dst[13], dst[10], dst[11], dst[8], dst[9] // Time",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+	for _, unexpected := range []string{"binary.LittleEndian", "dst[2], dst[3] ="} {
+		if strings.Contains(code, unexpected) {
+			t.Errorf("Generated code contains %q", unexpected)
+		}
+	}
+
+	// The other orders assert nothing
+	little := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	if code, _ := little.Generate(); strings.Contains(code, "var _ binary.ByteOrder") {
+		t.Error("endian=little asserts a byte order")
+	}
+}
+
+func TestPDPEndian(t *testing.T) {
+	var order pdpEndian
+	b := make([]byte, 8)
+
+	order.PutUint32(b, 0x0A0B0C0D)
+	if want := []byte{0x0B, 0x0A, 0x0D, 0x0C}; !bytes.Equal(b[:4], want) {
+		t.Errorf("PutUint32(0x0A0B0C0D) = % x, want % x", b[:4], want)
+	}
+	if got := order.Uint32(b); got != 0x0A0B0C0D {
+		t.Errorf("Uint32() = %#x, want 0x0a0b0c0d", got)
+	}
+
+	order.PutUint64(b, 0x0102030405060708)
+	if want := []byte{0x02, 0x01, 0x04, 0x03, 0x06, 0x05, 0x08, 0x07}; !bytes.Equal(b, want) {
+		t.Errorf("PutUint64(0x0102030405060708) = % x, want % x", b, want)
+	}
+	if got := order.Uint64(b); got != 0x0102030405060708 {
+		t.Errorf("Uint64() = %#x, want 0x0102030405060708", got)
+	}
+
+	// Corrupt variants write counts through the same order
+	putUint(b[:4], 300, order)
+	if got := readUint(b[:4], order); got != 300 {
+		t.Errorf("readUint() = %d, want 300", got)
+	}
+}
+
+func TestGenerateByteOrder(t *testing.T) {
+	code := GenerateByteOrder("pages")
+
+	expectedParts := []string{
+		"package pages",
+		`import "encoding/binary"`,
+		"type layoutPDPEndian struct{}",
+		"func (layoutPDPEndian) Uint16(b []byte) uint16 {",
+		"func (layoutPDPEndian) PutUint32(b []byte, v uint32) {",
+		"func (e layoutPDPEndian) Uint64(b []byte) uint64 {",
+		"func (layoutPDPEndian) String() string {",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+
+	if !UsesByteOrderFile("little", "pdp") {
+		t.Error("UsesByteOrderFile(little, pdp) = false, want true")
+	}
+	if UsesByteOrderFile("little", "big", "") {
+		t.Error("UsesByteOrderFile(little, big) = true, want false")
+	}
+}
@@ -0,0 +1,41 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateCanonicalCheck generates the end of UnmarshalLayout under canonical=true:
+// the decoded value is re-encoded, and buf refused unless it is that encoding.
+// MarshalLayout zeroes gaps, padding and unused region space and packs dynamic
+// data in order, so only buffers written that way decode, and every buffer that
+// decodes re-encodes to itself, as content addressing and checksums need.
+func (g *Generator) generateCanonicalCheck() string {
+	if !g.layout.Anno.Canonical {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t// canonical=true: buf must be the encoding MarshalLayout produces\n")
+	code.WriteString("\tcanonical, err := p.MarshalLayout()\n")
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\treturn fmt.Errorf(\"re-encode: %w\", err)\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\tif len(canonical) != len(buf) {\n")
+	code.WriteString("\t\treturn fmt.Errorf(\"canonical encoding is %d bytes, got %d: %w\", len(canonical), len(buf), ErrNotCanonical)\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\tfor i := range canonical {\n")
+	code.WriteString("\t\tif canonical[i] != buf[i] {\n")
+	code.WriteString("\t\t\treturn fmt.Errorf(\"byte %d is %#02x, canonical encoding has %#02x: %w\", i, buf[i], canonical[i], ErrNotCanonical)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n\n")
+	return code.String()
+}
+
+// checkCanonical reports canonical=true on a mode keeping no separate encoding
+func (g *Generator) checkCanonical() error {
+	if g.layout.Anno.Canonical && g.mode == "zerocopy" {
+		return fmt.Errorf("canonical=true requires mode=copy or mode=stream, a zerocopy buffer is its own encoding")
+	}
+	return nil
+}
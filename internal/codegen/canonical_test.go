@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCanonicalCheck(t *testing.T) {
+	// @layout size=16 canonical=true [mode=stream length=Len]
+	// type Msg struct {
+	//     Len  uint16 `layout:"@0"`
+	//     Body []byte `layout:"start-end"`
+	// }
+	tests := []struct {
+		name, mode string
+		length     string
+	}{
+		{"copy", "copy", ""},
+		{"stream", "stream", "Len"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Msg",
+				Anno: &parser.TypeAnnotation{Size: 16, Mode: tt.mode, Length: tt.length, Canonical: true},
+				Fields: []parser.Field{
+					{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+
+			expectedParts := []string{
+				"\tcanonical, err := p.MarshalLayout()\n\tif err != nil {\n\t\treturn fmt.Errorf(\"re-encode: %w\", err)\n\t}\n",
+				"return fmt.Errorf(\"canonical encoding is %d bytes, got %d: %w\", len(canonical), len(buf), ErrNotCanonical)",
+				"return fmt.Errorf(\"byte %d is %#02x, canonical encoding has %#02x: %w\", i, buf[i], canonical[i], ErrNotCanonical)",
+			}
+			for _, expected := range expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateCanonicalZeroCopy(t *testing.T) {
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 8, Mode: "zerocopy", Canonical: true},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	_, err = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "").Generate()
+	if err == nil || !strings.Contains(err.Error(), "a zerocopy buffer is its own encoding") {
+		t.Errorf("Generate() error = %v, want zerocopy refused", err)
+	}
+}
@@ -0,0 +1,242 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// capacityPair is a start-end and an end-start region growing into the same
+// free space
+type capacityPair struct {
+	forward, backward analyzer.Region
+	space             int // Bytes the two share
+}
+
+// generateCapacity generates the geometry that split and fit decisions need:
+// <Type><Field>Capacity, the bytes of each dynamic region, and <Type>Max<Field>,
+// the elements of a struct slice that fit in it alone. Regions growing into the
+// same free space get <Type>FreeSpace, the bytes they leave with given counts,
+// and indirect slices <Type>Max<Field>Size, the largest entry that fits beside
+// one element and the other slices' entries. Region= chains are located at
+// runtime and left out, as are prefix-encoded slices, whose stored size depends
+// on the entry before.
+func (g *Generator) generateCapacity() string {
+	typeName := g.analyzed.TypeName
+
+	type constant struct {
+		name, value, comment string
+	}
+	var constants []constant
+	width, valueWidth := 0, 0
+	var regions []analyzer.Region
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.DynamicRegion || region.Field.Layout.Group != "" {
+			continue
+		}
+		regions = append(regions, region)
+		low, high := g.regionSpan(region)
+		name := typeName + upperFirst(region.Field.Name) + "Capacity"
+		constants = append(constants, constant{name, fmt.Sprint(high - low), "bytes"})
+		width, valueWidth = max(width, len(name)), max(valueWidth, len(fmt.Sprint(high-low)))
+
+		if n, ok := g.maxElements(region); ok {
+			name := typeName + "Max" + upperFirst(region.Field.Name)
+			constants = append(constants, constant{name, fmt.Sprint(n), "elements"})
+			width, valueWidth = max(width, len(name)), max(valueWidth, len(fmt.Sprint(n)))
+		}
+	}
+	if len(constants) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	if len(constants) == len(regions) {
+		code.WriteString(fmt.Sprintf("// Capacities in bytes of the dynamic regions of %s\n", typeName))
+	} else {
+		code.WriteString(fmt.Sprintf("// Capacities of the dynamic regions of %s: their bytes, and the most\n", typeName))
+		code.WriteString("// elements of a struct slice that fit when the region holds nothing else\n")
+	}
+	code.WriteString("const (\n")
+	for _, c := range constants {
+		code.WriteString(fmt.Sprintf("\t%-*s = %-*s // %s\n", width, c.name, valueWidth, c.value, c.comment))
+	}
+	code.WriteString(")\n\n")
+
+	pairs := g.sharedSpace(regions)
+	for _, pair := range pairs {
+		code.WriteString(g.generateFreeSpace(pair, len(pairs) > 1))
+	}
+	for _, field := range g.layout.Fields {
+		if field.Layout.From != "" && field.Layout.Region != "" && !isPrefix(field) {
+			code.WriteString(g.generateMaxEntrySize(field, pairs))
+		}
+	}
+	return code.String()
+}
+
+// maxElements returns how many elements of a struct slice its region holds,
+// within its max= and what its count field can store. Not ok for byte regions,
+// whose capacity says as much, and elements of varying length.
+func (g *Generator) maxElements(region analyzer.Region) (int, bool) {
+	fl := region.Field.Layout
+	if isByteRegion(region) || fl.Encode != "" || g.isFramed(region) || region.ElementStride <= 0 {
+		return 0, false
+	}
+	low, high := g.regionSpan(region)
+	n := (high - low) / region.ElementStride
+	if fl.MaxCount > 0 {
+		n = min(n, fl.MaxCount)
+	}
+	if fl.CountField != "" {
+		if limit := g.countLimit(fl.CountField); limit >= 0 {
+			n = min(n, limit)
+		}
+	}
+	return n, true
+}
+
+// sharedSpace pairs the start-end and end-start regions whose spans overlap,
+// each growing into the free space the other leaves
+func (g *Generator) sharedSpace(regions []analyzer.Region) []capacityPair {
+	var pairs []capacityPair
+	for _, fwd := range regions {
+		if fwd.Direction != parser.StartEnd {
+			continue
+		}
+		fwdLow, fwdHigh := g.regionSpan(fwd)
+		for _, bwd := range regions {
+			if bwd.Direction != parser.EndStart {
+				continue
+			}
+			bwdLow, bwdHigh := g.regionSpan(bwd)
+			if fwdLow < bwdHigh && bwdLow < fwdHigh {
+				pairs = append(pairs, capacityPair{fwd, bwd, max(fwdHigh, bwdHigh) - min(fwdLow, bwdLow)})
+			}
+		}
+	}
+	return pairs
+}
+
+// generateFreeSpace generates <Type>FreeSpace, the bytes a pair of regions
+// leaves free holding the given numbers of elements, bytes for byte regions.
+// Layouts with several pairs name it after the forward region too.
+func (g *Generator) generateFreeSpace(pair capacityPair, several bool) string {
+	name := g.analyzed.TypeName + "FreeSpace"
+	if several {
+		name = g.analyzed.TypeName + upperFirst(pair.forward.Field.Name) + "FreeSpace"
+	}
+	fwd, bwd := g.localName(pair.forward.Field.Name, ""), g.localName(pair.backward.Field.Name, "")
+	unit := func(region analyzer.Region) string {
+		if isByteRegion(region) {
+			return "bytes"
+		}
+		return "elements"
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// %s returns how many of the %d bytes shared by %s and %s\n",
+		name, pair.space, pair.forward.Field.Name, pair.backward.Field.Name))
+	code.WriteString(fmt.Sprintf("// are free, given the length of each (%s for %s, %s for %s).\n",
+		unit(pair.forward), pair.forward.Field.Name, unit(pair.backward), pair.backward.Field.Name))
+	code.WriteString("// Negative when they don't fit.\n")
+	code.WriteString(fmt.Sprintf("func %s(%s, %s int) int {\n", name, fwd, bwd))
+	code.WriteString(fmt.Sprintf("\treturn %d - %s - %s\n", pair.space, scaled(fwd, pair.forward), scaled(bwd, pair.backward)))
+	code.WriteString("}\n\n")
+	return code.String()
+}
+
+// generateMaxEntrySize generates <Type>Max<Field>Size for an indirect slice:
+// the largest entry its data region holds beside one metadata element and
+// entries of the given sizes of the other indirect slices sharing both, within
+// what the element's size field can store
+func (g *Generator) generateMaxEntrySize(field parser.Field, pairs []capacityPair) string {
+	fl := field.Layout
+	var metadata, data analyzer.Region
+	for _, region := range g.analyzed.Regions {
+		switch {
+		case region.Kind != analyzer.DynamicRegion || region.Field.Layout.Group != "":
+		case region.Field.Name == fl.From:
+			metadata = region
+		case region.Field.Name == fl.Region:
+			data = region
+		}
+	}
+	if metadata.Field.Layout == nil || data.Field.Layout == nil || metadata.ElementStride <= 0 {
+		return ""
+	}
+
+	low, high := g.regionSpan(data)
+	avail := high - low
+	for _, pair := range pairs {
+		if pair.forward.Field.Name == metadata.Field.Name && pair.backward.Field.Name == data.Field.Name ||
+			pair.backward.Field.Name == metadata.Field.Name && pair.forward.Field.Name == data.Field.Name {
+			avail = pair.space - metadata.ElementStride
+		}
+	}
+
+	var params, docs []string
+	terms := []string{fmt.Sprint(avail)}
+	for _, other := range g.layout.Fields {
+		if other.Name == field.Name || other.Layout.From != fl.From || other.Layout.Region != fl.Region {
+			continue
+		}
+		param := g.localName(other.Name, "Size")
+		params = append(params, param)
+		if isPrefix(other) {
+			docs = append(docs, fmt.Sprintf("a %s entry storing %s bytes", other.Name, param))
+		} else {
+			docs = append(docs, fmt.Sprintf("a %s entry of %s bytes", other.Name, param))
+		}
+		terms = append(terms, param)
+	}
+	expr := strings.Join(terms, " - ")
+	if limit := g.sizeFieldLimit(metadata.ElementType, fl.SizeField); limit >= 0 && limit < avail {
+		expr = fmt.Sprintf("min(%s, %d)", strings.Join(terms, "-"), limit)
+	}
+
+	name := g.analyzed.TypeName + "Max" + upperFirst(field.Name) + "Size"
+	beside := "one element"
+	if len(docs) > 0 {
+		beside += " and " + strings.Join(docs, ", ")
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// %s returns the largest %s entry a %s holds, beside\n", name, field.Name, g.analyzed.TypeName))
+	code.WriteString(fmt.Sprintf("// %s.\n", beside))
+	code.WriteString("// Negative when even an empty one doesn't fit.\n")
+	signature := strings.Join(params, ", ")
+	if signature != "" {
+		signature += " int"
+	}
+	code.WriteString(fmt.Sprintf("func %s(%s) int {\n", name, signature))
+	code.WriteString(fmt.Sprintf("\treturn %s\n", expr))
+	code.WriteString("}\n\n")
+	return code.String()
+}
+
+// sizeFieldLimit returns the largest size the size field of a metadata element
+// type can store, -1 when it isn't known
+func (g *Generator) sizeFieldLimit(elemType, sizeField string) int {
+	for _, layout := range g.allLayouts {
+		if layout.Name != elemType {
+			continue
+		}
+		for _, f := range layout.Fields {
+			if f.Name == sizeField {
+				return analyzer.MaxCountValue(g.registry.ResolveType(f.GoType))
+			}
+		}
+	}
+	return -1
+}
+
+// scaled returns the bytes n elements of a region take, n itself for bytes
+func scaled(n string, region analyzer.Region) string {
+	if stride := region.ElementStride; stride > 1 {
+		return fmt.Sprintf("%s*%d", n, stride)
+	}
+	return n
+}
@@ -0,0 +1,79 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCapacity(t *testing.T) {
+	// @layout size=8
+	// type Elem struct {
+	//     KeyOffset   uint16 `layout:"@0"`
+	//     KeySize     uint16 `layout:"@2"`
+	//     ValueOffset uint16 `layout:"@4"`
+	//     ValueSize   uint8  `layout:"@6"`
+	// }
+	//
+	// @layout size=512
+	// type Page struct {
+	//     N      uint8    `layout:"@0"`
+	//     Elems  []Elem   `layout:"@16,start-end,count=N"`
+	//     Data   []byte   `layout:"end-start"`
+	//     Keys   [][]byte `layout:"from=Elems,offset=KeyOffset,size=KeySize,region=Data"`
+	//     Values [][]byte `layout:"from=Elems,offset=ValueOffset,size=ValueSize,region=Data"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "KeyOffset", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "KeySize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "ValueOffset", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "ValueSize", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 512},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.EndStart}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, From: "Elems", OffsetField: "KeyOffset", SizeField: "KeySize", Region: "Data"}},
+			{Name: "Values", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, From: "Elems", OffsetField: "ValueOffset", SizeField: "ValueSize", Region: "Data"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	reg.RegisterFields("Elem", elem.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{elem, layout}, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"\tPageElemsCapacity = 496 // bytes\n",
+		// 496/8, below the 255 the uint8 count stores
+		"\tPageMaxElems      = 62  // elements\n",
+		"\tPageDataCapacity  = 496 // bytes\n",
+		"func PageFreeSpace(elems, data int) int {\n\treturn 496 - elems*8 - data\n}\n",
+		// One element leaves 488 bytes for the entries
+		"func PageMaxKeysSize(valuesSize int) int {\n\treturn 488 - valuesSize\n}\n",
+		// ValueSize is a uint8
+		"func PageMaxValuesSize(keysSize int) int {\n\treturn min(488-keysSize, 255)\n}\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// isCapSlice reports whether a region is an array holding a slice inline (cap=)
+func isCapSlice(region analyzer.Region) bool {
+	return region.Field.Layout != nil && region.Field.Layout.Cap > 0
+}
+
+// isCapSliceCount reports whether the named field counts the elements of an inline
+// slice
+func (g *Generator) isCapSliceCount(name string) bool {
+	for _, region := range g.analyzed.Regions {
+		if isCapSlice(region) && region.Field.Layout.Used == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generateCapSliceOp generates copy-mode marshal/unmarshal code for a cap= field:
+// the elements in use, from the first, each at its place in the array's bytes.
+// Marshal zeroes the bytes of the others and unmarshal the elements, so unused
+// elements never carry stale values. Both refuse counts past the capacity.
+func (g *Generator) generateCapSliceOp(region analyzer.Region, op string) string {
+	var code strings.Builder
+	field, used := region.Field.Name, region.Field.Layout.Used
+	n, elemType, size := g.presentSlots(region)
+	unsigned := fmt.Sprintf("uint%d", size*8)
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d), the first %s elements in use\n",
+		field, region.Field.GoType, region.Start, region.Boundary, used))
+	code.WriteString("\t{\n")
+	code.WriteString(fmt.Sprintf("\t\tn := int(p.%s)\n", used))
+	if bits := g.bitmapBits(used); bits >= 64 || 1<<bits-1 > n {
+		ret := "return "
+		if op == "marshal" {
+			ret = "return nil, "
+		}
+		code.WriteString(fmt.Sprintf("\t\tif n > %d {\n", n))
+		code.WriteString(fmt.Sprintf("\t\t\t%sfmt.Errorf(\"%s: %%d elements exceed the capacity of %s, %d: %%w\", n, ErrRegionOverflow)\n",
+			ret, used, field, n))
+		code.WriteString("\t\t}\n")
+	}
+
+	if op == "marshal" {
+		if elemType == "byte" || elemType == "uint8" {
+			code.WriteString(fmt.Sprintf("\t\tcopy(buf[%d:%d+n], p.%s[:n])\n", region.Start, region.Start, field))
+		} else {
+			code.WriteString(fmt.Sprintf("\t\tfor i, v := range p.%s[:n] {\n", field))
+			if size == 1 {
+				code.WriteString(fmt.Sprintf("\t\t\tbuf[%d+i] = byte(v)\n", region.Start))
+			} else {
+				value := "v"
+				if elemType != unsigned {
+					value = unsigned + "(v)"
+				}
+				code.WriteString(fmt.Sprintf("\t\t\toff := %d + i*%d\n", region.Start, size))
+				code.WriteString(fmt.Sprintf("\t\t\t%s.%s(buf[off:off+%d], %s)\n", g.endianPrefix(), g.binaryPutFunc(elemType), size, value))
+			}
+			code.WriteString("\t\t}\n")
+		}
+		end := "n"
+		if size > 1 {
+			end = fmt.Sprintf("n*%d", size)
+		}
+		code.WriteString(fmt.Sprintf("\t\tclear(buf[%d+%s : %d])\n", region.Start, end, region.Boundary))
+	} else {
+		code.WriteString("\t\tfor i := range n {\n")
+		load := fmt.Sprintf("buf[%d+i]", region.Start)
+		if size > 1 {
+			code.WriteString(fmt.Sprintf("\t\t\toff := %d + i*%d\n", region.Start, size))
+			load = fmt.Sprintf("%s.%s(buf[off:off+%d])", g.endianPrefix(), g.binaryGetFunc(elemType), size)
+		}
+		if elemType != unsigned && (size > 1 || elemType != "byte") {
+			load = fmt.Sprintf("%s(%s)", elemType, load)
+		}
+		code.WriteString(fmt.Sprintf("\t\t\tp.%s[i] = %s\n", field, load))
+		code.WriteString("\t\t}\n")
+		code.WriteString(fmt.Sprintf("\t\tclear(p.%s[n:])\n", field))
+	}
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// generateCapSliceAccessors generates Append<Element>, <Field>Slice and All<Field>
+// for a cap= field, keeping the count and the elements in use in step
+//
+// Items [32]uint32 → AppendItem(v uint32) error, ItemsSlice() []uint32,
+// AllItems() iter.Seq2[int, uint32]
+func (g *Generator) generateCapSliceAccessors(region analyzer.Region) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	field, used := region.Field.Name, region.Field.Layout.Used
+	n, elemType, _ := g.presentSlots(region)
+	singularName := upperFirst(strings.TrimSuffix(field, "s")) // Items -> Item
+
+	code.WriteString(fmt.Sprintf("\n// Append%s appends v to %s in place, bumping %s\n", singularName, field, used))
+	code.WriteString(fmt.Sprintf("func (p *%s) Append%s(v %s) error {\n", typeName, singularName, elemType))
+	code.WriteString(fmt.Sprintf("\tn := int(p.%s)\n", used))
+	code.WriteString(fmt.Sprintf("\tif n >= %d {\n", n))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Append%s: %s full at %%d elements: %%w\", n, ErrRegionOverflow)\n", singularName, field))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tp.%s[n] = v\n", field))
+	code.WriteString(fmt.Sprintf("\tp.%s++\n", used))
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// %sSlice returns the %s elements of %s in use, sharing the array\n", upperFirst(field), used, field))
+	code.WriteString(fmt.Sprintf("func (p *%s) %sSlice() []%s {\n", typeName, upperFirst(field), elemType))
+	code.WriteString(fmt.Sprintf("\treturn p.%s[:min(int(p.%s), %d)]\n", field, used, n))
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// All%s returns an iterator over the %s elements of %s in use\n", upperFirst(field), used, field))
+	code.WriteString(fmt.Sprintf("func (p *%s) All%s() iter.Seq2[int, %s] {\n", typeName, upperFirst(field), elemType))
+	code.WriteString(fmt.Sprintf("\treturn func(yield func(int, %s) bool) {\n", elemType))
+	code.WriteString(fmt.Sprintf("\t\tfor i, v := range p.%sSlice() {\n", upperFirst(field)))
+	code.WriteString("\t\t\tif !yield(i, v) {\n")
+	code.WriteString("\t\t\t\treturn\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
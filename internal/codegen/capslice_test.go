@@ -0,0 +1,79 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCapSlice(t *testing.T) {
+	// @layout size=64
+	// type Batch struct {
+	//     NumItems uint8     `layout:"@0"`
+	//     NumTags  uint8     `layout:"@1.0,bits=2"`
+	//     Items    [8]int32  `layout:"@4,cap=8,count=NumItems"`
+	//     Tags     [3]byte   `layout:"@36,cap=3,count=NumTags"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Batch",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "NumItems", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "NumTags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed, Bits: 2}},
+			{Name: "Items", GoType: "[8]int32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Cap: 8, Used: "NumItems"}},
+			{Name: "Tags", GoType: "[3]byte", Layout: &parser.FieldLayout{Offset: 36, Direction: parser.Fixed, Cap: 3, Used: "NumTags"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal writes the elements in use and zeroes the rest
+		"\t\tn := int(p.NumItems)\n\t\tif n > 8 {\n" +
+			"\t\t\treturn nil, fmt.Errorf(\"NumItems: %d elements exceed the capacity of Items, 8: %w\", n, ErrRegionOverflow)\n",
+		"\t\tfor i, v := range p.Items[:n] {\n\t\t\toff := 4 + i*4\n\t\t\tbinary.LittleEndian.PutUint32(buf[off:off+4], uint32(v))\n\t\t}\n" +
+			"\t\tclear(buf[4+n*4 : 36])\n",
+		"\t\tcopy(buf[36:36+n], p.Tags[:n])\n\t\tclear(buf[36+n : 39])\n",
+		// Unmarshal decodes them and zeroes the others
+		"\t\t\treturn fmt.Errorf(\"NumItems: %d elements exceed the capacity of Items, 8: %w\", n, ErrRegionOverflow)\n",
+		"\t\tfor i := range n {\n\t\t\toff := 4 + i*4\n\t\t\tp.Items[i] = int32(binary.LittleEndian.Uint32(buf[off:off+4]))\n\t\t}\n\t\tclear(p.Items[n:])\n",
+		"\t\t\tp.Tags[i] = buf[36+i]\n",
+		// Helpers bounded by the capacity
+		"func (p *Batch) AppendItem(v int32) error {\n\tn := int(p.NumItems)\n\tif n >= 8 {\n",
+		"\tp.Items[n] = v\n\tp.NumItems++\n\treturn nil\n}\n",
+		"func (p *Batch) ItemsSlice() []int32 {\n\treturn p.Items[:min(int(p.NumItems), 8)]\n}\n",
+		"func (p *Batch) AllItems() iter.Seq2[int, int32] {\n",
+		"func (p *Batch) AppendTag(v byte) error {\n",
+		// ConvertEndian swaps element by element
+		"\tdst[32], dst[33], dst[34], dst[35] = dst[35], dst[34], dst[33], dst[32] // Items[7]\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	// A 2-bit count can't exceed the capacity of Tags
+	if strings.Contains(code, "capacity of Tags") {
+		t.Errorf("Generated code checks a count that can't exceed the capacity\n\nGenerated:\n%s", code)
+	}
+	for _, patch := range []string{"func PatchBatchItems", "func PatchBatchNumItems", "func PatchBatchTags"} {
+		if strings.Contains(code, patch) {
+			t.Errorf("Generated code contains %q", patch)
+		}
+	}
+	if imports := gen.Imports(); !slices.Contains(imports, "iter") || !slices.Contains(imports, "encoding/binary") {
+		t.Errorf("Imports() = %v, want iter and encoding/binary", imports)
+	}
+}
@@ -0,0 +1,116 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// chainCapacity returns the most bytes of the chained field one page holds: its
+// region, lowered by max= and by what its count field can hold
+func (g *Generator) chainCapacity(region analyzer.Region) int {
+	countField := region.Field.Layout.CountField
+	capacity, _ := g.countCapacity(countField)
+	if limit := analyzer.MaxCountValue(g.registry.ResolveType(g.countFieldType(countField))); limit >= 0 {
+		capacity = min(capacity, limit)
+	}
+	return capacity
+}
+
+// generateChain generates ChainLen, MarshalChain and UnmarshalChain for a next=
+// layout, whose dynamic field runs on across continuation pages linked by the
+// next= field. Every page is a complete encoding of the type, holding the next
+// piece of the field; 0 in the next= field ends the chain.
+func (g *Generator) generateChain() string {
+	next := g.layout.Anno.Next
+	if next == "" {
+		return ""
+	}
+
+	var region, nextRegion analyzer.Region
+	for _, r := range g.analyzed.Regions {
+		if r.Kind == analyzer.DynamicRegion {
+			region = r
+		} else if r.Field.Name == next {
+			nextRegion = r
+		}
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	field, countField := region.Field.Name, region.Field.Layout.CountField
+	capacity := g.chainCapacity(region)
+	nextType := nextRegion.Field.GoType
+
+	code.WriteString(fmt.Sprintf("\n// ChainLen returns the number of pages MarshalChain spreads %s over, %d bytes\n", field, capacity))
+	code.WriteString("// to a page\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) ChainLen() int {\n", typeName))
+	code.WriteString(fmt.Sprintf("\treturn max(1, (len(p.%s)+%d)/%d)\n", field, capacity-1, capacity))
+	code.WriteString("}\n\n")
+
+	code.WriteString("// MarshalChain encodes p as ChainLen pages, page i to be written as page\n")
+	code.WriteString(fmt.Sprintf("// pageIDs[i]. Each holds the next piece of %s and, in %s, the ID of the\n", field, next))
+	code.WriteString("// page after it, 0 on the last. The other fields are repeated on every page.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) MarshalChain(pageIDs []uint64) ([][]byte, error) {\n", typeName))
+	code.WriteString("\tif n := p.ChainLen(); len(pageIDs) != n {\n")
+	code.WriteString("\t\treturn nil, fmt.Errorf(\"MarshalChain: %d page IDs for %d pages: %w\", len(pageIDs), n, ErrCountMismatch)\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tdata, count, next := p.%s, p.%s, p.%s\n", field, countField, next))
+	code.WriteString("\tdefer func() {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s, p.%s, p.%s = data, count, next\n", field, countField, next))
+	code.WriteString("\t}()\n\n")
+	code.WriteString("\tpages := make([][]byte, len(pageIDs))\n")
+	code.WriteString("\tfor i := range pages {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = data[min(i*%d, len(data)):min((i+1)*%d, len(data))]\n", field, capacity, capacity))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = %s(len(p.%s))\n", countField, g.countFieldType(countField), field))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = 0\n", next))
+	code.WriteString("\t\tif i+1 < len(pageIDs) {\n")
+	code.WriteString("\t\t\tid := pageIDs[i+1]\n")
+	if size := nextRegion.Boundary - nextRegion.Start; size < 8 {
+		code.WriteString(fmt.Sprintf("\t\t\tif id == 0 || id > %#x {\n", uint64(1)<<(size*8)-1))
+	} else {
+		code.WriteString("\t\t\tif id == 0 {\n")
+	}
+	code.WriteString(fmt.Sprintf("\t\t\t\treturn nil, fmt.Errorf(\"MarshalChain: page ID %%d doesn't fit %s: %%w\", id, ErrOutOfRange)\n", next))
+	code.WriteString("\t\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\t\tp.%s = %s(id)\n", next, nextType))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tbuf, err := p.MarshalLayout()\n")
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString("\t\t\treturn nil, fmt.Errorf(\"MarshalChain: page %d: %w\", i, err)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tpages[i] = buf\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn pages, nil\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// UnmarshalChain decodes the first page of a chain into p, then appends the %s\n", field))
+	code.WriteString(fmt.Sprintf("// of each page %s links to, read through fetch, to p.%s. A nil or empty\n", next, field))
+	code.WriteString("// page fails with ErrShortBuffer, and a link back into the chain with ErrBadChain.\n")
+	code.WriteString(fmt.Sprintf("// p.%s keeps the count of the first page.\n", countField))
+	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalChain(first []byte, fetch func(pageID uint64) []byte) error {\n", typeName))
+	code.WriteString("\tif err := p.UnmarshalLayout(first); err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n\n")
+	code.WriteString(fmt.Sprintf("\tvar page %s\n", typeName))
+	code.WriteString("\tseen := make(map[uint64]bool)\n")
+	code.WriteString(fmt.Sprintf("\tfor id := uint64(p.%s); id != 0; id = uint64(page.%s) {\n", next, next))
+	code.WriteString("\t\tif seen[id] {\n")
+	code.WriteString("\t\t\treturn fmt.Errorf(\"UnmarshalChain: page %d links back into the chain: %w\", id, ErrBadChain)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tseen[id] = true\n\n")
+	code.WriteString("\t\tbuf := fetch(id)\n")
+	code.WriteString("\t\tif len(buf) == 0 {\n")
+	code.WriteString("\t\t\treturn fmt.Errorf(\"UnmarshalChain: page %d: %w\", id, ErrShortBuffer)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tif err := page.UnmarshalLayout(buf); err != nil {\n")
+	code.WriteString("\t\t\treturn fmt.Errorf(\"UnmarshalChain: page %d: %w\", id, err)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = append(p.%s, page.%s...)\n", field, field, field))
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
@@ -0,0 +1,69 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateChain(t *testing.T) {
+	// @layout size=64 next=Next
+	// type Blob struct {
+	//     Len  uint16 `layout:"@0"`
+	//     Next uint32 `layout:"@4"`
+	//     Data []byte `layout:"@8,start-end,count=Len"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Blob",
+		Anno: &parser.TypeAnnotation{Size: 64, Next: "Next"},
+		Fields: []parser.Field{
+			{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Next", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "Len"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Blob) ChainLen() int {\n\treturn max(1, (len(p.Data)+55)/56)\n}\n",
+		"func (p *Blob) MarshalChain(pageIDs []uint64) ([][]byte, error) {\n",
+		"\tdata, count, next := p.Data, p.Len, p.Next\n",
+		"\t\tp.Data = data[min(i*56, len(data)):min((i+1)*56, len(data))]\n\t\tp.Len = uint16(len(p.Data))\n\t\tp.Next = 0\n",
+		"\t\t\tif id == 0 || id > 0xffffffff {\n",
+		"\t\t\tp.Next = uint32(id)\n",
+		"func (p *Blob) UnmarshalChain(first []byte, fetch func(pageID uint64) []byte) error {\n",
+		"\tfor id := uint64(p.Next); id != 0; id = uint64(page.Next) {\n",
+		"\t\t\treturn fmt.Errorf(\"UnmarshalChain: page %d links back into the chain: %w\", id, ErrBadChain)\n",
+		"\t\tp.Data = append(p.Data, page.Data...)\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Without next= there is no chain
+	layout.Anno.Next = ""
+	analyzed, err = analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if strings.Contains(code, "Chain") {
+		t.Error("Generated chain methods without next=")
+	}
+}
@@ -0,0 +1,153 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// CorpusTestPattern names the temporary test file `layout test` writes next to the
+// generated code, runs and removes, for os.CreateTemp: the * becomes a random
+// string, so no file of the package is overwritten
+const CorpusTestPattern = "layout_corpus_*_test.go"
+
+// GenerateCorpusTest generates a test file for package pkg that replays every file
+// in corpusDir through UnmarshalLayout and MarshalLayout of the type. Files that
+// decode must re-encode to the same bytes; mismatches are reported as byte-level
+// diffs naming the field of each differing run. Files refused with an error pass,
+// since that is how corrupt input should be handled. Panics fail the file.
+func (g *Generator) GenerateCorpusTest(pkg, corpusDir string) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString("// Code generated by layout test. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import (\n")
+	code.WriteString("\t\"bytes\"\n")
+	code.WriteString("\t\"fmt\"\n")
+	code.WriteString("\t\"os\"\n")
+	code.WriteString("\t\"path/filepath\"\n")
+	code.WriteString("\t\"strings\"\n")
+	code.WriteString("\t\"testing\"\n")
+	code.WriteString(")\n\n")
+
+	code.WriteString(fmt.Sprintf("func TestLayoutCorpus%s(t *testing.T) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tfiles, err := filepath.Glob(filepath.Join(%q, \"*\"))\n", corpusDir))
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\tt.Fatal(err)\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\tif len(files) == 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\tt.Fatalf(\"no corpus files in %%s\", %q)\n", corpusDir))
+	code.WriteString("\t}\n")
+	code.WriteString("\tfor _, file := range files {\n")
+	code.WriteString("\t\tt.Run(filepath.Base(file), func(t *testing.T) {\n")
+	code.WriteString("\t\t\tdata, err := os.ReadFile(file)\n")
+	code.WriteString("\t\t\tif err != nil {\n")
+	code.WriteString("\t\t\t\tt.Skip(err) // Subdirectories and unreadable files\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tdefer func() {\n")
+	code.WriteString("\t\t\t\tif r := recover(); r != nil {\n")
+	code.WriteString("\t\t\t\t\tt.Errorf(\"panic: %v\", r)\n")
+	code.WriteString("\t\t\t\t}\n")
+	code.WriteString("\t\t\t}()\n\n")
+	code.WriteString(fmt.Sprintf("\t\t\tp := %s\n", g.emptyValue()))
+	code.WriteString("\t\t\tif err := p.UnmarshalLayout(data); err != nil {\n")
+	code.WriteString("\t\t\t\tt.Logf(\"rejected: %v\", err)\n")
+	code.WriteString("\t\t\t\treturn\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tout, err := p.MarshalLayout()\n")
+	code.WriteString("\t\t\tif err != nil {\n")
+	code.WriteString("\t\t\t\tt.Errorf(\"decoded but does not re-encode: %v\", err)\n")
+	code.WriteString("\t\t\t\treturn\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tif !bytes.Equal(out, data) {\n")
+	code.WriteString("\t\t\t\tt.Errorf(\"round trip mismatch:\\n%s\", layoutCorpusDiff(data, out))\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t})\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(g.generateCorpusFields())
+	code.WriteString(generateCorpusDiff())
+
+	return code.String()
+}
+
+// emptyValue returns the expression for a new, empty value of the type, which
+// generated tests decode into or marshal. Slice-backed zerocopy types (align= or
+// allocator=) need New for their buffer.
+func (g *Generator) emptyValue() string {
+	if g.mode == "zerocopy" && (g.align > 0 || g.allocator != "") {
+		return fmt.Sprintf("New%s()", g.analyzed.TypeName)
+	}
+	return fmt.Sprintf("&%s{}", g.analyzed.TypeName)
+}
+
+// generateCorpusFields generates the table naming the byte ranges of the layout's
+// fields, used to label diffs. Dynamic regions span their boundaries.
+func (g *Generator) generateCorpusFields() string {
+	var code strings.Builder
+
+	code.WriteString("// layoutCorpusFields are the byte ranges of the fields, in offset order\n")
+	code.WriteString("var layoutCorpusFields = []struct {\n")
+	code.WriteString("\tstart, end int\n")
+	code.WriteString("\tname       string\n")
+	code.WriteString("}{\n")
+	for _, region := range g.analyzed.Regions {
+		start, end := region.Start, region.Boundary
+		if region.Kind == analyzer.DynamicRegion && region.Direction == parser.EndStart {
+			start, end = region.Boundary, region.Start
+		}
+		code.WriteString(fmt.Sprintf("\t{%d, %d, %q},\n", start, end, region.Field.Name))
+	}
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
+
+// generateCorpusDiff generates layoutCorpusDiff, which lists the runs of differing
+// bytes between two encodings with the fields they fall in
+func generateCorpusDiff() string {
+	var code strings.Builder
+
+	code.WriteString("// layoutCorpusDiff describes where got differs from want, one run of differing\n")
+	code.WriteString("// bytes per line\n")
+	code.WriteString("func layoutCorpusDiff(want, got []byte) string {\n")
+	code.WriteString("\tvar diff strings.Builder\n")
+	code.WriteString("\tif len(want) != len(got) {\n")
+	code.WriteString("\t\tfmt.Fprintf(&diff, \"  length: want %d, got %d\\n\", len(want), len(got))\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\truns := 0\n")
+	code.WriteString("\tfor i := 0; i < min(len(want), len(got)); i++ {\n")
+	code.WriteString("\t\tif want[i] == got[i] {\n")
+	code.WriteString("\t\t\tcontinue\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tj := i\n")
+	code.WriteString("\t\tfor j < min(len(want), len(got)) && want[j] != got[j] {\n")
+	code.WriteString("\t\t\tj++\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tif runs++; runs > 32 {\n")
+	code.WriteString("\t\t\tdiff.WriteString(\"  ...\\n\")\n")
+	code.WriteString("\t\t\tbreak\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tfield := \"(gap)\"\n")
+	code.WriteString("\t\tfor _, f := range layoutCorpusFields {\n")
+	code.WriteString("\t\t\tif i >= f.start && i < f.end {\n")
+	code.WriteString("\t\t\t\tfield = f.name\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tshown := min(j, i+16)\n")
+	code.WriteString("\t\tmore := \"\"\n")
+	code.WriteString("\t\tif shown < j {\n")
+	code.WriteString("\t\t\tmore = \" ...\"\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tfmt.Fprintf(&diff, \"  [%d, %d) %s: want % x%s, got % x%s\\n\", i, j, field, want[i:shown], more, got[i:shown], more)\n")
+	code.WriteString("\t\ti = j\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn diff.String()\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
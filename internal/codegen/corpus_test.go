@@ -0,0 +1,69 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCorpusTest(t *testing.T) {
+	// @layout size=64
+	// type Page struct {
+	//     N    uint16 `layout:"@0"`
+	//     Body []byte `layout:"start-end,count=N"`
+	//     Tail []byte `layout:"@56,end-start"`
+	//     CRC  uint32 `layout:"@60"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 56, Direction: parser.EndStart}},
+			{Name: "CRC", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 60, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code := gen.GenerateCorpusTest("pages", "/data/corpus")
+
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n\nGenerated:\n%s", err, code)
+	}
+
+	expectedParts := []string{
+		"package pages\n",
+		"func TestLayoutCorpusPage(t *testing.T) {",
+		`files, err := filepath.Glob(filepath.Join("/data/corpus", "*"))`,
+		"p := &Page{}",
+		"if err := p.UnmarshalLayout(data); err != nil {\n\t\t\t\tt.Logf(\"rejected: %v\", err)",
+		"t.Errorf(\"round trip mismatch:\\n%s\", layoutCorpusDiff(data, out))",
+		// Field table, with the backward region spanning [boundary, start)
+		"\t{0, 2, \"N\"},\n",
+		"\t{2, 56, \"Body\"},\n",
+		"\t{2, 56, \"Tail\"},\n",
+		"\t{60, 64, \"CRC\"},\n",
+		"func layoutCorpusDiff(want, got []byte) string {",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Slice-backed zerocopy buffers come from New
+	gen = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 64, "")
+	if code := gen.GenerateCorpusTest("pages", "/data/corpus"); !strings.Contains(code, "p := NewPage()") {
+		t.Errorf("Zerocopy corpus test should decode into NewPage()\n\nGenerated:\n%s", code)
+	}
+}
@@ -0,0 +1,269 @@
+package codegen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// CorruptVariant is a corrupted copy of a valid encoded sample
+type CorruptVariant struct {
+	Name    string // File name, e.g. "truncate-16" or "count-N-bit3"
+	Data    []byte
+	WantErr bool // UnmarshalLayout must refuse it, not only survive it
+}
+
+// CorruptVariants returns systematically corrupted copies of sample, a valid
+// encoding of the type, one per validation path: truncated at every field
+// boundary, every bit of each count field flipped, counts set past their
+// region's capacity, and the offsets and sizes of the first element of each
+// indirect slice's metadata pointed out of the buffer. Variants that no valid
+// decoding accepts want an error; the others, like bit flips that may land on
+// a count still in range, only have to decode without panicking.
+func (g *Generator) CorruptVariants(sample []byte) ([]CorruptVariant, error) {
+	if g.mode != "stream" && len(sample) < g.analyzed.BufferSize {
+		return nil, fmt.Errorf("sample of %d bytes is shorter than %s's %d", len(sample), g.analyzed.TypeName, g.analyzed.BufferSize)
+	}
+
+	var variants []CorruptVariant
+	corrupt := func(name string, wantErr bool, change func(data []byte)) {
+		data := slices.Clone(sample)
+		change(data)
+		variants = append(variants, CorruptVariant{name, data, wantErr})
+	}
+
+	// Truncated at the start and end of every field, and one byte short
+	cuts := []int{0, 1, len(sample) - 1}
+	for _, region := range g.analyzed.Regions {
+		low, high := region.Start, region.Boundary
+		if region.Kind == analyzer.DynamicRegion {
+			low, high = g.regionSpan(region)
+		}
+		cuts = append(cuts, low, high)
+	}
+	slices.Sort(cuts)
+	for _, n := range slices.Compact(cuts) {
+		if n >= 0 && n < len(sample) {
+			variants = append(variants, CorruptVariant{fmt.Sprintf("truncate-%d", n), slices.Clone(sample[:n]), true})
+		}
+	}
+
+	// Count fields: each bit flipped, then all ones and one past capacity
+	seen := make(map[string]bool)
+	for _, region := range g.analyzed.Regions {
+		countField := region.Field.Layout.CountField
+		if region.Kind != analyzer.DynamicRegion || seen[countField] {
+			continue
+		}
+		seen[countField] = true
+		span, ok := g.countSpan(countField)
+		if !ok || span.start+span.size > len(sample) {
+			continue
+		}
+		name := strings.ReplaceAll(countField, ".", "")
+		for bit := range span.size * 8 {
+			corrupt(fmt.Sprintf("count-%s-bit%d", name, bit), false, func(data []byte) {
+				data[span.start+bit/8] ^= 1 << (bit % 8)
+			})
+		}
+		if strings.HasPrefix(span.goType, "int") {
+			continue // All ones is -1, which decoding may take for none
+		}
+		capacity, ok := g.countCapacity(countField)
+		if !ok {
+			continue
+		}
+		most := uint64(1)<<(span.size*8) - 1
+		corrupt(fmt.Sprintf("count-%s-max", name), most > uint64(capacity), func(data []byte) {
+			fillOnes(data[span.start : span.start+span.size])
+		})
+		if uint64(capacity) < most {
+			corrupt(fmt.Sprintf("count-%s-over", name), true, func(data []byte) {
+				putUint(data[span.start:span.start+span.size], uint64(capacity)+1, span.byteOrder())
+			})
+		}
+	}
+
+	// Indirect slices: the first metadata element's offset and size out of range
+	for _, field := range g.layout.Fields {
+		if field.Layout.From == "" {
+			continue
+		}
+		for _, target := range []string{field.Layout.OffsetField, field.Layout.SizeField} {
+			at, size, ok := g.metadataField(field.Layout.From, target, sample)
+			if !ok {
+				continue
+			}
+			most := uint64(1)<<(min(size, 8)*8) - 1
+			corrupt(fmt.Sprintf("%s-%s", field.Name, target), most >= uint64(g.analyzed.BufferSize), func(data []byte) {
+				fillOnes(data[at : at+size])
+			})
+		}
+	}
+
+	return variants, nil
+}
+
+// metadataField locates field of the first element of the metadata slice
+// named from in sample: not ok when the sample holds none, or the element's
+// place doesn't follow from the layout alone
+func (g *Generator) metadataField(from, field string, sample []byte) (int, int, bool) {
+	var metadata analyzer.Region
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.DynamicRegion && region.Field.Name == from {
+			metadata = region
+		}
+	}
+	fl := metadata.Field.Layout
+	if fl == nil || fl.Group != "" || len(metadata.Members) > 0 || metadata.ElementStride == 0 || g.isFramed(metadata) {
+		return 0, 0, false
+	}
+	count := uint64(1) // A sentinel-ended slice has an element, if only the sentinel
+	if !fl.Sentinel {
+		span, ok := g.countSpan(fl.CountField)
+		if !ok || span.start+span.size > len(sample) {
+			return 0, 0, false
+		}
+		count = readUint(sample[span.start:span.start+span.size], span.byteOrder())
+	}
+	if count == 0 || (fl.Sentinel && metadata.Direction == parser.EndStart) {
+		return 0, 0, false
+	}
+
+	var elem parser.Field
+	for _, layout := range g.allLayouts {
+		if layout.Name != metadata.ElementType {
+			continue
+		}
+		for _, f := range layout.Fields {
+			if f.Name == field && f.Layout != nil && f.Layout.Direction == parser.Fixed && f.Layout.Bits == 0 {
+				elem = f
+			}
+		}
+	}
+	if elem.Layout == nil {
+		return 0, 0, false
+	}
+	size, err := analyzer.SizeOf(g.registry.ResolveType(elem.GoType))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	at := metadata.Start + elem.Layout.Offset
+	if metadata.Direction == parser.EndStart {
+		at -= int(count) * metadata.ElementStride // Backward regions end with the last element
+	}
+	if at < 0 || at+size > len(sample) {
+		return 0, 0, false
+	}
+	return at, size, true
+}
+
+// byteOrder returns the byte order the count field is encoded in
+func (c countBytes) byteOrder() binary.ByteOrder {
+	if c.mode == "zerocopy" {
+		return binary.NativeEndian
+	}
+	return byteOrderOf(c.endian).order
+}
+
+// putUint encodes v into b, as wide as b is
+func putUint(b []byte, v uint64, order binary.ByteOrder) {
+	switch len(b) {
+	case 1:
+		b[0] = byte(v)
+	case 2:
+		order.PutUint16(b, uint16(v))
+	case 4:
+		order.PutUint32(b, uint32(v))
+	default:
+		order.PutUint64(b, v)
+	}
+}
+
+// readUint decodes the unsigned integer b holds, as wide as b is
+func readUint(b []byte, order binary.ByteOrder) uint64 {
+	switch len(b) {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(order.Uint16(b))
+	case 4:
+		return uint64(order.Uint32(b))
+	}
+	return order.Uint64(b)
+}
+
+// fillOnes sets every bit of b
+func fillOnes(b []byte) {
+	for i := range b {
+		b[i] = 0xff
+	}
+}
+
+// GenerateCorruptTest generates a test file for package pkg decoding the
+// variants written to dir: the sample must decode, variants wanting an error
+// must be refused, and no variant may panic or decode into a value that doesn't
+// re-encode
+func (g *Generator) GenerateCorruptTest(pkg, dir string, variants []CorruptVariant) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString("// Code generated by layout corrupt. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import (\n")
+	code.WriteString("\t\"os\"\n")
+	code.WriteString("\t\"path/filepath\"\n")
+	code.WriteString("\t\"testing\"\n")
+	code.WriteString(")\n\n")
+
+	code.WriteString(fmt.Sprintf("// layoutCorrupt%s lists the corrupted samples in %s and whether\n", typeName, dir))
+	code.WriteString("// decoding must refuse them\n")
+	code.WriteString(fmt.Sprintf("var layoutCorrupt%s = []struct {\n", typeName))
+	code.WriteString("\tname    string\n")
+	code.WriteString("\twantErr bool\n")
+	code.WriteString("}{\n")
+	for _, v := range variants {
+		code.WriteString(fmt.Sprintf("\t{%q, %t},\n", v.Name, v.WantErr))
+	}
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("func TestLayoutCorrupt%s(t *testing.T) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tsample, err := os.ReadFile(filepath.Join(%q, \"sample\"))\n", dir))
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\tt.Fatal(err)\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tif err := (%s).UnmarshalLayout(sample); err != nil {\n", g.emptyValue()))
+	code.WriteString("\t\tt.Fatalf(\"sample does not decode: %v\", err)\n")
+	code.WriteString("\t}\n\n")
+	code.WriteString(fmt.Sprintf("\tfor _, tc := range layoutCorrupt%s {\n", typeName))
+	code.WriteString("\t\tt.Run(tc.name, func(t *testing.T) {\n")
+	code.WriteString(fmt.Sprintf("\t\t\tdata, err := os.ReadFile(filepath.Join(%q, tc.name))\n", dir))
+	code.WriteString("\t\t\tif err != nil {\n")
+	code.WriteString("\t\t\t\tt.Fatal(err)\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tdefer func() {\n")
+	code.WriteString("\t\t\t\tif r := recover(); r != nil {\n")
+	code.WriteString("\t\t\t\t\tt.Errorf(\"panic: %v\", r)\n")
+	code.WriteString("\t\t\t\t}\n")
+	code.WriteString("\t\t\t}()\n\n")
+	code.WriteString(fmt.Sprintf("\t\t\tp := %s\n", g.emptyValue()))
+	code.WriteString("\t\t\tif err := p.UnmarshalLayout(data); err != nil {\n")
+	code.WriteString("\t\t\t\treturn\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tif tc.wantErr {\n")
+	code.WriteString("\t\t\t\tt.Fatalf(\"decoded a corrupt buffer, want an error\")\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tif _, err := p.MarshalLayout(); err != nil {\n")
+	code.WriteString("\t\t\t\tt.Errorf(\"decoded but does not re-encode: %v\", err)\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t})\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
@@ -0,0 +1,93 @@
+package codegen
+
+import (
+	"bytes"
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestCorruptVariants(t *testing.T) {
+	// @layout size=16
+	// type Page struct {
+	//     N    uint16 `layout:"@0"`
+	//     Body []byte `layout:"@4,start-end,count=N"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 4, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+
+	sample := []byte{2, 0, 0, 0, 'h', 'i', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	variants, err := gen.CorruptVariants(sample)
+	if err != nil {
+		t.Fatalf("CorruptVariants() error: %v", err)
+	}
+	got := make(map[string]CorruptVariant)
+	for _, v := range variants {
+		got[v.Name] = v
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"truncate-0", []byte{}, true},
+		{"truncate-2", sample[:2], true},
+		{"truncate-15", sample[:15], true},
+		{"count-N-bit1", []byte{0, 0, 0, 0, 'h', 'i', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, false},
+		{"count-N-bit8", []byte{2, 1, 0, 0, 'h', 'i', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, false},
+		{"count-N-max", []byte{0xff, 0xff, 0, 0, 'h', 'i', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, true},
+		// One past the 12 bytes Body holds, little endian
+		{"count-N-over", []byte{13, 0, 0, 0, 'h', 'i', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, true},
+	}
+	for _, tt := range tests {
+		v, ok := got[tt.name]
+		if !ok {
+			t.Errorf("no variant %s among %d", tt.name, len(variants))
+			continue
+		}
+		if !bytes.Equal(v.Data, tt.data) || v.WantErr != tt.wantErr {
+			t.Errorf("%s = % x, want error %v; want % x, %v", tt.name, v.Data, v.WantErr, tt.data, tt.wantErr)
+		}
+	}
+	if sample[0] != 2 {
+		t.Errorf("CorruptVariants() changed the sample")
+	}
+
+	if _, err := gen.CorruptVariants(sample[:8]); err == nil {
+		t.Errorf("CorruptVariants() of a short sample succeeded, want an error")
+	}
+
+	code := gen.GenerateCorruptTest("pages", "testdata/corrupt/Page", variants)
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n\nGenerated:\n%s", err, code)
+	}
+	expectedParts := []string{
+		"package pages\n",
+		"\t{\"count-N-over\", true},\n",
+		"func TestLayoutCorruptPage(t *testing.T) {",
+		"\tif err := (&Page{}).UnmarshalLayout(sample); err != nil {\n",
+		"filepath.Join(\"testdata/corrupt/Page\", tc.name)",
+		"\t\t\tif tc.wantErr {\n\t\t\t\tt.Fatalf(\"decoded a corrupt buffer, want an error\")\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// countCapacity returns the maximum element count the field may hold when it is the
+// count field of one or more dynamic regions, lowered by their max=. With several
+// governed regions the smallest capacity wins. Nested count fields (e.g.,
+// "Header.NumKeys") live in another type and are not range-checked here.
+func (g *Generator) countCapacity(countField string) (int, bool) {
+	capacity := -1
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.DynamicRegion || region.Field.Layout.CountField != countField {
+			continue
+		}
+
+		boundary := region.Boundary
+		if boundary < 0 {
+			boundary = g.analyzed.BufferSize
+		}
+		span := boundary - region.Start
+		if span < 0 {
+			span = -span // Backward regions grow from Start down to Boundary
+		}
+
+		stride := region.ElementStride
+		if stride <= 0 {
+			stride = 1
+		}
+		n := span / stride
+		if max := region.Field.Layout.MaxCount; max > 0 && max < n {
+			n = max
+		}
+		if capacity < 0 || n < capacity {
+			capacity = n
+		}
+	}
+
+	return capacity, capacity >= 0
+}
+
+// generateCountSetter generates a range-checked setter for a count field governing
+// one or more regions. Counts beyond the region capacity are refused instead of
+// being written, so a miscounted write can't produce a page that decodes past its
+// region boundary.
+//
+// Copy mode sets the struct field; zerocopy mode writes through to p.buf.
+func (g *Generator) generateCountSetter(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	capacity, _ := g.countCapacity(field.Name)
+	resolvedType := g.registry.ResolveType(field.GoType)
+	if region.Bits > 0 && int(bitMask(region.Bits)) < capacity {
+		capacity = int(bitMask(region.Bits)) // Narrower than the region
+	}
+
+	code.WriteString(fmt.Sprintf("// Set%s sets %s, refusing counts beyond the region capacity of %d\n",
+		upperFirst(field.Name), field.Name, capacity))
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) error {\n", g.analyzed.TypeName, upperFirst(field.Name), field.GoType))
+	if strings.HasPrefix(resolvedType, "int") {
+		code.WriteString(fmt.Sprintf("\tif v < 0 || int(v) > %d {\n", capacity))
+	} else {
+		code.WriteString(fmt.Sprintf("\tif int(v) > %d {\n", capacity))
+	}
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"Set%s: %%d exceeds capacity %d: %%w\", v, ErrRegionOverflow)\n", upperFirst(field.Name), capacity))
+	code.WriteString("\t}\n")
+
+	if g.mode != "zerocopy" {
+		code.WriteString(fmt.Sprintf("\tp.%s = v\n", field.Name))
+		code.WriteString("\treturn nil\n")
+		code.WriteString("}\n")
+		return code.String()
+	}
+
+	switch {
+	case region.Bits > 0:
+		code.WriteString(g.bitStore(region, "p.buf", "v", "\t"))
+	case region.Misaligned:
+		code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], %s)\n", region.Start, region.Boundary,
+			bytesOf("&v", region.Boundary-region.Start)))
+	case resolvedType == "uint8", resolvedType == "byte":
+		code.WriteString(fmt.Sprintf("\tp.buf[%d] = v\n", region.Start))
+	case resolvedType == "int8":
+		code.WriteString(fmt.Sprintf("\tp.buf[%d] = byte(v)\n", region.Start))
+	default:
+		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, region.Start))
+	}
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
+
+// countLimit returns the largest value a count field can store: the maximum of its
+// integer type, or of its width for bit fields. -1 when the type is unknown.
+func (g *Generator) countLimit(countField string) int {
+	limit := analyzer.MaxCountValue(g.registry.ResolveType(g.countFieldType(countField)))
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.FixedRegion && region.Field.Name == countField && region.Bits > 0 && region.Bits < 31 {
+			limit = min(limit, int(bitMask(region.Bits)))
+		}
+	}
+	return limit
+}
+
+// overflowGuard returns a check refusing value, an int expression, when it exceeds
+// what a field of goType can store, before it is narrowed into target. The analyzer
+// can't bound counts and offsets held in other types, so the check is made at
+// runtime. Empty when no value within the buffer can overflow the type.
+func (g *Generator) overflowGuard(value, goType, target, results, indent string) string {
+	limit := analyzer.MaxCountValue(g.registry.ResolveType(goType))
+	if limit < 0 || limit >= g.analyzed.BufferSize {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("%sif %s > %d {\n", indent, value, limit))
+	code.WriteString(fmt.Sprintf("%s\treturn %sfmt.Errorf(\"%%d overflows %s (%s): %%w\", %s, ErrOutOfRange)\n", indent, results, target, goType, value))
+	code.WriteString(fmt.Sprintf("%s}\n", indent))
+	return code.String()
+}
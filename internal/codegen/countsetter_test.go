@@ -0,0 +1,186 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCountSetter(t *testing.T) {
+	// @layout
+	// type Elem struct {
+	//     Key uint32 `layout:"@0"`
+	// }
+	//
+	// @layout size=64 mode=zerocopy
+	// type Page struct {
+	//     buf      [64]byte
+	//     NumElems uint16 `layout:"@0"`
+	//     Flags    uint16 `layout:"@2"`
+	//     Elements []Elem `layout:"@4,start-end,count=NumElems"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 4},
+		Fields: []parser.Field{
+			{Name: "Key", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	page := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "NumElems", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Flags", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Elements", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 4, CountField: "NumElems",
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 4)
+	reg.Register("Page", 64)
+
+	analyzed, err := analyzer.Analyze(page, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	all := []*parser.TypeLayout{elem, page}
+	for _, mode := range []string{"copy", "zerocopy"} {
+		page.Anno.Mode = mode
+		gen := NewGenerator(analyzed, page, all, reg, "little", mode, 0, "")
+		code, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("%s: Generate() error: %v", mode, err)
+		}
+
+		// (64-4)/4 = 15 elements fit in the region
+		expectedParts := []string{
+			"func (p *Page) SetNumElems(v uint16) error {",
+			"if int(v) > 15 {",
+			`return fmt.Errorf("SetNumElems: %d exceeds capacity 15: %w", v, ErrRegionOverflow)`,
+		}
+		for _, expected := range expectedParts {
+			if !strings.Contains(code, expected) {
+				t.Errorf("%s: generated code missing: %q", mode, expected)
+			}
+		}
+
+		// Fields not governing a region keep the plain setter
+		if strings.Contains(code, "SetFlags(v uint16) error") {
+			t.Errorf("%s: range-checked setter generated for non-count field", mode)
+		}
+	}
+}
+
+func TestGenerateCountOverflowGuards(t *testing.T) {
+	// @layout size=4
+	// type Meta struct {
+	//     KeyOff  uint8  `layout:"@0"`
+	//     KeySize uint8  `layout:"@1"`
+	//     Pad     uint16 `layout:"@2"`
+	// }
+	//
+	// @layout size=512
+	// type Leaf struct {
+	//     N     uint16   `layout:"@0"`
+	//     Elems []Meta   `layout:"start-end,count=N"`
+	//     Data  []byte   `layout:"end-start"`
+	//     Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data"`
+	// }
+	meta := &parser.TypeLayout{
+		Name: "Meta",
+		Anno: &parser.TypeAnnotation{Size: 4},
+		Fields: []parser.Field{
+			{Name: "KeyOff", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "KeySize", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Pad", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+		},
+	}
+	leaf := &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 512},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Meta", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize", Region: "Data", OffsetMode: parser.OffsetAfterMetadata,
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Meta", 4)
+	analyzed, err := analyzer.Analyze(leaf, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, leaf, []*parser.TypeLayout{meta, leaf}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// A 512-byte page can hold offsets and sizes that a uint8 can't
+	expectedParts := []string{
+		"if offset - elementsEnd > 255 {",
+		`return nil, fmt.Errorf("%d overflows Elems.KeyOff (uint8): %w", offset - elementsEnd, ErrOutOfRange)`,
+		"if size > 255 {",
+		`return nil, fmt.Errorf("%d overflows Elems.KeySize (uint8): %w", size, ErrOutOfRange)`,
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Wide enough types need no guard
+	meta.Fields[0].GoType, meta.Fields[1].GoType = "uint16", "uint16"
+	code, err = gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if strings.Contains(code, "overflows") {
+		t.Errorf("uint16 metadata should not be guarded in a 512-byte page\n\nGenerated:\n%s", code)
+	}
+}
+
+func TestGenerateAppendBitCountLimit(t *testing.T) {
+	// @layout size=128 mode=zerocopy
+	// type Page struct {
+	//     buf  [128]byte
+	//     N    uint8  `layout:"@0,bits=3"`
+	//     Body []byte `layout:"@1,start-end,count=N"`
+	// }
+	page := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Bits: 3}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: 1, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(page, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, page, []*parser.TypeLayout{page}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// A 3-bit count stops Append at 7 bytes, not at the 127-byte region
+	if !strings.Contains(code, "if n+len(b) > 7 {") {
+		t.Errorf("AppendBody should be limited by the 3-bit count\n\nGenerated:\n%s", code)
+	}
+}
@@ -0,0 +1,140 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// CStructTag is the build tag enabling the cgo offset tests of cstruct= layouts,
+// keeping cgo out of ordinary builds of the package
+const CStructTag = "layoutcgo"
+
+// GenerateCStructHeader generates the package clause, cgo preamble and imports
+// of the file reading member offsets of C structs, including each header of the
+// cstruct= layouts. Headers resolve against the package directory too.
+func GenerateCStructHeader(pkg string, includes []string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("//go:build cgo && %s\n\n", CStructTag))
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("/*\n")
+	code.WriteString("#cgo CFLAGS: -I${SRCDIR}\n")
+	for _, header := range includes {
+		code.WriteString(fmt.Sprintf("#include <%s>\n", header))
+	}
+	code.WriteString("*/\n")
+	code.WriteString("import \"C\"\n\n")
+	code.WriteString("import \"unsafe\"\n\n")
+
+	return code.String()
+}
+
+// GenerateCStructTestHeader generates the package clause and imports of the
+// tests comparing layouts against their C structs
+func GenerateCStructTestHeader(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("//go:build cgo && %s\n\n", CStructTag))
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import \"testing\"\n\n")
+
+	return code.String()
+}
+
+// cMembers returns the fixed regions of a cstruct= layout compared against the
+// C struct, with the C member of each as cgo names it: cfield= or the field
+// name, Go keywords prefixed with an underscore. Bit fields, which cgo can't
+// reach, and reserved ranges are not compared.
+func (g *Generator) cMembers() ([]analyzer.Region, []string) {
+	var regions []analyzer.Region
+	var members []string
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.FixedRegion || region.Bits > 0 || isReserved(region) {
+			continue
+		}
+		member := region.Field.Layout.CField
+		if member == "" {
+			member = region.Field.Name
+		}
+		if token.IsKeyword(member) {
+			member = "_" + member
+		}
+		regions = append(regions, region)
+		members = append(members, member)
+	}
+	return regions, members
+}
+
+// GenerateCStructMembers generates c<Type>Members for a cstruct= layout,
+// returning the offset and size of the C member of each compared field, keyed
+// by field name, and the size of the C struct. Tests can't use cgo themselves,
+// so the function lives in a file of its own.
+func (g *Generator) GenerateCStructMembers() string {
+	cType := g.layout.Anno.CStruct
+	if cType == "" {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// c%sMembers returns the offset and size of the C.%s members\n", typeName, cType))
+	code.WriteString(fmt.Sprintf("// mirrored by %s, keyed by field, and the size of the struct\n", typeName))
+	code.WriteString(fmt.Sprintf("func c%sMembers() (map[string][2]uintptr, uintptr) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tvar c C.%s\n", cType))
+	code.WriteString("\treturn map[string][2]uintptr{\n")
+	regions, members := g.cMembers()
+	for i, region := range regions {
+		code.WriteString(fmt.Sprintf("\t\t%q: {unsafe.Offsetof(c.%s), unsafe.Sizeof(c.%s)},\n",
+			region.Field.Name, members[i], members[i]))
+	}
+	code.WriteString("\t}, unsafe.Sizeof(c)\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// GenerateCStructTest generates a test comparing the offset and size of every
+// compared field of a cstruct= layout against its C member, as the C compiler
+// packs the struct, and checking that the buffer holds the whole struct
+func (g *Generator) GenerateCStructTest() string {
+	cType := g.layout.Anno.CStruct
+	if cType == "" {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName, size := g.analyzed.TypeName, g.analyzed.BufferSize
+
+	code.WriteString(fmt.Sprintf("// TestLayoutCStruct%s compares the offsets of %s against the members of\n", typeName, typeName))
+	code.WriteString(fmt.Sprintf("// C.%s, catching packing the tags don't match.\n", cType))
+	code.WriteString(fmt.Sprintf("func TestLayoutCStruct%s(t *testing.T) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tmembers, size := c%sMembers()\n", typeName))
+	code.WriteString("\ttests := []struct {\n")
+	code.WriteString("\t\tfield, member string\n")
+	code.WriteString("\t\toffset, size  uintptr\n")
+	code.WriteString("\t}{\n")
+	regions, members := g.cMembers()
+	for i, region := range regions {
+		code.WriteString(fmt.Sprintf("\t\t{%q, %q, %d, %d},\n",
+			region.Field.Name, members[i], region.Start, region.Boundary-region.Start))
+	}
+	code.WriteString("\t}\n")
+	code.WriteString("\tfor _, tt := range tests {\n")
+	code.WriteString("\t\tif got := members[tt.field]; got != [2]uintptr{tt.offset, tt.size} {\n")
+	code.WriteString(fmt.Sprintf("\t\t\tt.Errorf(\"%%s at [%%d, %%d), C.%s.%%s at [%%d, %%d)\",\n", cType))
+	code.WriteString("\t\t\t\ttt.field, tt.offset, tt.offset+tt.size, tt.member, got[0], got[0]+got[1])\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tif size > %d {\n", size))
+	code.WriteString(fmt.Sprintf("\t\tt.Errorf(\"C.%s is %%d bytes, more than the %d-byte buffer\", size)\n", cType, size))
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
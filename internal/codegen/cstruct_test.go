@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCStruct(t *testing.T) {
+	// @layout size=32 cstruct=struct_dev_hdr cinclude=stdint.h,dev.h
+	// type DevHdr struct {
+	//     Magic   uint32 `layout:"@0"`
+	//     Type    uint8  `layout:"@4,cfield=type"`
+	//     Version uint8  `layout:"@5.0,bits=4"`
+	//     ID      uint64 `layout:"@8,cfield=id"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "DevHdr",
+		Anno: &parser.TypeAnnotation{Size: 32, CStruct: "struct_dev_hdr", CInclude: []string{"stdint.h", "dev.h"}},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, CField: "type"}},
+			{Name: "Version", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 5, Direction: parser.Fixed, Bits: 4}},
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, CField: "id"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+
+	members := GenerateCStructHeader("dev", layout.Anno.CInclude) + gen.GenerateCStructMembers()
+	test := GenerateCStructTestHeader("dev") + gen.GenerateCStructTest()
+	for _, code := range []string{members, test} {
+		if _, err := format.Source([]byte(code)); err != nil {
+			t.Fatalf("Generated code does not parse: %v\n\nGenerated:\n%s", err, code)
+		}
+	}
+
+	expectedParts := []string{
+		"//go:build cgo && layoutcgo\n",
+		"#cgo CFLAGS: -I${SRCDIR}\n#include <stdint.h>\n#include <dev.h>\n*/\nimport \"C\"\n",
+		"func cDevHdrMembers() (map[string][2]uintptr, uintptr) {\n\tvar c C.struct_dev_hdr\n",
+		"\t\t\"Magic\": {unsafe.Offsetof(c.Magic), unsafe.Sizeof(c.Magic)},\n",
+		// cgo prefixes members named like Go keywords
+		"\t\t\"Type\": {unsafe.Offsetof(c._type), unsafe.Sizeof(c._type)},\n",
+		"\t\t\"ID\": {unsafe.Offsetof(c.id), unsafe.Sizeof(c.id)},\n",
+		"\t}, unsafe.Sizeof(c)\n",
+		"func TestLayoutCStructDevHdr(t *testing.T) {\n\tmembers, size := cDevHdrMembers()\n",
+		"\t\t{\"Type\", \"_type\", 4, 1},\n",
+		"\t\t{\"ID\", \"id\", 8, 8},\n",
+		"\tif size > 32 {\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(members+test, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s%s", expected, members, test)
+		}
+	}
+	// cgo can't reach C bit fields
+	if strings.Contains(members, "Version") {
+		t.Errorf("Generated code compares bit field Version:\n%s", members)
+	}
+
+	layout.Anno.CStruct = ""
+	if got := gen.GenerateCStructTest(); got != "" {
+		t.Errorf("GenerateCStructTest() without cstruct= = %q, want none", got)
+	}
+}
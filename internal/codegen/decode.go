@@ -0,0 +1,148 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// kindRead describes where the kind= layouts of a file keep their kind: every
+// one must read it from the same bytes the same way for DecodePage to route by it
+type kindRead struct {
+	offset, size int
+	goType       string // Resolved integer type, e.g. uint16
+	order        string // Byte order of multi-byte kinds, e.g. binary.LittleEndian
+}
+
+// kindRead returns where the generator's layout keeps its kind. The analyzer
+// checked that kindfield= names a fixed integer field.
+func (g *Generator) kindRead() kindRead {
+	for _, field := range g.layout.Fields {
+		if field.Name != g.layout.Anno.KindField {
+			continue
+		}
+		goType := g.registry.ResolveType(field.GoType)
+		if goType == "byte" {
+			goType = "uint8"
+		}
+		size, _ := analyzer.SizeOf(goType)
+		read := kindRead{offset: field.Layout.Offset, size: size, goType: goType}
+		if size > 1 {
+			read.order = g.byteOrder()
+		}
+		return read
+	}
+	return kindRead{}
+}
+
+// String describes the kind's bytes for errors, e.g. "uint16 at @0 in binary.BigEndian"
+func (k kindRead) String() string {
+	if k.order == "" {
+		return fmt.Sprintf("%s at @%d", k.goType, k.offset)
+	}
+	return fmt.Sprintf("%s at @%d in %s", k.goType, k.offset, k.order)
+}
+
+// expr returns the expression reading the kind from buf
+func (k kindRead) expr() string {
+	if k.size == 1 {
+		if k.goType == "int8" {
+			return fmt.Sprintf("int8(buf[%d])", k.offset)
+		}
+		return fmt.Sprintf("buf[%d]", k.offset)
+	}
+	read := fmt.Sprintf("%s.Uint%d(buf[%d:])", k.order, k.size*8, k.offset)
+	if strings.HasPrefix(k.goType, "int") {
+		return fmt.Sprintf("%s(%s)", k.goType, read)
+	}
+	return read
+}
+
+// GenerateDecodePage generates DecodePage, which reads the kind of an encoded
+// page and unmarshals it into a new value of the kind= layout it names, and the
+// packages it imports. gens are the generators of one file; those without kind=
+// are left out, and without any nothing is generated. The kind= layouts must
+// keep their kind in the same bytes, and no two may share a kind.
+func GenerateDecodePage(gens []*Generator) (string, []string, error) {
+	var kinds []*Generator
+	for _, g := range gens {
+		if g.layout.Anno.Kind != "" {
+			kinds = append(kinds, g)
+		}
+	}
+	if len(kinds) == 0 {
+		return "", nil, nil
+	}
+
+	first := kinds[0]
+	read := first.kindRead()
+	seen := make(map[string]string)
+	minimal := true
+	for _, g := range kinds {
+		anno := g.layout.Anno
+		if other := g.kindRead(); other != read {
+			return "", nil, fmt.Errorf("kind=: %s reads its kind from %s (%s), %s from %s (%s)",
+				g.analyzed.TypeName, anno.KindField, other, first.analyzed.TypeName, first.layout.Anno.KindField, read)
+		}
+		value := normalizeKind(anno.Kind)
+		if other, ok := seen[value]; ok {
+			return "", nil, fmt.Errorf("kind=%s: %s and %s share the kind", anno.Kind, other, g.analyzed.TypeName)
+		}
+		seen[value] = g.analyzed.TypeName
+		minimal = minimal && g.minimal()
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// DecodePage decodes buf into a new value of the layout its %s field names:\n",
+		first.layout.Anno.KindField))
+	code.WriteString("//\n")
+	for _, g := range kinds {
+		code.WriteString(fmt.Sprintf("//   - kind %s: *%s\n", g.layout.Anno.Kind, g.analyzed.TypeName))
+	}
+	code.WriteString("//\n")
+	code.WriteString("// Other kinds return ErrUnknownKind.\n")
+	code.WriteString("func DecodePage(buf []byte) (any, error) {\n")
+	code.WriteString(fmt.Sprintf("\tif len(buf) < %d {\n", read.offset+read.size))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"DecodePage: expected at least %d bytes, got %%d: %%w\", len(buf), ErrShortBuffer)\n",
+		read.offset+read.size))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tswitch kind := %s; kind {\n", read.expr()))
+	for _, g := range kinds {
+		code.WriteString(fmt.Sprintf("\tcase %s:\n", g.layout.Anno.Kind))
+		code.WriteString(fmt.Sprintf("\t\tp := %s\n", g.emptyValue()))
+		code.WriteString("\t\tif err := p.UnmarshalLayout(buf); err != nil {\n")
+		code.WriteString("\t\t\treturn nil, err\n")
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t\treturn p, nil\n")
+	}
+	code.WriteString("\tdefault:\n")
+	code.WriteString("\t\treturn nil, fmt.Errorf(\"DecodePage: kind %d: %w\", kind, ErrUnknownKind)\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	imports := []string{"fmt"}
+	if minimal {
+		imports = nil
+	}
+	if read.size > 1 {
+		imports = append(imports, "encoding/binary")
+	}
+	if minimal {
+		return stripErrorf(code.String()), imports, nil
+	}
+	return code.String(), imports, nil
+}
+
+// normalizeKind returns the decimal form of a kind= literal, so 0x10 and 16
+// are seen as the same kind
+func normalizeKind(kind string) string {
+	if v, err := strconv.ParseInt(kind, 0, 64); err == nil {
+		return strconv.FormatInt(v, 10)
+	}
+	if v, err := strconv.ParseUint(kind, 0, 64); err == nil {
+		return strconv.FormatUint(v, 10)
+	}
+	return kind
+}
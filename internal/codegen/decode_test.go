@@ -0,0 +1,118 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// kindLayout returns a 64-byte layout routed by kind, its kind kept in a Type
+// field of goType at @0
+//
+//	// @layout size=64 kind=<kind> kindfield=Type
+//	type <name> struct {
+//	    Type <goType> `layout:"@0"`
+//	    Next uint64   `layout:"@8"`
+//	}
+func kindLayout(name, kind, goType string) *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: name,
+		Anno: &parser.TypeAnnotation{Size: 64, Kind: kind, KindField: "Type"},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: goType, Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Next", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+}
+
+func kindGenerators(t *testing.T, layouts ...*parser.TypeLayout) []*Generator {
+	t.Helper()
+	reg := analyzer.NewTypeRegistry()
+	var gens []*Generator
+	for _, layout := range layouts {
+		analyzed, err := analyzer.Analyze(layout, reg)
+		if err != nil {
+			t.Fatalf("Analyze(%s) error: %v, errors: %v", layout.Name, err, analyzed.Errors)
+		}
+		gens = append(gens, NewGenerator(analyzed, layout, layouts, reg, "little", "copy", 0, ""))
+	}
+	return gens
+}
+
+func TestGenerateDecodePage(t *testing.T) {
+	meta := kindLayout("Meta", "", "uint16")
+	meta.Anno.KindField = ""
+	gens := kindGenerators(t, kindLayout("Leaf", "1", "uint16"), kindLayout("Branch", "0x2", "uint16"), meta)
+	code, imports, err := GenerateDecodePage(gens)
+	if err != nil {
+		t.Fatalf("GenerateDecodePage() error: %v", err)
+	}
+
+	// The kind is read once, then routed to a new value of its layout; layouts
+	// without kind= aren't routed to
+	expectedParts := []string{
+		"//   - kind 1: *Leaf\n//   - kind 0x2: *Branch\n",
+		"func DecodePage(buf []byte) (any, error) {\n\tif len(buf) < 2 {\n",
+		"\tswitch kind := binary.LittleEndian.Uint16(buf[0:]); kind {\n",
+		"\tcase 0x2:\n\t\tp := &Branch{}\n\t\tif err := p.UnmarshalLayout(buf); err != nil {\n" +
+			"\t\t\treturn nil, err\n\t\t}\n\t\treturn p, nil\n",
+		"\t\treturn nil, fmt.Errorf(\"DecodePage: kind %d: %w\", kind, ErrUnknownKind)\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "Meta") {
+		t.Errorf("Meta has no kind= but is routed to:\n%s", code)
+	}
+	if !slices.Equal(imports, []string{"fmt", "encoding/binary"}) {
+		t.Errorf("imports = %v, want [fmt encoding/binary]", imports)
+	}
+
+	// Without kind= layouts there is nothing to route
+	if code, _, _ := GenerateDecodePage(kindGenerators(t, meta)); code != "" {
+		t.Errorf("GenerateDecodePage() without kind= = %q, want nothing", code)
+	}
+}
+
+func TestGenerateDecodePage_Byte(t *testing.T) {
+	// A signed byte is read without a byte order, or encoding/binary
+	a, b := kindLayout("A", "-1", "int8"), kindLayout("B", "1", "int8")
+	a.Anno.Errors, b.Anno.Errors = "minimal", "minimal"
+	code, imports, err := GenerateDecodePage(kindGenerators(t, a, b))
+	if err != nil {
+		t.Fatalf("GenerateDecodePage() error: %v", err)
+	}
+	if !strings.Contains(code, "\tswitch kind := int8(buf[0]); kind {\n") {
+		t.Errorf("Generated code doesn't read an int8 kind:\n%s", code)
+	}
+	// errors=minimal everywhere returns the sentinels as they are, without fmt
+	if strings.Contains(code, "fmt.") || len(imports) != 0 {
+		t.Errorf("errors=minimal code uses fmt, imports %v:\n%s", imports, code)
+	}
+}
+
+func TestGenerateDecodePage_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		layouts []*parser.TypeLayout
+		wantErr string
+	}{
+		{"shared kind", []*parser.TypeLayout{kindLayout("Leaf", "16", "uint8"), kindLayout("Branch", "0x10", "uint8")},
+			"kind=0x10: Leaf and Branch share the kind"},
+		{"other type", []*parser.TypeLayout{kindLayout("Leaf", "1", "uint8"), kindLayout("Branch", "2", "uint16")},
+			"kind=: Branch reads its kind from Type (uint16 at @0 in binary.LittleEndian), Leaf from Type (uint8 at @0)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := GenerateDecodePage(kindGenerators(t, tt.layouts...))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateDecodePage() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// defaultRegions returns the fixed regions whose fields declare default=
+func (g *Generator) defaultRegions() []analyzer.Region {
+	var regions []analyzer.Region
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.FixedRegion && region.Field.Layout.Default != "" {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// generateMarshalDefaults generates the start of MarshalLayout, which replaces zero
+// fields with their defaults so magics and versions are never encoded as zero
+func (g *Generator) generateMarshalDefaults() string {
+	regions := g.defaultRegions()
+	if len(regions) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t// Zero fields take their defaults\n")
+	for _, region := range regions {
+		field := region.Field
+		code.WriteString(fmt.Sprintf("\tif p.%s == 0 {\n", field.Name))
+		code.WriteString(fmt.Sprintf("\t\tp.%s = %s\n", field.Name, field.Layout.Default))
+		code.WriteString("\t}\n")
+	}
+	code.WriteString("\n")
+
+	return code.String()
+}
+
+// generateNewDefaults generates the part of zerocopy New<Type> that stores field
+// defaults into both the struct and p.buf
+func (g *Generator) generateNewDefaults() string {
+	regions := g.defaultRegions()
+	if len(regions) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t\n")
+	code.WriteString("\t// Field defaults\n")
+	for _, region := range regions {
+		field := region.Field
+		code.WriteString(fmt.Sprintf("\tp.%s = %s\n", field.Name, field.Layout.Default))
+		if _, ok := g.countCapacity(field.Name); ok {
+			// Defaults beyond the capacity are refused, leaving p.buf zero
+			code.WriteString(fmt.Sprintf("\t_ = p.Set%s(p.%s)\n", upperFirst(field.Name), field.Name))
+		} else if !writable(region) {
+			// Read-only registers have no setter
+			code.WriteString("\t" + g.registerStore(region, "p."+field.Name))
+		} else {
+			code.WriteString(fmt.Sprintf("\tp.Set%s(p.%s)\n", upperFirst(field.Name), field.Name))
+		}
+	}
+
+	return code.String()
+}
+
+// generateMagicOp generates unmarshal code for a magic= field, which decodes it and
+// refuses buffers holding any other value, e.g. another format or garbage
+func (g *Generator) generateMagicOp(region analyzer.Region) string {
+	layout := *region.Field.Layout
+	layout.Magic = false
+	region.Field.Layout = &layout
+
+	var code strings.Builder
+	code.WriteString(strings.TrimSuffix(g.generateFixedOp(region, "unmarshal"), "\n"))
+	code.WriteString(magicCheck(region.Field))
+	code.WriteString("\n")
+
+	return code.String()
+}
+
+// magicCheck generates the check that the decoded magic= field holds its value
+func magicCheck(field parser.Field) string {
+	verb := "%d"
+	if strings.HasPrefix(strings.ToLower(field.Layout.Default), "0x") {
+		verb = "%#x"
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\tif p.%s != %s {\n", field.Name, field.Layout.Default))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: %s, want %s: %%w\", p.%s, ErrBadMagic)\n",
+		field.Name, verb, field.Layout.Default, field.Name))
+	code.WriteString("\t}\n")
+	return code.String()
+}
@@ -0,0 +1,116 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateDefaults(t *testing.T) {
+	// @layout size=16
+	// type Header struct {
+	//     Magic   uint32 `layout:"@0,default=0xCAFE"`
+	//     Version uint16 `layout:"@4,default=1"`
+	//     Flags   uint16 `layout:"@6"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "0xCAFE"}},
+			{Name: "Version", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Default: "1"}},
+			{Name: "Flags", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	// Copy mode: marshal fills zero fields
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	expectedParts := []string{
+		"if p.Magic == 0 {\n\t\tp.Magic = 0xCAFE\n\t}",
+		"if p.Version == 0 {\n\t\tp.Version = 1\n\t}",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "p.Flags == 0") {
+		t.Errorf("Flags has no default\n\nGenerated:\n%s", code)
+	}
+
+	// Zerocopy mode: New stores defaults into the struct and p.buf
+	gen = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err = gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	expectedParts = []string{
+		"func NewHeader() *Header {",
+		"p.Magic = 0xCAFE\n\tp.SetMagic(p.Magic)",
+		"p.Version = 1\n\tp.SetVersion(p.Version)",
+		"if p.Magic == 0 {",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	// Array-backed buffers are embedded, New allocates nothing
+	if strings.Contains(code, "p.backing") {
+		t.Errorf("Array-backed New should not allocate\n\nGenerated:\n%s", code)
+	}
+}
+
+func TestGenerateMagic(t *testing.T) {
+	// @layout size=8
+	// type Header struct {
+	//     Magic   uint32 `layout:"@0,magic=0xCAFE"`
+	//     Version uint8  `layout:"@4.0,bits=4,magic=2"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "0xCAFE", Magic: true}},
+			{Name: "Version", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Bits: 4, Default: "2", Magic: true}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	for _, mode := range []string{"copy", "zerocopy"} {
+		gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", mode, 0, "")
+		code, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("%s: Generate() error: %v", mode, err)
+		}
+
+		// Magics default like default= and are checked right after decoding
+		expectedParts := []string{
+			"if p.Magic == 0 {\n\t\tp.Magic = 0xCAFE\n\t}",
+			"\tif p.Magic != 0xCAFE {\n\t\treturn fmt.Errorf(\"Magic: %#x, want 0xCAFE: %w\", p.Magic, ErrBadMagic)\n\t}",
+			"\tif p.Version != 2 {\n\t\treturn fmt.Errorf(\"Version: %d, want 2: %w\", p.Version, ErrBadMagic)\n\t}",
+		}
+		for _, expected := range expectedParts {
+			if !strings.Contains(code, expected) {
+				t.Errorf("%s: generated code missing: %q\n\nGenerated:\n%s", mode, expected, code)
+			}
+		}
+	}
+}
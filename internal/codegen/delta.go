@@ -0,0 +1,113 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// isDelta reports whether a region holds varint deltas of sorted integers (encode=delta)
+func isDelta(region analyzer.Region) bool {
+	return region.Kind == analyzer.DynamicRegion && region.Field.Layout.Encode == parser.EncodeDelta
+}
+
+// usesDelta reports whether the code encodes varint deltas
+func (g *Generator) usesDelta() bool {
+	for _, region := range g.analyzed.Regions {
+		if isDelta(region) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateDeltaMarshal generates marshal code for an encode=delta region: each
+// element written as the uvarint difference from the one before it, the first
+// from zero. Descending elements are refused, as their difference would wrap.
+func (g *Generator) generateDeltaMarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (delta-encoded)\n",
+		field.Name, field.GoType, region.Start, region.Boundary, countField))
+	code.WriteString(fmt.Sprintf("\toffset = %d\n", region.Start))
+	code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
+		field.Name, field.Name, countField))
+	code.WriteString("\t}\n")
+	code.WriteString(generateMaxCheck(field))
+
+	code.WriteString("\t{\n")
+	code.WriteString(fmt.Sprintf("\t\tvar prev %s\n", region.ElementType))
+	code.WriteString("\t\tvar delta [binary.MaxVarintLen64]byte\n")
+	index := "i" // Only errors name the element
+	if g.minimal() {
+		index = "_"
+	}
+	code.WriteString(fmt.Sprintf("\t\tfor %s, v := range p.%s {\n", index, field.Name))
+	code.WriteString("\t\t\tif v < prev {\n")
+	code.WriteString(fmt.Sprintf("\t\t\t\treturn nil, fmt.Errorf(\"%s[%%d]: %%d is below the %%d before it: %%w\", i, v, prev, ErrOutOfRange)\n", field.Name))
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tn := binary.PutUvarint(delta[:], uint64(v-prev))\n")
+	code.WriteString(fmt.Sprintf("\t\t\tif offset+n > %d {\n", region.Boundary))
+	code.WriteString(fmt.Sprintf("\t\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset, ErrRegionOverflow)\n", field.Name))
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\toffset += copy(buf[offset:], delta[:n])\n")
+	code.WriteString("\t\t\tprev = v\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// generateDeltaUnmarshal generates unmarshal code for an encode=delta region,
+// summing count= varint deltas read from the region's start. Deltas running past
+// the region or past the element type's range are refused.
+func (g *Generator) generateDeltaUnmarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+	elementType := region.ElementType
+
+	var limit uint64
+	switch g.registry.ResolveType(elementType) {
+	case "uint16":
+		limit = 1<<16 - 1
+	case "uint32":
+		limit = 1<<32 - 1
+	default:
+		limit = 1<<64 - 1
+	}
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (delta-encoded)\n",
+		field.Name, field.GoType, region.Start, region.Boundary, countField))
+	code.WriteString(g.generateCountCheck(region))
+	code.WriteString("\t// Reuse slice if capacity allows\n")
+	code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= int(p.%s) {\n", field.Name, countField))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:p.%s]\n", field.Name, field.Name, countField))
+	code.WriteString("\t} else {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = make([]%s, p.%s)\n", field.Name, elementType, countField))
+	code.WriteString("\t}\n")
+
+	code.WriteString("\t{\n")
+	code.WriteString(fmt.Sprintf("\t\toffset := %d\n", region.Start))
+	code.WriteString(fmt.Sprintf("\t\tvar prev %s\n", elementType))
+	code.WriteString(fmt.Sprintf("\t\tfor i := range p.%s {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\t\tdelta, n := binary.Uvarint(buf[offset:%d])\n", region.Boundary))
+	code.WriteString("\t\t\tif n <= 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\t\t\treturn fmt.Errorf(\"%s[%%d]: delta at offset %%d outside region: %%w\", i, offset, ErrRegionOverflow)\n", field.Name))
+	code.WriteString("\t\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\t\tif delta > %#x-uint64(prev) {\n", limit))
+	code.WriteString(fmt.Sprintf("\t\t\t\treturn fmt.Errorf(\"%s[%%d]: delta %%d overflows %s: %%w\", i, delta, ErrOutOfRange)\n", field.Name, elementType))
+	code.WriteString("\t\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\t\tprev += %s(delta)\n", elementType))
+	code.WriteString(fmt.Sprintf("\t\t\tp.%s[i] = prev\n", field.Name))
+	code.WriteString("\t\t\toffset += n\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
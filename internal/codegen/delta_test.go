@@ -0,0 +1,59 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateDelta(t *testing.T) {
+	// @layout size=64
+	// type Postings struct {
+	//     N   uint8    `layout:"@0"`
+	//     IDs []uint32 `layout:"start-end,count=N,encode=delta"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Postings",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "IDs", GoType: "[]uint32", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N", Encode: parser.EncodeDelta}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal writes uvarint differences and refuses descending values
+		"\t// IDs: []uint32 at [1, 64) with count=N (delta-encoded)\n\toffset = 1\n",
+		"\t\tvar prev uint32\n",
+		"\t\t\tif v < prev {\n",
+		"\t\t\tn := binary.PutUvarint(delta[:], uint64(v-prev))\n\t\t\tif offset+n > 64 {\n",
+		"\t\t\toffset += copy(buf[offset:], delta[:n])\n\t\t\tprev = v\n",
+		// Unmarshal sums them, bounded by the region and by uint32
+		"\t\treturn fmt.Errorf(\"IDs: count %d outside capacity 63: %w\", p.N, ErrRegionOverflow)\n",
+		"\t\t\tdelta, n := binary.Uvarint(buf[offset:64])\n\t\t\tif n <= 0 {\n",
+		"\t\t\tif delta > 0xffffffff-uint64(prev) {\n",
+		"\t\t\tprev += uint32(delta)\n\t\t\tp.IDs[i] = prev\n\t\t\toffset += n\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if !gen.usesBinary() {
+		t.Error("usesBinary() = false, want true for varint deltas")
+	}
+}
@@ -0,0 +1,63 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetBlockSize sets the filesystem block size aligned zerocopy layouts are checked
+// against (-blocksize): direct I/O transfers whole blocks from block-aligned
+// memory, so align= and the layout size must be multiples of it. 0 disables the
+// check.
+func (g *Generator) SetBlockSize(blockSize int) {
+	g.blockSize = blockSize
+}
+
+// checkDirectIO refuses aligned zerocopy layouts whose buffer can't be read or
+// written with direct I/O on a filesystem of the configured block size
+func (g *Generator) checkDirectIO() error {
+	if g.blockSize == 0 || g.mode != "zerocopy" || g.align == 0 {
+		return nil
+	}
+	if g.align%g.blockSize != 0 {
+		return fmt.Errorf("align=%d is not a multiple of the %d-byte block size (-blocksize), direct I/O needs block-aligned buffers",
+			g.align, g.blockSize)
+	}
+	if size := g.analyzed.BufferSize; size%g.blockSize != 0 {
+		return fmt.Errorf("size=%d is not a multiple of the %d-byte block size (-blocksize), direct I/O transfers whole blocks",
+			size, g.blockSize)
+	}
+	return nil
+}
+
+// generateDirectBuffer generates DirectBuffer for aligned zerocopy types, handing
+// out p.buf for syscall-based direct I/O (O_DIRECT) once its address and length
+// are checked to be multiples of the alignment
+func (g *Generator) generateDirectBuffer() string {
+	if g.align == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// DirectBuffer returns the buffer of %s for direct I/O (O_DIRECT), such as\n", typeName))
+	code.WriteString(fmt.Sprintf("// syscall.Pread into it. Its address and length are multiples of %d bytes.\n", g.align))
+	if g.blockSize > 0 {
+		code.WriteString(fmt.Sprintf("// Both meet the %d-byte filesystem block size checked at generation.\n", g.blockSize))
+	}
+	code.WriteString(fmt.Sprintf("func (p *%s) DirectBuffer() ([]byte, error) {\n", typeName))
+	code.WriteString("\tif len(p.buf) == 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"DirectBuffer: no buffer, create %s with New%s: %%w\", ErrBadBuffer)\n", typeName, typeName))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tif uintptr(unsafe.Pointer(&p.buf[0]))%%%d != 0 {\n", g.align))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"DirectBuffer: buffer is not %d-byte aligned: %%w\", ErrBadBuffer)\n", g.align))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tif len(p.buf)%%%d != 0 {\n", g.align))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"DirectBuffer: %%d-byte buffer is not a multiple of %d bytes: %%w\", len(p.buf), ErrBadBuffer)\n", g.align))
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn p.buf, nil\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
@@ -0,0 +1,108 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// alignedPage returns a zerocopy page of the given size and alignment
+func alignedPage(size, align int) *parser.TypeLayout {
+	// @layout size=N mode=zerocopy align=A
+	// type Page struct {
+	//     ID uint64 `layout:"@0"`
+	// }
+	return &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: size, Mode: "zerocopy", Align: align},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+}
+
+func TestGenerateDirectBuffer(t *testing.T) {
+	tests := []struct {
+		name            string
+		align           int
+		blockSize       int
+		expectedParts   []string
+		unexpectedParts []string
+	}{
+		{"aligned", 512, 0, []string{
+			"func (p *Page) DirectBuffer() ([]byte, error) {\n\tif len(p.buf) == 0 {\n" +
+				"\t\treturn nil, fmt.Errorf(\"DirectBuffer: no buffer, create Page with NewPage: %w\", ErrBadBuffer)\n\t}\n",
+			"\tif uintptr(unsafe.Pointer(&p.buf[0]))%512 != 0 {\n",
+			"\tif len(p.buf)%512 != 0 {\n",
+			"\treturn p.buf, nil\n}\n",
+		}, []string{"block size"}},
+		{"block size", 4096, 4096, []string{
+			"// Both meet the 4096-byte filesystem block size checked at generation.\n",
+		}, nil},
+		{"unaligned", 0, 0, nil, []string{"DirectBuffer"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := alignedPage(4096, tt.align)
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", tt.align, "")
+			gen.SetBlockSize(tt.blockSize)
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			for _, unexpected := range tt.unexpectedParts {
+				if strings.Contains(code, unexpected) {
+					t.Errorf("Generated code has: %q\n\nGenerated:\n%s", unexpected, code)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDirectIO(t *testing.T) {
+	tests := []struct {
+		name                   string
+		size, align, blockSize int
+		wantErr                string
+	}{
+		{"meets block size", 8192, 4096, 4096, ""},
+		{"no block size", 100, 8, 0, ""},
+		{"no alignment", 100, 0, 4096, ""},
+		{"alignment below block size", 4096, 512, 4096, "align=512 is not a multiple of the 4096-byte block size"},
+		{"partial block", 6144, 4096, 4096, "size=6144 is not a multiple of the 4096-byte block size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := alignedPage(tt.size, tt.align)
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", tt.align, "")
+			gen.SetBlockSize(tt.blockSize)
+			_, err = gen.Generate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Generate() error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package codegen
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// dumpRowSize is the number of bytes on each line of a region's hex dump
+const dumpRowSize = 16
+
+// generateDump generates Dump, a hex dump of an encoded buffer for logs and
+// debugging: each fixed field, then the used part of each dynamic region, at
+// their offsets. Bytes of sensitive fields and regions are redacted, as are
+// the fields and regions whose bytes they overlap, and layouts of this file
+// holding sensitive fields. Free space isn't shown, so a region sharing free
+// space with a sensitive one is shown only when both counts tell how far each
+// reaches, and never past the other.
+func (g *Generator) generateDump() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	bufferSize := g.analyzed.BufferSize
+
+	code.WriteString(fmt.Sprintf("// Dump returns a hex dump of the %s encoded in buf, for logs and debugging: its\n", typeName))
+	code.WriteString("// fields and the used part of its regions, at their offsets. Sensitive fields and\n")
+	code.WriteString("// regions are redacted, and free space isn't shown. p is not read and may be nil.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) Dump(buf []byte) string {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tif len(buf) < %d {\n", bufferSize))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Sprintf(\"%s: %%d bytes, want %d\", len(buf))\n", typeName, bufferSize))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tout := []byte(\"%s (%d bytes)\\n\")\n", typeName, bufferSize))
+
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.FixedRegion || isReserved(region) {
+			continue
+		}
+		field := region.Field
+		size := region.Boundary - region.Start
+		if g.redacted(region, region.Start, region.Boundary, "") {
+			code.WriteString(fmt.Sprintf("\tout = append(out, \"%04x  %s: %d bytes redacted\\n\"...)\n", region.Start, field.Name, size))
+			continue
+		}
+		code.WriteString(fmt.Sprintf("\tout = fmt.Appendf(out, \"%04x  %s: %% x\\n\", buf[%d:%d])\n", region.Start, field.Name, region.Start, region.Boundary))
+	}
+
+	counts := make(map[string]bool)
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.DynamicRegion {
+			code.WriteString(g.generateRegionDump(region, counts))
+		}
+	}
+
+	code.WriteString("\treturn string(out)\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// generateRegionDump generates the dump of a dynamic region: the bytes its count
+// says are in use, or the whole region when its count doesn't tell, a row of
+// bytes per line
+func (g *Generator) generateRegionDump(region analyzer.Region, counts map[string]bool) string {
+	var code strings.Builder
+	field := region.Field
+	low, high := g.regionSpan(region)
+
+	partner, shared := g.sensitivePartner(region)
+	if g.redacted(region, low, high, partner.Field.Name) {
+		code.WriteString(fmt.Sprintf("\tout = append(out, \"%04x  %s: %d bytes redacted\\n\"...)\n", low, field.Name, high-low))
+		return code.String()
+	}
+
+	// Counts are probed first, so none is declared for a region left unread
+	probe := maps.Clone(counts)
+	_, _, ok := g.usedBytes(region, "buf", probe)
+	if shared {
+		_, _, partnerOK := g.usedBytes(partner, "buf", probe)
+		if !ok || !partnerOK {
+			code.WriteString(fmt.Sprintf("\tout = append(out, \"%04x  %s: %d bytes redacted, sharing free space with %s\\n\"...)\n",
+				low, field.Name, high-low, partner.Field.Name))
+			return code.String()
+		}
+	}
+
+	used := fmt.Sprint(high - low)
+	if ok {
+		var decl string
+		decl, used, _ = g.usedBytes(region, "buf", counts)
+		code.WriteString(decl)
+	}
+	if shared {
+		// Shown up to the bytes the sensitive region's count says it uses
+		decl, partnerUsed, _ := g.usedBytes(partner, "buf", counts)
+		code.WriteString(decl)
+		stride := max(region.ElementStride, 1)
+		used = fmt.Sprintf("max(min(%s, %d-%s), 0)", g.usedCount(region), (high-low)/stride*stride, partnerUsed)
+	}
+
+	name := "used" + field.Name
+	code.WriteString(fmt.Sprintf("\t%s := %s\n", name, used))
+	from, to := fmt.Sprint(low), fmt.Sprintf("%d+%s", low, name)
+	if region.Direction == parser.EndStart {
+		from, to = fmt.Sprintf("%d-%s", high, name), fmt.Sprint(high)
+	}
+	code.WriteString(fmt.Sprintf("\tout = fmt.Appendf(out, \"%%04x  %s: %%d bytes\\n\", %s, %s)\n", field.Name, from, name))
+	code.WriteString(fmt.Sprintf("\tfor at := %s; at < %s; at += %d {\n", from, to, dumpRowSize))
+	code.WriteString(fmt.Sprintf("\t\tout = fmt.Appendf(out, \"%%04x    %% x\\n\", at, buf[at:min(at+%d, %s)])\n", dumpRowSize, to))
+	code.WriteString("\t}\n")
+	return code.String()
+}
+
+// redacted reports whether the bytes [low, high) of region are kept out of dumps:
+// it is sensitive, holds a layout of this file with sensitive fields, or
+// overlaps a sensitive field or region other than except
+func (g *Generator) redacted(region analyzer.Region, low, high int, except string) bool {
+	if region.Field.Layout.Sensitive || g.holdsSensitive(region.Field.GoType, nil) {
+		return true
+	}
+	for _, other := range g.analyzed.Regions {
+		if other.Field.Name == region.Field.Name || other.Field.Name == except || !other.Field.Layout.Sensitive {
+			continue
+		}
+		otherLow, otherHigh := other.Start, other.Boundary
+		if other.Kind == analyzer.DynamicRegion {
+			otherLow, otherHigh = g.regionSpan(other)
+		}
+		if low < otherHigh && otherLow < high {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitivePartner returns the sensitive region sharing free space with region,
+// if it isn't sensitive itself
+func (g *Generator) sensitivePartner(region analyzer.Region) (analyzer.Region, bool) {
+	if region.Field.Layout.Sensitive {
+		return analyzer.Region{}, false
+	}
+	for _, gap := range g.analyzed.Gaps {
+		switch region.Field.Name {
+		case gap.Forward.Field.Name:
+			if gap.Backward.Field.Layout.Sensitive {
+				return gap.Backward, true
+			}
+		case gap.Backward.Field.Name:
+			if gap.Forward.Field.Layout.Sensitive {
+				return gap.Forward, true
+			}
+		}
+	}
+	return analyzer.Region{}, false
+}
+
+// holdsSensitive reports whether goType, or the element type of a slice or
+// array of it, is a layout of this file with sensitive fields, directly or in
+// layouts it nests. Layouts of other packages aren't known.
+func (g *Generator) holdsSensitive(goType string, seen map[string]bool) bool {
+	goType = strings.TrimLeft(goType, "[]0123456789")
+	if seen[goType] {
+		return false
+	}
+	for _, layout := range g.allLayouts {
+		if layout.Name != goType {
+			continue
+		}
+		if seen == nil {
+			seen = make(map[string]bool)
+		}
+		seen[goType] = true
+		for _, f := range layout.Fields {
+			if f.Layout != nil && (f.Layout.Sensitive || g.holdsSensitive(f.GoType, seen)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateDump(t *testing.T) {
+	// @layout size=8
+	// type Cred struct {
+	//     ID    uint32 `layout:"@0"`
+	//     Token uint32 `layout:"@4,sensitive"`
+	// }
+	//
+	// @layout size=128
+	// type Page struct {
+	//     N      uint16  `layout:"@0"`
+	//     M      uint16  `layout:"@2"`
+	//     Key    [8]byte `layout:"@4,sensitive"`
+	//     Flags  uint8   `layout:"@12"`
+	//     Cred   Cred    `layout:"@16"`
+	//     Names  []byte  `layout:"@24,start-end,count=N"`
+	//     Secret []byte  `layout:"@128,end-start,count=M,sensitive"`
+	// }
+	cred := &parser.TypeLayout{
+		Name: "Cred",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Token", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Sensitive: true}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Key", GoType: "[8]byte", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Sensitive: true}},
+			{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed}},
+			{Name: "Cred", GoType: "Cred", Layout: &parser.FieldLayout{Offset: 16, Direction: parser.Fixed}},
+			{Name: "Names", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 24, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Secret", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 128, Direction: parser.EndStart, CountField: "M", Sensitive: true}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Cred", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{cred, layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Page) Dump(buf []byte) string {\n",
+		"\t\treturn fmt.Sprintf(\"Page: %d bytes, want 128\", len(buf))\n",
+		"\tout = fmt.Appendf(out, \"0000  N: % x\\n\", buf[0:2])\n",
+		"\tout = append(out, \"0004  Key: 8 bytes redacted\\n\"...)\n",
+		// Nested layouts holding sensitive fields are redacted whole
+		"\tout = append(out, \"0010  Cred: 8 bytes redacted\\n\"...)\n",
+		// Names is shown up to the bytes Secret uses of their shared free space
+		"\tusedNames := max(min(countN, 104-max(min(countM, 104), 0)), 0)\n",
+		"\tfor at := 24; at < 24+usedNames; at += 16 {\n",
+		"\tout = append(out, \"0018  Secret: 104 bytes redacted\\n\"...)\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "Key: % x") {
+		t.Errorf("Dump shows the sensitive Key")
+	}
+}
+
+func TestGenerateDumpBitCount(t *testing.T) {
+	// @layout size=64
+	// type Page struct {
+	//     N      uint8  `layout:"@0.0,bits=4"`
+	//     Secret []byte `layout:"@8,start-end,count=N,sensitive"`
+	//     Tail   []byte `layout:"@64,end-start,count=N"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Bits: 4}},
+			{Name: "Secret", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N", Sensitive: true}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 64, Direction: parser.EndStart, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// A bit field count doesn't tell where either side ends
+	expected := "\tout = append(out, \"0008  Tail: 56 bytes redacted, sharing free space with Secret\\n\"...)\n"
+	if !strings.Contains(code, expected) {
+		t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+	}
+}
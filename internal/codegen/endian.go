@@ -0,0 +1,98 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// generateConvertEndian generates ConvertEndian, which copies src into dst and
+// byte-swaps every multi-byte fixed field, converting an encoded buffer between
+// little and big endian without materializing the struct. Nested struct fields
+// are converted through their own ConvertEndian. Dynamic regions are copied as-is.
+//
+// endian=pdp buffers are converted to and from little endian instead, by
+// reversing the order of the 16-bit words of each field.
+//
+// dst and src may be the same slice to convert in place.
+func (g *Generator) generateConvertEndian() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	bufferSize := g.analyzed.BufferSize
+	family := g.byteOrderFamily()
+
+	code.WriteString(fmt.Sprintf("// ConvertEndian copies an encoded %s from src to dst, byte-swapping multi-byte\n", typeName))
+	code.WriteString(fmt.Sprintf("// fixed fields between %s. dst and src may overlap exactly.\n", family.convert))
+	code.WriteString(fmt.Sprintf("// Panics if either buffer is shorter than %d bytes.\n", bufferSize))
+	code.WriteString(fmt.Sprintf("func (p *%s) ConvertEndian(dst []byte, src []byte) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\t_ = dst[%d] // Bounds check hint to compiler\n", bufferSize-1))
+	code.WriteString(fmt.Sprintf("\tcopy(dst[:%d], src[:%d])\n", bufferSize, bufferSize))
+
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.FixedRegion {
+			continue
+		}
+		field := region.Field
+		start := region.Start
+		end := region.Boundary
+		resolvedType := g.registry.ResolveType(field.GoType)
+
+		// Bit fields, reserved ranges and byte arrays have no byte order, and
+		// aliases are converted as the fields they overlay
+		if region.Bits > 0 || isReserved(region) || isAlias(region) {
+			continue
+		}
+		if strings.HasPrefix(resolvedType, "[") && strings.HasSuffix(resolvedType, "]byte") {
+			continue
+		}
+		// Present slots are packed from the start of the field, and elements
+		// of inline slices each at their place, so swapping every slot-sized
+		// piece converts them whichever are present or in use
+		if isPresent(region) || isCapSlice(region) {
+			n, _, size := g.presentSlots(region)
+			for i := 0; size > 1 && i < n; i++ {
+				if swap := family.swap(start+i*size, size); swap != "" {
+					code.WriteString(fmt.Sprintf("\t%s // %s[%d]\n", swap, field.Name, i))
+				}
+			}
+			continue
+		}
+		// Kinds define the byte order of their encoding, unless it is the layout's
+		if k, ok := kindOf(region); ok {
+			if swap := family.swap(start, end-start); k.ByteOrdered && end-start > 1 && swap != "" {
+				code.WriteString(fmt.Sprintf("\t%s // %s\n", swap, field.Name))
+			}
+			continue
+		}
+
+		size, err := analyzer.SizeOf(resolvedType)
+		if err != nil {
+			// Struct type - converted by its own ConvertEndian
+			code.WriteString(fmt.Sprintf("\tp.%s.ConvertEndian(dst[%d:%d], dst[%d:%d])\n", field.Name, start, end, start, end))
+			continue
+		}
+		if size < 2 {
+			continue
+		}
+
+		if swap := family.swap(start, size); swap != "" {
+			code.WriteString(fmt.Sprintf("\t%s // %s\n", swap, field.Name))
+		}
+	}
+
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// swapBytes returns a tuple assignment reversing dst[start:start+size]
+func swapBytes(start, size int) string {
+	lhs := make([]string, size)
+	rhs := make([]string, size)
+	for i := 0; i < size; i++ {
+		lhs[i] = fmt.Sprintf("dst[%d]", start+i)
+		rhs[i] = fmt.Sprintf("dst[%d]", start+size-1-i)
+	}
+	return strings.Join(lhs, ", ") + " = " + strings.Join(rhs, ", ")
+}
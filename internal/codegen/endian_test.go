@@ -0,0 +1,70 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateConvertEndian(t *testing.T) {
+	// type PageID uint64
+	//
+	// @layout size=32
+	// type Header struct {
+	//     Flags  uint8    `layout:"@0"`
+	//     Count  uint16   `layout:"@2"`
+	//     Next   PageID   `layout:"@8"`
+	//     Magic  [4]byte  `layout:"@16"`
+	//     Data   []byte   `layout:"@20,start-end"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 32},
+		Fields: []parser.Field{
+			{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Count", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Next", GoType: "PageID", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			{Name: "Magic", GoType: "[4]byte", Layout: &parser.FieldLayout{Offset: 16, Direction: parser.Fixed}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 20,
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.RegisterAlias("PageID", "uint64")
+
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Header) ConvertEndian(dst []byte, src []byte) {",
+		"copy(dst[:32], src[:32])",
+		"dst[2], dst[3] = dst[3], dst[2] // Count",
+		// Aliases swap as their underlying type
+		"dst[8], dst[9], dst[10], dst[11], dst[12], dst[13], dst[14], dst[15] = dst[15], dst[14], dst[13], dst[12], dst[11], dst[10], dst[9], dst[8] // Next",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+
+	// Single bytes and byte arrays have no byte order
+	convert, _, _ := strings.Cut(code, "func (p *Header) EqualBuffers")
+	for _, name := range []string{"Flags", "Magic"} {
+		if strings.Contains(convert, "// "+name+"\n") {
+			t.Errorf("Generated code swaps %s", name)
+		}
+	}
+}
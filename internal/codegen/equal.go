@@ -0,0 +1,306 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// byteRange is a span of an encoded buffer compared by EqualBuffers, with the
+// fields it holds for the generated comment. A span holding a nested layout is
+// compared by that type's EqualBuffers.
+type byteRange struct {
+	start, end int
+	names      []string
+	nested     string
+}
+
+// generateEqualBuffers generates EqualBuffers, which compares two encoded buffers
+// byte for byte, but only the bytes the layout decodes: fixed fields, then the
+// used part of each counted region. Gaps, reserved ranges, the padding between
+// elemalign= or stride= elements and the free space past a count are skipped, so
+// buffers differing only there compare equal. Fixed fields come first, so the
+// counts are known equal by the time they are read from a. Regions whose used
+// part can't be told from a plain count field are compared whole.
+func (g *Generator) generateEqualBuffers() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	bufferSize := g.analyzed.BufferSize
+
+	code.WriteString(fmt.Sprintf("// EqualBuffers reports whether a and b encode the same %s, comparing its fields\n", typeName))
+	code.WriteString("// and the used part of its regions but not gaps, reserved ranges or free space.\n")
+	code.WriteString(fmt.Sprintf("// p is not read and may be nil. Panics if either buffer is shorter than %d bytes.\n", bufferSize))
+	code.WriteString(fmt.Sprintf("func (p *%s) EqualBuffers(a, b []byte) bool {\n", typeName))
+	code.WriteString(fmt.Sprintf("\t_, _ = a[%d], b[%d] // Bounds check hint to compiler\n", bufferSize-1, bufferSize-1))
+
+	for _, r := range g.fixedRanges() {
+		if r.nested != "" {
+			code.WriteString(fmt.Sprintf("\tif !(*%s)(nil).EqualBuffers(a[%d:%d], b[%d:%d]) { // %s\n",
+				r.nested, r.start, r.end, r.start, r.end, r.names[0]))
+		} else {
+			code.WriteString(fmt.Sprintf("\tif string(a[%d:%d]) != string(b[%d:%d]) { // %s\n",
+				r.start, r.end, r.start, r.end, strings.Join(r.names, ", ")))
+		}
+		code.WriteString("\t\treturn false\n")
+		code.WriteString("\t}\n")
+	}
+
+	counts := make(map[string]bool)
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.DynamicRegion {
+			continue
+		}
+		code.WriteString(g.generateRegionEqual(region, counts))
+	}
+
+	code.WriteString("\treturn true\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// fixedRanges returns the spans of the fixed fields, reserved ranges left out,
+// with overlapping and adjacent fields merged into one span unless they are
+// nested layouts
+func (g *Generator) fixedRanges() []byteRange {
+	var ranges []byteRange
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.FixedRegion || isReserved(region) {
+			continue
+		}
+		r := byteRange{start: region.Start, end: region.Boundary, names: []string{region.Field.Name}}
+		if !isAlias(region) && region.Bits == 0 {
+			r.nested = g.nestedEqual(region.Field.GoType)
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+
+	var merged []byteRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end && r.nested == "" && merged[n-1].nested == "" {
+			last := &merged[n-1]
+			last.end = max(last.end, r.end)
+			last.names = append(last.names, r.names...)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// generateRegionEqual generates the comparison of a dynamic region: the bytes its
+// count says are in use, read once per count field, or the whole region
+func (g *Generator) generateRegionEqual(region analyzer.Region, counts map[string]bool) string {
+	var code strings.Builder
+	field := region.Field
+	low, high := g.regionSpan(region)
+
+	decl, used, ok := g.usedBytes(region, "a", counts)
+	if !ok {
+		code.WriteString(fmt.Sprintf("\tif string(a[%d:%d]) != string(b[%d:%d]) { // %s, compared whole\n", low, high, low, high, field.Name))
+		code.WriteString("\t\treturn false\n")
+		code.WriteString("\t}\n")
+		return code.String()
+	}
+	code.WriteString(decl)
+
+	stride := max(region.ElementStride, 1)
+	code.WriteString(fmt.Sprintf("\t// %s: %s bytes in use, at most %d\n", field.Name, g.usedCount(region), (high-low)/stride*stride))
+	code.WriteString(fmt.Sprintf("\tif n := %s; ", used))
+
+	from, to := fmt.Sprint(low), fmt.Sprintf("%d+n", low)
+	if region.Direction == parser.EndStart {
+		from, to = fmt.Sprintf("%d-n", high), fmt.Sprint(high)
+	}
+	nested := ""
+	if len(region.Members) == 0 {
+		nested = g.nestedEqual(region.ElementType)
+	}
+	if nested == "" && (region.ElementSize == stride || region.ElementType == "byte") {
+		code.WriteString(fmt.Sprintf("string(a[%s:%s]) != string(b[%s:%s]) {\n", from, to, from, to))
+		code.WriteString("\t\treturn false\n")
+		code.WriteString("\t}\n")
+		return code.String()
+	}
+
+	// Elements are compared one at a time, without the padding to their stride
+	// and by their own EqualBuffers when they are layouts
+	code.WriteString("n > 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\tfor at := %s; at < %s; at += %d {\n", from, to, stride))
+	if nested != "" {
+		code.WriteString(fmt.Sprintf("\t\t\tif !(*%s)(nil).EqualBuffers(a[at:at+%d], b[at:at+%d]) {\n", nested, region.ElementSize, region.ElementSize))
+	} else {
+		code.WriteString(fmt.Sprintf("\t\t\tif string(a[at:at+%d]) != string(b[at:at+%d]) {\n", region.ElementSize, region.ElementSize))
+	}
+	code.WriteString("\t\t\t\treturn false\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	return code.String()
+}
+
+// regionSpan returns the bytes [low, high) a dynamic region may occupy
+func (g *Generator) regionSpan(region analyzer.Region) (int, int) {
+	boundary := region.Boundary
+	if boundary < 0 {
+		boundary = g.analyzed.BufferSize
+	}
+	if region.Direction == parser.EndStart {
+		return boundary, region.Start // Backward regions grow from Start down to Boundary
+	}
+	return region.Start, boundary
+}
+
+// usedBytes returns an int expression of how many bytes of a dynamic region are
+// in use, from its count read out of buf, whole elements within the region
+// only, and the statements declaring the count unless counts holds it already.
+// Not ok when a plain count field doesn't tell: uncounted regions, nested or
+// bit field counts of other types, region= chains, length-prefixed elements and
+// encode=delta.
+func (g *Generator) usedBytes(region analyzer.Region, buf string, counts map[string]bool) (string, string, bool) {
+	field := region.Field
+	countField := field.Layout.CountField
+	load, ok := g.countLoad(countField, buf)
+	if !ok || field.Layout.Group != "" || field.Layout.Encode != "" || field.Layout.Sentinel || g.isFramed(region) {
+		return "", "", false
+	}
+
+	decl := ""
+	if !counts[countField] {
+		counts[countField] = true
+		decl = load
+	}
+	low, high := g.regionSpan(region)
+	stride := max(region.ElementStride, 1)
+	return decl, fmt.Sprintf("max(min(%s, %d), 0)", g.usedCount(region), (high-low)/stride*stride), true
+}
+
+// usedCount returns the bytes a region's count says are in use, unbounded
+func (g *Generator) usedCount(region analyzer.Region) string {
+	count := "count" + upperFirst(strings.ReplaceAll(region.Field.Layout.CountField, ".", ""))
+	if stride := region.ElementStride; stride > 1 {
+		return fmt.Sprintf("%s*%d", count, stride)
+	}
+	return count
+}
+
+// nestedEqual returns goType when it is a fixed-size layout of this file, whose
+// generated EqualBuffers skips its own padding; empty otherwise
+func (g *Generator) nestedEqual(goType string) string {
+	for _, layout := range g.allLayouts {
+		if layout.Name == goType && layout.Anno != nil && layout.Anno.Mode != "stream" && layout.Anno.Raw == "" {
+			return goType
+		}
+	}
+	return ""
+}
+
+// isFramed reports whether a region holds length-prefixed elements, whose used
+// bytes their count doesn't tell
+func (g *Generator) isFramed(region analyzer.Region) bool {
+	_, ok := g.registry.Frame(region.ElementType)
+	return ok
+}
+
+// countLoad returns a statement declaring count<Field>, the value of an integer
+// count field read from the encoded buffer buf: in the byte order of the type
+// declaring it, native in zerocopy mode. Not ok for the count fields countSpan
+// can't locate, whose regions are compared whole instead.
+func (g *Generator) countLoad(countField, buf string) (string, bool) {
+	span, ok := g.countSpan(countField)
+	if !ok {
+		return "", false
+	}
+	start, size, resolved, endian, mode := span.start, span.size, span.goType, span.endian, span.mode
+
+	end := start + size
+	count := "count" + upperFirst(strings.ReplaceAll(countField, ".", ""))
+	switch {
+	case size == 1 && resolved == "int8":
+		return fmt.Sprintf("\t%s := int(int8(%s[%d]))\n", count, buf, start), true
+	case size == 1:
+		return fmt.Sprintf("\t%s := int(%s[%d])\n", count, buf, start), true
+	case mode == "zerocopy":
+		var code strings.Builder
+		code.WriteString(fmt.Sprintf("\tvar %sValue %s\n", count, resolved))
+		code.WriteString(fmt.Sprintf("\tcopy(%s, %s[%d:%d])\n", bytesOf("&"+count+"Value", size), buf, start, end))
+		code.WriteString(fmt.Sprintf("\t%s := int(%sValue)\n", count, count))
+		return code.String(), true
+	default:
+		eg := *g
+		eg.endian = endian
+		load := fmt.Sprintf("%s.%s(%s[%d:%d])", eg.endianPrefix(), g.binaryGetFunc(resolved), buf, start, end)
+		if !strings.HasPrefix(resolved, "uint") {
+			load = fmt.Sprintf("%s(%s)", resolved, load)
+		}
+		return fmt.Sprintf("\t%s := int(%s)\n", count, load), true
+	}
+}
+
+// countBytes is where an integer count field is encoded
+type countBytes struct {
+	start, size  int
+	goType       string // Resolved integer type
+	endian, mode string // Of the type declaring the field
+}
+
+// countSpan locates an integer count field in the encoded buffer. Nested count
+// fields (e.g., "Header.NumKeys") are found through the fixed fields of the
+// layouts of this file. Not ok for bit field and kind counts, or fields of
+// other types.
+func (g *Generator) countSpan(countField string) (countBytes, bool) {
+	if countField == "" {
+		return countBytes{}, false
+	}
+	parts := strings.Split(countField, ".")
+	var field parser.Field
+	for _, r := range g.analyzed.Regions {
+		if r.Kind == analyzer.FixedRegion && r.Field.Name == parts[0] {
+			field = r.Field
+		}
+	}
+	if field.Layout == nil {
+		return countBytes{}, false
+	}
+	start := field.Layout.Offset
+	endian, mode := g.endian, g.mode
+
+	// Walk nested references through the layouts declaring them
+	for _, name := range parts[1:] {
+		var layout *parser.TypeLayout
+		for _, l := range g.allLayouts {
+			if l.Name == field.GoType && l.Anno != nil {
+				layout = l
+			}
+		}
+		if layout == nil {
+			return countBytes{}, false
+		}
+		var next parser.Field
+		for _, f := range layout.Fields {
+			if f.Name == name && f.Layout != nil && f.Layout.Direction == parser.Fixed {
+				next = f
+			}
+		}
+		if next.Layout == nil {
+			return countBytes{}, false
+		}
+		field = next
+		start += field.Layout.Offset
+		endian, mode = layout.Anno.Endian, layout.Anno.Mode
+	}
+	if field.Layout.Bits > 0 || field.Layout.Kind != "" {
+		return countBytes{}, false
+	}
+
+	resolved := g.registry.ResolveType(field.GoType)
+	size, err := analyzer.SizeOf(resolved)
+	if err != nil || (!strings.Contains(resolved, "int") && resolved != "byte") {
+		return countBytes{}, false
+	}
+	return countBytes{start, size, resolved, endian, mode}, true
+}
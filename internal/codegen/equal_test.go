@@ -0,0 +1,152 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateEqualBuffers(t *testing.T) {
+	// @layout size=8
+	// type Val struct {
+	//     A uint32 `layout:"@0"`
+	//     B uint16 `layout:"@4"`
+	// }
+	//
+	// @layout size=128
+	// type Page struct {
+	//     N     uint16  `layout:"@0"`
+	//     M     int8    `layout:"@2"`
+	//     Pad   [4]byte `layout:"@4,reserve=4"`
+	//     Flags uint8   `layout:"@9"`
+	//     Vals  []Val   `layout:"@16,start-end,count=N"`
+	//     Tail  []byte  `layout:"@128,end-start,count=M"`
+	// }
+	val := &parser.TypeLayout{
+		Name: "Val",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "A", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "B", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "int8", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Pad", GoType: "[4]byte", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Reserve: 4}},
+			{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 9, Direction: parser.Fixed}},
+			{Name: "Vals", GoType: "[]Val", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 128, Direction: parser.EndStart, CountField: "M"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Val", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"\tcountN := int(binary.LittleEndian.Uint16(a[0:2]))\n",
+		}},
+		{"zerocopy", []string{
+			"\tvar countNValue uint16\n\tcopy((*[2]byte)(unsafe.Pointer(&countNValue))[:], a[0:2])\n\tcountN := int(countNValue)\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{val, layout}, reg, "little", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+
+			expectedParts := append(tt.expectedParts,
+				"func (p *Page) EqualBuffers(a, b []byte) bool {\n",
+				// Adjacent fields are compared together; the gap and reserved range are skipped
+				"\tif string(a[0:3]) != string(b[0:3]) { // N, M\n",
+				"\tif string(a[9:10]) != string(b[9:10]) { // Flags\n",
+				// Layout elements skip their own padding
+				"\tif n := max(min(countN*8, 112), 0); n > 0 {\n\t\tfor at := 16; at < 16+n; at += 8 {\n",
+				"\t\t\tif !(*Val)(nil).EqualBuffers(a[at:at+8], b[at:at+8]) {\n",
+				// Backward regions end at their start
+				"\tcountM := int(int8(a[2]))\n",
+				"\tif n := max(min(countM, 112), 0); string(a[128-n:128]) != string(b[128-n:128]) {\n",
+			)
+			for _, expected := range expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if strings.Contains(code, "a[4:8]") {
+				t.Errorf("EqualBuffers compares the reserved range")
+			}
+		})
+	}
+}
+
+func TestGenerateEqualBuffersNestedCount(t *testing.T) {
+	// @layout size=8 endian=big
+	// type Header struct {
+	//     Magic   uint32 `layout:"@0"`
+	//     NumKeys uint16 `layout:"@4"`
+	// }
+	//
+	// @layout size=64
+	// type Leaf struct {
+	//     Header Header `layout:"@0"`
+	//     Keys   []byte `layout:"@8,start-end,count=Header.NumKeys"`
+	// }
+	header := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 8, Endian: "big"},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "NumKeys", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Header", GoType: "Header", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Keys", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "Header.NumKeys"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Header", 8)
+	reg.RegisterFields("Header", header.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{header, layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"\tif !(*Header)(nil).EqualBuffers(a[0:8], b[0:8]) { // Header\n",
+		// Read in the byte order of the type declaring it
+		"\tcountHeaderNumKeys := int(binary.BigEndian.Uint16(a[4:6]))\n",
+		"\tif n := max(min(countHeaderNumKeys, 56), 0); string(a[8:8+n]) != string(b[8:8+n]) {\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
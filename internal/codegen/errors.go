@@ -0,0 +1,79 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorsFile is the file holding the sentinel errors of a package. Every generated
+// file of the package shares it, so it is written once per package directory.
+const ErrorsFile = "layout_errors.go"
+
+// sentinels are the errors generated code wraps, with field context, so callers can
+// match failures with errors.Is instead of comparing strings
+var sentinels = []struct {
+	name string
+	doc  string
+	text string
+}{
+	{"ErrShortBuffer", "a buffer or frame is shorter than the layout requires", "short buffer"},
+	{"ErrLongBuffer", "a buffer or frame is longer than the layout allows", "long buffer"},
+	{"ErrBadBuffer", "a buffer can't back a zerocopy type: misaligned or overlapping", "unusable buffer"},
+	{"ErrCountMismatch", "a count or length field disagrees with the data it describes", "count mismatch"},
+	{"ErrRegionOverflow", "a dynamic region outgrows its space or runs into another region", "region overflow"},
+	{"ErrOutOfRange", "a value doesn't fit the field, bit width or integer type storing it", "value out of range"},
+	{"ErrBadMagic", "a magic= field holds another value", "bad magic"},
+	{"ErrReservedNotZero", "a verified reserved range holds non-zero bytes", "reserved bytes not zero"},
+	{"ErrNotCanonical", "a canonical=true buffer holds bytes MarshalLayout wouldn't write", "not canonical"},
+	{"ErrTruncatedPage", "a reader or batch buffer ends partway through a page", "truncated page"},
+	{"ErrBadChain", "a next= page links back into its own chain", "bad page chain"},
+	{"ErrUnknownKind", "DecodePage reads a kind no kind= layout has", "unknown page kind"},
+}
+
+// GenerateErrors generates the sentinel errors file of package pkg
+func GenerateErrors(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import \"errors\"\n\n")
+	code.WriteString("// Errors returned by generated layout code, wrapped with the failing field or\n")
+	code.WriteString("// method. Match them with errors.Is.\n")
+	code.WriteString("var (\n")
+	for i, s := range sentinels {
+		if i > 0 {
+			code.WriteString("\n")
+		}
+		code.WriteString(fmt.Sprintf("\t// %s is returned when %s\n", s.name, s.doc))
+		code.WriteString(fmt.Sprintf("\t%s = errors.New(\"layout: %s\")\n", s.name, s.text))
+	}
+	code.WriteString(")\n")
+
+	return code.String()
+}
+
+// generateLengthCheck generates a check that lenExpr is within [minLen, maxLen],
+// returning ErrShortBuffer or ErrLongBuffer prefixed with context. results are the
+// return values preceding the error, e.g. "nil, ".
+func generateLengthCheck(lenExpr string, minLen, maxLen int, context, results string) string {
+	var code strings.Builder
+	want := fmt.Sprintf("%d bytes", minLen)
+	if minLen != maxLen {
+		want = fmt.Sprintf("[%d, %d] bytes", minLen, maxLen)
+	}
+	prefix := ""
+	if context != "" {
+		prefix = context + ": "
+	}
+
+	code.WriteString(fmt.Sprintf("\tif %s < %d {\n", lenExpr, minLen))
+	code.WriteString(fmt.Sprintf("\t\treturn %sfmt.Errorf(\"%sexpected %s, got %%d: %%w\", %s, ErrShortBuffer)\n",
+		results, prefix, want, lenExpr))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tif %s > %d {\n", lenExpr, maxLen))
+	code.WriteString(fmt.Sprintf("\t\treturn %sfmt.Errorf(\"%sexpected %s, got %%d: %%w\", %s, ErrLongBuffer)\n",
+		results, prefix, want, lenExpr))
+	code.WriteString("\t}\n")
+
+	return code.String()
+}
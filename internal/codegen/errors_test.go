@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateErrors(t *testing.T) {
+	code := GenerateErrors("pages")
+
+	expectedParts := []string{
+		"// Code generated by layout. DO NOT EDIT.",
+		"package pages\n",
+		"import \"errors\"",
+		"ErrShortBuffer = errors.New(\"layout: short buffer\")",
+		"ErrLongBuffer = errors.New(\"layout: long buffer\")",
+		"ErrCountMismatch = errors.New(\"layout: count mismatch\")",
+		"ErrRegionOverflow = errors.New(\"layout: region overflow\")",
+		"ErrOutOfRange = errors.New(\"layout: value out of range\")",
+		"ErrBadMagic = errors.New(\"layout: bad magic\")",
+		"ErrReservedNotZero = errors.New(\"layout: reserved bytes not zero\")",
+		"ErrNotCanonical = errors.New(\"layout: not canonical\")",
+		"ErrTruncatedPage = errors.New(\"layout: truncated page\")",
+		"ErrBadChain = errors.New(\"layout: bad page chain\")",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateLengthCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		min, max      int
+		context       string
+		expectedParts []string
+	}{
+		{
+			name: "exact",
+			min:  64, max: 64,
+			expectedParts: []string{
+				"\tif len(buf) < 64 {\n\t\treturn fmt.Errorf(\"expected 64 bytes, got %d: %w\", len(buf), ErrShortBuffer)\n\t}\n",
+				"\tif len(buf) > 64 {\n\t\treturn fmt.Errorf(\"expected 64 bytes, got %d: %w\", len(buf), ErrLongBuffer)\n\t}\n",
+			},
+		},
+		{
+			name: "range",
+			min:  7, max: 1500, context: "frame",
+			expectedParts: []string{
+				"return fmt.Errorf(\"frame: expected [7, 1500] bytes, got %d: %w\", len(buf), ErrShortBuffer)",
+				"return fmt.Errorf(\"frame: expected [7, 1500] bytes, got %d: %w\", len(buf), ErrLongBuffer)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := generateLengthCheck("len(buf)", tt.min, tt.max, tt.context, "")
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+		})
+	}
+}
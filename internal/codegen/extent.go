@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// generateExtents generates the accessors of every extents= field. Their bytes
+// live in other pages, so MarshalLayout and UnmarshalLayout leave them alone.
+func (g *Generator) generateExtents() string {
+	var code strings.Builder
+	for _, field := range g.layout.Fields {
+		if field.Layout.Extents == "" {
+			continue
+		}
+		code.WriteString("\n")
+		code.WriteString(g.generateExtentAt(field))
+		code.WriteString("\n")
+		code.WriteString(g.generateExtentLoad(field))
+	}
+	return code.String()
+}
+
+// generateExtentAt generates <Field>At, which resolves one extent through fetch.
+// An extent within its first page is returned as a subslice of it; one running
+// past the end continues at offset 0 of the following page IDs and is copied. The
+// size is read from the page, so the copy grows with the pages fetched rather than
+// being allocated up front.
+func (g *Generator) generateExtentAt(field parser.Field) string {
+	var code strings.Builder
+	l := field.Layout
+	name := field.Name
+
+	code.WriteString(fmt.Sprintf("// %sAt returns element i of %s: %s[i].%s bytes at %s[i].%s of page %s[i].%s,\n",
+		upperFirst(name), name, l.Extents, l.SizeField, l.Extents, l.OffsetField, l.Extents, l.PageField))
+	code.WriteString("// continuing into the following pages when it runs past the end of one. fetch\n")
+	code.WriteString("// returns the bytes of a page, or nil if there is none.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) %sAt(i int, fetch func(pageID uint64) []byte) ([]byte, error) {\n",
+		g.analyzed.TypeName, upperFirst(name)))
+	code.WriteString(fmt.Sprintf("\telem := &p.%s[i]\n", l.Extents))
+	code.WriteString(fmt.Sprintf("\tpage := uint64(elem.%s)\n", l.PageField))
+	code.WriteString(fmt.Sprintf("\toffset, size := int(elem.%s), int(elem.%s)\n", l.OffsetField, l.SizeField))
+	code.WriteString("\tdata := fetch(page)\n")
+	code.WriteString("\tif offset < 0 || size < 0 || offset > len(data) {\n")
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s[%%d]: offset %%d outside page %%d of %%d bytes: %%w\", i, offset, page, len(data), ErrRegionOverflow)\n",
+		name))
+	code.WriteString("\t}\n")
+	code.WriteString("\tif offset+size <= len(data) {\n")
+	code.WriteString("\t\treturn data[offset : offset+size], nil\n")
+	code.WriteString("\t}\n\n")
+	code.WriteString("\t// Spans pages: gather the pieces as they arrive, so a corrupt size allocates\n")
+	code.WriteString("\t// no more than the pages fetched hold\n")
+	code.WriteString("\tout := append([]byte(nil), data[offset:]...)\n")
+	code.WriteString("\tfor len(out) < size {\n")
+	code.WriteString("\t\tpage++\n")
+	code.WriteString("\t\tdata = fetch(page)\n")
+	code.WriteString("\t\tif len(data) == 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s[%%d]: page %%d empty with %%d of %%d bytes read: %%w\", i, page, len(out), size, ErrShortBuffer)\n",
+		name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tout = append(out, data[:min(len(data), size-len(out))]...)\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn out, nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// generateExtentLoad generates Load<Field>, which resolves every extent into the
+// field, reusing its backing array
+func (g *Generator) generateExtentLoad(field parser.Field) string {
+	var code strings.Builder
+	name := field.Name
+	source := field.Layout.Extents
+
+	code.WriteString(fmt.Sprintf("// Load%s resolves every element of %s through fetch, see %sAt\n", upperFirst(name), name, upperFirst(name)))
+	code.WriteString(fmt.Sprintf("func (p *%s) Load%s(fetch func(pageID uint64) []byte) error {\n", g.analyzed.TypeName, upperFirst(name)))
+	code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= len(p.%s) {\n", name, source))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:len(p.%s)]\n", name, name, source))
+	code.WriteString("\t} else {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = make([][]byte, len(p.%s))\n", name, source))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", source))
+	code.WriteString(fmt.Sprintf("\t\tdata, err := p.%sAt(i, fetch)\n", upperFirst(name)))
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s[i] = data\n", name))
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
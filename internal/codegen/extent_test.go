@@ -0,0 +1,83 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// extentLeaf builds:
+//
+//	// @layout size=64
+//	type Leaf struct {
+//	    N        uint16   `layout:"@0"`
+//	    Elems    []Elem   `layout:"@2,start-end,count=N"`
+//	    Overflow [][]byte `layout:"extents=Elems,page=OvfPage,offset=OvfOff,size=OvfLen"`
+//	    Data     []byte   `layout:"end-start"`
+//	}
+func extentLeaf() *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2, CountField: "N",
+			}},
+			{Name: "Overflow", GoType: "[][]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Extents: "Elems", PageField: "OvfPage", OffsetField: "OvfOff", SizeField: "OvfLen",
+			}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+		},
+	}
+}
+
+func TestGenerateExtents(t *testing.T) {
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+
+	for _, mode := range []string{"copy", "zerocopy"} {
+		t.Run(mode, func(t *testing.T) {
+			layout := extentLeaf()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+
+			expectedParts := []string{
+				"func (p *Leaf) OverflowAt(i int, fetch func(pageID uint64) []byte) ([]byte, error) {\n",
+				"\tpage := uint64(elem.OvfPage)\n\toffset, size := int(elem.OvfOff), int(elem.OvfLen)\n",
+				"return nil, fmt.Errorf(\"Overflow[%d]: offset %d outside page %d of %d bytes: %w\", i, offset, page, len(data), ErrRegionOverflow)",
+				"\tif offset+size <= len(data) {\n\t\treturn data[offset : offset+size], nil\n\t}\n",
+				"\t\tpage++\n\t\tdata = fetch(page)\n",
+				"return nil, fmt.Errorf(\"Overflow[%d]: page %d empty with %d of %d bytes read: %w\", i, page, len(out), size, ErrShortBuffer)",
+				"func (p *Leaf) LoadOverflow(fetch func(pageID uint64) []byte) error {\n",
+				"\t\tp.Overflow = make([][]byte, len(p.Elems))\n",
+				"\t\tdata, err := p.OverflowAt(i, fetch)\n",
+			}
+			for _, expected := range expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+
+			// A corrupt size allocates nothing before the pages holding it arrive
+			if strings.Contains(code, "make([]byte, 0, size)") || !strings.Contains(code, "\tout := append([]byte(nil), data[offset:]...)\n") {
+				t.Errorf("OverflowAt allocates from the size in the page:\n%s", code)
+			}
+
+			// The bytes live in other pages: nothing is read from or written to buf
+			if strings.Contains(code, "p.Overflow = p.Overflow[:0]") || strings.Contains(code, "range p.Overflow") {
+				t.Errorf("MarshalLayout/UnmarshalLayout touch Overflow:\n%s", code)
+			}
+		})
+	}
+}
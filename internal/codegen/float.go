@@ -0,0 +1,168 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// canonicalNaN are the bits floatpolicy=strict writes for every NaN: the quiet NaN
+// with no payload
+var canonicalNaN = map[int]string{32: "0x7fc00000", 64: "0x7ff8000000000000"}
+
+// floatBits returns the width of a resolved float type, 0 for other types
+func floatBits(resolvedType string) int {
+	switch resolvedType {
+	case "float32":
+		return 32
+	case "float64":
+		return 64
+	}
+	return 0
+}
+
+// usesFloats reports whether the layout has float fields, which need package math
+func (g *Generator) usesFloats() bool {
+	fixed := append(append([]analyzer.Region{}, g.analyzed.Regions...), g.analyzed.Trailer...)
+	for _, region := range fixed {
+		if region.Kind == analyzer.FixedRegion && floatBits(g.registry.ResolveType(region.Field.GoType)) > 0 {
+			if _, ok := kindOf(region); !ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// byteOrder returns the encoding/binary byte order of multi-byte values not
+// accessed through typed pointers: endian= in copy and stream mode, native in
+// zerocopy mode like the typed accesses
+func (g *Generator) byteOrder() string {
+	if g.mode == "zerocopy" {
+		return "binary.NativeEndian"
+	}
+	return g.endianPrefix()
+}
+
+// floatStrict reports whether floatpolicy=strict canonicalizes NaNs and refuses
+// infinities
+func (g *Generator) floatStrict() bool {
+	return g.layout != nil && g.layout.Anno != nil && g.layout.Anno.FloatPolicy == "strict"
+}
+
+// floatCodec returns, for a float field of the given type, the conversions of its
+// bits: the expression converting a value to bits and the format converting %s
+// bits back to a value of the field type
+func floatCodec(goType string, bits int) (toBits func(value string) string, fromBits string) {
+	conv := fmt.Sprintf("float%d", bits)
+	toBits = func(value string) string {
+		if goType != conv {
+			value = conv + "(" + value + ")"
+		}
+		return fmt.Sprintf("math.Float%dbits(%s)", bits, value)
+	}
+	fromBits = fmt.Sprintf("math.Float%dfrombits(%%s)", bits)
+	if goType != conv {
+		fromBits = goType + "(" + fromBits + ")"
+	}
+	return toBits, fromBits
+}
+
+// generateFloatOp generates marshal/unmarshal code for a float field, stored as
+// its IEEE 754 bits. Under floatpolicy=strict, marshal writes NaNs as the
+// canonical NaN and refuses infinities, and unmarshal refuses infinities and
+// decodes every NaN as the canonical NaN, so decoded values re-encode to stable
+// bytes.
+func (g *Generator) generateFloatOp(region analyzer.Region, op string) string {
+	var code strings.Builder
+	field := region.Field
+	bits := floatBits(g.registry.ResolveType(field.GoType))
+	toBits, fromBits := floatCodec(field.GoType, bits)
+	bytes := fmt.Sprintf("buf[%d:%d]", region.Start, region.Boundary)
+	if g.mode == "zerocopy" {
+		bytes = "p." + bytes
+	}
+	value := "p." + field.Name
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d)\n", field.Name, field.GoType, region.Start, region.Boundary))
+	if op == "marshal" {
+		if !g.floatStrict() {
+			code.WriteString(fmt.Sprintf("\t%s.PutUint%d(%s, %s)\n\n", g.byteOrder(), bits, bytes, toBits(value)))
+			return code.String()
+		}
+		code.WriteString(g.generateFiniteCheck(field.Name, value, "nil, "))
+		code.WriteString(fmt.Sprintf("\tif math.IsNaN(float64(%s)) {\n", value))
+		code.WriteString(fmt.Sprintf("\t\t%s.PutUint%d(%s, %s) // Canonical NaN\n", g.byteOrder(), bits, bytes, canonicalNaN[bits]))
+		code.WriteString("\t} else {\n")
+		code.WriteString(fmt.Sprintf("\t\t%s.PutUint%d(%s, %s)\n", g.byteOrder(), bits, bytes, toBits(value)))
+		code.WriteString("\t}\n\n")
+		return code.String()
+	}
+
+	code.WriteString(fmt.Sprintf("\t%s = %s\n", value, fmt.Sprintf(fromBits, fmt.Sprintf("%s.Uint%d(%s)", g.byteOrder(), bits, bytes))))
+	if g.floatStrict() {
+		code.WriteString(g.generateFiniteCheck(field.Name, value, ""))
+		code.WriteString(fmt.Sprintf("\tif math.IsNaN(float64(%s)) {\n", value))
+		code.WriteString(fmt.Sprintf("\t\t%s = %s // Canonical NaN\n", value, fmt.Sprintf(fromBits, canonicalNaN[bits])))
+		code.WriteString("\t}\n")
+	}
+	code.WriteString("\n")
+	return code.String()
+}
+
+// generateFiniteCheck generates the floatpolicy=strict check refusing infinite
+// values, with results preceding the error, e.g. "nil, "
+func (g *Generator) generateFiniteCheck(name, value, results string) string {
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\tif math.IsInf(float64(%s), 0) {\n", value))
+	code.WriteString(fmt.Sprintf("\t\treturn %sfmt.Errorf(\"%s: %%v is not finite: %%w\", %s, ErrOutOfRange)\n", results, name, value))
+	code.WriteString("\t}\n")
+	return code.String()
+}
+
+// generateFloatAccessors generates the zerocopy Get/Set of a float field. Under
+// floatpolicy=strict the getter returns NaNs as the canonical NaN, and the setter
+// stores them so and refuses infinities.
+func (g *Generator) generateFloatAccessors(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	typeName := g.analyzed.TypeName
+	bits := floatBits(g.registry.ResolveType(field.GoType))
+	toBits, fromBits := floatCodec(field.GoType, bits)
+	bytes := fmt.Sprintf("p.buf[%d:%d]", region.Start, region.Boundary)
+	load := fmt.Sprintf(fromBits, fmt.Sprintf("%s.Uint%d(%s)", g.byteOrder(), bits, bytes))
+
+	code.WriteString(fmt.Sprintf("// Get%s returns %s at offset %d\n", upperFirst(field.Name), field.GoType, region.Start))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%s() %s {\n", typeName, upperFirst(field.Name), field.GoType))
+	if !g.floatStrict() {
+		code.WriteString(fmt.Sprintf("\treturn %s\n", load))
+		code.WriteString("}\n\n")
+
+		code.WriteString(fmt.Sprintf("// Set%s sets %s at offset %d\n", upperFirst(field.Name), field.GoType, region.Start))
+		code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) {\n", typeName, upperFirst(field.Name), field.GoType))
+		code.WriteString(fmt.Sprintf("\t%s.PutUint%d(%s, %s)\n", g.byteOrder(), bits, bytes, toBits("v")))
+		code.WriteString("}\n\n")
+		return code.String()
+	}
+
+	code.WriteString(fmt.Sprintf("\tv := %s\n", load))
+	code.WriteString("\tif math.IsNaN(float64(v)) {\n")
+	code.WriteString(fmt.Sprintf("\t\treturn %s // Canonical NaN\n", fmt.Sprintf(fromBits, canonicalNaN[bits])))
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn v\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// Set%s sets %s at offset %d, refusing infinities\n", upperFirst(field.Name), field.GoType, region.Start))
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) error {\n", typeName, upperFirst(field.Name), field.GoType))
+	code.WriteString(g.generateFiniteCheck(field.Name, "v", ""))
+	code.WriteString("\tif math.IsNaN(float64(v)) {\n")
+	code.WriteString(fmt.Sprintf("\t\t%s.PutUint%d(%s, %s) // Canonical NaN\n", g.byteOrder(), bits, bytes, canonicalNaN[bits]))
+	code.WriteString("\t\treturn nil\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\t%s.PutUint%d(%s, %s)\n", g.byteOrder(), bits, bytes, toBits("v")))
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateFloat(t *testing.T) {
+	// type Celsius float32
+	//
+	// @layout size=12 endian=big floatpolicy=raw|strict
+	// type Sample struct {
+	//     Value float64 `layout:"@0"`
+	//     Temp  Celsius `layout:"@8"`
+	// }
+	tests := []struct {
+		name, mode, policy string
+		expectedParts      []string
+		unexpectedParts    []string
+	}{
+		{"copy", "copy", "raw", []string{
+			"\tbinary.BigEndian.PutUint64(buf[0:8], math.Float64bits(p.Value))\n",
+			"\tbinary.BigEndian.PutUint32(buf[8:12], math.Float32bits(float32(p.Temp)))\n",
+			"\tp.Value = math.Float64frombits(binary.BigEndian.Uint64(buf[0:8]))\n",
+			"\tp.Temp = Celsius(math.Float32frombits(binary.BigEndian.Uint32(buf[8:12])))\n",
+		}, []string{"IsInf", "IsNaN"}},
+		{"copy strict", "copy", "strict", []string{
+			"\tif math.IsInf(float64(p.Value), 0) {\n\t\treturn nil, fmt.Errorf(\"Value: %v is not finite: %w\", p.Value, ErrOutOfRange)\n\t}\n",
+			"\tif math.IsNaN(float64(p.Value)) {\n\t\tbinary.BigEndian.PutUint64(buf[0:8], 0x7ff8000000000000) // Canonical NaN\n\t} else {\n",
+			"\tif math.IsInf(float64(p.Temp), 0) {\n\t\treturn fmt.Errorf(\"Temp: %v is not finite: %w\", p.Temp, ErrOutOfRange)\n\t}\n",
+			"\t\tp.Temp = Celsius(math.Float32frombits(0x7fc00000)) // Canonical NaN\n",
+		}, nil},
+		{"zerocopy", "zerocopy", "raw", []string{
+			"\tbinary.NativeEndian.PutUint64(p.buf[0:8], math.Float64bits(p.Value))\n",
+			"func (p *Sample) GetTemp() Celsius {\n\treturn Celsius(math.Float32frombits(binary.NativeEndian.Uint32(p.buf[8:12])))\n}\n",
+			"func (p *Sample) SetValue(v float64) {\n\tbinary.NativeEndian.PutUint64(p.buf[0:8], math.Float64bits(v))\n}\n",
+		}, []string{"(*float64)(unsafe.Pointer"}},
+		{"zerocopy strict", "zerocopy", "strict", []string{
+			"\tif math.IsNaN(float64(v)) {\n\t\treturn math.Float64frombits(0x7ff8000000000000) // Canonical NaN\n\t}\n",
+			"func (p *Sample) SetTemp(v Celsius) error {\n\tif math.IsInf(float64(v), 0) {\n",
+			"\t\tbinary.NativeEndian.PutUint32(p.buf[8:12], 0x7fc00000) // Canonical NaN\n\t\treturn nil\n",
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Sample",
+				Anno: &parser.TypeAnnotation{Size: 12, Endian: "big", FloatPolicy: tt.policy},
+				Fields: []parser.Field{
+					{Name: "Value", GoType: "float64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Temp", GoType: "Celsius", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+				},
+			}
+
+			reg := analyzer.NewTypeRegistry()
+			reg.RegisterAlias("Celsius", "float32")
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "big", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			for _, unexpected := range tt.unexpectedParts {
+				if strings.Contains(code, unexpected) {
+					t.Errorf("Generated code contains %q\n\nGenerated:\n%s", unexpected, code)
+				}
+			}
+			for _, path := range []string{"encoding/binary", "math"} {
+				if !slices.Contains(gen.Imports(), path) {
+					t.Errorf("Imports() = %v, missing %s", gen.Imports(), path)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// frameLength returns the length field region of a length-prefixed element type,
+// and an expression decoding it as an int from the element at buf[offset:], in
+// the element's byte order
+func (g *Generator) frameLength(region analyzer.Region) (length analyzer.Region, expr string, ok bool) {
+	var elem *parser.TypeLayout
+	for _, layout := range g.allLayouts {
+		if layout.Name == region.ElementType {
+			elem = layout
+		}
+	}
+	if elem == nil {
+		return analyzer.Region{}, "", false
+	}
+	analyzed, err := analyzer.Analyze(elem, g.registry)
+	if err != nil {
+		return analyzer.Region{}, "", false
+	}
+
+	for _, r := range analyzed.Regions {
+		if r.Field.Name != elem.Anno.Length {
+			continue
+		}
+		if r.Boundary-r.Start == 1 {
+			return r, fmt.Sprintf("int(buf[offset+%d])", r.Start), true
+		}
+		reader := &Generator{registry: g.registry, endian: elem.Anno.Endian}
+		return r, fmt.Sprintf("int(%s.%s(buf[offset+%d : offset+%d]))",
+			reader.endianPrefix(), reader.binaryGetFunc(r.Field.GoType), r.Start, r.Boundary), true
+	}
+	return analyzer.Region{}, "", false
+}
+
+// generateFramedMarshal generates marshal code for a slice of length-prefixed
+// elements, each written as long as it encodes to
+func (g *Generator) generateFramedMarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (length-prefixed elements)\n",
+		field.Name, field.GoType, region.Start, region.Boundary, countField))
+	code.WriteString(fmt.Sprintf("\toffset = %d\n", region.Start))
+	code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
+		field.Name, field.Name, countField))
+	code.WriteString("\t}\n")
+	code.WriteString(generateMaxCheck(field))
+
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\telemBuf, err := p.%s[i].MarshalLayout()\n", field.Name))
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)\n", field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tif offset+len(elemBuf) > %d {\n", region.Boundary))
+	code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset, ErrRegionOverflow)\n", field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tcopy(buf[offset:], elemBuf)\n")
+	code.WriteString("\t\toffset += len(elemBuf)\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// generateFramedUnmarshal generates unmarshal code for a slice of length-prefixed
+// elements: each element's length field gives the bytes it decodes from and where
+// the next one starts. The element's UnmarshalLayout checks the length against
+// its frame bounds; the region bounds are checked here.
+func (g *Generator) generateFramedUnmarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+	boundary := region.Boundary
+
+	length, decode, ok := g.frameLength(region)
+	if !ok {
+		return fmt.Sprintf("\t// %s: length field of %s not found\n\n", field.Name, region.ElementType)
+	}
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (length-prefixed elements)\n",
+		field.Name, field.GoType, region.Start, boundary, countField))
+	code.WriteString(g.generateCountCheck(region))
+	code.WriteString("\t// Reuse slice if capacity allows\n")
+	code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= int(p.%s) {\n", field.Name, countField))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:p.%s]\n", field.Name, field.Name, countField))
+	code.WriteString("\t} else {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = make([]%s, p.%s)\n", field.Name, region.ElementType, countField))
+	code.WriteString("\t}\n")
+
+	code.WriteString(fmt.Sprintf("\toffset := %d\n", region.Start))
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\tif offset+%d > %d {\n", length.Boundary, boundary))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s[%%d]: length at offset %%d outside region: %%w\", i, offset, ErrRegionOverflow)\n", field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tsize := %s\n", decode))
+	if length.Boundary-length.Start >= 4 {
+		// 32-bit ints wrap lengths past 2 GiB negative
+		code.WriteString(fmt.Sprintf("\t\tif size < 0 || size > %d-offset {\n", boundary))
+	} else {
+		code.WriteString(fmt.Sprintf("\t\tif size > %d-offset {\n", boundary))
+	}
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s[%%d]: %%d bytes at offset %%d outside region: %%w\", i, size, offset, ErrRegionOverflow)\n", field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(buf[offset : offset+size]); err != nil {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\toffset += size\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// usesFrameLengths reports whether the code decodes multi-byte element lengths
+func (g *Generator) usesFrameLengths() bool {
+	for _, region := range g.analyzed.Regions {
+		if !region.Framed {
+			continue
+		}
+		if length, _, ok := g.frameLength(region); ok && length.Boundary-length.Start > 1 {
+			return true
+		}
+	}
+	return false
+}
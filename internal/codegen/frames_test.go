@@ -0,0 +1,102 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateFramed(t *testing.T) {
+	// @layout size=64 mode=stream length=Len endian=big
+	// type Record struct {
+	//     Type uint8  `layout:"@0"`
+	//     Len  uint16 `layout:"@1"`
+	//     Data []byte `layout:"start-end"`
+	// }
+	//
+	// @layout size=256
+	// type Log struct {
+	//     N       uint8    `layout:"@0"`
+	//     Records []Record `layout:"start-end,count=N"`
+	// }
+	tests := []struct {
+		name          string
+		lengthType    string
+		expectedParts []string
+		binary        bool
+	}{
+		{"uint16 length", "uint16", []string{
+			// Elements take at least their 3 header bytes each
+			"\tif p.N > 85 {\n\t\treturn fmt.Errorf(\"Records: count %d outside capacity 85: %w\", p.N, ErrRegionOverflow)\n\t}\n",
+			"\t\tif offset+3 > 256 {\n\t\t\treturn fmt.Errorf(\"Records[%d]: length at offset %d outside region: %w\", i, offset, ErrRegionOverflow)\n\t\t}\n",
+			"\t\tsize := int(binary.BigEndian.Uint16(buf[offset+1 : offset+3]))\n\t\tif size > 256-offset {\n",
+		}, true},
+		{"uint8 length", "uint8", []string{
+			"\tif p.N > 127 {\n",
+			"\t\tsize := int(buf[offset+1])\n",
+		}, false},
+		{"uint32 length", "uint32", []string{
+			"\tif p.N > 51 {\n",
+			"\t\tsize := int(binary.BigEndian.Uint32(buf[offset+1 : offset+5]))\n\t\tif size < 0 || size > 256-offset {\n",
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &parser.TypeLayout{
+				Name: "Record",
+				Anno: &parser.TypeAnnotation{Size: 64, Mode: "stream", Length: "Len", Endian: "big"},
+				Fields: []parser.Field{
+					{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Len", GoType: tt.lengthType, Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+					{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+				},
+			}
+			log := &parser.TypeLayout{
+				Name: "Log",
+				Anno: &parser.TypeAnnotation{Size: 256},
+				Fields: []parser.Field{
+					{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Records", GoType: "[]Record", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N"}},
+				},
+			}
+
+			reg := analyzer.NewTypeRegistry()
+			reg.Register("Record", 64)
+			analyzedRecord, err := analyzer.Analyze(record, reg)
+			if err != nil {
+				t.Fatalf("Analyze(Record) error: %v, errors: %v", err, analyzedRecord.Errors)
+			}
+			reg.RegisterFrame("Record", analyzedRecord.MinFrameSize())
+			analyzed, err := analyzer.Analyze(log, reg)
+			if err != nil {
+				t.Fatalf("Analyze(Log) error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, log, []*parser.TypeLayout{log, record}, reg, "little", "copy", 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+
+			expectedParts := append([]string{
+				"\t// Records: []Record at [1, 256) with count=N (length-prefixed elements)\n",
+				// Marshal writes each element as long as it encodes to
+				"\t\tif offset+len(elemBuf) > 256 {\n",
+				"\t\tcopy(buf[offset:], elemBuf)\n\t\toffset += len(elemBuf)\n",
+				"\t\tif err := p.Records[i].UnmarshalLayout(buf[offset : offset+size]); err != nil {\n",
+				"\t\toffset += size\n",
+			}, tt.expectedParts...)
+			for _, expected := range expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if got := slices.Contains(gen.Imports(), "encoding/binary"); got != tt.binary {
+				t.Errorf("imports encoding/binary: %v, want %v", got, tt.binary)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateFuzzHeader generates the package clause and imports of a round-trip
+// fuzz test file, followed by one GenerateFuzzTest per type
+func GenerateFuzzHeader(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import (\n")
+	code.WriteString("\t\"bytes\"\n")
+	code.WriteString("\t\"testing\"\n")
+	code.WriteString(")\n\n")
+
+	return code.String()
+}
+
+// GenerateFuzzTest generates a fuzz test of the round-trip property: a buffer that
+// decodes re-encodes, and the encoding is stable, decoding and re-encoding to the
+// same bytes. Under canonical=true the accepted buffer is itself that encoding,
+// marshal(unmarshal(buf)) == buf. The seeds are the encodings of the golden cases.
+func (g *Generator) GenerateFuzzTest() string {
+	if g.layout.Anno.Raw != "" {
+		return g.rawGenerator().GenerateFuzzTest() // The raw= type has the methods
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// FuzzLayoutRoundTrip%s checks that every buffer %s decodes re-encodes\n", typeName, typeName))
+	code.WriteString("// to byte-stable output. Run with go test -fuzz.\n")
+	code.WriteString(fmt.Sprintf("func FuzzLayoutRoundTrip%s(f *testing.F) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tfor _, value := range []func() *%s{\n", typeName))
+	code.WriteString(fmt.Sprintf("\t\tfunc() *%s { return %s },\n", typeName, g.emptyValue()))
+	for _, fixture := range g.layout.Anno.Fixtures {
+		code.WriteString(fmt.Sprintf("\t\t%s,\n", fixture))
+	}
+	code.WriteString("\t} {\n")
+	code.WriteString("\t\tseed, err := value().MarshalLayout()\n")
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString("\t\t\tf.Fatalf(\"MarshalLayout() error: %v\", err)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tf.Add(bytes.Clone(seed))\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\tf.Fuzz(func(t *testing.T, data []byte) {\n")
+	code.WriteString(fmt.Sprintf("\t\tp := %s\n", g.emptyValue()))
+	code.WriteString("\t\tif err := p.UnmarshalLayout(data); err != nil {\n")
+	code.WriteString("\t\t\treturn\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tout, err := p.MarshalLayout()\n")
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString("\t\t\tt.Fatalf(\"decoded but does not re-encode: %v\", err)\n")
+	code.WriteString("\t\t}\n")
+	if g.layout.Anno.Canonical {
+		code.WriteString("\t\tif !bytes.Equal(out, data) {\n")
+		code.WriteString("\t\t\tt.Fatalf(\"canonical=true accepted a buffer that re-encodes differently\")\n")
+		code.WriteString("\t\t}\n")
+	}
+	code.WriteString(fmt.Sprintf("\t\tq := %s\n", g.emptyValue()))
+	code.WriteString("\t\tif err := q.UnmarshalLayout(out); err != nil {\n")
+	code.WriteString("\t\t\tt.Fatalf(\"re-encoding does not decode: %v\", err)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tagain, err := q.MarshalLayout()\n")
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString("\t\t\tt.Fatalf(\"re-decoded but does not re-encode: %v\", err)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tif !bytes.Equal(again, out) {\n")
+	code.WriteString("\t\t\ti := 0\n")
+	code.WriteString("\t\t\tfor i < min(len(again), len(out)) && again[i] == out[i] {\n")
+	code.WriteString("\t\t\t\ti++\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tt.Fatalf(\"encoding is not stable: differs at byte %d (%d bytes, then %d)\", i, len(out), len(again))\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t})\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
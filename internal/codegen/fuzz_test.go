@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateFuzzTest(t *testing.T) {
+	// @layout size=16 fixtures=fullHeader [canonical=true]
+	// type Header struct {
+	//     Magic uint32 `layout:"@0"`
+	//     N     uint16 `layout:"@4"`
+	//     Body  []byte `layout:"@8,start-end,count=N"`
+	// }
+	tests := []struct {
+		name      string
+		canonical bool
+	}{
+		{"stable", false},
+		{"canonical", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Header",
+				Anno: &parser.TypeAnnotation{Size: 16, Fixtures: []string{"fullHeader"}, Canonical: tt.canonical},
+				Fields: []parser.Field{
+					{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+					{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v", err)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+			code := GenerateFuzzHeader("headers") + gen.GenerateFuzzTest()
+			if _, err := format.Source([]byte(code)); err != nil {
+				t.Fatalf("Generated code does not parse: %v\n\nGenerated:\n%s", err, code)
+			}
+
+			expectedParts := []string{
+				"package headers\n",
+				"func FuzzLayoutRoundTripHeader(f *testing.F) {",
+				"\t\tfunc() *Header { return &Header{} },\n\t\tfullHeader,\n",
+				"\t\tf.Add(bytes.Clone(seed))\n",
+				"\t\tif err := p.UnmarshalLayout(data); err != nil {\n\t\t\treturn\n\t\t}\n",
+				"\t\tif err := q.UnmarshalLayout(out); err != nil {\n",
+				"\t\tif !bytes.Equal(again, out) {\n",
+			}
+			for _, expected := range expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if got := strings.Contains(code, "if !bytes.Equal(out, data) {"); got != tt.canonical {
+				t.Errorf("asserts out == data: %v, want %v\n\nGenerated:\n%s", got, tt.canonical, code)
+			}
+		})
+	}
+}
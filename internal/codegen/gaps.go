@@ -0,0 +1,57 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// freeBytesName returns the name of a shared gap's free-space method: FreeBytes,
+// or <Forward>FreeBytes when the layout has several gaps
+func (g *Generator) freeBytesName(gap analyzer.SharedGap) string {
+	if len(g.analyzed.Gaps) == 1 {
+		return "FreeBytes"
+	}
+	return upperFirst(gap.Forward.Field.Name) + "FreeBytes"
+}
+
+// regionBytes returns an expression for the bytes a dynamic region's slice takes
+func regionBytes(region analyzer.Region) string {
+	if region.ElementStride > 1 {
+		return fmt.Sprintf("len(p.%s)*%d", region.Field.Name, region.ElementStride)
+	}
+	return fmt.Sprintf("len(p.%s)", region.Field.Name)
+}
+
+// generateFreeBytes generates the free-space method of each shared gap: the bytes
+// between the end of the forward region and the start of the backward region
+func (g *Generator) generateFreeBytes() string {
+	var code strings.Builder
+	for _, gap := range g.analyzed.Gaps {
+		fwd, bwd := gap.Forward, gap.Backward
+		code.WriteString("\n")
+		code.WriteString(fmt.Sprintf("// %s returns the bytes left between %s, growing up from %d, and %s, growing\n",
+			g.freeBytesName(gap), fwd.Field.Name, fwd.Start, bwd.Field.Name))
+		code.WriteString(fmt.Sprintf("// down from %d. Negative when they overlap, which MarshalLayout refuses.\n", bwd.Start))
+		code.WriteString(fmt.Sprintf("func (p *%s) %s() int {\n", g.analyzed.TypeName, g.freeBytesName(gap)))
+		code.WriteString(fmt.Sprintf("\treturn (%d - %s) - (%d + %s)\n", bwd.Start, regionBytes(bwd), fwd.Start, regionBytes(fwd)))
+		code.WriteString("}\n")
+	}
+	return code.String()
+}
+
+// generateGapChecks generates the start of MarshalLayout that refuses regions
+// growing into each other, before any of them is written
+func (g *Generator) generateGapChecks() string {
+	var code strings.Builder
+	for _, gap := range g.analyzed.Gaps {
+		fwd, bwd := gap.Forward, gap.Backward
+		code.WriteString(fmt.Sprintf("\t// %s and %s share free space\n", fwd.Field.Name, bwd.Field.Name))
+		code.WriteString(fmt.Sprintf("\tif free := p.%s(); free < 0 {\n", g.freeBytesName(gap)))
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s and %s overlap by %%d bytes: %%w\", -free, ErrRegionOverflow)\n",
+			fwd.Field.Name, bwd.Field.Name))
+		code.WriteString("\t}\n\n")
+	}
+	return code.String()
+}
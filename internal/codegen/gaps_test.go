@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateFreeBytes(t *testing.T) {
+	// @layout size=64
+	// type Slot struct {
+	//     K    uint8  `layout:"@0"`
+	//     Keys []Elem `layout:"start-end,count=K"`
+	//     Data []byte `layout:"end-start"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "A", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Slot",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "K", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Keys", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "K"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{elem, layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Slot) FreeBytes() int {",
+		"return (64 - len(p.Data)) - (1 + len(p.Keys)*8)",
+		"if free := p.FreeBytes(); free < 0 {",
+		`return nil, fmt.Errorf("Keys and Data overlap by %d bytes: %w", -free, ErrRegionOverflow)`,
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// The check runs before either region is written
+	if strings.Index(code, "p.FreeBytes(); free < 0") > strings.Index(code, "// Keys: []Elem") {
+		t.Errorf("Overlap check should precede the regions\n\nGenerated:\n%s", code)
+	}
+}
@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/alexhholmes/layout/internal/analyzer"
@@ -15,9 +16,12 @@ type Generator struct {
 	allLayouts []*parser.TypeLayout // All parsed layouts (for type lookups)
 	registry   *analyzer.TypeRegistry
 	endian     string // "little" or "big"
-	mode       string // "copy" or "zerocopy"
+	mode       string // "copy", "zerocopy" or "stream"
 	align      int    // alignment requirement (0 = none)
 	allocator  string // custom allocator function name (optional)
+	blockSize  int    // filesystem block size direct I/O is checked against (0 = unchecked)
+	target     string // compiler the code must build with (-target), "" for gc
+	blit       bool   // blit=true layout copied as its memory, for hosts of its byte order
 }
 
 // typeEmitter holds marshal/unmarshal code generators for a type
@@ -54,42 +58,139 @@ func NewGenerator(analyzed *analyzer.AnalyzedLayout, layout *parser.TypeLayout,
 	}
 }
 
-// NeedsFmt returns true if zerocopy mode requires fmt package
-func (g *Generator) NeedsFmt() bool {
-	// Custom allocator needs fmt.Sprintf for panic
-	if g.allocator != "" {
-		return true
+// Imports returns the import paths required by this type's generated code
+func (g *Generator) Imports() []string {
+	imports := g.imports()
+	if g.minimal() {
+		return slices.DeleteFunc(imports, func(path string) bool { return path == "fmt" })
 	}
+	return imports
+}
 
-	// Check regions for complex types that need error handling
-	for _, region := range g.analyzed.Regions {
-		resolvedType := g.registry.ResolveType(region.Field.GoType)
-
-		// Struct fields need fmt.Errorf
-		if region.Kind == analyzer.FixedRegion {
-			// Check if it's a struct type (not primitive, not []byte array)
-			if !strings.HasPrefix(resolvedType, "[") &&
-				resolvedType != "uint8" && resolvedType != "int8" && resolvedType != "byte" &&
-				resolvedType != "uint16" && resolvedType != "int16" &&
-				resolvedType != "uint32" && resolvedType != "int32" &&
-				resolvedType != "uint64" && resolvedType != "int64" {
-				return true
+// imports returns the import paths of the code before errors=minimal strips fmt
+func (g *Generator) imports() []string {
+	if g.mode != "zerocopy" {
+		imports := []string{"fmt", "io"} // io for Scan and stream frames
+		if g.usesBinary() {
+			imports = append([]string{"encoding/binary"}, imports...)
+		}
+		if g.layout.Anno.Mirror != "" || g.blit {
+			imports = append(imports, "unsafe") // mirror= offset assertions, blit=true copies
+		}
+		if _, ok := g.splitMetadata(); ok {
+			imports = append(imports, "bytes") // SplitInto and MergeFrom copy entries
+			if g.usesPrefix() {
+				imports = append(imports, "slices") // Insert of prefix-encoded slices
 			}
 		}
+		if g.usesFloats() {
+			imports = append(imports, "math")
+		}
+		if slices.ContainsFunc(g.analyzed.Regions, isCapSlice) {
+			imports = append(imports, "iter") // All iterators of cap= arrays
+		}
+		imports = append(imports, g.headerImports()...)
+		imports = append(imports, g.inlineImports()...)
+		return append(imports, g.kindImports()...)
+	}
 
-		// Dynamic struct slices need fmt.Errorf
+	imports := []string{"encoding/json", "fmt", "io", "unsafe"} // UnmarshalLayout reports overlapping buffers
+	if g.usesFloats() {
+		imports = append(imports, "encoding/binary", "math")
+	}
+	imports = append(imports, g.headerImports()...)
+	for _, region := range g.analyzed.Regions {
 		if region.Kind == analyzer.DynamicRegion && region.ElementType != "byte" && region.ElementType != "" {
-			return true
+			imports = append(imports, "iter") // Iterate/All iterators
+			break
 		}
 	}
+	imports = append(imports, g.inlineImports()...)
+	return append(imports, g.kindImports()...)
+}
 
-	return false
+// usesBinary reports whether copy or stream mode code calls encoding/binary, which
+// only multi-byte primitives packed on byte boundaries do (bit fields shift bytes),
+// and the multi-byte length fields of length-prefixed elements
+func (g *Generator) usesBinary() bool {
+	fixed := append(append([]analyzer.Region{}, g.analyzed.Regions...), g.analyzed.Trailer...)
+	for _, region := range fixed {
+		if region.Kind != analyzer.FixedRegion || region.Bits > 0 || isReserved(region) {
+			continue
+		}
+		if _, ok := kindOf(region); ok {
+			continue // The kind's own imports cover its code
+		}
+		resolved := g.registry.ResolveType(region.Field.GoType)
+		if size, err := analyzer.SizeOf(resolved); err == nil && size >= 2 && !strings.HasPrefix(resolved, "[") {
+			return true
+		}
+		if isCapSlice(region) && region.Boundary-region.Start >= 2*region.Field.Layout.Cap {
+			return true // Multi-byte elements of cap= arrays are encoded one by one
+		}
+	}
+	return g.usesFrameLengths() || g.usesDelta() || g.usesPrefix() || g.usesArrangedIntegers()
 }
 
 // Generate returns the generated code for this type (without package header/imports)
 func (g *Generator) Generate() (string, error) {
+	code, err := g.generate()
+	if err != nil || !g.minimal() {
+		return code, err
+	}
+	return stripErrorf(code), nil
+}
+
+// generate returns the generated code for this type, errors formatted with fmt
+func (g *Generator) generate() (string, error) {
 	var out strings.Builder
 
+	if err := g.checkCanonical(); err != nil {
+		return "", err
+	}
+	if err := g.checkInto(); err != nil {
+		return "", err
+	}
+	if err := g.checkBatch(); err != nil {
+		return "", err
+	}
+	if err := g.checkDirectIO(); err != nil {
+		return "", err
+	}
+	if err := g.checkUnsafe(); err != nil {
+		return "", err
+	}
+	if err := g.checkTarget(); err != nil {
+		return "", err
+	}
+	if err := g.checkReadOnly(); err != nil {
+		return "", err
+	}
+	if err := g.checkRetain(); err != nil {
+		return "", err
+	}
+
+	// The raw= type takes the generated code, buffer included
+	if g.layout.Anno.Raw != "" {
+		return g.generateRaw()
+	}
+
+	// Instances of generic layouts are declared alongside their methods
+	instance := g.generateInstanceType()
+
+	// Stream frames vary in length, so there is no fixed buffer to convert
+	if g.mode == "stream" {
+		header, err := g.generateHeaderView()
+		if err != nil {
+			return "", err
+		}
+		if zeroize := g.generateZeroize(); zeroize != "" {
+			header = "\n" + zeroize + header
+		}
+		stream := g.generateStream() + "\n" + g.generateScan() + g.generateKindMethods()
+		return instance + g.generateOffsetConstants() + g.instrumentMetrics(stream) + header + g.generateByteOrderAssertion(stream), nil
+	}
+
 	// Generate code based on mode
 	if g.mode == "zerocopy" {
 		// Zerocopy mode: generate accessor methods
@@ -100,12 +201,129 @@ func (g *Generator) Generate() (string, error) {
 		marshal := g.GenerateMarshal()
 		out.WriteString(marshal)
 		out.WriteString("\n")
+		if g.marshalInto() {
+			out.WriteString(g.generateMarshalInto())
+			out.WriteString("\n")
+		}
 
 		unmarshal := g.GenerateUnmarshal()
 		out.WriteString(unmarshal)
+		out.WriteString(g.generateFreeBytes())
+		out.WriteString(g.generateRetainedBytes())
+		out.WriteString(g.generateBatch())
+
+		// Range-checked setters for count fields
+		for _, region := range g.analyzed.Regions {
+			if region.Kind == analyzer.FixedRegion {
+				if _, ok := g.countCapacity(region.Field.Name); ok {
+					out.WriteString("\n")
+					out.WriteString(g.generateCountSetter(region))
+				}
+			}
+		}
+
+		// Slot accessors keeping present= bitmaps in step
+		for _, region := range g.analyzed.Regions {
+			if isPresent(region) {
+				out.WriteString(g.generatePresentAccessors(region))
+			}
+		}
+
+		// Append and iterate helpers bounded by the count of cap= arrays
+		for _, region := range g.analyzed.Regions {
+			if isCapSlice(region) {
+				out.WriteString(g.generateCapSliceAccessors(region))
+			}
+		}
+
+		// Single-field writes into encoded buffers
+		out.WriteString(g.generatePatchers())
+
+		// Values spanning pages linked by next=
+		out.WriteString(g.generateChain())
+
+		// B-tree leaf splits and merges of indirect slice entries
+		out.WriteString(g.generateSplitMerge())
+		out.WriteString(g.generatePrefixInserts())
+	}
+
+	// Record scanner for streams of back-to-back layouts
+	out.WriteString("\n")
+	out.WriteString(g.generateScan())
+
+	// Page-addressed I/O for files of back-to-back layouts
+	out.WriteString("\n")
+	out.WriteString(g.generatePageIO())
+
+	// Drop-in replacements for binary.Read and binary.Write of binaryread=true layouts
+	if binaryIO := g.generateBinaryIO(); binaryIO != "" {
+		out.WriteString("\n")
+		out.WriteString(binaryIO)
+	}
+
+	// Pooled values of reuse=true layouts are reset keeping their slices
+	if reset := g.generateReset(); reset != "" {
+		out.WriteString("\n")
+		out.WriteString(reset)
+	}
+
+	// Secrets are wiped from zeroize=true values, buffers and slices included
+	if zeroize := g.generateZeroize(); zeroize != "" {
+		out.WriteString("\n")
+		out.WriteString(zeroize)
+	}
+
+	// Endian conversion works on encoded buffers, independent of mode
+	out.WriteString("\n")
+	out.WriteString(g.generateConvertEndian())
+
+	// Padding-blind comparison of encoded buffers, likewise
+	out.WriteString("\n")
+	out.WriteString(g.generateEqualBuffers())
+
+	// Hex dump for logs, sensitive bytes redacted; it formats with fmt
+	if !g.minimal() {
+		out.WriteString("\n")
+		out.WriteString(g.generateDump())
+	}
+	out.WriteString(g.generateSizeLayout())
+
+	// Accessor-backed JSON, the struct fields of zerocopy types being stale
+	if g.mode == "zerocopy" {
+		out.WriteString("\n")
+		out.WriteString(g.generateJSON())
+	}
+
+	out.WriteString(g.generateExtents())
+	out.WriteString(g.generateKindMethods())
+
+	views, err := g.generateViews()
+	if err != nil {
+		return "", err
+	}
+	out.WriteString(views)
+
+	// The getters only, for code that must not modify shared pages
+	out.WriteString(g.generateReadOnly(out.String()))
+
+	if mirror := g.generateMirrorAssertions(); mirror != "" {
+		out.WriteString("\n")
+		out.WriteString(mirror)
 	}
+	if blit := g.generateBlitAssertions(); blit != "" {
+		out.WriteString("\n")
+		out.WriteString(blit)
+	}
+
+	// The header type has an UnmarshalLayout of its own, left uninstrumented
+	header, err := g.generateHeaderView()
+	if err != nil {
+		return "", err
+	}
+	// Custom byte orders implement binary.ByteOrder, checked at compile time
+	out.WriteString(g.generateByteOrderAssertion(out.String()))
 
-	return out.String(), nil
+	return instance + g.generateOffsetConstants() + g.generateCapacity() + g.instrumentMetrics(out.String()) + header, nil
 }
 
 // GenerateMarshal generates the MarshalLayout method
@@ -118,10 +336,15 @@ func (g *Generator) GenerateMarshal() string {
 
 // generateCopyMarshal generates copy-mode marshal (existing behavior)
 func (g *Generator) generateCopyMarshal() string {
+	if g.blit {
+		return g.generateBlitMarshal()
+	}
+
 	var code strings.Builder
 
 	// Function signature
 	code.WriteString(fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", g.analyzed.TypeName))
+	code.WriteString(g.generateMarshalDefaults())
 	code.WriteString(fmt.Sprintf("\tbuf := make([]byte, %d)\n", g.analyzed.BufferSize))
 
 	// Declare offset only if we have dynamic regions or indirect slices
@@ -144,31 +367,42 @@ func (g *Generator) generateCopyMarshal() string {
 		code.WriteString("\tvar offset int\n")
 	}
 	code.WriteString("\n")
+	code.WriteString(g.generateGapChecks())
+
+	// Metadata read by indirect slices is marshaled once they've stored their
+	// offsets and sizes in it, unless it continues a region= chain
+	deferred := g.deferredMetadata()
 
 	// Generate code for each region
 	for _, region := range g.analyzed.Regions {
 		if region.Kind == analyzer.FixedRegion {
 			code.WriteString(g.generateFixedOp(region, "marshal"))
-		} else {
+		} else if !deferred[region.Field.Name] {
 			code.WriteString(g.generateDynamicMarshal(region))
 		}
 	}
 
 	// Generate indirect slice marshal ([][]byte with metadata indirection)
-	var hasIndirect bool
-	var metadataField string
+	var remarshal []string
 	if g.layout != nil {
 		for _, field := range g.layout.Fields {
 			if field.Layout.From != "" {
 				code.WriteString(g.generateIndirectMarshal(field))
-				hasIndirect = true
-				metadataField = field.Layout.From
+				if !deferred[field.Layout.From] && !slices.Contains(remarshal, field.Layout.From) {
+					remarshal = append(remarshal, field.Layout.From)
+				}
 			}
 		}
 	}
 
-	// Re-marshal metadata Elements after indirect slices have updated offsets
-	if hasIndirect && metadataField != "" {
+	for _, region := range g.analyzed.Regions {
+		if deferred[region.Field.Name] {
+			code.WriteString(g.generateDynamicMarshal(region))
+		}
+	}
+
+	// Chained metadata is marshaled in place and again after the updates
+	for _, metadataField := range remarshal {
 		code.WriteString(fmt.Sprintf("\t// Re-marshal %s after updating offsets\n", metadataField))
 
 		// Find the metadata region
@@ -176,11 +410,9 @@ func (g *Generator) generateCopyMarshal() string {
 			if region.Field.Name == metadataField {
 				code.WriteString(fmt.Sprintf("\toffset = %d\n", region.Start))
 				code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", metadataField))
-				code.WriteString(fmt.Sprintf("\t\telemBuf, err := p.%s[i].MarshalLayout()\n", metadataField))
-				code.WriteString("\t\tif err != nil {\n")
-				code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"remarshal %s[%%d]: %%w\", i, err)\n", metadataField))
-				code.WriteString("\t\t}\n")
-				code.WriteString(fmt.Sprintf("\t\tcopy(buf[offset:offset+%d], elemBuf)\n", region.ElementSize))
+				code.WriteString(g.generateElementMarshal("p."+metadataField+"[i]", region.ElementType,
+					fmt.Sprintf("buf[offset:offset+%d]", region.ElementSize), "\t\t",
+					fmt.Sprintf("return nil, fmt.Errorf(\"remarshal %s[%%d]: %%w\", i, err)", metadataField)))
 				code.WriteString(fmt.Sprintf("\t\toffset += %d\n", region.ElementSize))
 				code.WriteString("\t}\n\n")
 				break
@@ -188,6 +420,7 @@ func (g *Generator) generateCopyMarshal() string {
 		}
 	}
 
+	code.WriteString(g.generateHookOps(g.analyzed.Regions, "marshal"))
 	code.WriteString("\treturn buf, nil\n")
 	code.WriteString("}\n")
 
@@ -203,6 +436,7 @@ func (g *Generator) generateZeroCopyMarshal() string {
 	code.WriteString("\n")
 
 	code.WriteString(fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", g.analyzed.TypeName))
+	code.WriteString(g.generateMarshalDefaults())
 
 	// Generate code for each region, writing to p.buf
 	for _, region := range g.analyzed.Regions {
@@ -212,7 +446,9 @@ func (g *Generator) generateZeroCopyMarshal() string {
 			code.WriteString(g.generateZeroCopyDynamicMarshal(region))
 		}
 	}
+	code.WriteString(g.generateZeroFill())
 
+	code.WriteString(g.generateHookOps(g.analyzed.Regions, "marshal"))
 	code.WriteString("\treturn p.buf[:], nil\n")
 	code.WriteString("}\n")
 
@@ -229,15 +465,21 @@ func (g *Generator) GenerateUnmarshal() string {
 
 // generateCopyUnmarshal generates copy-mode unmarshal (existing behavior)
 func (g *Generator) generateCopyUnmarshal() string {
+	if g.blit {
+		return g.generateBlitUnmarshal()
+	}
+
 	var code strings.Builder
 
 	// Function signature
 	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", g.analyzed.TypeName))
 
 	// Buffer size check
-	code.WriteString(fmt.Sprintf("\tif len(buf) != %d {\n", g.analyzed.BufferSize))
-	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"expected %d bytes, got %%d\", len(buf))\n", g.analyzed.BufferSize))
-	code.WriteString("\t}\n\n")
+	code.WriteString(generateLengthCheck("len(buf)", g.analyzed.BufferSize, g.analyzed.BufferSize, "", ""))
+	code.WriteString("\n")
+	if g.retain() {
+		code.WriteString("\tp.buf = buf // retain=true: kept for the <Field>Bytes accessors\n\n")
+	}
 
 	// Generate code for each region
 	for _, region := range g.analyzed.Regions {
@@ -257,6 +499,8 @@ func (g *Generator) generateCopyUnmarshal() string {
 		}
 	}
 
+	code.WriteString(g.generateHookOps(g.analyzed.Regions, "unmarshal"))
+	code.WriteString(g.generateCanonicalCheck())
 	code.WriteString("\treturn nil\n")
 	code.WriteString("}\n")
 
@@ -269,12 +513,7 @@ func (g *Generator) generateZeroCopyUnmarshal() string {
 
 	// UnmarshalLayout: keep buf parameter for backward compatibility, but use p.buf
 	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", g.analyzed.TypeName))
-	code.WriteString(fmt.Sprintf("\t// Zero-copy mode: copy buf into p.buf if different\n"))
-	code.WriteString("\tif len(buf) > 0 && len(p.buf) > 0 {\n")
-	code.WriteString("\t\tif &buf[0] != &p.buf[0] {\n")
-	code.WriteString("\t\t\tcopy(p.buf, buf)\n")
-	code.WriteString("\t\t}\n")
-	code.WriteString("\t}\n\n")
+	code.WriteString(g.generateBufferIntake())
 
 	// Generate code for each region
 	for _, region := range g.analyzed.Regions {
@@ -294,9 +533,14 @@ func (g *Generator) generateZeroCopyUnmarshal() string {
 		}
 	}
 
+	code.WriteString(g.generateHookOps(g.analyzed.Regions, "unmarshal"))
 	code.WriteString("\treturn nil\n")
 	code.WriteString("}\n\n")
 
+	// Add SetBuffer for slice-backed types
+	code.WriteString(g.generateSetBuffer())
+	code.WriteString(g.generateDirectBuffer())
+
 	// Add LoadFrom helper
 	code.WriteString(g.generateLoadFromHelper())
 
@@ -337,8 +581,8 @@ func (g *Generator) generateNewFunction() string {
 			code.WriteString("\t\n")
 			code.WriteString("\t// Validate buffer size to prevent out-of-bounds access\n")
 			code.WriteString(fmt.Sprintf("\tif len(backing) < %d {\n", requiredSize))
-			code.WriteString(fmt.Sprintf("\t\tpanic(fmt.Sprintf(\"%s returned buffer of %%d bytes, need at least %d\", len(backing)))\n",
-				g.allocator, requiredSize))
+			code.WriteString(g.generatePanic("\t\t", fmt.Sprintf("%s returned buffer of %%d bytes, need at least %d", g.allocator, requiredSize),
+				"len(backing)", fmt.Sprintf("%s returned buffer of fewer than %d bytes", g.allocator, requiredSize)))
 			code.WriteString("\t}\n")
 			code.WriteString("\t\n")
 			code.WriteString(fmt.Sprintf("\t// Find %d-byte aligned offset\n", g.align))
@@ -369,15 +613,11 @@ func (g *Generator) generateNewFunction() string {
 			code.WriteString("\t\n")
 			code.WriteString("\t// Validate buffer size to prevent out-of-bounds access\n")
 			code.WriteString(fmt.Sprintf("\tif len(p.buf) < %d {\n", g.analyzed.BufferSize))
-			code.WriteString(fmt.Sprintf("\t\tpanic(fmt.Sprintf(\"%s returned buffer of %%d bytes, need at least %d\", len(p.buf)))\n",
-				g.allocator, g.analyzed.BufferSize))
+			code.WriteString(g.generatePanic("\t\t", fmt.Sprintf("%s returned buffer of %%d bytes, need at least %d", g.allocator, g.analyzed.BufferSize),
+				"len(p.buf)", fmt.Sprintf("%s returned buffer of fewer than %d bytes", g.allocator, g.analyzed.BufferSize)))
 			code.WriteString("\t}\n")
-		} else {
-			// This shouldn't happen as we only call this function if align > 0 or allocator != ""
-			// But handle it anyway for completeness
-			code.WriteString(fmt.Sprintf("\tp.backing = make([]byte, %d)\n", g.analyzed.BufferSize))
-			code.WriteString(fmt.Sprintf("\tp.buf = p.backing\n"))
 		}
+		// Otherwise buf is an embedded array, there is nothing to allocate
 	}
 
 	// Initialize dynamic []byte fields with len=0, cap=max
@@ -401,41 +641,88 @@ func (g *Generator) generateNewFunction() string {
 		}
 	}
 
+	code.WriteString(g.generateNewDefaults())
+
 	code.WriteString("\treturn p\n")
 	code.WriteString("}\n")
 
 	return code.String()
 }
 
-// generateLoadFromHelper generates LoadFrom and WriteTo helpers for zerocopy mode
+// generateLoadFromHelper generates the I/O helpers for zerocopy mode: ReadFrom
+// and WriteTo, satisfying io.ReaderFrom and io.WriterTo, LoadFromAt for positioned
+// reads, and LoadFrom and SaveTo for callers that only want the error
 func (g *Generator) generateLoadFromHelper() string {
 	var code strings.Builder
+	typeName, size := g.analyzed.TypeName, g.analyzed.BufferSize
+
+	// loadFrom: read one encoding from io.Reader into p.buf, for ReadFrom and LoadFrom
+	code.WriteString(fmt.Sprintf("// loadFrom reads one %d-byte %s from r into the buffer and decodes it. It\n", size, typeName))
+	code.WriteString("// returns io.EOF if r ends before the page, and ErrTruncatedPage if partway\n")
+	code.WriteString("// through it.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) loadFrom(r io.Reader) (int, error) {\n", typeName))
+	code.WriteString("\tn, err := io.ReadFull(r, p.buf[:])\n")
+	code.WriteString("\tif err == io.ErrUnexpectedEOF {\n")
+	code.WriteString(fmt.Sprintf("\t\treturn n, fmt.Errorf(\"ReadFrom: read %%d of %d bytes: %%w\", n, ErrTruncatedPage)\n", size))
+	code.WriteString("\t}\n")
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\treturn n, err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn n, p.UnmarshalLayout(p.buf[:])\n")
+	code.WriteString("}\n\n")
 
-	// Check if buf is array-based (no allocator/alignment) or slice-based
-	isArrayBased := g.align == 0 && g.allocator == ""
+	// ReadFrom: io.ReaderFrom, where reaching EOF is success
+	code.WriteString(fmt.Sprintf("// ReadFrom reads one %d-byte %s from r into the buffer and decodes it.\n", size, typeName))
+	code.WriteString("// It implements io.ReaderFrom, but stops after the page rather than at EOF. As\n")
+	code.WriteString("// io.Copy expects, r ending before the page returns 0, nil and leaves p as it\n")
+	code.WriteString("// was; r ending partway through it fails with ErrTruncatedPage.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) ReadFrom(r io.Reader) (int64, error) {\n", typeName))
+	code.WriteString("\tn, err := p.loadFrom(r)\n")
+	code.WriteString("\tif err == io.EOF {\n")
+	code.WriteString("\t\treturn 0, nil\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn int64(n), err\n")
+	code.WriteString("}\n\n")
 
-	// LoadFrom: read from io.Reader into p.buf
-	code.WriteString(fmt.Sprintf("func (p *%s) LoadFrom(r io.Reader) error {\n", g.analyzed.TypeName))
-	code.WriteString("\tif _, err := io.ReadFull(r, p.buf[:]); err != nil {\n")
+	// LoadFromAt: positioned read, as file-backed pages are
+	code.WriteString(fmt.Sprintf("// LoadFromAt reads the %d-byte %s at offset off of r into the buffer and\n", size, typeName))
+	code.WriteString("// decodes it. It returns io.EOF if off is at or past the end of r, and\n")
+	code.WriteString("// ErrTruncatedPage if r ends partway through the page.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) LoadFromAt(r io.ReaderAt, off int64) error {\n", typeName))
+	code.WriteString("\tn, err := r.ReadAt(p.buf[:], off)\n")
+	code.WriteString(fmt.Sprintf("\tif n < %d {\n", size))
+	code.WriteString("\t\tswitch {\n")
+	code.WriteString("\t\tcase n == 0 && err == io.EOF:\n")
+	code.WriteString("\t\t\treturn io.EOF\n")
+	code.WriteString("\t\tcase err == nil || err == io.EOF:\n")
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"LoadFromAt: read %%d of %d bytes at offset %%d: %%w\", n, off, ErrTruncatedPage)\n", size))
+	code.WriteString("\t\t}\n")
 	code.WriteString("\t\treturn err\n")
 	code.WriteString("\t}\n")
-
-	if isArrayBased {
-		// Array-based: need to use [:] to pass as slice
-		code.WriteString("\treturn p.UnmarshalLayout(p.buf[:])\n")
-	} else {
-		// Slice-based: can pass p.buf directly
-		code.WriteString("\treturn p.UnmarshalLayout(p.buf)\n")
-	}
-
+	code.WriteString("\treturn p.UnmarshalLayout(p.buf[:])\n")
 	code.WriteString("}\n\n")
 
 	// WriteTo: marshal and write p.buf to io.Writer
-	code.WriteString(fmt.Sprintf("func (p *%s) WriteTo(w io.Writer) error {\n", g.analyzed.TypeName))
+	code.WriteString("// WriteTo encodes p into its buffer and writes the buffer to w. It implements\n")
+	code.WriteString("// io.WriterTo.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) WriteTo(w io.Writer) (int64, error) {\n", typeName))
 	code.WriteString("\tif _, err := p.MarshalLayout(); err != nil {\n")
-	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t\treturn 0, err\n")
 	code.WriteString("\t}\n")
-	code.WriteString("\t_, err := w.Write(p.buf[:])\n")
+	code.WriteString("\tn, err := w.Write(p.buf[:])\n")
+	code.WriteString("\treturn int64(n), err\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString("// LoadFrom is ReadFrom without the byte count, returning io.EOF if r ends\n")
+	code.WriteString("// before the page, so a loop over a stream of pages knows where it stops\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) LoadFrom(r io.Reader) error {\n", typeName))
+	code.WriteString("\t_, err := p.loadFrom(r)\n")
+	code.WriteString("\treturn err\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString("// SaveTo is WriteTo without the byte count\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) SaveTo(w io.Writer) error {\n", typeName))
+	code.WriteString("\t_, err := p.WriteTo(w)\n")
 	code.WriteString("\treturn err\n")
 	code.WriteString("}\n")
 
@@ -488,12 +775,11 @@ func (g *Generator) binaryGetFunc(goType string) string {
 	}
 }
 
-// endianPrefix returns "binary.LittleEndian" or "binary.BigEndian"
+// endianPrefix returns the byte order expression of endian=: "binary.LittleEndian",
+// "binary.BigEndian", "layoutPDPEndian{}" or, for the header type of a zerocopy
+// layout, "binary.NativeEndian"
 func (g *Generator) endianPrefix() string {
-	if g.endian == "big" {
-		return "binary.BigEndian"
-	}
-	return "binary.LittleEndian"
+	return g.byteOrderFamily().expr
 }
 
 // emitters returns type-specific code generators based on mode
@@ -854,6 +1140,56 @@ func (g *Generator) generateFixedOp(region analyzer.Region, op string) string {
 	resolvedType := g.registry.ResolveType(field.GoType)
 	needsCast := resolvedType != field.GoType
 
+	// Hooked fields are encoded once the rest of the buffer is
+	if hookDeferred(region, op) {
+		return ""
+	}
+
+	// Aliases are encoded by the fields they overlay
+	if op == "marshal" && isAlias(region) {
+		return generateAliasMarshal(region)
+	}
+
+	// Optional slots are packed by their bitmap
+	if isPresent(region) {
+		return g.generatePresentOp(region, op)
+	}
+
+	// Inline slices encode the elements their count says are in use
+	if isCapSlice(region) {
+		return g.generateCapSliceOp(region, op)
+	}
+
+	// Kinds encode the field their own way
+	if k, ok := kindOf(region); ok {
+		return g.generateKindOp(region, k, op)
+	}
+
+	// Magics are decoded as plain fields, then checked
+	if op == "unmarshal" && field.Layout != nil && field.Layout.Magic {
+		return g.generateMagicOp(region)
+	}
+
+	// Reserved ranges hold no data
+	if isReserved(region) {
+		return g.generateReservedOp(region, op)
+	}
+
+	// Bit fields are shifted and masked in place
+	if region.Bits > 0 {
+		return g.generateBitOp(region, op)
+	}
+
+	// Floats are stored as their bits, subject to floatpolicy=
+	if floatBits(resolvedType) > 0 {
+		return g.generateFloatOp(region, op)
+	}
+
+	// Misaligned zerocopy fields are copied bytewise
+	if g.mode == "zerocopy" && region.Misaligned {
+		return g.generateUnalignedOp(region, op)
+	}
+
 	// Try primitive emitter first
 	emitter, ok := g.emitters()[resolvedType]
 	if ok {
@@ -908,15 +1244,13 @@ func (g *Generator) generateComplexFixedOp(region analyzer.Region, op string) st
 
 	// Struct types
 	if op == "marshal" {
-		code.WriteString(fmt.Sprintf("\telemBuf, err := p.%s.MarshalLayout()\n", field.Name))
-		code.WriteString("\tif err != nil {\n")
-		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"marshal %s: %%w\", err)\n", field.Name))
-		code.WriteString("\t}\n")
+		dst := fmt.Sprintf("buf[%d:%d]", start, end)
 		if g.mode == "zerocopy" {
-			code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], elemBuf)\n\n", start, end))
-		} else {
-			code.WriteString(fmt.Sprintf("\tcopy(buf[%d:%d], elemBuf)\n\n", start, end))
+			dst = "p." + dst
 		}
+		code.WriteString(g.generateElementMarshal("p."+field.Name, field.GoType, dst, "\t",
+			fmt.Sprintf("return nil, fmt.Errorf(\"marshal %s: %%w\", err)", field.Name)))
+		code.WriteString("\n")
 	} else {
 		if g.mode == "zerocopy" {
 			code.WriteString(fmt.Sprintf("\tif err := p.%s.UnmarshalLayout(p.buf[%d:%d]); err != nil {\n", field.Name, start, end))
@@ -932,6 +1266,12 @@ func (g *Generator) generateComplexFixedOp(region analyzer.Region, op string) st
 
 // generateDynamicMarshal generates marshal code for a dynamic field
 func (g *Generator) generateDynamicMarshal(region analyzer.Region) string {
+	if len(region.Members) > 0 {
+		return g.generateArrangedMarshal(region)
+	}
+	if isDelta(region) {
+		return g.generateDeltaMarshal(region)
+	}
 	// Check element type to determine marshal strategy
 	if region.ElementType == "byte" {
 		return g.generateByteMarshal(region)
@@ -958,25 +1298,25 @@ func (g *Generator) generateByteMarshal(region analyzer.Region) string {
 	}
 
 	if region.Direction == parser.StartEnd {
-		// Forward growth
-		code.WriteString(fmt.Sprintf("\toffset = %d\n", start))
+		// Forward growth; later regions of a region= chain continue from the previous
+		if region.Chain == 0 {
+			code.WriteString(fmt.Sprintf("\toffset = %d\n", start))
+		}
 
 		// Count validation if count field exists
 		if countField != "" {
 			code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
-			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d\", len(p.%s), p.%s)\n",
+			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
 				field.Name, field.Name, countField))
 			code.WriteString("\t}\n")
+			code.WriteString(generateMaxCheck(field))
 		}
 
-		// Marshal loop
-		code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
-		code.WriteString(fmt.Sprintf("\t\tif offset >= %d {\n", boundary))
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d\", offset)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\tbuf[offset] = p.%s[i]\n", field.Name))
-		code.WriteString("\t\toffset++\n")
-		code.WriteString("\t}\n\n")
+		// Marshal with one copy, once the bytes are known to fit
+		code.WriteString(fmt.Sprintf("\tif offset+len(p.%s) > %d {\n", field.Name, boundary))
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset, ErrRegionOverflow)\n", field.Name))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\toffset += copy(buf[offset:], p.%s)\n\n", field.Name))
 	} else {
 		// Backward growth (end-start)
 		code.WriteString(fmt.Sprintf("\toffset = %d\n", start))
@@ -984,19 +1324,18 @@ func (g *Generator) generateByteMarshal(region analyzer.Region) string {
 		// Count validation if count field exists
 		if countField != "" {
 			code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
-			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d\", len(p.%s), p.%s)\n",
+			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
 				field.Name, field.Name, countField))
 			code.WriteString("\t}\n")
+			code.WriteString(generateMaxCheck(field))
 		}
 
-		// Marshal backward
-		code.WriteString(fmt.Sprintf("\tfor i := len(p.%s) - 1; i >= 0; i-- {\n", field.Name))
-		code.WriteString("\t\toffset--\n")
-		code.WriteString(fmt.Sprintf("\t\tif offset < %d {\n", boundary))
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d\", offset)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\tbuf[offset] = p.%s[i]\n", field.Name))
-		code.WriteString("\t}\n\n")
+		// Marshal backward: the bytes end at offset, in order
+		code.WriteString(fmt.Sprintf("\tif offset-len(p.%s) < %d {\n", field.Name, boundary))
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset-len(p.%s), ErrRegionOverflow)\n", field.Name, field.Name))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\toffset -= len(p.%s)\n", field.Name))
+		code.WriteString(fmt.Sprintf("\tcopy(buf[offset:], p.%s)\n\n", field.Name))
 	}
 
 	return code.String()
@@ -1004,6 +1343,10 @@ func (g *Generator) generateByteMarshal(region analyzer.Region) string {
 
 // generateStructMarshal generates element-by-element marshal for []StructType
 func (g *Generator) generateStructMarshal(region analyzer.Region) string {
+	if region.Framed {
+		return g.generateFramedMarshal(region)
+	}
+
 	var code strings.Builder
 
 	field := region.Field
@@ -1011,40 +1354,49 @@ func (g *Generator) generateStructMarshal(region analyzer.Region) string {
 	boundary := region.Boundary
 	countField := field.Layout.CountField
 	elementSize := region.ElementSize
+	stride := region.ElementStride
 
 	// Comment
 	if countField != "" {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, countField, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (%s)\n",
+			field.Name, field.GoType, start, boundary, countField, elementNote(region)))
 	} else {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (%s)\n",
+			field.Name, field.GoType, start, boundary, elementNote(region)))
 	}
 
 	if region.Direction == parser.StartEnd {
-		// Forward growth
-		code.WriteString(fmt.Sprintf("\toffset = %d\n", start))
+		// Forward growth; later regions of a region= chain continue from the previous
+		if region.Chain == 0 {
+			code.WriteString(fmt.Sprintf("\toffset = %d\n", start))
+		}
 
 		// Count validation if count field exists
 		if countField != "" {
 			code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
-			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d\", len(p.%s), p.%s)\n",
+			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
 				field.Name, field.Name, countField))
 			code.WriteString("\t}\n")
+			code.WriteString(generateMaxCheck(field))
 		}
 
 		// Marshal loop for structs
 		code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
 		code.WriteString(fmt.Sprintf("\t\tif offset + %d > %d {\n", elementSize, boundary))
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d\", offset)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\telemBuf, err := p.%s[i].MarshalLayout()\n", field.Name))
-		code.WriteString("\t\tif err != nil {\n")
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)\n", field.Name))
+		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset, ErrRegionOverflow)\n", field.Name))
 		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\tcopy(buf[offset:offset+%d], elemBuf)\n", elementSize))
-		code.WriteString(fmt.Sprintf("\t\toffset += %d\n", elementSize))
+		if field.Layout.Sentinel {
+			// Marshaled after the indirect slices stored offsets and sizes
+			code.WriteString(g.generateSentinelCheck(field.Name))
+		}
+		code.WriteString(g.generateElementMarshal("p."+field.Name+"[i]", region.ElementType,
+			fmt.Sprintf("buf[offset:offset+%d]", elementSize), "\t\t",
+			fmt.Sprintf("return nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)", field.Name)))
+		code.WriteString(fmt.Sprintf("\t\toffset += %d\n", stride))
 		code.WriteString("\t}\n\n")
+		if field.Layout.Sentinel {
+			code.WriteString(generateSentinelEnd(region))
+		}
 	} else {
 		// Backward growth (end-start)
 		code.WriteString(fmt.Sprintf("\toffset = %d\n", start))
@@ -1052,22 +1404,21 @@ func (g *Generator) generateStructMarshal(region analyzer.Region) string {
 		// Count validation if count field exists
 		if countField != "" {
 			code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
-			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d\", len(p.%s), p.%s)\n",
+			code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
 				field.Name, field.Name, countField))
 			code.WriteString("\t}\n")
+			code.WriteString(generateMaxCheck(field))
 		}
 
 		// Marshal backward for structs
 		code.WriteString(fmt.Sprintf("\tfor i := len(p.%s) - 1; i >= 0; i-- {\n", field.Name))
-		code.WriteString(fmt.Sprintf("\t\toffset -= %d\n", elementSize))
+		code.WriteString(fmt.Sprintf("\t\toffset -= %d\n", stride))
 		code.WriteString(fmt.Sprintf("\t\tif offset < %d {\n", boundary))
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d\", offset)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\telemBuf, err := p.%s[i].MarshalLayout()\n", field.Name))
-		code.WriteString("\t\tif err != nil {\n")
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)\n", field.Name))
+		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", offset, ErrRegionOverflow)\n", field.Name))
 		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\tcopy(buf[offset:offset+%d], elemBuf)\n", elementSize))
+		code.WriteString(g.generateElementMarshal("p."+field.Name+"[i]", region.ElementType,
+			fmt.Sprintf("buf[offset:offset+%d]", elementSize), "\t\t",
+			fmt.Sprintf("return nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)", field.Name)))
 		code.WriteString("\t}\n\n")
 	}
 
@@ -1076,6 +1427,15 @@ func (g *Generator) generateStructMarshal(region analyzer.Region) string {
 
 // generateDynamicUnmarshal generates unmarshal code for a dynamic field
 func (g *Generator) generateDynamicUnmarshal(region analyzer.Region) string {
+	if region.Field.Layout.Group != "" {
+		return g.generateChainUnmarshal(region)
+	}
+	if len(region.Members) > 0 {
+		return g.generateArrangedUnmarshal(region)
+	}
+	if isDelta(region) {
+		return g.generateDeltaUnmarshal(region)
+	}
 	// Check element type to determine unmarshal strategy
 	if region.ElementType == "byte" {
 		return g.generateByteUnmarshal(region)
@@ -1104,6 +1464,15 @@ func (g *Generator) generateByteUnmarshal(region analyzer.Region) string {
 	// Calculate length
 	if countField != "" {
 		// Explicit count
+		code.WriteString(g.generateCountCheck(region))
+		if g.retain() {
+			if region.Direction == parser.StartEnd {
+				code.WriteString(retainedByteUnmarshal(field, fmt.Sprint(start), fmt.Sprintf("%d+int(p.%s)", start, countField)))
+			} else {
+				code.WriteString(retainedByteUnmarshal(field, fmt.Sprintf("%d-int(p.%s)", start, countField), fmt.Sprint(start)))
+			}
+			return code.String()
+		}
 		code.WriteString(fmt.Sprintf("\t// Reuse buffer if capacity allows\n"))
 		code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= int(p.%s) {\n", field.Name, countField))
 		code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:p.%s]\n", field.Name, field.Name, countField))
@@ -1115,11 +1484,16 @@ func (g *Generator) generateByteUnmarshal(region analyzer.Region) string {
 			code.WriteString(fmt.Sprintf("\tcopy(p.%s, buf[%d:%d+p.%s])\n\n", field.Name, start, start, countField))
 		} else {
 			// Backward: copy from (start - count) to start
-			code.WriteString(fmt.Sprintf("\tcopy(p.%s, buf[%d-p.%s:%d])\n\n", field.Name, start, countField, start))
+			code.WriteString(fmt.Sprintf("\tcopy(p.%s, buf[%d-int(p.%s):%d])\n\n", field.Name, start, countField, start))
 		}
 	} else {
 		// Implicit length from boundaries
-		lenVar := fmt.Sprintf("%sLen", strings.ToLower(string(field.Name[0])))
+		if g.retain() {
+			low, high := g.regionSpan(region)
+			code.WriteString(retainedByteUnmarshal(field, fmt.Sprint(low), fmt.Sprint(high)))
+			return code.String()
+		}
+		lenVar := g.localName(field.Name, "Len")
 		if region.Direction == parser.StartEnd {
 			code.WriteString(fmt.Sprintf("\t%s := %d - %d\n", lenVar, boundary, start))
 		} else {
@@ -1145,6 +1519,13 @@ func (g *Generator) generateByteUnmarshal(region analyzer.Region) string {
 
 // generateStructUnmarshal generates element-by-element unmarshal for []StructType
 func (g *Generator) generateStructUnmarshal(region analyzer.Region) string {
+	if region.Field.Layout.Sentinel {
+		return g.generateSentinelUnmarshal(region)
+	}
+	if region.Framed {
+		return g.generateFramedUnmarshal(region)
+	}
+
 	var code strings.Builder
 
 	field := region.Field
@@ -1152,20 +1533,22 @@ func (g *Generator) generateStructUnmarshal(region analyzer.Region) string {
 	boundary := region.Boundary
 	countField := field.Layout.CountField
 	elementSize := region.ElementSize
+	stride := region.ElementStride
 	elementType := region.ElementType
 
 	// Comment
 	if countField != "" {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, countField, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (%s)\n",
+			field.Name, field.GoType, start, boundary, countField, elementNote(region)))
 	} else {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (%s)\n",
+			field.Name, field.GoType, start, boundary, elementNote(region)))
 	}
 
 	// Calculate number of elements
 	if countField != "" {
 		// Explicit count
+		code.WriteString(g.generateCountCheck(region))
 		code.WriteString(fmt.Sprintf("\t// Reuse slice if capacity allows\n"))
 		code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= int(p.%s) {\n", field.Name, countField))
 		code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:p.%s]\n", field.Name, field.Name, countField))
@@ -1174,12 +1557,12 @@ func (g *Generator) generateStructUnmarshal(region analyzer.Region) string {
 		code.WriteString("\t}\n")
 	} else {
 		// Implicit count from region size
-		numElements := (boundary - start) / elementSize
+		numElements := (boundary - start) / stride
 		if region.Direction == parser.EndStart {
-			numElements = (start - boundary) / elementSize
+			numElements = (start - boundary) / stride
 		}
 		code.WriteString(fmt.Sprintf("\tnumElements := %d // (%d bytes / %d bytes per element)\n",
-			numElements, abs(boundary-start), elementSize))
+			numElements, abs(boundary-start), stride))
 		code.WriteString(fmt.Sprintf("\t// Reuse slice if capacity allows\n"))
 		code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= numElements {\n", field.Name))
 		code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:numElements]\n", field.Name, field.Name))
@@ -1188,30 +1571,44 @@ func (g *Generator) generateStructUnmarshal(region analyzer.Region) string {
 		code.WriteString("\t}\n")
 	}
 
-	// Unmarshal loop
-	code.WriteString(fmt.Sprintf("\toffset := %d\n", start))
+	// Unmarshal loop. Backward regions were packed so elements ascend in memory
+	// and end at the region start; read them forward from the lowest element.
+	// Each element's offset is computed in the loop, so several regions can be
+	// unmarshaled in one function.
 	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
-
 	if region.Direction == parser.StartEnd {
-		code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(buf[offset:offset+%d]); err != nil {\n",
-			field.Name, elementSize))
-		code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\toffset += %d\n", elementSize))
+		code.WriteString(fmt.Sprintf("\t\tat := %d + i*%d\n", start, stride))
 	} else {
-		// Backward
-		code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(buf[offset-%d:offset]); err != nil {\n",
-			field.Name, elementSize))
-		code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\toffset -= %d\n", elementSize))
+		code.WriteString(fmt.Sprintf("\t\tat := %d - (len(p.%s)-i)*%d\n", start, field.Name, stride))
 	}
-
+	code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(buf[at:at+%d]); err != nil {\n",
+		field.Name, elementSize))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
+	code.WriteString("\t\t}\n")
 	code.WriteString("\t}\n\n")
 
 	return code.String()
 }
 
+// elementNote describes the elements of a struct slice region for generated comments
+func elementNote(region analyzer.Region) string {
+	if region.ElementStride != region.ElementSize {
+		return fmt.Sprintf("element size: %d, stride: %d", region.ElementSize, region.ElementStride)
+	}
+	return fmt.Sprintf("element size: %d", region.ElementSize)
+}
+
+// clearSlack returns a statement zeroing the bytes between the end of the
+// element at offset and the start of the next, the slack stride= and elemalign=
+// leave, or nothing for packed elements. Writes in place into p.buf need it,
+// fresh buffers are zero already.
+func clearSlack(region analyzer.Region, offset, indent string) string {
+	if region.ElementStride <= region.ElementSize {
+		return ""
+	}
+	return fmt.Sprintf("%sclear(p.buf[%s+%d : %s+%d])\n", indent, offset, region.ElementSize, offset, region.ElementStride)
+}
+
 func abs(x int) int {
 	if x < 0 {
 		return -x
@@ -1260,12 +1657,13 @@ func (g *Generator) generateZeroCopyDynamicUnmarshal(region analyzer.Region) str
 		// Slice directly into buffer
 		if countField != "" {
 			// Count-dependent slicing
+			code.WriteString(g.generateCountCheck(region))
 			if region.Direction == parser.StartEnd {
 				// Forward: slice from start with count
 				code.WriteString(fmt.Sprintf("\tp.%s = p.buf[%d:%d+p.%s]\n\n", field.Name, start, start, countField))
 			} else {
 				// Backward: slice from (start - count) to start
-				code.WriteString(fmt.Sprintf("\tp.%s = p.buf[%d-p.%s:%d]\n\n", field.Name, start, countField, start))
+				code.WriteString(fmt.Sprintf("\tp.%s = p.buf[%d-int(p.%s):%d]\n\n", field.Name, start, countField, start))
 			}
 		} else {
 			// Implicit length from boundaries
@@ -1280,20 +1678,22 @@ func (g *Generator) generateZeroCopyDynamicUnmarshal(region analyzer.Region) str
 
 	// Handle struct slices - need to unmarshal each element
 	elementSize := region.ElementSize
+	stride := region.ElementStride
 	elementType := region.ElementType
 
 	// Comment
 	if countField != "" {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, countField, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (%s)\n",
+			field.Name, field.GoType, start, boundary, countField, elementNote(region)))
 	} else {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (%s)\n",
+			field.Name, field.GoType, start, boundary, elementNote(region)))
 	}
 
 	// Calculate number of elements
 	if countField != "" {
 		// Explicit count
+		code.WriteString(g.generateCountCheck(region))
 		code.WriteString(fmt.Sprintf("\t// Reuse slice if capacity allows\n"))
 		code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= int(p.%s) {\n", field.Name, countField))
 		code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:p.%s]\n", field.Name, field.Name, countField))
@@ -1302,12 +1702,12 @@ func (g *Generator) generateZeroCopyDynamicUnmarshal(region analyzer.Region) str
 		code.WriteString("\t}\n")
 	} else {
 		// Implicit count from region size
-		numElements := (boundary - start) / elementSize
+		numElements := (boundary - start) / stride
 		if region.Direction == parser.EndStart {
-			numElements = (start - boundary) / elementSize
+			numElements = (start - boundary) / stride
 		}
 		code.WriteString(fmt.Sprintf("\tnumElements := %d // (%d bytes / %d bytes per element)\n",
-			numElements, abs(boundary-start), elementSize))
+			numElements, abs(boundary-start), stride))
 		code.WriteString(fmt.Sprintf("\t// Reuse slice if capacity allows\n"))
 		code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= numElements {\n", field.Name))
 		code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:numElements]\n", field.Name, field.Name))
@@ -1316,25 +1716,20 @@ func (g *Generator) generateZeroCopyDynamicUnmarshal(region analyzer.Region) str
 		code.WriteString("\t}\n")
 	}
 
-	// Unmarshal loop
-	code.WriteString(fmt.Sprintf("\toffset := %d\n", start))
+	// Unmarshal loop. Backward regions were packed so elements ascend in memory
+	// and end at the region start; read them forward from the lowest element.
+	// Each element's offset is computed in the loop, so several regions can be
+	// unmarshaled in one function.
 	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
-
 	if region.Direction == parser.StartEnd {
-		code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(p.buf[offset:offset+%d]); err != nil {\n",
-			field.Name, elementSize))
-		code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\toffset += %d\n", elementSize))
+		code.WriteString(fmt.Sprintf("\t\tat := %d + i*%d\n", start, stride))
 	} else {
-		// Backward
-		code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(p.buf[offset-%d:offset]); err != nil {\n",
-			field.Name, elementSize))
-		code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\toffset -= %d\n", elementSize))
+		code.WriteString(fmt.Sprintf("\t\tat := %d - (len(p.%s)-i)*%d\n", start, field.Name, stride))
 	}
-
+	code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(p.buf[at:at+%d]); err != nil {\n",
+		field.Name, elementSize))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
+	code.WriteString("\t\t}\n")
 	code.WriteString("\t}\n\n")
 
 	return code.String()
@@ -1365,52 +1760,51 @@ func (g *Generator) generateZeroCopyDynamicMarshal(region analyzer.Region) strin
 
 	// Handle struct slices - need to marshal each element
 	elementSize := region.ElementSize
+	stride := region.ElementStride
 
 	// Comment
 	if countField != "" {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, countField, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) with count=%s (%s)\n",
+			field.Name, field.GoType, start, boundary, countField, elementNote(region)))
 	} else {
-		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (element size: %d)\n",
-			field.Name, field.GoType, start, boundary, elementSize))
+		code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) (%s)\n",
+			field.Name, field.GoType, start, boundary, elementNote(region)))
 	}
 
 	// Count validation if count field exists
 	if countField != "" {
 		code.WriteString(fmt.Sprintf("\tif len(p.%s) != int(p.%s) {\n", field.Name, countField))
-		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d\", len(p.%s), p.%s)\n",
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s length mismatch: have %%d, want %%d: %%w\", len(p.%s), p.%s, ErrCountMismatch)\n",
 			field.Name, field.Name, countField))
 		code.WriteString("\t}\n")
+		code.WriteString(generateMaxCheck(field))
 	}
 
-	// Marshal loop for structs
+	// Marshal loop for structs, computing each element's offset so several
+	// regions can be marshaled in one function
 	if region.Direction == parser.StartEnd {
 		// Forward growth
-		code.WriteString(fmt.Sprintf("\toffset := %d\n", start))
 		code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
-		code.WriteString(fmt.Sprintf("\t\tif offset + %d > %d {\n", elementSize, boundary))
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d\", offset)\n", field.Name))
+		code.WriteString(fmt.Sprintf("\t\tat := %d + i*%d\n", start, stride))
+		code.WriteString(fmt.Sprintf("\t\tif at + %d > %d {\n", elementSize, boundary))
+		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", at, ErrRegionOverflow)\n", field.Name))
 		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\telemBuf, err := p.%s[i].MarshalLayout()\n", field.Name))
-		code.WriteString("\t\tif err != nil {\n")
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\tcopy(p.buf[offset:offset+%d], elemBuf)\n", elementSize))
-		code.WriteString(fmt.Sprintf("\t\toffset += %d\n", elementSize))
+		code.WriteString(g.generateElementMarshal("p."+field.Name+"[i]", region.ElementType,
+			fmt.Sprintf("p.buf[at:at+%d]", elementSize), "\t\t",
+			fmt.Sprintf("return nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)", field.Name)))
+		code.WriteString(clearSlack(region, "at", "\t\t"))
 		code.WriteString("\t}\n\n")
 	} else {
 		// Backward growth
-		code.WriteString(fmt.Sprintf("\toffset := %d\n", start))
 		code.WriteString(fmt.Sprintf("\tfor i := len(p.%s) - 1; i >= 0; i-- {\n", field.Name))
-		code.WriteString(fmt.Sprintf("\t\toffset -= %d\n", elementSize))
-		code.WriteString(fmt.Sprintf("\t\tif offset < %d {\n", boundary))
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d\", offset)\n", field.Name))
-		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\telemBuf, err := p.%s[i].MarshalLayout()\n", field.Name))
-		code.WriteString("\t\tif err != nil {\n")
-		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)\n", field.Name))
+		code.WriteString(fmt.Sprintf("\t\tat := %d - (len(p.%s)-i)*%d\n", start, field.Name, stride))
+		code.WriteString(fmt.Sprintf("\t\tif at < %d {\n", boundary))
+		code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s collision at offset %%d: %%w\", at, ErrRegionOverflow)\n", field.Name))
 		code.WriteString("\t\t}\n")
-		code.WriteString(fmt.Sprintf("\t\tcopy(p.buf[offset:offset+%d], elemBuf)\n", elementSize))
+		code.WriteString(g.generateElementMarshal("p."+field.Name+"[i]", region.ElementType,
+			fmt.Sprintf("p.buf[at:at+%d]", elementSize), "\t\t",
+			fmt.Sprintf("return nil, fmt.Errorf(\"marshal %s[%%d]: %%w\", i, err)", field.Name)))
+		code.WriteString(clearSlack(region, "at", "\t\t"))
 		code.WriteString("\t}\n\n")
 	}
 
@@ -1450,8 +1844,8 @@ func (g *Generator) generateIndirectUnmarshal(field parser.Field) string {
 				   region.ElementType != "byte" &&
 				   region.Field.Name == field.Layout.From {
 					code.WriteString(fmt.Sprintf("\t// Initialize %s data region after metadata\n", field.Layout.Region))
-					code.WriteString(fmt.Sprintf("\telementsEnd := %d + int(p.%s)*%d\n",
-						region.Start, region.Field.Layout.CountField, region.ElementSize))
+					code.WriteString(fmt.Sprintf("\telementsEnd := %d + %s*%d\n",
+						region.Start, metadataEntries(region, ""), region.ElementSize))
 
 					// Use appropriate buffer reference based on mode
 					if g.mode == "zerocopy" {
@@ -1478,20 +1872,42 @@ func (g *Generator) generateIndirectUnmarshal(field parser.Field) string {
 	code.WriteString(fmt.Sprintf("\t\toffset := int(p.%s[i].%s)\n", field.Layout.From, field.Layout.OffsetField))
 	code.WriteString(fmt.Sprintf("\t\tsize := int(p.%s[i].%s)\n", field.Layout.From, field.Layout.SizeField))
 
-	// Handle absolute vs relative offset mode
-	if field.Layout.OffsetMode == "absolute" {
-		code.WriteString("\t\t// Offset is absolute from page start, adjust to region-relative\n")
-		code.WriteString("\t\tregionOffset := offset - elementsEnd\n")
-		code.WriteString(fmt.Sprintf("\t\tp.%s[i] = p.%s[regionOffset:regionOffset+size]\n", field.Name, field.Layout.Region))
+	// Slotted pages mark free slots with a zero offset
+	if g.layout != nil && g.layout.Anno != nil && g.layout.Anno.Slotted {
+		code.WriteString("\t\tif offset == 0 {\n")
+		code.WriteString(fmt.Sprintf("\t\t\tp.%s[i] = nil\n", field.Name))
+		code.WriteString("\t\t\tcontinue\n")
+		code.WriteString("\t\t}\n")
+	}
+
+	// The data region slice starts at elementsEnd; offsets counting from the page or
+	// the region start are rebased onto it
+	if base := g.indirectBase(field); base != "elementsEnd" {
+		code.WriteString(fmt.Sprintf("\t\t// Offset counts from %s, rebase onto %s\n", offsetOrigin(field), field.Layout.Region))
+		code.WriteString(fmt.Sprintf("\t\tregionOffset := %s - elementsEnd\n", g.bufferOffset(field, "offset")))
+		code.WriteString(g.generateIndirectCheck(field, "regionOffset"))
+		code.WriteString(g.indirectEntry(field, "regionOffset"))
 	} else {
 		// Default: relative mode (backwards compatible)
-		code.WriteString(fmt.Sprintf("\t\tp.%s[i] = p.%s[offset:offset+size]\n", field.Name, field.Layout.Region))
+		code.WriteString(g.generateIndirectCheck(field, "offset"))
+		code.WriteString(g.indirectEntry(field, "offset"))
 	}
 	code.WriteString("\t}\n\n")
 
 	return code.String()
 }
 
+// indirectEntry returns the statements setting entry i of an indirect slice from
+// the size bytes at start of its data region: a subslice, or for encode=prefix
+// the entry rebuilt from the stored bytes
+func (g *Generator) indirectEntry(field parser.Field, start string) string {
+	stored := fmt.Sprintf("p.%s[%s:%s+size]", field.Layout.Region, start, start)
+	if isPrefix(field) {
+		return g.generatePrefixEntry(field, stored)
+	}
+	return fmt.Sprintf("\t\tp.%s[i] = %s\n", field.Name, stored)
+}
+
 // getMetadataFieldType looks up the type of a field in the metadata struct
 func (g *Generator) getMetadataFieldType(fromField, fieldName string) string {
 	// Find the source field in current layout
@@ -1524,13 +1940,122 @@ func (g *Generator) getMetadataFieldType(fromField, fieldName string) string {
 	return "uint32" // fallback if not found
 }
 
+// countFieldType resolves the Go type of a count field, following nested
+// references (e.g., "Header.Meta.NumKeys") through the parsed layouts
+func (g *Generator) countFieldType(countField string) string {
+	parts := strings.Split(countField, ".")
+	if g.layout == nil {
+		return "int"
+	}
+
+	goType := ""
+	for _, f := range g.layout.Fields {
+		if f.Name == parts[0] {
+			goType = f.GoType
+			break
+		}
+	}
+
+	for _, name := range parts[1:] {
+		next := ""
+		for _, layout := range g.allLayouts {
+			if layout.Name != goType {
+				continue
+			}
+			for _, f := range layout.Fields {
+				if f.Name == name {
+					next = f.GoType
+					break
+				}
+			}
+		}
+		goType = next
+	}
+
+	if goType == "" {
+		return "int" // fallback if not found
+	}
+	return goType
+}
+
+// countFieldGetter returns an int expression reading the count field from p.buf
+// through the zerocopy getters
+func (g *Generator) countFieldGetter(countField string) string {
+	parts := strings.Split(countField, ".")
+	if len(parts) == 1 {
+		return fmt.Sprintf("int(p.Get%s())", upperFirst(countField))
+	}
+	return fmt.Sprintf("int(p.Get%s().%s)", upperFirst(parts[0]), strings.Join(parts[1:], "."))
+}
+
+// countFieldMirror returns a statement that stores expr into the struct's own copy
+// of the count field, keeping it in sync with p.buf
+func (g *Generator) countFieldMirror(countField, expr, indent string) string {
+	return fmt.Sprintf("%sp.%s = %s(%s)\n", indent, countField, g.countFieldType(countField), expr)
+}
+
+// countFieldSetter returns statements that store expr into the count field
+// through the zerocopy setters, so the value lands in p.buf
+func (g *Generator) countFieldSetter(countField, expr, indent string) string {
+	countType := g.countFieldType(countField)
+	parts := strings.Split(countField, ".")
+	if len(parts) == 1 {
+		// Callers check capacity first; the range-checked setter can't fail here
+		return fmt.Sprintf("%s_ = p.Set%s(%s(%s))\n", indent, upperFirst(countField), countType, expr)
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("%sparent := p.Get%s()\n", indent, upperFirst(parts[0])))
+	code.WriteString(fmt.Sprintf("%sparent.%s = %s(%s)\n", indent, strings.Join(parts[1:], "."), countType, expr))
+	code.WriteString(fmt.Sprintf("%sp.Set%s(parent)\n", indent, upperFirst(parts[0])))
+	return code.String()
+}
+
+// deferredMetadata returns the metadata slices of indirect slices that copy-mode
+// marshal encodes after packing: one pass, with the offsets and sizes already
+// stored. Metadata in a region= chain is located by the regions before it, so
+// it's marshaled in place and again after packing.
+func (g *Generator) deferredMetadata() map[string]bool {
+	deferred := make(map[string]bool)
+	if g.layout == nil {
+		return deferred
+	}
+	for _, field := range g.layout.Fields {
+		if field.Layout.From == "" {
+			continue
+		}
+		for _, region := range g.analyzed.Regions {
+			if region.Field.Name == field.Layout.From && region.Kind == analyzer.DynamicRegion && region.Field.Layout.Group == "" {
+				deferred[region.Field.Name] = true
+			}
+		}
+	}
+	return deferred
+}
+
 // generateIndirectMarshal generates marshal code for [][]byte with backward packing
 func (g *Generator) generateIndirectMarshal(field parser.Field) string {
 	var code strings.Builder
 
+	// Later indirect slices pack below the earlier ones, reusing their elementsEnd
+	first, hasElementsEnd := true, false
+	for _, f := range g.layout.Fields {
+		if f.Name == field.Name {
+			break
+		}
+		if f.Layout.From != "" {
+			first = false
+			hasElementsEnd = hasElementsEnd || (g.indirectBase(f) == "elementsEnd" && f.Layout.From == field.Layout.From)
+		}
+	}
+
 	// Comment
-	code.WriteString(fmt.Sprintf("\t// %s: [][]byte packed backward into %s, updating %s metadata\n",
-		field.Name, field.Layout.Region, field.Layout.From))
+	encoded := ""
+	if isPrefix(field) {
+		encoded = " (prefix-encoded)"
+	}
+	code.WriteString(fmt.Sprintf("\t// %s: [][]byte packed backward into %s, updating %s metadata%s\n",
+		field.Name, field.Layout.Region, field.Layout.From, encoded))
 
 	// Find the region field to determine pack start point
 	var regionField *parser.Field
@@ -1555,46 +2080,51 @@ func (g *Generator) generateIndirectMarshal(field parser.Field) string {
 	offsetType := g.getMetadataFieldType(field.Layout.From, field.Layout.OffsetField)
 	sizeType := g.getMetadataFieldType(field.Layout.From, field.Layout.SizeField)
 
-	// Calculate elementsEnd only for relative offset mode
+	// Calculate elementsEnd only for offsets counting from the end of the metadata
 	var elementsEnd string
-	if field.Layout.OffsetMode != "absolute" {
+	if hasElementsEnd {
+		elementsEnd = "elementsEnd"
+	} else if g.indirectBase(field) == "elementsEnd" {
 		// Find the metadata region to calculate where it ends
 		for _, region := range g.analyzed.Regions {
 			if region.Kind == analyzer.DynamicRegion &&
 			   region.Direction == parser.StartEnd &&
 			   region.ElementType != "byte" &&
 			   region.Field.Name == field.Layout.From {
-				code.WriteString(fmt.Sprintf("\telementsEnd := %d + len(p.%s)*%d\n",
-					region.Start, field.Layout.From, region.ElementSize))
+				code.WriteString(fmt.Sprintf("\telementsEnd := %d + %s*%d\n",
+					region.Start, metadataEntries(region, fmt.Sprintf("len(p.%s)", field.Layout.From)), region.ElementSize))
 				elementsEnd = "elementsEnd"
 				break
 			}
 		}
 	}
 
-	code.WriteString(fmt.Sprintf("\toffset = %s\n", packStart))
+	if first {
+		code.WriteString(fmt.Sprintf("\toffset = %s\n", packStart))
+	}
 	code.WriteString(fmt.Sprintf("\tfor i := len(p.%s) - 1; i >= 0; i-- {\n", field.Name))
-	code.WriteString(fmt.Sprintf("\t\tsize := len(p.%s[i])\n", field.Name))
-	code.WriteString("\t\toffset -= size\n")
-	code.WriteString(fmt.Sprintf("\t\tcopy(buf[offset:offset+size], p.%s[i])\n", field.Name))
-
-	// Store offset based on offset mode
-	if field.Layout.OffsetMode == "absolute" {
-		// Store absolute offset from page start
-		code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(offset)\n",
-			field.Layout.From, field.Layout.OffsetField, offsetType))
+	if isPrefix(field) {
+		code.WriteString(g.generatePrefixPack(field))
 	} else {
-		// Store region-relative offset (default, backwards compatible)
-		if elementsEnd != "" {
-			code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(offset - %s)\n",
-				field.Layout.From, field.Layout.OffsetField, offsetType, elementsEnd))
-		} else {
-			// Fallback: assume region starts at 0 (shouldn't happen)
-			code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(offset)\n",
-				field.Layout.From, field.Layout.OffsetField, offsetType))
-		}
+		code.WriteString(fmt.Sprintf("\t\tsize := len(p.%s[i])\n", field.Name))
+		code.WriteString("\t\toffset -= size\n")
+		code.WriteString(fmt.Sprintf("\t\tcopy(buf[offset:offset+size], p.%s[i])\n", field.Name))
 	}
 
+	// Offsets and sizes are narrowed into the metadata element's fields
+	storedOffset := "offset"
+	if g.indirectBase(field) != "elementsEnd" || elementsEnd != "" {
+		storedOffset = g.storedOffset(field, "offset")
+	}
+	offsetTarget := fmt.Sprintf("%s.%s", field.Layout.From, field.Layout.OffsetField)
+	sizeTarget := fmt.Sprintf("%s.%s", field.Layout.From, field.Layout.SizeField)
+	code.WriteString(g.overflowGuard(storedOffset, offsetType, offsetTarget, "nil, ", "\t\t"))
+	code.WriteString(g.overflowGuard("size", sizeType, sizeTarget, "nil, ", "\t\t"))
+
+	// Store the offset relative to what offsetmode= counts from
+	code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(%s)\n",
+		field.Layout.From, field.Layout.OffsetField, offsetType, storedOffset))
+
 	code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(size)\n", field.Layout.From, field.Layout.SizeField, sizeType))
 	code.WriteString("\t}\n\n")
 
@@ -1732,16 +2262,9 @@ func (g *Generator) generateRebuildIndirectSlices() string {
 			code.WriteString(fmt.Sprintf("\t\toffset -= %s\n", sizeVar))
 			code.WriteString(fmt.Sprintf("\t\tcopy(p.buf[offset:offset+%s], p.%s[i])\n", sizeVar, field.Name))
 
-			// Store offset based on offset mode
-			if field.Layout.OffsetMode == "absolute" {
-				// Store absolute offset from page start
-				code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(offset)\n",
-					firstFrom, field.Layout.OffsetField, offsetType))
-			} else {
-				// Store region-relative offset (default, backwards compatible)
-				code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(offset - elementsEnd)\n",
-					firstFrom, field.Layout.OffsetField, offsetType))
-			}
+			// Store the offset relative to what offsetmode= counts from
+			code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(%s)\n",
+				firstFrom, field.Layout.OffsetField, offsetType, g.storedOffset(field, "offset")))
 
 			code.WriteString(fmt.Sprintf("\t\tp.%s[i].%s = %s(%s)\n",
 				firstFrom, field.Layout.SizeField, sizeType, sizeVar))
@@ -1775,6 +2298,7 @@ func (g *Generator) generateZeroCopyMarshalMethod() string {
 	var code strings.Builder
 
 	code.WriteString(fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", g.analyzed.TypeName))
+	code.WriteString(g.generateMarshalDefaults())
 
 	// Generate code for each region, writing to p.buf
 	for _, region := range g.analyzed.Regions {
@@ -1784,7 +2308,9 @@ func (g *Generator) generateZeroCopyMarshalMethod() string {
 			code.WriteString(g.generateZeroCopyDynamicMarshal(region))
 		}
 	}
+	code.WriteString(g.generateZeroFill())
 
+	code.WriteString(g.generateHookOps(g.analyzed.Regions, "marshal"))
 	code.WriteString("\treturn p.buf[:], nil\n")
 	code.WriteString("}\n")
 
@@ -1795,27 +2321,9 @@ func (g *Generator) generateZeroCopyMarshalMethod() string {
 func (g *Generator) generateZeroCopyUnmarshalMethod() string {
 	var code strings.Builder
 
-	// Check if buf is array-based (no allocator/alignment) or slice-based
-	isArrayBased := g.align == 0 && g.allocator == ""
-
 	// UnmarshalLayout: keep buf parameter for backward compatibility, but use p.buf
 	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", g.analyzed.TypeName))
-	code.WriteString(fmt.Sprintf("\t// Zero-copy mode: copy buf into p.buf if different\n"))
-	code.WriteString("\tif len(buf) > 0 && len(p.buf) > 0 {\n")
-
-	if isArrayBased {
-		// Array-based: need to use [:] to get slice for comparison and copy
-		code.WriteString("\t\tif &buf[0] != &p.buf[0] {\n")
-		code.WriteString("\t\t\tcopy(p.buf[:], buf)\n")
-		code.WriteString("\t\t}\n")
-	} else {
-		// Slice-based: can use p.buf directly
-		code.WriteString("\t\tif &buf[0] != &p.buf[0] {\n")
-		code.WriteString("\t\t\tcopy(p.buf, buf)\n")
-		code.WriteString("\t\t}\n")
-	}
-
-	code.WriteString("\t}\n\n")
+	code.WriteString(g.generateBufferIntake())
 
 	// Generate code for each region
 	for _, region := range g.analyzed.Regions {
@@ -1835,9 +2343,14 @@ func (g *Generator) generateZeroCopyUnmarshalMethod() string {
 		}
 	}
 
+	code.WriteString(g.generateHookOps(g.analyzed.Regions, "unmarshal"))
 	code.WriteString("\treturn nil\n")
 	code.WriteString("}\n\n")
 
+	// Add SetBuffer for slice-backed types
+	code.WriteString(g.generateSetBuffer())
+	code.WriteString(g.generateDirectBuffer())
+
 	// Add LoadFrom helper
 	code.WriteString(g.generateLoadFromHelper())
 
@@ -1862,8 +2375,8 @@ func (g *Generator) generateZeroCopyUnmarshalMethod() string {
 func (g *Generator) generateZeroCopyAccessors() string {
 	var code strings.Builder
 
-	// Generate New<Type>() constructor only when using dynamic allocation
-	if g.align > 0 || g.allocator != "" {
+	// Generate New<Type>() constructor when using dynamic allocation or defaults
+	if g.align > 0 || g.allocator != "" || len(g.defaultRegions()) > 0 {
 		code.WriteString(g.generateNewFunction())
 		code.WriteString("\n")
 	}
@@ -1878,6 +2391,7 @@ func (g *Generator) generateZeroCopyAccessors() string {
 			code.WriteString(g.generateFixedAccessors(region))
 		} else {
 			code.WriteString(g.generateDynamicAccessors(region))
+			code.WriteString(g.generateAppend(region))
 		}
 	}
 
@@ -1890,6 +2404,9 @@ func (g *Generator) generateZeroCopyAccessors() string {
 		}
 	}
 
+	// Generate slot-directory management for slotted pages
+	code.WriteString(g.generateSlottedHelpers())
+
 	// Generate MarshalLayout and UnmarshalLayout for serialization
 	code.WriteString(g.generateZeroCopyMarshalMethod())
 	code.WriteString("\n")
@@ -1922,6 +2439,22 @@ func (g *Generator) generateClone() string {
 
 // generateFixedAccessors generates Get/Set for fixed fields
 func (g *Generator) generateFixedAccessors(region analyzer.Region) string {
+	if isReserved(region) {
+		return ""
+	}
+	if region.Bits > 0 {
+		return g.generateBitAccessors(region)
+	}
+	if region.Field.Layout.Access != "" {
+		return g.generateRegisterAccessors(region)
+	}
+	if k, ok := kindOf(region); ok {
+		return g.generateKindAccessors(region, k)
+	}
+	if floatBits(g.registry.ResolveType(region.Field.GoType)) > 0 {
+		return g.generateFloatAccessors(region)
+	}
+
 	var code strings.Builder
 	field := region.Field
 	resolvedType := g.registry.ResolveType(field.GoType)
@@ -1929,72 +2462,92 @@ func (g *Generator) generateFixedAccessors(region analyzer.Region) string {
 	end := region.Boundary
 
 	// Generate getter
-	code.WriteString(fmt.Sprintf("// Get%s returns %s at offset %d\n", field.Name, field.GoType, start))
-	code.WriteString(fmt.Sprintf("func (p *%s) Get%s() %s {\n", g.analyzed.TypeName, field.Name, field.GoType))
-
-	switch resolvedType {
-	case "uint8", "byte":
-		code.WriteString(fmt.Sprintf("\treturn p.buf[%d]\n", start))
-	case "int8":
-		code.WriteString(fmt.Sprintf("\treturn int8(p.buf[%d])\n", start))
-	case "uint16":
-		code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
-	case "int16":
-		code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
-	case "uint32":
-		code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
-	case "int32":
-		code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
-	case "uint64":
-		code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
-	case "int64":
-		code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
-	default:
-		// Handle arrays and structs
-		if strings.HasPrefix(field.GoType, "[") && strings.Contains(field.GoType, "]byte") {
-			// Byte array
-			code.WriteString(fmt.Sprintf("\tvar v %s\n", field.GoType))
-			code.WriteString(fmt.Sprintf("\tcopy(v[:], p.buf[%d:%d])\n", start, end))
-			code.WriteString("\treturn v\n")
-		} else {
-			// Struct type - needs unmarshal
-			code.WriteString(fmt.Sprintf("\tvar v %s\n", field.GoType))
-			code.WriteString(fmt.Sprintf("\tv.UnmarshalLayout(p.buf[%d:%d])\n", start, end))
-			code.WriteString("\treturn v\n")
+	code.WriteString(fmt.Sprintf("// Get%s returns %s at offset %d\n", upperFirst(field.Name), field.GoType, start))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%s() %s {\n", g.analyzed.TypeName, upperFirst(field.Name), field.GoType))
+
+	if region.Misaligned {
+		// Bytewise load, a typed load may fault on strict-alignment targets
+		code.WriteString(fmt.Sprintf("\tvar v %s\n", field.GoType))
+		code.WriteString(fmt.Sprintf("\tcopy(%s, p.buf[%d:%d])\n", bytesOf("&v", end-start), start, end))
+		code.WriteString("\treturn v\n")
+	} else {
+		switch resolvedType {
+		case "uint8", "byte":
+			code.WriteString(fmt.Sprintf("\treturn p.buf[%d]\n", start))
+		case "int8":
+			code.WriteString(fmt.Sprintf("\treturn int8(p.buf[%d])\n", start))
+		case "uint16":
+			code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
+		case "int16":
+			code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
+		case "uint32":
+			code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
+		case "int32":
+			code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
+		case "uint64":
+			code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
+		case "int64":
+			code.WriteString(fmt.Sprintf("\treturn *(*%s)(unsafe.Pointer(&p.buf[%d]))\n", field.GoType, start))
+		default:
+			// Handle arrays and structs
+			if strings.HasPrefix(field.GoType, "[") && strings.Contains(field.GoType, "]byte") {
+				// Byte array
+				code.WriteString(fmt.Sprintf("\tvar v %s\n", field.GoType))
+				code.WriteString(fmt.Sprintf("\tcopy(v[:], p.buf[%d:%d])\n", start, end))
+				code.WriteString("\treturn v\n")
+			} else {
+				// Struct type - needs unmarshal
+				code.WriteString(fmt.Sprintf("\tvar v %s\n", field.GoType))
+				code.WriteString(fmt.Sprintf("\tv.UnmarshalLayout(p.buf[%d:%d])\n", start, end))
+				code.WriteString("\treturn v\n")
+			}
 		}
 	}
 	code.WriteString("}\n\n")
 
+	// Count fields governing a region get a range-checked setter
+	if _, ok := g.countCapacity(field.Name); ok {
+		code.WriteString(g.generateCountSetter(region))
+		return code.String()
+	}
+
 	// Generate setter
-	code.WriteString(fmt.Sprintf("// Set%s sets %s at offset %d\n", field.Name, field.GoType, start))
-	code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) {\n", g.analyzed.TypeName, field.Name, field.GoType))
-
-	switch resolvedType {
-	case "uint8", "byte":
-		code.WriteString(fmt.Sprintf("\tp.buf[%d] = v\n", start))
-	case "int8":
-		code.WriteString(fmt.Sprintf("\tp.buf[%d] = byte(v)\n", start))
-	case "uint16":
-		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
-	case "int16":
-		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
-	case "uint32":
-		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
-	case "int32":
-		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
-	case "uint64":
-		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
-	case "int64":
-		code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
-	default:
-		// Handle arrays and structs
-		if strings.HasPrefix(field.GoType, "[") && strings.Contains(field.GoType, "]byte") {
-			// Byte array
-			code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], v[:])\n", start, end))
-		} else {
-			// Struct type - needs marshal
-			code.WriteString("\tbuf, _ := v.MarshalLayout()\n")
-			code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], buf)\n", start, end))
+	code.WriteString(fmt.Sprintf("// Set%s sets %s at offset %d\n", upperFirst(field.Name), field.GoType, start))
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) {\n", g.analyzed.TypeName, upperFirst(field.Name), field.GoType))
+
+	if region.Misaligned {
+		// Bytewise store, a typed store may fault on strict-alignment targets
+		code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], %s)\n", start, end, bytesOf("&v", end-start)))
+	} else {
+		switch resolvedType {
+		case "uint8", "byte":
+			code.WriteString(fmt.Sprintf("\tp.buf[%d] = v\n", start))
+		case "int8":
+			code.WriteString(fmt.Sprintf("\tp.buf[%d] = byte(v)\n", start))
+		case "uint16":
+			code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
+		case "int16":
+			code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
+		case "uint32":
+			code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
+		case "int32":
+			code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
+		case "uint64":
+			code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
+		case "int64":
+			code.WriteString(fmt.Sprintf("\t*(*%s)(unsafe.Pointer(&p.buf[%d])) = v\n", field.GoType, start))
+		default:
+			// Handle arrays and structs
+			if strings.HasPrefix(field.GoType, "[") && strings.Contains(field.GoType, "]byte") {
+				// Byte array
+				code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], v[:])\n", start, end))
+			} else if g.marshalsInto(field.GoType) {
+				code.WriteString(fmt.Sprintf("\tv.MarshalLayoutInto(p.buf[%d:%d])\n", start, end))
+			} else {
+				// Struct type - needs marshal
+				code.WriteString("\tbuf, _ := v.MarshalLayout()\n")
+				code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:%d], buf)\n", start, end))
+			}
 		}
 	}
 	code.WriteString("}\n\n")
@@ -2013,50 +2566,102 @@ func (g *Generator) generateDynamicAccessors(region analyzer.Region) string {
 	}
 
 	elementType := region.ElementType
-	start := region.Start
 	elementSize := region.ElementSize
 	countField := field.Layout.CountField
 
 	// Generate count getter
-	code.WriteString(fmt.Sprintf("// Get%sCount returns the number of %s elements\n", field.Name, field.Name))
-	code.WriteString(fmt.Sprintf("func (p *%s) Get%sCount() int {\n", g.analyzed.TypeName, field.Name))
+	code.WriteString(fmt.Sprintf("// Get%sCount returns the number of %s elements\n", upperFirst(field.Name), field.Name))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%sCount() int {\n", g.analyzed.TypeName, upperFirst(field.Name)))
 
 	// Handle nested field access (e.g., "Header.NumKeys")
 	if strings.Contains(countField, ".") {
 		parts := strings.Split(countField, ".")
 		// First part is the struct getter, rest is field access
-		code.WriteString(fmt.Sprintf("\treturn int(p.Get%s()", parts[0]))
+		code.WriteString(fmt.Sprintf("\treturn int(p.Get%s()", upperFirst(parts[0])))
 		for i := 1; i < len(parts); i++ {
 			code.WriteString(fmt.Sprintf(".%s", parts[i]))
 		}
 		code.WriteString(")\n")
 	} else {
 		// Simple field
-		code.WriteString(fmt.Sprintf("\treturn int(p.Get%s())\n", countField))
+		code.WriteString(fmt.Sprintf("\treturn int(p.Get%s())\n", upperFirst(countField)))
 	}
 	code.WriteString("}\n\n")
 
 	// Generate element getter
-	code.WriteString(fmt.Sprintf("// Get%sAt returns the %s element at index idx\n", field.Name, elementType))
-	code.WriteString(fmt.Sprintf("func (p *%s) Get%sAt(idx int) %s {\n", g.analyzed.TypeName, field.Name, elementType))
-	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", field.Name))
+	code.WriteString(fmt.Sprintf("// Get%sAt returns the %s element at index idx\n", upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%sAt(idx int) %s {\n", g.analyzed.TypeName, upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", upperFirst(field.Name)))
 	code.WriteString("\t\tpanic(\"index out of bounds\")\n")
 	code.WriteString("\t}\n")
-	code.WriteString(fmt.Sprintf("\toffset := %d + idx*%d\n", start, elementSize))
+	code.WriteString(fmt.Sprintf("\toffset := %s\n", g.elementOffsetExpr(region, "idx")))
 	code.WriteString(fmt.Sprintf("\tvar elem %s\n", elementType))
 	code.WriteString(fmt.Sprintf("\telem.UnmarshalLayout(p.buf[offset:offset+%d])\n", elementSize))
 	code.WriteString("\treturn elem\n")
 	code.WriteString("}\n\n")
 
 	// Generate element setter
-	code.WriteString(fmt.Sprintf("// Set%sAt sets the %s element at index idx\n", field.Name, elementType))
-	code.WriteString(fmt.Sprintf("func (p *%s) Set%sAt(idx int, elem %s) {\n", g.analyzed.TypeName, field.Name, elementType))
-	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", field.Name))
+	code.WriteString(fmt.Sprintf("// Set%sAt sets the %s element at index idx\n", upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%sAt(idx int, elem %s) {\n", g.analyzed.TypeName, upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", upperFirst(field.Name)))
 	code.WriteString("\t\tpanic(\"index out of bounds\")\n")
 	code.WriteString("\t}\n")
-	code.WriteString(fmt.Sprintf("\toffset := %d + idx*%d\n", start, elementSize))
-	code.WriteString("\tbuf, _ := elem.MarshalLayout()\n")
-	code.WriteString(fmt.Sprintf("\tcopy(p.buf[offset:offset+%d], buf)\n", elementSize))
+	code.WriteString(fmt.Sprintf("\toffset := %s\n", g.elementOffsetExpr(region, "idx")))
+	if g.marshalsInto(elementType) {
+		code.WriteString(fmt.Sprintf("\telem.MarshalLayoutInto(p.buf[offset : offset+%d])\n", elementSize))
+	} else {
+		code.WriteString("\tbuf, _ := elem.MarshalLayout()\n")
+		code.WriteString(fmt.Sprintf("\tcopy(p.buf[offset:offset+%d], buf)\n", elementSize))
+	}
+	code.WriteString(clearSlack(region, "offset", "\t"))
+	code.WriteString("}\n\n")
+
+	// Generate iterators for full scans without per-element accessor calls
+	code.WriteString(g.generateIterators(region))
+
+	return code.String()
+}
+
+// elementOffsetExpr returns an expression for the byte offset of element idx in a
+// zerocopy struct slice. Backward regions hold elements in ascending order ending at
+// the region start, matching the marshal packing.
+func (g *Generator) elementOffsetExpr(region analyzer.Region, idx string) string {
+	if region.Direction == parser.EndStart {
+		return fmt.Sprintf("%d - (p.Get%sCount()-%s)*%d", region.Start, upperFirst(region.Field.Name), idx, region.ElementStride)
+	}
+	return fmt.Sprintf("%d + %s*%d", region.Start, idx, region.ElementStride)
+}
+
+// generateIterators generates a callback iterator and a range-over-func iterator
+// that decode struct slice elements on the fly from p.buf
+func (g *Generator) generateIterators(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	elementType := region.ElementType
+	elementSize := region.ElementSize
+
+	// Callback iterator reusing a single element value
+	code.WriteString(fmt.Sprintf("// Iterate%s calls fn for each %s element decoded from p.buf, stopping early if fn returns false.\n", upperFirst(field.Name), elementType))
+	code.WriteString("// The element pointer is reused between calls.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) Iterate%s(fn func(i int, e *%s) bool) {\n", g.analyzed.TypeName, upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("\tvar elem %s\n", elementType))
+	code.WriteString(fmt.Sprintf("\tfor i := 0; i < p.Get%sCount(); i++ {\n", upperFirst(field.Name)))
+	code.WriteString(fmt.Sprintf("\t\toffset := %s\n", g.elementOffsetExpr(region, "i")))
+	code.WriteString(fmt.Sprintf("\t\telem.UnmarshalLayout(p.buf[offset:offset+%d])\n", elementSize))
+	code.WriteString("\t\tif !fn(i, &elem) {\n")
+	code.WriteString("\t\t\treturn\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n\n")
+
+	// Range-over-func iterator
+	code.WriteString(fmt.Sprintf("// All%s returns an iterator over the %s elements decoded from p.buf\n", upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("func (p *%s) All%s() iter.Seq2[int, %s] {\n", g.analyzed.TypeName, upperFirst(field.Name), elementType))
+	code.WriteString(fmt.Sprintf("\treturn func(yield func(int, %s) bool) {\n", elementType))
+	code.WriteString(fmt.Sprintf("\t\tp.Iterate%s(func(i int, e *%s) bool {\n", upperFirst(field.Name), elementType))
+	code.WriteString("\t\t\treturn yield(i, *e)\n")
+	code.WriteString("\t\t})\n")
+	code.WriteString("\t}\n")
 	code.WriteString("}\n\n")
 
 	return code.String()
@@ -2084,50 +2689,42 @@ func (g *Generator) generateIndirectAccessors(field parser.Field) string {
 	}
 
 	// Generate getter
-	code.WriteString(fmt.Sprintf("// Get%s returns the %s at index idx\n", field.Name, field.Name))
-	code.WriteString(fmt.Sprintf("func (p *%s) Get%s(idx int) []byte {\n", g.analyzed.TypeName, field.Name))
-	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", metadataRegion.Field.Name))
+	code.WriteString(fmt.Sprintf("// Get%s returns the %s at index idx\n", upperFirst(field.Name), field.Name))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%s(idx int) []byte {\n", g.analyzed.TypeName, upperFirst(field.Name)))
+	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", upperFirst(metadataRegion.Field.Name)))
 	code.WriteString("\t\tpanic(\"index out of bounds\")\n")
 	code.WriteString("\t}\n")
-	code.WriteString(fmt.Sprintf("\telem := p.Get%sAt(idx)\n", metadataRegion.Field.Name))
+	code.WriteString(fmt.Sprintf("\telem := p.Get%sAt(idx)\n", upperFirst(metadataRegion.Field.Name)))
 
-	// Handle offset mode
-	if field.Layout.OffsetMode == "absolute" {
-		// Absolute mode: offset is from page start, use directly
-		code.WriteString(fmt.Sprintf("\tstart := int(elem.%s)\n", field.Layout.OffsetField))
-	} else {
-		// Relative mode: offset is relative to data region, need to add elementsEnd
+	// Rebase the offset per offsetmode=; after the metadata, it moves with the count
+	if g.indirectBase(field) == "elementsEnd" {
 		code.WriteString(fmt.Sprintf("\telementsEnd := %d + p.Get%sCount()*%d\n",
-			metadataRegion.Start, metadataRegion.Field.Name, metadataRegion.ElementSize))
-		code.WriteString(fmt.Sprintf("\tstart := elementsEnd + int(elem.%s)\n", field.Layout.OffsetField))
+			metadataRegion.Start, upperFirst(metadataRegion.Field.Name), metadataRegion.ElementSize))
 	}
+	code.WriteString(fmt.Sprintf("\tstart := %s\n", g.bufferOffset(field, fmt.Sprintf("int(elem.%s)", field.Layout.OffsetField))))
 
 	code.WriteString(fmt.Sprintf("\tsize := int(elem.%s)\n", field.Layout.SizeField))
 	code.WriteString("\treturn p.buf[start:start+size]\n")
 	code.WriteString("}\n\n")
 
 	// Generate in-place setter (requires same size)
-	singularName := strings.TrimSuffix(field.Name, "s") // Keys -> Key, Values -> Value
+	singularName := upperFirst(strings.TrimSuffix(field.Name, "s")) // Keys -> Key, Values -> Value
 	code.WriteString(fmt.Sprintf("// Set%sInPlace updates %s at index idx (size must match)\n", singularName, field.Name))
 	code.WriteString(fmt.Sprintf("func (p *%s) Set%sInPlace(idx int, data []byte) {\n", g.analyzed.TypeName, singularName))
-	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", metadataRegion.Field.Name))
+	code.WriteString(fmt.Sprintf("\tif idx >= p.Get%sCount() {\n", upperFirst(metadataRegion.Field.Name)))
 	code.WriteString("\t\tpanic(\"index out of bounds\")\n")
 	code.WriteString("\t}\n")
-	code.WriteString(fmt.Sprintf("\telem := p.Get%sAt(idx)\n", metadataRegion.Field.Name))
+	code.WriteString(fmt.Sprintf("\telem := p.Get%sAt(idx)\n", upperFirst(metadataRegion.Field.Name)))
 	code.WriteString(fmt.Sprintf("\tif uint16(len(data)) != elem.%s {\n", field.Layout.SizeField))
 	code.WriteString("\t\tpanic(\"size mismatch: use Update instead of SetInPlace\")\n")
 	code.WriteString("\t}\n")
 
-	// Calculate start position based on offset mode
-	if field.Layout.OffsetMode == "absolute" {
-		// Absolute mode: offset is from page start, use directly
-		code.WriteString(fmt.Sprintf("\tstart := int(elem.%s)\n", field.Layout.OffsetField))
-	} else {
-		// Relative mode: offset is relative to data region, need to add elementsEnd
+	// Rebase the offset per offsetmode=; after the metadata, it moves with the count
+	if g.indirectBase(field) == "elementsEnd" {
 		code.WriteString(fmt.Sprintf("\telementsEnd := %d + p.Get%sCount()*%d\n",
-			metadataRegion.Start, metadataRegion.Field.Name, metadataRegion.ElementSize))
-		code.WriteString(fmt.Sprintf("\tstart := elementsEnd + int(elem.%s)\n", field.Layout.OffsetField))
+			metadataRegion.Start, upperFirst(metadataRegion.Field.Name), metadataRegion.ElementSize))
 	}
+	code.WriteString(fmt.Sprintf("\tstart := %s\n", g.bufferOffset(field, fmt.Sprintf("int(elem.%s)", field.Layout.OffsetField))))
 
 	code.WriteString("\tcopy(p.buf[start:], data)\n")
 	code.WriteString("}\n\n")
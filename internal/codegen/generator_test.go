@@ -61,7 +61,7 @@ func TestGenerateFixedFields(t *testing.T) {
 	}
 
 	// Check size validation
-	if !strings.Contains(code, "if len(buf) != 4096") {
+	if !strings.Contains(code, "if len(buf) < 4096") {
 		t.Error("Missing buffer size validation")
 	}
 }
@@ -277,14 +277,14 @@ func TestGenerateDynamicStartEnd(t *testing.T) {
 	if !strings.Contains(marshal, "offset = 2") {
 		t.Error("Expected offset initialization for dynamic field")
 	}
-	if !strings.Contains(marshal, "for i := range p.Body") {
-		t.Error("Expected loop over Body")
-	}
-	if !strings.Contains(marshal, "if offset >= 4088") {
+	if !strings.Contains(marshal, "if offset+len(p.Body) > 4088") {
 		t.Error("Expected collision check")
 	}
-	if !strings.Contains(marshal, "buf[offset] = p.Body[i]") {
-		t.Error("Expected byte-by-byte marshal")
+	if !strings.Contains(marshal, "offset += copy(buf[offset:], p.Body)") {
+		t.Error("Expected single copy marshal")
+	}
+	if strings.Contains(marshal, "for i := range p.Body") {
+		t.Error("Expected no byte-by-byte loop over Body")
 	}
 
 	// Unmarshal checks
@@ -376,19 +376,19 @@ func TestGenerateDynamicEndStart(t *testing.T) {
 	marshal := gen.GenerateMarshal()
 	unmarshal := gen.GenerateUnmarshal()
 
-	// Marshal checks - backward iteration
-	if !strings.Contains(marshal, "for i := len(p.Keys) - 1; i >= 0; i--") {
-		t.Error("Expected backward iteration for end-start")
+	// Marshal checks - bytes ending at the start offset
+	if !strings.Contains(marshal, "if offset-len(p.Keys) < 2") {
+		t.Error("Expected collision check with lower bound")
 	}
-	if !strings.Contains(marshal, "offset--") {
-		t.Error("Expected offset decrement")
+	if !strings.Contains(marshal, "offset -= len(p.Keys)") {
+		t.Error("Expected offset moved before the bytes")
 	}
-	if !strings.Contains(marshal, "if offset < 2") {
-		t.Error("Expected collision check with lower bound")
+	if !strings.Contains(marshal, "copy(buf[offset:], p.Keys)") {
+		t.Error("Expected single copy for end-start")
 	}
 
 	// Unmarshal checks - implicit length
-	if !strings.Contains(unmarshal, "kLen := 4096 - 2") {
+	if !strings.Contains(unmarshal, "keysLen := 4096 - 2") {
 		t.Error("Expected length calculation for end-start")
 	}
 	if !strings.Contains(unmarshal, "copy(p.Keys, buf[2:4096])") {
@@ -434,3 +434,305 @@ func TestGenerateComplete(t *testing.T) {
 		t.Error("Missing UnmarshalLayout method")
 	}
 }
+
+func TestGenerateZeroCopyIterators(t *testing.T) {
+	// @layout size=4096 mode=zerocopy
+	// type Page struct {
+	//     buf      [4096]byte
+	//     NumElems uint16 `layout:"@0"`
+	//     Elements []Elem `layout:"@2,start-end,count=NumElems"`
+	//     Tail     []Elem `layout:"end-start,count=NumElems"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 4096, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "NumElems", GoType: "uint16", Layout: &parser.FieldLayout{
+				Offset: 0, Direction: parser.Fixed,
+			}},
+			{Name: "Elements", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2, CountField: "NumElems",
+			}},
+			{Name: "Tail", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.EndStart, StartAt: -1, CountField: "NumElems",
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Page) IterateElements(fn func(i int, e *Elem) bool) {",
+		"func (p *Page) AllElements() iter.Seq2[int, Elem] {",
+		"offset := 2 + i*8",
+		// Backward regions hold elements ascending, ending at the region start
+		"offset := 4096 - (p.GetTailCount()-i)*8",
+		"offset := 4096 - (p.GetTailCount()-idx)*8",
+		"at := 4096 - (len(p.Tail)-i)*8",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+
+	foundIter := false
+	for _, path := range gen.Imports() {
+		if path == "iter" {
+			foundIter = true
+		}
+	}
+	if !foundIter {
+		t.Errorf("Imports() = %v, want iter", gen.Imports())
+	}
+}
+
+func TestGenerateElemAlign(t *testing.T) {
+	// @layout size=64
+	// type Table struct {
+	//     N       uint8   `layout:"@0"`
+	//     M       uint8   `layout:"@1"`
+	//     Entries []Entry `layout:"@8,start-end,count=N,elemalign=8"` // Entry is 6 bytes
+	//     Back    []Entry `layout:"@64,end-start,count=M,elemalign=8"`
+	// }
+	layout := func(mode string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Table",
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: mode},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "M", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+				{Name: "Entries", GoType: "[]Entry", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "N", ElemAlign: 8,
+				}},
+				{Name: "Back", GoType: "[]Entry", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.EndStart, StartAt: 64, CountField: "M", ElemAlign: 8,
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"\t// Entries: []Entry at [8, 64) with count=N (element size: 6, stride: 8)\n",
+			"\t\tif offset + 6 > 64 {\n",
+			"\t\tcopy(buf[offset:offset+6], elemBuf)\n",
+			"\t\toffset += 8\n",
+			"\t\toffset -= 8\n",
+			"\t\tat := 64 - (len(p.Back)-i)*8\n",
+			"\t\tif err := p.Back[i].UnmarshalLayout(buf[at:at+6]); err != nil {\n",
+		}},
+		{"zerocopy", []string{
+			"\toffset := 8 + idx*8\n",
+			"\toffset := 64 - (p.GetBackCount()-idx)*8\n",
+			"\telem.UnmarshalLayout(p.buf[offset:offset+6])\n",
+			"\toffset := 8 + n*8\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			l := layout(tt.mode)
+			reg := analyzer.NewTypeRegistry()
+			reg.Register("Entry", 6)
+			analyzed, err := analyzer.Analyze(l, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			code, err := NewGenerator(analyzed, l, []*parser.TypeLayout{l}, reg, "little", tt.mode, 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateStride(t *testing.T) {
+	// @layout size=72
+	// type File struct {
+	//     N       uint8    `layout:"@0"`
+	//     Records []Record `layout:"@8,start-end,count=N,stride=16"` // Record is 12 bytes
+	// }
+	layout := func(mode string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "File",
+			Anno: &parser.TypeAnnotation{Size: 72, Mode: mode},
+			Fields: []parser.Field{
+				{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Records", GoType: "[]Record", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "N", Stride: 16,
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"\t// Records: []Record at [8, 72) with count=N (element size: 12, stride: 16)\n",
+			"\t\tcopy(buf[offset:offset+12], elemBuf)\n",
+			"\t\toffset += 16\n",
+		}},
+		// Writes in place zero the 4 bytes of slack after each element
+		{"zerocopy", []string{
+			"\t\tat := 8 + i*16\n",
+			"\t\tclear(p.buf[at+12 : at+16])\n",
+			"\toffset := 8 + n*16\n\tcopy(p.buf[offset:offset+12], elemBuf)\n\tclear(p.buf[offset+12 : offset+16])\n",
+			"\toffset := 8 + idx*16\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			l := layout(tt.mode)
+			reg := analyzer.NewTypeRegistry()
+			reg.Register("Record", 12)
+			analyzed, err := analyzer.Analyze(l, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			code, err := NewGenerator(analyzed, l, []*parser.TypeLayout{l}, reg, "little", tt.mode, 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if tt.mode == "copy" && strings.Contains(code, "clear(") {
+				t.Error("copy mode clears slack of a buffer that is fresh")
+			}
+		})
+	}
+}
+
+func TestGenerateIndirectMarshalOnce(t *testing.T) {
+	// @layout size=128
+	// type Leaf struct {
+	//     Count  uint16   `layout:"@0"`
+	//     Elems  []Elem   `layout:"start-end,count=Count"`
+	//     Keys   [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data"`
+	//     Values [][]byte `layout:"from=Elems,offset=ValOff,size=ValSize,region=Data"`
+	//     Data   []byte   `layout:"end-start"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "KeyOff", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "KeySize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "ValOff", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "ValSize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 128},
+		Fields: []parser.Field{
+			{Name: "Count", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "Count"}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize", Region: "Data"}},
+			{Name: "Values", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, From: "Elems", OffsetField: "ValOff", SizeField: "ValSize", Region: "Data"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.EndStart}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	reg.RegisterFields("Elem", elem.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout, elem}, reg, "little", "copy", 0, "")
+	marshal := gen.GenerateMarshal()
+
+	// Values continue below Keys, and Elems is encoded once, after both
+	keys := strings.Index(marshal, "\t// Keys: [][]byte packed backward")
+	values := strings.Index(marshal, "\t// Values: [][]byte packed backward")
+	elems := strings.Index(marshal, "\t// Elems: []Elem")
+	if keys < 0 || values < keys || elems < values {
+		t.Errorf("Expected Keys, Values, then Elems\n\nGenerated:\n%s", marshal)
+	}
+	if n := strings.Count(marshal, "elementsEnd :="); n != 1 {
+		t.Errorf("elementsEnd declared %d times, want 1\n\nGenerated:\n%s", n, marshal)
+	}
+	if n := strings.Count(marshal, "offset = 128\n"); n != 2 {
+		t.Errorf("offset reset to 128 %d times, want 2 (Data and Keys)\n\nGenerated:\n%s", n, marshal)
+	}
+	if n := strings.Count(marshal, "{ // Elem inlined"); n != 1 {
+		t.Errorf("Elems encoded %d times, want 1\n\nGenerated:\n%s", n, marshal)
+	}
+	if strings.Contains(marshal, "Re-marshal") {
+		t.Errorf("Expected no re-marshal of Elems\n\nGenerated:\n%s", marshal)
+	}
+}
+
+func TestGenerateIOHelpers(t *testing.T) {
+	// @layout size=64 mode=zerocopy
+	// type Page struct {
+	//     Header uint16 `layout:"@0"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "Header", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	expectedParts := []string{
+		// io.ReaderFrom and io.WriterTo
+		"func (p *Page) loadFrom(r io.Reader) (int, error) {\n\tn, err := io.ReadFull(r, p.buf[:])\n\tif err == io.ErrUnexpectedEOF {\n" +
+			"\t\treturn n, fmt.Errorf(\"ReadFrom: read %d of 64 bytes: %w\", n, ErrTruncatedPage)\n\t}\n",
+		"\treturn n, p.UnmarshalLayout(p.buf[:])\n}\n",
+		// A clean EOF is success, as io.Copy expects
+		"func (p *Page) ReadFrom(r io.Reader) (int64, error) {\n\tn, err := p.loadFrom(r)\n\tif err == io.EOF {\n\t\treturn 0, nil\n\t}\n" +
+			"\treturn int64(n), err\n}\n",
+		"func (p *Page) WriteTo(w io.Writer) (int64, error) {\n\tif _, err := p.MarshalLayout(); err != nil {\n\t\treturn 0, err\n\t}\n" +
+			"\tn, err := w.Write(p.buf[:])\n\treturn int64(n), err\n}\n",
+		// Positioned reads
+		"func (p *Page) LoadFromAt(r io.ReaderAt, off int64) error {\n\tn, err := r.ReadAt(p.buf[:], off)\n\tif n < 64 {\n",
+		"\t\tcase n == 0 && err == io.EOF:\n\t\t\treturn io.EOF\n",
+		"\t\t\treturn fmt.Errorf(\"LoadFromAt: read %d of 64 bytes at offset %d: %w\", n, off, ErrTruncatedPage)\n",
+		// The error-only helpers
+		"func (p *Page) LoadFrom(r io.Reader) error {\n\t_, err := p.loadFrom(r)\n\treturn err\n}\n",
+		"func (p *Page) SaveTo(w io.Writer) error {\n\t_, err := p.WriteTo(w)\n\treturn err\n}\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
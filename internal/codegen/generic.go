@@ -0,0 +1,44 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateInstanceType generates the declaration of a generic layout's instance,
+// a defined type over the instantiated generic type carrying its generated
+// methods. Methods can't be specialized per type argument, and the instance
+// shares the generic type's fields, so converting between them is free.
+func (g *Generator) generateInstanceType() string {
+	if g.layout.Generic == "" {
+		return ""
+	}
+	var code strings.Builder
+	name := g.analyzed.TypeName
+	generic := fmt.Sprintf("%s[%s]", g.layout.Generic, g.layout.TypeArg)
+
+	code.WriteString(fmt.Sprintf("// %s carries the layout methods of %s, whose fields it shares.\n", name, generic))
+	code.WriteString(fmt.Sprintf("// Convert a *%s with (*%s)(p).\n", generic, name))
+	code.WriteString(fmt.Sprintf("type %s %s\n\n", name, generic))
+	return code.String()
+}
+
+// generateSizeLayout generates SizeLayout for a layout some generic layout of
+// this file is instantiated with, meeting a constraint such as
+// interface{ SizeLayout() int }. A value receiver lets the type argument itself
+// satisfy it, not only its pointer. Other type arguments declare their own.
+func (g *Generator) generateSizeLayout() string {
+	name := g.analyzed.TypeName
+	for _, layout := range g.allLayouts {
+		if layout.Generic == "" || layout.TypeArg != name {
+			continue
+		}
+		var code strings.Builder
+		code.WriteString(fmt.Sprintf("\n// SizeLayout returns the encoded size of %s, for generic layouts instantiated with it\n", name))
+		code.WriteString(fmt.Sprintf("func (%s) SizeLayout() int {\n", name))
+		code.WriteString(fmt.Sprintf("\treturn %d\n", g.analyzed.BufferSize))
+		code.WriteString("}\n")
+		return code.String()
+	}
+	return ""
+}
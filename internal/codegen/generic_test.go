@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateGenericInstance(t *testing.T) {
+	// @layout size=4
+	// type Pair struct {
+	//     A uint16 `layout:"@0"`
+	//     B uint16 `layout:"@2"`
+	// }
+	//
+	// @layout size=64 instances=Pair
+	// type Page[T Element] struct {
+	//     N     uint8 `layout:"@0"`
+	//     Items []T   `layout:"@8,start-end,count=N"`
+	// }
+	pair := &parser.TypeLayout{
+		Name: "Pair",
+		Anno: &parser.TypeAnnotation{Size: 4},
+		Fields: []parser.Field{
+			{Name: "A", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "B", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name:    "PagePair",
+		Anno:    &parser.TypeAnnotation{Size: 64, Instances: []string{"Pair"}},
+		Generic: "Page",
+		TypeArg: "Pair",
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Items", GoType: "[]Pair", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+	allLayouts := []*parser.TypeLayout{pair, layout}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Pair", 4)
+	reg.RegisterFields("Pair", pair.Fields)
+
+	tests := []struct {
+		layout        *parser.TypeLayout
+		expectedParts []string
+	}{
+		{layout, []string{
+			"// PagePair carries the layout methods of Page[Pair], whose fields it shares.\n",
+			"type PagePair Page[Pair]\n",
+			"func (p *PagePair) MarshalLayout() ([]byte, error) {\n",
+		}},
+		// The type argument meets a SizeLayout constraint by value
+		{pair, []string{
+			"func (Pair) SizeLayout() int {\n\treturn 4\n}\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout.Name, func(t *testing.T) {
+			analyzed, err := analyzer.Analyze(tt.layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			code, err := NewGenerator(analyzed, tt.layout, allLayouts, reg, "little", "copy", 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if tt.layout == layout && strings.Contains(code, "SizeLayout") {
+				t.Errorf("instance got a SizeLayout, only type arguments do")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GoldenEnv is the environment variable that makes golden tests rewrite their
+// golden files instead of comparing against them
+const GoldenEnv = "LAYOUT_UPDATE_GOLDEN"
+
+// GenerateGoldenHeader generates the package clause and imports of a golden test
+// file, followed by one GenerateGoldenTest per type
+func GenerateGoldenHeader(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import (\n")
+	code.WriteString("\t\"bytes\"\n")
+	code.WriteString("\t\"os\"\n")
+	code.WriteString("\t\"path/filepath\"\n")
+	code.WriteString("\t\"testing\"\n")
+	code.WriteString(")\n\n")
+
+	return code.String()
+}
+
+// GenerateGoldenTest generates a table-driven test marshaling representative
+// values of the type and comparing the bytes against golden files committed in
+// testdata/, so a change to the layout shows up as a changed binary file in
+// review. The cases are the empty value, which marshals the field defaults, and
+// each fixtures= function.
+func (g *Generator) GenerateGoldenTest() string {
+	if g.layout.Anno.Raw != "" {
+		return g.rawGenerator().GenerateGoldenTest() // The raw= type has the methods
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// TestLayoutGolden%s compares encoded %s values against testdata/%s.<case>.golden.\n",
+		typeName, typeName, typeName))
+	code.WriteString(fmt.Sprintf("// After an intended layout change, run with %s=1 to rewrite the files.\n", GoldenEnv))
+	code.WriteString(fmt.Sprintf("func TestLayoutGolden%s(t *testing.T) {\n", typeName))
+	code.WriteString("\ttests := []struct {\n")
+	code.WriteString("\t\tname  string\n")
+	code.WriteString(fmt.Sprintf("\t\tvalue func() *%s\n", typeName))
+	code.WriteString("\t}{\n")
+	code.WriteString(fmt.Sprintf("\t\t{\"defaults\", func() *%s { return %s }},\n", typeName, g.emptyValue()))
+	for _, fixture := range g.layout.Anno.Fixtures {
+		code.WriteString(fmt.Sprintf("\t\t{%q, %s},\n", fixture, fixture))
+	}
+	code.WriteString("\t}\n")
+	code.WriteString("\tfor _, tt := range tests {\n")
+	code.WriteString("\t\tt.Run(tt.name, func(t *testing.T) {\n")
+	code.WriteString("\t\t\tgot, err := tt.value().MarshalLayout()\n")
+	code.WriteString("\t\t\tif err != nil {\n")
+	code.WriteString("\t\t\t\tt.Fatalf(\"MarshalLayout() error: %v\", err)\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\t\tgolden := filepath.Join(\"testdata\", \"%s.\"+tt.name+\".golden\")\n", typeName))
+	code.WriteString(fmt.Sprintf("\t\t\tif os.Getenv(%q) != \"\" {\n", GoldenEnv))
+	code.WriteString("\t\t\t\tif err := os.MkdirAll(\"testdata\", 0755); err != nil {\n")
+	code.WriteString("\t\t\t\t\tt.Fatal(err)\n")
+	code.WriteString("\t\t\t\t}\n")
+	code.WriteString("\t\t\t\tif err := os.WriteFile(golden, got, 0644); err != nil {\n")
+	code.WriteString("\t\t\t\t\tt.Fatal(err)\n")
+	code.WriteString("\t\t\t\t}\n")
+	code.WriteString("\t\t\t\treturn\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\twant, err := os.ReadFile(golden)\n")
+	code.WriteString("\t\t\tif err != nil {\n")
+	code.WriteString(fmt.Sprintf("\t\t\t\tt.Fatalf(\"%%v (run with %s=1 to create it)\", err)\n", GoldenEnv))
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t\tif !bytes.Equal(got, want) {\n")
+	code.WriteString("\t\t\t\ti := 0\n")
+	code.WriteString("\t\t\t\tfor i < min(len(got), len(want)) && got[i] == want[i] {\n")
+	code.WriteString("\t\t\t\t\ti++\n")
+	code.WriteString("\t\t\t\t}\n")
+	code.WriteString("\t\t\t\tt.Errorf(\"encoding differs from %s at byte %d (got %d bytes, want %d)\", golden, i, len(got), len(want))\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t})\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
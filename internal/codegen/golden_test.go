@@ -0,0 +1,60 @@
+package codegen
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateGoldenTest(t *testing.T) {
+	// @layout size=16 fixtures=fullHeader,emptyBody
+	// type Header struct {
+	//     Magic uint32 `layout:"@0,default=0xCAFE"`
+	//     N     uint16 `layout:"@4"`
+	//     Body  []byte `layout:"@8,start-end,count=N"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 16, Fixtures: []string{"fullHeader", "emptyBody"}},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "0xCAFE"}},
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code := GenerateGoldenHeader("headers") + gen.GenerateGoldenTest()
+
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n\nGenerated:\n%s", err, code)
+	}
+
+	expectedParts := []string{
+		"package headers\n",
+		"func TestLayoutGoldenHeader(t *testing.T) {",
+		"\t\tvalue func() *Header\n",
+		// The empty value marshals the defaults, then each fixture
+		"{\"defaults\", func() *Header { return &Header{} }},",
+		"{\"fullHeader\", fullHeader},",
+		"{\"emptyBody\", emptyBody},",
+		"got, err := tt.value().MarshalLayout()",
+		`golden := filepath.Join("testdata", "Header."+tt.name+".golden")`,
+		`if os.Getenv("LAYOUT_UPDATE_GOLDEN") != "" {`,
+		"if !bytes.Equal(got, want) {",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
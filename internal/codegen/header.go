@@ -0,0 +1,105 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// headerPrefix returns the fixed regions leading the layout, up to its first
+// dynamic region, and the end of the last of them
+func (g *Generator) headerPrefix() ([]analyzer.Region, int) {
+	var prefix []analyzer.Region
+	end := 0
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.FixedRegion {
+			break
+		}
+		prefix = append(prefix, region)
+		end = max(end, region.Boundary)
+	}
+	return prefix, end
+}
+
+// headerGenerator returns a copy-mode generator for the header type of header=,
+// whose regions are the fixed prefix minus reserved ranges. A zerocopy layout
+// holds its fields in native byte order, which the header decodes likewise.
+func (g *Generator) headerGenerator() *Generator {
+	prefix, end := g.headerPrefix()
+	header := *g.analyzed
+	header.TypeName = g.layout.Anno.Header
+	header.BufferSize = end
+	header.Regions = nil
+	header.Trailer = nil
+	for _, region := range prefix {
+		if !isReserved(region) {
+			header.Regions = append(header.Regions, region)
+		}
+	}
+
+	// Of the annotation only the field options apply, such as floatpolicy=
+	anno := *g.layout.Anno
+	anno.Header, anno.Mirror, anno.Metrics = "", "", false
+	layout := *g.layout
+	layout.Anno = &anno
+
+	hg := *g
+	hg.analyzed = &header
+	hg.layout = &layout
+	hg.mode = "copy"
+	if g.mode == "zerocopy" {
+		hg.endian = "native"
+	}
+	return &hg
+}
+
+// headerImports returns the packages the header type's code uses
+func (g *Generator) headerImports() []string {
+	if g.layout.Anno.Header == "" {
+		return nil
+	}
+	return g.headerGenerator().Imports()
+}
+
+// generateHeaderView generates the type named by header=: the fields of the fixed
+// prefix and an UnmarshalLayout decoding only them, so code routing pages or frames
+// by their header never builds the full type
+func (g *Generator) generateHeaderView() (string, error) {
+	name := g.layout.Anno.Header
+	if name == "" {
+		return "", nil
+	}
+	if name == g.analyzed.TypeName {
+		return "", fmt.Errorf("header=%s: the header type needs a name of its own", name)
+	}
+	hg := g.headerGenerator()
+	if len(hg.analyzed.Regions) == 0 {
+		return "", fmt.Errorf("header=%s: %s has no fixed fields before its first dynamic region", name, g.analyzed.TypeName)
+	}
+	typeName := g.analyzed.TypeName
+	size := hg.analyzed.BufferSize
+
+	var code strings.Builder
+	code.WriteString("\n")
+	code.WriteString(fmt.Sprintf("// %s is the fixed header of %s, bytes [0, %d), decoded without the rest\n", name, typeName, size))
+	code.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	for _, region := range hg.analyzed.Regions {
+		code.WriteString(fmt.Sprintf("\t%s %s\n", region.Field.Name, region.Field.GoType))
+	}
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// UnmarshalLayout decodes the header from the first %d bytes of buf, an encoded\n", size))
+	code.WriteString(fmt.Sprintf("// %s or any prefix of one at least that long\n", typeName))
+	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", name))
+	code.WriteString(fmt.Sprintf("\tif len(buf) < %d {\n", size))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: expected at least %d bytes, got %%d: %%w\", len(buf), ErrShortBuffer)\n", name, size))
+	code.WriteString("\t}\n\n")
+	for _, region := range hg.analyzed.Regions {
+		code.WriteString(hg.generateFixedOp(region, "unmarshal"))
+	}
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n")
+
+	return code.String(), nil
+}
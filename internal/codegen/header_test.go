@@ -0,0 +1,158 @@
+package codegen
+
+import (
+	goparser "go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateHeaderView(t *testing.T) {
+	// @layout size=64 header=PageHeader
+	// type Page struct {
+	//     Type   uint8   `layout:"@0,magic=3"`
+	//     _      [3]byte `layout:"@1,reserve=3"`
+	//     Count  uint32  `layout:"@4"`
+	//     Body   []byte  `layout:"start-end"`
+	//     Footer uint64  `layout:"@56"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Header: "PageHeader", Metrics: true},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "3", Magic: true}},
+			{Name: "_", GoType: "[3]byte", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed, Reserve: 3}},
+			{Name: "Count", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+			{Name: "Footer", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 56, Direction: parser.Fixed}},
+		},
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"// PageHeader is the fixed header of Page, bytes [0, 8), decoded without the rest\ntype PageHeader struct {\n\tType uint8\n\tCount uint32\n}\n",
+			"func (p *PageHeader) UnmarshalLayout(buf []byte) error {\n\tif len(buf) < 8 {\n",
+			"return fmt.Errorf(\"PageHeader: expected at least 8 bytes, got %d: %w\", len(buf), ErrShortBuffer)",
+			"\tif p.Type != 3 {\n",
+			"\tp.Count = binary.LittleEndian.Uint32(buf[4:8])\n",
+		}},
+		{"zerocopy", []string{
+			"func (p *PageHeader) UnmarshalLayout(buf []byte) error {\n",
+			"\tp.Count = binary.NativeEndian.Uint32(buf[4:8])\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+
+			header := code[strings.Index(code, "type PageHeader"):]
+			for _, unexpected := range []string{"Footer", "Body", "func (p *PageHeader) unmarshalLayout"} {
+				if strings.Contains(header, unexpected) {
+					t.Errorf("Header type contains %q\n\nGenerated:\n%s", unexpected, header)
+				}
+			}
+			if !slices.Contains(gen.Imports(), "encoding/binary") {
+				t.Errorf("Imports() = %v, missing encoding/binary", gen.Imports())
+			}
+		})
+	}
+}
+
+func TestGenerateHeaderViewReadme(t *testing.T) {
+	// The example of the README's Header Types section, parsed from source
+	src := `package wire
+
+// @layout size=4096 header=PageHeader
+type Page struct {
+    Type   uint8  ` + "`layout:\"@0,magic=3\"`" + `
+    Count  uint16 ` + "`layout:\"@2\"`" + `
+    Body   []byte ` + "`layout:\"@4,start-end\"`" + `
+    Footer uint64 ` + "`layout:\"@4088\"`" + `
+}
+`
+	file, err := goparser.ParseFile(token.NewFileSet(), "page.go", src, goparser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layouts, _, diagnostics := parser.ExtractTypes(file, nil, nil)
+	if len(layouts) != 1 || len(diagnostics) != 0 {
+		t.Fatalf("ExtractTypes() = %v, %v, want Page", layouts, diagnostics)
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layouts[0], reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err := NewGenerator(analyzed, layouts[0], layouts, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Both fields before Body, read from buf[0:4], as documented
+	for _, expected := range []string{
+		"type PageHeader struct {\n\tType uint8\n\tCount uint16\n}\n",
+		"func (p *PageHeader) UnmarshalLayout(buf []byte) error {\n\tif len(buf) < 4 {\n",
+		"\tp.Count = binary.LittleEndian.Uint16(buf[2:4])\n",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateHeaderViewErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		fields  []parser.Field
+		wantErr string
+	}{
+		{"own name", "Page", []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		}, "needs a name of its own"},
+		{"no prefix", "PageHeader", []parser.Field{
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+		}, "no fixed fields before its first dynamic region"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name:   "Page",
+				Anno:   &parser.TypeAnnotation{Size: 64, Header: tt.header},
+				Fields: tt.fields,
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+			if _, err := gen.Generate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// hookDeferred reports whether a field's marshal code is deferred to its get= hook
+func hookDeferred(region analyzer.Region, op string) bool {
+	return op == "marshal" && region.Field.Layout != nil && region.Field.Layout.Get != ""
+}
+
+// generateHookOps generates the end of MarshalLayout or UnmarshalLayout for fields
+// with hooks, once every other field is in the buffer. Marshal encodes the value
+// returned by p.Get(buf), computed while the field's own bytes are still zero, so
+// checksums can cover the whole buffer. Unmarshal passes the decoded value to
+// p.Set(buf, v), whose error fails the unmarshal.
+func (g *Generator) generateHookOps(regions []analyzer.Region, op string) string {
+	var code strings.Builder
+	bufExpr := "buf"
+	if g.mode == "zerocopy" {
+		bufExpr = "p.buf[:]"
+	}
+
+	for _, region := range regions {
+		field := region.Field
+		if field.Layout == nil {
+			continue
+		}
+
+		if op == "marshal" && field.Layout.Get != "" {
+			code.WriteString(fmt.Sprintf("\t// %s: computed by %s\n", field.Name, field.Layout.Get))
+			if g.mode == "zerocopy" {
+				code.WriteString(fmt.Sprintf("\tclear(p.buf[%d:%d])\n", region.Start, region.Boundary))
+			}
+			code.WriteString(fmt.Sprintf("\tp.%s = p.%s(%s)\n", field.Name, field.Layout.Get, bufExpr))
+
+			// Encode as a plain field
+			layout := *field.Layout
+			layout.Get = ""
+			region.Field.Layout = &layout
+			code.WriteString(g.generateFixedOp(region, op))
+		}
+
+		if op == "unmarshal" && field.Layout.Set != "" {
+			code.WriteString(fmt.Sprintf("\t// %s: passed to %s\n", field.Name, field.Layout.Set))
+			code.WriteString(fmt.Sprintf("\tif err := p.%s(%s, p.%s); err != nil {\n", field.Layout.Set, bufExpr, field.Name))
+			code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: %%w\", err)\n", field.Name))
+			code.WriteString("\t}\n\n")
+		}
+	}
+
+	return code.String()
+}
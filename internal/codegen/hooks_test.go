@@ -0,0 +1,70 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateHooks(t *testing.T) {
+	// @layout size=16
+	// type Record struct {
+	//     ID  uint32 `layout:"@0"`
+	//     Val uint64 `layout:"@4"`
+	//     CRC uint32 `layout:"@12,get=computeCRC,set=checkCRC"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Record",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Val", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "CRC", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed, Get: "computeCRC", Set: "checkCRC"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// The hook runs after every other field is encoded, then CRC is encoded once
+	hook := strings.Index(code, "p.CRC = p.computeCRC(buf)")
+	val := strings.Index(code, "binary.LittleEndian.PutUint64(buf[4:12], p.Val)")
+	if hook < 0 || val < 0 || hook < val {
+		t.Errorf("computeCRC should be called after Val is encoded\n\nGenerated:\n%s", code)
+	}
+	if n := strings.Count(code, "PutUint32(buf[12:16], p.CRC)"); n != 1 {
+		t.Errorf("CRC encoded %d times, want 1\n\nGenerated:\n%s", n, code)
+	}
+
+	expectedParts := []string{
+		"p.CRC = binary.LittleEndian.Uint32(buf[12:16])",
+		"if err := p.checkCRC(buf, p.CRC); err != nil {",
+		`return fmt.Errorf("CRC: %w", err)`,
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Zerocopy clears the field's bytes before the hook sees p.buf
+	gen = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err = gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if !strings.Contains(code, "clear(p.buf[12:16])\n\tp.CRC = p.computeCRC(p.buf[:])") {
+		t.Errorf("Zerocopy hook should clear CRC before computing it\n\nGenerated:\n%s", code)
+	}
+}
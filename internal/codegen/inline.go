@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// inlineGenerator returns the generator of the layout type elemType if its
+// marshaling can be inlined into the layouts holding it: a copy-mode type of the
+// same package made of fixed fields only, whose MarshalLayout no one observes
+// (metrics=). Returns nil otherwise.
+func (g *Generator) inlineGenerator(elemType string) *Generator {
+	if strings.Contains(elemType, ".") {
+		return nil // Imported types may have unexported fields
+	}
+	for _, layout := range g.allLayouts {
+		if layout.Name != elemType {
+			continue
+		}
+		if (layout.Anno.Mode != "" && layout.Anno.Mode != "copy") || layout.Anno.Metrics {
+			return nil
+		}
+		analyzed, err := analyzer.Analyze(layout, g.registry)
+		if err != nil || len(analyzed.Trailer) > 0 {
+			return nil
+		}
+		for _, region := range analyzed.Regions {
+			if region.Kind != analyzer.FixedRegion {
+				return nil
+			}
+		}
+		endian := layout.Anno.Endian
+		if endian == "" {
+			endian = "little"
+		}
+		return NewGenerator(analyzed, layout, g.allLayouts, g.registry, endian, "copy", 0, "")
+	}
+	return nil
+}
+
+// generateElementMarshal generates the marshaling of the nested layout value elem,
+// of type elemType, into dst, the bytes it occupies, as statements at indent.
+// errReturn is the statement returning a failed element's error.
+//
+// Inlinable types have their field operations emitted in place, in a block where
+// buf is dst and p points to elem, so they run as in the type's own MarshalLayout
+// without allocating or copying its encoding. Types with MarshalLayoutInto encode
+// into dst; others allocate their encoding, which is copied into dst.
+func (g *Generator) generateElementMarshal(elem, elemType, dst, indent, errReturn string) string {
+	var code strings.Builder
+
+	if inline := g.inlineGenerator(elemType); inline != nil {
+		code.WriteString(fmt.Sprintf("%s{ // %s inlined\n", indent, elemType))
+		code.WriteString(fmt.Sprintf("%s\tbuf := %s\n", indent, dst))
+		code.WriteString(fmt.Sprintf("%s\tp := &%s\n", indent, elem))
+		if g.mode == "zerocopy" {
+			code.WriteString(fmt.Sprintf("%s\tclear(buf) // Gaps are zero, as in a fresh encoding\n", indent))
+		}
+		var body strings.Builder
+		body.WriteString(inline.generateMarshalDefaults())
+		for _, region := range inline.analyzed.Regions {
+			body.WriteString(inline.generateFixedOp(region, "marshal"))
+		}
+		body.WriteString(inline.generateHookOps(inline.analyzed.Regions, "marshal"))
+		for _, line := range strings.Split(strings.TrimRight(body.String(), "\n"), "\n") {
+			if line != "" {
+				line = indent + line
+			}
+			code.WriteString(line + "\n")
+		}
+		code.WriteString(fmt.Sprintf("%s}\n", indent))
+		return code.String()
+	}
+
+	if g.marshalsInto(elemType) {
+		code.WriteString(fmt.Sprintf("%sif _, err := %s.MarshalLayoutInto(%s); err != nil {\n", indent, elem, dst))
+		code.WriteString(fmt.Sprintf("%s\t%s\n", indent, errReturn))
+		code.WriteString(fmt.Sprintf("%s}\n", indent))
+		return code.String()
+	}
+
+	code.WriteString(fmt.Sprintf("%selemBuf, err := %s.MarshalLayout()\n", indent, elem))
+	code.WriteString(fmt.Sprintf("%sif err != nil {\n", indent))
+	code.WriteString(fmt.Sprintf("%s\t%s\n", indent, errReturn))
+	code.WriteString(fmt.Sprintf("%s}\n", indent))
+	code.WriteString(fmt.Sprintf("%scopy(%s, elemBuf)\n", indent, dst))
+	return code.String()
+}
+
+// inlineImports returns the imports of the field operations inlined from nested
+// layout types, which the types holding them don't otherwise need
+func (g *Generator) inlineImports() []string {
+	var imports []string
+	seen := make(map[string]bool)
+	for _, region := range g.analyzed.Regions {
+		elemType := region.Field.GoType
+		if region.Kind == analyzer.DynamicRegion {
+			elemType = region.ElementType
+		}
+		if seen[elemType] {
+			continue
+		}
+		seen[elemType] = true
+		inline := g.inlineGenerator(elemType)
+		if inline == nil {
+			continue
+		}
+		if inline.usesBinary() {
+			imports = append(imports, "encoding/binary")
+		}
+		if inline.usesFloats() {
+			imports = append(imports, "math")
+		}
+		imports = append(imports, inline.kindImports()...)
+		imports = append(imports, inline.inlineImports()...)
+	}
+	return imports
+}
@@ -0,0 +1,146 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateInlineMarshal(t *testing.T) {
+	// @layout size=12 [metrics=true]
+	// type Elem struct {
+	//     Key   uint32 `layout:"@0,default=7"`
+	//     Value uint64 `layout:"@4"`
+	// }
+	//
+	// @layout size=64 [mode=zerocopy]
+	// type Page struct {
+	//     Head  Elem   `layout:"@0"`
+	//     N     uint8  `layout:"@12"`
+	//     Elems []Elem `layout:"@16,start-end,count=N"`
+	// }
+	tests := []struct {
+		name            string
+		mode            string
+		metrics         bool
+		expectedParts   []string
+		unexpectedParts []string
+	}{
+		{"copy", "copy", false, []string{
+			"\t{ // Elem inlined\n\t\tbuf := buf[0:12]\n\t\tp := &p.Head\n",
+			"\t\tif p.Key == 0 {\n\t\t\tp.Key = 7\n\t\t}\n",
+			"\t\tbinary.LittleEndian.PutUint32(buf[0:4], p.Key)\n",
+			"\t\tbinary.LittleEndian.PutUint64(buf[4:12], p.Value)\n\t}\n",
+			"\t\t{ // Elem inlined\n\t\t\tbuf := buf[offset:offset+12]\n\t\t\tp := &p.Elems[i]\n",
+		}, []string{"elemBuf", "clear(buf)"}},
+		{"zerocopy", "zerocopy", false, []string{
+			"\t{ // Elem inlined\n\t\tbuf := p.buf[0:12]\n\t\tp := &p.Head\n\t\tclear(buf) // Gaps are zero, as in a fresh encoding\n",
+		}, []string{"elemBuf"}},
+		{"metrics", "copy", true, []string{
+			"\telemBuf, err := p.Head.MarshalLayout()\n",
+		}, []string{"inlined"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elem := &parser.TypeLayout{
+				Name: "Elem",
+				Anno: &parser.TypeAnnotation{Size: 12, Metrics: tt.metrics},
+				Fields: []parser.Field{
+					{Name: "Key", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "7"}},
+					{Name: "Value", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+				},
+			}
+			page := &parser.TypeLayout{
+				Name: "Page",
+				Anno: &parser.TypeAnnotation{Size: 64, Mode: tt.mode},
+				Fields: []parser.Field{
+					{Name: "Head", GoType: "Elem", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed}},
+					{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			reg.Register("Elem", 12)
+			analyzed, err := analyzer.Analyze(page, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, page, []*parser.TypeLayout{elem, page}, reg, "little", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			marshal := code[strings.Index(code, ") MarshalLayout() ("):]
+			marshal = marshal[:strings.Index(marshal, "\n}\n")]
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(marshal, expected) {
+					t.Errorf("MarshalLayout missing: %q\n\nGenerated:\n%s", expected, marshal)
+				}
+			}
+			for _, unexpected := range tt.unexpectedParts {
+				if strings.Contains(marshal, unexpected) {
+					t.Errorf("MarshalLayout has: %q\n\nGenerated:\n%s", unexpected, marshal)
+				}
+			}
+			// The page itself uses binary only through Elem's inlined fields
+			if !tt.metrics && !slices.Contains(gen.Imports(), "encoding/binary") {
+				t.Errorf("Imports() = %v, want encoding/binary", gen.Imports())
+			}
+		})
+	}
+}
+
+func TestInlineGenerator(t *testing.T) {
+	fixed := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 4},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	dynamic := &parser.TypeLayout{
+		Name: "Cell",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "Len", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "Len"}},
+		},
+	}
+	zerocopy := &parser.TypeLayout{
+		Name: "ZHeader",
+		Anno: &parser.TypeAnnotation{Size: 4, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	imported := *fixed
+	imported.Name = "common.Header"
+	layouts := []*parser.TypeLayout{fixed, dynamic, zerocopy, &imported}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(fixed, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, fixed, layouts, reg, "little", "copy", 0, "")
+
+	tests := []struct {
+		elemType string
+		want     bool
+	}{
+		{"Header", true},
+		{"Cell", false},
+		{"ZHeader", false},
+		{"common.Header", false},
+		{"Missing", false},
+	}
+	for _, tt := range tests {
+		if got := gen.inlineGenerator(tt.elemType) != nil; got != tt.want {
+			t.Errorf("inlineGenerator(%q) inlines = %v, want %v", tt.elemType, got, tt.want)
+		}
+	}
+}
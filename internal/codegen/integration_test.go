@@ -96,15 +96,14 @@ func TestIntegrationSimplePage(t *testing.T) {
 		"binary.LittleEndian.PutUint16(buf[0:2], p.Header)",
 		// Body marshal (dynamic)
 		"offset = 2",
-		"for i := range p.Body",
-		"if offset >= 4088",
-		"buf[offset] = p.Body[i]",
+		"if offset+len(p.Body) > 4088",
+		"offset += copy(buf[offset:], p.Body)",
 		// Footer marshal
 		"binary.LittleEndian.PutUint64(buf[4088:4096], p.Footer)",
 		"return buf, nil",
 		// Unmarshal
 		"func (p *Page) UnmarshalLayout(buf []byte) error",
-		"if len(buf) != 4096",
+		"if len(buf) < 4096",
 		// Header unmarshal
 		"p.Header = binary.LittleEndian.Uint16(buf[0:2])",
 		// Body unmarshal with buffer reuse
@@ -244,15 +243,15 @@ func TestIntegrationBackwardGrowth(t *testing.T) {
 		t.Fatalf("Generate failed: %v", err)
 	}
 
-	// Verify backward iteration
-	if !strings.Contains(code, "for i := len(p.Keys) - 1; i >= 0; i--") {
-		t.Error("Missing backward iteration")
+	// Verify backward growth
+	if !strings.Contains(code, "if offset-len(p.Keys) < 2") {
+		t.Error("Missing collision check for backward growth")
 	}
-	if !strings.Contains(code, "offset--") {
-		t.Error("Missing offset decrement")
+	if !strings.Contains(code, "offset -= len(p.Keys)") {
+		t.Error("Missing offset moved before the bytes")
 	}
-	if !strings.Contains(code, "if offset < 2") {
-		t.Error("Missing collision check for backward growth")
+	if !strings.Contains(code, "copy(buf[offset:], p.Keys)") {
+		t.Error("Missing single copy for backward growth")
 	}
 
 	t.Logf("Generated code:\n%s", code)
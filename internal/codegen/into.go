@@ -0,0 +1,56 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkInto reports into=true on a mode whose MarshalLayout doesn't encode into a
+// buffer of its own making
+func (g *Generator) checkInto() error {
+	if g.layout.Anno.Into && g.mode != "copy" {
+		return fmt.Errorf("into=true requires mode=copy, mode=%s has no buffer to encode into", g.mode)
+	}
+	return nil
+}
+
+// generateMarshalInto generates MarshalLayoutInto (into=true): MarshalLayout on a
+// value receiver, encoding into the caller's buf instead of allocating one. Layouts
+// holding the type marshal their elements through it straight into their own
+// buffer. Defaults apply to the copy, so p is left as it was.
+func (g *Generator) generateMarshalInto() string {
+	typeName := g.analyzed.TypeName
+	size := g.analyzed.BufferSize
+
+	marshal := g.generateCopyMarshal()
+	marshal = strings.Replace(marshal, fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", typeName), "", 1)
+	intake := fmt.Sprintf("\tbuf = buf[:%d]\n\tclear(buf)\n", size)
+	if g.blit {
+		// The copy writes every byte, from the value p
+		intake = fmt.Sprintf("\tbuf = buf[:%d]\n", size)
+		marshal = strings.Replace(marshal, "unsafe.Pointer(p)", "unsafe.Pointer(&p)", 1)
+	}
+	marshal = strings.Replace(marshal, fmt.Sprintf("\tbuf := make([]byte, %d)\n", size), intake, 1)
+	marshal = strings.ReplaceAll(marshal, "return nil, ", "return 0, ")
+	marshal = strings.Replace(marshal, "\treturn buf, nil\n", fmt.Sprintf("\treturn %d, nil\n", size), 1)
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// MarshalLayoutInto encodes p into buf[:%d] without allocating and returns %d.\n", size, size))
+	code.WriteString("// buf may hold anything; every byte of the encoding is written.\n")
+	code.WriteString(fmt.Sprintf("func (p %s) MarshalLayoutInto(buf []byte) (int, error) {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tif len(buf) < %d {\n", size))
+	code.WriteString(fmt.Sprintf("\t\treturn 0, fmt.Errorf(\"expected at least %d bytes, got %%d: %%w\", len(buf), ErrShortBuffer)\n", size))
+	code.WriteString("\t}\n\n")
+	code.WriteString(marshal)
+	return code.String()
+}
+
+// marshalsInto reports whether the layout type elemType has MarshalLayoutInto
+func (g *Generator) marshalsInto(elemType string) bool {
+	for _, layout := range g.allLayouts {
+		if layout.Name == elemType {
+			return (layout.Anno.Into || layout.Anno.Batch) && (layout.Anno.Mode == "" || layout.Anno.Mode == "copy")
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateMarshalInto(t *testing.T) {
+	// @layout size=12 into=true
+	// type Elem struct {
+	//     Key   uint32 `layout:"@0,default=7"`
+	//     Value uint64 `layout:"@4"`
+	// }
+	//
+	// @layout size=64
+	// type Page struct {
+	//     Head  common.Elem   `layout:"@0"`
+	//     N     uint8         `layout:"@12"`
+	//     Elems []common.Elem `layout:"@16,start-end,count=N"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 12, Into: true},
+		Fields: []parser.Field{
+			{Name: "Key", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: "7"}},
+			{Name: "Value", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+		},
+	}
+	// Elem of the same package would be inlined, so the page holds an imported one
+	imported := *elem
+	imported.Name = "common.Elem"
+	page := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Head", GoType: "common.Elem", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]common.Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+	layouts := []*parser.TypeLayout{elem, page, &imported}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 12)
+	reg.Register("common.Elem", 12)
+
+	tests := []struct {
+		layout        *parser.TypeLayout
+		expectedParts []string
+	}{
+		{elem, []string{
+			"func (p Elem) MarshalLayoutInto(buf []byte) (int, error) {\n\tif len(buf) < 12 {\n" +
+				"\t\treturn 0, fmt.Errorf(\"expected at least 12 bytes, got %d: %w\", len(buf), ErrShortBuffer)\n\t}\n\n",
+			// Defaults apply to the receiver's copy
+			"\tif p.Key == 0 {\n\t\tp.Key = 7\n\t}\n",
+			"\tbuf = buf[:12]\n\tclear(buf)\n",
+			"\treturn 12, nil\n}\n",
+			// MarshalLayout is unchanged
+			"func (p *Elem) MarshalLayout() ([]byte, error) {\n",
+			"\tbuf := make([]byte, 12)\n",
+		}},
+		// Nested and slice elements are encoded into the page's buffer
+		{page, []string{
+			"\tif _, err := p.Head.MarshalLayoutInto(buf[0:12]); err != nil {\n\t\treturn nil, fmt.Errorf(\"marshal Head: %w\", err)\n\t}\n\n",
+			"\t\tif _, err := p.Elems[i].MarshalLayoutInto(buf[offset:offset+12]); err != nil {\n" +
+				"\t\t\treturn nil, fmt.Errorf(\"marshal Elems[%d]: %w\", i, err)\n\t\t}\n\t\toffset += 12\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout.Name, func(t *testing.T) {
+			analyzed, err := analyzer.Analyze(tt.layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			code, err := NewGenerator(analyzed, tt.layout, layouts, reg, "little", "copy", 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if strings.Contains(code, "elemBuf") {
+				t.Errorf("Generated code allocates element encodings\n\nGenerated:\n%s", code)
+			}
+		})
+	}
+}
+
+func TestGenerateMarshalIntoZeroCopy(t *testing.T) {
+	layout := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8, Mode: "zerocopy", Into: true},
+		Fields: []parser.Field{
+			{Name: "Key", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	_, err = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "").Generate()
+	if err == nil || !strings.Contains(err.Error(), "into=true requires mode=copy") {
+		t.Errorf("Generate() error = %v, want into=true refused", err)
+	}
+}
@@ -0,0 +1,259 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// generateJSON generates MarshalJSON and UnmarshalJSON for a zerocopy type, whose
+// struct fields are only refreshed by UnmarshalLayout: both go through the
+// accessors and the buffer instead, so a debugging endpoint shows what the page
+// holds. Fixed fields appear under their names, byte regions as base64 of their
+// used bytes (the whole region when its count doesn't tell), struct slices as
+// arrays of their elements. Reserved ranges are left out, and aliases are only
+// marshaled, their overlaid fields restoring them. Registers are only marshaled
+// if they can be read, access=wo ones staying zero, and only unmarshaled if they
+// can be written.
+func (g *Generator) generateJSON() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	jsonType := lowerFirst(typeName) + "JSON"
+
+	var fixed, dynamic []analyzer.Region
+	for _, region := range g.analyzed.Regions {
+		switch {
+		case region.Kind == analyzer.FixedRegion && !isReserved(region):
+			fixed = append(fixed, region)
+		case region.Kind == analyzer.DynamicRegion:
+			dynamic = append(dynamic, region)
+		}
+	}
+
+	// Fields in offset order, aligned as gofmt would. Those of unexported fields
+	// are exported for encoding/json, and all tagged with the names they have
+	// in the type.
+	type jsonField struct{ name, goType string }
+	var fields []jsonField
+	width, typeWidth, tagged := 0, 0, false
+	for _, region := range g.analyzed.Regions {
+		goType := region.Field.GoType
+		switch {
+		case region.Kind == analyzer.FixedRegion && isReserved(region):
+			continue
+		case region.Kind == analyzer.DynamicRegion && isByteRegion(region):
+			goType = "[]byte"
+		case region.Kind == analyzer.DynamicRegion:
+			goType = "[]" + region.ElementType
+		}
+		fields = append(fields, jsonField{region.Field.Name, goType})
+		width = max(width, len(region.Field.Name))
+		typeWidth = max(typeWidth, len(goType))
+		tagged = tagged || region.Field.Name != upperFirst(region.Field.Name)
+	}
+
+	code.WriteString(fmt.Sprintf("// %s is the JSON form of %s, filled from its buffer\n", jsonType, typeName))
+	code.WriteString(fmt.Sprintf("type %s struct {\n", jsonType))
+	for _, f := range fields {
+		if tagged {
+			code.WriteString(fmt.Sprintf("\t%-*s %-*s `json:\"%s\"`\n", width, upperFirst(f.name), typeWidth, f.goType, f.name))
+			continue
+		}
+		code.WriteString(fmt.Sprintf("\t%-*s %s\n", width, f.name, f.goType))
+	}
+	code.WriteString("}\n\n")
+
+	code.WriteString(g.generateMarshalJSON(jsonType, fixed, dynamic))
+	code.WriteString("\n")
+	code.WriteString(g.generateUnmarshalJSON(jsonType, fixed, dynamic))
+	return code.String()
+}
+
+// generateMarshalJSON generates MarshalJSON, reading fixed fields and struct
+// slice elements through their getters and byte regions from the buffer
+func (g *Generator) generateMarshalJSON(jsonType string, fixed, dynamic []analyzer.Region) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// MarshalJSON encodes the %s held in the buffer, read through its accessors\n", typeName))
+	code.WriteString(fmt.Sprintf("func (p *%s) MarshalJSON() ([]byte, error) {\n", typeName))
+	code.WriteString(g.generateJSONBufferCheck("MarshalJSON"))
+	code.WriteString(fmt.Sprintf("\tv := %s{\n", jsonType))
+	for _, region := range fixed {
+		if !readable(region) {
+			continue // Write-only registers can't be read back
+		}
+		code.WriteString(fmt.Sprintf("\t\t%s: p.Get%s(),\n", upperFirst(region.Field.Name), upperFirst(region.Field.Name)))
+	}
+	code.WriteString("\t}\n")
+
+	counts := make(map[string]bool)
+	for _, region := range dynamic {
+		field := region.Field
+		name := upperFirst(field.Name) // Of the mirror's field and the accessors
+		low, high := g.regionSpan(region)
+		if !isByteRegion(region) {
+			// Counts past the region's capacity stop at it, like Dump
+			stride := max(region.ElementStride, 1)
+			code.WriteString(fmt.Sprintf("\tv.%s = make([]%s, max(min(p.Get%sCount(), %d), 0))\n", name, region.ElementType, name, (high-low)/stride))
+			code.WriteString(fmt.Sprintf("\tfor i := range v.%s {\n", name))
+			code.WriteString(fmt.Sprintf("\t\tv.%s[i] = p.Get%sAt(i)\n", name, name))
+			code.WriteString("\t}\n")
+			continue
+		}
+
+		decl, used, ok := g.usedBytes(region, "p.buf", counts)
+		if !ok {
+			code.WriteString(fmt.Sprintf("\tv.%s = p.buf[%d:%d]\n", name, low, high))
+			continue
+		}
+		code.WriteString(decl)
+		if region.Direction == parser.EndStart {
+			code.WriteString(fmt.Sprintf("\tv.%s = p.buf[%d-%s : %d]\n", name, high, used, high))
+		} else {
+			code.WriteString(fmt.Sprintf("\tv.%s = p.buf[%d : %d+%s]\n", name, low, low, used))
+		}
+	}
+
+	code.WriteString("\treturn json.Marshal(&v)\n")
+	code.WriteString("}\n")
+	return code.String()
+}
+
+// generateUnmarshalJSON generates UnmarshalJSON, writing fixed fields through
+// their setters, counts last so their range checks apply, then the regions, and
+// refreshing the struct fields with UnmarshalLayout. Regions must hold as many
+// elements as their counts say; whole byte regions may be shorter and are
+// zero-filled past their bytes.
+func (g *Generator) generateUnmarshalJSON(jsonType string, fixed, dynamic []analyzer.Region) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// UnmarshalJSON writes the %s encoded in data into the buffer through its\n", typeName))
+	code.WriteString("// accessors, then decodes the buffer as UnmarshalLayout does\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalJSON(data []byte) error {\n", typeName))
+	code.WriteString(g.generateJSONBufferCheck("UnmarshalJSON"))
+	code.WriteString(fmt.Sprintf("\tvar v %s\n", jsonType))
+	code.WriteString("\tif err := json.Unmarshal(data, &v); err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+
+	var failing []analyzer.Region
+	for _, region := range fixed {
+		if isAlias(region) || !writable(region) {
+			continue
+		}
+		if g.setterFails(region) {
+			failing = append(failing, region)
+			continue
+		}
+		code.WriteString(fmt.Sprintf("\tp.Set%s(v.%s)\n", upperFirst(region.Field.Name), upperFirst(region.Field.Name)))
+	}
+	for _, region := range failing {
+		code.WriteString(fmt.Sprintf("\tif err := p.Set%s(v.%s); err != nil {\n", upperFirst(region.Field.Name), upperFirst(region.Field.Name)))
+		code.WriteString("\t\treturn fmt.Errorf(\"UnmarshalJSON: %w\", err)\n")
+		code.WriteString("\t}\n")
+	}
+
+	counts := make(map[string]bool)
+	for _, region := range dynamic {
+		field := region.Field
+		name := upperFirst(field.Name) // Of the mirror's field and the accessors
+		low, high := g.regionSpan(region)
+		if !isByteRegion(region) {
+			stride := max(region.ElementStride, 1)
+			code.WriteString(fmt.Sprintf("\tif len(v.%s) > %d {\n", name, (high-low)/stride))
+			code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"UnmarshalJSON: %s: %%d elements exceeds capacity %d: %%w\", len(v.%s), ErrRegionOverflow)\n",
+				field.Name, (high-low)/stride, name))
+			code.WriteString("\t}\n")
+			code.WriteString(fmt.Sprintf("\tif len(v.%s) != p.Get%sCount() {\n", name, name))
+			code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"UnmarshalJSON: %s has %%d elements, count says %%d: %%w\", len(v.%s), p.Get%sCount(), ErrCountMismatch)\n",
+				field.Name, name, name))
+			code.WriteString("\t}\n")
+			code.WriteString(fmt.Sprintf("\tfor i, elem := range v.%s {\n", name))
+			code.WriteString(fmt.Sprintf("\t\tp.Set%sAt(i, elem)\n", name))
+			code.WriteString("\t}\n")
+			continue
+		}
+
+		code.WriteString(fmt.Sprintf("\tif len(v.%s) > %d {\n", name, high-low))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"UnmarshalJSON: %s: %%d bytes exceeds capacity %d: %%w\", len(v.%s), ErrRegionOverflow)\n",
+			field.Name, high-low, name))
+		code.WriteString("\t}\n")
+
+		decl, _, ok := g.usedBytes(region, "p.buf", counts)
+		if !ok {
+			code.WriteString(fmt.Sprintf("\tclear(p.buf[%d+copy(p.buf[%d:%d], v.%s) : %d])\n", low, low, high, name, high))
+			continue
+		}
+		code.WriteString(decl)
+		count := g.usedCount(region)
+		code.WriteString(fmt.Sprintf("\tif len(v.%s) != %s {\n", name, count))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"UnmarshalJSON: %s has %%d bytes, count says %%d: %%w\", len(v.%s), %s, ErrCountMismatch)\n",
+			field.Name, name, count))
+		code.WriteString("\t}\n")
+		if region.Direction == parser.EndStart {
+			code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d-len(v.%s):%d], v.%s)\n", high, name, high, name))
+		} else {
+			code.WriteString(fmt.Sprintf("\tcopy(p.buf[%d:], v.%s)\n", low, name))
+		}
+	}
+
+	code.WriteString("\treturn p.UnmarshalLayout(p.buf[:])\n")
+	code.WriteString("}\n")
+	return code.String()
+}
+
+// generateJSONBufferCheck generates the check that a slice-backed buffer exists,
+// which New<Type> or SetBuffer provide; array-backed buffers always do
+func (g *Generator) generateJSONBufferCheck(method string) string {
+	if g.align == 0 && g.allocator == "" {
+		return ""
+	}
+	var code strings.Builder
+	fail := fmt.Sprintf("fmt.Errorf(\"%s: buffer of %%d bytes, want %d: %%w\", len(p.buf), ErrShortBuffer)", method, g.analyzed.BufferSize)
+	if method == "MarshalJSON" {
+		fail = "nil, " + fail
+	}
+	code.WriteString(fmt.Sprintf("\tif len(p.buf) < %d {\n", g.analyzed.BufferSize))
+	code.WriteString(fmt.Sprintf("\t\treturn %s\n", fail))
+	code.WriteString("\t}\n")
+	return code.String()
+}
+
+// setterFails reports whether the zerocopy setter of a fixed field returns an
+// error: range-checked counts, validated kinds and floatpolicy=strict floats
+func (g *Generator) setterFails(region analyzer.Region) bool {
+	if k, ok := kindOf(region); ok {
+		return g.kindValid(region.Field, k, "v") != ""
+	}
+	if region.Bits == 0 && floatBits(g.registry.ResolveType(region.Field.GoType)) > 0 {
+		return g.floatStrict()
+	}
+	_, ok := g.countCapacity(region.Field.Name)
+	return ok
+}
+
+// isByteRegion reports whether a dynamic region holds bytes, which have no
+// zerocopy accessors
+func isByteRegion(region analyzer.Region) bool {
+	return region.Field.GoType == "[]byte" || region.ElementType == "byte"
+}
+
+// lowerFirst returns name with its first letter lowercased, unexporting it
+func lowerFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// upperFirst returns name with its first letter uppercased, exporting it. The
+// identifiers generated from a field name, such as GetCount, use it, so those of
+// an unexported field (count) read like those of an exported one.
+func upperFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,89 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateJSON(t *testing.T) {
+	// @layout size=8
+	// type Elem struct {
+	//     K uint32 `layout:"@0"`
+	//     V uint32 `layout:"@4"`
+	// }
+	//
+	// @layout size=128 mode=zerocopy
+	// type Page struct {
+	//     buf   [128]byte
+	//     N     uint16  `layout:"@0"`
+	//     M     uint8   `layout:"@2"`
+	//     Pad   [4]byte `layout:"@3,reserve=4"`
+	//     Items []Elem  `layout:"@8,start-end,count=N,max=8"`
+	//     Tail  []byte  `layout:"@128,end-start,count=M"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "K", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "V", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "Pad", GoType: "[4]byte", Layout: &parser.FieldLayout{Offset: 3, Direction: parser.Fixed, Reserve: 4}},
+			{Name: "Items", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N", MaxCount: 8}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 128, Direction: parser.EndStart, CountField: "M"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	reg.RegisterFields("Elem", elem.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{elem, layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Fields in offset order, the reserved range left out
+		"type pageJSON struct {\n\tN     uint16\n\tM     uint8\n\tItems []Elem\n\tTail  []byte\n}\n",
+		"func (p *Page) MarshalJSON() ([]byte, error) {\n",
+		"\t\tN: p.GetN(),\n",
+		"\tv.Items = make([]Elem, max(min(p.GetItemsCount(), 15), 0))\n",
+		"\t\tv.Items[i] = p.GetItemsAt(i)\n",
+		"\tv.Tail = p.buf[128-max(min(countM, 120), 0) : 128]\n",
+		"\treturn json.Marshal(&v)\n",
+		"func (p *Page) UnmarshalJSON(data []byte) error {\n",
+		// Range-checked count setters fail the unmarshal
+		"\tif err := p.SetN(v.N); err != nil {\n",
+		"\tif len(v.Items) != p.GetItemsCount() {\n",
+		"\t\tp.SetItemsAt(i, elem)\n",
+		"\tif len(v.Tail) != countM {\n",
+		"\tcopy(p.buf[128-len(v.Tail):128], v.Tail)\n",
+		"\treturn p.UnmarshalLayout(p.buf[:])\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	imports := strings.Join(gen.Imports(), " ")
+	if !strings.Contains(imports, "encoding/json") {
+		t.Errorf("Imports() = %s, want encoding/json", imports)
+	}
+}
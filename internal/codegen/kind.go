@@ -0,0 +1,157 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+	"github.com/alexhholmes/layout/kind"
+)
+
+// kindOf returns the registered kind encoding a region's field, if any
+func kindOf(region analyzer.Region) (kind.Kind, bool) {
+	if region.Field.Layout == nil || region.Field.Layout.Kind == "" {
+		return kind.Kind{}, false
+	}
+	return kind.Lookup(region.Field.Layout.Kind)
+}
+
+// kindField describes a field to its kind, with the byte order of the type's
+// other fields: endian= in copy and stream mode, native in zerocopy mode
+func (g *Generator) kindField(field parser.Field) kind.Field {
+	f := parser.KindField(field)
+	f.ByteOrder = g.byteOrder()
+	return f
+}
+
+// kindImports returns the packages the kinds of the layout's fields import
+func (g *Generator) kindImports() []string {
+	var imports []string
+	for _, region := range g.analyzed.Regions {
+		if k, ok := kindOf(region); ok {
+			imports = append(imports, k.ImportsOf(parser.KindField(region.Field))...)
+		}
+	}
+	return imports
+}
+
+// kindBlock wraps the statements a kind returned in a block of their own,
+// indented one level deeper than indent. Kinds indent nested lines with tabs.
+func kindBlock(stmts, indent string) string {
+	var code strings.Builder
+	code.WriteString(indent + "{\n")
+	for _, line := range strings.Split(strings.TrimRight(stmts, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			code.WriteString("\n")
+			continue
+		}
+		code.WriteString(indent + "\t" + line + "\n")
+	}
+	code.WriteString(indent + "}\n")
+	return code.String()
+}
+
+// generateKindOp generates marshal/unmarshal code for a field encoded by its kind,
+// between p's field and the field's bytes of the buffer
+func (g *Generator) generateKindOp(region analyzer.Region, k kind.Kind, op string) string {
+	field := region.Field
+	bytes := fmt.Sprintf("buf[%d:%d]", region.Start, region.Boundary)
+	if g.mode == "zerocopy" {
+		bytes = "p." + bytes
+	}
+
+	code := fmt.Sprintf("\t// %s: %s at [%d, %d) (%s)\n", field.Name, field.GoType, region.Start, region.Boundary, k.Name)
+	if op == "marshal" {
+		code += g.kindValidCheck(field, k, "p."+field.Name, "nil, ")
+		code += kindBlock(k.Marshal(g.kindField(field), bytes, "p."+field.Name), "\t")
+	} else {
+		code += kindBlock(k.Unmarshal(g.kindField(field), bytes, "p."+field.Name), "\t")
+		code += g.kindValidCheck(field, k, "p."+field.Name, "")
+	}
+	return code + "\n"
+}
+
+// generateKindAccessors generates the zerocopy Get/Set of a field encoded by its
+// kind, decoding from and encoding into p.buf
+func (g *Generator) generateKindAccessors(region analyzer.Region, k kind.Kind) string {
+	var code strings.Builder
+	field := region.Field
+	bytes := fmt.Sprintf("p.buf[%d:%d]", region.Start, region.Boundary)
+
+	code.WriteString(fmt.Sprintf("// Get%s returns %s at offset %d, decoded as %s\n", upperFirst(field.Name), field.GoType, region.Start, k.Name))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%s() %s {\n", g.analyzed.TypeName, upperFirst(field.Name), field.GoType))
+	code.WriteString(fmt.Sprintf("\tvar v %s\n", field.GoType))
+	code.WriteString(kindBlock(k.Unmarshal(g.kindField(field), bytes, "v"), "\t"))
+	code.WriteString("\treturn v\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// Set%s sets %s at offset %d, encoded as %s\n", upperFirst(field.Name), field.GoType, region.Start, k.Name))
+	if g.kindValid(field, k, "v") == "" {
+		code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) {\n", g.analyzed.TypeName, upperFirst(field.Name), field.GoType))
+		code.WriteString(kindBlock(k.Marshal(g.kindField(field), bytes, "v"), "\t"))
+		code.WriteString("}\n\n")
+		return code.String()
+	}
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) error {\n", g.analyzed.TypeName, upperFirst(field.Name), field.GoType))
+	code.WriteString(g.kindValidCheck(field, k, "v", ""))
+	code.WriteString(kindBlock(k.Marshal(g.kindField(field), bytes, "v"), "\t"))
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
+
+// kindValid returns the kind's expression validating value, "" if it has none
+func (g *Generator) kindValid(field parser.Field, k kind.Kind, value string) string {
+	if k.Valid == nil {
+		return ""
+	}
+	return k.Valid(g.kindField(field), value)
+}
+
+// kindValidCheck generates the check refusing values the kind rejects, with
+// results preceding the error, e.g. "nil, ". Empty for kinds accepting any value.
+func (g *Generator) kindValidCheck(field parser.Field, k kind.Kind, value, results string) string {
+	valid := g.kindValid(field, k, value)
+	if valid == "" {
+		return ""
+	}
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\tif !(%s) {\n", valid))
+	code.WriteString(fmt.Sprintf("\t\treturn %sfmt.Errorf(\"%s: %%v is not a valid %s: %%w\", %s, ErrOutOfRange)\n",
+		results, field.Name, k.Name, value))
+	code.WriteString("\t}\n")
+	return code.String()
+}
+
+// generateKindMethods generates the extra methods kinds add for their fields,
+// reaching each field through p's field in copy mode and the accessors in zerocopy
+func (g *Generator) generateKindMethods() string {
+	var code strings.Builder
+	for _, region := range g.analyzed.Regions {
+		k, ok := kindOf(region)
+		if !ok || k.Methods == nil {
+			continue
+		}
+		name := region.Field.Name
+		a := kind.Accessor{
+			Type: g.analyzed.TypeName,
+			Get:  "p." + name,
+			Set:  "p." + name + " = %s",
+		}
+		if g.mode == "zerocopy" {
+			a.Get = fmt.Sprintf("p.Get%s()", upperFirst(name))
+			a.Set = fmt.Sprintf("p.Set%s(%%s)", upperFirst(name))
+			if g.kindValid(region.Field, k, "v") != "" {
+				a.Set = "_ = " + a.Set // Methods store only valid values
+			}
+		}
+		// Method names begin with the field name, exported like the accessors'
+		f := g.kindField(region.Field)
+		f.Name = upperFirst(f.Name)
+		code.WriteString("\n")
+		code.WriteString(k.Methods(f, a))
+	}
+	return code.String()
+}
@@ -0,0 +1,202 @@
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+	"github.com/alexhholmes/layout/kind"
+)
+
+func init() {
+	kind.Register(kind.Kind{
+		Name: "codegentestuuid",
+		Size: func(f kind.Field) (int, error) { return 16, nil },
+		Marshal: func(f kind.Field, dst, value string) string {
+			return fmt.Sprintf("b := %s.Bytes()\ncopy(%s, b)\n", value, dst)
+		},
+		Unmarshal: func(f kind.Field, src, value string) string {
+			return fmt.Sprintf("%s = uuid.FromBytes(%s)\n", value, src)
+		},
+		Imports: []string{"example.com/uuid"},
+	})
+}
+
+func TestGenerateKind(t *testing.T) {
+	// @layout size=32
+	// type Record struct {
+	//     Flags uint64    `layout:"@0"`
+	//     ID    uuid.UUID `layout:"@8,codegentestuuid"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Record",
+		Anno: &parser.TypeAnnotation{Size: 32},
+		Fields: []parser.Field{
+			{Name: "Flags", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "ID", GoType: "uuid.UUID", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, Kind: "codegentestuuid"}},
+		},
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"\t// ID: uuid.UUID at [8, 24) (codegentestuuid)\n\t{\n\t\tb := p.ID.Bytes()\n\t\tcopy(buf[8:24], b)\n\t}\n",
+			"\t{\n\t\tp.ID = uuid.FromBytes(buf[8:24])\n\t}\n",
+		}},
+		{"zerocopy", []string{
+			"\t{\n\t\tb := p.ID.Bytes()\n\t\tcopy(p.buf[8:24], b)\n\t}\n",
+			"func (p *Record) GetID() uuid.UUID {\n\tvar v uuid.UUID\n\t{\n\t\tv = uuid.FromBytes(p.buf[8:24])\n\t}\n\treturn v\n}\n",
+			"func (p *Record) SetID(v uuid.UUID) {\n\t{\n\t\tb := v.Bytes()\n\t\tcopy(p.buf[8:24], b)\n\t}\n}\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+
+			if !slices.Contains(gen.Imports(), "example.com/uuid") {
+				t.Errorf("Imports() = %v, want the kind's imports", gen.Imports())
+			}
+			// The kind owns its byte order
+			if strings.Contains(code, "dst[8]") || strings.Contains(code, "p.ID.ConvertEndian") {
+				t.Errorf("ConvertEndian swaps the kind's bytes:\n%s", code)
+			}
+		})
+	}
+}
+
+func TestGenerateKindValidAndMethods(t *testing.T) {
+	// @layout size=16
+	// type Frame struct {
+	//     Src netip.Addr `layout:"@0,ipaddr,width=4"`
+	//     HW  [6]byte    `layout:"@4,mac"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Frame",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "Src", GoType: "netip.Addr", Layout: &parser.FieldLayout{
+				Offset: 0, Direction: parser.Fixed, Kind: "ipaddr", KindParams: map[string]string{"width": "4"},
+			}},
+			{Name: "HW", GoType: "[6]byte", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Kind: "mac"}},
+		},
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"\tif !(!p.Src.IsValid() || p.Src.Unmap().Is4()) {\n\t\treturn nil, fmt.Errorf(\"Src: %v is not a valid ipaddr: %w\", p.Src, ErrOutOfRange)\n\t}\n",
+			"\t{\n\t\tif !p.Src.IsValid() {\n\t\t\tclear(buf[0:4])\n\t\t} else {\n",
+			"func (p *Frame) HWString() string {\n\taddr := p.HW\n",
+			"\tp.HW = [6]byte(addr)\n\treturn nil\n",
+		}},
+		{"zerocopy", []string{
+			"func (p *Frame) SetSrc(v netip.Addr) error {\n\tif !(!v.IsValid() || v.Unmap().Is4()) {\n",
+			"func (p *Frame) SetHW(v [6]byte) {\n",
+			"func (p *Frame) HWString() string {\n\taddr := p.GetHW()\n",
+			"\tp.SetHW([6]byte(addr))\n\treturn nil\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			for _, path := range []string{"net", "net/netip"} {
+				if !slices.Contains(gen.Imports(), path) {
+					t.Errorf("Imports() = %v, missing %s", gen.Imports(), path)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateKindByteOrder(t *testing.T) {
+	// @layout size=12 endian=big
+	// type Quote struct {
+	//     Price float64 `layout:"@0,fixed=16.16"`
+	//     Total int64   `layout:"@4,fixed=32.32"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Quote",
+		Anno: &parser.TypeAnnotation{Size: 12, Endian: "big"},
+		Fields: []parser.Field{
+			{Name: "Price", GoType: "float64", Layout: &parser.FieldLayout{
+				Offset: 0, Direction: parser.Fixed, Kind: "fixed", KindParams: map[string]string{"fixed": "16.16"},
+			}},
+			{Name: "Total", GoType: "int64", Layout: &parser.FieldLayout{
+				Offset: 4, Direction: parser.Fixed, Kind: "fixed", KindParams: map[string]string{"fixed": "32.32"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"binary.BigEndian.PutUint32(buf[0:4], uint32(int32(n)))",
+			"p.Total = int64(binary.BigEndian.Uint64(buf[4:12]))",
+			"dst[0], dst[1], dst[2], dst[3] = dst[3], dst[2], dst[1], dst[0] // Price\n",
+			"func (p *Quote) SetTotalFloat(v float64) error {\n",
+		}},
+		{"zerocopy", []string{
+			"binary.NativeEndian.PutUint32(p.buf[0:4], uint32(int32(n)))",
+			"\tp.SetTotal(int64(n))\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "big", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+		})
+	}
+}
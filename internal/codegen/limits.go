@@ -0,0 +1,87 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// generateCountCheck generates the unmarshal check that a counted region's count
+// field is within the region capacity and its max=, before anything is allocated
+// or sliced from it. The count comes from the buffer, so a corrupt or hostile
+// header would otherwise allocate up to the count type's maximum, then panic on
+// the out-of-range slice. Empty when the count type can't exceed the limit.
+//
+// A count shared by several regions is checked once, before the first of them,
+// against the smallest limit among them, which then holds for the rest.
+func (g *Generator) generateCountCheck(region analyzer.Region) string {
+	countField := region.Field.Layout.CountField
+	if countField == "" {
+		return ""
+	}
+	sharers := g.analyzed.SharedCount(countField)
+	if len(sharers) > 1 && sharers[0].Field.Name != region.Field.Name {
+		return "" // Checked before the first region sharing the count
+	}
+
+	var field parser.Field
+	limit, bound := -1, ""
+	for _, r := range sharers {
+		n, b := abs(r.Boundary-r.Start)/r.ElementStride, "capacity"
+		if max := r.Field.Layout.MaxCount; max > 0 && max < n {
+			n, b = max, "max"
+		}
+		if limit < 0 || n < limit {
+			field, limit, bound = r.Field, n, b
+		}
+	}
+
+	var conds []string
+	if strings.HasPrefix(g.registry.ResolveType(g.countFieldType(countField)), "int") {
+		conds = append(conds, fmt.Sprintf("p.%s < 0", countField))
+	}
+	if typeLimit := g.countLimit(countField); typeLimit < 0 || typeLimit > limit {
+		conds = append(conds, fmt.Sprintf("p.%s > %d", countField, limit))
+	}
+	if len(conds) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\tif %s {\n", strings.Join(conds, " || ")))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: count %%d outside %s %d: %%w\", p.%s, ErrRegionOverflow)\n",
+		field.Name, bound, limit, countField))
+	code.WriteString("\t}\n")
+	return code.String()
+}
+
+// generateIndirectCheck generates the unmarshal check that an indirect slice's
+// offset and size, read from its metadata element, stay within the data region
+func (g *Generator) generateIndirectCheck(field parser.Field, offsetVar string) string {
+	var code strings.Builder
+	region := field.Layout.Region
+
+	code.WriteString(fmt.Sprintf("\t\tif %s < 0 || size < 0 || %s+size > len(p.%s) {\n", offsetVar, offsetVar, region))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s[%%d]: [%%d, %%d) outside %s: %%w\", i, %s, %s+size, ErrRegionOverflow)\n",
+		field.Name, region, offsetVar, offsetVar))
+	code.WriteString("\t\t}\n")
+	return code.String()
+}
+
+// generateMaxCheck generates the marshal check refusing more elements than max=,
+// so MarshalLayout never writes a buffer UnmarshalLayout would refuse
+func generateMaxCheck(field parser.Field) string {
+	max := field.Layout.MaxCount
+	if max == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\tif len(p.%s) > %d {\n", field.Name, max))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s: %%d elements exceed max %d: %%w\", len(p.%s), ErrRegionOverflow)\n",
+		field.Name, max, field.Name))
+	code.WriteString("\t}\n")
+	return code.String()
+}
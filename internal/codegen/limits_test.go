@@ -0,0 +1,167 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateCountChecks(t *testing.T) {
+	// @layout size=128
+	// type Page struct {
+	//     N     uint16 `layout:"@0"`
+	//     M     int32  `layout:"@2"`
+	//     S     uint8  `layout:"@6"`
+	//     Body  []byte `layout:"@8,start-end,count=N,max=16"`
+	//     Elems []Elem `layout:"@64,start-end,count=M"`
+	//     Tail  []byte `layout:"end-start,count=S"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "int32", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "S", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N", MaxCount: 16}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 64, Direction: parser.StartEnd, CountField: "M"}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.EndStart, CountField: "S"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	for _, mode := range []string{"copy", "zerocopy"} {
+		gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", mode, 0, "")
+		code, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("%s: Generate() error: %v", mode, err)
+		}
+
+		expectedParts := []string{
+			// max= is below the region capacity
+			"\tif p.N > 16 {\n\t\treturn fmt.Errorf(\"Body: count %d outside max 16: %w\", p.N, ErrRegionOverflow)\n\t}\n",
+			// Signed counts may also be negative
+			"\tif p.M < 0 || p.M > 8 {\n\t\treturn fmt.Errorf(\"Elems: count %d outside capacity 8: %w\", p.M, ErrRegionOverflow)\n\t}\n",
+			"\tif p.S > 64 {\n\t\treturn fmt.Errorf(\"Tail: count %d outside capacity 64: %w\", p.S, ErrRegionOverflow)\n\t}\n",
+		}
+		if mode == "copy" {
+			// Marshal refuses what unmarshal would; zerocopy bytes are written by AppendBody
+			expectedParts = append(expectedParts,
+				"\tif len(p.Body) > 16 {\n\t\treturn nil, fmt.Errorf(\"Body: %d elements exceed max 16: %w\", len(p.Body), ErrRegionOverflow)\n\t}\n")
+		}
+		for _, expected := range expectedParts {
+			if !strings.Contains(code, expected) {
+				t.Errorf("%s: generated code missing: %q\n\nGenerated:\n%s", mode, expected, code)
+			}
+		}
+	}
+}
+
+func TestGenerateSharedCountCheck(t *testing.T) {
+	// @layout size=256
+	// type Page struct {
+	//     N      uint8  `layout:"@0"`
+	//     Keys   []Elem `layout:"@8,start-end,count=N"`   // 15 elements
+	//     Values []Elem `layout:"@128,start-end,count=N"` // 16 elements
+	//     Flags  []byte `layout:"@256,end-start,count=N"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 256},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Keys", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Values", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 128, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Flags", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 256, Direction: parser.EndStart, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	for _, mode := range []string{"copy", "zerocopy"} {
+		gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", mode, 0, "")
+		code, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("%s: Generate() error: %v", mode, err)
+		}
+
+		// One check, against the smallest region, before the first of them
+		check := "\tif p.N > 15 {\n\t\treturn fmt.Errorf(\"Keys: count %d outside capacity 15: %w\", p.N, ErrRegionOverflow)\n\t}\n"
+		if n := strings.Count(code, "if p.N > "); n != 1 {
+			t.Errorf("%s: %d count checks of N, want 1\n\nGenerated:\n%s", mode, n, code)
+		}
+		if !strings.Contains(code, check) {
+			t.Errorf("%s: generated code missing: %q\n\nGenerated:\n%s", mode, check, code)
+		}
+		// Each region is still checked against the count on marshal
+		if !strings.Contains(code, "\tif len(p.Values) != int(p.N) {\n") {
+			t.Errorf("%s: Values length not checked against N", mode)
+		}
+		// Appending to one region alone would leave the others miscounted
+		if strings.Contains(code, "func (p *Page) Append") {
+			t.Errorf("%s: Append helper generated for a shared count", mode)
+		}
+	}
+}
+
+func TestGenerateIndirectBoundsCheck(t *testing.T) {
+	// @layout size=128
+	// type Leaf struct {
+	//     Count uint16   `layout:"@0"`
+	//     Elems []Elem   `layout:"start-end,count=Count"`
+	//     Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data"`
+	//     Data  []byte   `layout:"end-start"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 4},
+		Fields: []parser.Field{
+			{Name: "KeyOff", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "KeySize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 128},
+		Fields: []parser.Field{
+			{Name: "Count", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "Count"}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize", Region: "Data", OffsetMode: parser.OffsetAfterMetadata}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.EndStart}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 4)
+	reg.RegisterFields("Elem", elem.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout, elem}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expected := "\t\tif offset < 0 || size < 0 || offset+size > len(p.Data) {\n" +
+		"\t\t\treturn fmt.Errorf(\"Keys[%d]: [%d, %d) outside Data: %w\", i, offset, offset+size, ErrRegionOverflow)\n" +
+		"\t\t}\n\t\tp.Keys[i] = p.Data[offset:offset+size]\n"
+	if !strings.Contains(code, expected) {
+		t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+	}
+}
@@ -0,0 +1,85 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetricsFile is the file holding the LayoutMetrics interface of a package, written
+// once per package directory when any of its layouts is annotated metrics=true
+const MetricsFile = "layout_metrics.go"
+
+// GenerateMetrics generates the metrics file of package pkg: the interface types
+// annotated metrics=true report their calls to, and the setter injecting it
+func GenerateMetrics(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("// LayoutMetrics observes the MarshalLayout and UnmarshalLayout calls of the types\n")
+	code.WriteString("// annotated metrics=true. typ is the type name, op is \"marshal\" or \"unmarshal\",\n")
+	code.WriteString("// n the bytes encoded or decoded (0 on error) and err the error returned.\n")
+	code.WriteString("// Implementations must be safe for concurrent use; counter vectors labeled by\n")
+	code.WriteString("// type and op, such as Prometheus CounterVecs, fit directly.\n")
+	code.WriteString("type LayoutMetrics interface {\n")
+	code.WriteString("\tObserve(typ, op string, n int, err error)\n")
+	code.WriteString("}\n\n")
+	code.WriteString("// layoutMetrics receives the observations; nil disables them\n")
+	code.WriteString("var layoutMetrics LayoutMetrics\n\n")
+	code.WriteString("// SetLayoutMetrics sets the metrics observing the generated code of the package;\n")
+	code.WriteString("// nil disables them. It is not synchronized with marshaling, so set it during\n")
+	code.WriteString("// initialization.\n")
+	code.WriteString("func SetLayoutMetrics(m LayoutMetrics) {\n")
+	code.WriteString("\tlayoutMetrics = m\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// instrumentMetrics renames the MarshalLayout and UnmarshalLayout methods in code
+// to marshalLayout and unmarshalLayout, and appends exported wrappers reporting
+// each call to layoutMetrics. Everything else calling the exported methods, such
+// as WriteTo or ReadFrame, is observed through them.
+func (g *Generator) instrumentMetrics(code string) string {
+	if !g.layout.Anno.Metrics {
+		return code
+	}
+	typeName := g.analyzed.TypeName
+
+	for _, method := range []string{"MarshalLayout", "UnmarshalLayout"} {
+		private := strings.ToLower(method[:1]) + method[1:]
+		code = strings.ReplaceAll(code, fmt.Sprintf("func (p *%s) %s(", typeName, method),
+			fmt.Sprintf("func (p *%s) %s(", typeName, private))
+		code = strings.ReplaceAll(code, fmt.Sprintf("\n// %s ", method), fmt.Sprintf("\n// %s ", private))
+		if strings.HasPrefix(code, "// "+method+" ") {
+			code = "// " + private + code[len("// "+method):]
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(code)
+	out.WriteString("\n")
+	out.WriteString("// MarshalLayout encodes p, reporting the call to the package's LayoutMetrics\n")
+	out.WriteString(fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", typeName))
+	out.WriteString("\tbuf, err := p.marshalLayout()\n")
+	out.WriteString("\tif m := layoutMetrics; m != nil {\n")
+	out.WriteString(fmt.Sprintf("\t\tm.Observe(%q, \"marshal\", len(buf), err)\n", typeName))
+	out.WriteString("\t}\n")
+	out.WriteString("\treturn buf, err\n")
+	out.WriteString("}\n\n")
+	out.WriteString("// UnmarshalLayout decodes buf into p, reporting the call to the package's\n")
+	out.WriteString("// LayoutMetrics\n")
+	out.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", typeName))
+	out.WriteString("\terr := p.unmarshalLayout(buf)\n")
+	out.WriteString("\tif m := layoutMetrics; m != nil {\n")
+	out.WriteString("\t\tn := len(buf)\n")
+	out.WriteString("\t\tif err != nil {\n")
+	out.WriteString("\t\t\tn = 0\n")
+	out.WriteString("\t\t}\n")
+	out.WriteString(fmt.Sprintf("\t\tm.Observe(%q, \"unmarshal\", n, err)\n", typeName))
+	out.WriteString("\t}\n")
+	out.WriteString("\treturn err\n")
+	out.WriteString("}\n")
+
+	return out.String()
+}
@@ -0,0 +1,94 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateMetrics(t *testing.T) {
+	code := GenerateMetrics("pages")
+
+	expectedParts := []string{
+		"// Code generated by layout. DO NOT EDIT.",
+		"package pages\n",
+		"type LayoutMetrics interface {\n\tObserve(typ, op string, n int, err error)\n}\n",
+		"var layoutMetrics LayoutMetrics\n",
+		"func SetLayoutMetrics(m LayoutMetrics) {\n\tlayoutMetrics = m\n}\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateMetricsWrappers(t *testing.T) {
+	// @layout size=16 metrics=true
+	// type Header struct {
+	//     Magic uint32 `layout:"@0"`
+	//     Body  []byte `layout:"start-end"`
+	// }
+	for _, mode := range []string{"copy", "zerocopy"} {
+		t.Run(mode, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Header",
+				Anno: &parser.TypeAnnotation{Size: 16, Metrics: true},
+				Fields: []parser.Field{
+					{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+				},
+			}
+
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+
+			expectedParts := []string{
+				"func (p *Header) marshalLayout() ([]byte, error) {\n",
+				"func (p *Header) unmarshalLayout(buf []byte) error {\n",
+				"func (p *Header) MarshalLayout() ([]byte, error) {\n\tbuf, err := p.marshalLayout()\n",
+				"\t\tm.Observe(\"Header\", \"marshal\", len(buf), err)\n",
+				"func (p *Header) UnmarshalLayout(buf []byte) error {\n\terr := p.unmarshalLayout(buf)\n",
+				"\t\tm.Observe(\"Header\", \"unmarshal\", n, err)\n",
+			}
+			for _, expected := range expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+
+			// The wrappers are the only exported definitions
+			for _, decl := range []string{"func (p *Header) MarshalLayout(", "func (p *Header) UnmarshalLayout("} {
+				if n := strings.Count(code, decl); n != 1 {
+					t.Errorf("%s defined %d times, want 1", decl, n)
+				}
+			}
+		})
+	}
+
+	// Without metrics=true the methods are generated directly
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	reg := analyzer.NewTypeRegistry()
+	analyzed, _ := analyzer.Analyze(layout, reg)
+	code, _ := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").Generate()
+	if strings.Contains(code, "layoutMetrics") || strings.Contains(code, "marshalLayout()") {
+		t.Errorf("Generated metrics without metrics=true:\n%s", code)
+	}
+}
@@ -0,0 +1,93 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minimal reports whether the layout's errors are the bare sentinels
+// (errors=minimal), leaving the generated code without fmt
+func (g *Generator) minimal() bool {
+	return g.layout != nil && g.layout.Anno != nil && g.layout.Anno.Errors == "minimal"
+}
+
+// checkUnsafe refuses unsafe=false layouts whose code needs package unsafe:
+// zerocopy accessors load through pointers into the buffer, mirror= asserts
+// offsets with unsafe.Offsetof, and blit=true copies the struct's memory
+func (g *Generator) checkUnsafe() error {
+	if !g.layout.Anno.NoUnsafe {
+		return nil
+	}
+	if g.mode == "zerocopy" {
+		return fmt.Errorf("unsafe=false requires mode=copy or mode=stream, zerocopy accessors use unsafe")
+	}
+	if g.layout.Anno.Mirror != "" {
+		return fmt.Errorf("unsafe=false cannot be combined with mirror=%s, whose assertions use unsafe.Offsetof", g.layout.Anno.Mirror)
+	}
+	if g.layout.Anno.Blit {
+		return fmt.Errorf("unsafe=false cannot be combined with blit=true, which copies the struct's memory through unsafe.Pointer")
+	}
+	return nil
+}
+
+// generatePanic returns a statement panicking with a message formatted from
+// format and args, or with the static text for errors=minimal
+func (g *Generator) generatePanic(indent, format, args, static string) string {
+	if g.minimal() {
+		return fmt.Sprintf("%spanic(%q)\n", indent, static)
+	}
+	return fmt.Sprintf("%spanic(fmt.Sprintf(%q, %s))\n", indent, format, args)
+}
+
+// stripErrorf rewrites every fmt.Errorf call of generated code into the error
+// it wraps, its last argument: the sentinel, or the error of the call that
+// failed. Errors lose their field context and the code its use of fmt.
+func stripErrorf(code string) string {
+	const call = "fmt.Errorf("
+	var out strings.Builder
+	for {
+		i := strings.Index(code, call)
+		if i < 0 {
+			out.WriteString(code)
+			return out.String()
+		}
+		out.WriteString(code[:i])
+		args, end := splitArgs(code[i+len(call):])
+		out.WriteString(strings.TrimSpace(args[len(args)-1]))
+		code = code[i+len(call)+end:]
+	}
+}
+
+// splitArgs splits the arguments of a call, starting after its opening
+// parenthesis, at the commas outside nested brackets and literals. It returns
+// them and the length up to and including the closing parenthesis.
+func splitArgs(s string) ([]string, int) {
+	var args []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			quote := s[i]
+			for i++; i < len(s) && s[i] != quote; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case '`':
+			i += 1 + strings.IndexByte(s[i+1:], '`')
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return append(args, s[start:i]), i + 1
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, s[start:]), len(s)
+}
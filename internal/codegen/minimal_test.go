@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestStripErrorf(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{
+			"\t\treturn nil, fmt.Errorf(\"Body: %d bytes exceeds capacity 12: %w\", len(p.Body), ErrRegionOverflow)\n",
+			"\t\treturn nil, ErrRegionOverflow\n",
+		},
+		// Commas and parentheses inside literals and nested calls don't split
+		{
+			"\treturn fmt.Errorf(\"got (%d, %c), want ')': %w\", min(a, b), ',', err)\n\treturn nil\n",
+			"\treturn err\n\treturn nil\n",
+		},
+		{
+			"\tif err != nil {\n\t\treturn 0, fmt.Errorf(\"a: %w\", err)\n\t}\n\treturn 0, fmt.Errorf(\"b %q: %w\", \"\\\"\", ErrBadMagic)\n",
+			"\tif err != nil {\n\t\treturn 0, err\n\t}\n\treturn 0, ErrBadMagic\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := stripErrorf(tt.code); got != tt.want {
+			t.Errorf("stripErrorf(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateMinimalErrors(t *testing.T) {
+	// @layout size=64 errors=minimal
+	// type Postings struct {
+	//     N   uint8    `layout:"@0"`
+	//     IDs []uint32 `layout:"start-end,count=N,encode=delta"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Postings",
+		Anno: &parser.TypeAnnotation{Size: 64, Errors: "minimal", NoUnsafe: true},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "IDs", GoType: "[]uint32", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Direction: parser.StartEnd, CountField: "N", Encode: parser.EncodeDelta}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"\t\treturn nil, ErrCountMismatch\n",
+		// The index only named the element in the error
+		"\t\tfor _, v := range p.IDs {\n\t\t\tif v < prev {\n\t\t\t\treturn nil, ErrOutOfRange\n",
+		"\t\t\t\treturn ErrRegionOverflow\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "fmt.") {
+		t.Errorf("Generated code uses fmt:\n%s", code)
+	}
+	if strings.Contains(code, ") Dump(") {
+		t.Error("Generated Dump, which formats with fmt")
+	}
+	if imports := gen.Imports(); slices.Contains(imports, "fmt") || slices.Contains(imports, "unsafe") {
+		t.Errorf("Imports() = %v, want neither fmt nor unsafe", imports)
+	}
+
+	// unsafe=false refuses code that can't do without it
+	zc := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	if _, err := zc.Generate(); err == nil || !strings.Contains(err.Error(), "unsafe=false requires mode=copy") {
+		t.Errorf("zerocopy Generate() error = %v, want unsafe=false refused", err)
+	}
+	layout.Anno.Mirror = "nativePostings"
+	if _, err := gen.Generate(); err == nil || !strings.Contains(err.Error(), "unsafe=false cannot be combined with mirror=") {
+		t.Errorf("Generate() with mirror= error = %v, want unsafe=false refused", err)
+	}
+}
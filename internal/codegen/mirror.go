@@ -0,0 +1,48 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// generateMirrorAssertions generates compile-time checks that the native struct
+// named by mirror= declares every fixed field at its tagged offset and size, for
+// code casting buffers to the struct (cgo, mmap). Each check builds an array whose
+// length is the constant difference: a larger offset or size mismatches the
+// [0]struct{} type and a smaller one is a negative constant, so editing an offset
+// in the tag or in the struct alone stops the package from compiling.
+func (g *Generator) generateMirrorAssertions() string {
+	mirror := g.layout.Anno.Mirror
+	if mirror == "" {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// %s mirrors the fixed fields of %s; these fail to compile when the offsets drift apart\n",
+		mirror, g.analyzed.TypeName))
+	code.WriteString("var (\n")
+	code.WriteString(g.offsetAssertions(mirror))
+	code.WriteString("\t// The buffer holds the whole struct\n")
+	code.WriteString(fmt.Sprintf("\t_ [%d - unsafe.Sizeof(%s{})]struct{}\n", g.analyzed.BufferSize, mirror))
+	code.WriteString(")\n")
+
+	return code.String()
+}
+
+// offsetAssertions generates the checks, within a var block, that the struct
+// native declares each fixed field of the layout at its tagged offset and size
+func (g *Generator) offsetAssertions(native string) string {
+	var code strings.Builder
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.FixedRegion || region.Bits > 0 || isReserved(region) {
+			continue
+		}
+		name := region.Field.Name
+		code.WriteString(fmt.Sprintf("\t// %s at [%d, %d)\n", name, region.Start, region.Boundary))
+		code.WriteString(fmt.Sprintf("\t_ [0]struct{} = [unsafe.Offsetof(%s{}.%s) - %d]struct{}{}\n", native, name, region.Start))
+		code.WriteString(fmt.Sprintf("\t_ [0]struct{} = [unsafe.Sizeof(%s{}.%s) - %d]struct{}{}\n", native, name, region.Boundary-region.Start))
+	}
+	return code.String()
+}
@@ -0,0 +1,72 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateMirrorAssertions(t *testing.T) {
+	// @layout size=32 mirror=RawHeader
+	// type Header struct {
+	//     Magic uint32 `layout:"@0"`
+	//     Kind  uint8  `layout:"@4.0,bits=4"`
+	//     _     struct{} `layout:"@5,reserve=3"`
+	//     ID    uint64 `layout:"@8"`
+	//     Body  []byte `layout:"start-end"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 32, Mirror: "RawHeader"},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Kind", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Bits: 4}},
+			{Name: "_", GoType: "struct{}", Layout: &parser.FieldLayout{Offset: 5, Direction: parser.Fixed, Reserve: 3}},
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.StartEnd}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"// RawHeader mirrors the fixed fields of Header",
+		"\t_ [0]struct{} = [unsafe.Offsetof(RawHeader{}.Magic) - 0]struct{}{}\n",
+		"\t_ [0]struct{} = [unsafe.Sizeof(RawHeader{}.Magic) - 4]struct{}{}\n",
+		"\t_ [0]struct{} = [unsafe.Offsetof(RawHeader{}.ID) - 8]struct{}{}\n",
+		"\t_ [0]struct{} = [unsafe.Sizeof(RawHeader{}.ID) - 8]struct{}{}\n",
+		"\t_ [32 - unsafe.Sizeof(RawHeader{})]struct{}\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Bit fields, reserved ranges and dynamic regions have no native counterpart
+	for _, name := range []string{"Kind", "_", "Body"} {
+		if strings.Contains(code, "RawHeader{}."+name+")") {
+			t.Errorf("Mirror should not assert %s\n\nGenerated:\n%s", name, code)
+		}
+	}
+
+	found := false
+	for _, path := range gen.Imports() {
+		found = found || path == "unsafe"
+	}
+	if !found {
+		t.Errorf("Imports() = %v, want unsafe for mirror assertions", gen.Imports())
+	}
+}
@@ -0,0 +1,44 @@
+package codegen
+
+import (
+	"go/token"
+	"go/types"
+	"path"
+	"slices"
+)
+
+// generatedIdents are the names generated code declares or refers to beside
+// those derived from field names: receivers, parameters and locals. Imported
+// packages and predeclared identifiers are reserved likewise.
+var generatedIdents = []string{
+	"p", "r", "b", "w", "buf", "backing", "err", "i", "j", "n", "at", "off", "offset", "addr",
+	"regionOffset", "elementsEnd", "elemBuf", "numElements", "out", "v", "value", "src", "dst",
+	"clone", "pageID", "low", "high",
+}
+
+// reservedIdent reports whether a local or parameter named name would shadow, or
+// fail to compile against, what the generated code around it refers to
+func (g *Generator) reservedIdent(name string) bool {
+	if token.IsKeyword(name) || types.Universe.Lookup(name) != nil || slices.Contains(generatedIdents, name) {
+		return true
+	}
+	for _, imp := range g.imports() {
+		if path.Base(imp) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// localName returns the name of a local or parameter derived from a field: the
+// field name with its first letter lowercased, then suffix. A name that is
+// reserved gets an N, so none shadows a package, builtin or generated local.
+// Fields differing only in the case of their first letter, whose locals would be
+// the same, are refused by the analyzer.
+func (g *Generator) localName(field, suffix string) string {
+	name := lowerFirst(field) + suffix
+	if g.reservedIdent(name) {
+		name += "N"
+	}
+	return name
+}
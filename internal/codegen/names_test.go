@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestLocalName(t *testing.T) {
+	// @layout size=64
+	// type Page struct {
+	//     Type uint8  `layout:"@0"`
+	//     Min  uint8  `layout:"@1"`
+	//     Body []byte `layout:"@8,start-end"`
+	//     Mid  uint32 `layout:"@32"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Min", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: 8, Direction: parser.StartEnd}},
+			{Name: "Mid", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 32, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+
+	tests := []struct {
+		field, suffix string
+		want          string
+	}{
+		{"Mid", "Len", "midLen"},
+		{"Type", "", "typeN"},     // Keyword
+		{"Min", "", "minN"},       // Builtin
+		{"Buf", "", "bufN"},       // Generated local
+		{"Binary", "", "binaryN"}, // Imported package
+		{"Body", "Len", "bodyLen"},
+		{"body", "Len", "bodyLen"},
+	}
+	for _, tt := range tests {
+		if got := gen.localName(tt.field, tt.suffix); got != tt.want {
+			t.Errorf("localName(%q, %q) = %q, want %q", tt.field, tt.suffix, got, tt.want)
+		}
+	}
+
+}
+
+func TestGenerateUnexportedFields(t *testing.T) {
+	// @layout size=64 mode=zerocopy
+	// type Rec struct {
+	//     count uint8  `layout:"@0"`
+	//     flags uint16 `layout:"@2"`
+	//     body  []byte `layout:"@8,start-end,count=count"`
+	//     buf   [64]byte
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Rec",
+		Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy"},
+		Fields: []parser.Field{
+			{Name: "count", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "flags", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "body", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "count"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Generated identifiers read as those of exported fields
+	for _, expected := range []string{
+		"\tRecCountOffset = 0\n",
+		"\tRecBodyCapacity = 56 // bytes\n",
+		"func (p *Rec) GetCount() uint8 {",
+		"func (p *Rec) SetFlags(v uint16) {",
+		"func (p *Rec) AppendBody(b []byte) error {",
+		// encoding/json ignores unexported fields, so the mirror's are exported
+		// and keep the field names as keys
+		"\tCount uint8  `json:\"count\"`\n",
+		"\tBody  []byte `json:\"body\"`\n",
+		"\t\tFlags: p.GetFlags(),\n",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	for _, unexpected := range []string{"Getcount", "Setflags", "Appendbody", "Recbody"} {
+		if strings.Contains(code, unexpected) {
+			t.Errorf("Generated code contains %q\n\nGenerated:\n%s", unexpected, code)
+		}
+	}
+}
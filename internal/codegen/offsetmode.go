@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// indirectBase returns the buffer offset the stored offsets of an indirect slice
+// count from, per its offsetmode=: "0" for page, the start of the data region's
+// space for region, and elementsEnd, the end of the metadata, for after-metadata.
+// The first two are constants; elementsEnd is a variable of the generated code.
+func (g *Generator) indirectBase(field parser.Field) string {
+	switch field.Layout.OffsetMode {
+	case parser.OffsetPage:
+		return "0"
+	case parser.OffsetRegion:
+		return fmt.Sprintf("%d", g.dataRegionStart(field.Layout.Region))
+	default:
+		return "elementsEnd"
+	}
+}
+
+// dataRegionStart returns the lowest buffer offset of the named data region's
+// space: the boundary a backward region grows down to, or a forward region's start
+func (g *Generator) dataRegionStart(name string) int {
+	for _, region := range g.analyzed.Regions {
+		if region.Field.Name != name {
+			continue
+		}
+		if region.Direction == parser.EndStart && region.Kind == analyzer.DynamicRegion {
+			return region.Boundary
+		}
+		return region.Start
+	}
+	return 0
+}
+
+// storedOffset returns the expression stored into the metadata for data at buffer
+// offset pos
+func (g *Generator) storedOffset(field parser.Field, pos string) string {
+	if base := g.indirectBase(field); base != "0" {
+		return fmt.Sprintf("%s - %s", pos, base)
+	}
+	return pos
+}
+
+// bufferOffset returns the buffer offset of data whose stored offset is stored
+func (g *Generator) bufferOffset(field parser.Field, stored string) string {
+	if base := g.indirectBase(field); base != "0" {
+		return fmt.Sprintf("%s + %s", base, stored)
+	}
+	return stored
+}
+
+// offsetOrigin describes what the offsets of a page or region offsetmode= count
+// from, for comments
+func offsetOrigin(field parser.Field) string {
+	if field.Layout.OffsetMode == parser.OffsetRegion {
+		return fmt.Sprintf("the start of %s", field.Layout.Region)
+	}
+	return "the page start"
+}
@@ -0,0 +1,160 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateOffsetModes(t *testing.T) {
+	// @layout size=128 mode=...
+	// type Leaf struct {
+	//     Count uint16   `layout:"@0"`
+	//     Elems []Elem   `layout:"@8,start-end,count=Count"`
+	//     Data  []byte   `layout:"end-start"`
+	//     Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data,offsetmode=..."`
+	//     buf   [128]byte // zerocopy
+	// }
+	newLayout := func(mode, offsetMode string) *parser.TypeLayout {
+		return &parser.TypeLayout{
+			Name: "Leaf",
+			Anno: &parser.TypeAnnotation{Size: 128, Mode: mode},
+			Fields: []parser.Field{
+				{Name: "Count", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "Count",
+				}},
+				{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+				{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{
+					Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize",
+					Region: "Data", OffsetMode: offsetMode,
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		mode       string
+		offsetMode string
+		want       []string
+	}{
+		// Copy marshal and unmarshal
+		{"copy", parser.OffsetPage, []string{
+			"\t\tp.Elems[i].KeyOff = uint32(offset)\n",
+			"\t\t// Offset counts from the page start, rebase onto Data\n\t\tregionOffset := offset - elementsEnd\n",
+		}},
+		{"copy", parser.OffsetRegion, []string{
+			"\t\tp.Elems[i].KeyOff = uint32(offset - 8)\n",
+			"\t\t// Offset counts from the start of Data, rebase onto Data\n\t\tregionOffset := 8 + offset - elementsEnd\n",
+		}},
+		{"copy", parser.OffsetAfterMetadata, []string{
+			"\telementsEnd := 8 + len(p.Elems)*8\n",
+			"\t\tp.Elems[i].KeyOff = uint32(offset - elementsEnd)\n",
+			"\t\tp.Keys[i] = p.Data[offset:offset+size]\n",
+		}},
+		// Zerocopy rebuild (marshal), unmarshal and accessors
+		{"zerocopy", parser.OffsetPage, []string{
+			"\t\tp.Elems[i].KeyOff = uint32(offset)\n",
+			"\t\tregionOffset := offset - elementsEnd\n",
+			"\tstart := int(elem.KeyOff)\n",
+		}},
+		{"zerocopy", parser.OffsetRegion, []string{
+			"\t\tp.Elems[i].KeyOff = uint32(offset - 8)\n",
+			"\t\tregionOffset := 8 + offset - elementsEnd\n",
+			"\tstart := 8 + int(elem.KeyOff)\n",
+		}},
+		{"zerocopy", parser.OffsetAfterMetadata, []string{
+			"\t\tp.Elems[i].KeyOff = uint32(offset - elementsEnd)\n",
+			"\t\tp.Keys[i] = p.Data[offset:offset+size]\n",
+			"\telementsEnd := 8 + p.GetElemsCount()*8\n\tstart := elementsEnd + int(elem.KeyOff)\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.offsetMode, func(t *testing.T) {
+			layout := newLayout(tt.mode, tt.offsetMode)
+			reg := analyzer.NewTypeRegistry()
+			reg.Register("Elem", 8)
+			reg.RegisterFields("Elem", []parser.Field{
+				{Name: "KeyOff", GoType: "uint16"},
+				{Name: "KeySize", GoType: "uint16"},
+			})
+
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.want {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+		})
+	}
+
+	// Unknown modes are rejected
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, _ := analyzer.Analyze(newLayout("copy", "field-end"), reg)
+	if errs := strings.Join(analyzed.Errors, "; "); !strings.Contains(errs, "offsetmode must be") {
+		t.Errorf("Expected offsetmode error, got: %s", errs)
+	}
+}
+
+func TestGenerateOffsetModeFromTag(t *testing.T) {
+	// offsetmode= parsed from the tag reaches the generated code
+	tags := map[string]string{
+		"absolute": "\t\tp.Elems[i].KeyOff = uint32(offset)\n",
+		"relative": "\t\tp.Elems[i].KeyOff = uint32(offset - elementsEnd)\n",
+	}
+	for mode, expected := range tags {
+		keys, err := parser.ParseTag("from=Elems,offset=KeyOff,size=KeySize,region=Data,offsetmode=" + mode)
+		if err != nil {
+			t.Fatalf("ParseTag() error: %v", err)
+		}
+
+		// @layout size=128
+		// type Leaf struct {
+		//     Count uint16   `layout:"@0"`
+		//     Elems []Elem   `layout:"@8,start-end,count=Count"`
+		//     Data  []byte   `layout:"end-start"`
+		//     Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data,offsetmode=..."`
+		// }
+		layout := &parser.TypeLayout{
+			Name: "Leaf",
+			Anno: &parser.TypeAnnotation{Size: 128},
+			Fields: []parser.Field{
+				{Name: "Count", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+					Offset: -1, Direction: parser.StartEnd, StartAt: 8, CountField: "Count",
+				}},
+				{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+				{Name: "Keys", GoType: "[][]byte", Layout: keys},
+			},
+		}
+
+		reg := analyzer.NewTypeRegistry()
+		reg.Register("Elem", 8)
+		analyzed, err := analyzer.Analyze(layout, reg)
+		if err != nil {
+			t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+		}
+
+		gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+		code, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+		if !strings.Contains(code, expected) {
+			t.Errorf("offsetmode=%s: generated code missing: %q\n\nGenerated:\n%s", mode, expected, code)
+		}
+	}
+}
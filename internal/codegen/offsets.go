@@ -0,0 +1,42 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// generateOffsetConstants generates <Type><Field>Offset and <Type><Field>Size for
+// each fixed field, naming the bytes it is encoded in for code patching a single
+// field in place. Bit fields share their bytes with others and are left out.
+func (g *Generator) generateOffsetConstants() string {
+	type constant struct {
+		name  string
+		value int
+	}
+	var constants []constant
+	width := 0
+	for _, r := range g.analyzed.Regions {
+		if r.Kind != analyzer.FixedRegion || r.Bits > 0 || r.Field.Name == "_" {
+			continue
+		}
+		name := g.analyzed.TypeName + upperFirst(r.Field.Name)
+		constants = append(constants,
+			constant{name + "Offset", r.Start},
+			constant{name + "Size", r.Boundary - r.Start})
+		width = max(width, len(name+"Offset"))
+	}
+	if len(constants) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("// Byte offsets and sizes of the fixed fields of %s\n", g.analyzed.TypeName))
+	code.WriteString("const (\n")
+	for _, c := range constants {
+		code.WriteString(fmt.Sprintf("\t%-*s = %d\n", width, c.name, c.value))
+	}
+	code.WriteString(")\n\n")
+	return code.String()
+}
@@ -0,0 +1,53 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateOffsetConstants(t *testing.T) {
+	// @layout size=4096
+	// type Page struct {
+	//     Header uint16 `layout:"@0"`
+	//     Flags  uint8  `layout:"@2.0,bits=4"`
+	//     _      struct{} `layout:"@3,reserve=5"`
+	//     Body   []byte `layout:"start-end"`
+	//     Footer uint64 `layout:"@4088"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 4096},
+		Fields: []parser.Field{
+			{Name: "Header", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed, Bits: 4}},
+			{Name: "_", GoType: "struct{}", Layout: &parser.FieldLayout{Offset: 3, Direction: parser.Fixed, Reserve: 5}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1}},
+			{Name: "Footer", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 4088, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expected := "// Byte offsets and sizes of the fixed fields of Page\nconst (\n" +
+		"\tPageHeaderOffset = 0\n\tPageHeaderSize   = 2\n" +
+		"\tPageFooterOffset = 4088\n\tPageFooterSize   = 8\n)\n"
+	if !strings.HasPrefix(code, expected) {
+		t.Errorf("Generated code does not start with:\n%s\nGenerated:\n%s", expected, code)
+	}
+	for _, unexpected := range []string{"PageFlagsOffset", "PageBodyOffset", "Page_Offset"} {
+		if strings.Contains(code, unexpected) {
+			t.Errorf("Generated code has: %q", unexpected)
+		}
+	}
+}
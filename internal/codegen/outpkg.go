@@ -0,0 +1,98 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// PrepareForeign prepares the layouts of package qualifier for generation into
+// another package (-output-package): field types naming the package's own aliases
+// are qualified, and aliases returns them under their qualified names for the
+// type registry. Generated code reaches the types from outside, so it can't use
+// unexported fields, methods (get=/set= hooks), other declarations (mirror=,
+// fixtures=) or a buffer inside the type (mode=zerocopy), and nested layouts of
+// the same file would lack their methods.
+func PrepareForeign(layouts []*parser.TypeLayout, aliases map[string]string, qualifier string) (map[string]string, error) {
+	local := make(map[string]bool)
+	for _, layout := range layouts {
+		local[layout.Name] = true
+	}
+
+	qualified := make(map[string]string, len(aliases))
+	for alias, underlying := range aliases {
+		if !strings.Contains(alias, ".") {
+			qualified[qualifier+"."+alias] = underlying
+		}
+	}
+
+	for _, layout := range layouts {
+		fail := func(format string, args ...any) error {
+			return fmt.Errorf("-output-package: %s: %s", layout.Name, fmt.Sprintf(format, args...))
+		}
+		switch {
+		case layout.Anno.Mode == "zerocopy":
+			return nil, fail("mode=zerocopy keeps its buffer in the type, generate it in its own package")
+		case layout.Generic != "":
+			return nil, fail("generic layouts are instantiated in their own package")
+		case layout.Anno.Mirror != "":
+			return nil, fail("mirror=%s names a type of package %s", layout.Anno.Mirror, qualifier)
+		case len(layout.Anno.Fixtures) > 0:
+			return nil, fail("fixtures= names functions of package %s", qualifier)
+		}
+
+		for i, field := range layout.Fields {
+			if field.Layout == nil || field.Name == "_" {
+				continue
+			}
+			if r, _ := utf8.DecodeRuneInString(field.Name); !unicode.IsUpper(r) {
+				return nil, fail("field %s is unexported, reachable only from package %s; export it or generate in its own package",
+					field.Name, qualifier)
+			}
+			if field.Layout.Get != "" || field.Layout.Set != "" {
+				return nil, fail("field %s has get=/set= hooks, methods of %s.%s", field.Name, qualifier, layout.Name)
+			}
+
+			base := field.GoType
+			for strings.HasPrefix(base, "[") && strings.Contains(base, "]") {
+				base = base[strings.Index(base, "]")+1:]
+			}
+			if local[base] {
+				return nil, fail("field %s nests %s, whose methods would be on another type", field.Name, base)
+			}
+			if _, ok := aliases[base]; ok && !strings.Contains(base, ".") {
+				layout.Fields[i].GoType = strings.TrimSuffix(field.GoType, base) + qualifier + "." + base
+			}
+		}
+	}
+	return qualified, nil
+}
+
+// GenerateForeignTypes generates, for layouts generated into another package than
+// qualifier, their own, the local types carrying the generated methods and the
+// exported functions marshaling the original types through them
+func GenerateForeignTypes(qualifier string, layouts []*parser.TypeLayout) string {
+	var code strings.Builder
+	for _, layout := range layouts {
+		name := layout.Name
+		original := qualifier + "." + name
+
+		code.WriteString(fmt.Sprintf("// %s carries the layout methods of %s, whose fields it shares.\n", name, original))
+		code.WriteString(fmt.Sprintf("// Convert a *%s with (*%s)(p).\n", original, name))
+		code.WriteString(fmt.Sprintf("type %s %s\n\n", name, original))
+
+		code.WriteString(fmt.Sprintf("// Marshal%s encodes p, as %s.MarshalLayout\n", name, name))
+		code.WriteString(fmt.Sprintf("func Marshal%s(p *%s) ([]byte, error) {\n", name, original))
+		code.WriteString(fmt.Sprintf("\treturn (*%s)(p).MarshalLayout()\n", name))
+		code.WriteString("}\n\n")
+
+		code.WriteString(fmt.Sprintf("// Unmarshal%s decodes buf into p, as %s.UnmarshalLayout\n", name, name))
+		code.WriteString(fmt.Sprintf("func Unmarshal%s(buf []byte, p *%s) error {\n", name, original))
+		code.WriteString(fmt.Sprintf("\treturn (*%s)(p).UnmarshalLayout(buf)\n", name))
+		code.WriteString("}\n\n")
+	}
+	return code.String()
+}
@@ -0,0 +1,110 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// foreignLayout returns a copy-mode page whose Type field is of the package's own
+// alias PageType
+func foreignLayout() *parser.TypeLayout {
+	// type PageType uint8
+	//
+	// @layout size=16
+	// type Page struct {
+	//     ID   uint64   `layout:"@0"`
+	//     Type PageType `layout:"@8"`
+	// }
+	return &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Type", GoType: "PageType", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+}
+
+func TestPrepareForeign(t *testing.T) {
+	layout := foreignLayout()
+	layouts := []*parser.TypeLayout{layout}
+	aliases, err := PrepareForeign(layouts, map[string]string{"PageType": "uint8", "common.PageID": "uint64"}, "pages")
+	if err != nil {
+		t.Fatalf("PrepareForeign() error: %v", err)
+	}
+	if got := layout.Fields[1].GoType; got != "pages.PageType" {
+		t.Errorf("Type GoType = %q, want pages.PageType", got)
+	}
+	if len(aliases) != 1 || aliases["pages.PageType"] != "uint8" {
+		t.Errorf("aliases = %v, want only pages.PageType", aliases)
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	for alias, underlying := range aliases {
+		reg.RegisterAlias(alias, underlying)
+	}
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err := NewGenerator(analyzed, layout, layouts, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	code = GenerateForeignTypes("pages", layouts) + code
+
+	expectedParts := []string{
+		"type Page pages.Page\n",
+		"func MarshalPage(p *pages.Page) ([]byte, error) {\n\treturn (*Page)(p).MarshalLayout()\n}\n",
+		"func UnmarshalPage(buf []byte, p *pages.Page) error {\n\treturn (*Page)(p).UnmarshalLayout(buf)\n}\n",
+		"func (p *Page) MarshalLayout() ([]byte, error) {\n",
+		"p.Type = pages.PageType(",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
+
+func TestPrepareForeignErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(layout *parser.TypeLayout) []*parser.TypeLayout
+		wantErr string
+	}{
+		{"zerocopy", func(l *parser.TypeLayout) []*parser.TypeLayout {
+			l.Anno.Mode = "zerocopy"
+			return nil
+		}, "mode=zerocopy keeps its buffer in the type"},
+		{"unexported field", func(l *parser.TypeLayout) []*parser.TypeLayout {
+			l.Fields[0].Name = "id"
+			return nil
+		}, "field id is unexported, reachable only from package"},
+		{"hook", func(l *parser.TypeLayout) []*parser.TypeLayout {
+			l.Fields[0].Layout.Get = "Checksum"
+			return nil
+		}, "get=/set= hooks"},
+		{"mirror", func(l *parser.TypeLayout) []*parser.TypeLayout {
+			l.Anno.Mirror = "nativePage"
+			return nil
+		}, "mirror=nativePage names a type of package pages"},
+		{"nested layout", func(l *parser.TypeLayout) []*parser.TypeLayout {
+			l.Fields[0].GoType = "[2]Header"
+			return []*parser.TypeLayout{{Name: "Header", Anno: &parser.TypeAnnotation{Size: 4}}}
+		}, "field ID nests Header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := foreignLayout()
+			layouts := append([]*parser.TypeLayout{layout}, tt.modify(layout)...)
+			_, err := PrepareForeign(layouts, map[string]string{"PageType": "uint8"}, "pages")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("PrepareForeign() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// isAlias reports whether a region aliases the bytes of other fields
+// (overlap=allow). Aliases are decoded, but the fields they overlay encode the
+// bytes, so marshaling and endian conversion skip them.
+func isAlias(region analyzer.Region) bool {
+	return region.Field.Layout != nil && region.Field.Layout.Overlap
+}
+
+// generateAliasMarshal generates the marshal code of an alias: only a note that
+// the overlaid fields encode its bytes
+func generateAliasMarshal(region analyzer.Region) string {
+	return fmt.Sprintf("\t// %s: aliases [%d, %d), encoded by the fields it overlays\n\n",
+		region.Field.Name, region.Start, region.Boundary)
+}
@@ -0,0 +1,53 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateOverlap(t *testing.T) {
+	// @layout size=10 endian=big
+	// type Packet struct {
+	//     Payload [8]byte `layout:"@0"`
+	//     Word    uint64  `layout:"@0,overlap=allow"`
+	//     Seq     uint16  `layout:"@8"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Packet",
+		Anno: &parser.TypeAnnotation{Size: 10, Endian: "big"},
+		Fields: []parser.Field{
+			{Name: "Payload", GoType: "[8]byte", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Word", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Overlap: true}},
+			{Name: "Seq", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "big", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"\t// Word: aliases [0, 8), encoded by the fields it overlays\n",
+		"\tp.Word = binary.BigEndian.Uint64(buf[0:8])\n",
+		"dst[8], dst[9] = dst[9], dst[8] // Seq\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	for _, unexpected := range []string{"PutUint64(buf[0:8], p.Word)", "// Word\n"} {
+		if strings.Contains(code, unexpected) {
+			t.Errorf("Generated code contains %q\n\nGenerated:\n%s", unexpected, code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generatePageIO generates ReadAt and WriteAt, addressing the encoding by page
+// number in a file of back-to-back pages: page pageID is the BufferSize bytes at
+// pageID*BufferSize. Zerocopy mode reads through LoadFromAt and writes p.buf, copy
+// mode reads and writes a buffer of its own.
+func (g *Generator) generatePageIO() string {
+	var code strings.Builder
+	typeName, size := g.analyzed.TypeName, g.analyzed.BufferSize
+
+	// Page numbers past this one have no int64 offset
+	maxPage := (1<<63 - 1) / size
+	pageOffset := func(method string) string {
+		var check strings.Builder
+		check.WriteString(fmt.Sprintf("\tif pageID > %d {\n", maxPage))
+		check.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: page %%d has no int64 offset: %%w\", pageID, ErrOutOfRange)\n", method))
+		check.WriteString("\t}\n")
+		check.WriteString(fmt.Sprintf("\toff := int64(pageID) * %d\n", size))
+		return check.String()
+	}
+
+	code.WriteString(fmt.Sprintf("// ReadAt reads page pageID of f, the %d bytes at offset pageID*%d, and decodes\n", size, size))
+	code.WriteString("// it. It returns io.EOF for a page past the end of f, and ErrTruncatedPage for a\n")
+	code.WriteString("// page f ends partway through.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) ReadAt(f io.ReaderAt, pageID uint64) error {\n", typeName))
+	code.WriteString(pageOffset("ReadAt"))
+	if g.mode == "zerocopy" {
+		code.WriteString("\treturn p.LoadFromAt(f, off)\n")
+	} else {
+		code.WriteString(fmt.Sprintf("\tbuf := make([]byte, %d)\n", size))
+		code.WriteString("\tn, err := f.ReadAt(buf, off)\n")
+		code.WriteString(fmt.Sprintf("\tif n < %d {\n", size))
+		code.WriteString("\t\tswitch {\n")
+		code.WriteString("\t\tcase n == 0 && err == io.EOF:\n")
+		code.WriteString("\t\t\treturn io.EOF\n")
+		code.WriteString("\t\tcase err == nil || err == io.EOF:\n")
+		code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"ReadAt: read %%d of %d bytes of page %%d: %%w\", n, pageID, ErrTruncatedPage)\n", size))
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t\treturn err\n")
+		code.WriteString("\t}\n")
+		code.WriteString("\treturn p.UnmarshalLayout(buf)\n")
+	}
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// WriteAt encodes p and writes it as page pageID of f, at offset pageID*%d\n", size))
+	code.WriteString(fmt.Sprintf("func (p *%s) WriteAt(f io.WriterAt, pageID uint64) error {\n", typeName))
+	code.WriteString(pageOffset("WriteAt"))
+	code.WriteString("\tbuf, err := p.MarshalLayout()\n")
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\t_, err = f.WriteAt(buf, off)\n")
+	code.WriteString("\treturn err\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGeneratePageIO(t *testing.T) {
+	// @layout size=4096 [mode=zerocopy]
+	// type Page struct {
+	//     ID  uint64 `layout:"@0"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 4096},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	common := []string{
+		"func (p *Page) ReadAt(f io.ReaderAt, pageID uint64) error {\n\tif pageID > 2251799813685247 {\n" +
+			"\t\treturn fmt.Errorf(\"ReadAt: page %d has no int64 offset: %w\", pageID, ErrOutOfRange)\n\t}\n\toff := int64(pageID) * 4096\n",
+		"func (p *Page) WriteAt(f io.WriterAt, pageID uint64) error {\n\tif pageID > 2251799813685247 {\n",
+		"\tbuf, err := p.MarshalLayout()\n\tif err != nil {\n\t\treturn err\n\t}\n\t_, err = f.WriteAt(buf, off)\n\treturn err\n}\n",
+	}
+	tests := []struct {
+		mode          string
+		expectedParts []string
+	}{
+		{"copy", []string{
+			"\tbuf := make([]byte, 4096)\n\tn, err := f.ReadAt(buf, off)\n\tif n < 4096 {\n",
+			"\t\t\treturn fmt.Errorf(\"ReadAt: read %d of 4096 bytes of page %d: %w\", n, pageID, ErrTruncatedPage)\n",
+			"\treturn p.UnmarshalLayout(buf)\n}\n",
+		}},
+		{"zerocopy", []string{
+			"\toff := int64(pageID) * 4096\n\treturn p.LoadFromAt(f, off)\n}\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range append(common, tt.expectedParts...) {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+		})
+	}
+}
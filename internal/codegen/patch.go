@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// patchable reports whether a fixed field can be rewritten in an encoded buffer
+// on its own. Count fields would disagree with their regions, magics, reserved
+// ranges, aliases and get= fields aren't set by the caller, present= slots move
+// with their bitmap, cap= arrays and their counts change together, and nested
+// structs may hold all of these.
+func (g *Generator) patchable(region analyzer.Region) bool {
+	l := region.Field.Layout
+	if region.Kind != analyzer.FixedRegion || l == nil || l.Magic || l.Get != "" || isReserved(region) || isAlias(region) || isPresent(region) {
+		return false
+	}
+	if isCapSlice(region) || g.isCapSliceCount(region.Field.Name) {
+		return false
+	}
+	if _, ok := g.countCapacity(region.Field.Name); ok {
+		return false
+	}
+	if _, ok := kindOf(region); ok || region.Bits > 0 {
+		return true
+	}
+	resolved := g.registry.ResolveType(region.Field.GoType)
+	if _, ok := g.emitters()[resolved]; ok || floatBits(resolved) > 0 {
+		return true
+	}
+	goType := region.Field.GoType
+	return strings.HasPrefix(goType, "[") && strings.HasSuffix(goType, "]byte")
+}
+
+// generatePatchers generates Patch<Type><Field>(buf, v) for each patchable fixed
+// field of a copy-mode type, writing the one field into an encoded buffer without
+// decoding and re-encoding the rest. The field's MarshalLayout code encodes it
+// from a value holding only v, so range checks and floatpolicy= apply as usual.
+func (g *Generator) generatePatchers() string {
+	var code strings.Builder
+	typeName, size := g.analyzed.TypeName, g.analyzed.BufferSize
+
+	for _, region := range g.analyzed.Regions {
+		if !g.patchable(region) {
+			continue
+		}
+		field := region.Field
+		name := fmt.Sprintf("Patch%s%s", typeName, upperFirst(field.Name))
+		op := strings.ReplaceAll(g.generateFixedOp(region, "marshal"), "return nil, ", "return ")
+
+		code.WriteString(fmt.Sprintf("\n// %s writes v as the %s of the %s encoded in buf, leaving its\n", name, field.Name, typeName))
+		code.WriteString("// other bytes as they are\n")
+		code.WriteString(fmt.Sprintf("func %s(buf []byte, v %s) error {\n", name, field.GoType))
+		code.WriteString(fmt.Sprintf("\tif len(buf) < %d {\n", size))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"expected %d bytes, got %%d: %%w\", len(buf), ErrShortBuffer)\n", size))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\tif len(buf) > %d {\n", size))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"expected %d bytes, got %%d: %%w\", len(buf), ErrLongBuffer)\n", size))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\tp := %s{%s: v}\n\n", typeName, field.Name))
+		code.WriteString(op)
+		code.WriteString("\treturn nil\n")
+		code.WriteString("}\n")
+	}
+	return code.String()
+}
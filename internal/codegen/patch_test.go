@@ -0,0 +1,70 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGeneratePatchers(t *testing.T) {
+	// @layout size=64
+	// type Node struct {
+	//     Magic uint32 `layout:"@0,magic=0xCAFE"`
+	//     N     uint16 `layout:"@4"`
+	//     Flags uint8  `layout:"@6.0,bits=4"`
+	//     LSN   uint64 `layout:"@8"`
+	//     Keys  []byte `layout:"start-end,count=N"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Node",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Magic", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Magic: true, Default: "0xCAFE"}},
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "Flags", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed, Bits: 4}},
+			{Name: "LSN", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			{Name: "Keys", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	tests := []struct {
+		mode            string
+		expectedParts   []string
+		unexpectedParts []string
+	}{
+		{"copy", []string{
+			"// PatchNodeLSN writes v as the LSN of the Node encoded in buf, leaving its\n// other bytes as they are\n" +
+				"func PatchNodeLSN(buf []byte, v uint64) error {\n\tif len(buf) < 64 {\n",
+			"\tp := Node{LSN: v}\n\n\t// LSN: uint64 at [8, 16)\n\tbinary.LittleEndian.PutUint64(buf[8:16], p.LSN)\n\n\treturn nil\n}\n",
+			"func PatchNodeFlags(buf []byte, v uint8) error {\n",
+			"\t\treturn fmt.Errorf(\"Flags: %d exceeds 4 bits: %w\", p.Flags, ErrOutOfRange)\n",
+		}, []string{"PatchNodeMagic", "PatchNodeN(", "PatchNodeKeys"}},
+		{"zerocopy", nil, []string{"func Patch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "").Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			for _, unexpected := range tt.unexpectedParts {
+				if strings.Contains(code, unexpected) {
+					t.Errorf("Generated code has: %q", unexpected)
+				}
+			}
+		})
+	}
+}
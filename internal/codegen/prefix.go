@@ -0,0 +1,151 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// isPrefix reports whether an indirect slice stores each entry after the prefix
+// it shares with the one before it (encode=prefix)
+func isPrefix(field parser.Field) bool {
+	return field.Layout.From != "" && field.Layout.Encode == parser.EncodePrefix
+}
+
+// usesPrefix reports whether the code encodes shared prefix lengths
+func (g *Generator) usesPrefix() bool {
+	if g.layout == nil {
+		return false
+	}
+	for _, field := range g.layout.Fields {
+		if isPrefix(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedPrefix returns statements counting into shared the leading bytes entry
+// has in common with prev
+func sharedPrefix(prev, entry, indent string) string {
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("%sshared := 0\n", indent))
+	code.WriteString(fmt.Sprintf("%sfor shared < len(%s) && shared < len(%s) && %s[shared] == %s[shared] {\n",
+		indent, prev, entry, prev, entry))
+	code.WriteString(fmt.Sprintf("%s\tshared++\n", indent))
+	code.WriteString(fmt.Sprintf("%s}\n", indent))
+	return code.String()
+}
+
+// generatePrefixPack generates the body of the backward packing loop for an
+// encode=prefix slice: entry i stored as the uvarint length of the prefix it
+// shares with entry i-1, then the bytes after it, the first sharing nothing.
+// It leaves size, the stored bytes, and offset, where they start.
+func (g *Generator) generatePrefixPack(field parser.Field) string {
+	var code strings.Builder
+	name := field.Name
+
+	code.WriteString(fmt.Sprintf("\t\t// Stored as the length shared with %s[i-1], then the rest\n", name))
+	code.WriteString("\t\tvar prev []byte\n")
+	code.WriteString("\t\tif i > 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\t\tprev = p.%s[i-1]\n", name))
+	code.WriteString("\t\t}\n")
+	code.WriteString(sharedPrefix("prev", fmt.Sprintf("p.%s[i]", name), "\t\t"))
+	code.WriteString("\t\tvar head [binary.MaxVarintLen64]byte\n")
+	code.WriteString("\t\tn := binary.PutUvarint(head[:], uint64(shared))\n")
+	code.WriteString(fmt.Sprintf("\t\tsize := n + len(p.%s[i]) - shared\n", name))
+	code.WriteString("\t\toffset -= size\n")
+	code.WriteString("\t\tcopy(buf[offset:], head[:n])\n")
+	code.WriteString(fmt.Sprintf("\t\tcopy(buf[offset+n:offset+size], p.%s[i][shared:])\n", name))
+	return code.String()
+}
+
+// generatePrefixEntry generates unmarshal code rebuilding entry i of an
+// encode=prefix slice from its stored bytes, the expression given, into a slice
+// of its own: the prefix it shares with entry i-1, then the rest. A shared
+// length running past its bytes or past the entry before it is refused.
+func (g *Generator) generatePrefixEntry(field parser.Field, stored string) string {
+	var code strings.Builder
+	name := field.Name
+
+	code.WriteString(fmt.Sprintf("\t\tstored := %s\n", stored))
+	code.WriteString("\t\tshared, n := binary.Uvarint(stored)\n")
+	code.WriteString("\t\tif n <= 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s[%%d]: shared prefix length outside its %%d bytes: %%w\", i, size, ErrRegionOverflow)\n", name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tvar prev []byte\n")
+	code.WriteString("\t\tif i > 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\t\tprev = p.%s[i-1]\n", name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tif shared > uint64(len(prev)) {\n")
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s[%%d]: shares %%d bytes with the %%d before it: %%w\", i, shared, len(prev), ErrOutOfRange)\n", name))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tentry := make([]byte, 0, int(shared)+len(stored)-n)\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s[i] = append(append(entry, prev[:shared]...), stored[n:]...)\n", name))
+	return code.String()
+}
+
+// generatePrefixInserts generates Insert<Field> for each encode=prefix slice of
+// a layout SplitInto and MergeFrom move entries of: it inserts a copy of an
+// entry at its sorted place, with a zero element and empty entries in the other
+// slices at the same index, and sets the count. Sorted entries sit next to the
+// ones they share most with, which is what makes prefix encoding pay.
+func (g *Generator) generatePrefixInserts() string {
+	entries, ok := g.splitMetadata()
+	if !ok {
+		return ""
+	}
+	typeName := g.analyzed.TypeName
+
+	var code strings.Builder
+	for _, field := range entries.fields {
+		if !isPrefix(field) {
+			continue
+		}
+		name := field.Name
+		var others []string
+		for _, other := range entries.fields {
+			if other.Name != name {
+				others = append(others, other.Name)
+			}
+		}
+
+		list := others[0]
+		if n := len(others); n > 1 {
+			list = strings.Join(others[:n-1], ", ") + " and " + others[n-1]
+		}
+		code.WriteString(fmt.Sprintf("\n// Insert%s inserts a copy of entry into %s at its sorted place, before\n", upperFirst(name), name))
+		code.WriteString(fmt.Sprintf("// any equal entry, with zero entries of %s at the same index,\n", list))
+		code.WriteString("// and sets the count. It returns the index. Sorted neighbours share the\n")
+		code.WriteString(fmt.Sprintf("// longest prefixes, which %s stores once.\n", name))
+		code.WriteString(fmt.Sprintf("func (p *%s) Insert%s(entry []byte) int {\n", typeName, upperFirst(name)))
+		code.WriteString(fmt.Sprintf("\ti, _ := slices.BinarySearchFunc(p.%s, entry, bytes.Compare)\n", name))
+		for _, other := range entries.fields {
+			value := "bytes.Clone(entry)"
+			if other.Name != name {
+				value = g.zeroElement(other)
+			}
+			code.WriteString(fmt.Sprintf("\tp.%s = slices.Insert(p.%s, i, %s)\n", other.Name, other.Name, value))
+		}
+		code.WriteString(g.splitCounts(entries, "p"))
+		code.WriteString("\treturn i\n")
+		code.WriteString("}\n")
+	}
+	return code.String()
+}
+
+// zeroElement returns the zero value of an element of a slice field: nil for
+// [][]byte, a composite literal for layout types, *new(T) for the rest
+func (g *Generator) zeroElement(field parser.Field) string {
+	elemType := strings.TrimPrefix(field.GoType, "[]")
+	if elemType == "[]byte" {
+		return "nil"
+	}
+	for _, layout := range g.allLayouts {
+		if layout.Name == elemType {
+			return elemType + "{}"
+		}
+	}
+	return fmt.Sprintf("*new(%s)", elemType)
+}
@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGeneratePrefix(t *testing.T) {
+	// @layout size=8
+	// type Elem struct {
+	//     KeyOffset   uint16 `layout:"@0"`
+	//     KeySize     uint16 `layout:"@2"`
+	//     ValueOffset uint16 `layout:"@4"`
+	//     ValueSize   uint16 `layout:"@6"`
+	// }
+	//
+	// @layout size=512
+	// type Leaf struct {
+	//     N      uint16   `layout:"@0"`
+	//     Elems  []Elem   `layout:"@16,start-end,count=N"`
+	//     Data   []byte   `layout:"end-start"`
+	//     Keys   [][]byte `layout:"from=Elems,offset=KeyOffset,size=KeySize,region=Data,encode=prefix"`
+	//     Values [][]byte `layout:"from=Elems,offset=ValueOffset,size=ValueSize,region=Data"`
+	// }
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "KeyOffset", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "KeySize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "ValueOffset", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "ValueSize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 512},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.EndStart}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, From: "Elems", OffsetField: "KeyOffset", SizeField: "KeySize", Region: "Data",
+				Encode: parser.EncodePrefix}},
+			{Name: "Values", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, From: "Elems", OffsetField: "ValueOffset", SizeField: "ValueSize", Region: "Data"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	reg.RegisterFields("Elem", elem.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{elem, layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal stores the length shared with the key before, then the rest
+		"\t// Keys: [][]byte packed backward into Data, updating Elems metadata (prefix-encoded)\n",
+		"\t\tfor shared < len(prev) && shared < len(p.Keys[i]) && prev[shared] == p.Keys[i][shared] {\n",
+		"\t\tn := binary.PutUvarint(head[:], uint64(shared))\n\t\tsize := n + len(p.Keys[i]) - shared\n",
+		"\t\tcopy(buf[offset+n:offset+size], p.Keys[i][shared:])\n",
+		// Values are packed as they are
+		"\t\tsize := len(p.Values[i])\n",
+		// Unmarshal rebuilds each key into a slice of its own
+		"\t\tstored := p.Data[offset:offset+size]\n\t\tshared, n := binary.Uvarint(stored)\n",
+		"\t\tif shared > uint64(len(prev)) {\n",
+		"\t\tp.Keys[i] = append(append(entry, prev[:shared]...), stored[n:]...)\n",
+		"\t\tp.Values[i] = p.Data[offset:offset+size]\n",
+		// Insert keeps the keys sorted and every slice in step
+		"func (p *Leaf) InsertKeys(entry []byte) int {\n\ti, _ := slices.BinarySearchFunc(p.Keys, entry, bytes.Compare)\n",
+		"\tp.Elems = slices.Insert(p.Elems, i, Elem{})\n\tp.Keys = slices.Insert(p.Keys, i, bytes.Clone(entry))\n\tp.Values = slices.Insert(p.Values, i, nil)\n\tp.N = uint16(len(p.Elems))\n",
+		// MergeFrom counts the keys as stored once merged
+		"\tvar prevKeys []byte // Right's first entry follows p's last\n",
+		"\t\t\tusedData += binary.PutUvarint(head[:], uint64(shared)) + len(entry) - shared\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "func LeafMaxKeysSize(") {
+		t.Error("generated LeafMaxKeysSize for prefix-encoded Keys, whose stored size depends on the key before")
+	}
+	if imports := gen.Imports(); !slices.Contains(imports, "slices") || !slices.Contains(imports, "encoding/binary") {
+		t.Errorf("Imports() = %v, want slices and encoding/binary", imports)
+	}
+}
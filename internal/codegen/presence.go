@@ -0,0 +1,126 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// isPresent reports whether a region is an array of optional slots (present=)
+func isPresent(region analyzer.Region) bool {
+	return region.Field.Layout != nil && region.Field.Layout.Present != ""
+}
+
+// presentSlots returns the slot count, slot type and slot size of a present= field
+func (g *Generator) presentSlots(region analyzer.Region) (int, string, int) {
+	n, slotType, _ := analyzer.PresentSlots(region.Field.GoType)
+	return n, slotType, (region.Boundary - region.Start) / n
+}
+
+// generatePresentOp generates copy-mode marshal/unmarshal code for a present=
+// field: the slots whose bit is set in the bitmap field, packed in order from the
+// field's offset. Both refuse bitmap bits past the last slot, which no slot
+// round-trips; unmarshal zeroes absent slots.
+func (g *Generator) generatePresentOp(region analyzer.Region, op string) string {
+	var code strings.Builder
+	field, present := region.Field.Name, region.Field.Layout.Present
+	n, slotType, size := g.presentSlots(region)
+	unsigned := fmt.Sprintf("uint%d", size*8)
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d), the slots present in %s packed in order\n",
+		field, region.Field.GoType, region.Start, region.Boundary, present))
+	if n < g.bitmapBits(present) {
+		results := ""
+		if op == "marshal" {
+			results = "nil, "
+		}
+		code.WriteString(fmt.Sprintf("\tif p.%s>>%d != 0 {\n", present, n))
+		code.WriteString(fmt.Sprintf("\t\treturn %sfmt.Errorf(\"%s: %%#x marks slots past the %d of %s: %%w\", p.%s, ErrOutOfRange)\n",
+			results, present, n, field, present))
+		code.WriteString("\t}\n")
+	}
+	code.WriteString("\t{\n")
+	code.WriteString(fmt.Sprintf("\t\toff := %d\n", region.Start))
+	if op == "marshal" {
+		code.WriteString(fmt.Sprintf("\t\tfor i, v := range p.%s {\n", field))
+		code.WriteString(fmt.Sprintf("\t\t\tif p.%s&(1<<i) == 0 {\n", present))
+		code.WriteString("\t\t\t\tcontinue\n")
+		code.WriteString("\t\t\t}\n")
+		value := "v"
+		if size == 1 {
+			code.WriteString("\t\t\tbuf[off] = byte(v)\n")
+		} else {
+			if slotType != unsigned {
+				value = unsigned + "(v)"
+			}
+			code.WriteString(fmt.Sprintf("\t\t\t%s.%s(buf[off:off+%d], %s)\n", g.endianPrefix(), g.binaryPutFunc(slotType), size, value))
+		}
+	} else {
+		code.WriteString(fmt.Sprintf("\t\tfor i := range p.%s {\n", field))
+		code.WriteString(fmt.Sprintf("\t\t\tp.%s[i] = 0\n", field))
+		code.WriteString(fmt.Sprintf("\t\t\tif p.%s&(1<<i) == 0 {\n", present))
+		code.WriteString("\t\t\t\tcontinue\n")
+		code.WriteString("\t\t\t}\n")
+		load := "buf[off]"
+		if size > 1 {
+			load = fmt.Sprintf("%s.%s(buf[off:off+%d])", g.endianPrefix(), g.binaryGetFunc(slotType), size)
+		}
+		if slotType != unsigned && (size > 1 || slotType != "byte") {
+			load = fmt.Sprintf("%s(%s)", slotType, load)
+		}
+		code.WriteString(fmt.Sprintf("\t\t\tp.%s[i] = %s\n", field, load))
+	}
+	code.WriteString(fmt.Sprintf("\t\t\toff += %d\n", size))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// bitmapBits returns the number of bits the bitmap field of a present= field holds
+func (g *Generator) bitmapBits(present string) int {
+	for _, r := range g.analyzed.Regions {
+		if r.Field.Name != present {
+			continue
+		}
+		if r.Bits > 0 {
+			return r.Bits
+		}
+		return (r.Boundary - r.Start) * 8
+	}
+	return 0
+}
+
+// generatePresentAccessors generates Has<Field>, Get<Field>At, Set<Field>At and
+// Clear<Field>At for a present= field, keeping the bitmap and the slots in step
+func (g *Generator) generatePresentAccessors(region analyzer.Region) string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	field, present := region.Field.Name, region.Field.Layout.Present
+	n, slotType, _ := g.presentSlots(region)
+
+	code.WriteString(fmt.Sprintf("\n// Has%s reports whether slot i of %s is present, false for i out of range\n", upperFirst(field), field))
+	code.WriteString(fmt.Sprintf("func (p *%s) Has%s(i int) bool {\n", typeName, upperFirst(field)))
+	code.WriteString(fmt.Sprintf("\treturn i >= 0 && i < %d && p.%s&(1<<i) != 0\n", n, present))
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// Get%sAt returns slot i of %s and whether it is present\n", upperFirst(field), field))
+	code.WriteString(fmt.Sprintf("func (p *%s) Get%sAt(i int) (%s, bool) {\n", typeName, upperFirst(field), slotType))
+	code.WriteString(fmt.Sprintf("\treturn p.%s[i], p.Has%s(i)\n", field, upperFirst(field)))
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// Set%sAt stores v in slot i of %s and marks it present\n", upperFirst(field), field))
+	code.WriteString(fmt.Sprintf("func (p *%s) Set%sAt(i int, v %s) {\n", typeName, upperFirst(field), slotType))
+	code.WriteString(fmt.Sprintf("\tp.%s[i] = v\n", field))
+	code.WriteString(fmt.Sprintf("\tp.%s |= 1 << i\n", present))
+	code.WriteString("}\n\n")
+
+	code.WriteString(fmt.Sprintf("// Clear%sAt zeroes slot i of %s and marks it absent\n", upperFirst(field), field))
+	code.WriteString(fmt.Sprintf("func (p *%s) Clear%sAt(i int) {\n", typeName, upperFirst(field)))
+	code.WriteString(fmt.Sprintf("\tp.%s[i] = 0\n", field))
+	code.WriteString(fmt.Sprintf("\tp.%s &^= 1 << i\n", present))
+	code.WriteString("}\n")
+
+	return code.String()
+}
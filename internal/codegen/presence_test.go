@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGeneratePresent(t *testing.T) {
+	// @layout size=64
+	// type Sensor struct {
+	//     Mask   uint16    `layout:"@0"`
+	//     Values [8]uint32 `layout:"@4,present=Mask"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Sensor",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Mask", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Values", GoType: "[8]uint32", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed, Present: "Mask"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal packs the present slots and refuses bits past the last slot
+		"\tif p.Mask>>8 != 0 {\n",
+		"\t\tfor i, v := range p.Values {\n\t\t\tif p.Mask&(1<<i) == 0 {\n\t\t\t\tcontinue\n\t\t\t}\n",
+		"\t\t\tbinary.LittleEndian.PutUint32(buf[off:off+4], v)\n\t\t\toff += 4\n",
+		// Unmarshal refuses them too, and zeroes absent slots
+		"\t\treturn fmt.Errorf(\"Mask: %#x marks slots past the 8 of Values: %w\", p.Mask, ErrOutOfRange)\n",
+		"\t\t\tp.Values[i] = 0\n",
+		"\t\t\tp.Values[i] = binary.LittleEndian.Uint32(buf[off:off+4])\n",
+		// Accessors keep the bitmap in step
+		"func (p *Sensor) HasValues(i int) bool {\n\treturn i >= 0 && i < 8 && p.Mask&(1<<i) != 0\n}\n",
+		"func (p *Sensor) GetValuesAt(i int) (uint32, bool) {\n",
+		"func (p *Sensor) SetValuesAt(i int, v uint32) {\n\tp.Values[i] = v\n\tp.Mask |= 1 << i\n}\n",
+		"func (p *Sensor) ClearValuesAt(i int) {\n\tp.Values[i] = 0\n\tp.Mask &^= 1 << i\n}\n",
+		// ConvertEndian swaps slot by slot
+		"\tdst[4], dst[5], dst[6], dst[7] = dst[7], dst[6], dst[5], dst[4] // Values[0]\n",
+		"\tdst[32], dst[33], dst[34], dst[35] = dst[35], dst[34], dst[33], dst[32] // Values[7]\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "func PatchSensorValues") {
+		t.Errorf("present= field should not get a Patch function\n\nGenerated:\n%s", code)
+	}
+}
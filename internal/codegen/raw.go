@@ -0,0 +1,116 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// rawGenerator returns the generator of the raw= type, which gets the layout's
+// zerocopy code under its own name. It embeds the annotated type, so the code
+// reaches the fields as before.
+func (g *Generator) rawGenerator() *Generator {
+	raw := *g.analyzed
+	raw.TypeName = g.layout.Anno.Raw
+
+	anno := *g.layout.Anno
+	anno.Raw = ""
+	layout := *g.layout
+	layout.Anno = &anno
+
+	rg := *g
+	rg.analyzed = &raw
+	rg.layout = &layout
+	return &rg
+}
+
+// generateRaw generates the type named by raw=, holding the annotated type and
+// the buffer it would otherwise have to declare (buf, and backing with align=),
+// with the zerocopy code on it and From/To converting from and to the plain type
+func (g *Generator) generateRaw() (string, error) {
+	name, typeName := g.layout.Anno.Raw, g.analyzed.TypeName
+	if g.mode != "zerocopy" {
+		return "", fmt.Errorf("raw=%s requires mode=zerocopy, other modes keep no buffer", name)
+	}
+	if name == typeName {
+		return "", fmt.Errorf("raw=%s: the raw type needs a name of its own", name)
+	}
+
+	code, err := g.rawGenerator().Generate()
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("// %s is a %s together with its encoding, in a buffer %s doesn't\n", name, typeName, typeName))
+	out.WriteString(fmt.Sprintf("// declare. Accessors work on the buffer; From and To convert from and to %s.\n", typeName))
+	out.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	out.WriteString(fmt.Sprintf("\t%s\n", typeName))
+	switch {
+	case g.align > 0 && g.allocator == "":
+		out.WriteString("\tbacking []byte\n")
+		out.WriteString("\tbuf     []byte\n")
+	case g.align > 0 || g.allocator != "":
+		out.WriteString("\tbuf []byte\n")
+	default:
+		out.WriteString(fmt.Sprintf("\tbuf [%d]byte\n", g.analyzed.BufferSize))
+	}
+	out.WriteString("}\n\n")
+
+	// Slice-backed buffers exist once New or SetBuffer provides them
+	noBuffer := func(method string) string {
+		if g.align == 0 && g.allocator == "" {
+			return ""
+		}
+		return fmt.Sprintf("\tif r.buf == nil {\n\t\treturn fmt.Errorf(\"%s: no buffer, create %s with New%s: %%w\", ErrBadBuffer)\n\t}\n", method, name, name)
+	}
+
+	out.WriteString("// From encodes p into r's buffer\n")
+	out.WriteString(fmt.Sprintf("func (r *%s) From(p *%s) error {\n", name, typeName))
+	out.WriteString(noBuffer("From"))
+	out.WriteString(fmt.Sprintf("\tr.%s = *p\n", typeName))
+	out.WriteString(g.generateRawBytes())
+	out.WriteString("\t_, err := r.MarshalLayout()\n")
+	out.WriteString("\treturn err\n")
+	out.WriteString("}\n\n")
+
+	out.WriteString("// To decodes r's buffer into p. p's []byte fields alias the buffer.\n")
+	out.WriteString(fmt.Sprintf("func (r *%s) To(p *%s) error {\n", name, typeName))
+	out.WriteString(noBuffer("To"))
+	out.WriteString("\tif err := r.UnmarshalLayout(r.buf[:]); err != nil {\n")
+	out.WriteString("\t\treturn err\n")
+	out.WriteString("\t}\n")
+	out.WriteString(fmt.Sprintf("\t*p = r.%s\n", typeName))
+	out.WriteString("\treturn nil\n")
+	out.WriteString("}\n\n")
+
+	out.WriteString(code)
+	return out.String(), nil
+}
+
+// generateRawBytes generates the start of From copying p's []byte fields into
+// r's buffer. Zerocopy marshaling expects them sliced from the buffer already,
+// as accessors and UnmarshalLayout leave them.
+func (g *Generator) generateRawBytes() string {
+	var code strings.Builder
+	for _, region := range g.analyzed.Regions {
+		field := region.Field
+		if region.Kind != analyzer.DynamicRegion || field.GoType != "[]byte" {
+			continue
+		}
+		capacity := region.Boundary - region.Start
+		bytes := fmt.Sprintf("r.buf[%d : %d+len(p.%s)]", region.Start, region.Start, field.Name)
+		if region.Direction == parser.EndStart {
+			capacity = region.Start - region.Boundary
+			bytes = fmt.Sprintf("r.buf[%d-len(p.%s) : %d]", region.Start, field.Name, region.Start)
+		}
+		code.WriteString(fmt.Sprintf("\tif len(p.%s) > %d {\n", field.Name, capacity))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"From: %s: %%d bytes exceeds capacity %d: %%w\", len(p.%s), ErrRegionOverflow)\n", field.Name, capacity, field.Name))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\tr.%s = %s\n", field.Name, bytes))
+		code.WriteString(fmt.Sprintf("\tcopy(r.%s, p.%s)\n", field.Name, field.Name))
+	}
+	return code.String()
+}
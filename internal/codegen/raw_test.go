@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateRaw(t *testing.T) {
+	// @layout size=16 mode=zerocopy raw=PageRaw [align=8]
+	// type Page struct {
+	//     ID    uint64 `layout:"@0"`
+	//     Count uint16 `layout:"@8"`
+	//     Data  []byte `layout:"start-end,count=Count"`
+	// }
+	tests := []struct {
+		name          string
+		align         int
+		expectedParts []string
+	}{
+		{"array-backed", 0, []string{
+			"type PageRaw struct {\n\tPage\n\tbuf [16]byte\n}\n",
+			"func (r *PageRaw) From(p *Page) error {\n\tr.Page = *p\n",
+			"\tif len(p.Data) > 6 {\n\t\treturn fmt.Errorf(\"From: Data: %d bytes exceeds capacity 6: %w\", len(p.Data), ErrRegionOverflow)\n\t}\n",
+			"\tr.Data = r.buf[10 : 10+len(p.Data)]\n\tcopy(r.Data, p.Data)\n\t_, err := r.MarshalLayout()\n\treturn err\n}\n",
+			"func (r *PageRaw) To(p *Page) error {\n\tif err := r.UnmarshalLayout(r.buf[:]); err != nil {\n\t\treturn err\n\t}\n\t*p = r.Page\n\treturn nil\n}\n",
+			"func (p *PageRaw) MarshalLayout() ([]byte, error) {\n",
+			"func (p *PageRaw) GetID() uint64 {\n",
+			"func (p *PageRaw) Clone() *PageRaw {\n",
+		}},
+		{"aligned", 8, []string{
+			"type PageRaw struct {\n\tPage\n\tbacking []byte\n\tbuf     []byte\n}\n",
+			"\tif r.buf == nil {\n\t\treturn fmt.Errorf(\"From: no buffer, create PageRaw with NewPageRaw: %w\", ErrBadBuffer)\n\t}\n",
+			"func (p *PageRaw) SetBuffer(buf []byte) error {\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Page",
+				Anno: &parser.TypeAnnotation{Size: 16, Mode: "zerocopy", Align: tt.align, Raw: "PageRaw"},
+				Fields: []parser.Field{
+					{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Count", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+					{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "Count"}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", tt.align, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			if strings.Contains(code, "func (p *Page) ") {
+				t.Errorf("Generated code has methods on Page\n\nGenerated:\n%s", code)
+			}
+			if golden := gen.GenerateGoldenTest(); !strings.Contains(golden, "func TestLayoutGoldenPageRaw(t *testing.T) {\n") {
+				t.Errorf("GenerateGoldenTest() doesn't test PageRaw:\n%s", golden)
+			}
+		})
+	}
+}
+
+func TestGenerateRawErrors(t *testing.T) {
+	tests := []struct {
+		name, mode, raw, wantErr string
+	}{
+		{"copy mode", "copy", "PageRaw", "raw=PageRaw requires mode=zerocopy"},
+		{"own name", "zerocopy", "Page", "the raw type needs a name of its own"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Page",
+				Anno: &parser.TypeAnnotation{Size: 8, Mode: tt.mode, Raw: tt.raw},
+				Fields: []parser.Field{
+					{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			_, err = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "").Generate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// getterPrefixes start the names of the zerocopy methods that only read the
+// buffer, followed by the name of the field they read
+var getterPrefixes = []string{"Get", "Has", "Iterate", "All"}
+
+// getter is a method of the zerocopy type that a readonly= view forwards
+type getter struct {
+	name    string
+	params  string // As declared, e.g. "idx int"
+	args    string // The parameter names, e.g. "idx"
+	results string // As declared, "" for none
+}
+
+// getters returns the read-only methods declared on the type in code, the
+// type's generated code: those named after one of its fields with a prefix of
+// getterPrefixes, in the order they are declared
+func (g *Generator) getters(code string) []getter {
+	method := regexp.MustCompile(fmt.Sprintf(`(?m)^func \(p \*%s\) (\w+)\((.*) \{$`, regexp.QuoteMeta(g.analyzed.TypeName)))
+
+	var getters []getter
+	for _, m := range method.FindAllStringSubmatch(code, -1) {
+		if !g.isGetter(m[1]) {
+			continue
+		}
+		params, end := splitArgs(m[2])
+		var names []string
+		for _, param := range params {
+			if fields := strings.Fields(param); len(fields) > 0 {
+				names = append(names, fields[0])
+			}
+		}
+		getters = append(getters, getter{
+			name:    m[1],
+			params:  m[2][:end-1],
+			args:    strings.Join(names, ", "),
+			results: strings.TrimSpace(m[2][end:]),
+		})
+	}
+	return getters
+}
+
+// isGetter reports whether name is a getter prefix followed by a field name,
+// such as GetBodyCount, and not a method that happens to share a prefix, such
+// as AllocSlot
+func (g *Generator) isGetter(name string) bool {
+	for _, prefix := range getterPrefixes {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		for _, field := range g.layout.Fields {
+			if strings.HasPrefix(rest, upperFirst(field.Name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkReadOnly refuses readonly= views of layouts without a buffer to view
+func (g *Generator) checkReadOnly() error {
+	name := g.layout.Anno.ReadOnly
+	if name == "" {
+		return nil
+	}
+	if g.mode != "zerocopy" {
+		return fmt.Errorf("readonly=%s requires mode=zerocopy, other modes keep no buffer to view", name)
+	}
+	if name == g.analyzed.TypeName {
+		return fmt.Errorf("readonly=%s: the view type needs a name of its own", name)
+	}
+	return nil
+}
+
+// generateReadOnly generates the type named by readonly=, wrapping the zerocopy
+// type to expose its getters only: no setters, no MarshalLayout, no way to the
+// buffer. code is the type's generated code, whose getters are forwarded.
+// Code handed a shared cached page as the view can't modify it, checked at
+// compile time. Slice-backed types also get a constructor borrowing a buffer.
+func (g *Generator) generateReadOnly(code string) string {
+	name, typeName := g.layout.Anno.ReadOnly, g.analyzed.TypeName
+	if name == "" {
+		return ""
+	}
+
+	var out strings.Builder
+	out.WriteString("\n")
+	out.WriteString(fmt.Sprintf("// %s is a read-only view of a %s: it has the getters and none of the\n", name, typeName))
+	out.WriteString(fmt.Sprintf("// setters, so code holding a %s can't modify the page. The view borrows\n", name))
+	out.WriteString("// the buffer, seeing writes made through the page. Byte slices it returns alias\n")
+	out.WriteString("// the buffer; don't write to them either.\n")
+	out.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	out.WriteString(fmt.Sprintf("\tp *%s\n", typeName))
+	out.WriteString("}\n\n")
+
+	out.WriteString("// View returns a read-only view of p, sharing its buffer\n")
+	out.WriteString(fmt.Sprintf("func (p *%s) View() %s {\n", typeName, name))
+	out.WriteString(fmt.Sprintf("\treturn %s{p: p}\n", name))
+	out.WriteString("}\n\n")
+
+	// Only slice-backed types can alias the caller's buffer
+	if g.align > 0 || g.allocator != "" {
+		out.WriteString(fmt.Sprintf("// New%s returns a read-only view of buf, an encoded %s, without copying it.\n", name, typeName))
+		out.WriteString("// The caller keeps ownership of buf; the view must not outlive it.\n")
+		out.WriteString(fmt.Sprintf("func New%s(buf []byte) (%s, error) {\n", name, name))
+		out.WriteString(fmt.Sprintf("\tvar p %s\n", typeName))
+		out.WriteString("\tif err := p.SetBuffer(buf); err != nil {\n")
+		out.WriteString(fmt.Sprintf("\t\treturn %s{}, err\n", name))
+		out.WriteString("\t}\n")
+		out.WriteString(fmt.Sprintf("\treturn %s{p: &p}, nil\n", name))
+		out.WriteString("}\n\n")
+	}
+
+	for _, m := range g.getters(code) {
+		out.WriteString(fmt.Sprintf("// %s calls %s.%s on the viewed page\n", m.name, typeName, m.name))
+		signature := fmt.Sprintf("func (v %s) %s(%s)", name, m.name, m.params)
+		if m.results != "" {
+			signature += " " + m.results
+		}
+		out.WriteString(signature + " {\n")
+		call := fmt.Sprintf("v.p.%s(%s)", m.name, m.args)
+		if m.results != "" {
+			call = "return " + call
+		}
+		out.WriteString(fmt.Sprintf("\t%s\n", call))
+		out.WriteString("}\n\n")
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,119 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateReadOnly(t *testing.T) {
+	// @layout size=128 mode=zerocopy readonly=PageView [align=8]
+	// type Page struct {
+	//     N     uint16 `layout:"@0"`
+	//     ID    uint64 `layout:"@8"`
+	//     Elems []Elem `layout:"@16,start-end,count=N"` // Elem is 8 bytes
+	// }
+	tests := []struct {
+		name          string
+		align         int
+		expectedParts []string
+		missingParts  []string
+	}{
+		{"array-backed", 0, []string{
+			"type PageView struct {\n\tp *Page\n}\n",
+			"func (p *Page) View() PageView {\n\treturn PageView{p: p}\n}\n",
+			"func (v PageView) GetID() uint64 {\n\treturn v.p.GetID()\n}\n",
+			"func (v PageView) GetElemsAt(idx int) Elem {\n\treturn v.p.GetElemsAt(idx)\n}\n",
+			"func (v PageView) IterateElems(fn func(i int, e *Elem) bool) {\n\tv.p.IterateElems(fn)\n}\n",
+			"func (v PageView) AllElems() iter.Seq2[int, Elem] {\n\treturn v.p.AllElems()\n}\n",
+		}, []string{
+			// An array can't alias the caller's buffer
+			"func NewPageView(",
+		}},
+		{"aligned", 8, []string{
+			"func NewPageView(buf []byte) (PageView, error) {\n\tvar p Page\n\tif err := p.SetBuffer(buf); err != nil {\n" +
+				"\t\treturn PageView{}, err\n\t}\n\treturn PageView{p: &p}, nil\n}\n",
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Page",
+				Anno: &parser.TypeAnnotation{Size: 128, Mode: "zerocopy", Align: tt.align, ReadOnly: "PageView"},
+				Fields: []parser.Field{
+					{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+					{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+						Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "N"}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			reg.Register("Elem", 8)
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", tt.align, "")
+			code, err := gen.Generate()
+			if err != nil {
+				t.Fatalf("Generate() error: %v", err)
+			}
+			for _, expected := range tt.expectedParts {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+				}
+			}
+			for _, missing := range tt.missingParts {
+				if strings.Contains(code, missing) {
+					t.Errorf("Generated code has %q:\n%s", missing, code)
+				}
+			}
+
+			// The view writes nothing: no setters, no MarshalLayout, no buffer
+			for _, method := range []string{"Set", "Marshal", "Unmarshal", "Append", "Clone", "Load", "Save", "Write", "Read"} {
+				if strings.Contains(code, "func (v PageView) "+method) {
+					t.Errorf("PageView has a %s method:\n%s", method, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateReadOnly_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		view    string
+		wantErr string
+	}{
+		{"copy mode", "copy", "PageView", "readonly=PageView requires mode=zerocopy"},
+		{"own name", "zerocopy", "Page", "readonly=Page: the view type needs a name of its own"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// @layout size=16 mode=<mode> readonly=<view>
+			// type Page struct {
+			//     ID uint64 `layout:"@0"`
+			// }
+			layout := &parser.TypeLayout{
+				Name: "Page",
+				Anno: &parser.TypeAnnotation{Size: 16, Mode: tt.mode, ReadOnly: tt.view},
+				Fields: []parser.Field{
+					{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "")
+			if _, err := gen.Generate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
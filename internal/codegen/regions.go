@@ -0,0 +1,75 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// generateChainUnmarshal generates unmarshal code for a field of the region= chain.
+// regionOffset tracks where the field starts; counts come from the buffer, so each
+// field is checked to end within the chain before it is read.
+func (g *Generator) generateChainUnmarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	countField := field.Layout.CountField
+	boundary := region.Boundary
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s in region %s, within [%d, %d)",
+		field.Name, field.GoType, field.Layout.Group, region.Start, boundary))
+	if countField != "" {
+		code.WriteString(fmt.Sprintf(" with count=%s", countField))
+	}
+	code.WriteString("\n")
+	if region.Chain == 0 {
+		code.WriteString(fmt.Sprintf("\tregionOffset := %d\n", region.Start))
+	}
+
+	// Element count: from the count field, or whatever is left of the chain
+	count := fmt.Sprintf("int(p.%s)", countField)
+	if countField == "" {
+		count = fmt.Sprintf("%d - regionOffset", boundary)
+		if region.ElementSize > 1 {
+			count = fmt.Sprintf("(%s) / %d", count, region.ElementSize)
+		}
+	} else {
+		code.WriteString(g.generateCountCheck(region))
+		size := count
+		if region.ElementSize > 1 {
+			size = fmt.Sprintf("%s*%d", count, region.ElementSize)
+		}
+		code.WriteString(fmt.Sprintf("\tif regionOffset+%s > %d {\n", size, boundary))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: %%d elements at offset %%d overrun the regions ending at %d: %%w\", p.%s, regionOffset, ErrRegionOverflow)\n",
+			field.Name, boundary, countField))
+		code.WriteString("\t}\n")
+	}
+
+	lenVar := g.localName(field.Name, "Len")
+	code.WriteString(fmt.Sprintf("\t%s := %s\n", lenVar, count))
+	code.WriteString("\t// Reuse slice if capacity allows\n")
+	code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= %s {\n", field.Name, lenVar))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:%s]\n", field.Name, field.Name, lenVar))
+	code.WriteString("\t} else {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = make(%s, %s)\n", field.Name, field.GoType, lenVar))
+	code.WriteString("\t}\n")
+
+	if region.ElementType == "byte" {
+		code.WriteString(fmt.Sprintf("\tcopy(p.%s, buf[regionOffset:regionOffset+%s])\n", field.Name, lenVar))
+		if countField != "" {
+			code.WriteString(fmt.Sprintf("\tregionOffset += %s\n", lenVar))
+		}
+		code.WriteString("\n")
+		return code.String()
+	}
+
+	code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+	code.WriteString(fmt.Sprintf("\t\tif err := p.%s[i].UnmarshalLayout(buf[regionOffset:regionOffset+%d]); err != nil {\n",
+		field.Name, region.ElementSize))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", i, err)\n", field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tregionOffset += %d\n", region.ElementSize))
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
@@ -0,0 +1,61 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateRegionChain(t *testing.T) {
+	// @layout size=128 regions=body,blob
+	// type Page struct {
+	//     BodyLen uint16 `layout:"@0"`
+	//     Blob    []byte `layout:"start-end,region=blob"`
+	//     Body    []byte `layout:"start-end,count=BodyLen,region=body"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Mode: "copy", Regions: []string{"body", "blob"}},
+		Fields: []parser.Field{
+			{Name: "BodyLen", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Blob", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, Group: "blob"}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.StartEnd, StartAt: -1, CountField: "BodyLen", Group: "body"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Marshal continues Blob from where Body ended
+	if n := strings.Count(code, "\toffset = 2\n"); n != 1 {
+		t.Errorf("offset reset %d times, want once for the chain head\n\nGenerated:\n%s", n, code)
+	}
+
+	expectedParts := []string{
+		"regionOffset := 2",
+		"if regionOffset+int(p.BodyLen) > 128 {",
+		"copy(p.Body, buf[regionOffset:regionOffset+bodyLen])",
+		"regionOffset += bodyLen",
+		"blobLen := 128 - regionOffset",
+		"copy(p.Blob, buf[regionOffset:regionOffset+blobLen])",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Index(code, "bodyLen :=") > strings.Index(code, "blobLen :=") {
+		t.Errorf("Body should be unmarshaled before Blob\n\nGenerated:\n%s", code)
+	}
+}
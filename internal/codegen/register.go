@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// readable reports whether the zerocopy type has a getter for the field in
+// region: every field but access=wo registers
+func readable(region analyzer.Region) bool {
+	return region.Field.Layout == nil || region.Field.Layout.Access != parser.AccessWriteOnly
+}
+
+// writable reports whether the zerocopy type has a setter for the field in
+// region: every field but access=ro registers
+func writable(region analyzer.Region) bool {
+	return region.Field.Layout == nil || region.Field.Layout.Access != parser.AccessReadOnly
+}
+
+// registerLoad returns the expression loading the register in region from
+// p.buf with a single load, through a pointer of the field's type for multi-byte
+// registers, whose alignment the analyzer checked
+func (g *Generator) registerLoad(region analyzer.Region) string {
+	field := region.Field
+	if size, _ := analyzer.SizeOf(g.registry.ResolveType(field.GoType)); size == 1 {
+		return fmt.Sprintf("%s(p.buf[%d])", field.GoType, region.Start)
+	}
+	return fmt.Sprintf("*(*%s)(unsafe.Pointer(&p.buf[%d]))", field.GoType, region.Start)
+}
+
+// registerStore returns the statement storing v, of the field's type, into the
+// register in region with a single store
+func (g *Generator) registerStore(region analyzer.Region, v string) string {
+	field := region.Field
+	if size, _ := analyzer.SizeOf(g.registry.ResolveType(field.GoType)); size == 1 {
+		return fmt.Sprintf("p.buf[%d] = byte(%s)\n", region.Start, v)
+	}
+	return fmt.Sprintf("*(*%s)(unsafe.Pointer(&p.buf[%d])) = %s\n", field.GoType, region.Start, v)
+}
+
+// generateRegisterAccessors generates the accessors of an access= field, a
+// register of memory-mapped hardware: a getter unless it is write-only, a
+// setter unless it is read-only. They are never inlined, so the compiler can't
+// merge, hoist or drop the load or store of one call with another's; a loop
+// polling a status register reads it on every iteration.
+func (g *Generator) generateRegisterAccessors(region analyzer.Region) string {
+	var code strings.Builder
+	field, typeName := region.Field, g.analyzed.TypeName
+	access := map[string]string{
+		parser.AccessReadOnly:  "read-only",
+		parser.AccessWriteOnly: "write-only",
+		parser.AccessReadWrite: "read-write",
+	}[field.Layout.Access]
+
+	if readable(region) {
+		code.WriteString(fmt.Sprintf("// Get%s reads the %s register %s, %s at offset %d, with a single\n",
+			upperFirst(field.Name), access, field.Name, field.GoType, region.Start))
+		code.WriteString("// load on every call\n")
+		code.WriteString("//\n")
+		code.WriteString("//go:noinline\n")
+		code.WriteString(fmt.Sprintf("func (p *%s) Get%s() %s {\n", typeName, upperFirst(field.Name), field.GoType))
+		code.WriteString(fmt.Sprintf("\treturn %s\n", g.registerLoad(region)))
+		code.WriteString("}\n\n")
+	}
+
+	if writable(region) {
+		code.WriteString(fmt.Sprintf("// Set%s writes the %s register %s, %s at offset %d, with a single\n",
+			upperFirst(field.Name), access, field.Name, field.GoType, region.Start))
+		code.WriteString("// store on every call\n")
+		code.WriteString("//\n")
+		code.WriteString("//go:noinline\n")
+		code.WriteString(fmt.Sprintf("func (p *%s) Set%s(v %s) {\n", typeName, upperFirst(field.Name), field.GoType))
+		code.WriteString("\t" + g.registerStore(region, "v"))
+		code.WriteString("}\n\n")
+	}
+
+	return code.String()
+}
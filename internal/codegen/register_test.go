@@ -0,0 +1,71 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateRegisterAccessors(t *testing.T) {
+	// @layout size=16 mode=zerocopy align=8
+	// type UART struct {
+	//     Data    uint8  `layout:"@0,access=rw"`
+	//     Level   int8   `layout:"@1,access=wo"`
+	//     ID      uint32 `layout:"@4,magic=0x55415254,access=ro"`
+	//     Command uint32 `layout:"@8,access=wo"`
+	//     Baud    uint32 `layout:"@12"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "UART",
+		Anno: &parser.TypeAnnotation{Size: 16, Mode: "zerocopy", Align: 8},
+		Fields: []parser.Field{
+			{Name: "Data", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Access: parser.AccessReadWrite}},
+			{Name: "Level", GoType: "int8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed, Access: parser.AccessWriteOnly}},
+			{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{
+				Offset: 4, Direction: parser.Fixed, Default: "0x55415254", Magic: true, Access: parser.AccessReadOnly}},
+			{Name: "Command", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, Access: parser.AccessWriteOnly}},
+			{Name: "Baud", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 12, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 8, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"// GetData reads the read-write register Data, uint8 at offset 0, with a single\n// load on every call\n//\n//go:noinline\n" +
+			"func (p *UART) GetData() uint8 {\n\treturn uint8(p.buf[0])\n}\n",
+		"//go:noinline\nfunc (p *UART) SetData(v uint8) {\n\tp.buf[0] = byte(v)\n}\n",
+		"//go:noinline\nfunc (p *UART) SetLevel(v int8) {\n\tp.buf[1] = byte(v)\n}\n",
+		"//go:noinline\nfunc (p *UART) GetID() uint32 {\n\treturn *(*uint32)(unsafe.Pointer(&p.buf[4]))\n}\n",
+		"//go:noinline\nfunc (p *UART) SetCommand(v uint32) {\n\t*(*uint32)(unsafe.Pointer(&p.buf[8])) = v\n}\n",
+		// New stores the read-only magic without a setter
+		"\tp.ID = 0x55415254\n\t*(*uint32)(unsafe.Pointer(&p.buf[4])) = p.ID\n",
+		// JSON skips the accessors the registers don't have
+		"\t\tData: p.GetData(),\n\t\tID: p.GetID(),\n\t\tBaud: p.GetBaud(),\n",
+		"\tp.SetData(v.Data)\n\tp.SetLevel(v.Level)\n\tp.SetCommand(v.Command)\n\tp.SetBaud(v.Baud)\n",
+		// Plain fields keep both accessors, inlined
+		"func (p *UART) GetBaud() uint32 {",
+		"func (p *UART) SetBaud(v uint32) {",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+	for _, unexpected := range []string{"func (p *UART) GetLevel(", "func (p *UART) GetCommand(", "func (p *UART) SetID("} {
+		if strings.Contains(code, unexpected) {
+			t.Errorf("Generated code contains %q", unexpected)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// isReserved reports whether a region is a reserved range (reserve=) rather than
+// a real field. Reserved ranges have no accessors and no struct storage.
+func isReserved(region analyzer.Region) bool {
+	return region.Field.Layout != nil && region.Field.Layout.Reserve > 0
+}
+
+// generateReservedOp generates marshal/unmarshal code for a reserved range.
+// Marshal zero-fills it; copy mode allocates a zeroed buffer, so only zerocopy
+// needs to clear. Unmarshal checks the range is still zero when verify is set,
+// refusing data written by a newer format version that uses the space.
+func (g *Generator) generateReservedOp(region analyzer.Region, op string) string {
+	var code strings.Builder
+	bufExpr := "buf"
+	if g.mode == "zerocopy" {
+		bufExpr = "p.buf"
+	}
+
+	if op == "marshal" {
+		code.WriteString(fmt.Sprintf("\t// %s: reserved [%d, %d), zero-filled\n", region.Field.Name, region.Start, region.Boundary))
+		if g.mode == "zerocopy" {
+			code.WriteString(fmt.Sprintf("\tclear(p.buf[%d:%d])\n", region.Start, region.Boundary))
+		}
+		code.WriteString("\n")
+		return code.String()
+	}
+
+	if !region.Field.Layout.VerifyZero {
+		return ""
+	}
+	code.WriteString(fmt.Sprintf("\t// %s: reserved [%d, %d), must be zero\n", region.Field.Name, region.Start, region.Boundary))
+	code.WriteString(fmt.Sprintf("\tfor _, b := range %s[%d:%d] {\n", bufExpr, region.Start, region.Boundary))
+	code.WriteString("\t\tif b != 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"reserved bytes [%d, %d) are not zero: %%w\", ErrReservedNotZero)\n", region.Start, region.Boundary))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
@@ -0,0 +1,68 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateReserved(t *testing.T) {
+	// @layout size=32
+	// type Header struct {
+	//     Kind uint16   `layout:"@0"`
+	//     _    struct{} `layout:"@2,reserve=6,verify"`
+	//     ID   uint64   `layout:"@8"`
+	//     _    struct{} `layout:"@16,reserve=16"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 32},
+		Fields: []parser.Field{
+			{Name: "Kind", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "_", GoType: "unknown", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed, Reserve: 6, VerifyZero: true}},
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			{Name: "_", GoType: "unknown", Layout: &parser.FieldLayout{Offset: 16, Direction: parser.Fixed, Reserve: 16}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	expectedParts := []string{
+		"// _: reserved [2, 8), zero-filled",
+		"for _, b := range buf[2:8] {",
+		`return fmt.Errorf("reserved bytes [2, 8) are not zero: %w", ErrReservedNotZero)`,
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	// Without verify, unmarshal ignores the range; nothing references the pseudo-field
+	if strings.Contains(code, "buf[16:32] {") || strings.Contains(code, "p._") {
+		t.Errorf("Unverified reserved range should not be read\n\nGenerated:\n%s", code)
+	}
+
+	// Zerocopy clears the range in p.buf and generates no accessors for it
+	gen = NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err = gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if !strings.Contains(code, "clear(p.buf[16:32])") || !strings.Contains(code, "for _, b := range p.buf[2:8] {") {
+		t.Errorf("Zerocopy reserved handling missing\n\nGenerated:\n%s", code)
+	}
+	if strings.Contains(code, "Get_") || strings.Contains(code, "p._") {
+		t.Errorf("Reserved ranges should have no accessors\n\nGenerated:\n%s", code)
+	}
+}
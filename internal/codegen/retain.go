@@ -0,0 +1,73 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// retain reports whether UnmarshalLayout keeps its buffer (retain=true)
+func (g *Generator) retain() bool {
+	return g.layout != nil && g.layout.Anno != nil && g.layout.Anno.Retain
+}
+
+// checkRetain refuses retain=true outside copy mode: zerocopy types hold their
+// buffer already, and stream frames are read into buffers of their own
+func (g *Generator) checkRetain() error {
+	if g.retain() && g.mode != "copy" {
+		return fmt.Errorf("retain=true requires mode=copy, mode=%s keeps no decoded buffer", g.mode)
+	}
+	return nil
+}
+
+// generateRetainedBytes generates <Field>Bytes for each dynamic region of a
+// retain=true layout: the bytes of the region in the buffer UnmarshalLayout
+// kept, without copying them. Used bytes are read from the count in the
+// buffer, so the result doesn't change with the struct fields; regions whose
+// count doesn't tell return their whole span.
+func (g *Generator) generateRetainedBytes() string {
+	if !g.retain() {
+		return ""
+	}
+
+	var code strings.Builder
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.DynamicRegion {
+			continue
+		}
+		name := region.Field.Name
+		low, high := g.regionSpan(region)
+
+		code.WriteString("\n")
+		code.WriteString(fmt.Sprintf("// %sBytes returns the encoded bytes of %s in the buffer UnmarshalLayout\n", upperFirst(name), name))
+		code.WriteString("// retained, a subslice sharing its memory, or nil before UnmarshalLayout\n")
+		code.WriteString(fmt.Sprintf("func (p *%s) %sBytes() []byte {\n", g.analyzed.TypeName, upperFirst(name)))
+		code.WriteString("\tif p.buf == nil {\n")
+		code.WriteString("\t\treturn nil\n")
+		code.WriteString("\t}\n")
+		decl, used, ok := g.usedBytes(region, "p.buf", make(map[string]bool))
+		switch {
+		case !ok:
+			code.WriteString(fmt.Sprintf("\treturn p.buf[%d:%d:%d]\n", low, high, high))
+		case region.Direction == parser.EndStart:
+			code.WriteString(decl)
+			code.WriteString(fmt.Sprintf("\treturn p.buf[%d-%s : %d : %d]\n", high, used, high, high))
+		default:
+			code.WriteString(decl)
+			code.WriteString(fmt.Sprintf("\tend := %d + %s\n", low, used))
+			code.WriteString(fmt.Sprintf("\treturn p.buf[%d:end:end]\n", low))
+		}
+		code.WriteString("}\n")
+	}
+	return code.String()
+}
+
+// retainedByteUnmarshal returns the statement aliasing a []byte field to the
+// bytes [low, high) of buf, which UnmarshalLayout retains instead of copying
+// them out. The capacity ends at high, so appending to the field reallocates
+// rather than writing over the bytes after it.
+func retainedByteUnmarshal(field parser.Field, low, high string) string {
+	return fmt.Sprintf("\tp.%s = buf[%s:%s:%s] // retain=true: aliased, not copied\n\n", field.Name, low, high, high)
+}
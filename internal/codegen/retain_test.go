@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateRetain(t *testing.T) {
+	// @layout size=128 retain=true
+	// type Page struct {
+	//     buf   []byte
+	//     N     uint8  `layout:"@0"`
+	//     M     uint8  `layout:"@1"`
+	//     Elems []Elem `layout:"@8,start-end,count=N"` // Elem is 8 bytes
+	//     Body  []byte `layout:"end-start,count=M"`
+	//     Tail  []byte `layout:"@64,start-end"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Retain: true},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 64, Direction: parser.EndStart, CountField: "M"}},
+			{Name: "Tail", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 64, Direction: parser.StartEnd}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// UnmarshalLayout keeps buf and aliases the []byte fields to it, capped so
+	// appends don't write over what follows
+	expectedParts := []string{
+		"\tp.buf = buf // retain=true: kept for the <Field>Bytes accessors\n",
+		"\tp.Body = buf[64-int(p.M):64:64] // retain=true: aliased, not copied\n",
+		"\tp.Tail = buf[64:128:128] // retain=true: aliased, not copied\n",
+		// The accessors read the counts from the buffer, not the fields
+		"func (p *Page) ElemsBytes() []byte {\n\tif p.buf == nil {\n\t\treturn nil\n\t}\n" +
+			"\tcountN := int(p.buf[0])\n\tend := 8 + max(min(countN*8, 56), 0)\n\treturn p.buf[8:end:end]\n}\n",
+		"\tcountM := int(p.buf[1])\n\treturn p.buf[64-max(min(countM, 56), 0) : 64 : 64]\n",
+		"func (p *Page) TailBytes() []byte {\n\tif p.buf == nil {\n\t\treturn nil\n\t}\n\treturn p.buf[64:128:128]\n}\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "copy(p.Body") || strings.Contains(code, "copy(p.Tail") {
+		t.Errorf("retain=true copies a []byte field:\n%s", code)
+	}
+
+	// Zerocopy types hold their buffer already
+	zerocopy := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	if _, err := zerocopy.Generate(); err == nil || !strings.Contains(err.Error(), "retain=true requires mode=copy") {
+		t.Errorf("Generate() in zerocopy mode error = %v, want retain=true requires mode=copy", err)
+	}
+}
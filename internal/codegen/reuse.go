@@ -0,0 +1,113 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateReset generates Reset for reuse=true layouts, readying a pooled value
+// for the next UnmarshalLayout: the fields are zeroed but the slices keep their
+// backing arrays, truncated to length zero, and nested layouts are reset by
+// their own Reset, elements past the length of a slice included, so their
+// slices keep theirs too. Zeroing the struct instead would drop them all.
+func (g *Generator) generateReset() string {
+	if !g.layout.Anno.Reuse {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString("// Reset zeroes p for reuse, keeping the backing arrays of its slices and of the\n")
+	code.WriteString("// layouts nested in it, so UnmarshalLayout into p allocates only for a count\n")
+	code.WriteString("// beyond any it decoded before.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) Reset() {\n", typeName))
+
+	var kept []string
+	for _, field := range g.layout.Fields {
+		if field.Name == "_" {
+			continue
+		}
+		_, nested := g.registry.NestedLayout(field.GoType)
+		isSlice := strings.HasPrefix(field.GoType, "[]")
+		switch {
+		case nested && (isSlice || strings.HasPrefix(field.GoType, "[")):
+			if isSlice {
+				code.WriteString(fmt.Sprintf("\tp.%s = p.%s[:cap(p.%s)]\n", field.Name, field.Name, field.Name))
+			}
+			code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", field.Name))
+			code.WriteString(fmt.Sprintf("\t\tp.%s[i].Reset()\n", field.Name))
+			code.WriteString("\t}\n")
+		case nested:
+			code.WriteString(fmt.Sprintf("\tp.%s.Reset()\n", field.Name))
+		}
+		switch {
+		case isSlice:
+			kept = append(kept, fmt.Sprintf("\t\t%s: p.%s[:0],\n", field.Name, field.Name))
+		case nested:
+			kept = append(kept, fmt.Sprintf("\t\t%s: p.%s,\n", field.Name, field.Name))
+		}
+	}
+
+	if len(kept) == 0 {
+		code.WriteString(fmt.Sprintf("\t*p = %s{}\n", typeName))
+	} else {
+		code.WriteString(fmt.Sprintf("\t*p = %s{\n", typeName))
+		for _, k := range kept {
+			code.WriteString(k)
+		}
+		code.WriteString("\t}\n")
+	}
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// GenerateReuseTestHeader generates the package clause and imports of the tests
+// checking that reuse=true layouts unmarshal without allocating
+func GenerateReuseTestHeader(pkg string) string {
+	var code strings.Builder
+
+	code.WriteString("// Code generated by layout. DO NOT EDIT.\n\n")
+	code.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	code.WriteString("import \"testing\"\n\n")
+
+	return code.String()
+}
+
+// GenerateReuseTest generates a test for a reuse=true layout that unmarshals the
+// encodings of the golden cases in turn into one value, as a pool hands out the
+// same value for different pages, and checks that once each was seen, Reset and
+// UnmarshalLayout don't allocate
+func (g *Generator) GenerateReuseTest() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// TestLayoutReuse%s checks that Reset and UnmarshalLayout into a reused %s\n", typeName, typeName))
+	code.WriteString("// don't allocate once the value held the largest counts\n")
+	code.WriteString(fmt.Sprintf("func TestLayoutReuse%s(t *testing.T) {\n", typeName))
+	code.WriteString("\tvar bufs [][]byte\n")
+	code.WriteString(fmt.Sprintf("\tfor _, value := range %s {\n", g.caseValues("\t")))
+	code.WriteString("\t\tbuf, err := value().MarshalLayout()\n")
+	code.WriteString("\t\tif err != nil {\n")
+	code.WriteString("\t\t\tt.Fatalf(\"MarshalLayout() error: %v\", err)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tbufs = append(bufs, buf)\n")
+	code.WriteString("\t}\n\n")
+	code.WriteString(fmt.Sprintf("\tp := %s\n", g.emptyValue()))
+	code.WriteString("\tunmarshalAll := func() {\n")
+	code.WriteString("\t\tfor i, buf := range bufs {\n")
+	code.WriteString("\t\t\tp.Reset()\n")
+	code.WriteString("\t\t\tif err := p.UnmarshalLayout(buf); err != nil {\n")
+	code.WriteString("\t\t\t\tt.Fatalf(\"UnmarshalLayout(case %d) error: %v\", i, err)\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\tunmarshalAll() // Grows the slices to the largest counts\n")
+	code.WriteString("\tif allocs := testing.AllocsPerRun(100, unmarshalAll); allocs != 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\tt.Errorf(\"unmarshaling %%d cases into a reused %s allocates %%v times, want 0\", len(bufs), allocs)\n", typeName))
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
@@ -0,0 +1,73 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateReset(t *testing.T) {
+	// @layout size=64 reuse=true fixtures=fullPage
+	// type Page struct {
+	//     N       uint8     `layout:"@0"`
+	//     Magic   [4]byte   `layout:"@1"`
+	//     Head    Record    `layout:"@8"`
+	//     Records []Record  `layout:"@24,start-end,count=N"` // Record is 16 bytes, reuse=true
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Reuse: true, Fixtures: []string{"fullPage"}},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Magic", GoType: "[4]byte", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Head", GoType: "Record", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+			{Name: "Records", GoType: "[]Record", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 24, Direction: parser.StartEnd, CountField: "N"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Record", 16)
+	reg.RegisterReuse("Record")
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Nested layouts are reset first, elements up to the capacity, then kept while
+	// the rest is zeroed
+	expected := "func (p *Page) Reset() {\n" +
+		"\tp.Head.Reset()\n" +
+		"\tp.Records = p.Records[:cap(p.Records)]\n" +
+		"\tfor i := range p.Records {\n\t\tp.Records[i].Reset()\n\t}\n" +
+		"\t*p = Page{\n\t\tHead: p.Head,\n\t\tRecords: p.Records[:0],\n\t}\n}\n"
+	if !strings.Contains(code, expected) {
+		t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+	}
+
+	test := gen.GenerateReuseTest()
+	expectedParts := []string{
+		"func TestLayoutReusePage(t *testing.T) {\n",
+		"\t\tfunc() *Page { return &Page{} },\n\t\tfullPage,\n",
+		"\t\t\tp.Reset()\n\t\t\tif err := p.UnmarshalLayout(buf); err != nil {\n",
+		"\tif allocs := testing.AllocsPerRun(100, unmarshalAll); allocs != 0 {\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(test, expected) {
+			t.Errorf("Generated test missing: %q\n\nGenerated:\n%s", expected, test)
+		}
+	}
+
+	// Without reuse=true there is no Reset
+	layout.Anno.Reuse = false
+	if code := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").generateReset(); code != "" {
+		t.Errorf("generateReset() without reuse=true = %q, want nothing", code)
+	}
+}
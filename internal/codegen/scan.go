@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateScan generates Scan<Type>, which decodes back-to-back records from a
+// stream (e.g., a log file of consecutive layouts) into one reused value. Copy mode
+// reads into a reused buffer, zerocopy mode loads into the value's own buffer and
+// stream mode reads frame by frame.
+func (g *Generator) generateScan() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString(fmt.Sprintf("// Scan%s reads consecutive %s records from r until it is exhausted, calling fn\n", typeName, typeName))
+	code.WriteString("// with each. The value passed to fn is reused for the next record, so fn must copy\n")
+	code.WriteString("// anything it keeps. Scanning stops at the first error from r, decoding or fn.\n")
+	code.WriteString(fmt.Sprintf("func Scan%s(r io.Reader, fn func(*%s) error) error {\n", typeName, typeName))
+
+	var read string
+	switch g.mode {
+	case "zerocopy":
+		if g.align > 0 || g.allocator != "" {
+			code.WriteString(fmt.Sprintf("\tp := New%s()\n", typeName))
+		} else {
+			code.WriteString(fmt.Sprintf("\tp := &%s{}\n", typeName))
+		}
+		read = "p.LoadFrom(r)"
+	case "stream":
+		code.WriteString(fmt.Sprintf("\tp := &%s{}\n", typeName))
+		read = "p.ReadFrame(r)"
+	default:
+		code.WriteString(fmt.Sprintf("\tp := &%s{}\n", typeName))
+		code.WriteString(fmt.Sprintf("\tbuf := make([]byte, %d)\n", g.analyzed.BufferSize))
+	}
+
+	code.WriteString("\tfor {\n")
+	if read != "" {
+		code.WriteString(fmt.Sprintf("\t\tif err := %s; err != nil {\n", read))
+		code.WriteString("\t\t\tif err == io.EOF {\n")
+		code.WriteString("\t\t\t\treturn nil // Clean end between records\n")
+		code.WriteString("\t\t\t}\n")
+		code.WriteString("\t\t\treturn err\n")
+		code.WriteString("\t\t}\n")
+	} else {
+		code.WriteString("\t\tif _, err := io.ReadFull(r, buf); err != nil {\n")
+		code.WriteString("\t\t\tif err == io.EOF {\n")
+		code.WriteString("\t\t\t\treturn nil // Clean end between records\n")
+		code.WriteString("\t\t\t}\n")
+		code.WriteString("\t\t\treturn err\n")
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t\tif err := p.UnmarshalLayout(buf); err != nil {\n")
+		code.WriteString("\t\t\treturn err\n")
+		code.WriteString("\t\t}\n")
+	}
+	code.WriteString("\t\tif err := fn(p); err != nil {\n")
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
@@ -0,0 +1,71 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateScan(t *testing.T) {
+	// @layout size=16
+	// type Record struct {
+	//     ID  uint64 `layout:"@0"`
+	//     Seq uint64 `layout:"@8"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Record",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "ID", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Seq", GoType: "uint64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	tests := []struct {
+		mode          string
+		align         int
+		expectedParts []string
+	}{
+		{"copy", 0, []string{
+			"func ScanRecord(r io.Reader, fn func(*Record) error) error {",
+			"buf := make([]byte, 16)",
+			"if _, err := io.ReadFull(r, buf); err != nil {",
+			"if err := p.UnmarshalLayout(buf); err != nil {",
+			"if err := fn(p); err != nil {",
+		}},
+		{"zerocopy", 0, []string{
+			"p := &Record{}",
+			"if err := p.LoadFrom(r); err != nil {",
+		}},
+		{"zerocopy", 8, []string{
+			"p := NewRecord()",
+			"if err := p.LoadFrom(r); err != nil {",
+		}},
+	}
+
+	for _, tt := range tests {
+		gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, tt.align, "")
+		code, err := gen.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error: %v", err)
+		}
+
+		// A clean end of r between records ends the scan without error
+		if !strings.Contains(code, "if err == io.EOF {\n\t\t\t\treturn nil") {
+			t.Errorf("%s: Scan should treat io.EOF as the end of records\n\nGenerated:\n%s", tt.mode, code)
+		}
+		for _, expected := range tt.expectedParts {
+			if !strings.Contains(code, expected) {
+				t.Errorf("%s: generated code missing: %s\n\nGenerated:\n%s", tt.mode, expected, code)
+			}
+		}
+	}
+}
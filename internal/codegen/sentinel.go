@@ -0,0 +1,95 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// sentinelCond returns the condition that elem, an element of the sentinel
+// metadata slice named metadata, is the zero entry ending it: the offset and size
+// fields of every indirect slice reading the metadata are zero
+func (g *Generator) sentinelCond(metadata, elem string) string {
+	var conds []string
+	seen := make(map[string]bool)
+	for _, field := range g.layout.Fields {
+		if field.Layout.From != metadata {
+			continue
+		}
+		for _, name := range []string{field.Layout.OffsetField, field.Layout.SizeField} {
+			if !seen[name] {
+				seen[name] = true
+				conds = append(conds, fmt.Sprintf("%s.%s == 0", elem, name))
+			}
+		}
+	}
+	return strings.Join(conds, " && ")
+}
+
+// metadataEntries returns the expression for the number of entries the metadata
+// region occupies: the count field, or for a sentinel slice its elements plus the
+// zero entry. lenExpr is used in place of the count field when non-empty.
+func metadataEntries(region analyzer.Region, lenExpr string) string {
+	if region.Field.Layout.Sentinel {
+		return fmt.Sprintf("(len(p.%s)+1)", region.Field.Name)
+	}
+	if lenExpr != "" {
+		return lenExpr
+	}
+	return fmt.Sprintf("int(p.%s)", region.Field.Layout.CountField)
+}
+
+// generateSentinelUnmarshal generates unmarshal code for a sentinel metadata
+// slice, decoding elements until the zero entry. A region without one is corrupt.
+func (g *Generator) generateSentinelUnmarshal(region analyzer.Region) string {
+	var code strings.Builder
+	field := region.Field
+	elementSize := region.ElementSize
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, %d) ended by a zero entry (element size: %d)\n",
+		field.Name, field.GoType, region.Start, region.Boundary, elementSize))
+	code.WriteString(fmt.Sprintf("\tp.%s = p.%s[:0]\n", field.Name, field.Name))
+	code.WriteString(fmt.Sprintf("\tfor offset := %d; ; offset += %d {\n", region.Start, elementSize))
+	code.WriteString(fmt.Sprintf("\t\tif offset+%d > %d {\n", elementSize, region.Boundary))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"%s: no zero entry before offset %d: %%w\", ErrRegionOverflow)\n",
+		field.Name, region.Boundary))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tvar elem %s\n", region.ElementType))
+	code.WriteString(fmt.Sprintf("\t\tif err := elem.UnmarshalLayout(buf[offset:offset+%d]); err != nil {\n", elementSize))
+	code.WriteString(fmt.Sprintf("\t\t\treturn fmt.Errorf(\"unmarshal %s[%%d]: %%w\", len(p.%s), err)\n", field.Name, field.Name))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tif %s {\n", g.sentinelCond(field.Name, "elem")))
+	code.WriteString("\t\t\tbreak\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = append(p.%s, elem)\n", field.Name, field.Name))
+	code.WriteString("\t}\n\n")
+
+	return code.String()
+}
+
+// generateSentinelEnd generates the marshal check that the zero entry ending a
+// sentinel metadata slice fits after its elements. buf is zeroed, so the entry is
+// left in place.
+func generateSentinelEnd(region analyzer.Region) string {
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\t// Zero entry ending %s\n", region.Field.Name))
+	code.WriteString(fmt.Sprintf("\tif offset + %d > %d {\n", region.ElementSize, region.Boundary))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"%s: no room for the zero entry at offset %%d: %%w\", offset, ErrRegionOverflow)\n",
+		region.Field.Name))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\toffset += %d\n\n", region.ElementSize))
+	return code.String()
+}
+
+// generateSentinelCheck generates the marshal check, run once indirect offsets and
+// sizes are stored, that no element of a sentinel metadata slice reads as the zero
+// entry, which would end the slice early on unmarshal
+func (g *Generator) generateSentinelCheck(metadata string) string {
+	var code strings.Builder
+	code.WriteString(fmt.Sprintf("\t\tif %s {\n", g.sentinelCond(metadata, fmt.Sprintf("p.%s[i]", metadata))))
+	code.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"%s[%%d]: zero offset and size would end %s: %%w\", i, ErrOutOfRange)\n",
+		metadata, metadata))
+	code.WriteString("\t\t}\n")
+	return code.String()
+}
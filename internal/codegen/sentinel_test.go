@@ -0,0 +1,77 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// sentinelLeaf builds:
+//
+//	// @layout size=64
+//	type Leaf struct {
+//	    Flags uint16   `layout:"@0"`
+//	    Elems []Elem   `layout:"@2,start-end,sentinel"`
+//	    Keys  [][]byte `layout:"from=Elems,offset=KeyOff,size=KeySize,region=Data"`
+//	    Data  []byte   `layout:"end-start"`
+//	}
+func sentinelLeaf() *parser.TypeLayout {
+	return &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 64},
+		Fields: []parser.Field{
+			{Name: "Flags", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2, Sentinel: true,
+			}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, From: "Elems", OffsetField: "KeyOff", SizeField: "KeySize", Region: "Data",
+			}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.EndStart, StartAt: -1}},
+		},
+	}
+}
+
+func TestGenerateSentinelMetadata(t *testing.T) {
+	layout := sentinelLeaf()
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	reg.RegisterFields("Elem", []parser.Field{
+		{Name: "KeyOff", GoType: "uint16"},
+		{Name: "KeySize", GoType: "uint16"},
+		{Name: "Val", GoType: "uint32"},
+	})
+
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal: room for the zero entry, and entries offset by it
+		"\t// Zero entry ending Elems\n\tif offset + 8 > 64 {\n",
+		"return nil, fmt.Errorf(\"Elems: no room for the zero entry at offset %d: %w\", offset, ErrRegionOverflow)",
+		"\telementsEnd := 2 + (len(p.Elems)+1)*8\n",
+		"\t\tif p.Elems[i].KeyOff == 0 && p.Elems[i].KeySize == 0 {\n",
+		"return nil, fmt.Errorf(\"Elems[%d]: zero offset and size would end Elems: %w\", i, ErrOutOfRange)",
+		// Unmarshal: scan up to the zero entry
+		"\tp.Elems = p.Elems[:0]\n\tfor offset := 2; ; offset += 8 {\n",
+		"return fmt.Errorf(\"Elems: no zero entry before offset 64: %w\", ErrRegionOverflow)",
+		"\t\tif elem.KeyOff == 0 && elem.KeySize == 0 {\n\t\t\tbreak\n\t\t}\n",
+		"\t\tp.Elems = append(p.Elems, elem)\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// slottedParts returns the single indirect cell field and its slot directory region
+// for a slotted=true layout (validated by the analyzer)
+func (g *Generator) slottedParts() (parser.Field, *analyzer.Region, bool) {
+	if g.layout == nil || g.layout.Anno == nil || !g.layout.Anno.Slotted {
+		return parser.Field{}, nil, false
+	}
+
+	for _, field := range g.layout.Fields {
+		if field.Layout.From == "" {
+			continue
+		}
+		for i := range g.analyzed.Regions {
+			region := &g.analyzed.Regions[i]
+			if region.Field.Name == field.Layout.From {
+				return field, region, true
+			}
+		}
+	}
+
+	return parser.Field{}, nil, false
+}
+
+// generateSlottedHelpers generates slot-directory management for slotted pages:
+// AllocSlot, FreeSlot, FreeSpace, FragmentedBytes and Defragment.
+//
+// Slots are entries of the directory region (e.g., Elements) and cells are the
+// byte ranges they reference in the data region, packed backward from the end of
+// the buffer. A slot with a zero offset is free and may be reused.
+func (g *Generator) generateSlottedHelpers() string {
+	cell, dir, ok := g.slottedParts()
+	if !ok {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	dirName := dir.Field.Name
+	offsetField := cell.Layout.OffsetField
+	sizeField := cell.Layout.SizeField
+	offsetType := g.getMetadataFieldType(cell.Layout.From, offsetField)
+	sizeType := g.getMetadataFieldType(cell.Layout.From, sizeField)
+	bufferSize := g.analyzed.BufferSize
+
+	// slottedCellsLow: lowest offset occupied by a live cell
+	code.WriteString(fmt.Sprintf("// slottedCellsLow returns the lowest offset occupied by a live %s cell\n", cell.Name))
+	code.WriteString(fmt.Sprintf("func (p *%s) slottedCellsLow() int {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tlow := %d\n", bufferSize))
+	code.WriteString(fmt.Sprintf("\tfor i := 0; i < p.Get%sCount(); i++ {\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\t\telem := p.Get%sAt(i)\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\t\tif elem.%s != 0 && int(elem.%s) < low {\n", offsetField, offsetField))
+	code.WriteString(fmt.Sprintf("\t\t\tlow = int(elem.%s)\n", offsetField))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn low\n")
+	code.WriteString("}\n\n")
+
+	// FreeSpace: contiguous bytes between the directory and the cells
+	code.WriteString(fmt.Sprintf("// FreeSpace returns the contiguous free bytes between the %s directory and the lowest cell\n", dirName))
+	code.WriteString(fmt.Sprintf("func (p *%s) FreeSpace() int {\n", typeName))
+	code.WriteString(fmt.Sprintf("\treturn p.slottedCellsLow() - (%d + p.Get%sCount()*%d)\n", dir.Start, upperFirst(dirName), dir.ElementSize))
+	code.WriteString("}\n\n")
+
+	// FragmentedBytes: dead bytes inside the cell area
+	code.WriteString("// FragmentedBytes returns the bytes inside the cell area not referenced by any live slot\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) FragmentedBytes() int {\n", typeName))
+	code.WriteString("\tused := 0\n")
+	code.WriteString(fmt.Sprintf("\tfor i := 0; i < p.Get%sCount(); i++ {\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\t\telem := p.Get%sAt(i)\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\t\tif elem.%s != 0 {\n", offsetField))
+	code.WriteString(fmt.Sprintf("\t\t\tused += int(elem.%s)\n", sizeField))
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\treturn %d - p.slottedCellsLow() - used\n", bufferSize))
+	code.WriteString("}\n\n")
+
+	// Defragment: compact live cells against the end of the buffer
+	code.WriteString("// Defragment compacts live cells against the end of the buffer in slot order,\n")
+	code.WriteString("// making all free space contiguous, and reloads the page\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) Defragment() error {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tvar scratch [%d]byte\n", bufferSize))
+	code.WriteString(fmt.Sprintf("\toffset := %d\n", bufferSize))
+	code.WriteString(fmt.Sprintf("\tfor i := 0; i < p.Get%sCount(); i++ {\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\t\telem := p.Get%sAt(i)\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\t\tif elem.%s == 0 {\n", offsetField))
+	code.WriteString("\t\t\tcontinue\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tstart := int(elem.%s)\n", offsetField))
+	code.WriteString(fmt.Sprintf("\t\tsize := int(elem.%s)\n", sizeField))
+	code.WriteString("\t\toffset -= size\n")
+	code.WriteString("\t\tcopy(scratch[offset:offset+size], p.buf[start:start+size])\n")
+	code.WriteString(fmt.Sprintf("\t\telem.%s = %s(offset)\n", offsetField, offsetType))
+	code.WriteString(fmt.Sprintf("\t\tp.Set%sAt(i, elem)\n", upperFirst(dirName)))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tcopy(p.buf[offset:%d], scratch[offset:])\n", bufferSize))
+	code.WriteString("\treturn p.UnmarshalLayout(p.buf[:])\n")
+	code.WriteString("}\n\n")
+
+	// AllocSlot: reserve a cell and a slot referencing it
+	code.WriteString(fmt.Sprintf("// AllocSlot reserves size bytes of cell space and returns the %s slot referencing it.\n", dirName))
+	code.WriteString("// Free slots are reused before the directory grows, and the page is defragmented\n")
+	code.WriteString("// when the request only fits after compaction.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) AllocSlot(size int) (int, error) {\n", typeName))
+	code.WriteString("\tif size < 0 {\n")
+	code.WriteString("\t\treturn 0, fmt.Errorf(\"AllocSlot: negative size %d: %w\", size, ErrOutOfRange)\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tcount := p.Get%sCount()\n", upperFirst(dirName)))
+	code.WriteString("\tslot := -1\n")
+	code.WriteString("\tfor i := 0; i < count; i++ {\n")
+	code.WriteString(fmt.Sprintf("\t\tif p.Get%sAt(i).%s == 0 {\n", upperFirst(dirName), offsetField))
+	code.WriteString("\t\t\tslot = i\n")
+	code.WriteString("\t\t\tbreak\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tdirEnd := %d + count*%d\n", dir.Start, dir.ElementSize))
+	code.WriteString("\tif slot < 0 {\n")
+	code.WriteString(fmt.Sprintf("\t\tdirEnd += %d\n", dir.ElementSize))
+	code.WriteString("\t}\n")
+	code.WriteString("\tfree := p.slottedCellsLow() - dirEnd\n")
+	code.WriteString("\tif free < size {\n")
+	code.WriteString("\t\tif free+p.FragmentedBytes() < size {\n")
+	code.WriteString("\t\t\treturn 0, fmt.Errorf(\"AllocSlot: need %d bytes, have %d: %w\", size, free+p.FragmentedBytes(), ErrRegionOverflow)\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\tif err := p.Defragment(); err != nil {\n")
+	code.WriteString("\t\t\treturn 0, err\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\toffset := p.slottedCellsLow() - size\n")
+	code.WriteString(g.overflowGuard("offset", offsetType, dirName+"."+offsetField, "0, ", "\t"))
+	code.WriteString(g.overflowGuard("size", sizeType, dirName+"."+sizeField, "0, ", "\t"))
+	code.WriteString("\tif slot < 0 {\n")
+	capacity, _ := g.countCapacity(dir.Field.Layout.CountField)
+	if limit := g.countLimit(dir.Field.Layout.CountField); limit >= 0 && limit < capacity {
+		code.WriteString(fmt.Sprintf("\t\tif count+1 > %d {\n", limit))
+		code.WriteString(fmt.Sprintf("\t\t\treturn 0, fmt.Errorf(\"AllocSlot: %s full at %%d slots: %%w\", count, ErrRegionOverflow)\n", dirName))
+		code.WriteString("\t\t}\n")
+	}
+	code.WriteString("\t\tslot = count\n")
+	code.WriteString(g.countFieldSetter(dir.Field.Layout.CountField, "count+1", "\t\t"))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tvar elem %s\n", dir.ElementType))
+	code.WriteString(fmt.Sprintf("\telem.%s = %s(offset)\n", offsetField, offsetType))
+	code.WriteString(fmt.Sprintf("\telem.%s = %s(size)\n", sizeField, sizeType))
+	code.WriteString(fmt.Sprintf("\tp.Set%sAt(slot, elem)\n", upperFirst(dirName)))
+	code.WriteString("\treturn slot, p.UnmarshalLayout(p.buf[:])\n")
+	code.WriteString("}\n\n")
+
+	// FreeSlot: release a cell and trim trailing free slots
+	code.WriteString(fmt.Sprintf("// FreeSlot releases the cell referenced by %s slot idx. The slot is marked free\n", dirName))
+	code.WriteString("// for reuse, trailing free slots are trimmed from the directory, and the page is\n")
+	code.WriteString("// reloaded.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) FreeSlot(idx int) error {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tif idx < 0 || idx >= p.Get%sCount() {\n", upperFirst(dirName)))
+	code.WriteString("\t\tpanic(\"index out of bounds\")\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tvar elem %s\n", dir.ElementType))
+	code.WriteString(fmt.Sprintf("\tp.Set%sAt(idx, elem)\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\tcount := p.Get%sCount()\n", upperFirst(dirName)))
+	code.WriteString(fmt.Sprintf("\tfor count > 0 && p.Get%sAt(count-1).%s == 0 {\n", upperFirst(dirName), offsetField))
+	code.WriteString("\t\tcount--\n")
+	code.WriteString("\t}\n")
+	code.WriteString(g.countFieldSetter(dir.Field.Layout.CountField, "count", "\t"))
+	code.WriteString("\treturn p.UnmarshalLayout(p.buf[:])\n")
+	code.WriteString("}\n\n")
+
+	return code.String()
+}
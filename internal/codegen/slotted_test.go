@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateSlottedHelpers(t *testing.T) {
+	// @layout
+	// type Slot struct {
+	//     Off uint16 `layout:"@0"`
+	//     Len uint16 `layout:"@2"`
+	// }
+	//
+	// @layout size=4096 mode=zerocopy slotted=true
+	// type Page struct {
+	//     buf      [4096]byte
+	//     NumSlots uint16   `layout:"@0"`
+	//     Slots    []Slot   `layout:"@2,start-end,count=NumSlots"`
+	//     Data     []byte   `layout:"end-start"`
+	//     Cells    [][]byte `layout:"from=Slots,offset=Off,size=Len,region=Data,offsetmode=page"`
+	// }
+	slot := &parser.TypeLayout{
+		Name: "Slot",
+		Anno: &parser.TypeAnnotation{Size: 4},
+		Fields: []parser.Field{
+			{Name: "Off", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+		},
+	}
+	page := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 4096, Mode: "zerocopy", Slotted: true},
+		Fields: []parser.Field{
+			{Name: "NumSlots", GoType: "uint16", Layout: &parser.FieldLayout{
+				Offset: 0, Direction: parser.Fixed,
+			}},
+			{Name: "Slots", GoType: "[]Slot", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: 2, CountField: "NumSlots",
+			}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.EndStart, StartAt: -1,
+			}},
+			{Name: "Cells", GoType: "[][]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, From: "Slots", OffsetField: "Off", SizeField: "Len",
+				Region: "Data", OffsetMode: parser.OffsetPage,
+			}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Slot", 4)
+	reg.Register("Page", 4096)
+
+	analyzed, err := analyzer.Analyze(page, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	all := []*parser.TypeLayout{slot, page}
+	gen := NewGenerator(analyzed, page, all, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Page) AllocSlot(size int) (int, error) {",
+		"func (p *Page) FreeSlot(idx int) error {",
+		"func (p *Page) FreeSpace() int {",
+		"func (p *Page) FragmentedBytes() int {",
+		"func (p *Page) Defragment() error {",
+		// Reloading the page reports its errors
+		"\tcopy(p.buf[offset:4096], scratch[offset:])\n\treturn p.UnmarshalLayout(p.buf[:])\n",
+		"\t\tif err := p.Defragment(); err != nil {\n\t\t\treturn 0, err\n",
+		// Directory end accounts for the slot about to be appended
+		"dirEnd := 2 + count*4",
+		// Count field updated through the zerocopy setter
+		"p.SetNumSlots(uint16(count+1))",
+		// Free slots are skipped when rebuilding cells
+		"if offset == 0 {\n\t\t\tp.Cells[i] = nil",
+	}
+
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q", expected)
+		}
+	}
+
+	// Non-slotted layouts don't get slot management
+	page.Anno.Slotted = false
+	gen = NewGenerator(analyzed, page, all, reg, "little", "zerocopy", 0, "")
+	code, err = gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if strings.Contains(code, "AllocSlot") {
+		t.Error("AllocSlot generated without slotted=true")
+	}
+}
@@ -0,0 +1,230 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// splitEntries describes the entries SplitInto and MergeFrom move: the elements
+// of the metadata slice and, per element, the fields reading it
+type splitEntries struct {
+	metadata analyzer.Region
+	fields   []parser.Field // Slices holding one entry per element, metadata first
+	count    string         // Count field of the metadata, empty for sentinel
+}
+
+// splitMetadata returns the entries of a copy-mode layout whose indirect slices
+// read a single metadata slice, which SplitInto and MergeFrom move between
+// pages. Not ok when the metadata shares its count with other regions or has a
+// count of a custom kind, which moving entries can't keep in step.
+func (g *Generator) splitMetadata() (splitEntries, bool) {
+	if g.mode != "copy" || g.layout.Anno.Next != "" {
+		return splitEntries{}, false
+	}
+	from := ""
+	for _, field := range g.layout.Fields {
+		if field.Layout.From == "" {
+			continue
+		}
+		if from != "" && field.Layout.From != from {
+			return splitEntries{}, false // Pages of several directories split apart
+		}
+		from = field.Layout.From
+	}
+	if from == "" {
+		return splitEntries{}, false
+	}
+
+	var entries splitEntries
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.DynamicRegion && region.Field.Name == from {
+			entries.metadata = region
+		}
+	}
+	fl := entries.metadata.Field.Layout
+	if fl == nil || fl.Group != "" {
+		return splitEntries{}, false
+	}
+
+	entries.fields = append(entries.fields, entries.metadata.Field)
+	moved := map[string]bool{from: true}
+	for _, field := range g.layout.Fields {
+		if field.Layout.From == from || field.Layout.Extents == from || field.Layout.Parallel == from {
+			entries.fields = append(entries.fields, field)
+			moved[field.Name] = true
+		}
+	}
+
+	if fl.CountField != "" {
+		for _, region := range g.analyzed.SharedCount(fl.CountField) {
+			if !moved[region.Field.Name] {
+				return splitEntries{}, false
+			}
+		}
+		if g.countKind(fl.CountField) {
+			return splitEntries{}, false
+		}
+		entries.count = fl.CountField
+	}
+	return entries, true
+}
+
+// generateSplitMerge generates SplitInto and MergeFrom for pages of indirect
+// slices, the B-tree leaf routines: moving the entries past a pivot to a new
+// right sibling, and taking back a sibling's entries when both fit in one page.
+// Entries are moved as elements and their byte slices, copied so neither page
+// shares memory with the other's buffer, and counts are set on both;
+// MarshalLayout then packs the data and rewrites the offsets and sizes.
+func (g *Generator) generateSplitMerge() string {
+	entries, ok := g.splitMetadata()
+	if !ok {
+		return ""
+	}
+	typeName := g.analyzed.TypeName
+	metadata := entries.metadata.Field.Name
+
+	var code strings.Builder
+	code.WriteString("\n// SplitInto moves the entries of p from pivot on into right, replacing its own,\n")
+	code.WriteString("// and sets the counts of both. The moved bytes are copied, so right doesn't share\n")
+	code.WriteString(fmt.Sprintf("// p's buffer. Panics if pivot is outside [0, len(p.%s)].\n", metadata))
+	code.WriteString(fmt.Sprintf("func (p *%s) SplitInto(right *%s, pivot int) {\n", typeName, typeName))
+	code.WriteString(fmt.Sprintf("\tif pivot < 0 || pivot > len(p.%s) {\n", metadata))
+	code.WriteString(g.generatePanic("\t\t", "SplitInto: pivot %d outside [0, %d]", fmt.Sprintf("pivot, len(p.%s)", metadata),
+		fmt.Sprintf("SplitInto: pivot outside [0, len(p.%s)]", metadata)))
+	code.WriteString("\t}\n")
+	for _, field := range entries.fields {
+		name := field.Name
+		if field.GoType == "[][]byte" {
+			code.WriteString(fmt.Sprintf("\tright.%s = right.%s[:0]\n", name, name))
+			code.WriteString(fmt.Sprintf("\tfor _, entry := range p.%s[pivot:] {\n", name))
+			code.WriteString(fmt.Sprintf("\t\tright.%s = append(right.%s, bytes.Clone(entry))\n", name, name))
+			code.WriteString("\t}\n")
+		} else {
+			code.WriteString(fmt.Sprintf("\tright.%s = append(right.%s[:0], p.%s[pivot:]...)\n", name, name, name))
+		}
+		code.WriteString(fmt.Sprintf("\tp.%s = p.%s[:pivot]\n", name, name))
+	}
+	code.WriteString(g.splitCounts(entries, "p", "right"))
+	code.WriteString("}\n\n")
+
+	code.WriteString("// MergeFrom appends the entries of right to p, leaving right empty, and sets\n")
+	code.WriteString("// the counts of both. The moved bytes are copied, so p doesn't share right's\n")
+	code.WriteString("// buffer. It fails with ErrRegionOverflow, changing neither page, when the\n")
+	code.WriteString("// entries of both don't fit in one.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) MergeFrom(right *%s) error {\n", typeName, typeName))
+	code.WriteString(g.generateMergeCheck(entries))
+	for _, field := range entries.fields {
+		name := field.Name
+		if field.GoType == "[][]byte" {
+			code.WriteString(fmt.Sprintf("\tfor _, entry := range right.%s {\n", name))
+			code.WriteString(fmt.Sprintf("\t\tp.%s = append(p.%s, bytes.Clone(entry))\n", name, name))
+			code.WriteString("\t}\n")
+		} else {
+			code.WriteString(fmt.Sprintf("\tp.%s = append(p.%s, right.%s...)\n", name, name, name))
+		}
+		code.WriteString(fmt.Sprintf("\tright.%s = right.%s[:0]\n", name, name))
+	}
+	code.WriteString(g.splitCounts(entries, "p", "right"))
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// splitCounts returns the statements setting the metadata count of each page
+// from its number of elements
+func (g *Generator) splitCounts(entries splitEntries, pages ...string) string {
+	if entries.count == "" {
+		return "" // The sentinel entry ends the slice
+	}
+	var code strings.Builder
+	countType := g.countFieldType(entries.count)
+	for _, page := range pages {
+		code.WriteString(fmt.Sprintf("\t%s.%s = %s(len(%s.%s))\n", page, entries.count, countType, page, entries.metadata.Field.Name))
+	}
+	return code.String()
+}
+
+// generateMergeCheck generates the start of MergeFrom refusing entries that
+// don't fit in one page: more elements than the metadata region or its count
+// holds, or more bytes than each data region, or the space it shares with the
+// metadata, holds. Prefix-encoded entries are counted as stored after merging.
+func (g *Generator) generateMergeCheck(entries splitEntries) string {
+	var code strings.Builder
+	metadata := entries.metadata
+	name := metadata.Field.Name
+	stride := metadata.ElementStride
+
+	code.WriteString(fmt.Sprintf("\tn := len(p.%s) + len(right.%s)\n", name, name))
+	if limit, ok := g.maxElements(metadata); ok {
+		if metadata.Field.Layout.Sentinel {
+			limit-- // The sentinel entry takes one
+		}
+		code.WriteString(fmt.Sprintf("\tif n > %d {\n", limit))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"MergeFrom: %%d elements exceed the %d %s holds: %%w\", n, ErrRegionOverflow)\n", limit, name))
+		code.WriteString("\t}\n")
+	}
+
+	var regions []analyzer.Region
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.DynamicRegion && region.Field.Layout.Group == "" {
+			regions = append(regions, region)
+		}
+	}
+	pairs := g.sharedSpace(regions)
+	for _, data := range regions {
+		var readers []parser.Field
+		for _, field := range entries.fields {
+			if field.Layout.Region == data.Field.Name {
+				readers = append(readers, field)
+			}
+		}
+		if len(readers) == 0 {
+			continue
+		}
+
+		low, high := g.regionSpan(data)
+		space, used := high-low, "0"
+		holder := fmt.Sprintf("%s holds", data.Field.Name)
+		for _, pair := range pairs {
+			if pair.forward.Field.Name == name && pair.backward.Field.Name == data.Field.Name ||
+				pair.backward.Field.Name == name && pair.forward.Field.Name == data.Field.Name {
+				space, used = pair.space, fmt.Sprintf("n * %d", stride)
+				if metadata.Field.Layout.Sentinel {
+					used = fmt.Sprintf("(n + 1) * %d", stride)
+				}
+				holder = fmt.Sprintf("%s and %s share", pair.forward.Field.Name, pair.backward.Field.Name)
+			}
+		}
+		total := "used" + data.Field.Name
+		code.WriteString(fmt.Sprintf("\t%s := %s\n", total, used))
+		for _, reader := range readers {
+			if isPrefix(reader) {
+				code.WriteString(fmt.Sprintf("\tvar prev%s []byte // Right's first entry follows p's last\n", reader.Name))
+			}
+		}
+		code.WriteString("\tfor _, page := range []*" + g.analyzed.TypeName + "{p, right} {\n")
+		for _, reader := range readers {
+			code.WriteString(fmt.Sprintf("\t\tfor _, entry := range page.%s {\n", reader.Name))
+			if isPrefix(reader) {
+				prev := "prev" + reader.Name
+				code.WriteString(sharedPrefix(prev, "entry", "\t\t\t"))
+				code.WriteString("\t\t\tvar head [binary.MaxVarintLen64]byte\n")
+				code.WriteString(fmt.Sprintf("\t\t\t%s += binary.PutUvarint(head[:], uint64(shared)) + len(entry) - shared\n", total))
+				code.WriteString(fmt.Sprintf("\t\t\t%s = entry\n", prev))
+			} else {
+				code.WriteString(fmt.Sprintf("\t\t\t%s += len(entry)\n", total))
+			}
+			code.WriteString("\t\t}\n")
+		}
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\tif %s > %d {\n", total, space))
+		code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"MergeFrom: entries need %%d bytes, %s %d: %%w\", %s, ErrRegionOverflow)\n",
+			holder, space, total))
+		code.WriteString("\t}\n")
+	}
+	return code.String()
+}
@@ -0,0 +1,105 @@
+package codegen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateSplitMerge(t *testing.T) {
+	// @layout size=8
+	// type Elem struct {
+	//     KeyOffset   uint16 `layout:"@0"`
+	//     KeySize     uint16 `layout:"@2"`
+	//     ValueOffset uint16 `layout:"@4"`
+	//     ValueSize   uint16 `layout:"@6"`
+	// }
+	//
+	// @layout size=512
+	// type Leaf struct {
+	//     Header Header   `layout:"@0"`
+	//     Elems  []Elem   `layout:"@16,start-end,count=Header.N"`
+	//     Data   []byte   `layout:"end-start"`
+	//     Keys   [][]byte `layout:"from=Elems,offset=KeyOffset,size=KeySize,region=Data"`
+	//     Values [][]byte `layout:"from=Elems,offset=ValueOffset,size=ValueSize,region=Data"`
+	// }
+	header := &parser.TypeLayout{
+		Name: "Header",
+		Anno: &parser.TypeAnnotation{Size: 16},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	elem := &parser.TypeLayout{
+		Name: "Elem",
+		Anno: &parser.TypeAnnotation{Size: 8},
+		Fields: []parser.Field{
+			{Name: "KeyOffset", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "KeySize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 2, Direction: parser.Fixed}},
+			{Name: "ValueOffset", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 4, Direction: parser.Fixed}},
+			{Name: "ValueSize", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 6, Direction: parser.Fixed}},
+		},
+	}
+	layout := &parser.TypeLayout{
+		Name: "Leaf",
+		Anno: &parser.TypeAnnotation{Size: 512},
+		Fields: []parser.Field{
+			{Name: "Header", GoType: "Header", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{Offset: -1, StartAt: 16, Direction: parser.StartEnd, CountField: "Header.N"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{Offset: -1, StartAt: -1, Direction: parser.EndStart}},
+			{Name: "Keys", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, From: "Elems", OffsetField: "KeyOffset", SizeField: "KeySize", Region: "Data"}},
+			{Name: "Values", GoType: "[][]byte", Layout: &parser.FieldLayout{Offset: -1, From: "Elems", OffsetField: "ValueOffset", SizeField: "ValueSize", Region: "Data"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Header", 16)
+	reg.RegisterFields("Header", header.Fields)
+	reg.Register("Elem", 8)
+	reg.RegisterFields("Elem", elem.Fields)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+
+	allLayouts := []*parser.TypeLayout{header, elem, layout}
+	gen := NewGenerator(analyzed, layout, allLayouts, reg, "little", "copy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"func (p *Leaf) SplitInto(right *Leaf, pivot int) {\n",
+		"\tif pivot < 0 || pivot > len(p.Elems) {\n",
+		"\tright.Elems = append(right.Elems[:0], p.Elems[pivot:]...)\n\tp.Elems = p.Elems[:pivot]\n",
+		// Moved bytes don't alias the source page's buffer
+		"\tfor _, entry := range p.Keys[pivot:] {\n\t\tright.Keys = append(right.Keys, bytes.Clone(entry))\n\t}\n\tp.Keys = p.Keys[:pivot]\n",
+		"\tp.Header.N = uint16(len(p.Elems))\n\tright.Header.N = uint16(len(right.Elems))\n",
+		"func (p *Leaf) MergeFrom(right *Leaf) error {\n",
+		"\tif n > 62 {\n",
+		// Elements and entries share the 496 bytes past the header
+		"\tusedData := n * 8\n",
+		"\t\tfor _, entry := range page.Values {\n\t\t\tusedData += len(entry)\n",
+		"\tif usedData > 496 {\n",
+		"\t\tp.Keys = append(p.Keys, bytes.Clone(entry))\n",
+		"\tright.Values = right.Values[:0]\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if !slices.Contains(gen.Imports(), "bytes") {
+		t.Errorf("Imports() = %v, want bytes", gen.Imports())
+	}
+
+	// Zerocopy pages keep their entries in the buffer, which the methods don't move
+	zc := NewGenerator(analyzed, layout, allLayouts, reg, "little", "zerocopy", 0, "")
+	if got := zc.generateSplitMerge(); got != "" {
+		t.Errorf("zerocopy generateSplitMerge() = %q, want none", got)
+	}
+}
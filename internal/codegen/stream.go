@@ -0,0 +1,216 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+)
+
+// generateStream generates the frame codec for mode=stream. A frame is the fixed
+// header, the payload and the trailer, with its total length in the length= header
+// field, so frames are only as long as their payload needs. size= bounds the frame
+// length, guarding ReadFrame against corrupt or hostile length fields.
+func (g *Generator) generateStream() string {
+	var code strings.Builder
+
+	code.WriteString(g.generateStreamMarshal())
+	code.WriteString("\n")
+	code.WriteString(g.generateStreamUnmarshal())
+	code.WriteString("\n")
+	code.WriteString(g.generateReadFrame())
+	code.WriteString("\n")
+	code.WriteString(g.generateWriteFrame())
+
+	return code.String()
+}
+
+// streamParts returns the payload region, the length field region and the trailer size
+func (g *Generator) streamParts() (payload, length analyzer.Region, trailerSize int) {
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.DynamicRegion {
+			payload = region
+		} else if region.Field.Name == g.layout.Anno.Length {
+			length = region
+		}
+	}
+	for _, region := range g.analyzed.Trailer {
+		trailerSize += region.Boundary - region.Start
+	}
+	return payload, length, trailerSize
+}
+
+// generateTrailerOps generates marshal/unmarshal code for trailer fields, which sit
+// at offsets relative to the trailer slice rather than buf
+func (g *Generator) generateTrailerOps(op string) string {
+	var code strings.Builder
+	for _, region := range g.analyzed.Trailer {
+		code.WriteString(strings.ReplaceAll(g.generateFixedOp(region, op), "buf[", "trailer["))
+	}
+	return code.String()
+}
+
+// generateStreamHookOps generates the hooks of header fields, then of trailer
+// fields, so a trailing checksum sees the rest of the frame
+func (g *Generator) generateStreamHookOps(op string) string {
+	code := g.generateHookOps(g.analyzed.Regions, op)
+	code += strings.ReplaceAll(g.generateHookOps(g.analyzed.Trailer, op), "buf[", "trailer[")
+	return code
+}
+
+// generateStreamMarshal generates MarshalLayout for a stream frame, which sets the
+// length field to the encoded frame length
+func (g *Generator) generateStreamMarshal() string {
+	var code strings.Builder
+	payload, length, trailerSize := g.streamParts()
+	headerSize := payload.Start
+	maxSize := g.analyzed.BufferSize
+
+	code.WriteString(fmt.Sprintf("// MarshalLayout encodes p as one frame, setting %s to the frame length\n", length.Field.Name))
+	code.WriteString(fmt.Sprintf("func (p *%s) MarshalLayout() ([]byte, error) {\n", g.analyzed.TypeName))
+	code.WriteString(g.generateMarshalDefaults())
+	code.WriteString(fmt.Sprintf("\tn := %d + len(p.%s) + %d\n", headerSize, payload.Field.Name, trailerSize))
+	code.WriteString(fmt.Sprintf("\tif n > %d {\n", maxSize))
+	code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"frame of %%d bytes exceeds maximum %d: %%w\", n, ErrLongBuffer)\n", maxSize))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tp.%s = %s(n)\n", length.Field.Name, length.Field.GoType))
+	code.WriteString("\tbuf := make([]byte, n)\n\n")
+
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.FixedRegion {
+			code.WriteString(g.generateFixedOp(region, "marshal"))
+		}
+	}
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, n-%d)\n", payload.Field.Name, payload.Field.GoType, headerSize, trailerSize))
+	code.WriteString(fmt.Sprintf("\tcopy(buf[%d:], p.%s)\n\n", headerSize, payload.Field.Name))
+
+	if len(g.analyzed.Trailer) > 0 {
+		code.WriteString(fmt.Sprintf("\ttrailer := buf[n-%d:]\n", trailerSize))
+		code.WriteString(g.generateTrailerOps("marshal"))
+	}
+	code.WriteString(g.generateStreamHookOps("marshal"))
+
+	code.WriteString("\treturn buf, nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// generateStreamUnmarshal generates UnmarshalLayout for a stream frame. buf must be
+// exactly one frame: its length has to match the length field.
+func (g *Generator) generateStreamUnmarshal() string {
+	var code strings.Builder
+	payload, length, trailerSize := g.streamParts()
+	headerSize := payload.Start
+	minSize := headerSize + trailerSize
+	maxSize := g.analyzed.BufferSize
+	lenVar := g.localName(payload.Field.Name, "Len")
+
+	code.WriteString(fmt.Sprintf("// UnmarshalLayout decodes one frame; len(buf) must equal %s\n", length.Field.Name))
+	code.WriteString(fmt.Sprintf("func (p *%s) UnmarshalLayout(buf []byte) error {\n", g.analyzed.TypeName))
+	code.WriteString(generateLengthCheck("len(buf)", minSize, maxSize, "frame", ""))
+	code.WriteString("\n")
+
+	for _, region := range g.analyzed.Regions {
+		if region.Kind == analyzer.FixedRegion {
+			code.WriteString(g.generateFixedOp(region, "unmarshal"))
+		}
+	}
+
+	code.WriteString(fmt.Sprintf("\tif int(p.%s) != len(buf) {\n", length.Field.Name))
+	code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s %%d does not match frame of %%d bytes: %%w\", p.%s, len(buf), ErrCountMismatch)\n",
+		length.Field.Name, length.Field.Name))
+	code.WriteString("\t}\n\n")
+
+	code.WriteString(fmt.Sprintf("\t// %s: %s at [%d, len(buf)-%d)\n", payload.Field.Name, payload.Field.GoType, headerSize, trailerSize))
+	code.WriteString(fmt.Sprintf("\t%s := len(buf) - %d\n", lenVar, minSize))
+	code.WriteString("\t// Reuse buffer if capacity allows\n")
+	code.WriteString(fmt.Sprintf("\tif cap(p.%s) >= %s {\n", payload.Field.Name, lenVar))
+	code.WriteString(fmt.Sprintf("\t\tp.%s = p.%s[:%s]\n", payload.Field.Name, payload.Field.Name, lenVar))
+	code.WriteString("\t} else {\n")
+	code.WriteString(fmt.Sprintf("\t\tp.%s = make([]byte, %s)\n", payload.Field.Name, lenVar))
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tcopy(p.%s, buf[%d:%d+%s])\n\n", payload.Field.Name, headerSize, headerSize, lenVar))
+
+	if len(g.analyzed.Trailer) > 0 {
+		code.WriteString(fmt.Sprintf("\ttrailer := buf[len(buf)-%d:]\n", trailerSize))
+		code.WriteString(g.generateTrailerOps("unmarshal"))
+	}
+	code.WriteString(g.generateStreamHookOps("unmarshal"))
+	code.WriteString(g.generateCanonicalCheck())
+
+	code.WriteString("\treturn nil\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// generateReadFrame generates ReadFrame, which reads the header first to learn the
+// frame length, then the rest of the frame. A clean end of stream before the header
+// is returned as io.EOF.
+func (g *Generator) generateReadFrame() string {
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	payload, length, trailerSize := g.streamParts()
+	headerSize := payload.Start
+	minSize := headerSize + trailerSize
+	maxSize := g.analyzed.BufferSize
+
+	var lengthExpr string
+	if length.Boundary-length.Start == 1 {
+		lengthExpr = fmt.Sprintf("int(header[%d])", length.Start)
+	} else {
+		lengthExpr = fmt.Sprintf("int(%s.%s(header[%d:%d]))",
+			g.endianPrefix(), g.binaryGetFunc(length.Field.GoType), length.Start, length.Boundary)
+	}
+
+	code.WriteString(fmt.Sprintf("// Read%sFrame reads the next frame from r\n", typeName))
+	code.WriteString(fmt.Sprintf("func Read%sFrame(r io.Reader) (*%s, error) {\n", typeName, typeName))
+	code.WriteString(fmt.Sprintf("\tp := &%s{}\n", typeName))
+	code.WriteString("\tif err := p.ReadFrame(r); err != nil {\n")
+	code.WriteString("\t\treturn nil, err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn p, nil\n")
+	code.WriteString("}\n\n")
+
+	code.WriteString("// ReadFrame reads the next frame from r into p. Each frame is read into a buffer\n")
+	code.WriteString("// of its own length, allocated per call; the payload is then copied into p's,\n")
+	code.WriteString("// which is reused when large enough.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) ReadFrame(r io.Reader) error {\n", typeName))
+	code.WriteString(fmt.Sprintf("\tvar header [%d]byte\n", headerSize))
+	code.WriteString("\tif _, err := io.ReadFull(r, header[:]); err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+	code.WriteString(fmt.Sprintf("\tn := %s\n", lengthExpr))
+	code.WriteString(generateLengthCheck("n", minSize, maxSize, "ReadFrame: frame length", ""))
+	code.WriteString("\tbuf := make([]byte, n)\n")
+	code.WriteString("\tcopy(buf, header[:])\n")
+	code.WriteString(fmt.Sprintf("\tif _, err := io.ReadFull(r, buf[%d:]); err != nil {\n", headerSize))
+	code.WriteString("\t\tif err == io.EOF {\n")
+	code.WriteString("\t\t\terr = io.ErrUnexpectedEOF // The header promised more bytes\n")
+	code.WriteString("\t\t}\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\treturn p.UnmarshalLayout(buf)\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
+
+// generateWriteFrame generates WriteFrame, which encodes and writes one frame
+func (g *Generator) generateWriteFrame() string {
+	var code strings.Builder
+
+	code.WriteString("// WriteFrame encodes p and writes it to w as one frame\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) WriteFrame(w io.Writer) error {\n", g.analyzed.TypeName))
+	code.WriteString("\tbuf, err := p.MarshalLayout()\n")
+	code.WriteString("\tif err != nil {\n")
+	code.WriteString("\t\treturn err\n")
+	code.WriteString("\t}\n")
+	code.WriteString("\t_, err = w.Write(buf)\n")
+	code.WriteString("\treturn err\n")
+	code.WriteString("}\n")
+
+	return code.String()
+}
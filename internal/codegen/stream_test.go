@@ -0,0 +1,79 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateStream(t *testing.T) {
+	// @layout size=1500 mode=stream length=Len endian=big
+	// type Msg struct {
+	//     Type    uint8  `layout:"@0"`
+	//     Len     uint16 `layout:"@1"`
+	//     Payload []byte `layout:"start-end"`
+	//     CRC     uint32 `layout:"trailer"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Msg",
+		Anno: &parser.TypeAnnotation{Size: 1500, Mode: "stream", Length: "Len", Endian: "big"},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Len", GoType: "uint16", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Payload", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, Direction: parser.StartEnd, StartAt: -1,
+			}},
+			{Name: "CRC", GoType: "uint32", Layout: &parser.FieldLayout{Offset: -1, Direction: parser.Fixed, Trailer: true}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "big", "stream", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		// Marshal sizes the frame and sets the length field
+		"n := 3 + len(p.Payload) + 4",
+		"p.Len = uint16(n)",
+		"copy(buf[3:], p.Payload)",
+		"trailer := buf[n-4:]",
+		"binary.BigEndian.PutUint32(trailer[0:4], p.CRC)",
+		// Unmarshal checks the frame against the length field
+		"if len(buf) > 1500 {",
+		"if int(p.Len) != len(buf) {",
+		"payloadLen := len(buf) - 7",
+		"p.CRC = binary.BigEndian.Uint32(trailer[0:4])",
+		// Frame reader/writer
+		"func ReadMsgFrame(r io.Reader) (*Msg, error) {",
+		"func (p *Msg) ReadFrame(r io.Reader) error {",
+		"n := int(binary.BigEndian.Uint16(header[1:3]))",
+		"if n > 1500 {",
+		"func (p *Msg) WriteFrame(w io.Writer) error {",
+		"func ScanMsg(r io.Reader, fn func(*Msg) error) error {",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %s\n\nGenerated:\n%s", expected, code)
+		}
+	}
+
+	// Frames vary in length, so no fixed-size endian conversion
+	if strings.Contains(code, "ConvertEndian") {
+		t.Errorf("Stream mode should not generate ConvertEndian\n\nGenerated:\n%s", code)
+	}
+
+	imports := strings.Join(gen.Imports(), ",")
+	if imports != "encoding/binary,fmt,io" {
+		t.Errorf("Imports() = %s, want encoding/binary,fmt,io", imports)
+	}
+}
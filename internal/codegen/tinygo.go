@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// TargetTinyGo is the -target compiling generated code with TinyGo, e.g. for
+// microcontrollers speaking the same binary protocol
+const TargetTinyGo = "tinygo"
+
+// tinyGoUnsupported lists the packages TinyGo doesn't fully support: reflection
+// is partial, and unsafe pointer casts into byte buffers fault on cores without
+// unaligned loads
+var tinyGoUnsupported = []string{"encoding/json", "reflect", "unsafe"}
+
+// SetTarget sets the compiler the generated code must build with (-target):
+// "" for gc, or TargetTinyGo
+func (g *Generator) SetTarget(target string) {
+	g.target = target
+}
+
+// checkTarget refuses layouts whose code TinyGo can't build when targeting it:
+// zerocopy accessors cast buffer bytes with unsafe, mirror= and blit=true rely
+// on native offsets that depend on the target's alignment, and kinds may import
+// packages relying on reflection
+func (g *Generator) checkTarget() error {
+	if g.target != TargetTinyGo {
+		return nil
+	}
+	if g.mode == "zerocopy" {
+		return fmt.Errorf("-target tinygo requires mode=copy or mode=stream, zerocopy accessors use unsafe")
+	}
+	if g.layout.Anno.Mirror != "" {
+		return fmt.Errorf("-target tinygo cannot be combined with mirror=%s, whose native offsets vary with the target's alignment", g.layout.Anno.Mirror)
+	}
+	if g.layout.Anno.Blit {
+		return fmt.Errorf("-target tinygo cannot be combined with blit=true, whose native offsets vary with the target's alignment")
+	}
+	for _, region := range g.analyzed.Regions {
+		k, ok := kindOf(region)
+		if !ok {
+			continue
+		}
+		for _, path := range k.ImportsOf(parser.KindField(region.Field)) {
+			if slices.Contains(tinyGoUnsupported, path) {
+				return fmt.Errorf("-target tinygo: %s: kind %s imports %s, which TinyGo doesn't fully support",
+					region.Field.Name, k.Name, path)
+			}
+		}
+	}
+	return nil
+}
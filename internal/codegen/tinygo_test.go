@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+	"github.com/alexhholmes/layout/kind"
+)
+
+func init() {
+	kind.Register(kind.Kind{
+		Name: "codegentestreflect",
+		Size: func(f kind.Field) (int, error) { return 8, nil },
+		Marshal: func(f kind.Field, dst, value string) string {
+			return fmt.Sprintf("binary.LittleEndian.PutUint64(%s, uint64(reflect.ValueOf(%s).Int()))\n", dst, value)
+		},
+		Unmarshal: func(f kind.Field, src, value string) string {
+			return fmt.Sprintf("reflect.ValueOf(&%s).Elem().SetInt(int64(binary.LittleEndian.Uint64(%s)))\n", value, src)
+		},
+		Imports: []string{"encoding/binary", "reflect"},
+	})
+}
+
+func TestCheckTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		target  string
+		mirror  string
+		kind    string
+		wantErr string
+	}{
+		{"copy", "copy", TargetTinyGo, "", "", ""},
+		{"gc zerocopy", "zerocopy", "", "", "", ""},
+		{"zerocopy", "zerocopy", TargetTinyGo, "", "", "-target tinygo requires mode=copy or mode=stream"},
+		{"mirror", "copy", TargetTinyGo, "rawRecord", "", "-target tinygo cannot be combined with mirror=rawRecord"},
+		{"reflecting kind", "copy", TargetTinyGo, "", "codegentestreflect",
+			"-target tinygo: Seq: kind codegentestreflect imports reflect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// @layout size=16 mode=M mirror=R
+			// type Record struct {
+			//     ID  uint32 `layout:"@0"`
+			//     Seq int64  `layout:"@8,K"`
+			// }
+			layout := &parser.TypeLayout{
+				Name: "Record",
+				Anno: &parser.TypeAnnotation{Size: 16, Mode: tt.mode, Mirror: tt.mirror},
+				Fields: []parser.Field{
+					{Name: "ID", GoType: "uint32", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Seq", GoType: "int64", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, Kind: tt.kind}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+
+			gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", tt.mode, 0, "")
+			gen.SetTarget(tt.target)
+			err = gen.checkTarget()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkTarget() error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkTarget() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// view is a type of views=, selected when the base field at its discriminator's
+// offset holds its magic= value
+type view struct {
+	name  string
+	field parser.Field // The base field read as the discriminator
+	magic string       // The discriminator value selecting the view
+}
+
+// resolveViews checks the types named by views= and finds their discriminators.
+// Each must be a slice-backed zerocopy type (align= or allocator=), the only kind
+// whose SetBuffer aliases a buffer, of the same size, with a magic= field lying
+// exactly over a fixed field of this type. No two may share a discriminator value.
+func (g *Generator) resolveViews() ([]view, error) {
+	names := g.layout.Anno.Views
+	if len(names) > 0 && g.mode != "zerocopy" {
+		return nil, fmt.Errorf("views= requires mode=zerocopy, copies share no buffer")
+	}
+
+	var views []view
+	seen := make(map[string]string)
+	for _, name := range names {
+		var layout *parser.TypeLayout
+		for _, l := range g.allLayouts {
+			if l.Name == name {
+				layout = l
+			}
+		}
+		if layout == nil {
+			return nil, fmt.Errorf("views=%s: no @layout type %s in this file", name, name)
+		}
+		if layout.Anno.Mode != "zerocopy" || (layout.Anno.Align == 0 && layout.Anno.Allocator == "") {
+			return nil, fmt.Errorf("views=%s: %s must be mode=zerocopy with align= or allocator= to share the buffer", name, name)
+		}
+		if layout.Anno.Size != 0 && layout.Anno.Size != g.analyzed.BufferSize {
+			return nil, fmt.Errorf("views=%s: %s is %d bytes, want %d", name, name, layout.Anno.Size, g.analyzed.BufferSize)
+		}
+
+		v, ok := g.viewDiscriminator(layout)
+		if !ok {
+			return nil, fmt.Errorf("views=%s: %s needs a magic= field at the offset and size of a fixed field of %s", name, name, g.analyzed.TypeName)
+		}
+		key := v.field.Name + "=" + v.magic
+		if other, dup := seen[key]; dup {
+			return nil, fmt.Errorf("views=%s: %s and %s are both selected by %s", name, other, name, key)
+		}
+		seen[key] = name
+		views = append(views, v)
+	}
+	return views, nil
+}
+
+// viewDiscriminator finds the first magic= field of layout lying exactly over a
+// plain fixed field of this type
+func (g *Generator) viewDiscriminator(layout *parser.TypeLayout) (view, bool) {
+	for _, field := range layout.Fields {
+		if field.Layout == nil || !field.Layout.Magic || field.Layout.Bits > 0 {
+			continue
+		}
+		size, err := g.registry.SizeOf(field.GoType)
+		if err != nil {
+			continue
+		}
+		for _, region := range g.analyzed.Regions {
+			if region.Kind != analyzer.FixedRegion || region.Bits > 0 || isReserved(region) {
+				continue
+			}
+			if _, ok := kindOf(region); ok {
+				continue
+			}
+			if region.Start == field.Layout.Offset && region.Boundary-region.Start == size {
+				return view{
+					name:  layout.Name,
+					field: region.Field,
+					magic: field.Layout.Default,
+				}, true
+			}
+		}
+	}
+	return view{}, false
+}
+
+// generateViews generates As<View> for each type of views=, which checks the
+// discriminator and returns the view over p's own buffer, so a buffer pool can
+// hold raw pages and hand out the typed view each one holds
+func (g *Generator) generateViews() (string, error) {
+	views, err := g.resolveViews()
+	if err != nil {
+		return "", err
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+	for _, v := range views {
+		disc := v.field.Name
+		code.WriteString("\n")
+		code.WriteString(fmt.Sprintf("// As%s returns p's buffer viewed as a %s, if %s is %s. The view shares\n", v.name, v.name, disc, v.magic))
+		code.WriteString("// the buffer: writes through either are seen by both.\n")
+		code.WriteString(fmt.Sprintf("func (p *%s) As%s() (*%s, error) {\n", typeName, v.name, v.name))
+		code.WriteString(fmt.Sprintf("\tif got := p.Get%s(); got != %s {\n", upperFirst(disc), v.magic))
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"As%s: %s is %%v, want %s: %%w\", got, ErrBadMagic)\n", v.name, disc, v.magic))
+		code.WriteString("\t}\n")
+		code.WriteString(fmt.Sprintf("\tview := &%s{}\n", v.name))
+		code.WriteString("\tif err := view.SetBuffer(p.buf[:]); err != nil {\n")
+		code.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"As%s: %%w\", err)\n", v.name))
+		code.WriteString("\t}\n")
+		code.WriteString("\treturn view, nil\n")
+		code.WriteString("}\n")
+	}
+	return code.String(), nil
+}
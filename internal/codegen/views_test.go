@@ -0,0 +1,101 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// viewLayouts returns a base page with views=LeafPage,BranchPage and the two view
+// types, discriminated by the byte at offset 0
+func viewLayouts() []*parser.TypeLayout {
+	// @layout size=64 mode=zerocopy align=8 views=LeafPage,BranchPage
+	// type Page struct {
+	//     Type uint8 `layout:"@0"`
+	// }
+	page := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy", Align: 8, Views: []string{"LeafPage", "BranchPage"}},
+		Fields: []parser.Field{
+			{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+		},
+	}
+	views := []*parser.TypeLayout{page}
+	for name, magic := range map[string]string{"LeafPage": "1", "BranchPage": "0x02"} {
+		// @layout size=64 mode=zerocopy align=8
+		// type LeafPage struct {
+		//     Type uint8 `layout:"@0,magic=1"`
+		// }
+		views = append(views, &parser.TypeLayout{
+			Name: name,
+			Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy", Align: 8},
+			Fields: []parser.Field{
+				{Name: "Type", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed, Default: magic, Magic: true}},
+			},
+		})
+	}
+	return views
+}
+
+func generateViewsCode(t *testing.T, all []*parser.TypeLayout, mode string) (string, error) {
+	t.Helper()
+	reg := analyzer.NewTypeRegistry()
+	analyzed, err := analyzer.Analyze(all[0], reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, all[0], all, reg, "little", mode, all[0].Anno.Align, "")
+	return gen.Generate()
+}
+
+func TestGenerateViews(t *testing.T) {
+	code, err := generateViewsCode(t, viewLayouts(), "zerocopy")
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	expectedParts := []string{
+		"// AsLeafPage returns p's buffer viewed as a LeafPage, if Type is 1. The view shares\n",
+		"func (p *Page) AsLeafPage() (*LeafPage, error) {\n\tif got := p.GetType(); got != 1 {\n",
+		"return nil, fmt.Errorf(\"AsLeafPage: Type is %v, want 1: %w\", got, ErrBadMagic)",
+		"\tview := &LeafPage{}\n\tif err := view.SetBuffer(p.buf[:]); err != nil {\n\t\treturn nil, fmt.Errorf(\"AsLeafPage: %w\", err)\n\t}\n\treturn view, nil\n",
+		"func (p *Page) AsBranchPage() (*BranchPage, error) {\n\tif got := p.GetType(); got != 0x02 {\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateViewsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		modify  func(all []*parser.TypeLayout)
+		wantErr string
+	}{
+		{"copy mode", "copy", func(all []*parser.TypeLayout) { all[0].Anno.Mode = "copy" }, "views= requires mode=zerocopy"},
+		{"unknown type", "zerocopy", func(all []*parser.TypeLayout) { all[0].Anno.Views = []string{"RootPage"} }, "no @layout type RootPage"},
+		{"array-backed view", "zerocopy", func(all []*parser.TypeLayout) { all[1].Anno.Align = 0 }, "must be mode=zerocopy with align= or allocator="},
+		{"size", "zerocopy", func(all []*parser.TypeLayout) { all[1].Anno.Size = 128 }, "is 128 bytes, want 64"},
+		{"no magic", "zerocopy", func(all []*parser.TypeLayout) { all[1].Fields[0].Layout.Magic = false }, "needs a magic= field"},
+		{"magic elsewhere", "zerocopy", func(all []*parser.TypeLayout) { all[1].Fields[0].Layout.Offset = 1 }, "needs a magic= field"},
+		{"shared value", "zerocopy", func(all []*parser.TypeLayout) {
+			for _, l := range all[1:] {
+				l.Fields[0].Layout.Default = "1"
+			}
+		}, "are both selected by Type=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := viewLayouts()
+			tt.modify(all)
+			if _, err := generateViewsCode(t, all, tt.mode); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
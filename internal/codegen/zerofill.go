@@ -0,0 +1,84 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// unevenlyUsed reports whether the bytes a region uses aren't the len(slice)
+// elements next to its start: it holds the data of indirect slices, wherever
+// their offsets point, shares a region= chain, whose fields start where the
+// previous one ends, or is encoded or terminated other than element by element
+func (g *Generator) unevenlyUsed(region analyzer.Region) bool {
+	if layout := region.Field.Layout; layout.Group != "" || layout.Encode != "" || layout.Sentinel || g.isFramed(region) {
+		return true
+	}
+	for _, f := range g.layout.Fields {
+		if f.Layout.From != "" && f.Layout.Region == region.Field.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// generateZeroFill generates the end of a zerocopy MarshalLayout under
+// zerofill=true, which clears the bytes of each dynamic region its slice doesn't
+// use, so a reused buffer writes no stale elements. The space a start-end and an
+// end-start region share is cleared between their ends, once. Regions used
+// unevenly, and those sharing space with one, are left as they are.
+// Copy and stream mode encode into a fresh buffer, zero already.
+func (g *Generator) generateZeroFill() string {
+	if !g.layout.Anno.ZeroFill || g.mode != "zerocopy" {
+		return ""
+	}
+
+	var code strings.Builder
+	for _, region := range g.analyzed.Regions {
+		if region.Kind != analyzer.DynamicRegion || g.unevenlyUsed(region) {
+			continue
+		}
+		spanLow, spanHigh := g.regionSpan(region)
+		used := regionBytes(region)
+
+		low, highs := fmt.Sprintf("%d+%s", region.Start, used), []string{strconv.Itoa(spanHigh)}
+		if region.Direction == parser.EndStart {
+			low, highs = strconv.Itoa(spanLow), []string{fmt.Sprintf("%d-%s", region.Start, used)}
+		}
+
+		skip := false
+		for _, other := range g.analyzed.Regions {
+			if other.Kind != analyzer.DynamicRegion || other.Field.Name == region.Field.Name {
+				continue
+			}
+			otherLow, otherHigh := g.regionSpan(other)
+			if otherHigh <= spanLow || spanHigh <= otherLow {
+				continue
+			}
+			if g.unevenlyUsed(other) || region.Direction == parser.EndStart {
+				skip = true // Cleared up to by the start-end region, or not at all
+				break
+			}
+			highs = append(highs, fmt.Sprintf("%d-%s", other.Start, regionBytes(other)))
+		}
+		if skip {
+			continue
+		}
+
+		high := highs[0]
+		if len(highs) > 1 {
+			high = fmt.Sprintf("min(%s)", strings.Join(highs, ", "))
+		}
+		code.WriteString(fmt.Sprintf("\t// zerofill=true: clear the bytes %s doesn't use\n", region.Field.Name))
+		code.WriteString(fmt.Sprintf("\tif low, high := %s, %s; low < high {\n", low, high))
+		code.WriteString("\t\tclear(p.buf[low:high])\n")
+		code.WriteString("\t}\n")
+	}
+	if code.Len() > 0 {
+		code.WriteString("\n")
+	}
+	return code.String()
+}
@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateZeroFill(t *testing.T) {
+	// @layout size=128 mode=zerocopy zerofill=true
+	// type Page struct {
+	//     buf   [128]byte
+	//     N     uint8  `layout:"@0"`
+	//     M     uint8  `layout:"@1"`
+	//     Body  []byte `layout:"@8,start-end,count=N"`  // Up to 32, a region of its own
+	//     Elems []Elem `layout:"@32,start-end,count=N"` // Elem is 8 bytes
+	//     Data  []byte `layout:"end-start,count=M"`     // Shares [32, 128) with Elems
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Page",
+		Anno: &parser.TypeAnnotation{Size: 128, Mode: "zerocopy", ZeroFill: true},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "M", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 1, Direction: parser.Fixed}},
+			{Name: "Body", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Elems", GoType: "[]Elem", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 32, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Direction: parser.EndStart, CountField: "M"}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Elem", 8)
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	gen := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", 0, "")
+	code, err := gen.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Each forward region clears past its elements up to its boundary, or up to
+	// the elements of the backward region sharing its space, which clears nothing
+	// itself; all after the regions are written, before MarshalLayout returns
+	expectedParts := []string{
+		"\t// zerofill=true: clear the bytes Body doesn't use\n" +
+			"\tif low, high := 8+len(p.Body), 32; low < high {\n" +
+			"\t\tclear(p.buf[low:high])\n\t}\n",
+		"\t// zerofill=true: clear the bytes Elems doesn't use\n" +
+			"\tif low, high := 32+len(p.Elems)*8, min(128, 128-len(p.Data)); low < high {\n" +
+			"\t\tclear(p.buf[low:high])\n\t}\n\n\treturn p.buf[:], nil\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "bytes Data doesn't use") {
+		t.Errorf("Data shares its space with Elems, which clears it, but clears it too:\n%s", code)
+	}
+
+	// Copy mode marshals into a fresh buffer, which is zero already
+	copyLayout := *layout
+	copyLayout.Anno = &parser.TypeAnnotation{Size: 128, ZeroFill: true}
+	copyGen := NewGenerator(analyzed, &copyLayout, []*parser.TypeLayout{&copyLayout}, reg, "little", "copy", 0, "")
+	if code := copyGen.generateZeroFill(); code != "" {
+		t.Errorf("generateZeroFill() in copy mode = %q, want nothing", code)
+	}
+
+	// Without zerofill=true stale bytes are left as they are
+	layout.Anno.ZeroFill = false
+	if code := gen.generateZeroFill(); code != "" {
+		t.Errorf("generateZeroFill() without zerofill=true = %q, want nothing", code)
+	}
+}
@@ -0,0 +1,75 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// generateZeroize generates Zeroize for zeroize=true layouts, overwriting every
+// byte a value holds so secrets don't linger in pooled values and buffers: the
+// zerocopy buffer, the backing arrays of slices up to their capacity, where
+// earlier, longer contents remain, and the layouts nested in it, by their own
+// Zeroize. Data regions of indirect slices may alias the buffer UnmarshalLayout
+// was given, so only the bytes they hold are wiped there; the indirect slices
+// themselves hold nothing but views of them.
+func (g *Generator) generateZeroize() string {
+	if !g.layout.Anno.Zeroize {
+		return ""
+	}
+
+	var code strings.Builder
+	typeName := g.analyzed.TypeName
+
+	code.WriteString("// Zeroize overwrites the bytes p holds with zeros: its buffer, the backing arrays\n")
+	code.WriteString("// of its slices up to their capacity and the layouts nested in it. p is left\n")
+	code.WriteString("// empty, keeping its buffer, for values holding keys or credentials.\n")
+	code.WriteString(fmt.Sprintf("func (p *%s) Zeroize() {\n", typeName))
+
+	dataRegions := make(map[string]bool)
+	for _, field := range g.layout.Fields {
+		if field.Layout != nil && field.Layout.From != "" {
+			dataRegions[field.Layout.Region] = true
+		}
+	}
+
+	for _, field := range g.layout.Fields {
+		if field.Name == "_" || field.Layout == nil || field.Layout.From != "" {
+			continue
+		}
+		name := field.Name
+		_, nested := g.registry.NestedLayout(field.GoType)
+		isSlice := strings.HasPrefix(field.GoType, "[]")
+		switch {
+		case nested && isSlice:
+			code.WriteString(fmt.Sprintf("\tp.%s = p.%s[:cap(p.%s)]\n", name, name, name))
+			fallthrough
+		case nested && strings.HasPrefix(field.GoType, "["):
+			code.WriteString(fmt.Sprintf("\tfor i := range p.%s {\n", name))
+			code.WriteString(fmt.Sprintf("\t\tp.%s[i].Zeroize()\n", name))
+			code.WriteString("\t}\n")
+		case nested:
+			code.WriteString(fmt.Sprintf("\tp.%s.Zeroize()\n", name))
+		case dataRegions[name]:
+			code.WriteString(fmt.Sprintf("\tclear(p.%s)\n", name))
+		case isSlice:
+			code.WriteString(fmt.Sprintf("\tclear(p.%s[:cap(p.%s)])\n", name, name))
+		}
+	}
+
+	// Zeroing the struct overwrites fixed fields and an embedded buffer in place
+	switch {
+	case g.mode != "zerocopy":
+		code.WriteString(fmt.Sprintf("\t*p = %s{}\n", typeName))
+	case g.align > 0 && g.allocator == "":
+		code.WriteString("\tclear(p.backing)\n")
+		code.WriteString(fmt.Sprintf("\t*p = %s{backing: p.backing, buf: p.buf}\n", typeName))
+	case g.align > 0 || g.allocator != "":
+		code.WriteString("\tclear(p.buf)\n")
+		code.WriteString(fmt.Sprintf("\t*p = %s{buf: p.buf}\n", typeName))
+	default:
+		code.WriteString(fmt.Sprintf("\t*p = %s{}\n", typeName))
+	}
+	code.WriteString("}\n")
+
+	return code.String()
+}
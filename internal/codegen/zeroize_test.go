@@ -0,0 +1,104 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+func TestGenerateZeroize(t *testing.T) {
+	// @layout size=128 zeroize=true
+	// type Session struct {
+	//     N       uint8    `layout:"@0"`
+	//     Key     [32]byte `layout:"@8,sensitive"`
+	//     Head    Record   `layout:"@40"`
+	//     Records []Record `layout:"@56,start-end,count=N"` // Record is 16 bytes, zeroize=true
+	//     Tags    []byte   `layout:"end-start,sensitive"`
+	// }
+	layout := &parser.TypeLayout{
+		Name: "Session",
+		Anno: &parser.TypeAnnotation{Size: 128, Zeroize: true},
+		Fields: []parser.Field{
+			{Name: "N", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+			{Name: "Key", GoType: "[32]byte", Layout: &parser.FieldLayout{Offset: 8, Direction: parser.Fixed, Sensitive: true}},
+			{Name: "Head", GoType: "Record", Layout: &parser.FieldLayout{Offset: 40, Direction: parser.Fixed}},
+			{Name: "Records", GoType: "[]Record", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: 56, Direction: parser.StartEnd, CountField: "N"}},
+			{Name: "Tags", GoType: "[]byte", Layout: &parser.FieldLayout{
+				Offset: -1, StartAt: -1, Direction: parser.EndStart, Sensitive: true}},
+		},
+	}
+
+	reg := analyzer.NewTypeRegistry()
+	reg.Register("Record", 16)
+	reg.RegisterZeroize("Record")
+	analyzed, err := analyzer.Analyze(layout, reg)
+	if err != nil {
+		t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+	}
+	code, err := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").Generate()
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	// Nested layouts wipe their own slices, elements past the length included;
+	// byte slices are wiped up to their capacity before the struct is zeroed
+	expected := "func (p *Session) Zeroize() {\n" +
+		"\tp.Head.Zeroize()\n" +
+		"\tp.Records = p.Records[:cap(p.Records)]\n" +
+		"\tfor i := range p.Records {\n\t\tp.Records[i].Zeroize()\n\t}\n" +
+		"\tclear(p.Tags[:cap(p.Tags)])\n" +
+		"\t*p = Session{}\n}\n"
+	if !strings.Contains(code, expected) {
+		t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+	}
+
+	// Without zeroize=true there is no Zeroize
+	layout.Anno.Zeroize = false
+	if code := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "copy", 0, "").generateZeroize(); code != "" {
+		t.Errorf("generateZeroize() without zeroize=true = %q, want nothing", code)
+	}
+}
+
+func TestGenerateZeroizeBuffer(t *testing.T) {
+	// @layout size=64 mode=zerocopy zeroize=true [align=512 | allocator=AllocatePage]
+	// type Secret struct {
+	//     buf  [64]byte | backing, buf []byte
+	//     Len  uint8  `layout:"@0"`
+	//     Data []byte `layout:"@8,start-end,count=Len"`
+	// }
+	tests := []struct {
+		name      string
+		align     int
+		allocator string
+		want      string
+	}{
+		{"embedded", 0, "", "\tclear(p.Data[:cap(p.Data)])\n\t*p = Secret{}\n"},
+		{"aligned", 512, "", "\tclear(p.backing)\n\t*p = Secret{backing: p.backing, buf: p.buf}\n"},
+		{"allocator", 512, "AllocatePage", "\tclear(p.buf)\n\t*p = Secret{buf: p.buf}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := &parser.TypeLayout{
+				Name: "Secret",
+				Anno: &parser.TypeAnnotation{Size: 64, Mode: "zerocopy", Zeroize: true, Align: tt.align, Allocator: tt.allocator},
+				Fields: []parser.Field{
+					{Name: "Len", GoType: "uint8", Layout: &parser.FieldLayout{Offset: 0, Direction: parser.Fixed}},
+					{Name: "Data", GoType: "[]byte", Layout: &parser.FieldLayout{
+						Offset: -1, StartAt: 8, Direction: parser.StartEnd, CountField: "Len"}},
+				},
+			}
+			reg := analyzer.NewTypeRegistry()
+			analyzed, err := analyzer.Analyze(layout, reg)
+			if err != nil {
+				t.Fatalf("Analyze() error: %v, errors: %v", err, analyzed.Errors)
+			}
+			code := NewGenerator(analyzed, layout, []*parser.TypeLayout{layout}, reg, "little", "zerocopy", tt.align, tt.allocator).generateZeroize()
+			if !strings.Contains(code, tt.want) {
+				t.Errorf("generateZeroize() missing: %q\n\nGenerated:\n%s", tt.want, code)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"go/token"
 	"regexp"
 	"strconv"
 	"strings"
@@ -9,11 +10,44 @@ import (
 
 // TypeAnnotation holds parsed @layout annotation
 type TypeAnnotation struct {
-	Size      int    // Buffer size in bytes
-	Endian    string // "little" or "big"
-	Mode      string // "copy" or "zerocopy"
-	Align     int    // Alignment in bytes (0 = no alignment requirement)
-	Allocator string // Custom allocator function name (optional)
+	Size        int      // Buffer size in bytes
+	Endian      string   // "little", "big" or "pdp"
+	Mode        string   // "copy", "zerocopy" or "stream"
+	Align       int      // Alignment in bytes (0 = no alignment requirement)
+	Allocator   string   // Custom allocator function name (optional)
+	Slotted     bool     // Generate slot-directory management for indirect slices
+	Length      string   // Header field holding the total frame length (mode=stream)
+	BitOrder    string   // "lsb" or "msb": which end of a byte bit 0 of a bit field offset is
+	Regions     []string // Order of named start-end regions (region=), e.g. regions=body,index,blob
+	Fixtures    []string // Functions returning *Type, marshaled by golden tests besides the defaults
+	Mirror      string   // Native struct whose same-named fields must sit at the tagged offsets
+	Metrics     bool     // Report MarshalLayout/UnmarshalLayout calls to the package's LayoutMetrics
+	FloatPolicy string   // "raw" or "strict": whether NaNs are canonicalized and infinities refused
+	Views       []string // Zerocopy types sharing the buffer, selected by their magic= discriminator
+	Header      string   // Type generated for the fixed prefix, decoded without the rest
+	Raw         string   // Zerocopy type generated to own the buffer, keeping it out of the annotated type
+	ReadOnly    string   // Zerocopy type generated to expose the getters only, over a borrowed buffer
+	Canonical   bool     // Refuse buffers on unmarshal that don't re-encode to the same bytes
+	Into        bool     // Also generate MarshalLayoutInto, encoding a value into the caller's buffer
+	Batch       bool     // Also generate Marshal<Type>Batch and Unmarshal<Type>Batch, encoding many values into one buffer
+	Next        string   // Fixed field holding the ID of the page the dynamic field continues on (next=)
+	Instances   []string // Type arguments a generic layout is generated for, e.g. instances=Int64Elem,KeyElem
+	Errors      string   // "full" or "minimal": whether errors carry context formatted by fmt, or are the bare sentinels
+	NoUnsafe    bool     // unsafe=false: refuse layouts whose generated code needs package unsafe
+	BinaryRead  bool     // Refuse layouts encoding differently from binary.Read of the struct, and generate ReadBinary/WriteBinary
+	Blit        bool     // Refuse layouts encoding differently from the struct's memory, and copy it whole on hosts of the endian= byte order
+	CStruct     string   // C type, as cgo names it (struct_stat), whose member offsets a generated cgo test compares against
+	CInclude    []string // Headers declaring CStruct, e.g. cinclude=linux/if_ether.h
+	Reuse       bool     // Refuse layouts whose unmarshal allocates into a reused value, and generate Reset
+	ZeroFill    bool     // Clear the unused bytes of dynamic regions in zerocopy MarshalLayout; other modes encode into zeroed buffers
+	Zeroize     bool     // Generate Zeroize, overwriting the buffer and the backing arrays of slices for layouts holding secrets
+	Retain      bool     // Keep the buffer UnmarshalLayout decodes in the buf field, aliasing []byte fields and generating <Field>Bytes
+	Kind        string   // Value of KindField that DecodePage routes to this layout, e.g. kind=3
+	KindField   string   // Fixed field holding the kind= value, at the same offset in every kind= layout of the file
+
+	// Most allocations a call may make, checked by generated tests; nil for no test
+	MarshalAllocs   *int // marshalallocs=N: MarshalLayout of each golden case
+	UnmarshalAllocs *int // unmarshalallocs=N: UnmarshalLayout of each golden case's encoding
 }
 
 // ParseAnnotation parses @layout annotation from comment text
@@ -36,9 +70,12 @@ func ParseAnnotation(comment string) (*TypeAnnotation, error) {
 	// If no params, return default annotation with size=0 (calculate from fields)
 	if len(matches) < 2 || matches[1] == "" {
 		return &TypeAnnotation{
-			Endian: "little",
-			Mode:   "copy",
-			Size:   0,
+			Endian:      "little",
+			Mode:        "copy",
+			Size:        0,
+			BitOrder:    "lsb",
+			FloatPolicy: "raw",
+			Errors:      "full",
 		}, nil
 	}
 
@@ -48,14 +85,17 @@ func ParseAnnotation(comment string) (*TypeAnnotation, error) {
 
 func parseLayoutParams(params string) (*TypeAnnotation, error) {
 	anno := &TypeAnnotation{
-		Endian: "little", // Default
-		Mode:   "copy",   // Default
-		Size:   0,        // 0 means calculate from fields
+		Endian:      "little", // Default
+		Mode:        "copy",   // Default
+		Size:        0,        // 0 means calculate from fields
+		BitOrder:    "lsb",    // Default
+		FloatPolicy: "raw",    // Default
+		Errors:      "full",   // Default
 	}
 
 	// Extract key=value pairs: "size=4096 endian=big"
-	// Allow negative numbers in values
-	pairRe := regexp.MustCompile(`(\w+)=([\w-]+)`)
+	// Allow negative numbers, comma-separated lists and header paths in values
+	pairRe := regexp.MustCompile(`(\w+)=([\w,./-]+)`)
 	pairs := pairRe.FindAllStringSubmatch(params, -1)
 
 	// Allow @layout with no parameters (size will be calculated)
@@ -79,14 +119,14 @@ func parseLayoutParams(params string) (*TypeAnnotation, error) {
 			anno.Size = size
 
 		case "endian":
-			if value != "little" && value != "big" {
-				return nil, fmt.Errorf("endian must be 'little' or 'big', got: %s", value)
+			if value != "little" && value != "big" && value != "pdp" {
+				return nil, fmt.Errorf("endian must be 'little', 'big' or 'pdp', got: %s", value)
 			}
 			anno.Endian = value
 
 		case "mode":
-			if value != "copy" && value != "zerocopy" {
-				return nil, fmt.Errorf("mode must be 'copy' or 'zerocopy', got: %s", value)
+			if value != "copy" && value != "zerocopy" && value != "stream" {
+				return nil, fmt.Errorf("mode must be 'copy', 'zerocopy' or 'stream', got: %s", value)
 			}
 			anno.Mode = value
 
@@ -103,6 +143,194 @@ func parseLayoutParams(params string) (*TypeAnnotation, error) {
 		case "allocator":
 			anno.Allocator = value
 
+		case "slotted":
+			slotted, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("slotted must be 'true' or 'false', got: %s", value)
+			}
+			anno.Slotted = slotted
+
+		case "metrics":
+			metrics, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("metrics must be 'true' or 'false', got: %s", value)
+			}
+			anno.Metrics = metrics
+
+		case "canonical":
+			canonical, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("canonical must be 'true' or 'false', got: %s", value)
+			}
+			anno.Canonical = canonical
+
+		case "binaryread":
+			binaryRead, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("binaryread must be 'true' or 'false', got: %s", value)
+			}
+			anno.BinaryRead = binaryRead
+
+		case "blit":
+			blit, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("blit must be 'true' or 'false', got: %s", value)
+			}
+			anno.Blit = blit
+
+		case "marshalallocs", "unmarshalallocs":
+			budget, err := strconv.Atoi(value)
+			if err != nil || budget < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative allocation count, got: %s", key, value)
+			}
+			if key == "marshalallocs" {
+				anno.MarshalAllocs = &budget
+			} else {
+				anno.UnmarshalAllocs = &budget
+			}
+
+		case "zeroize":
+			zeroize, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("zeroize must be 'true' or 'false', got: %s", value)
+			}
+			anno.Zeroize = zeroize
+
+		case "retain":
+			retain, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("retain must be 'true' or 'false', got: %s", value)
+			}
+			anno.Retain = retain
+
+		case "zerofill":
+			zeroFill, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("zerofill must be 'true' or 'false', got: %s", value)
+			}
+			anno.ZeroFill = zeroFill
+
+		case "reuse":
+			reuse, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("reuse must be 'true' or 'false', got: %s", value)
+			}
+			anno.Reuse = reuse
+
+		case "into":
+			into, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("into must be 'true' or 'false', got: %s", value)
+			}
+			anno.Into = into
+
+		case "batch":
+			batch, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("batch must be 'true' or 'false', got: %s", value)
+			}
+			anno.Batch = batch
+
+		case "length":
+			anno.Length = value
+
+		case "next":
+			anno.Next = value
+
+		case "kind":
+			if _, err := strconv.ParseInt(value, 0, 64); err != nil {
+				if _, err := strconv.ParseUint(value, 0, 64); err != nil {
+					return nil, fmt.Errorf("kind must be an integer, got: %s", value)
+				}
+			}
+			anno.Kind = value
+
+		case "kindfield":
+			anno.KindField = value
+
+		case "bitorder":
+			if value != "lsb" && value != "msb" {
+				return nil, fmt.Errorf("bitorder must be 'lsb' or 'msb', got: %s", value)
+			}
+			anno.BitOrder = value
+
+		case "floatpolicy":
+			if value != "raw" && value != "strict" {
+				return nil, fmt.Errorf("floatpolicy must be 'raw' or 'strict', got: %s", value)
+			}
+			anno.FloatPolicy = value
+
+		case "errors":
+			if value != "full" && value != "minimal" {
+				return nil, fmt.Errorf("errors must be 'full' or 'minimal', got: %s", value)
+			}
+			anno.Errors = value
+
+		case "unsafe":
+			allowed, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("unsafe must be 'true' or 'false', got: %s", value)
+			}
+			anno.NoUnsafe = !allowed
+
+		case "header":
+			anno.Header = value
+
+		case "raw":
+			anno.Raw = value
+
+		case "readonly":
+			anno.ReadOnly = value
+
+		case "views":
+			anno.Views = strings.Split(value, ",")
+			for _, name := range anno.Views {
+				if name == "" {
+					return nil, fmt.Errorf("views must be a comma-separated list of types, got: %s", value)
+				}
+			}
+
+		case "instances":
+			anno.Instances = strings.Split(value, ",")
+			for _, name := range anno.Instances {
+				if name == "" {
+					return nil, fmt.Errorf("instances must be a comma-separated list of types, got: %s", value)
+				}
+			}
+
+		case "regions":
+			anno.Regions = strings.Split(value, ",")
+			for _, name := range anno.Regions {
+				if name == "" {
+					return nil, fmt.Errorf("regions must be a comma-separated list of names, got: %s", value)
+				}
+			}
+
+		case "mirror":
+			anno.Mirror = value
+
+		case "cstruct":
+			if !token.IsIdentifier(value) {
+				return nil, fmt.Errorf("cstruct must be a C type as cgo names it, e.g. struct_stat, got: %q", value)
+			}
+			anno.CStruct = value
+
+		case "cinclude":
+			anno.CInclude = strings.Split(value, ",")
+			for _, header := range anno.CInclude {
+				if header == "" {
+					return nil, fmt.Errorf("cinclude must be a comma-separated list of headers, got: %s", value)
+				}
+			}
+
+		case "fixtures":
+			anno.Fixtures = strings.Split(value, ",")
+			for _, name := range anno.Fixtures {
+				if name == "" {
+					return nil, fmt.Errorf("fixtures must be a comma-separated list of functions, got: %s", value)
+				}
+			}
+
 		default:
 			return nil, fmt.Errorf("unknown parameter: %s", key)
 		}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -19,6 +21,7 @@ func TestParseAnnotation(t *testing.T) {
 		{"@layout endian=big size=4096", 4096, "big", false}, // Order doesn't matter
 		{"@layout", 0, "little", false},                      // no params, size will be calculated
 		{"@layout endian=big", 0, "big", false},              // size optional, will be calculated
+		{"@layout size=64 endian=pdp", 64, "pdp", false},
 
 		// Error cases
 		{"", 0, "", true},                                     // no annotation
@@ -142,4 +145,425 @@ func TestFindAnnotation(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestParseAnnotationSlotted(t *testing.T) {
+	tests := []struct {
+		comment     string
+		wantSlotted bool
+		wantErr     bool
+	}{
+		{"@layout size=4096 mode=zerocopy slotted=true", true, false},
+		{"@layout size=4096 mode=zerocopy slotted=false", false, false},
+		{"@layout size=4096 mode=zerocopy", false, false},
+		{"@layout size=4096 slotted=yes", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			got, err := ParseAnnotation(tt.comment)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAnnotation(%q) expected error, got nil", tt.comment)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseAnnotation(%q) unexpected error: %v", tt.comment, err)
+			}
+
+			if got.Slotted != tt.wantSlotted {
+				t.Errorf("ParseAnnotation(%q).Slotted = %v, want %v", tt.comment, got.Slotted, tt.wantSlotted)
+			}
+		})
+	}
+}
+
+func TestParseAnnotationStream(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=1500 mode=stream length=Len")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.Mode != "stream" {
+		t.Errorf("Mode = %q, want %q", got.Mode, "stream")
+	}
+	if got.Length != "Len" {
+		t.Errorf("Length = %q, want %q", got.Length, "Len")
+	}
+}
+
+func TestParseAnnotationBitOrder(t *testing.T) {
+	tests := []struct {
+		comment      string
+		wantBitOrder string
+		wantErr      bool
+	}{
+		{"@layout size=20", "lsb", false},
+		{"@layout", "lsb", false},
+		{"@layout size=20 bitorder=msb", "msb", false},
+		{"@layout size=20 bitorder=lsb", "lsb", false},
+		{"@layout size=20 bitorder=big", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			got, err := ParseAnnotation(tt.comment)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAnnotation(%q) expected error, got nil", tt.comment)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseAnnotation(%q) unexpected error: %v", tt.comment, err)
+			}
+
+			if got.BitOrder != tt.wantBitOrder {
+				t.Errorf("ParseAnnotation(%q).BitOrder = %q, want %q", tt.comment, got.BitOrder, tt.wantBitOrder)
+			}
+		})
+	}
+}
+
+func TestParseAnnotationRegions(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 regions=body,index,blob")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if fmt.Sprint(got.Regions) != "[body index blob]" {
+		t.Errorf("Regions = %v, want [body index blob]", got.Regions)
+	}
+
+	if _, err := ParseAnnotation("@layout size=4096 regions=body,,blob"); err == nil {
+		t.Errorf("ParseAnnotation() expected error for empty region name")
+	}
+}
+
+func TestParseAnnotationFixtures(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 fixtures=fullPage,emptyPage")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if fmt.Sprint(got.Fixtures) != "[fullPage emptyPage]" {
+		t.Errorf("Fixtures = %v, want [fullPage emptyPage]", got.Fixtures)
+	}
+
+	if _, err := ParseAnnotation("@layout size=4096 fixtures=fullPage,"); err == nil {
+		t.Errorf("ParseAnnotation() expected error for empty fixture name")
+	}
+}
+
+func TestParseAnnotationMirror(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 mirror=RawPage")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.Mirror != "RawPage" {
+		t.Errorf("Mirror = %q, want RawPage", got.Mirror)
+	}
+}
+
+func TestParseAnnotationCStruct(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=64 cstruct=struct_ethhdr cinclude=linux/if_ether.h,dev.h")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.CStruct != "struct_ethhdr" {
+		t.Errorf("CStruct = %q, want struct_ethhdr", got.CStruct)
+	}
+	if want := []string{"linux/if_ether.h", "dev.h"}; !reflect.DeepEqual(got.CInclude, want) {
+		t.Errorf("CInclude = %v, want %v", got.CInclude, want)
+	}
+
+	for _, comment := range []string{"@layout size=64 cstruct=struct.x", "@layout size=64 cinclude=a.h,,b.h"} {
+		if _, err := ParseAnnotation(comment); err == nil {
+			t.Errorf("ParseAnnotation(%q) expected error, got nil", comment)
+		}
+	}
+}
+
+func TestParseAnnotationMetrics(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 metrics=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Metrics {
+		t.Errorf("Metrics = false, want true")
+	}
+
+	if _, err := ParseAnnotation("@layout size=4096 metrics=prometheus"); err == nil {
+		t.Errorf("ParseAnnotation() expected error for non-boolean metrics")
+	}
+}
+
+func TestParseAnnotationFloatPolicy(t *testing.T) {
+	for comment, want := range map[string]string{
+		"@layout":                            "raw",
+		"@layout size=64":                    "raw",
+		"@layout size=64 floatpolicy=strict": "strict",
+		"@layout size=64 floatpolicy=raw":    "raw",
+	} {
+		got, err := ParseAnnotation(comment)
+		if err != nil {
+			t.Fatalf("ParseAnnotation(%q) unexpected error: %v", comment, err)
+		}
+		if got.FloatPolicy != want {
+			t.Errorf("ParseAnnotation(%q).FloatPolicy = %q, want %q", comment, got.FloatPolicy, want)
+		}
+	}
+
+	if _, err := ParseAnnotation("@layout size=64 floatpolicy=canonical"); err == nil {
+		t.Errorf("ParseAnnotation() expected error for unknown floatpolicy")
+	}
+}
+
+func TestParseAnnotationErrors(t *testing.T) {
+	for comment, want := range map[string]string{
+		"@layout":                        "full",
+		"@layout size=64":                "full",
+		"@layout size=64 errors=minimal": "minimal",
+		"@layout size=64 errors=full":    "full",
+	} {
+		got, err := ParseAnnotation(comment)
+		if err != nil {
+			t.Fatalf("ParseAnnotation(%q) unexpected error: %v", comment, err)
+		}
+		if got.Errors != want {
+			t.Errorf("ParseAnnotation(%q).Errors = %q, want %q", comment, got.Errors, want)
+		}
+	}
+
+	got, err := ParseAnnotation("@layout size=64 errors=minimal unsafe=false")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.NoUnsafe {
+		t.Errorf("ParseAnnotation() NoUnsafe = false, want true for unsafe=false")
+	}
+
+	for _, comment := range []string{"@layout size=64 errors=none", "@layout size=64 unsafe=never"} {
+		if _, err := ParseAnnotation(comment); err == nil {
+			t.Errorf("ParseAnnotation(%q) expected error", comment)
+		}
+	}
+}
+
+func TestParseAnnotationViews(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 mode=zerocopy views=LeafPage,BranchPage")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if fmt.Sprint(got.Views) != "[LeafPage BranchPage]" {
+		t.Errorf("Views = %v, want [LeafPage BranchPage]", got.Views)
+	}
+
+	if _, err := ParseAnnotation("@layout size=4096 views=LeafPage,,BranchPage"); err == nil {
+		t.Errorf("ParseAnnotation() expected error for empty view name")
+	}
+}
+
+func TestParseAnnotationInstances(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 instances=Int64Elem,KeyElem")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if fmt.Sprint(got.Instances) != "[Int64Elem KeyElem]" {
+		t.Errorf("Instances = %v, want [Int64Elem KeyElem]", got.Instances)
+	}
+
+	if _, err := ParseAnnotation("@layout size=4096 instances=Int64Elem,"); err == nil {
+		t.Errorf("ParseAnnotation() expected error for empty type argument")
+	}
+}
+
+func TestParseAnnotationCanonical(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=64 canonical=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Canonical {
+		t.Error("Canonical = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=64 canonical=yes"); err == nil {
+		t.Error("ParseAnnotation(canonical=yes) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationInto(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=8 into=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Into {
+		t.Error("Into = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=8 into=1x"); err == nil {
+		t.Error("ParseAnnotation(into=1x) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationBatch(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=8 batch=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Batch {
+		t.Error("Batch = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=8 batch=2"); err == nil {
+		t.Error("ParseAnnotation(batch=2) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationBinaryRead(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=8 binaryread=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.BinaryRead {
+		t.Error("BinaryRead = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=8 binaryread=on"); err == nil {
+		t.Error("ParseAnnotation(binaryread=on) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationBlit(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=8 blit=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Blit {
+		t.Error("Blit = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=8 blit=yes"); err == nil {
+		t.Error("ParseAnnotation(blit=yes) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationReuse(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 reuse=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Reuse {
+		t.Error("Reuse = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=4096 reuse=yes"); err == nil {
+		t.Error("ParseAnnotation(reuse=yes) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationAllocs(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 marshalallocs=1 unmarshalallocs=0")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.MarshalAllocs == nil || *got.MarshalAllocs != 1 || got.UnmarshalAllocs == nil || *got.UnmarshalAllocs != 0 {
+		t.Errorf("MarshalAllocs = %v, UnmarshalAllocs = %v, want 1 and 0", got.MarshalAllocs, got.UnmarshalAllocs)
+	}
+
+	if got, _ := ParseAnnotation("@layout size=4096"); got.MarshalAllocs != nil || got.UnmarshalAllocs != nil {
+		t.Error("budgets set without marshalallocs= or unmarshalallocs=")
+	}
+	for _, anno := range []string{"@layout size=8 marshalallocs=-1", "@layout size=8 unmarshalallocs=x"} {
+		if _, err := ParseAnnotation(anno); err == nil {
+			t.Errorf("ParseAnnotation(%q) expected error, got nil", anno)
+		}
+	}
+}
+
+func TestParseAnnotationZeroFill(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 mode=zerocopy zerofill=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.ZeroFill {
+		t.Error("ZeroFill = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=4096 zerofill=1x"); err == nil {
+		t.Error("ParseAnnotation(zerofill=1x) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationZeroize(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 zeroize=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Zeroize {
+		t.Error("Zeroize = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=4096 zeroize=yes"); err == nil {
+		t.Error("ParseAnnotation(zeroize=yes) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationKind(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 kind=0x03 kindfield=Type")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.Kind != "0x03" || got.KindField != "Type" {
+		t.Errorf("Kind, KindField = %q, %q, want 0x03, Type", got.Kind, got.KindField)
+	}
+	if _, err := ParseAnnotation("@layout size=4096 kind=leaf"); err == nil {
+		t.Error("ParseAnnotation(kind=leaf) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationNext(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 next=NextPage")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.Next != "NextPage" {
+		t.Errorf("Next = %q, want NextPage", got.Next)
+	}
+}
+
+func TestParseAnnotationRaw(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 mode=zerocopy raw=PageRaw")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.Raw != "PageRaw" {
+		t.Errorf("Raw = %q, want PageRaw", got.Raw)
+	}
+}
+
+func TestParseAnnotationRetain(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 retain=true")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if !got.Retain {
+		t.Error("Retain = false, want true")
+	}
+	if _, err := ParseAnnotation("@layout size=4096 retain=yes"); err == nil {
+		t.Error("ParseAnnotation(retain=yes) expected error, got nil")
+	}
+}
+
+func TestParseAnnotationReadOnly(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 mode=zerocopy readonly=PageView")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.ReadOnly != "PageView" {
+		t.Errorf("ReadOnly = %q, want PageView", got.ReadOnly)
+	}
+}
+
+func TestParseAnnotationHeader(t *testing.T) {
+	got, err := ParseAnnotation("@layout size=4096 header=PageHeader")
+	if err != nil {
+		t.Fatalf("ParseAnnotation() unexpected error: %v", err)
+	}
+	if got.Header != "PageHeader" {
+		t.Errorf("Header = %q, want PageHeader", got.Header)
+	}
+}
@@ -5,15 +5,24 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
 // TypeLayout represents a parsed struct with layout annotation
 type TypeLayout struct {
-	Name   string
-	Anno   *TypeAnnotation
-	Fields []Field
+	Name     string
+	Anno     *TypeAnnotation
+	Fields   []Field
+	Untagged []string // Exported fields without a layout tag, which the encoding leaves out
+	Omitted  []string // Every field the encoding leaves out, unexported, ignored and embedded ones included
+
+	// Generic layouts (instances=) become one layout per type argument, named
+	// after both: Page[T Element] instantiated with Int64Elem is PageInt64Elem
+	Generic string // Generic type declared in the source, e.g. "Page"
+	TypeArg string // Type argument of this instance, e.g. "Int64Elem"
 }
 
 // Field represents a struct field with layout tag
@@ -31,15 +40,41 @@ func ParseFile(filename string) ([]*TypeLayout, map[string]string, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("parse error: %w", err)
 	}
+	if err := checkFieldTypes(fset, file); err != nil {
+		return nil, nil, err
+	}
 
-	types, aliases := extractTypes(file)
+	types, aliases, diagnostics := extractTypes(file, nil, nil)
+	printDiagnostics(fset, diagnostics)
 	return types, aliases, nil
 }
 
-func extractTypes(file *ast.File) ([]*TypeLayout, map[string]string) {
-	var types []*TypeLayout
+// ExtractTypes returns the @layout types and type aliases declared in file, with
+// the problems that made it skip types or fields, for tools reporting them in
+// place. knownSizes and knownAliases describe types declared elsewhere that
+// fields may reference, named as the fields do (e.g., "common.PageHeader").
+func ExtractTypes(file *ast.File, knownSizes map[string]int, knownAliases map[string]string) ([]*TypeLayout, map[string]string, []Diagnostic) {
+	return extractTypes(file, knownSizes, knownAliases)
+}
+
+// extractTypes returns the @layout types and type aliases declared in file, and
+// the problems with types and fields it skipped. knownSizes and knownAliases
+// describe types declared elsewhere (e.g., imported packages) that fields may
+// reference; either may be nil.
+func extractTypes(file *ast.File, knownSizes map[string]int, knownAliases map[string]string) ([]*TypeLayout, map[string]string, []Diagnostic) {
 	aliases := make(map[string]string)
+	var diagnostics []Diagnostic
+
+	// pendingType is an annotated struct awaiting size inference
+	type pendingType struct {
+		name       string
+		pos        token.Pos
+		structType *ast.StructType
+		layout     *TypeLayout
+	}
+	var pending []pendingType
 
+	// First pass: collect aliases and annotated structs
 	for _, decl := range file.Decls {
 		genDecl, ok := decl.(*ast.GenDecl)
 		if !ok || genDecl.Tok != token.TYPE {
@@ -64,41 +99,91 @@ func extractTypes(file *ast.File) ([]*TypeLayout, map[string]string) {
 			// Extract @layout annotation from comments directly above type
 			anno := extractAnnotation(genDecl.Doc)
 			if anno == nil {
+				if d, ok := annotationDiagnostic(typeSpec.Name.Name, genDecl.Doc); ok {
+					diagnostics = append(diagnostics, d)
+				}
 				continue // No @layout, skip this type
 			}
 
 			// Extract fields with layout tags
-			fields := extractFields(structType)
+			fields, skipped := extractFields(typeSpec.Name.Name, structType)
+			diagnostics = append(diagnostics, skipped...)
 			if len(fields) == 0 {
 				continue // No layout tags, skip
 			}
 
-			// Calculate size from fields if not specified
-			if anno.Size == 0 {
-				calculatedSize := calculateSize(fields)
-				if calculatedSize == 0 {
-					fmt.Printf("Warning: %s: cannot calculate size (no fixed fields or only dynamic fields), size must be specified\n", typeSpec.Name.Name)
+			layouts := []*TypeLayout{{Name: typeSpec.Name.Name, Anno: anno, Fields: fields,
+				Untagged: untaggedFields(structType), Omitted: omittedFields(structType)}}
+			if typeSpec.TypeParams != nil || len(anno.Instances) > 0 {
+				var err error
+				if layouts, err = instantiate(layouts[0], typeSpec.TypeParams); err != nil {
+					diagnostics = append(diagnostics, Diagnostic{Pos: typeSpec.Name.Pos(), Type: typeSpec.Name.Name,
+						Message: fmt.Sprintf("%s: %v", typeSpec.Name.Name, err)})
 					continue
 				}
-				anno.Size = calculatedSize
 			}
 
-			// Validate struct has required fields for zerocopy mode
-			if err := validateStructFields(structType, anno); err != nil {
-				// TODO: collect errors instead of skipping
-				fmt.Printf("Warning: %s: %v\n", typeSpec.Name.Name, err)
+			for _, layout := range layouts {
+				pending = append(pending, pendingType{
+					name:       layout.Name,
+					pos:        typeSpec.Name.Pos(),
+					structType: structType,
+					layout:     layout,
+				})
+			}
+		}
+	}
+
+	// Second pass: infer omitted sizes. Struct-typed fields need the size of the
+	// nested layout, which may itself be inferred, so repeat until nothing changes.
+	sizes := make(map[string]int)
+	for name, size := range knownSizes {
+		sizes[name] = size
+	}
+	allAliases := make(map[string]string)
+	for alias, underlying := range knownAliases {
+		allAliases[alias] = underlying
+	}
+	for alias, underlying := range aliases {
+		allAliases[alias] = underlying
+	}
+	for _, p := range pending {
+		if p.layout.Anno.Size > 0 {
+			sizes[p.name] = p.layout.Anno.Size
+		}
+	}
+	for progress := true; progress; {
+		progress = false
+		for _, p := range pending {
+			if p.layout.Anno.Size > 0 {
 				continue
 			}
+			if size, ok := calculateSize(p.layout.Fields, sizes, allAliases); ok && size > 0 {
+				p.layout.Anno.Size = size
+				sizes[p.name] = size
+				progress = true
+			}
+		}
+	}
 
-			types = append(types, &TypeLayout{
-				Name:   typeSpec.Name.Name,
-				Anno:   anno,
-				Fields: fields,
-			})
+	var types []*TypeLayout
+	for _, p := range pending {
+		if p.layout.Anno.Size == 0 {
+			diagnostics = append(diagnostics, Diagnostic{Pos: p.pos, Type: p.name, Message: fmt.Sprintf(
+				"%s: cannot calculate size (no fixed fields, only dynamic fields, or fields of unknown struct types), size must be specified", p.name)})
+			continue
 		}
+
+		// Validate struct has required fields for zerocopy mode
+		if err := validateStructFields(p.structType, p.layout.Anno); err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Pos: p.pos, Type: p.name, Message: fmt.Sprintf("%s: %v", p.name, err)})
+			continue
+		}
+
+		types = append(types, p.layout)
 	}
 
-	return types, aliases
+	return types, aliases, diagnostics
 }
 
 func extractAnnotation(doc *ast.CommentGroup) *TypeAnnotation {
@@ -124,9 +209,12 @@ func extractAnnotation(doc *ast.CommentGroup) *TypeAnnotation {
 
 // validateStructFields checks that struct has required fields based on annotation
 func validateStructFields(structType *ast.StructType, anno *TypeAnnotation) error {
-	if anno.Mode != "zerocopy" {
+	if anno.Mode != "zerocopy" && !anno.Retain {
 		return nil // No special requirements for copy mode
 	}
+	if anno.Raw != "" {
+		return nil // The raw= type holds the buffer
+	}
 
 	// Extract all field names and types (not just ones with layout tags)
 	fieldMap := make(map[string]string)
@@ -139,6 +227,18 @@ func validateStructFields(structType *ast.StructType, anno *TypeAnnotation) erro
 		fieldMap[fieldName] = fieldType
 	}
 
+	// Copy mode with retain=true keeps the decoded buffer
+	if anno.Mode != "zerocopy" {
+		bufType, hasBufField := fieldMap["buf"]
+		if !hasBufField {
+			return fmt.Errorf("retain=true requires field: buf []byte")
+		}
+		if bufType != "[]byte" {
+			return fmt.Errorf("buf field must be []byte with retain=true, got %s", bufType)
+		}
+		return nil
+	}
+
 	// Zerocopy with alignment or custom allocator requires buf field
 	if anno.Align > 0 || anno.Allocator != "" {
 		// When using allocator: backing is handled as local variable, only buf needed
@@ -181,8 +281,11 @@ func validateStructFields(structType *ast.StructType, anno *TypeAnnotation) erro
 	return nil
 }
 
-func extractFields(structType *ast.StructType) []Field {
+// extractFields returns the fields of typeName with layout tags, and the problems
+// with the tags of those it skipped
+func extractFields(typeName string, structType *ast.StructType) ([]Field, []Diagnostic) {
 	var fields []Field
+	var skipped []Diagnostic
 
 	for _, field := range structType.Fields.List {
 		if len(field.Names) == 0 {
@@ -196,25 +299,101 @@ func extractFields(structType *ast.StructType) []Field {
 		// Parse struct tag
 		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
 		layoutTag := tag.Get("layout")
-		if layoutTag == "" {
-			continue // No layout tag
+		if layoutTag == "" || layoutTag == IgnoreTag {
+			continue // No layout tag, or left out on purpose
 		}
 
 		// Parse layout tag
 		layout, err := ParseTag(layoutTag)
 		if err != nil {
-			// TODO: collect errors instead of skipping
+			skipped = append(skipped, Diagnostic{Pos: field.Tag.Pos(), Type: typeName, Message: fmt.Sprintf(
+				"%s.%s: invalid layout tag %q: %v; field skipped", typeName, field.Names[0].Name, layoutTag, err)})
 			continue
 		}
 
+		goType := typeToString(field.Type)
+		if field.Names[0].Name == "_" {
+			blankPadding(layout, goType)
+		}
+
 		fields = append(fields, Field{
 			Name:   field.Names[0].Name,
-			GoType: typeToString(field.Type),
+			GoType: goType,
 			Layout: layout,
 		})
 	}
 
-	return fields
+	return fields, skipped
+}
+
+// untaggedFields returns the exported fields of structType without a layout tag.
+// They don't round-trip, which is usually an oversight; layout:"ignore" marks the
+// ones left out on purpose.
+func untaggedFields(structType *ast.StructType) []string {
+	var names []string
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			if field.Tag != nil && reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("layout") != "" {
+				continue
+			}
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// omittedFields returns every field of structType without a layout: untagged,
+// layout:"ignore", unexported and blank fields, and embedded fields by type.
+// Reflection-based encoders such as encoding/binary still see them.
+func omittedFields(structType *ast.StructType) []string {
+	var names []string
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			names = append(names, typeToString(field.Type))
+			continue
+		}
+		omitted := field.Names
+		if field.Tag != nil {
+			if tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("layout"); tag != "" && tag != IgnoreTag {
+				omitted = field.Names[1:] // The tag lays out the first name only
+			}
+		}
+		for _, name := range omitted {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// checkFieldTypes returns the first field type FieldTypeDiagnostics reports as
+// an error, with its position
+func checkFieldTypes(fset *token.FileSet, file *ast.File) error {
+	if diagnostics := FieldTypeDiagnostics(file); len(diagnostics) > 0 {
+		return fmt.Errorf("%s: %s", fset.Position(diagnostics[0].Pos), diagnostics[0].Message)
+	}
+	return nil
+}
+
+// blankPadding makes a blank [N]byte field at a fixed offset, e.g.
+// `_ [12]byte `layout:"@20"``, a reserved range of N bytes: the struct has no
+// storage to encode from, so the padding is zero-filled like reserve=N
+func blankPadding(layout *FieldLayout, goType string) {
+	if layout.Direction != Fixed || layout.Reserve > 0 || layout.Kind != "" || layout.Bits > 0 {
+		return
+	}
+	rest, ok := strings.CutPrefix(goType, "[")
+	if !ok {
+		return
+	}
+	n, elem, _ := strings.Cut(rest, "]")
+	length, err := strconv.Atoi(n)
+	if err != nil || length <= 0 || (elem != "byte" && elem != "uint8") {
+		return
+	}
+	layout.Reserve = length
 }
 
 // typeToString converts AST type expression to string
@@ -237,8 +416,13 @@ func typeToString(expr ast.Expr) string {
 		// Pointer: *Node (not supported for binary layout)
 		return "*" + typeToString(t.X)
 
+	case *ast.SelectorExpr:
+		// Imported type: common.PageHeader
+		return typeToString(t.X) + "." + t.Sel.Name
+
 	default:
-		return "unknown"
+		// Maps, channels, funcs, etc., refused by the analyzer by name
+		return types.ExprString(expr)
 	}
 }
 
@@ -253,32 +437,75 @@ func exprToString(expr ast.Expr) string {
 	}
 }
 
-// calculateSize determines the minimum buffer size needed based on field offsets
-// Returns 0 if size cannot be determined (e.g., only dynamic fields)
-func calculateSize(fields []Field) int {
+// calculateSize determines the minimum buffer size needed based on field offsets.
+// Struct-typed fields are sized from sizes (layouts in the same file) and aliases
+// are resolved to their underlying types. Returns false if a fixed field's size is
+// unknown; returns 0 if there are no fixed fields (e.g., only dynamic fields).
+func calculateSize(fields []Field, sizes map[string]int, aliases map[string]string) (int, bool) {
 	maxEnd := 0
 
 	for _, field := range fields {
-		// Only consider fixed fields for size calculation
-		if field.Layout.Direction != Fixed {
+		// Only consider fixed fields for size calculation; trailers have no offset
+		if field.Layout.Direction != Fixed || field.Layout.Trailer {
 			continue
 		}
 
-		// Get field size from type
-		fieldSize := getFixedTypeSize(field.GoType)
-		if fieldSize <= 0 {
-			// Can't determine size for this type (struct or unknown)
-			// For struct types, we'd need the registry, so skip for now
+		// Reserved ranges are sized by the tag, not the pseudo-field's type
+		if field.Layout.Reserve > 0 {
+			if end := field.Layout.Offset + field.Layout.Reserve; end > maxEnd {
+				maxEnd = end
+			}
 			continue
 		}
 
+		fieldSize := fieldTypeSize(field.GoType, sizes, aliases)
+		if field.Layout.Kind != "" {
+			fieldSize, _ = KindSize(field) // 0 on error; the analyzer reports it
+		}
+		if fieldSize <= 0 {
+			return 0, false // Unknown struct type, or one whose size is still pending
+		}
+
 		endOffset := field.Layout.Offset + fieldSize
+		if field.Layout.Bits > 0 {
+			endOffset = field.Layout.Offset + (field.Layout.BitOffset+field.Layout.Bits+7)/8
+		}
 		if endOffset > maxEnd {
 			maxEnd = endOffset
 		}
 	}
 
-	return maxEnd
+	return maxEnd, true
+}
+
+// fieldTypeSize returns the size of a fixed field type, resolving aliases and
+// struct types through sizes. Returns -1 if the size is unknown.
+func fieldTypeSize(goType string, sizes map[string]int, aliases map[string]string) int {
+	for {
+		underlying, ok := aliases[goType]
+		if !ok {
+			break
+		}
+		goType = underlying
+	}
+
+	if size, ok := sizes[goType]; ok {
+		return size
+	}
+
+	// Arrays of structs: [N]Type
+	if strings.HasPrefix(goType, "[") && strings.Contains(goType, "]") {
+		parts := strings.SplitN(goType[1:], "]", 2)
+		var count int
+		if _, err := fmt.Sscanf(parts[0], "%d", &count); err == nil {
+			if elemSize := fieldTypeSize(parts[1], sizes, aliases); elemSize > 0 {
+				return count * elemSize
+			}
+		}
+		return -1
+	}
+
+	return getFixedTypeSize(goType)
 }
 
 // getFixedTypeSize returns the size in bytes for basic fixed-size types
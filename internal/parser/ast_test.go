@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"go/parser"
+	"go/token"
+	"strings"
 	"testing"
 )
 
@@ -87,4 +90,207 @@ func TestTypeToString(t *testing.T) {
 	// Note: We can't easily test this without constructing AST nodes
 	// The real test is in TestParseFile which uses actual parsed code
 	// This is tested implicitly above
-}
\ No newline at end of file
+}
+func TestExtractTypesInfersNestedSizes(t *testing.T) {
+	// Outer is declared before the nested types its size depends on
+	src := "package test\n" +
+		"type PageID uint64\n" +
+		"// @layout\n" +
+		"type Outer struct {\n" +
+		"\tFlags  uint8     `layout:\"@0\"`\n" +
+		"\tHeader Header    `layout:\"@4\"`\n" +
+		"\tIDs    [2]PageID `layout:\"@20\"`\n" +
+		"}\n" +
+		"// @layout\n" +
+		"type Header struct {\n" +
+		"\tNext PageID `layout:\"@0\"`\n" +
+		"\tMeta Meta   `layout:\"@8\"`\n" +
+		"}\n" +
+		"// @layout size=8\n" +
+		"type Meta struct {\n" +
+		"\tNumKeys uint16 `layout:\"@0\"`\n" +
+		"}\n" +
+		"// @layout\n" +
+		"type Foreign struct {\n" +
+		"\tOther Unknown `layout:\"@0\"`\n" +
+		"}\n"
+
+	file, err := parser.ParseFile(token.NewFileSet(), "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+
+	types, _, _ := extractTypes(file, nil, nil)
+
+	sizes := make(map[string]int)
+	for _, typ := range types {
+		sizes[typ.Name] = typ.Anno.Size
+	}
+
+	// Header: Meta ends at 8+8; Outer: IDs ends at 20+2*8
+	want := map[string]int{"Outer": 36, "Header": 16, "Meta": 8}
+	for name, size := range want {
+		if sizes[name] != size {
+			t.Errorf("%s size = %d, want %d", name, sizes[name], size)
+		}
+	}
+
+	// Sizes depending on types outside the file can't be inferred
+	if _, ok := sizes["Foreign"]; ok {
+		t.Error("Foreign should be skipped, its field type size is unknown")
+	}
+}
+
+func TestExtractFieldsBlankPadding(t *testing.T) {
+	src := "package test\n" +
+		"// @layout\n" +
+		"type Page struct {\n" +
+		"\tID    uint64   `layout:\"@0\"`\n" +
+		"\t_     [12]byte `layout:\"@8\"`\n" +
+		"\tFlags uint32   `layout:\"@20\"`\n" +
+		"\t_     uint32   `layout:\"@24\"`\n" +
+		"}\n"
+
+	file, err := parser.ParseFile(token.NewFileSet(), "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	types, _, _ := extractTypes(file, nil, nil)
+	if len(types) != 1 {
+		t.Fatalf("extractTypes() found %d types, want 1", len(types))
+	}
+
+	fields := types[0].Fields
+	if got := fields[1].Layout.Reserve; got != 12 {
+		t.Errorf("_ [12]byte Reserve = %d, want 12", got)
+	}
+	if got := fields[3].Layout.Reserve; got != 0 {
+		t.Errorf("_ uint32 Reserve = %d, want 0 (only byte arrays are padding)", got)
+	}
+	if got := types[0].Anno.Size; got != 28 {
+		t.Errorf("Size = %d, want 28", got)
+	}
+}
+
+func TestCheckFieldTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		wantErr string
+	}{
+		{"pointer", "Next *Node `layout:\"@0\"`", "test.go:4:7: Node.Next: unsupported field type for layout: *Node"},
+		{"map", "Next map[string]uint32 `layout:\"@0\"`", "test.go:4:7: Node.Next: unsupported field type for layout: map[string]uint32"},
+		{"chan", "Next chan uint32 `layout:\"@0\"`", "unsupported field type for layout: chan uint32"},
+		{"func", "Next func() uint32 `layout:\"@0\"`", "unsupported field type for layout: func() uint32"},
+		{"slice of pointers", "Next []*Node `layout:\"start-end\"`", "unsupported field type for layout: []*Node"},
+		{"untagged pointer", "Next *Node", ""},
+		{"slice", "Next []Node `layout:\"start-end\"`", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package test\n" +
+				"// @layout size=8\n" +
+				"type Node struct {\n" +
+				"\t" + tt.field + "\n" +
+				"}\n"
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("ParseFile() error: %v", err)
+			}
+
+			err = checkFieldTypes(fset, file)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkFieldTypes() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkFieldTypes() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractTypesUntagged(t *testing.T) {
+	src := "package test\n" +
+		"// @layout size=8\n" +
+		"type Node struct {\n" +
+		"\tID         uint64 `layout:\"@0\"`\n" +
+		"\tCache, Hot []byte\n" +
+		"\tDirty      bool   `json:\"dirty\"`\n" +
+		"\tNext       *Node  `layout:\"ignore\"`\n" +
+		"\tbuf        []byte\n" +
+		"}\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	if err := checkFieldTypes(fset, file); err != nil {
+		t.Errorf("checkFieldTypes() error: %v, want ignored fields skipped", err)
+	}
+	types, _, _ := extractTypes(file, nil, nil)
+	if len(types) != 1 {
+		t.Fatalf("extractTypes() found %d types, want 1", len(types))
+	}
+
+	if got := len(types[0].Fields); got != 1 {
+		t.Errorf("found %d fields, want ID only", got)
+	}
+	want := []string{"Cache", "Hot", "Dirty"}
+	if got := types[0].Untagged; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Untagged = %v, want %v", got, want)
+	}
+	// Reflection-based encoders see the unexported and ignored fields too
+	want = []string{"Cache", "Hot", "Dirty", "Next", "buf"}
+	if got := types[0].Omitted; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Omitted = %v, want %v", got, want)
+	}
+}
+
+func TestExtractTypesDiagnostics(t *testing.T) {
+	src := "package test\n" +
+		"// @layout size=8\n" +
+		"type Node struct {\n" +
+		"\tID   uint64 `layout:\"@0\"`\n" +
+		"\tKind uint8  `layout:\"@8,bogus=1\"`\n" +
+		"}\n" +
+		"// @layout sze=8\n" +
+		"type Bad struct {\n" +
+		"\tID uint64 `layout:\"@0\"`\n" +
+		"}\n" +
+		"// @layout\n" +
+		"type Open struct {\n" +
+		"\tData []byte `layout:\"start-end\"`\n" +
+		"}\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	types, _, diagnostics := ExtractTypes(file, nil, nil)
+	if len(types) != 1 || types[0].Name != "Node" || len(types[0].Fields) != 1 {
+		t.Fatalf("ExtractTypes() = %v, want Node with ID only", types)
+	}
+
+	want := []struct {
+		pos, typ, message string
+	}{
+		{"test.go:5:14", "Node", `Node.Kind: invalid layout tag "@8,bogus=1"`},
+		{"test.go:7:1", "Bad", "Bad: invalid @layout annotation"},
+		{"test.go:12:6", "Open", "Open: cannot calculate size"},
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("diagnostics = %v, want %d", diagnostics, len(want))
+	}
+	for i, w := range want {
+		d := diagnostics[i]
+		if pos := fset.Position(d.Pos).String(); pos != w.pos || d.Type != w.typ || !strings.Contains(d.Message, w.message) {
+			t.Errorf("diagnostics[%d] = %s %s %q, want %s %s %q", i, pos, d.Type, d.Message, w.pos, w.typ, w.message)
+		}
+	}
+}
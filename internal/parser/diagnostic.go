@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Diagnostic is a problem with an @layout type found while parsing, at the tag,
+// field or type it concerns. Parsing skips what it concerns and carries on.
+type Diagnostic struct {
+	Pos      token.Pos
+	Position token.Position // Pos in its file, set by the Loader, whose file sets callers don't see
+	Type     string         // The @layout type concerned
+	Message  string
+}
+
+// printDiagnostics prints the diagnostics of a parse as warnings, with their
+// positions, to stderr so they don't mix with what the caller prints
+func printDiagnostics(fset *token.FileSet, diagnostics []Diagnostic) {
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", fset.Position(d.Pos), d.Message)
+	}
+}
+
+// positioned returns diagnostics with their Position set from fset
+func positioned(fset *token.FileSet, diagnostics []Diagnostic) []Diagnostic {
+	for i := range diagnostics {
+		diagnostics[i].Position = fset.Position(diagnostics[i].Pos)
+	}
+	return diagnostics
+}
+
+// annotationDiagnostic reports an @layout line in the doc comment of typeName
+// that doesn't parse, which would otherwise leave the type silently unannotated
+func annotationDiagnostic(typeName string, doc *ast.CommentGroup) (Diagnostic, bool) {
+	if doc == nil {
+		return Diagnostic{}, false
+	}
+	for _, comment := range doc.List {
+		line := CleanComment(comment.Text)
+		if line != "@layout" && !strings.HasPrefix(line, "@layout ") {
+			continue
+		}
+		if _, err := ParseAnnotation(line); err != nil {
+			return Diagnostic{Pos: comment.Pos(), Type: typeName, Message: fmt.Sprintf("%s: invalid @layout annotation: %v", typeName, err)}, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+// FieldTypeDiagnostics reports the fields with a layout tag in the @layout types
+// of file whose type holds a pointer, map, channel, func or interface. Such
+// values have no bytes of their own to lay out, and would otherwise fail later
+// with errors about sizes or generated code.
+func FieldTypeDiagnostics(file *ast.File) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE || extractAnnotation(genDecl.Doc) == nil {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range structType.Fields.List {
+				if len(field.Names) == 0 || field.Tag == nil {
+					continue
+				}
+				if tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("layout"); tag == "" || tag == IgnoreTag {
+					continue
+				}
+				unsupported := false
+				ast.Inspect(field.Type, func(n ast.Node) bool {
+					switch n.(type) {
+					case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+						unsupported = true
+					}
+					return !unsupported
+				})
+				if unsupported {
+					diagnostics = append(diagnostics, Diagnostic{Pos: field.Type.Pos(), Type: typeSpec.Name.Name, Message: fmt.Sprintf(
+						"%s.%s: unsupported field type for layout: %s", typeSpec.Name.Name, field.Names[0].Name, types.ExprString(field.Type))})
+				}
+			}
+		}
+	}
+	return diagnostics
+}
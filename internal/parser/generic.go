@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// instantiate returns the layouts of a generic layout, one per type argument of
+// its instances= annotation, with the type parameter replaced in field types.
+// Each is named after the generic type and its argument (Page[T Element] with
+// Int64Elem is PageInt64Elem), the type the generated code declares for its
+// methods. Generic layouts take a single type parameter.
+func instantiate(layout *TypeLayout, params *ast.FieldList) ([]*TypeLayout, error) {
+	if params == nil {
+		return nil, fmt.Errorf("instances= requires a generic type, e.g. %s[T Element]", layout.Name)
+	}
+	var names []string
+	for _, param := range params.List {
+		for _, name := range param.Names {
+			names = append(names, name.Name)
+		}
+	}
+	if len(names) != 1 {
+		return nil, fmt.Errorf("generic layouts take one type parameter, got %d", len(names))
+	}
+	if len(layout.Anno.Instances) == 0 {
+		return nil, fmt.Errorf("generic layout needs instances= naming the type arguments to generate, e.g. instances=Int64Elem")
+	}
+
+	if anno := layout.Anno; anno.Raw != "" || anno.Header != "" || len(anno.Views) > 0 || len(anno.Fixtures) > 0 {
+		return nil, fmt.Errorf("raw=, header=, views= and fixtures= name one type or function each, not one per instance")
+	}
+
+	param := regexp.MustCompile(`\b` + regexp.QuoteMeta(names[0]) + `\b`)
+	var layouts []*TypeLayout
+	for _, arg := range layout.Anno.Instances {
+		anno := *layout.Anno
+		instance := &TypeLayout{
+			Name:     layout.Name + instanceSuffix(layout.Name, arg),
+			Anno:     &anno,
+			Untagged: layout.Untagged,
+			Omitted:  layout.Omitted,
+			Generic:  layout.Name,
+			TypeArg:  arg,
+		}
+		for _, field := range layout.Fields {
+			fieldLayout := *field.Layout
+			instance.Fields = append(instance.Fields, Field{
+				Name:   field.Name,
+				GoType: param.ReplaceAllString(field.GoType, arg),
+				Layout: &fieldLayout,
+			})
+		}
+		layouts = append(layouts, instance)
+	}
+	return layouts, nil
+}
+
+// instanceSuffix returns the type argument as it ends an instance's name,
+// capitalized for exported generic types (Page[uint64] is PageUint64)
+func instanceSuffix(generic, arg string) string {
+	if r, _ := utf8.DecodeRuneInString(generic); !unicode.IsUpper(r) {
+		return arg
+	}
+	r, size := utf8.DecodeRuneInString(arg)
+	return strings.ToUpper(string(r)) + arg[size:]
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestExtractTypesGeneric(t *testing.T) {
+	src := "package test\n" +
+		"type Element interface{ SizeLayout() int }\n" +
+		"// @layout size=8\n" +
+		"type Int64Elem struct {\n" +
+		"\tV int64 `layout:\"@0\"`\n" +
+		"}\n" +
+		"// @layout instances=Int64Elem,uint32\n" +
+		"type Page[T Element] struct {\n" +
+		"\tN     uint16 `layout:\"@0\"`\n" +
+		"\tFirst T      `layout:\"@8\"`\n" +
+		"\tItems []T    `layout:\"@16,start-end,count=N\"`\n" +
+		"}\n"
+
+	file, err := parser.ParseFile(token.NewFileSet(), "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	types, _, diagnostics := ExtractTypes(file, nil, nil)
+	if len(diagnostics) > 0 {
+		t.Fatalf("ExtractTypes() diagnostics: %v", diagnostics)
+	}
+	if len(types) != 3 {
+		t.Fatalf("ExtractTypes() found %d types, want Int64Elem and two instances", len(types))
+	}
+
+	tests := []struct {
+		name, arg, first, items string
+		size                    int
+	}{
+		{"PageInt64Elem", "Int64Elem", "Int64Elem", "[]Int64Elem", 16},
+		{"PageUint32", "uint32", "uint32", "[]uint32", 12},
+	}
+	for i, tt := range tests {
+		instance := types[i+1]
+		if instance.Name != tt.name || instance.Generic != "Page" || instance.TypeArg != tt.arg {
+			t.Errorf("types[%d] = %s (%s[%s]), want %s (Page[%s])", i+1, instance.Name, instance.Generic, instance.TypeArg, tt.name, tt.arg)
+		}
+		if got := instance.Fields[1].GoType; got != tt.first {
+			t.Errorf("%s.First type = %q, want %q", tt.name, got, tt.first)
+		}
+		if got := instance.Fields[2].GoType; got != tt.items {
+			t.Errorf("%s.Items type = %q, want %q", tt.name, got, tt.items)
+		}
+		// Each instance infers its own size
+		if instance.Anno.Size != tt.size {
+			t.Errorf("%s size = %d, want %d", tt.name, instance.Anno.Size, tt.size)
+		}
+	}
+}
+
+func TestExtractTypesGenericErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		decl string
+		want string
+	}{
+		{"no instances", "// @layout size=8\ntype Page[T any] struct {\n\tV T `layout:\"@0\"`\n}\n",
+			"Page: generic layout needs instances="},
+		{"not generic", "// @layout size=8 instances=uint64\ntype Page struct {\n\tV uint64 `layout:\"@0\"`\n}\n",
+			"Page: instances= requires a generic type"},
+		{"two parameters", "// @layout size=8 instances=uint64\ntype Page[K, V any] struct {\n\tV V `layout:\"@0\"`\n}\n",
+			"Page: generic layouts take one type parameter, got 2"},
+		{"one header type", "// @layout size=8 instances=uint64 header=PageHeader\ntype Page[T any] struct {\n\tV T `layout:\"@0\"`\n}\n",
+			"Page: raw=, header=, views= and fixtures= name one type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parser.ParseFile(token.NewFileSet(), "test.go", "package test\n"+tt.decl, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("ParseFile() error: %v", err)
+			}
+			types, _, diagnostics := ExtractTypes(file, nil, nil)
+			if len(types) != 0 {
+				t.Errorf("ExtractTypes() = %v, want none", types)
+			}
+			if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, tt.want) {
+				t.Errorf("diagnostics = %v, want %q", diagnostics, tt.want)
+			}
+		})
+	}
+}
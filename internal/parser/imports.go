@@ -0,0 +1,313 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ParsedFile is a parsed source file together with the @layout types of the
+// packages its layouts reference (e.g., a field of type common.PageHeader)
+type ParsedFile struct {
+	Layouts  []*TypeLayout     // Types declared in the file
+	Aliases  map[string]string // Type aliases, including imported ones as "common.PageID"
+	Imported []*TypeLayout     // Imported layout types, named "common.PageHeader"
+	Imports  map[string]string // Package qualifier → import path of referenced packages
+
+	// Diagnostics are the tags and annotations parsing skipped, in the file and
+	// in the packages first loaded for it
+	Diagnostics []Diagnostic
+}
+
+// ParseFileWithImports parses a Go source file like ParseFile and also loads the
+// @layout types of imported packages referenced by qualified field types. Package
+// files are located with go/packages, run from the file's directory so the
+// enclosing module's dependencies resolve.
+func ParseFileWithImports(filename string) (*ParsedFile, error) {
+	return NewLoader().ParseFile(filename)
+}
+
+// Loader parses input files with the @layout types of the packages they import,
+// loading each package once however many files of one run reference it. Packages
+// are keyed by import path rather than by the qualifier a file imports them
+// under, so every importer sees the same sizes, and the layouts of a package may
+// nest those of the packages it imports in turn.
+type Loader struct {
+	packages    map[string]*loadedPackage // Import path → its layouts and aliases, unqualified
+	diagnostics []Diagnostic              // Of packages loaded for the file being parsed
+}
+
+// loadedPackage is the @layout types and aliases of an imported package, named
+// as the package itself names them
+type loadedPackage struct {
+	layouts []*TypeLayout
+	aliases map[string]string
+	loading bool // Still resolving its own imports
+}
+
+// NewLoader returns a Loader that has loaded no packages yet
+func NewLoader() *Loader {
+	return &Loader{packages: make(map[string]*loadedPackage)}
+}
+
+// ParseFile parses filename like ParseFileWithImports, reusing the packages
+// loaded for the files parsed before it
+func (l *Loader) ParseFile(filename string) (*ParsedFile, error) {
+	l.diagnostics = nil // Left by a file that failed to load its packages
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: %w", err)
+	}
+	if err := checkFieldTypes(fset, file); err != nil {
+		return nil, err
+	}
+
+	result := &ParsedFile{
+		Aliases: make(map[string]string),
+		Imports: make(map[string]string),
+	}
+	knownSizes := make(map[string]int)
+
+	importPaths := fileImports(file)
+	for _, qualifier := range referencedQualifiers(file) {
+		importPath, ok := importPaths[qualifier]
+		if !ok {
+			continue // Not a package qualifier
+		}
+
+		pkg, err := l.loadPackage(filepath.Dir(filename), importPath)
+		if err != nil {
+			return nil, err
+		}
+		layouts, aliases := pkg.qualified(qualifier)
+
+		result.Imports[qualifier] = importPath
+		result.Imported = append(result.Imported, layouts...)
+		for _, layout := range layouts {
+			knownSizes[layout.Name] = layout.Anno.Size
+		}
+		for alias, underlying := range aliases {
+			result.Aliases[alias] = underlying
+		}
+	}
+
+	layouts, aliases, diagnostics := extractTypes(file, knownSizes, result.Aliases)
+	result.Diagnostics = append(l.diagnostics, positioned(fset, diagnostics)...)
+	l.diagnostics = nil
+	result.Layouts = layouts
+	for alias, underlying := range aliases {
+		result.Aliases[alias] = underlying
+	}
+
+	return result, nil
+}
+
+// fileImports maps package qualifiers to import paths. Unnamed imports use the
+// last path element, which matches the package name for conventional packages.
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// referencedQualifiers returns the package qualifiers used by the types of fields
+// with layout tags and by their tag parameters, in order of first use
+func referencedQualifiers(file *ast.File) []string {
+	var qualifiers []string
+	seen := make(map[string]bool)
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			structType, ok := spec.(*ast.TypeSpec).Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range structType.Fields.List {
+				if field.Tag == nil || !strings.Contains(field.Tag.Value, `layout:"`) {
+					continue
+				}
+				var types []ast.Expr
+				types = append(types, field.Type)
+				types = append(types, tagTypes(field.Tag.Value)...)
+				for _, typ := range types {
+					ast.Inspect(typ, func(n ast.Node) bool {
+						sel, ok := n.(*ast.SelectorExpr)
+						if !ok {
+							return true
+						}
+						if ident, ok := sel.X.(*ast.Ident); ok && !seen[ident.Name] {
+							seen[ident.Name] = true
+							qualifiers = append(qualifiers, ident.Name)
+						}
+						return false
+					})
+				}
+			}
+		}
+	}
+
+	return qualifiers
+}
+
+// tagTypes returns the qualified type names among the parameter values of a
+// field's layout tag, such as the type=uid.UUID of a kind, which generated code
+// names like field types
+func tagTypes(tagLit string) []ast.Expr {
+	tag := reflect.StructTag(strings.Trim(tagLit, "`"))
+	var types []ast.Expr
+	for _, part := range strings.Split(tag.Get("layout"), ",") {
+		_, value, ok := strings.Cut(part, "=")
+		qualifier, name, qualified := strings.Cut(value, ".")
+		if !ok || !qualified || !token.IsIdentifier(qualifier) || !token.IsIdentifier(name) {
+			continue
+		}
+		types = append(types, &ast.SelectorExpr{X: ast.NewIdent(qualifier), Sel: ast.NewIdent(name)})
+	}
+	return types
+}
+
+// loadPackage returns the @layout types and aliases of the package at
+// importPath, parsing it, and first the packages its layouts reference, unless
+// an earlier file loaded it. dir is where importPath resolves from, by
+// go/packages, which lists the package's non-test Go files for the build context.
+func (l *Loader) loadPackage(dir, importPath string) (*loadedPackage, error) {
+	if pkg, ok := l.packages[importPath]; ok {
+		if pkg.loading {
+			return nil, fmt.Errorf("import cycle through package %s", importPath)
+		}
+		return pkg, nil
+	}
+	pkg := &loadedPackage{loading: true}
+	l.packages[importPath] = pkg
+
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles, Dir: dir}
+	found, err := packages.Load(cfg, importPath)
+	if err == nil && len(found) != 1 {
+		err = fmt.Errorf("%d packages match", len(found))
+	}
+	if err == nil && len(found[0].Errors) > 0 {
+		err = found[0].Errors[0]
+	}
+	if err != nil {
+		delete(l.packages, importPath)
+		return nil, fmt.Errorf("locate package %s: %w", importPath, err)
+	}
+	files := found[0].GoFiles
+	pkgDir := dir
+	if len(files) > 0 {
+		pkgDir = filepath.Dir(files[0])
+	}
+
+	// Merge declarations of all files so types may reference each other
+	merged := &ast.File{}
+	importPaths := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, name := range files {
+		file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err == nil {
+			err = checkFieldTypes(fset, file)
+		}
+		if err != nil {
+			delete(l.packages, importPath)
+			return nil, fmt.Errorf("parse package %s: %w", importPath, err)
+		}
+		merged.Decls = append(merged.Decls, file.Decls...)
+		for qualifier, path := range fileImports(file) {
+			importPaths[qualifier] = path
+		}
+	}
+
+	// Layouts nesting those of packages imported in turn take their sizes
+	knownSizes := make(map[string]int)
+	knownAliases := make(map[string]string)
+	for _, qualifier := range referencedQualifiers(merged) {
+		depPath, ok := importPaths[qualifier]
+		if !ok {
+			continue
+		}
+		dep, err := l.loadPackage(pkgDir, depPath)
+		if err != nil {
+			delete(l.packages, importPath)
+			return nil, err
+		}
+		layouts, aliases := dep.qualified(qualifier)
+		for _, layout := range layouts {
+			knownSizes[layout.Name] = layout.Anno.Size
+		}
+		for alias, underlying := range aliases {
+			knownAliases[alias] = underlying
+		}
+	}
+
+	layouts, aliases, diagnostics := extractTypes(merged, knownSizes, knownAliases)
+	l.diagnostics = append(l.diagnostics, positioned(fset, diagnostics)...)
+
+	pkg.layouts, pkg.aliases, pkg.loading = layouts, aliases, false
+	return pkg, nil
+}
+
+// qualified returns copies of the package's layouts and aliases named as seen
+// from an importer using qualifier, references between them qualified too
+func (pkg *loadedPackage) qualified(qualifier string) ([]*TypeLayout, map[string]string) {
+	// Package-local type names need the qualifier when seen from the importer
+	local := make(map[string]bool)
+	for _, layout := range pkg.layouts {
+		local[layout.Name] = true
+	}
+	for alias := range pkg.aliases {
+		local[alias] = true
+	}
+	qualify := func(goType string) string {
+		base := goType
+		for strings.HasPrefix(base, "[") && strings.Contains(base, "]") {
+			base = base[strings.Index(base, "]")+1:]
+		}
+		if !local[base] {
+			return goType
+		}
+		return strings.TrimSuffix(goType, base) + qualifier + "." + base
+	}
+
+	layouts := make([]*TypeLayout, 0, len(pkg.layouts))
+	for _, layout := range pkg.layouts {
+		copied := *layout
+		anno := *layout.Anno
+		copied.Anno = &anno
+		copied.Name = qualifier + "." + layout.Name
+		copied.Fields = slices.Clone(layout.Fields)
+		for i := range copied.Fields {
+			copied.Fields[i].GoType = qualify(copied.Fields[i].GoType)
+		}
+		layouts = append(layouts, &copied)
+	}
+	qualified := make(map[string]string, len(pkg.aliases))
+	for alias, underlying := range pkg.aliases {
+		qualified[qualifier+"."+alias] = underlying
+	}
+
+	return layouts, qualified
+}
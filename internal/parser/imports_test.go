@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFileWithImports(t *testing.T) {
+	// Module with a shared header package and a page importing it under an alias
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/db\n\ngo 1.25\n",
+		"common/header.go": "package common\n" +
+			"type PageID uint64\n" +
+			"// @layout\n" +
+			"type Meta struct {\n" +
+			"\tNumKeys uint16 `layout:\"@0\"`\n" +
+			"}\n" +
+			"// @layout\n" +
+			"type PageHeader struct {\n" +
+			"\tNext PageID `layout:\"@0\"`\n" +
+			"\tMeta Meta   `layout:\"@8\"`\n" +
+			"}\n",
+		"page/page.go": "package page\n" +
+			"import (\n" +
+			"\t\"sync\"\n" +
+			"\thdr \"example.com/db/common\"\n" +
+			")\n" +
+			"// @layout\n" +
+			"type Page struct {\n" +
+			"\tmu     sync.Mutex\n" +
+			"\tHeader hdr.PageHeader `layout:\"@0\"`\n" +
+			"\tPrev   hdr.PageID     `layout:\"@10\"`\n" +
+			"}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parsed, err := ParseFileWithImports(filepath.Join(dir, "page/page.go"))
+	if err != nil {
+		t.Fatalf("ParseFileWithImports() error: %v", err)
+	}
+
+	if got := parsed.Imports["hdr"]; got != "example.com/db/common" {
+		t.Errorf("Imports[hdr] = %q, want example.com/db/common", got)
+	}
+	if _, ok := parsed.Imports["sync"]; ok {
+		t.Error("sync imported, but no layout field references it")
+	}
+	if got := parsed.Aliases["hdr.PageID"]; got != "uint64" {
+		t.Errorf("Aliases[hdr.PageID] = %q, want uint64", got)
+	}
+
+	imported := make(map[string]*TypeLayout)
+	for _, layout := range parsed.Imported {
+		imported[layout.Name] = layout
+	}
+	header, ok := imported["hdr.PageHeader"]
+	if !ok {
+		t.Fatalf("hdr.PageHeader not imported, got %v", imported)
+	}
+	if header.Anno.Size != 10 {
+		t.Errorf("hdr.PageHeader size = %d, want 10", header.Anno.Size)
+	}
+	// References between imported types are qualified too
+	if got := header.Fields[1].GoType; got != "hdr.Meta" {
+		t.Errorf("hdr.PageHeader.Meta type = %q, want hdr.Meta", got)
+	}
+
+	// Page size inferred from the imported header and alias: Prev ends at 10+8
+	if len(parsed.Layouts) != 1 || parsed.Layouts[0].Anno.Size != 18 {
+		t.Errorf("Page layouts = %v, want one of size 18", parsed.Layouts)
+	}
+}
+
+func TestLoader(t *testing.T) {
+	// Module whose page package nests a node, which nests a header of a third
+	// package, none of them with size= set
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/db\n\ngo 1.25\n",
+		"common/meta.go": "package common\n" +
+			"// @layout\n" +
+			"type Meta struct {\n" +
+			"\tNumKeys uint16 `layout:\"@0\"`\n" +
+			"}\n",
+		"node/node.go": "package node\n" +
+			"import \"example.com/db/common\"\n" +
+			"// @layout\n" +
+			"type Node struct {\n" +
+			"\tMeta common.Meta `layout:\"@0\"`\n" +
+			"\tNext uint32      `layout:\"@2\"`\n" +
+			"}\n",
+		"page/page.go": "package page\n" +
+			"import \"example.com/db/node\"\n" +
+			"// @layout\n" +
+			"type Page struct {\n" +
+			"\tRoot node.Node `layout:\"@0\"`\n" +
+			"\tKind uint8     `layout:\"@6\"`\n" +
+			"}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader := NewLoader()
+	page, err := loader.ParseFile(filepath.Join(dir, "page/page.go"))
+	if err != nil {
+		t.Fatalf("ParseFile(page.go) error: %v", err)
+	}
+	// node.Node takes the size of the common.Meta it nests: Next ends at 2+4
+	if len(page.Imported) != 1 || page.Imported[0].Name != "node.Node" || page.Imported[0].Anno.Size != 6 {
+		t.Errorf("page imports %v, want node.Node of size 6", page.Imported)
+	}
+	if len(page.Layouts) != 1 || page.Layouts[0].Anno.Size != 7 {
+		t.Errorf("Page layouts = %v, want one of size 7", page.Layouts)
+	}
+
+	// The node package parsed next reuses the common package loaded for the page
+	node, err := loader.ParseFile(filepath.Join(dir, "node/node.go"))
+	if err != nil {
+		t.Fatalf("ParseFile(node.go) error: %v", err)
+	}
+	if len(node.Layouts) != 1 || node.Layouts[0].Anno.Size != 6 {
+		t.Errorf("Node layouts = %v, want one of size 6", node.Layouts)
+	}
+	if len(loader.packages) != 2 {
+		t.Errorf("loader loaded %d packages, want 2 (node and common)", len(loader.packages))
+	}
+
+	// Layouts handed to importers are copies, named as each qualifies them
+	if got := loader.packages["example.com/db/common"].layouts[0].Name; got != "Meta" {
+		t.Errorf("loaded common layout named %q, want Meta", got)
+	}
+}
+
+func TestLoaderDiagnostics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p.go")
+	src := "package p\n\n" +
+		"// @layout size=8\n" +
+		"type P struct {\n" +
+		"\tA uint32 `layout:\"@0\"`\n" +
+		"\tB uint32 `layout:\"@bogus\"`\n" +
+		"}\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := NewLoader().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error: %v", err)
+	}
+	// The skipped tag is returned, at its line, rather than printed
+	if len(parsed.Diagnostics) != 1 {
+		t.Fatalf("Diagnostics = %v, want the @bogus tag", parsed.Diagnostics)
+	}
+	d := parsed.Diagnostics[0]
+	if d.Type != "P" || d.Position.Filename != path || d.Position.Line != 6 || !strings.Contains(d.Message, "P.B: invalid layout tag") {
+		t.Errorf("Diagnostics[0] = %+v, want P.B's tag at %s:6", d, path)
+	}
+}
+
+func TestTagTypes(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"`layout:\"@0,as=uuid,type=uid.UUID,verify\"`", []string{"uid.UUID"}},
+		{"`layout:\"@0,magic=0x1.8p1\"`", nil},
+		{"`layout:\"start-end,count=Header.NumKeys\"`", []string{"Header.NumKeys"}},
+		{"`json:\"id\"`", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, expr := range tagTypes(tt.tag) {
+			sel := expr.(*ast.SelectorExpr)
+			got = append(got, sel.X.(*ast.Ident).Name+"."+sel.Sel.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("tagTypes(%s) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/alexhholmes/layout/kind"
+)
+
+// KindField describes a field tagged with a kind to the kind's functions
+func KindField(field Field) kind.Field {
+	return kind.Field{
+		Name:   field.Name,
+		GoType: field.GoType,
+		Params: field.Layout.KindParams,
+	}
+}
+
+// KindSize returns the encoded size of a field tagged with a kind
+func KindSize(field Field) (int, error) {
+	k, ok := kind.Lookup(field.Layout.Kind)
+	if !ok {
+		return 0, fmt.Errorf("unknown kind: %s", field.Layout.Kind)
+	}
+	size, err := k.Size(KindField(field))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", k.Name, err)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("%s: size must be positive, got %d", k.Name, size)
+	}
+	return size, nil
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alexhholmes/layout/kind"
+)
+
+func init() {
+	kind.Register(kind.Kind{
+		Name: "parsertestdecimal",
+		Size: func(f kind.Field) (int, error) {
+			if f.Params["width"] == "8" {
+				return 8, nil
+			}
+			return 0, fmt.Errorf("unsupported width %q", f.Params["width"])
+		},
+		Marshal:   func(f kind.Field, dst, value string) string { return "" },
+		Unmarshal: func(f kind.Field, src, value string) string { return "" },
+	})
+}
+
+func TestParseTagKind(t *testing.T) {
+	got, err := ParseTag("@16,parsertestdecimal,width=8,scale=2")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Offset != 16 || got.Direction != Fixed || got.Kind != "parsertestdecimal" {
+		t.Errorf("ParseTag() = @%d %v kind=%q, want fixed @16 kind=parsertestdecimal", got.Offset, got.Direction, got.Kind)
+	}
+	if got.KindParams["width"] != "8" || got.KindParams["scale"] != "2" {
+		t.Errorf("ParseTag().KindParams = %v, want width=8 scale=2", got.KindParams)
+	}
+
+	got, err = ParseTag("@16,as=parsertestdecimal,width=8,verify")
+	if err != nil {
+		t.Fatalf("ParseTag(as=) unexpected error: %v", err)
+	}
+	if got.Kind != "parsertestdecimal" || got.KindParams["width"] != "8" {
+		t.Errorf("ParseTag(as=) = kind=%q params=%v, want parsertestdecimal width=8", got.Kind, got.KindParams)
+	}
+	if v, ok := got.KindParams["verify"]; !ok || v != "" {
+		t.Errorf("ParseTag(as=).KindParams[verify] = %q, %v, want bare parameter", v, ok)
+	}
+
+	got, err = ParseTag("@16,parsertestdecimal=8,scale=2")
+	if err != nil {
+		t.Fatalf("ParseTag(K=V) unexpected error: %v", err)
+	}
+	if got.Kind != "parsertestdecimal" || got.KindParams["parsertestdecimal"] != "8" || got.KindParams["scale"] != "2" {
+		t.Errorf("ParseTag(K=V) = kind=%q params=%v, want parsertestdecimal parsertestdecimal=8 scale=2", got.Kind, got.KindParams)
+	}
+
+	for _, tag := range []string{
+		"@16,parsertestdecimal,=8",
+		"@16,parsertestdecimal=8,parsertestdecimal=8",
+		"@16,unregisteredkind=8",
+		"@16,as=unregisteredkind",
+		"@16,parsertestdecimal,width=8,width=16",
+		"@16,unregisteredkind",
+	} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error", tag)
+		}
+	}
+}
+
+func TestKindSize(t *testing.T) {
+	field := Field{Name: "Price", GoType: "Decimal", Layout: &FieldLayout{
+		Kind: "parsertestdecimal", KindParams: map[string]string{"width": "8"},
+	}}
+	if size, err := KindSize(field); err != nil || size != 8 {
+		t.Errorf("KindSize() = %d, %v, want 8", size, err)
+	}
+
+	field.Layout.KindParams["width"] = "3"
+	if _, err := KindSize(field); err == nil {
+		t.Errorf("KindSize() expected error for unsupported width")
+	}
+}
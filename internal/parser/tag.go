@@ -2,8 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"go/token"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/alexhholmes/layout/kind"
 )
 
 type PackDirection int
@@ -32,13 +36,82 @@ type FieldLayout struct {
 	Direction  PackDirection
 	StartAt    int    // -1 if unspecified; for directional, where growth begins
 	CountField string // Field name containing count/length for slices (empty if not specified)
+	Group      string // Named region (region=) a start-end field is packed into, ordered by regions=
+	MaxCount   int    // Upper bound (max=) on the count field, below the region capacity; 0 if unset
+	Sentinel   bool   // Metadata slice ended by an entry whose indirect offset and size are zero, instead of count=
+	Encode     string // Element encoding (encode=): EncodeDelta for a dynamic region, EncodePrefix for an indirect slice, or empty
+	ElemAlign  int    // Alignment (elemalign=) of each element of a struct slice; 0 packs elements back to back
+	Stride     int    // Distance (stride=) between element starts of a struct slice, the slack zeroed; 0 packs elements back to back
+	Arrange    string // Arrangement (arrange=) of the slices sharing the region: ArrangeInterleave or ArrangeColumnar
+	Parallel   string // Arranged slice (parallel=) whose region, count and arrangement this slice shares
 
 	// Indirect slice fields ([][]byte with metadata indirection)
 	From        string // Source slice field name (e.g., "Elements")
 	OffsetField string // Field in element that holds offset (e.g., "KeyOffset")
 	SizeField   string // Field in element that holds size (e.g., "KeySize")
 	Region      string // Region field that this slices into (e.g., "Data")
-	OffsetMode  string // "relative" (default) or "absolute" - how offsets are stored
+	OffsetMode  string // What stored offsets count from: OffsetAfterMetadata (default), OffsetPage or OffsetRegion
+
+	// Extent slices ([][]byte whose elements live in other pages, e.g. overflow
+	// pages, resolved through a fetch callback); OffsetField and SizeField locate
+	// the bytes within the page
+	Extents   string // Source slice field name (e.g., "Elements")
+	PageField string // Field in element that holds the page ID (e.g., "OverflowPage")
+
+	// Trailer fields (mode=stream) follow the payload at the end of each frame
+	Trailer bool
+
+	// Reserved byte range (pseudo-field, e.g. `_ struct{}`): zero-filled on marshal
+	// and, with VerifyZero, checked to be zero on unmarshal
+	Reserve    int
+	VerifyZero bool
+
+	// Default value (Go integer literal) for fixed fields left zero, e.g. "0xCAFE".
+	// With Magic (magic=V), unmarshal also refuses any other value.
+	Default string
+	Magic   bool
+
+	// Hooks: methods on the type computing the field on marshal (Get) and receiving
+	// it on unmarshal (Set), called once the rest of the buffer is done
+	Get string
+	Set string
+
+	// Custom kind (package kind) encoding the field, with its tag parameters
+	Kind       string
+	KindParams map[string]string
+
+	// Bit fields: Bits > 0 packs the field into Bits bits starting at bit BitOffset
+	// of byte Offset, numbered per the bitorder= annotation
+	BitOffset int
+	Bits      int
+
+	// Present (present=F) makes a fixed [N]T array a set of optional slots: bit i of
+	// field F marks slot i present, and present slots are packed from Offset in
+	// order, absent ones taking no space
+	Present string
+
+	// Overlap (overlap=allow) makes a fixed field an alias of bytes other fields
+	// own, e.g. a second interpretation of a payload: exempt from collision checks,
+	// decoded on unmarshal but never encoded
+	Overlap bool
+
+	// CField (cfield=) names the member of the cstruct= C type holding the field,
+	// when it isn't named like the field
+	CField string
+
+	// Sensitive (sensitive) masks the field's bytes in generated dumps, so pages
+	// holding secrets or personal data can be logged
+	Sensitive bool
+
+	// Access (access=) makes a fixed field a hardware register, read and written
+	// whole by zerocopy accessors never inlined: AccessReadOnly, AccessWriteOnly or
+	// AccessReadWrite; empty for other fields
+	Access string
+
+	// Cap (cap=N) with Used (count=F) makes a fixed [N]T array an inline slice:
+	// field F counts the elements in use, from the first, and the others are zero
+	Cap  int
+	Used string
 }
 
 // ParseTag parses layout struct tags
@@ -50,6 +123,36 @@ type FieldLayout struct {
 //   - "@N,start-end"            : Dynamic region starting at byte N, growing forward →
 //   - "@N,end-start"            : Dynamic region starting at byte N, growing backward ←
 //   - "direction,count=Field"   : Dynamic region with count from Field
+//   - "start-end,region=Name"   : Dynamic region packed into named region Name
+//   - "direction,count=F,max=N" : Dynamic region holding at most N elements
+//   - "start-end,sentinel"      : Metadata slice of indirect slices ended by a zero entry
+//   - "start-end,encode=delta"  : Sorted integers stored as varint differences
+//   - "start-end,elemalign=N"   : Struct slice elements each starting at a multiple of N
+//   - "start-end,stride=N"      : Struct slice elements starting N bytes apart
+//   - "start-end,arrange=A"     : Region shared with parallel= slices, A is interleave or columnar
+//   - "parallel=F"              : Slice stored alongside arrange= slice F, element for element
+//   - "trailer"                 : Fixed field after the payload of a stream frame
+//   - "@N.B"                    : Single-bit field at bit B (0-7) of byte N
+//   - "@N.B,bits=W"             : W-bit field starting at bit B of byte N
+//   - "@N,default=V"            : Fixed field defaulting to V when zero
+//   - "@N,magic=V"              : Fixed field defaulting to V, verified on unmarshal
+//   - "@N,reserve=L[,verify]"   : Reserved bytes [N, N+L), optionally verified zero
+//   - "@N,K[,key=V...]"         : Fixed field encoded by the registered kind K
+//   - "@N,as=K[,key=V...]"      : Same as "@N,K[,key=V...]"
+//   - "@N,K=V[,key=V...]"       : Same as "@N,K,K=V[,key=V...]", e.g. "@8,fixed=16.16"
+//   - "@N,get=F,set=G"          : Fixed field computed by p.F(buf) on marshal, passed
+//     to p.G(buf, v) on unmarshal
+//   - "@N,present=F"            : [N]T array of optional slots, packed by bitmap F
+//   - "@N,cap=C,count=F"        : [C]T array holding the first F elements in use
+//   - "@N,...,overlap=allow"    : Fixed field aliasing the bytes of other fields
+//   - "...,sensitive"           : Fixed field or dynamic region masked in dumps
+//   - "@N,...,access=ro|wo|rw"  : Register field without a setter (ro) or getter (wo)
+//   - "from=S,offset=O,size=Z,region=R[,offsetmode=M]" : [][]byte whose element i is
+//     R[S[i].O : S[i].O+S[i].Z]; M is page, region or after-metadata (default), or
+//     the original names absolute (page) and relative (after-metadata); encode=prefix
+//     stores each element after the prefix it shares with the one before it
+//   - "extents=S,page=P,offset=O,size=Z" : [][]byte whose element i is S[i].Z bytes
+//     at S[i].O of page S[i].P, continuing into the following pages, read lazily
 //
 // Count semantics (validated by analyzer):
 //   - end-start growing to offset 0 or fixed field: NO count needed (implicit boundary)
@@ -76,20 +179,113 @@ func ParseTag(tag string) (*FieldLayout, error) {
 
 	parts := strings.Split(tag, ",")
 
+	// Any fixed field may alias other fields' bytes
+	if i := slices.Index(parts, "overlap=allow"); i > 0 {
+		f, err := ParseTag(strings.Join(slices.Delete(parts, i, i+1), ","))
+		if err != nil {
+			return nil, err
+		}
+		if f.Direction != Fixed || f.Reserve > 0 || f.Trailer || f.Get != "" || f.Set != "" {
+			return nil, fmt.Errorf("overlap=allow requires a fixed field without reserve=, get= or set=")
+		}
+		f.Overlap = true
+		return f, nil
+	}
+
+	// Fields and regions holding their own bytes may be masked in dumps
+	if i := slices.Index(parts, "sensitive"); i > 0 {
+		f, err := ParseTag(strings.Join(slices.Delete(parts, i, i+1), ","))
+		if err != nil {
+			return nil, err
+		}
+		if f.Reserve > 0 || f.From != "" || f.Extents != "" || f.Parallel != "" {
+			return nil, fmt.Errorf("sensitive requires a fixed field or a dynamic region; mark the region holding the bytes instead")
+		}
+		f.Sensitive = true
+		return f, nil
+	}
+
+	// Register fields of memory-mapped hardware may be read-only or write-only
+	if i := slices.IndexFunc(parts, func(part string) bool { return strings.HasPrefix(part, "access=") }); i > 0 {
+		access := strings.TrimPrefix(parts[i], "access=")
+		if access != AccessReadOnly && access != AccessWriteOnly && access != AccessReadWrite {
+			return nil, fmt.Errorf("access must be '%s', '%s' or '%s', got: %s", AccessReadOnly, AccessWriteOnly, AccessReadWrite, access)
+		}
+		f, err := ParseTag(strings.Join(slices.Delete(parts, i, i+1), ","))
+		if err != nil {
+			return nil, err
+		}
+		if f.Direction != Fixed || f.Reserve > 0 || f.Trailer || f.Get != "" || f.Set != "" || f.Present != "" ||
+			f.Kind != "" || f.Bits > 0 || f.Overlap {
+			return nil, fmt.Errorf("access=%s requires a fixed field without reserve=, get=, set=, present=, a kind, bits or overlap=allow", access)
+		}
+		f.Access = access
+		return f, nil
+	}
+
 	// Check for indirect slice syntax: from=X,offset=Y,size=Z,region=W
 	if strings.HasPrefix(parts[0], "from=") {
 		return parseIndirectSlice(parts)
 	}
 
+	// Extent slice syntax: extents=X,page=P,offset=Y,size=Z
+	if strings.HasPrefix(parts[0], "extents=") {
+		return parseExtents(parts)
+	}
+
+	// Parallel slice: shares the region and count of an arrange= slice
+	if name, ok := strings.CutPrefix(parts[0], "parallel="); ok {
+		if name == "" || !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("parallel= requires a field name, got: %q", name)
+		}
+		if len(parts) > 1 {
+			return nil, fmt.Errorf("parallel= takes no other parameters, got: %s", parts[1])
+		}
+		f.Direction = StartEnd
+		f.Parallel = name
+		return f, nil
+	}
+
+	// Trailer field: placed after the payload, in declaration order
+	if parts[0] == "trailer" {
+		for _, part := range parts[1:] {
+			if !strings.HasPrefix(part, "get=") && !strings.HasPrefix(part, "set=") {
+				return nil, fmt.Errorf("unknown trailer parameter: %s", part)
+			}
+		}
+		if err := parseFixedParams(f, parts[1:]); err != nil {
+			return nil, err
+		}
+		f.Direction = Fixed
+		f.Trailer = true
+		return f, nil
+	}
+
 	// Check for fixed offset: @N
 	if strings.HasPrefix(parts[0], "@") {
-		// Extract offset: "@8" → 8
+		// Extract offset: "@8" → 8, "@8.3" → 8 with bit 3
 		offsetStr := strings.TrimPrefix(parts[0], "@")
+		bitStr := ""
+		if i := strings.Index(offsetStr, "."); i >= 0 {
+			offsetStr, bitStr = offsetStr[:i], offsetStr[i+1:]
+			if bitStr == "" {
+				return nil, fmt.Errorf("invalid offset: %s", parts[0])
+			}
+		}
 		offset, err := strconv.Atoi(offsetStr)
 		if err != nil {
 			return nil, fmt.Errorf("invalid offset: %s", parts[0])
 		}
 
+		// Bit field: "@N.B" or "@N.B,bits=W"
+		isBitField := bitStr != ""
+		for _, part := range parts[1:] {
+			isBitField = isBitField || strings.HasPrefix(part, "bits=")
+		}
+		if isBitField {
+			return parseBitField(f, offset, bitStr, parts[1:])
+		}
+
 		// No other parts: fixed field at offset
 		if len(parts) == 1 {
 			f.Offset = offset
@@ -97,58 +293,290 @@ func ParseTag(tag string) (*FieldLayout, error) {
 			return f, nil
 		}
 
+		// Reserved range: "@100,reserve=28" or "@100,reserve=28,verify"
+		if strings.HasPrefix(parts[1], "reserve=") {
+			return parseReserve(f, offset, parts[1:])
+		}
+
+		// Inline slice: "@8,cap=32,count=NumItems", in either order
+		if slices.ContainsFunc(parts[1:], func(part string) bool { return strings.HasPrefix(part, "cap=") }) {
+			return parseCap(f, offset, parts[1:])
+		}
+
+		// Custom kind: "@16,uuid", "@16,decimal128,scale=4", "@16,as=uuid" or
+		// "@16,fixed=16.16", the kind with its own name as a parameter
+		if name, ok := strings.CutPrefix(parts[1], "as="); ok {
+			if _, ok := kind.Lookup(name); !ok {
+				return nil, fmt.Errorf("unknown kind: %s", name)
+			}
+			return parseKind(f, offset, append([]string{name}, parts[2:]...))
+		}
+		if _, ok := kind.Lookup(parts[1]); ok {
+			return parseKind(f, offset, parts[1:])
+		}
+		if name, _, ok := strings.Cut(parts[1], "="); ok {
+			if _, ok := kind.Lookup(name); ok {
+				return parseKind(f, offset, append([]string{name}, parts[1:]...))
+			}
+		}
+
+		// Fixed field with parameters: "@0,magic=0xCAFE", "@12,get=computeCRC"
+		if isFixedParam(parts[1]) {
+			if err := parseFixedParams(f, parts[1:]); err != nil {
+				return nil, err
+			}
+			f.Offset = offset
+			f.Direction = Fixed
+			return f, nil
+		}
+
 		// Has direction: dynamic region starting at offset
 		// e.g., "@1999,end-start" or "@1999,end-start,count=N"
-		dir, countField, err := parseDirectionAndCount(parts[1:])
-		if err != nil {
+		if err := parseDirectionAndCount(f, parts[1:]); err != nil {
 			return nil, err
 		}
 		f.Offset = -1 // Dynamic
-		f.Direction = dir
 		f.StartAt = offset
-		f.CountField = countField
 	} else {
 		// Pure directional: "start-end" or "start-end,count=Len"
-		dir, countField, err := parseDirectionAndCount(parts)
-		if err != nil {
+		if err := parseDirectionAndCount(f, parts); err != nil {
 			return nil, err
 		}
-		f.Direction = dir
 		f.Offset = -1
 		f.StartAt = -1
-		f.CountField = countField
 	}
 
 	return f, nil
 }
 
-// parseDirectionAndCount extracts direction and optional count=Field from parts
-// Input: ["start-end"] or ["end-start", "count=NumElems"]
-func parseDirectionAndCount(parts []string) (PackDirection, string, error) {
+// parseBitField completes a bit field layout from the bit offset ("" for bit 0) and
+// the optional bits=W parameter (default 1)
+func parseBitField(f *FieldLayout, offset int, bitStr string, params []string) (*FieldLayout, error) {
+	f.Offset = offset
+	f.Direction = Fixed
+	f.Bits = 1
+
+	if bitStr != "" {
+		bit, err := strconv.Atoi(bitStr)
+		if err != nil || bit < 0 || bit > 7 {
+			return nil, fmt.Errorf("bit offset must be 0-7, got: %s", bitStr)
+		}
+		f.BitOffset = bit
+	}
+
+	for _, part := range params {
+		if strings.HasPrefix(part, "default=") || strings.HasPrefix(part, "magic=") {
+			if err := parseDefault(f, part); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !strings.HasPrefix(part, "bits=") {
+			return nil, fmt.Errorf("unknown bit field parameter: %s", part)
+		}
+		bits, err := strconv.Atoi(strings.TrimPrefix(part, "bits="))
+		if err != nil || bits < 1 || bits > 64 {
+			return nil, fmt.Errorf("bits must be 1-64, got: %s", strings.TrimPrefix(part, "bits="))
+		}
+		f.Bits = bits
+	}
+
+	return f, nil
+}
+
+// parseReserve parses the parameters of a reserved range: reserve=L[,verify]
+func parseReserve(f *FieldLayout, offset int, params []string) (*FieldLayout, error) {
+	length, err := strconv.Atoi(strings.TrimPrefix(params[0], "reserve="))
+	if err != nil || length <= 0 {
+		return nil, fmt.Errorf("reserve must be a positive byte count, got: %s", strings.TrimPrefix(params[0], "reserve="))
+	}
+	f.Offset = offset
+	f.Direction = Fixed
+	f.Reserve = length
+
+	for _, part := range params[1:] {
+		if part != "verify" {
+			return nil, fmt.Errorf("unknown reserve parameter: %s", part)
+		}
+		f.VerifyZero = true
+	}
+	return f, nil
+}
+
+// parseCap parses the parameters of an inline slice: cap=N and count=F
+func parseCap(f *FieldLayout, offset int, params []string) (*FieldLayout, error) {
+	f.Offset = offset
+	f.Direction = Fixed
+
+	for _, part := range params {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "cap":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("cap must be a positive element count, got: %s", value)
+			}
+			f.Cap = n
+		case "count":
+			if !token.IsIdentifier(value) {
+				return nil, fmt.Errorf("count= requires a field name, got: %q", value)
+			}
+			f.Used = value
+		default:
+			return nil, fmt.Errorf("unknown inline slice parameter: %s", part)
+		}
+	}
+	if f.Used == "" {
+		return nil, fmt.Errorf("cap=%d requires count= naming the field that counts the elements in use", f.Cap)
+	}
+	return f, nil
+}
+
+// parseKind parses a field of a registered kind: the kind name and its key=value
+// parameters, which the kind validates. A bare parameter (verify) has value "".
+func parseKind(f *FieldLayout, offset int, params []string) (*FieldLayout, error) {
+	f.Offset = offset
+	f.Direction = Fixed
+	f.Kind = params[0]
+	f.KindParams = make(map[string]string)
+
+	for _, part := range params[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		if key == "" {
+			return nil, fmt.Errorf("%s parameters must be key=value or a name, got: %s", f.Kind, part)
+		}
+		if _, dup := f.KindParams[key]; dup {
+			return nil, fmt.Errorf("%s parameter %s given twice", f.Kind, key)
+		}
+		f.KindParams[key] = value
+	}
+	return f, nil
+}
+
+// isFixedParam reports whether a tag part is a parameter of a fixed field
+func isFixedParam(part string) bool {
+	for _, prefix := range []string{"default=", "magic=", "get=", "set=", "present=", "cfield="} {
+		if strings.HasPrefix(part, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFixedParams parses the parameters of a fixed field: default=V, magic=V,
+// get=F, set=G, present=F, cfield=M
+func parseFixedParams(f *FieldLayout, params []string) error {
+	for _, part := range params {
+		key, value, _ := strings.Cut(part, "=")
+		switch {
+		case !isFixedParam(part):
+			return fmt.Errorf("unknown parameter: %s", part)
+		case key == "default", key == "magic":
+			if err := parseDefault(f, part); err != nil {
+				return err
+			}
+		case key == "present":
+			if !token.IsIdentifier(value) {
+				return fmt.Errorf("present= requires a field name, got: %q", value)
+			}
+			f.Present = value
+		case key == "cfield":
+			if !token.IsIdentifier(value) && !token.IsKeyword(value) {
+				return fmt.Errorf("cfield= requires a C member name, got: %q", value)
+			}
+			f.CField = value
+		case !token.IsIdentifier(value):
+			return fmt.Errorf("%s= requires a method name, got: %q", key, value)
+		case key == "get":
+			f.Get = value
+		default:
+			f.Set = value
+		}
+	}
+	return nil
+}
+
+// parseDefault sets the default from a "default=V" or "magic=V" parameter. V must
+// be an integer literal; the analyzer checks that it fits the field type.
+func parseDefault(f *FieldLayout, part string) error {
+	key, value, _ := strings.Cut(part, "=")
+	if f.Default != "" {
+		return fmt.Errorf("%s= conflicts with an earlier default= or magic=", key)
+	}
+	if _, err := strconv.ParseInt(value, 0, 64); err != nil {
+		if _, err := strconv.ParseUint(value, 0, 64); err != nil {
+			return fmt.Errorf("%s must be an integer literal, got: %s", key, value)
+		}
+	}
+	f.Default = value
+	f.Magic = key == "magic"
+	return nil
+}
+
+// parseDirectionAndCount sets the direction, optional count=Field, optional
+// region=Name, optional max=N, optional encode=, optional elemalign=N,
+// optional stride=N and optional arrange= of a dynamic region from parts
+// Input: ["start-end"], ["end-start", "count=NumElems"] or ["start-end", "region=body"]
+func parseDirectionAndCount(f *FieldLayout, parts []string) error {
 	if len(parts) == 0 {
-		return 0, "", fmt.Errorf("missing direction")
+		return fmt.Errorf("missing direction")
 	}
 
 	// First part is direction
 	dir, err := parseDirection(parts[0])
 	if err != nil {
-		return 0, "", err
+		return err
 	}
+	f.Direction = dir
 
-	// Check for count= in remaining parts
-	countField := ""
+	// Check for count=, region=, max=, sentinel, encode=, elemalign=, stride= and arrange= in remaining parts
 	for _, part := range parts[1:] {
 		if strings.HasPrefix(part, "count=") {
-			countField = strings.TrimPrefix(part, "count=")
-			if countField == "" {
-				return 0, "", fmt.Errorf("count= requires field name")
+			f.CountField = strings.TrimPrefix(part, "count=")
+			if f.CountField == "" {
+				return fmt.Errorf("count= requires field name")
+			}
+		} else if strings.HasPrefix(part, "region=") {
+			f.Group = strings.TrimPrefix(part, "region=")
+			if f.Group == "" {
+				return fmt.Errorf("region= requires a region name")
+			}
+		} else if strings.HasPrefix(part, "max=") {
+			max, err := strconv.Atoi(strings.TrimPrefix(part, "max="))
+			if err != nil || max <= 0 {
+				return fmt.Errorf("max must be a positive element count, got: %s", strings.TrimPrefix(part, "max="))
+			}
+			f.MaxCount = max
+		} else if part == "sentinel" {
+			f.Sentinel = true
+		} else if strings.HasPrefix(part, "elemalign=") {
+			align, err := strconv.Atoi(strings.TrimPrefix(part, "elemalign="))
+			if err != nil || align <= 0 || align&(align-1) != 0 {
+				return fmt.Errorf("elemalign must be a power of two, got: %s", strings.TrimPrefix(part, "elemalign="))
+			}
+			f.ElemAlign = align
+		} else if strings.HasPrefix(part, "stride=") {
+			stride, err := strconv.Atoi(strings.TrimPrefix(part, "stride="))
+			if err != nil || stride <= 0 {
+				return fmt.Errorf("stride must be a positive byte count, got: %s", strings.TrimPrefix(part, "stride="))
+			}
+			f.Stride = stride
+		} else if strings.HasPrefix(part, "arrange=") {
+			f.Arrange = strings.TrimPrefix(part, "arrange=")
+			if f.Arrange != ArrangeInterleave && f.Arrange != ArrangeColumnar {
+				return fmt.Errorf("unknown arrangement: %s (supported: %s, %s)", f.Arrange, ArrangeInterleave, ArrangeColumnar)
+			}
+		} else if strings.HasPrefix(part, "encode=") {
+			f.Encode = strings.TrimPrefix(part, "encode=")
+			if f.Encode != EncodeDelta {
+				return fmt.Errorf("unknown encoding: %s (supported: %s)", f.Encode, EncodeDelta)
 			}
 		} else {
-			return 0, "", fmt.Errorf("unknown parameter: %s", part)
+			return fmt.Errorf("unknown parameter: %s", part)
 		}
 	}
 
-	return dir, countField, nil
+	return nil
 }
 
 func parseDirection(s string) (PackDirection, error) {
@@ -162,12 +590,49 @@ func parseDirection(s string) (PackDirection, error) {
 	}
 }
 
-// parseIndirectSlice parses indirect slice tags: from=X,offset=Y,size=Z,region=W[,offsetmode=M]
+// Offset modes of indirect slices (offsetmode=): where the offsets stored in the
+// metadata elements count from
+const (
+	OffsetAfterMetadata = "after-metadata" // End of the metadata slice (default)
+	OffsetPage          = "page"           // Start of the buffer
+	OffsetRegion        = "region"         // Start of the data region's space
+)
+
+// Element encodings (encode=)
+const (
+	EncodeDelta  = "delta"  // Each element as the uvarint difference from the one before it
+	EncodePrefix = "prefix" // Each entry of an indirect slice as the uvarint length it shares with the one before it, then the rest
+)
+
+// Accesses of register fields (access=)
+const (
+	AccessReadOnly  = "ro" // Getter only, e.g. a status register
+	AccessWriteOnly = "wo" // Setter only, e.g. a command register
+	AccessReadWrite = "rw" // Both, as plain fields
+)
+
+// Arrangements of the parallel slices sharing a region (arrange=)
+const (
+	ArrangeInterleave = "interleave" // Element i of every slice together, row after row
+	ArrangeColumnar   = "columnar"   // Every element of one slice, then every element of the next
+)
+
+// IgnoreTag is the layout tag of a field left out of the encoding on purpose,
+// which vet doesn't report as untagged
+const IgnoreTag = "ignore"
+
+// legacyOffsetModes maps the original offsetmode= names to the modes they meant
+var legacyOffsetModes = map[string]string{
+	"relative": OffsetAfterMetadata,
+	"absolute": OffsetPage,
+}
+
+// parseIndirectSlice parses indirect slice tags: from=X,offset=Y,size=Z,region=W[,offsetmode=M][,encode=prefix]
 func parseIndirectSlice(parts []string) (*FieldLayout, error) {
 	f := &FieldLayout{
 		Offset:     -1,
 		StartAt:    -1,
-		OffsetMode: "relative", // Default to relative for backwards compatibility
+		OffsetMode: OffsetAfterMetadata,
 	}
 
 	// Parse all key=value pairs
@@ -187,10 +652,19 @@ func parseIndirectSlice(parts []string) (*FieldLayout, error) {
 		case "region":
 			f.Region = kv[1]
 		case "offsetmode":
-			if kv[1] != "relative" && kv[1] != "absolute" {
-				return nil, fmt.Errorf("offsetmode must be 'relative' or 'absolute', got: %s", kv[1])
+			mode := kv[1]
+			if legacy, ok := legacyOffsetModes[mode]; ok {
+				mode = legacy
 			}
-			f.OffsetMode = kv[1]
+			if mode != OffsetAfterMetadata && mode != OffsetPage && mode != OffsetRegion {
+				return nil, fmt.Errorf("offsetmode must be 'page', 'region' or 'after-metadata', got: %s", kv[1])
+			}
+			f.OffsetMode = mode
+		case "encode":
+			if kv[1] != EncodePrefix {
+				return nil, fmt.Errorf("unknown indirect slice encoding: %s (supported: %s)", kv[1], EncodePrefix)
+			}
+			f.Encode = kv[1]
 		default:
 			return nil, fmt.Errorf("unknown indirect slice parameter: %s", kv[0])
 		}
@@ -203,3 +677,37 @@ func parseIndirectSlice(parts []string) (*FieldLayout, error) {
 
 	return f, nil
 }
+
+// parseExtents parses extent slice tags: extents=X,page=P,offset=Y,size=Z
+func parseExtents(parts []string) (*FieldLayout, error) {
+	f := &FieldLayout{
+		Offset:  -1,
+		StartAt: -1,
+	}
+
+	for _, part := range parts {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid extent parameter: %s", part)
+		}
+
+		switch kv[0] {
+		case "extents":
+			f.Extents = kv[1]
+		case "page":
+			f.PageField = kv[1]
+		case "offset":
+			f.OffsetField = kv[1]
+		case "size":
+			f.SizeField = kv[1]
+		default:
+			return nil, fmt.Errorf("unknown extent parameter: %s", kv[0])
+		}
+	}
+
+	if f.Extents == "" || f.PageField == "" || f.OffsetField == "" || f.SizeField == "" {
+		return nil, fmt.Errorf("extents require all 4 params: extents, page, offset, size")
+	}
+
+	return f, nil
+}
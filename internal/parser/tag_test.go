@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -37,15 +38,19 @@ func TestParseTag(t *testing.T) {
 		{"@8,start-end,count=Len", -1, StartEnd, 8, "Len", false},
 		{"@1999,end-start,count=N", -1, EndStart, 1999, "N", false},
 
+		// Stream frame trailer (offset follows the payload)
+		{"trailer", -1, Fixed, -1, "", false},
+
 		// Error cases
-		{"", 0, 0, 0, "", true},                     // empty
-		{"@", 0, 0, 0, "", true},                    // no offset number
-		{"@abc", 0, 0, 0, "", true},                 // non-numeric offset
-		{"invalid", 0, 0, 0, "", true},              // unknown direction
-		{"@0,invalid", 0, 0, 0, "", true},           // bad direction after offset
-		{"@8,@16", 0, 0, 0, "", true},               // double offset
-		{"start-end,count=", 0, 0, 0, "", true},     // empty count
+		{"", 0, 0, 0, "", true},                      // empty
+		{"@", 0, 0, 0, "", true},                     // no offset number
+		{"@abc", 0, 0, 0, "", true},                  // non-numeric offset
+		{"invalid", 0, 0, 0, "", true},               // unknown direction
+		{"@0,invalid", 0, 0, 0, "", true},            // bad direction after offset
+		{"@8,@16", 0, 0, 0, "", true},                // double offset
+		{"start-end,count=", 0, 0, 0, "", true},      // empty count
 		{"start-end,unknown=foo", 0, 0, 0, "", true}, // unknown param
+		{"trailer,count=N", 0, 0, 0, "", true},       // trailer takes no params
 	}
 
 	for _, tt := range tests {
@@ -98,4 +103,498 @@ func TestPackDirectionString(t *testing.T) {
 			t.Errorf("PackDirection(%d).String() = %q, want %q", tt.dir, got, tt.want)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestParseTagBitField(t *testing.T) {
+	tests := []struct {
+		tag      string
+		wantOff  int
+		wantBit  int
+		wantBits int
+		wantErr  bool
+	}{
+		{"@0.4", 0, 4, 1, false},
+		{"@0.4,bits=4", 0, 4, 4, false},
+		{"@2,bits=12", 2, 0, 12, false},
+		{"@1.7,bits=9", 1, 7, 9, false},
+
+		// Error cases
+		{"@0.8", 0, 0, 0, true},         // bit offset out of range
+		{"@0.", 0, 0, 0, true},          // missing bit offset
+		{"@0.x", 0, 0, 0, true},         // non-numeric bit offset
+		{"@0.1,bits=0", 0, 0, 0, true},  // zero width
+		{"@0.1,bits=65", 0, 0, 0, true}, // wider than uint64
+		{"@0.1,count=N", 0, 0, 0, true}, // unknown param
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := ParseTag(tt.tag)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTag(%q) expected error, got nil", tt.tag)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTag(%q) unexpected error: %v", tt.tag, err)
+			}
+
+			if got.Direction != Fixed || got.Offset != tt.wantOff || got.BitOffset != tt.wantBit || got.Bits != tt.wantBits {
+				t.Errorf("ParseTag(%q) = %v @%d.%d bits=%d, want fixed @%d.%d bits=%d", tt.tag,
+					got.Direction, got.Offset, got.BitOffset, got.Bits, tt.wantOff, tt.wantBit, tt.wantBits)
+			}
+		})
+	}
+}
+
+func TestParseTagDefault(t *testing.T) {
+	tests := []struct {
+		tag         string
+		wantOff     int
+		wantBits    int
+		wantDefault string
+		wantErr     bool
+	}{
+		{"@0,default=0xCAFE", 0, 0, "0xCAFE", false},
+		{"@4,default=-1", 4, 0, "-1", false},
+		{"@0.4,bits=4,default=4", 0, 4, "4", false},
+		{"@1,default=3,bits=2", 1, 2, "3", false},
+
+		// Error cases
+		{"@0,default=", 0, 0, "", true},          // missing value
+		{"@0,default=CAFE", 0, 0, "", true},      // not a literal
+		{"@0,default=1,count=N", 0, 0, "", true}, // unknown param
+		{"start-end,default=1", 0, 0, "", true},  // dynamic field
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := ParseTag(tt.tag)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTag(%q) expected error, got nil", tt.tag)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseTag(%q) unexpected error: %v", tt.tag, err)
+			}
+
+			if got.Direction != Fixed || got.Offset != tt.wantOff || got.Bits != tt.wantBits || got.Default != tt.wantDefault {
+				t.Errorf("ParseTag(%q) = %v @%d bits=%d default=%q, want fixed @%d bits=%d default=%q", tt.tag,
+					got.Direction, got.Offset, got.Bits, got.Default, tt.wantOff, tt.wantBits, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestParseTagMagic(t *testing.T) {
+	got, err := ParseTag("@0.4,bits=4,magic=4")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Default != "4" || !got.Magic || got.Bits != 4 {
+		t.Errorf("ParseTag() = default=%q magic=%v bits=%d, want default=\"4\" magic=true bits=4",
+			got.Default, got.Magic, got.Bits)
+	}
+
+	got, err = ParseTag("@0,default=1")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Magic {
+		t.Error("default= should not set Magic")
+	}
+
+	for _, tag := range []string{"@0,magic=", "@0,magic=x", "@0,magic=1,default=1", "@0,default=1,magic=1"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagReserve(t *testing.T) {
+	got, err := ParseTag("@100,reserve=28,verify")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Offset != 100 || got.Reserve != 28 || !got.VerifyZero {
+		t.Errorf("ParseTag() = @%d reserve=%d verify=%v, want @100 reserve=28 verify=true",
+			got.Offset, got.Reserve, got.VerifyZero)
+	}
+
+	for _, tag := range []string{"@0,reserve=0", "@0,reserve=x", "@0,reserve=4,strict"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagOverlap(t *testing.T) {
+	for _, tag := range []string{"@8,overlap=allow", "@8,magic=1,overlap=allow", "@8.2,bits=3,overlap=allow"} {
+		got, err := ParseTag(tag)
+		if err != nil {
+			t.Fatalf("ParseTag(%q) unexpected error: %v", tag, err)
+		}
+		if got.Offset != 8 || got.Direction != Fixed || !got.Overlap {
+			t.Errorf("ParseTag(%q) = @%d %v overlap=%v, want fixed @8 overlap=true", tag, got.Offset, got.Direction, got.Overlap)
+		}
+	}
+
+	for _, tag := range []string{"start-end,overlap=allow", "@0,reserve=4,overlap=allow", "@0,get=f,overlap=allow", "@0,overlap=deny"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagSensitive(t *testing.T) {
+	tests := []struct {
+		tag       string
+		direction PackDirection
+	}{
+		{"@8,sensitive", Fixed},
+		{"@8,uuid,sensitive", Fixed},
+		{"start-end,sensitive,count=N", StartEnd},
+		{"@4096,end-start,count=N,sensitive", EndStart},
+	}
+	for _, tt := range tests {
+		got, err := ParseTag(tt.tag)
+		if err != nil {
+			t.Fatalf("ParseTag(%q) unexpected error: %v", tt.tag, err)
+		}
+		if got.Direction != tt.direction || !got.Sensitive {
+			t.Errorf("ParseTag(%q) = %v sensitive=%v, want %v sensitive=true", tt.tag, got.Direction, got.Sensitive, tt.direction)
+		}
+	}
+
+	for _, tag := range []string{"sensitive", "@0,reserve=4,sensitive", "parallel=Keys,sensitive", "from=E,offset=O,size=S,region=D,sensitive"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagAccess(t *testing.T) {
+	tests := []struct {
+		tag    string
+		access string
+	}{
+		{"@0,access=ro", AccessReadOnly},
+		{"@4,access=wo", AccessWriteOnly},
+		{"@8,access=rw,default=1", AccessReadWrite},
+		{"@8,magic=0x1F,access=ro", AccessReadOnly},
+	}
+	for _, tt := range tests {
+		got, err := ParseTag(tt.tag)
+		if err != nil {
+			t.Fatalf("ParseTag(%q) unexpected error: %v", tt.tag, err)
+		}
+		if got.Direction != Fixed || got.Access != tt.access {
+			t.Errorf("ParseTag(%q) = %v access=%q, want fixed access=%q", tt.tag, got.Direction, got.Access, tt.access)
+		}
+	}
+
+	for _, tag := range []string{"@0,access=rx", "start-end,access=ro", "@0.2,bits=3,access=ro", "@0,reserve=4,access=ro",
+		"@0,get=f,access=wo", "@0,uuid,access=ro", "access=ro"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagHooks(t *testing.T) {
+	got, err := ParseTag("@12,get=computeCRC,set=checkCRC")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Offset != 12 || got.Get != "computeCRC" || got.Set != "checkCRC" {
+		t.Errorf("ParseTag() = @%d get=%s set=%s, want @12 get=computeCRC set=checkCRC",
+			got.Offset, got.Get, got.Set)
+	}
+
+	got, err = ParseTag("trailer,get=computeCRC")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if !got.Trailer || got.Get != "computeCRC" {
+		t.Errorf("ParseTag() trailer=%v get=%s, want trailer get=computeCRC", got.Trailer, got.Get)
+	}
+
+	for _, tag := range []string{"@0,get=", "@0,set=p.check", "@0,get=f,count=N", "trailer,default=1"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagCField(t *testing.T) {
+	// C members may be Go keywords
+	for _, member := range []string{"h_proto", "type"} {
+		got, err := ParseTag("@12,cfield=" + member)
+		if err != nil {
+			t.Fatalf("ParseTag() unexpected error: %v", err)
+		}
+		if got.Offset != 12 || got.CField != member {
+			t.Errorf("ParseTag() = @%d cfield=%s, want @12 cfield=%s", got.Offset, got.CField, member)
+		}
+	}
+	if _, err := ParseTag("@0,cfield=a.b"); err == nil {
+		t.Error("ParseTag(cfield=a.b) expected error, got nil")
+	}
+}
+
+func TestParseTagPresent(t *testing.T) {
+	got, err := ParseTag("@2,present=Present")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Offset != 2 || got.Direction != Fixed || got.Present != "Present" {
+		t.Errorf("ParseTag() = @%d %v present=%s, want @2 fixed present=Present", got.Offset, got.Direction, got.Present)
+	}
+
+	for _, tag := range []string{"@2,present=", "@2,present=a.b", "start-end,present=Present"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagCap(t *testing.T) {
+	for _, tag := range []string{"@8,cap=32,count=NumItems", "@8,count=NumItems,cap=32"} {
+		got, err := ParseTag(tag)
+		if err != nil {
+			t.Fatalf("ParseTag(%q) unexpected error: %v", tag, err)
+		}
+		if got.Offset != 8 || got.Direction != Fixed || got.Cap != 32 || got.Used != "NumItems" || got.CountField != "" {
+			t.Errorf("ParseTag(%q) = @%d %v cap=%d count=%s, want @8 fixed cap=32 count=NumItems",
+				tag, got.Offset, got.Direction, got.Cap, got.Used)
+		}
+	}
+
+	tests := []struct {
+		tag     string
+		wantErr string
+	}{
+		{"@8,cap=32", "cap=32 requires count="},
+		{"@8,cap=0,count=N", "cap must be a positive element count, got: 0"},
+		{"@8,cap=x,count=N", "cap must be a positive element count, got: x"},
+		{"@8,cap=4,count=a.b", "count= requires a field name"},
+		{"@8,cap=4,count=N,magic=1", "unknown inline slice parameter: magic=1"},
+		{"start-end,cap=4,count=N", ""},
+	}
+	for _, tt := range tests {
+		_, err := ParseTag(tt.tag)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseTag(%q) error = %v, want %q", tt.tag, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseTagRegion(t *testing.T) {
+	got, err := ParseTag("start-end,count=NumIdx,region=index")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != StartEnd || got.CountField != "NumIdx" || got.Group != "index" {
+		t.Errorf("ParseTag() = %v count=%s region=%s, want start-end count=NumIdx region=index",
+			got.Direction, got.CountField, got.Group)
+	}
+
+	if _, err := ParseTag("start-end,region="); err == nil {
+		t.Errorf("ParseTag() expected error for empty region name")
+	}
+}
+
+func TestParseTagMax(t *testing.T) {
+	got, err := ParseTag("@8,end-start,count=N,max=64")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != EndStart || got.StartAt != 8 || got.CountField != "N" || got.MaxCount != 64 {
+		t.Errorf("ParseTag() = %v @%d count=%s max=%d, want end-start @8 count=N max=64",
+			got.Direction, got.StartAt, got.CountField, got.MaxCount)
+	}
+
+	for _, tag := range []string{"start-end,count=N,max=0", "start-end,count=N,max=-1", "start-end,max=x"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagEncode(t *testing.T) {
+	got, err := ParseTag("start-end,count=N,encode=delta")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != StartEnd || got.CountField != "N" || got.Encode != EncodeDelta {
+		t.Errorf("ParseTag() = %v count=%s encode=%q, want start-end count=N encode=delta",
+			got.Direction, got.CountField, got.Encode)
+	}
+
+	got, err = ParseTag("from=Elements,offset=KeyOffset,size=KeySize,region=Data,encode=prefix")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.From != "Elements" || got.Encode != EncodePrefix {
+		t.Errorf("ParseTag() = from=%s encode=%q, want from=Elements encode=prefix", got.From, got.Encode)
+	}
+
+	for _, tag := range []string{
+		"start-end,count=N,encode=",
+		"start-end,count=N,encode=zigzag",
+		"start-end,count=N,encode=prefix", // Only indirect slices share prefixes
+		"from=Elements,offset=KeyOffset,size=KeySize,region=Data,encode=delta",
+	} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagElemAlign(t *testing.T) {
+	got, err := ParseTag("@16,start-end,count=N,elemalign=8")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != StartEnd || got.StartAt != 16 || got.CountField != "N" || got.ElemAlign != 8 {
+		t.Errorf("ParseTag() = %v @%d count=%s elemalign=%d, want start-end @16 count=N elemalign=8",
+			got.Direction, got.StartAt, got.CountField, got.ElemAlign)
+	}
+
+	for _, tag := range []string{"start-end,count=N,elemalign=0", "start-end,count=N,elemalign=6", "start-end,elemalign=x"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagStride(t *testing.T) {
+	got, err := ParseTag("@8,start-end,count=N,stride=16")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != StartEnd || got.StartAt != 8 || got.CountField != "N" || got.Stride != 16 {
+		t.Errorf("ParseTag() = %v @%d count=%s stride=%d, want start-end @8 count=N stride=16",
+			got.Direction, got.StartAt, got.CountField, got.Stride)
+	}
+
+	for _, tag := range []string{"start-end,count=N,stride=0", "start-end,count=N,stride=-4", "start-end,stride=x"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagArrange(t *testing.T) {
+	got, err := ParseTag("@8,start-end,count=N,arrange=columnar")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != StartEnd || got.StartAt != 8 || got.CountField != "N" || got.Arrange != ArrangeColumnar {
+		t.Errorf("ParseTag() = %v @%d count=%s arrange=%q, want start-end @8 count=N arrange=columnar",
+			got.Direction, got.StartAt, got.CountField, got.Arrange)
+	}
+
+	got, err = ParseTag("parallel=Keys")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Parallel != "Keys" || got.Direction != StartEnd || got.Offset != -1 {
+		t.Errorf("ParseTag() = parallel=%q %v @%d, want parallel=Keys start-end", got.Parallel, got.Direction, got.Offset)
+	}
+
+	for _, tag := range []string{"start-end,count=N,arrange=rows", "parallel=", "parallel=Keys,count=N"} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error, got nil", tag)
+		}
+	}
+}
+
+func TestParseTagSentinel(t *testing.T) {
+	got, err := ParseTag("@16,start-end,sentinel")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Direction != StartEnd || got.StartAt != 16 || !got.Sentinel || got.CountField != "" {
+		t.Errorf("ParseTag() = %v @%d sentinel=%v count=%q, want start-end @16 sentinel=true",
+			got.Direction, got.StartAt, got.Sentinel, got.CountField)
+	}
+}
+
+func TestParseTagOffsetMode(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"from=Elems,offset=Off,size=Len,region=Data", OffsetAfterMetadata},
+		{"from=Elems,offset=Off,size=Len,region=Data,offsetmode=after-metadata", OffsetAfterMetadata},
+		{"from=Elems,offset=Off,size=Len,region=Data,offsetmode=page", OffsetPage},
+		{"from=Elems,offset=Off,size=Len,region=Data,offsetmode=region", OffsetRegion},
+	}
+	for _, tt := range tests {
+		got, err := ParseTag(tt.tag)
+		if err != nil {
+			t.Fatalf("ParseTag(%q) unexpected error: %v", tt.tag, err)
+		}
+		if got.OffsetMode != tt.want {
+			t.Errorf("ParseTag(%q).OffsetMode = %q, want %q", tt.tag, got.OffsetMode, tt.want)
+		}
+	}
+
+	if _, err := ParseTag("from=Elems,offset=Off,size=Len,region=Data,offsetmode=field"); err == nil {
+		t.Errorf("ParseTag() expected error for unknown offsetmode")
+	}
+}
+
+func TestParseTagOffsetModeLegacy(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"absolute", OffsetPage},
+		{"relative", OffsetAfterMetadata},
+	}
+	for _, tt := range tests {
+		got, err := ParseTag("from=Elems,offset=Off,size=Len,region=Data,offsetmode=" + tt.mode)
+		if err != nil {
+			t.Fatalf("ParseTag(offsetmode=%s) unexpected error: %v", tt.mode, err)
+		}
+		if got.From != "Elems" || got.OffsetField != "Off" || got.SizeField != "Len" || got.Region != "Data" {
+			t.Errorf("ParseTag(offsetmode=%s) = from=%s offset=%s size=%s region=%s", tt.mode,
+				got.From, got.OffsetField, got.SizeField, got.Region)
+		}
+		if got.OffsetMode != tt.want {
+			t.Errorf("ParseTag(offsetmode=%s).OffsetMode = %q, want %q", tt.mode, got.OffsetMode, tt.want)
+		}
+	}
+}
+
+func TestParseTagExtents(t *testing.T) {
+	got, err := ParseTag("extents=Elems,page=OvfPage,offset=OvfOff,size=OvfLen")
+	if err != nil {
+		t.Fatalf("ParseTag() unexpected error: %v", err)
+	}
+	if got.Extents != "Elems" || got.PageField != "OvfPage" || got.OffsetField != "OvfOff" || got.SizeField != "OvfLen" {
+		t.Errorf("ParseTag() = extents=%s page=%s offset=%s size=%s", got.Extents, got.PageField, got.OffsetField, got.SizeField)
+	}
+	if got.From != "" || got.Region != "" {
+		t.Errorf("ParseTag() set from=%q region=%q, want neither", got.From, got.Region)
+	}
+
+	for _, tag := range []string{
+		"extents=Elems,offset=OvfOff,size=OvfLen",
+		"extents=Elems,page=OvfPage,offset=OvfOff,size=OvfLen,region=Data",
+		"extents=Elems,page",
+	} {
+		if _, err := ParseTag(tag); err == nil {
+			t.Errorf("ParseTag(%q) expected error", tag)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"strings"
 	"testing"
 )
 
@@ -23,6 +24,26 @@ type Page struct {
 }`,
 			wantError: false,
 		},
+		{
+			name: "copy mode with retain - requires buf []byte",
+			code: `package test
+type Page struct {
+	buf    []byte
+	Header uint16
+	Body   []byte
+}`,
+			wantError: false,
+		},
+		{
+			name: "copy mode with retain - missing buf",
+			code: `package test
+type Page struct {
+	Header uint16
+	Body   []byte
+}`,
+			wantError: true,
+			errMsg:    "retain=true requires field: buf []byte",
+		},
 		{
 			name: "zerocopy without align - requires buf [size]byte",
 			code: `package test
@@ -99,6 +120,15 @@ type Page struct {
 			wantError: true,
 			errMsg:    "buf field must be []byte when using align or allocator, got [4096]byte",
 		},
+		{
+			name: "zerocopy with raw - no buffer fields",
+			code: `package test
+type Page struct {
+	Header uint16
+	Body   []byte
+}`,
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -138,6 +168,9 @@ type Page struct {
 			// Set mode based on test name
 			if tt.name == "copy mode - no requirements" {
 				anno.Mode = "copy"
+			} else if strings.HasPrefix(tt.name, "copy mode with retain") {
+				anno.Mode = "copy"
+				anno.Retain = true
 			} else {
 				anno.Mode = "zerocopy"
 				// Set align if test mentions it
@@ -147,6 +180,9 @@ type Page struct {
 					tt.name == "zerocopy with align - wrong buf type" {
 					anno.Align = 512
 				}
+				if tt.name == "zerocopy with raw - no buffer fields" {
+					anno.Raw = "PageRaw"
+				}
 			}
 
 			// Validate
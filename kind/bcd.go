@@ -0,0 +1,95 @@
+package kind
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// bcd encodes an integer in packed binary-coded decimal: two decimal digits a
+// byte, the most significant first, each digit in a nibble. The field holds
+// 2*width digits, width= bytes wide (default: the size of its type), and values
+// with more digits, or negative ones, are out of range. Bytes holding a nibble
+// above 9 decode to a value out of range, so UnmarshalLayout refuses them.
+var bcd = Kind{
+	Name: "bcd",
+	Size: func(f Field) (int, error) {
+		q, err := parseBCD(f)
+		if err != nil {
+			return 0, err
+		}
+		return q.width, nil
+	},
+	Valid: func(f Field, value string) string {
+		q, _ := parseBCD(f)
+		if q.signed {
+			return fmt.Sprintf("%s >= 0 && %s <= %s", value, value, q.max())
+		}
+		return fmt.Sprintf("%s <= %s", value, q.max())
+	},
+	Marshal: func(f Field, dst, value string) string {
+		q, _ := parseBCD(f)
+		return fmt.Sprintf("n := uint64(%s)\nfor i := %d; i >= 0; i-- {\n\t%s[i] = byte(n/10%%10)<<4 | byte(n%%10)\n\tn /= 100\n}\n",
+			value, q.width-1, dst)
+	},
+	Unmarshal: func(f Field, src, value string) string {
+		q, _ := parseBCD(f)
+		return fmt.Sprintf("var n uint64\nfor _, b := range %s {\n\tif b>>4 > 9 || b&0x0f > 9 {\n\t\tn = %s // Not BCD: one past the largest value, refused as out of range\n\t\tbreak\n\t}\n\tn = n*100 + uint64(b>>4)*10 + uint64(b&0x0f)\n}\n%s = %s(n)\n",
+			src, q.limit(), value, f.GoType)
+	},
+}
+
+// bcdFormat is a parsed bcd field
+type bcdFormat struct {
+	width  int  // Encoded bytes, two digits each
+	signed bool // The field type is signed, so negative values are refused too
+}
+
+// limit returns 10^digits, one past the largest value the field encodes
+func (q bcdFormat) limit() string {
+	return "1" + strings.Repeat("0", 2*q.width)
+}
+
+// max returns the largest value the field encodes, all digits 9
+func (q bcdFormat) max() string {
+	return strings.Repeat("9", 2*q.width)
+}
+
+// bcdDigits are the exponents of the largest power of ten each integer type
+// holds, bounding the digits of its bcd fields
+var bcdDigits = map[string]int{
+	"uint8": 2, "uint16": 4, "uint32": 9, "uint64": 19,
+	"int8": 2, "int16": 4, "int32": 9, "int64": 18,
+}
+
+// parseBCD parses and checks the parameters of a bcd field against its type.
+// One past the largest value must fit the type too, to decode bytes that aren't
+// BCD into a value out of range.
+func parseBCD(f Field) (bcdFormat, error) {
+	var q bcdFormat
+	digits, ok := bcdDigits[f.GoType]
+	if !ok {
+		return q, fmt.Errorf("requires a sized integer type, e.g. uint32, got %s", f.GoType)
+	}
+	for key := range f.Params {
+		if key != "width" {
+			return q, fmt.Errorf("unknown parameter: %s", key)
+		}
+	}
+
+	q.signed = strings.HasPrefix(f.GoType, "int")
+	size, _ := strconv.Atoi(strings.TrimLeft(f.GoType, "uint"))
+	q.width = size / 8
+	if width, ok := f.Params["width"]; ok {
+		n, err := strconv.Atoi(width)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("width must be a positive number of bytes, got: %s", width)
+		}
+		q.width = n
+	}
+	if 2*q.width > digits {
+		return q, fmt.Errorf("width=%d holds %d digits, more than %s has room for; use at most width=%d",
+			q.width, 2*q.width, f.GoType, digits/2)
+	}
+	return q, nil
+}
@@ -0,0 +1,59 @@
+package kind
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBCDSize(t *testing.T) {
+	tests := []struct {
+		goType  string
+		params  map[string]string
+		want    int
+		wantErr string
+	}{
+		{"uint8", nil, 1, ""},
+		{"uint32", nil, 4, ""},
+		{"int64", nil, 8, ""},
+		{"uint64", map[string]string{"width": "6"}, 6, ""},
+		{"uint64", map[string]string{"width": "9"}, 9, ""},
+		{"uint64", map[string]string{"width": "10"}, 0, "width=10 holds 20 digits, more than uint64 has room for; use at most width=9"},
+		{"uint32", map[string]string{"width": "5"}, 0, "use at most width=4"},
+		{"uint16", map[string]string{"width": "0"}, 0, "width must be a positive number of bytes"},
+		{"int", nil, 0, "requires a sized integer type"},
+		{"float64", nil, 0, "requires a sized integer type"},
+		{"uint32", map[string]string{"scale": "2"}, 0, "unknown parameter: scale"},
+	}
+	for _, tt := range tests {
+		got, err := bcd.Size(Field{Name: "Amount", GoType: tt.goType, Params: tt.params})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Size(%s, %v) error = %v, want %q", tt.goType, tt.params, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Size(%s, %v) = %d, %v, want %d", tt.goType, tt.params, got, err, tt.want)
+		}
+	}
+}
+
+func TestBCDCode(t *testing.T) {
+	amount := Field{Name: "Amount", GoType: "uint64", Params: map[string]string{"width": "6"}}
+	pin := Field{Name: "PIN", GoType: "int16"}
+
+	checks := []struct {
+		got, want string
+	}{
+		{bcd.Valid(amount, "p.Amount"), "p.Amount <= 999999999999"},
+		{bcd.Valid(pin, "p.PIN"), "p.PIN >= 0 && p.PIN <= 9999"},
+		{bcd.Marshal(amount, "buf[0:6]", "p.Amount"), "n := uint64(p.Amount)\nfor i := 5; i >= 0; i-- {\n\tbuf[0:6][i] = byte(n/10%10)<<4 | byte(n%10)\n\tn /= 100\n}\n"},
+		{bcd.Unmarshal(pin, "buf[6:8]", "p.PIN"), "for _, b := range buf[6:8] {\n\tif b>>4 > 9 || b&0x0f > 9 {\n\t\tn = 10000 //"},
+		{bcd.Unmarshal(pin, "buf[6:8]", "p.PIN"), "\tn = n*100 + uint64(b>>4)*10 + uint64(b&0x0f)\n}\np.PIN = int16(n)\n"},
+	}
+	for _, c := range checks {
+		if !strings.Contains(c.got, c.want) {
+			t.Errorf("Generated %q, want %q", c.got, c.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package kind
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The built-in kinds cover common network, identifier and numeric formats
+func init() {
+	Register(ipAddr)
+	Register(mac)
+	Register(uuid)
+	Register(fixed)
+	Register(bcd)
+	Register(transform)
+}
+
+// ipAddr encodes a netip.Addr in width= bytes: 4 for IPv4 only, or 16 (default),
+// storing IPv4 addresses IPv4-mapped. The zero Addr encodes as zeros.
+var ipAddr = Kind{
+	Name: "ipaddr",
+	Size: func(f Field) (int, error) {
+		if f.GoType != "netip.Addr" {
+			return 0, fmt.Errorf("requires netip.Addr, got %s", f.GoType)
+		}
+		for key := range f.Params {
+			if key != "width" {
+				return 0, fmt.Errorf("unknown parameter: %s", key)
+			}
+		}
+		switch f.Params["width"] {
+		case "4":
+			return 4, nil
+		case "", "16":
+			return 16, nil
+		}
+		return 0, fmt.Errorf("width must be 4 or 16, got: %s", f.Params["width"])
+	},
+	Valid: func(f Field, value string) string {
+		if f.Params["width"] == "4" {
+			return fmt.Sprintf("!%s.IsValid() || %s.Unmap().Is4()", value, value)
+		}
+		return fmt.Sprintf("%s.Zone() == \"\"", value) // As16 drops zones
+	},
+	Marshal: func(f Field, dst, value string) string {
+		as := "As16()"
+		if f.Params["width"] == "4" {
+			as = "Unmap().As4()"
+		}
+		return fmt.Sprintf("if !%s.IsValid() {\n\tclear(%s)\n} else {\n\tb := %s.%s\n\tcopy(%s, b[:])\n}\n",
+			value, dst, value, as, dst)
+	},
+	Unmarshal: func(f Field, src, value string) string {
+		if f.Params["width"] == "4" {
+			return fmt.Sprintf("%s = netip.AddrFrom4([4]byte(%s))\n", value, src)
+		}
+		return fmt.Sprintf("%s = netip.AddrFrom16([16]byte(%s)).Unmap()\n", value, src)
+	},
+	Imports: []string{"net/netip"},
+}
+
+// mac encodes a [6]byte MAC address as is, adding accessors in the colon-separated
+// hexadecimal form: <Field>String and Set<Field>String
+var mac = Kind{
+	Name: "mac",
+	Size: func(f Field) (int, error) {
+		if f.GoType != "[6]byte" {
+			return 0, fmt.Errorf("requires [6]byte, got %s", f.GoType)
+		}
+		if len(f.Params) > 0 {
+			return 0, fmt.Errorf("takes no parameters")
+		}
+		return 6, nil
+	},
+	Marshal: func(f Field, dst, value string) string {
+		return fmt.Sprintf("copy(%s, %s[:])\n", dst, value)
+	},
+	Unmarshal: func(f Field, src, value string) string {
+		return fmt.Sprintf("copy(%s[:], %s)\n", value, src)
+	},
+	Methods: func(f Field, a Accessor) string {
+		return fmt.Sprintf(`// %sString returns %s as colon-separated hexadecimal, e.g. 00:1a:2b:3c:4d:5e
+func (p *%s) %sString() string {
+	addr := %s
+	return net.HardwareAddr(addr[:]).String()
+}
+
+// Set%sString sets %s from a 6-byte MAC address in any form net.ParseMAC accepts
+func (p *%s) Set%sString(s string) error {
+	addr, err := net.ParseMAC(s)
+	if err != nil {
+		return fmt.Errorf("%s: %%w", err)
+	}
+	if len(addr) != 6 {
+		return fmt.Errorf("%s: %%q is a %%d-byte address, want 6: %%w", s, len(addr), ErrOutOfRange)
+	}
+	%s
+	return nil
+}
+`, f.Name, f.Name, a.Type, f.Name, a.Get, f.Name, f.Name, a.Type, f.Name, f.Name, f.Name,
+			fmt.Sprintf(a.Set, "[6]byte(addr)"))
+	},
+	Imports: []string{"net"},
+}
+
+// uuid keeps a [16]byte UUID as is, adding accessors in the canonical 8-4-4-4-12
+// hexadecimal form: <Field>String and Set<Field>String. type=T also adds
+// <Field>UUID and Set<Field>UUID converting to and from T, a UUID type over
+// [16]byte such as github.com/google/uuid.UUID. verify refuses UUIDs other than
+// the nil UUID without the RFC 9562 variant bits (10 in the top bits of byte 8).
+var uuid = Kind{
+	Name: "uuid",
+	Size: func(f Field) (int, error) {
+		if f.GoType != "[16]byte" {
+			return 0, fmt.Errorf("requires [16]byte, got %s", f.GoType)
+		}
+		for key, value := range f.Params {
+			switch {
+			case key == "type" && value != "":
+			case key == "verify" && value == "":
+			default:
+				return 0, fmt.Errorf("unknown parameter: %s", key)
+			}
+		}
+		return 16, nil
+	},
+	Valid: func(f Field, value string) string {
+		if _, ok := f.Params["verify"]; !ok {
+			return ""
+		}
+		return fmt.Sprintf("%s == [16]byte{} || %s[8]&0xc0 == 0x80", value, value)
+	},
+	Marshal: func(f Field, dst, value string) string {
+		return fmt.Sprintf("copy(%s, %s[:])\n", dst, value)
+	},
+	Unmarshal: func(f Field, src, value string) string {
+		return fmt.Sprintf("copy(%s[:], %s)\n", value, src)
+	},
+	Methods: func(f Field, a Accessor) string {
+		var code strings.Builder
+		code.WriteString(fmt.Sprintf(`// %sString returns %s in the canonical form, e.g. 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+func (p *%s) %sString() string {
+	u := %s
+	return fmt.Sprintf("%%x-%%x-%%x-%%x-%%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// Set%sString sets %s from the canonical form, in either case
+func (p *%s) Set%sString(s string) error {
+	var u [16]byte
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return fmt.Errorf("%s: %%q is not a UUID: %%w", s, ErrOutOfRange)
+	}
+	if _, err := hex.Decode(u[:], []byte(s[0:8]+s[9:13]+s[14:18]+s[19:23]+s[24:36])); err != nil {
+		return fmt.Errorf("%s: %%q is not a UUID: %%w", s, ErrOutOfRange)
+	}
+`, f.Name, f.Name, a.Type, f.Name, a.Get, f.Name, f.Name, a.Type, f.Name, f.Name, f.Name))
+		if _, ok := f.Params["verify"]; ok {
+			code.WriteString("\tif u != [16]byte{} && u[8]&0xc0 != 0x80 {\n")
+			code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: %%q is not an RFC 9562 UUID: %%w\", s, ErrOutOfRange)\n", f.Name))
+			code.WriteString("\t}\n")
+		}
+		code.WriteString(fmt.Sprintf("\t%s\n", fmt.Sprintf(a.Set, "u")))
+		code.WriteString("\treturn nil\n")
+		code.WriteString("}\n")
+
+		if typ := f.Params["type"]; typ != "" {
+			code.WriteString(fmt.Sprintf(`
+// %sUUID returns %s as a %s
+func (p *%s) %sUUID() %s {
+	return %s(%s)
+}
+
+// Set%sUUID sets %s from a %s
+func (p *%s) Set%sUUID(u %s) error {
+`, f.Name, f.Name, typ, a.Type, f.Name, typ, typ, a.Get, f.Name, f.Name, typ, a.Type, f.Name, typ))
+			if _, ok := f.Params["verify"]; ok {
+				code.WriteString("\tif u != (" + typ + "{}) && u[8]&0xc0 != 0x80 {\n")
+				code.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"%s: %%v is not an RFC 9562 UUID: %%w\", u, ErrOutOfRange)\n", f.Name))
+				code.WriteString("\t}\n")
+			}
+			code.WriteString(fmt.Sprintf("\t%s\n", fmt.Sprintf(a.Set, "[16]byte(u)")))
+			code.WriteString("\treturn nil\n")
+			code.WriteString("}\n")
+		}
+		return code.String()
+	},
+	Imports: []string{"encoding/hex"},
+}
@@ -0,0 +1,139 @@
+package kind
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIPAddrSize(t *testing.T) {
+	tests := []struct {
+		goType  string
+		params  map[string]string
+		want    int
+		wantErr string
+	}{
+		{"netip.Addr", nil, 16, ""},
+		{"netip.Addr", map[string]string{"width": "16"}, 16, ""},
+		{"netip.Addr", map[string]string{"width": "4"}, 4, ""},
+		{"netip.Addr", map[string]string{"width": "6"}, 0, "width must be 4 or 16"},
+		{"netip.Addr", map[string]string{"zone": "eth0"}, 0, "unknown parameter: zone"},
+		{"[4]byte", nil, 0, "requires netip.Addr"},
+	}
+	for _, tt := range tests {
+		got, err := ipAddr.Size(Field{Name: "Src", GoType: tt.goType, Params: tt.params})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Size(%s, %v) error = %v, want %q", tt.goType, tt.params, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Size(%s, %v) = %d, %v, want %d", tt.goType, tt.params, got, err, tt.want)
+		}
+	}
+}
+
+func TestIPAddrCode(t *testing.T) {
+	v4 := Field{Name: "Src", GoType: "netip.Addr", Params: map[string]string{"width": "4"}}
+	v6 := Field{Name: "Dst", GoType: "netip.Addr"}
+
+	checks := []struct {
+		got, want string
+	}{
+		{ipAddr.Valid(v4, "p.Src"), "!p.Src.IsValid() || p.Src.Unmap().Is4()"},
+		{ipAddr.Valid(v6, "p.Dst"), "p.Dst.Zone() == \"\""},
+		{ipAddr.Marshal(v4, "buf[0:4]", "p.Src"), "if !p.Src.IsValid() {\n\tclear(buf[0:4])\n} else {\n\tb := p.Src.Unmap().As4()\n\tcopy(buf[0:4], b[:])\n}\n"},
+		{ipAddr.Marshal(v6, "buf[4:20]", "p.Dst"), "\tb := p.Dst.As16()\n"},
+		{ipAddr.Unmarshal(v4, "buf[0:4]", "p.Src"), "p.Src = netip.AddrFrom4([4]byte(buf[0:4]))\n"},
+		{ipAddr.Unmarshal(v6, "buf[4:20]", "p.Dst"), "p.Dst = netip.AddrFrom16([16]byte(buf[4:20])).Unmap()\n"},
+	}
+	for _, c := range checks {
+		if !strings.Contains(c.got, c.want) {
+			t.Errorf("Generated %q, want %q", c.got, c.want)
+		}
+	}
+}
+
+func TestMAC(t *testing.T) {
+	field := Field{Name: "HW", GoType: "[6]byte"}
+	if size, err := mac.Size(field); err != nil || size != 6 {
+		t.Errorf("Size() = %d, %v, want 6", size, err)
+	}
+	if _, err := mac.Size(Field{Name: "HW", GoType: "[8]byte"}); err == nil {
+		t.Errorf("Size([8]byte) expected error")
+	}
+
+	code := mac.Methods(field, Accessor{Type: "Frame", Get: "p.GetHW()", Set: "p.SetHW(%s)"})
+	expectedParts := []string{
+		"func (p *Frame) HWString() string {\n\taddr := p.GetHW()\n\treturn net.HardwareAddr(addr[:]).String()\n}\n",
+		"func (p *Frame) SetHWString(s string) error {\n\taddr, err := net.ParseMAC(s)\n",
+		"return fmt.Errorf(\"HW: %q is a %d-byte address, want 6: %w\", s, len(addr), ErrOutOfRange)",
+		"\tp.SetHW([6]byte(addr))\n\treturn nil\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
+
+func TestUUIDSize(t *testing.T) {
+	tests := []struct {
+		goType  string
+		params  map[string]string
+		wantErr string
+	}{
+		{"[16]byte", nil, ""},
+		{"[16]byte", map[string]string{"type": "uuid.UUID", "verify": ""}, ""},
+		{"[16]byte", map[string]string{"verify": "true"}, "unknown parameter: verify"},
+		{"[16]byte", map[string]string{"type": ""}, "unknown parameter: type"},
+		{"[16]byte", map[string]string{"version": "4"}, "unknown parameter: version"},
+		{"[]byte", nil, "requires [16]byte"},
+	}
+	for _, tt := range tests {
+		got, err := uuid.Size(Field{Name: "ID", GoType: tt.goType, Params: tt.params})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Size(%s, %v) error = %v, want %q", tt.goType, tt.params, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != 16 {
+			t.Errorf("Size(%s, %v) = %d, %v, want 16", tt.goType, tt.params, got, err)
+		}
+	}
+}
+
+func TestUUIDCode(t *testing.T) {
+	plain := Field{Name: "ID", GoType: "[16]byte"}
+	typed := Field{Name: "ID", GoType: "[16]byte", Params: map[string]string{"type": "uuid.UUID", "verify": ""}}
+
+	if valid := uuid.Valid(plain, "p.ID"); valid != "" {
+		t.Errorf("Valid() without verify = %q, want no check", valid)
+	}
+	if valid := uuid.Valid(typed, "p.ID"); valid != "p.ID == [16]byte{} || p.ID[8]&0xc0 == 0x80" {
+		t.Errorf("Valid() with verify = %q", valid)
+	}
+
+	code := uuid.Methods(plain, Accessor{Type: "Row", Get: "p.ID", Set: "p.ID = %s"})
+	if strings.Contains(code, "UUID()") || strings.Contains(code, "RFC 9562") {
+		t.Errorf("Generated typed or verifying methods without type= or verify:\n%s", code)
+	}
+
+	code = uuid.Methods(typed, Accessor{Type: "Row", Get: "p.GetID()", Set: "_ = p.SetID(%s)"})
+	expectedParts := []string{
+		"func (p *Row) IDString() string {\n\tu := p.GetID()\n",
+		"func (p *Row) SetIDString(s string) error {\n",
+		"hex.Decode(u[:], []byte(s[0:8]+s[9:13]+s[14:18]+s[19:23]+s[24:36]))",
+		"return fmt.Errorf(\"ID: %q is not an RFC 9562 UUID: %w\", s, ErrOutOfRange)",
+		"\t_ = p.SetID(u)\n\treturn nil\n",
+		"func (p *Row) IDUUID() uuid.UUID {\n\treturn uuid.UUID(p.GetID())\n}\n",
+		"func (p *Row) SetIDUUID(u uuid.UUID) error {\n\tif u != (uuid.UUID{}) && u[8]&0xc0 != 0x80 {\n",
+		"\t_ = p.SetID([16]byte(u))\n\treturn nil\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package kind
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fixed encodes a number in fixed=I.F binary fixed point: an I+F bit two's
+// complement integer (unsigned with the bare unsigned parameter) counting units
+// of 2^-F, in the layout's byte order. Float fields convert on encode and decode,
+// rounding to the nearest unit and refusing NaN and values out of range. Integer
+// fields keep the raw integer and gain <Field>Float and Set<Field>Float.
+var fixed = Kind{
+	Name: "fixed",
+	Size: func(f Field) (int, error) {
+		q, err := parseFixed(f)
+		if err != nil {
+			return 0, err
+		}
+		return q.bits / 8, nil
+	},
+	Valid: func(f Field, value string) string {
+		q, _ := parseFixed(f)
+		if !q.float {
+			return "" // Every raw integer is a fixed-point value
+		}
+		n := fmt.Sprintf("math.Round(math.Ldexp(float64(%s), %d))", value, q.fracBits)
+		return q.inRange(n)
+	},
+	Marshal: func(f Field, dst, value string) string {
+		q, _ := parseFixed(f)
+		if !q.float {
+			return fmt.Sprintf("%s.PutUint%d(%s, uint%d(%s))\n", f.ByteOrder, q.bits, dst, q.bits, value)
+		}
+		return fmt.Sprintf("n := math.Round(math.Ldexp(float64(%s), %d))\n%s.PutUint%d(%s, uint%d(%s(n)))\n",
+			value, q.fracBits, f.ByteOrder, q.bits, dst, q.bits, q.raw())
+	},
+	Unmarshal: func(f Field, src, value string) string {
+		q, _ := parseFixed(f)
+		raw := fmt.Sprintf("%s(%s.Uint%d(%s))", q.raw(), f.ByteOrder, q.bits, src)
+		if !q.float {
+			return fmt.Sprintf("%s = %s\n", value, raw)
+		}
+		decoded := fmt.Sprintf("math.Ldexp(float64(%s), -%d)", raw, q.fracBits)
+		if f.GoType == "float32" {
+			decoded = "float32(" + decoded + ")"
+		}
+		return fmt.Sprintf("%s = %s\n", value, decoded)
+	},
+	Methods: func(f Field, a Accessor) string {
+		q, _ := parseFixed(f)
+		if q.float {
+			return ""
+		}
+		format := f.Params["fixed"]
+		return fmt.Sprintf(`// %sFloat returns %s, in %s fixed point, as a float64
+func (p *%s) %sFloat() float64 {
+	return math.Ldexp(float64(%s), -%d)
+}
+
+// Set%sFloat sets %s to v in %s fixed point, rounded to the nearest 2^-%d
+func (p *%s) Set%sFloat(v float64) error {
+	n := math.Round(math.Ldexp(v, %d))
+	if !(%s) {
+		return fmt.Errorf("%s: %%v is outside %s fixed point: %%w", v, ErrOutOfRange)
+	}
+	%s
+	return nil
+}
+`, f.Name, f.Name, format, a.Type, f.Name, a.Get, q.fracBits,
+			f.Name, f.Name, format, q.fracBits, a.Type, f.Name, q.fracBits,
+			q.inRange("n"), f.Name, format, fmt.Sprintf(a.Set, fmt.Sprintf("%s(n)", q.raw())))
+	},
+	Imports:     []string{"encoding/binary", "math"},
+	ByteOrdered: true,
+}
+
+// fixedFormat is a parsed fixed=I.F field
+type fixedFormat struct {
+	fracBits int  // F, the bits after the binary point
+	bits     int  // I+F, the encoded width
+	signed   bool // Two's complement, unless unsigned
+	float    bool // The field is a float converted on encode and decode
+}
+
+// raw returns the Go integer type of the encoded value, e.g. int32
+func (q fixedFormat) raw() string {
+	if q.signed {
+		return fmt.Sprintf("int%d", q.bits)
+	}
+	return fmt.Sprintf("uint%d", q.bits)
+}
+
+// inRange returns an expression reporting whether n, a rounded float64 count of
+// units, fits the encoding. NaN compares false.
+func (q fixedFormat) inRange(n string) string {
+	if q.signed {
+		return fmt.Sprintf("%s >= -0x1p%d && %s < 0x1p%d", n, q.bits-1, n, q.bits-1)
+	}
+	return fmt.Sprintf("%s >= 0 && %s < 0x1p%d", n, n, q.bits)
+}
+
+// parseFixed parses and checks the parameters of a fixed field against its type.
+// Float fields must hold every encoded value exactly, so that decoded values
+// re-encode: float64 up to 32 bits, float32 up to 16.
+func parseFixed(f Field) (fixedFormat, error) {
+	var q fixedFormat
+	for key, value := range f.Params {
+		switch {
+		case key == "fixed":
+		case key == "unsigned" && value == "":
+		default:
+			return q, fmt.Errorf("unknown parameter: %s", key)
+		}
+	}
+
+	format, ok := f.Params["fixed"]
+	if !ok {
+		return q, fmt.Errorf("requires fixed=I.F, e.g. fixed=16.16")
+	}
+	intStr, fracStr, _ := strings.Cut(format, ".")
+	intBits, err1 := strconv.Atoi(intStr)
+	fracBits, err2 := strconv.Atoi(fracStr)
+	if err1 != nil || err2 != nil || intBits < 0 || fracBits < 0 {
+		return q, fmt.Errorf("fixed must be I.F integer and fraction bits, got: %s", format)
+	}
+	q.fracBits = fracBits
+	q.bits = intBits + fracBits
+	if q.bits != 16 && q.bits != 32 && q.bits != 64 {
+		return q, fmt.Errorf("fixed=%s must be 16, 32 or 64 bits wide, got %d", format, q.bits)
+	}
+	_, unsigned := f.Params["unsigned"]
+	q.signed = !unsigned
+
+	switch f.GoType {
+	case "float64", "float32":
+		q.float = true
+		if exact := map[string]int{"float64": 32, "float32": 16}[f.GoType]; q.bits > exact {
+			return q, fmt.Errorf("%s can't hold every fixed=%s value exactly; use an %s field", f.GoType, format, q.raw())
+		}
+	case q.raw():
+	default:
+		return q, fmt.Errorf("fixed=%s requires float64, float32 or %s, got %s", format, q.raw(), f.GoType)
+	}
+	return q, nil
+}
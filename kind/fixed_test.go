@@ -0,0 +1,83 @@
+package kind
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixedSize(t *testing.T) {
+	tests := []struct {
+		goType  string
+		params  map[string]string
+		want    int
+		wantErr string
+	}{
+		{"float64", map[string]string{"fixed": "16.16"}, 4, ""},
+		{"float64", map[string]string{"fixed": "0.16", "unsigned": ""}, 2, ""},
+		{"float32", map[string]string{"fixed": "8.8"}, 2, ""},
+		{"int64", map[string]string{"fixed": "32.32"}, 8, ""},
+		{"uint32", map[string]string{"fixed": "16.16", "unsigned": ""}, 4, ""},
+		{"float64", nil, 0, "requires fixed=I.F"},
+		{"float64", map[string]string{"fixed": "16"}, 0, "fixed must be I.F"},
+		{"float64", map[string]string{"fixed": "4.4"}, 0, "must be 16, 32 or 64 bits wide"},
+		{"float64", map[string]string{"fixed": "32.32"}, 0, "use an int64 field"},
+		{"float32", map[string]string{"fixed": "16.16"}, 0, "use an int32 field"},
+		{"uint32", map[string]string{"fixed": "16.16"}, 0, "requires float64, float32 or int32"},
+		{"float64", map[string]string{"fixed": "16.16", "scale": "2"}, 0, "unknown parameter: scale"},
+		{"float64", map[string]string{"fixed": "16.16", "unsigned": "true"}, 0, "unknown parameter: unsigned"},
+	}
+	for _, tt := range tests {
+		got, err := fixed.Size(Field{Name: "Price", GoType: tt.goType, Params: tt.params})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Size(%s, %v) error = %v, want %q", tt.goType, tt.params, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Size(%s, %v) = %d, %v, want %d", tt.goType, tt.params, got, err, tt.want)
+		}
+	}
+}
+
+func TestFixedCode(t *testing.T) {
+	price := Field{Name: "Price", GoType: "float64", Params: map[string]string{"fixed": "16.16"}, ByteOrder: "binary.BigEndian"}
+	temp := Field{Name: "Temp", GoType: "float32", Params: map[string]string{"fixed": "8.8", "unsigned": ""}, ByteOrder: "binary.LittleEndian"}
+	total := Field{Name: "Total", GoType: "int64", Params: map[string]string{"fixed": "32.32"}, ByteOrder: "binary.LittleEndian"}
+
+	checks := []struct {
+		got, want string
+	}{
+		{fixed.Valid(price, "p.Price"), "math.Round(math.Ldexp(float64(p.Price), 16)) >= -0x1p31 && math.Round(math.Ldexp(float64(p.Price), 16)) < 0x1p31"},
+		{fixed.Valid(temp, "p.Temp"), "math.Round(math.Ldexp(float64(p.Temp), 8)) >= 0 && math.Round(math.Ldexp(float64(p.Temp), 8)) < 0x1p16"},
+		{fixed.Valid(total, "p.Total"), ""},
+		{fixed.Marshal(price, "buf[0:4]", "p.Price"), "n := math.Round(math.Ldexp(float64(p.Price), 16))\nbinary.BigEndian.PutUint32(buf[0:4], uint32(int32(n)))\n"},
+		{fixed.Marshal(temp, "buf[4:6]", "p.Temp"), "binary.LittleEndian.PutUint16(buf[4:6], uint16(uint16(n)))\n"},
+		{fixed.Marshal(total, "buf[6:14]", "p.Total"), "binary.LittleEndian.PutUint64(buf[6:14], uint64(p.Total))\n"},
+		{fixed.Unmarshal(price, "buf[0:4]", "p.Price"), "p.Price = math.Ldexp(float64(int32(binary.BigEndian.Uint32(buf[0:4]))), -16)\n"},
+		{fixed.Unmarshal(temp, "buf[4:6]", "p.Temp"), "p.Temp = float32(math.Ldexp(float64(uint16(binary.LittleEndian.Uint16(buf[4:6]))), -8))\n"},
+		{fixed.Unmarshal(total, "buf[6:14]", "p.Total"), "p.Total = int64(binary.LittleEndian.Uint64(buf[6:14]))\n"},
+	}
+	for _, c := range checks {
+		if !strings.Contains(c.got, c.want) {
+			t.Errorf("Generated %q, want %q", c.got, c.want)
+		}
+	}
+
+	if code := fixed.Methods(price, Accessor{Type: "Quote", Get: "p.Price", Set: "p.Price = %s"}); code != "" {
+		t.Errorf("Methods() for a float field = %q, want none", code)
+	}
+	code := fixed.Methods(total, Accessor{Type: "Quote", Get: "p.GetTotal()", Set: "p.SetTotal(%s)"})
+	expectedParts := []string{
+		"func (p *Quote) TotalFloat() float64 {\n\treturn math.Ldexp(float64(p.GetTotal()), -32)\n}\n",
+		"func (p *Quote) SetTotalFloat(v float64) error {\n\tn := math.Round(math.Ldexp(v, 32))\n",
+		"\tif !(n >= -0x1p63 && n < 0x1p63) {\n",
+		"return fmt.Errorf(\"Total: %v is outside 32.32 fixed point: %w\", v, ErrOutOfRange)",
+		"\tp.SetTotal(int64(n))\n\treturn nil\n",
+	}
+	for _, expected := range expectedParts {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code missing: %q\n\nGenerated:\n%s", expected, code)
+		}
+	}
+}
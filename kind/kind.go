@@ -0,0 +1,147 @@
+// Package kind registers custom field kinds: tag keywords such as ulid or
+// decimal128 that give a fixed field its own size and encoding, without changes to
+// the generator. A kind is selected by its name after the offset of a fixed field,
+// or as=name, optionally followed by parameters of its own. name=V is short for
+// the kind with parameter name=V:
+//
+//	ID    ulid.ULID  `layout:"@0,as=ulid"`
+//	Price [16]byte   `layout:"@16,decimal128,scale=4"`
+//
+// Plugins register their kinds from an init function; a layout binary bundling
+// them imports the plugins and runs cli.Main:
+//
+//	package main
+//
+//	import (
+//	    "github.com/alexhholmes/layout/cli"
+//	    _ "example.com/layoutkinds/ulid"
+//	)
+//
+//	func main() { cli.Main() }
+package kind
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"sync"
+)
+
+// Field is a field tagged with a kind, as passed to the kind's functions
+type Field struct {
+	Name   string            // Field name
+	GoType string            // Declared Go type, e.g. "ulid.ULID"
+	Params map[string]string // Tag parameters following the kind name, e.g. scale=4; "" for bare ones
+
+	// ByteOrder is the byte order of the type's other fields as an expression
+	// implementing binary.ByteOrder: binary.LittleEndian, binary.BigEndian or
+	// layoutPDPEndian{} per endian=, and binary.NativeEndian in zerocopy mode.
+	// Empty when only Size is asked.
+	ByteOrder string
+}
+
+// Kind is a custom field kind. The code it returns is inserted into generated
+// methods as a block of its own, so it may declare variables; it runs in every mode,
+// so it must not assume the buffer is addressable beyond the slice it is given.
+type Kind struct {
+	// Name is the tag keyword selecting the kind, a Go identifier
+	Name string
+
+	// Size returns the encoded size of f in bytes, or why f can't have this kind,
+	// e.g. an unsupported Go type or parameter
+	Size func(f Field) (int, error)
+
+	// Marshal returns Go statements encoding value, an expression of f's type,
+	// into dst, a byte slice expression of exactly Size bytes. Zerocopy setters
+	// encode into bytes holding the previous value, so every byte must be written.
+	Marshal func(f Field, dst, value string) string
+
+	// Unmarshal returns Go statements decoding src, a byte slice expression of
+	// exactly Size bytes, into value, an assignable expression of f's type
+	Unmarshal func(f Field, src, value string) string
+
+	// Valid optionally returns a boolean expression reporting whether value is
+	// valid for the kind, or "" if every value is. MarshalLayout refuses other
+	// values with ErrOutOfRange, as do the zerocopy setter and, after decoding,
+	// UnmarshalLayout.
+	Valid func(f Field, value string) string
+
+	// Methods optionally returns extra methods of the type holding f, such as
+	// string accessors, reaching the field through a. They may wrap the sentinel
+	// errors of the generated package, e.g. ErrOutOfRange. f.Name has its first
+	// letter uppercased, so the method names beginning with it are exported
+	// whether or not the field is.
+	Methods func(f Field, a Accessor) string
+
+	// Imports are the packages the generated statements use
+	Imports []string
+
+	// FieldImports optionally returns the packages the statements for f use,
+	// replacing Imports for kinds whose statements vary with f's type or
+	// parameters
+	FieldImports func(f Field) []string
+
+	// ByteOrdered reports that the encoding is a single integer in f.ByteOrder,
+	// so ConvertEndian byte-swaps it. Other kinds define their own byte order.
+	ByteOrdered bool
+}
+
+// Accessor tells Methods how generated code reaches a field, which differs
+// between modes
+type Accessor struct {
+	Type string // Type holding the field; methods have receiver p *Type
+	Get  string // Expression reading the field's value
+	Set  string // Statement format storing the value %s into the field; store only values Valid accepts
+}
+
+// ImportsOf returns the packages the generated statements for f use
+func (k Kind) ImportsOf(f Field) []string {
+	if k.FieldImports != nil {
+		return k.FieldImports(f)
+	}
+	return k.Imports
+}
+
+var (
+	mu    sync.RWMutex
+	kinds = make(map[string]Kind)
+)
+
+// Register makes a kind available to layout tags. It panics if the name is not an
+// identifier, a function is missing or the name is already registered, since
+// those are programming errors in the plugin.
+func Register(k Kind) {
+	if !token.IsIdentifier(k.Name) {
+		panic(fmt.Sprintf("kind: name must be an identifier, got %q", k.Name))
+	}
+	if k.Size == nil || k.Marshal == nil || k.Unmarshal == nil {
+		panic(fmt.Sprintf("kind: %s requires Size, Marshal and Unmarshal", k.Name))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := kinds[k.Name]; dup {
+		panic(fmt.Sprintf("kind: %s registered twice", k.Name))
+	}
+	kinds[k.Name] = k
+}
+
+// Lookup returns the kind registered under name
+func Lookup(name string) (Kind, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	k, ok := kinds[name]
+	return k, ok
+}
+
+// Names returns the names of the registered kinds, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,57 @@
+package kind
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func fixedKind(name string, size int) Kind {
+	return Kind{
+		Name:      name,
+		Size:      func(Field) (int, error) { return size, nil },
+		Marshal:   func(f Field, dst, value string) string { return fmt.Sprintf("copy(%s, %s[:])\n", dst, value) },
+		Unmarshal: func(f Field, src, value string) string { return fmt.Sprintf("copy(%s[:], %s)\n", value, src) },
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(fixedKind("testuuid", 16))
+
+	k, ok := Lookup("testuuid")
+	if !ok {
+		t.Fatalf("Lookup(testuuid) not found")
+	}
+	if size, _ := k.Size(Field{}); size != 16 {
+		t.Errorf("Size() = %d, want 16", size)
+	}
+	if _, ok := Lookup("testipaddr"); ok {
+		t.Errorf("Lookup(testipaddr) found an unregistered kind")
+	}
+	if !slices.Contains(Names(), "testuuid") {
+		t.Errorf("Names() = %v, want testuuid listed", Names())
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	Register(fixedKind("testdup", 4))
+
+	tests := []struct {
+		name string
+		kind Kind
+	}{
+		{"duplicate", fixedKind("testdup", 4)},
+		{"not identifier", fixedKind("start-end", 4)},
+		{"missing function", Kind{Name: "testnosize"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) did not panic", tt.kind.Name)
+				}
+			}()
+			Register(tt.kind)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package kind
+
+import (
+	"fmt"
+	"strings"
+)
+
+// transform keeps a sized integer field in natural form and encodes it
+// transformed, as hardware register maps store some fields, in the layout's
+// byte order: transform=bitreverse reverses its bits, transform=gray encodes it
+// in reflected binary Gray code, where consecutive values differ in one bit.
+// Both are bijections, so every value round-trips.
+var transform = Kind{
+	Name: "transform",
+	Size: func(f Field) (int, error) {
+		q, err := parseTransform(f)
+		if err != nil {
+			return 0, err
+		}
+		return q.bits / 8, nil
+	},
+	Marshal: func(f Field, dst, value string) string {
+		q, _ := parseTransform(f)
+		var code strings.Builder
+		code.WriteString(fmt.Sprintf("n := uint%d(%s)\n", q.bits, value))
+		switch q.name {
+		case "bitreverse":
+			code.WriteString(fmt.Sprintf("n = bits.Reverse%d(n)\n", q.bits))
+		case "gray":
+			code.WriteString("n ^= n >> 1\n")
+		}
+		if q.bits == 8 {
+			code.WriteString(fmt.Sprintf("%s[0] = n\n", dst))
+		} else {
+			code.WriteString(fmt.Sprintf("%s.PutUint%d(%s, n)\n", f.ByteOrder, q.bits, dst))
+		}
+		return code.String()
+	},
+	Unmarshal: func(f Field, src, value string) string {
+		q, _ := parseTransform(f)
+		var code strings.Builder
+		if q.bits == 8 {
+			code.WriteString(fmt.Sprintf("n := %s[0]\n", src))
+		} else {
+			code.WriteString(fmt.Sprintf("n := %s.Uint%d(%s)\n", f.ByteOrder, q.bits, src))
+		}
+		switch q.name {
+		case "bitreverse":
+			code.WriteString(fmt.Sprintf("n = bits.Reverse%d(n)\n", q.bits))
+		case "gray":
+			// Each bit is the XOR of the Gray code bits at and above it
+			for shift := 1; shift < q.bits; shift *= 2 {
+				code.WriteString(fmt.Sprintf("n ^= n >> %d\n", shift))
+			}
+		}
+		code.WriteString(fmt.Sprintf("%s = %s(n)\n", value, f.GoType))
+		return code.String()
+	},
+	FieldImports: func(f Field) []string {
+		q, _ := parseTransform(f)
+		var imports []string
+		if q.bits > 8 {
+			imports = append(imports, "encoding/binary")
+		}
+		if q.name == "bitreverse" {
+			imports = append(imports, "math/bits")
+		}
+		return imports
+	},
+	ByteOrdered: true,
+}
+
+// transformFormat is a parsed transform field
+type transformFormat struct {
+	name string // bitreverse or gray
+	bits int    // Width of the field's type
+}
+
+// transformBits are the widths of the integer types transform= encodes
+var transformBits = map[string]int{
+	"uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64,
+	"int8": 8, "int16": 16, "int32": 32, "int64": 64,
+}
+
+// parseTransform parses and checks the parameters of a transform field against
+// its type
+func parseTransform(f Field) (transformFormat, error) {
+	var q transformFormat
+	for key := range f.Params {
+		if key != "transform" {
+			return q, fmt.Errorf("unknown parameter: %s", key)
+		}
+	}
+	q.name = f.Params["transform"]
+	if q.name != "bitreverse" && q.name != "gray" {
+		return q, fmt.Errorf("transform must be 'bitreverse' or 'gray', got: %s", q.name)
+	}
+	bits, ok := transformBits[f.GoType]
+	if !ok {
+		return q, fmt.Errorf("transform=%s requires a sized integer type, e.g. uint16, got %s", q.name, f.GoType)
+	}
+	q.bits = bits
+	return q, nil
+}
@@ -0,0 +1,73 @@
+package kind
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTransformSize(t *testing.T) {
+	tests := []struct {
+		goType  string
+		params  map[string]string
+		want    int
+		wantErr string
+	}{
+		{"uint8", map[string]string{"transform": "gray"}, 1, ""},
+		{"int32", map[string]string{"transform": "bitreverse"}, 4, ""},
+		{"uint64", map[string]string{"transform": "gray"}, 8, ""},
+		{"uint16", map[string]string{"transform": "rot13"}, 0, "transform must be 'bitreverse' or 'gray', got: rot13"},
+		{"uint16", nil, 0, "transform must be 'bitreverse' or 'gray', got: "},
+		{"int", map[string]string{"transform": "gray"}, 0, "transform=gray requires a sized integer type"},
+		{"[2]byte", map[string]string{"transform": "bitreverse"}, 0, "requires a sized integer type"},
+		{"uint16", map[string]string{"transform": "gray", "width": "1"}, 0, "unknown parameter: width"},
+	}
+	for _, tt := range tests {
+		got, err := transform.Size(Field{Name: "Reg", GoType: tt.goType, Params: tt.params})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Size(%s, %v) error = %v, want %q", tt.goType, tt.params, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Size(%s, %v) = %d, %v, want %d", tt.goType, tt.params, got, err, tt.want)
+		}
+	}
+}
+
+func TestTransformCode(t *testing.T) {
+	angle := Field{Name: "Angle", GoType: "uint16", Params: map[string]string{"transform": "gray"}, ByteOrder: "binary.BigEndian"}
+	lanes := Field{Name: "Lanes", GoType: "uint8", Params: map[string]string{"transform": "bitreverse"}}
+	offset := Field{Name: "Offset", GoType: "int32", Params: map[string]string{"transform": "bitreverse"}, ByteOrder: "binary.LittleEndian"}
+
+	checks := []struct {
+		got, want string
+	}{
+		{transform.Marshal(angle, "buf[0:2]", "p.Angle"), "n := uint16(p.Angle)\nn ^= n >> 1\nbinary.BigEndian.PutUint16(buf[0:2], n)\n"},
+		{transform.Unmarshal(angle, "buf[0:2]", "p.Angle"), "n := binary.BigEndian.Uint16(buf[0:2])\nn ^= n >> 1\nn ^= n >> 2\nn ^= n >> 4\nn ^= n >> 8\np.Angle = uint16(n)\n"},
+		{transform.Marshal(lanes, "buf[2:3]", "p.Lanes"), "n := uint8(p.Lanes)\nn = bits.Reverse8(n)\nbuf[2:3][0] = n\n"},
+		{transform.Unmarshal(lanes, "buf[2:3]", "p.Lanes"), "n := buf[2:3][0]\nn = bits.Reverse8(n)\np.Lanes = uint8(n)\n"},
+		{transform.Marshal(offset, "buf[3:7]", "p.Offset"), "n := uint32(p.Offset)\nn = bits.Reverse32(n)\nbinary.LittleEndian.PutUint32(buf[3:7], n)\n"},
+		{transform.Unmarshal(offset, "buf[3:7]", "p.Offset"), "p.Offset = int32(n)\n"},
+	}
+	for _, c := range checks {
+		if !strings.Contains(c.got, c.want) {
+			t.Errorf("Generated %q, want %q", c.got, c.want)
+		}
+	}
+
+	imports := []struct {
+		f    Field
+		want []string
+	}{
+		{angle, []string{"encoding/binary"}},
+		{lanes, []string{"math/bits"}},
+		{offset, []string{"encoding/binary", "math/bits"}},
+	}
+	for _, tt := range imports {
+		if got := transform.ImportsOf(tt.f); !slices.Equal(got, tt.want) {
+			t.Errorf("ImportsOf(%s) = %v, want %v", tt.f.Name, got, tt.want)
+		}
+	}
+}
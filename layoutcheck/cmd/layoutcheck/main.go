@@ -0,0 +1,11 @@
+// Command layoutcheck runs the layoutcheck Analyzer on packages, on its own or
+// as `go vet -vettool=$(which layoutcheck) ./...`
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/alexhholmes/layout/layoutcheck"
+)
+
+func main() { singlechecker.Main(layoutcheck.Analyzer) }
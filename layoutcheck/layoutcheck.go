@@ -0,0 +1,194 @@
+// Package layoutcheck reports the problems of @layout types as a go/analysis
+// Analyzer: tags and annotations that don't parse, unsupported field types, and
+// the analyzer's errors and warnings, at the tag or type they concern. Editors
+// running gopls and `go vet -vettool` show them while the tags are being written.
+//
+// Custom field kinds are known to the Analyzer once their packages are imported
+// by the binary running it, as with package cli.
+package layoutcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/alexhholmes/layout/internal/analyzer"
+	"github.com/alexhholmes/layout/internal/parser"
+)
+
+// Analyzer checks the @layout types of a package
+var Analyzer = &analysis.Analyzer{
+	Name:      "layout",
+	Doc:       "check @layout annotations and layout tags\n\nReports what `layout generate` would refuse or warn about, without generating code.",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(sizeFact)},
+}
+
+// sizeFact is the encoded size of an @layout type, exported to the packages
+// whose layouts embed it
+type sizeFact struct {
+	Size int
+}
+
+func (*sizeFact) AFact() {}
+
+func (f *sizeFact) String() string { return fmt.Sprintf("layout size %d", f.Size) }
+
+func run(pass *analysis.Pass) (any, error) {
+	// Analyze the package's files together, so layouts may embed types declared
+	// in other files; generated code declares no layouts
+	merged := &ast.File{}
+	for _, file := range pass.Files {
+		if !ast.IsGenerated(file) {
+			merged.Decls = append(merged.Decls, file.Decls...)
+		}
+	}
+
+	// Types with unsupported fields are reported once, not again by the analyzer
+	unsupported := make(map[string]bool)
+	for _, d := range parser.FieldTypeDiagnostics(merged) {
+		pass.Report(analysis.Diagnostic{Pos: d.Pos, Message: d.Message})
+		unsupported[d.Type] = true
+	}
+
+	knownSizes, knownAliases := importedTypes(pass)
+	layouts, aliases, diagnostics := parser.ExtractTypes(merged, knownSizes, knownAliases)
+	for _, d := range diagnostics {
+		pass.Report(analysis.Diagnostic{Pos: d.Pos, Message: d.Message})
+	}
+
+	registry := analyzer.NewTypeRegistry()
+	for alias, underlying := range knownAliases {
+		registry.RegisterAlias(alias, underlying)
+	}
+	for alias, underlying := range aliases {
+		registry.RegisterAlias(alias, underlying)
+	}
+	for name, size := range knownSizes {
+		registry.Register(name, size)
+	}
+	for _, layout := range layouts {
+		registry.Register(layout.Name, layout.Anno.Size)
+		registry.RegisterFields(layout.Name, layout.Fields)
+		if layout.Anno.BinaryRead {
+			registry.RegisterBinaryRead(layout.Name, layout.Anno.Endian)
+		}
+		if layout.Anno.Reuse {
+			registry.RegisterReuse(layout.Name)
+		}
+		if layout.Anno.Zeroize {
+			registry.RegisterZeroize(layout.Name)
+		}
+	}
+	for _, layout := range layouts {
+		if layout.Anno.Mode != "stream" {
+			continue
+		}
+		if analyzed, err := analyzer.Analyze(layout, registry); err == nil {
+			registry.RegisterFrame(layout.Name, analyzed.MinFrameSize())
+		}
+	}
+
+	positions := declPositions(merged)
+	for _, layout := range layouts {
+		if obj, ok := pass.Pkg.Scope().Lookup(layout.Name).(*types.TypeName); ok {
+			pass.ExportObjectFact(obj, &sizeFact{Size: layout.Anno.Size})
+		}
+		if unsupported[layout.Name] {
+			continue
+		}
+
+		analyzed, _ := analyzer.Analyze(layout, registry)
+		if analyzed == nil {
+			continue
+		}
+		for _, msg := range append(analyzed.Errors, analyzed.Warnings...) {
+			pass.Report(analysis.Diagnostic{
+				Pos:     positions.of(layout, msg),
+				Message: fmt.Sprintf("%s: %s", layout.Name, msg),
+			})
+		}
+	}
+	return nil, nil
+}
+
+// importedTypes returns the sizes of the @layout types of the packages pass
+// imports, from their facts, and the basic types under their other named types,
+// both named as fields reference them (e.g., "common.PageHeader")
+func importedTypes(pass *analysis.Pass) (map[string]int, map[string]string) {
+	sizes := make(map[string]int)
+	aliases := make(map[string]string)
+	for _, pkg := range pass.Pkg.Imports() {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !obj.Exported() {
+				continue
+			}
+			qualified := pkg.Name() + "." + name
+			var fact sizeFact
+			if pass.ImportObjectFact(obj, &fact) {
+				sizes[qualified] = fact.Size
+			} else if basic, ok := obj.Type().Underlying().(*types.Basic); ok {
+				aliases[qualified] = basic.Name()
+			}
+		}
+	}
+	return sizes, aliases
+}
+
+// positions holds where the @layout types and their tagged fields are declared
+type positions struct {
+	types  map[string]token.Pos
+	fields map[string]map[string]token.Pos // Type → field → layout tag
+}
+
+// declPositions collects the positions of the struct types in file and their tags
+func declPositions(file *ast.File) positions {
+	p := positions{types: make(map[string]token.Pos), fields: make(map[string]map[string]token.Pos)}
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		structType, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		p.types[spec.Name.Name] = spec.Name.Pos()
+		fields := make(map[string]token.Pos)
+		for _, field := range structType.Fields.List {
+			for _, name := range field.Names {
+				if field.Tag != nil {
+					fields[name.Name] = field.Tag.Pos()
+				}
+			}
+		}
+		p.fields[spec.Name.Name] = fields
+		return false
+	})
+	return p
+}
+
+// of returns where to report an analyzer message about layout: the tag of the
+// field it starts with or quotes (e.g., "Keys: ..." or "field 'Keys' ..."),
+// otherwise the type's name
+func (p positions) of(layout *parser.TypeLayout, msg string) token.Pos {
+	name := layout.Name
+	if layout.Generic != "" {
+		name = layout.Generic // Instances are reported at the generic type
+	}
+	for _, field := range layout.Fields {
+		if strings.HasPrefix(msg, field.Name+":") || strings.HasPrefix(msg, field.Name+" ") ||
+			strings.Contains(msg, "field '"+field.Name+"'") {
+			if pos, ok := p.fields[name][field.Name]; ok {
+				return pos
+			}
+		}
+	}
+	return p.types[name]
+}
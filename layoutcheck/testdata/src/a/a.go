@@ -0,0 +1,40 @@
+package a
+
+type PageID uint64
+
+// @layout size=16
+type Header struct { // want Header:"layout size 16"
+	ID   PageID `layout:"@0"`
+	Next uint64 `layout:"@8"`
+}
+
+// @layout size=24
+type Node struct { // want Node:"layout size 24"
+	Header Header `layout:"@0"`
+	Parent *Node  `layout:"@16"`         // want `Node.Parent: unsupported field type for layout: \*Node`
+	Kind   uint8  `layout:"@20,bogus=1"` // want `Node.Kind: invalid layout tag "@20,bogus=1": .*; field skipped`
+}
+
+// @layout size=24
+type Entry struct { // want Entry:"layout size 24" `Entry: collision: Header \[0, 16\) overlaps Flags \[12, 16\)`
+	Header Header `layout:"@0"`
+	Flags  uint32 `layout:"@12"`
+}
+
+// @layout size=8
+type Tail struct { // want Tail:"layout size 8"
+	ID    uint64 `layout:"@0"`
+	Extra uint8  `layout:"@8"` // want `Tail: Extra: field \[8, 9\) exceeds buffer size 8`
+}
+
+// @layout size=32
+type Leaf struct { // want Leaf:"layout size 32" `Leaf: exported fields without layout tags don't round-trip: Cache`
+	N     uint8  `layout:"@0"`
+	Keys  []byte `layout:"start-end,count=N,max=31"`
+	Cache []byte
+}
+
+// @layout sze=8 // want `Bad: invalid @layout annotation: .*`
+type Bad struct {
+	ID uint64 `layout:"@0"`
+}
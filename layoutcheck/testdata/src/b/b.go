@@ -0,0 +1,9 @@
+package b
+
+import "a"
+
+// @layout
+type Page struct { // want Page:"layout size 24"
+	Header a.Header `layout:"@0"`
+	ID     a.PageID `layout:"@16"`
+}